@@ -0,0 +1,41 @@
+"""Smoke test of the generated client, it exits with 1 on the first failed call."""
+
+import argparse
+import os
+import sys
+
+import grpc
+
+sys.path.insert(0, os.path.join(os.path.dirname(os.path.abspath(__file__)), "src"))
+
+import api_pb2  # noqa: E402
+import api_pb2_grpc  # noqa: E402
+
+
+def main() -> int:
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--addr", default="localhost:9001", help="user API gRPC address")
+    parser.add_argument("--timeout", type=float, default=5.0, help="call timeout in seconds")
+    args = parser.parse_args()
+
+    with grpc.insecure_channel(args.addr) as channel:
+        stub = api_pb2_grpc.UserStub(channel)
+        try:
+            info = stub.ServiceInfo(api_pb2.ServiceInfoRequest(), timeout=args.timeout)
+            if not info.enabled_methods:
+                print("service info: no enabled methods", file=sys.stderr)
+                return 1
+
+            users = []
+            for resp in stub.UserAllList(api_pb2.UserAllListRequest(limit=10), timeout=args.timeout):
+                users.extend(resp.users)
+        except grpc.RpcError as err:
+            print(f"call failed: {err.code().name}: {err.details()}", file=sys.stderr)
+            return 1
+
+    print(f"python client passed: {len(info.enabled_methods)} methods, {len(users)} users")
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,45 @@
+// Smoke test of the generated client, it exits with 1 on the first failed call.
+import { credentials, ServiceError } from "@grpc/grpc-js";
+
+import { ServiceInfoRequest, ServiceInfoResponse, UserAllListRequest } from "./gen/api";
+import { UserClient } from "./gen/api.grpc-client";
+
+function arg(name: string, fallback: string): string {
+  const i = process.argv.indexOf(`--${name}`);
+  return i >= 0 && i + 1 < process.argv.length ? process.argv[i + 1] : fallback;
+}
+
+function deadline(timeoutMs: number): Date {
+  return new Date(Date.now() + timeoutMs);
+}
+
+async function main(): Promise<void> {
+  const addr = arg("addr", "localhost:9001");
+  const timeoutMs = Number(arg("timeout", "5")) * 1000;
+  const client = new UserClient(addr, credentials.createInsecure());
+
+  try {
+    const info = await new Promise<ServiceInfoResponse>((resolve, reject) => {
+      client.serviceInfo(ServiceInfoRequest.create(), { deadline: deadline(timeoutMs) }, (err, value) =>
+        err || !value ? reject(err) : resolve(value),
+      );
+    });
+    if (info.enabledMethods.length === 0) {
+      throw new Error("service info: no enabled methods");
+    }
+
+    let users = 0;
+    const stream = client.userAllList(UserAllListRequest.create({ limit: "10" }), { deadline: deadline(timeoutMs) });
+    for await (const resp of stream) {
+      users += resp.users.length;
+    }
+    console.log(`typescript client passed: ${info.enabledMethods.length} methods, ${users} users`);
+  } finally {
+    client.close();
+  }
+}
+
+main().catch((err: ServiceError | Error) => {
+  console.error(`call failed: ${err.message}`);
+  process.exit(1);
+});
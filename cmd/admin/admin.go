@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  reindex         start rebuild of secondary indexes
+  reindex-status  print progress of the last rebuild
+  backup          write backup of all users to the file or to the storage
+  restore         restore users from the backup file or from the storage
+  state-at        print user state at the time from its history and optionally restore it
+  maintenance     print maintenance mode, "maintenance on -reason <reason>" and "maintenance off" change it
+  cache-evict     drop the cached user and cached lists
+  audit-tail      print users changed and deleted since the time, with -follow wait for new changes
+  quota-set       set avatar and password attempts quotas of the tenant, other overrides are kept
+  generate        create fake users for demos and load tests
+
+calls are authenticated by $ADMIN_TOKEN or by the first of admin_tokens of the config
+`
+
+// tokenEnv is an environment variable with the admin token, it overrides the config one.
+const tokenEnv = "ADMIN_TOKEN"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	config, err := yamlPkg.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	conn, err := grpc.Dial(config.GRPCDataAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer conn.Close()
+	client := pb.NewAdminClient(conn)
+
+	ctx := context.Background()
+	token := os.Getenv(tokenEnv)
+	if tokens := config.AdminTokens(); token == "" && len(tokens) != 0 {
+		token = tokens[0]
+	}
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpcPkg.AdminMetaKey, token)
+	}
+
+	switch os.Args[1] {
+	case "reindex":
+		err = reindex(ctx, client, os.Args[2:])
+	case "reindex-status":
+		err = reindexStatus(ctx, client)
+	case "backup":
+		err = backup(ctx, client, os.Args[2:])
+	case "restore":
+		err = restore(ctx, client, os.Args[2:])
+	case "state-at":
+		err = stateAt(ctx, client, os.Args[2:])
+	case "maintenance":
+		err = maintenance(ctx, client, os.Args[2:])
+	case "cache-evict":
+		err = cacheEvict(ctx, client, os.Args[2:])
+	case "audit-tail":
+		err = auditTail(ctx, pb.NewUserClient(conn), os.Args[2:])
+	case "quota-set":
+		err = quotaSet(ctx, client, os.Args[2:])
+	case "generate":
+		err = generate(ctx, client, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func reindex(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	indexes := fs.String("index", "", "comma separated index names, all indexes if empty")
+	resume := fs.Bool("resume", false, "continue the last failed or interrupted job")
+	wait := fs.Bool("wait", false, "wait until the job is finished")
+	_ = fs.Parse(args)
+
+	req := &pb.ReindexStartRequest{
+		Resume: *resume,
+	}
+	if *indexes != "" {
+		req.Indexes = strings.Split(*indexes, ",")
+	}
+	resp, err := client.ReindexStart(ctx, req)
+	if err != nil {
+		return err
+	}
+	printJob(resp.GetJob())
+	if !*wait {
+		return nil
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		status, err := client.ReindexStatus(ctx, &pb.ReindexStatusRequest{})
+		if err != nil {
+			return err
+		}
+		job := status.GetJob()
+		printJob(job)
+		if job.GetStatus() != "running" {
+			break
+		}
+	}
+	return nil
+}
+
+func reindexStatus(ctx context.Context, client pb.AdminClient) error {
+	resp, err := client.ReindexStatus(ctx, &pb.ReindexStatusRequest{})
+	if err != nil {
+		return err
+	}
+	printJob(resp.GetJob())
+	return nil
+}
+
+func backup(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("out", "", "backup file, the backup is written to the storage if empty")
+	key := fs.String("key", "", "key of the stored backup, generated if empty")
+	_ = fs.Parse(args)
+
+	stream, err := client.BackupCreate(ctx, &pb.BackupCreateRequest{
+		Store: *out == "",
+		Key:   *key,
+	})
+	if err != nil {
+		return err
+	}
+	var file *os.File
+	if *out != "" {
+		if file, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if summary := resp.GetSummary(); summary != nil {
+			name := summary.GetKey()
+			if name == "" {
+				name = *out
+			}
+			fmt.Printf("backup %s: users %d, size %d, sha256 %s\n",
+				name, summary.GetUsers(), summary.GetSize(), summary.GetChecksum())
+			if file != nil {
+				return file.Close()
+			}
+			return nil
+		}
+		if _, err = file.Write(resp.GetChunk()); err != nil {
+			return err
+		}
+	}
+}
+
+func restore(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("in", "", "backup file")
+	key := fs.String("key", "", "key of the stored backup, used if file is empty")
+	checksum := fs.String("checksum", "", "expected sha256 of the backup")
+	overwrite := fs.Bool("overwrite", false, "replace existing users")
+	_ = fs.Parse(args)
+
+	if *in == "" && *key == "" {
+		return errors.New("file or key must be set")
+	}
+	stream, err := client.BackupRestore(ctx)
+	if err != nil {
+		return err
+	}
+	first := &pb.BackupRestoreRequest{
+		Key:       *key,
+		Checksum:  *checksum,
+		Overwrite: *overwrite,
+	}
+	if *in != "" {
+		first.Key = ""
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		for {
+			buf := make([]byte, 64<<10)
+			n, err := file.Read(buf)
+			if n > 0 {
+				first.Chunk = buf[:n]
+				if err := stream.Send(first); err != nil {
+					return err
+				}
+				first = &pb.BackupRestoreRequest{}
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	} else if err = stream.Send(first); err != nil {
+		return err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %d users: created %d, overwritten %d, skipped %d, failed %d\n",
+		resp.GetUsers(), resp.GetCreated(), resp.GetOverwritten(), resp.GetSkipped(), len(resp.GetFailed()))
+	for _, failed := range resp.GetFailed() {
+		fmt.Println("  ", failed)
+	}
+	return nil
+}
+
+func stateAt(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("state-at", flag.ExitOnError)
+	name := fs.String("name", "", "user name")
+	at := fs.String("at", "", "time in RFC3339 format")
+	restore := fs.Bool("restore", false, "replace the current user with the state")
+	_ = fs.Parse(args)
+
+	if *name == "" || *at == "" {
+		return errors.New("name and time must be set")
+	}
+	t, err := time.Parse(time.RFC3339, *at)
+	if err != nil {
+		return err
+	}
+	resp, err := client.UserStateAt(ctx, &pb.UserStateAtRequest{
+		Name:    *name,
+		At:      t.Unix(),
+		Restore: *restore,
+	})
+	if err != nil {
+		return err
+	}
+	user := resp.GetUser()
+	fmt.Printf("user %s (%s) at %s: email %s, full name %s, status %s, attributes %v\n",
+		user.GetName(), user.GetId(), t.Format(time.RFC3339), user.GetEmail(), user.GetFullName(), user.GetStatus(),
+		user.GetAttributes())
+	if *restore {
+		fmt.Println("restored")
+	}
+	return nil
+}
+
+func maintenance(ctx context.Context, client pb.AdminClient, args []string) error {
+	if len(args) == 0 {
+		resp, err := client.MaintenanceGet(ctx, &pb.MaintenanceGetRequest{})
+		if err != nil {
+			return err
+		}
+		printMaintenance(resp.GetState())
+		return nil
+	}
+
+	fs := flag.NewFlagSet("maintenance", flag.ExitOnError)
+	reason := fs.String("reason", "", "reason returned with rejected mutations, required to enable the mode")
+	_ = fs.Parse(args[1:])
+
+	req := &pb.MaintenanceSetRequest{Reason: *reason}
+	switch args[0] {
+	case "on":
+		req.Enabled = true
+	case "off":
+	default:
+		return errors.New(`mode must be "on" or "off"`)
+	}
+	resp, err := client.MaintenanceSet(ctx, req)
+	if err != nil {
+		return err
+	}
+	printMaintenance(resp.GetState())
+	return nil
+}
+
+func cacheEvict(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("cache-evict", flag.ExitOnError)
+	name := fs.String("name", "", "user name")
+	_ = fs.Parse(args)
+
+	if *name == "" {
+		return errors.New("name must be set")
+	}
+	resp, err := client.CacheEvict(ctx, &pb.CacheEvictRequest{Name: *name})
+	if err != nil {
+		return err
+	}
+	if resp.GetEvicted() {
+		fmt.Printf("user %s evicted\n", *name)
+	} else {
+		fmt.Printf("user %s is not cached, cached lists are dropped\n", *name)
+	}
+	return nil
+}
+
+func auditTail(ctx context.Context, client pb.UserClient, args []string) error {
+	fs := flag.NewFlagSet("audit-tail", flag.ExitOnError)
+	since := fs.Duration("since", 10*time.Minute, "print changes made within the period")
+	follow := fs.Bool("follow", false, "wait for new changes")
+	interval := fs.Duration("interval", 5*time.Second, "interval of polling for new changes")
+	_ = fs.Parse(args)
+
+	from := time.Now().Add(-*since).Unix()
+	for {
+		stream, err := client.UserChanges(ctx, &pb.UserChangesRequest{Since: from, IncludeDeleted: true})
+		if err != nil {
+			return err
+		}
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if next := resp.GetNextSince(); next != 0 {
+				from = next
+			}
+			for _, deleted := range resp.GetDeleted() {
+				fmt.Printf("%s deleted %s (%s)\n",
+					time.Unix(deleted.GetDeletedAt(), 0).Format(time.RFC3339), deleted.GetName(), deleted.GetId())
+			}
+			for _, user := range resp.GetUsers() {
+				fmt.Printf("%s written %s (%s): email %s, status %s, attributes %v\n",
+					time.Unix(user.GetUpdatedAt(), 0).Format(time.RFC3339), user.GetName(), user.GetId(), user.GetEmail(),
+					user.GetStatus(), user.GetAttributes())
+			}
+		}
+		if !*follow {
+			return nil
+		}
+		time.Sleep(*interval)
+	}
+}
+
+func quotaSet(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("quota-set", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "tenant name")
+	avatarSize := fs.Int64("avatar-size", 0, "quota of avatars in bytes, 0 keeps the service config")
+	attempts := fs.Uint("password-attempts", 0, "limit of failed password checks within the window, 0 keeps the service config")
+	_ = fs.Parse(args)
+
+	if *tenant == "" {
+		return errors.New("tenant must be set")
+	}
+	overrides := &pb.TenantOverrides{Tenant: *tenant}
+	resp, err := client.TenantOverridesGet(ctx, &pb.TenantOverridesGetRequest{Tenant: *tenant})
+	switch {
+	case err == nil:
+		overrides = resp.GetOverrides()
+	case status.Code(err) != codes.NotFound:
+		return err
+	}
+	// only quotas set by flags are changed
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "avatar-size":
+			overrides.MaxAvatarSize = *avatarSize
+		case "password-attempts":
+			overrides.MaxPasswordAttempts = uint32(*attempts)
+		}
+	})
+	set, err := client.TenantOverridesSet(ctx, &pb.TenantOverridesSetRequest{Overrides: overrides})
+	if err != nil {
+		return err
+	}
+	overrides = set.GetOverrides()
+	fmt.Printf("tenant %s: max avatar size %d, max password attempts %d\n",
+		overrides.GetTenant(), overrides.GetMaxAvatarSize(), overrides.GetMaxPasswordAttempts())
+	return nil
+}
+
+func generate(ctx context.Context, client pb.AdminClient, args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	count := fs.Uint("count", 100, "number of users")
+	prefix := fs.String("prefix", "", "prefix of user names up to 20 characters: lower case letters, digits, \"_\", \".\" and \"-\"")
+	verbose := fs.Bool("v", false, "print names of created users")
+	_ = fs.Parse(args)
+
+	started := time.Now()
+	resp, err := client.UserGenerate(ctx, &pb.UserGenerateRequest{
+		Count:  uint32(*count),
+		Prefix: *prefix,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("generated %d users in %s, failed %d\n",
+		len(resp.GetCreated()), time.Since(started).Round(time.Millisecond), len(resp.GetFailed()))
+	if *verbose {
+		for _, name := range resp.GetCreated() {
+			fmt.Println("  ", name)
+		}
+	}
+	for _, failed := range resp.GetFailed() {
+		fmt.Println("  ", failed)
+	}
+	return nil
+}
+
+func printMaintenance(state *pb.MaintenanceState) {
+	if !state.GetEnabled() {
+		fmt.Print("maintenance off")
+	} else {
+		fmt.Printf("maintenance on: %s", state.GetReason())
+	}
+	if state.GetSince() != 0 {
+		fmt.Printf(", by %s since %s", state.GetActor(), time.Unix(state.GetSince(), 0).Format(time.RFC3339))
+	}
+	fmt.Println()
+}
+
+func printJob(job *pb.ReindexJob) {
+	fmt.Printf("job %s %v: %s, page %d, processed %d",
+		job.GetId(), job.GetIndexes(), job.GetStatus(), job.GetPage(), job.GetProcessed())
+	if job.GetResumed() {
+		fmt.Print(", resumed")
+	}
+	if job.GetError() != "" {
+		fmt.Printf(", error: %s", job.GetError())
+	}
+	fmt.Printf(", updated %s\n", time.Unix(job.GetUpdatedAt(), 0).Format(time.RFC3339))
+}
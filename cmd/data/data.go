@@ -3,37 +3,100 @@ package main
 import (
 	"context"
 	"expvar"
+	"flag"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 
 	"github.com/Shopify/sarama"
 	grpcOpentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	apiAdminPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/admin"
 	apiDataPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/data"
+	apiReplicaPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/replica"
 	dataPkg "gitlab.ozon.dev/iTukaev/homework/internal/brokers/data"
+	metricsPkg "gitlab.ozon.dev/iTukaev/homework/internal/brokers/metrics"
 	configPkg "gitlab.ozon.dev/iTukaev/homework/internal/config"
 	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	anomalyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	approvalPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval"
+	authzPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/authz"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	backupPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup"
+	cdcPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cdc"
 	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	costPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cost"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	fakePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	ldapsyncPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/ldapsync"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	reconcilePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reconcile"
+	reindexPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reindex"
+	seedPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/seed"
+	selfcheckPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/selfcheck"
+	standbyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/standby"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	tombstonePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tombstone"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	usercountPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usercount"
+	userstatsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/userstats"
+	verifyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify"
 	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	bloomRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom"
+	budgetRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/budget"
+	cachedRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/cached"
+	canaryRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/canary"
+	instrumentedRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/instrumented"
 	localCachePkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/local"
+	oplogRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/oplog"
 	postgresPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres"
+	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	readonlyRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/readonly"
+	replicateRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/replicate"
+	scannedRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/scanned"
+	shardRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/shard"
+	slowlogRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/slowlog"
+	timedRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/timed"
 	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
 	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+	leaderPkg "gitlab.ozon.dev/iTukaev/homework/pkg/leader"
+	lifecyclePkg "gitlab.ozon.dev/iTukaev/homework/pkg/lifecycle"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
+	workerpoolPkg "gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
 )
 
+// seedAdminPasswordEnv keeps the password of the admin seeded by the flag out of the command line
+const seedAdminPasswordEnv = "SEED_ADMIN_PASSWORD"
+
 func main() {
+	check := flag.Bool("check", false, "probe dependencies, log readiness report and exit")
+	seedFile := flag.String("seed", "", "YAML file with users seeded into the empty store, overrides config")
+	seedAdmin := flag.String("seed-admin", "", "admin seeded into the empty store, password is read from "+seedAdminPasswordEnv)
+	bootstrap := flag.Bool("bootstrap-from-topic", false, "rebuild the empty store from the compacted user events topic and exit")
+	flag.Parse()
+
 	config, err := yamlPkg.New()
 	if err != nil {
 		log.Fatalln("Config init error:", err)
@@ -44,100 +107,730 @@ func main() {
 	}
 	logger.Infoln("Start main")
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer func() {
-		logger.Infoln("Shutting down...")
-		cancel()
-	}()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	probes := []selfcheckPkg.Probe{
+		selfcheckPkg.Kafka(config.Brokers()),
+		selfcheckPkg.Redis(config.RedisConfig()),
+	}
+	if !config.Local() {
+		probes = append(probes, selfcheckPkg.Postgres(config.PGConfig())...)
+	}
+	if _, err = selfcheckPkg.Run(ctx, logger, probes...); err != nil {
+		logger.Fatalln(err)
+	}
+	if *check {
+		return
+	}
 
-	go func() {
-		if err = start(ctx, config, logger); err != nil {
-			logger.Errorln("gRPC", err)
-		}
-		c <- os.Interrupt
-	}()
+	seed := config.SeedConfig()
+	if *seedFile != "" {
+		seed.Enabled = true
+		seed.File = *seedFile
+	}
+	if *seedAdmin != "" {
+		seed.Enabled = true
+		seed.Admin = seedPkg.User{Name: *seedAdmin, Password: os.Getenv(seedAdminPasswordEnv)}
+	}
 
-	<-c
+	if err = start(ctx, config, seed, *bootstrap, logger); err != nil {
+		logger.Errorln("data service", err)
+	}
+	_ = logger.Sync()
 }
 
-func start(ctx context.Context, config configPkg.Interface, logger *zap.SugaredLogger) (retErr error) {
-	var data repoPkg.Interface
-	if config.Local() {
-		workers := config.WorkersCount()
-		if workers == 0 {
-			workers = 10
+func start(
+	ctx context.Context,
+	config configPkg.Interface,
+	seed seedPkg.Config,
+	bootstrap bool,
+	logger *zap.SugaredLogger,
+) error {
+	var (
+		pools     []*workerpoolPkg.Pool
+		failovers []*postgresPkg.Failover
+	)
+	// failover keeps the pool connected to the primary, name tells apart pools in metrics
+	failover := func(pool *pgxpool.Pool, name string, pg pgModels.Config) {
+		if pg.Failover.Enabled {
+			failovers = append(failovers, postgresPkg.NewFailover(pool, name, pg.Failover, clock.Real(), logger))
+		}
+	}
+	metrics := config.RepoMetricsConfig()
+	budget := config.ScanBudgetConfig()
+	instrument := func(data repoPkg.Interface, backend string) repoPkg.Interface {
+		if !metrics.Enabled {
+			return data
+		}
+		return instrumentedRepoPkg.New(data, backend, metrics)
+	}
+	// suffix tells apart pools and metrics of shards
+	newBackend := func(local bool, pg pgModels.Config, suffix string) (repoPkg.Interface, error) {
+		if local {
+			workers := config.WorkersCount()
+			if workers == 0 {
+				workers = 10
+			}
+			pool := workerpoolPkg.New("local"+suffix, workerpoolPkg.Config{Workers: workers}, logger)
+			pools = append(pools, pool)
+			return instrument(localCachePkg.New(pool, logger), canaryRepoPkg.BackendLocal+suffix), nil
 		}
-		data = localCachePkg.New(workers, logger)
-	} else {
-		pg := config.PGConfig()
 		pool, err := postgresPkg.NewPostgres(ctx, pg.Host, pg.Port, pg.User, pg.Password, pg.DBName, logger)
 		if err != nil {
-			logger.Errorln("New Postgres", err)
+			return nil, errors.Wrap(err, "new postgres"+suffix)
+		}
+		failover(pool, "repo"+suffix, pg)
+		data := postgresPkg.New(pool, logger)
+		if budget.Enabled {
+			data = budgetRepoPkg.New(data, budget, logger)
+		}
+		return instrument(data, canaryRepoPkg.BackendPostgres+suffix), nil
+	}
+	newRepo := func(local bool) (repoPkg.Interface, error) {
+		return newBackend(local, config.PGConfig(), "")
+	}
+
+	var (
+		data    repoPkg.Interface
+		sharded *shardRepoPkg.Repo
+		err     error
+	)
+	if cfg := config.ShardConfig(); cfg.Enabled {
+		shards := make(map[string]repoPkg.Interface, len(cfg.Shards))
+		for _, shard := range cfg.Shards {
+			if shards[shard.Name], err = newBackend(shard.Local, shard.PG, ":"+shard.Name); err != nil {
+				return errors.Wrapf(err, "shard [%s]", shard.Name)
+			}
+		}
+		if sharded, err = shardRepoPkg.New(shards, cfg, logger); err != nil {
 			return err
 		}
-		data = postgresPkg.New(pool, logger)
+		data = sharded
+	} else if data, err = newRepo(config.Local()); err != nil {
+		return err
+	}
+	if canary := config.CanaryConfig(); canary.Enabled {
+		candidate, err := newRepo(canary.Candidate == canaryRepoPkg.BackendLocal)
+		if err != nil {
+			return errors.Wrap(err, "canary repo")
+		}
+		data = canaryRepoPkg.New(data, candidate, canary, logger)
+	}
+	if threshold := config.SlowQueryThreshold(); threshold > 0 {
+		data = slowlogRepoPkg.New(data, threshold, logger)
+	}
+	if cfg := config.OpLogConfig(); cfg.Enabled {
+		if data, err = oplogRepoPkg.New(data, cfg, logger); err != nil {
+			return errors.Wrap(err, "operation log")
+		}
 	}
+	costBudget := config.CostBudgetConfig()
+	if costBudget.Enabled {
+		data = scannedRepoPkg.New(data)
+	}
+	data = timedRepoPkg.New(data)
 
 	client, err := redisPkg.New(ctx, config.RedisConfig())
 	if err != nil {
 		return errors.Wrap(err, "new redis client")
 	}
 
-	user := userPkg.New(data, logger, client)
+	codec, err := codecPkg.New(config.CacheFormat())
+	if err != nil {
+		return errors.Wrap(err, "new cache codec")
+	}
+
+	// writes of other replicas are seen after TTL of users cached in memory
+	if cfg := config.RepoCacheConfig(); cfg.Enabled {
+		cache := cachedRepoPkg.NewMemory(cfg)
+		if cfg.Backend == cachedRepoPkg.BackendRedis {
+			cache = cachedRepoPkg.NewRedis(client, codec)
+		}
+		data = cachedRepoPkg.New(data, cache, cfg, logger)
+	}
+
+	maintenanceStore := maintenancePkg.NewMemory()
+	tenantStore := tenantPkg.NewMemory()
+	usageStore := usagePkg.NewMemory()
+	// shards have no single users table to count by one query
+	statsStore := userstatsPkg.NewRepo(data)
+	closeMaintenance := func() {}
+	if !config.Local() {
+		pg := config.PGConfig()
+		pool, err := postgresPkg.NewPostgres(ctx, pg.Host, pg.Port, pg.User, pg.Password, pg.DBName, logger)
+		if err != nil {
+			return errors.Wrap(err, "new maintenance postgres")
+		}
+		failover(pool, "stores", pg)
+		maintenanceStore, closeMaintenance = maintenancePkg.NewPostgres(pool), pool.Close
+		tenantStore = tenantPkg.NewPostgres(pool)
+		usageStore = usagePkg.NewPostgres(pool)
+		if sharded == nil {
+			statsStore = userstatsPkg.NewPostgres(pool)
+		}
+	}
+	maintenance := maintenancePkg.New(maintenanceStore, logger)
+	if err = maintenance.Refresh(ctx); err != nil {
+		return err
+	}
+	tenants := tenantPkg.New(tenantStore, logger)
+	if err = tenants.Refresh(ctx); err != nil {
+		return err
+	}
+	// writes are rejected in maintenance mode, so they are not replicated either
+	data = readonlyRepoPkg.New(data, maintenance)
+
+	var replicator *replicateRepoPkg.Repo
+	if cfg := config.ReplicationConfig(); cfg.Enabled {
+		if replicator, err = replicateRepoPkg.New(ctx, data, cfg, logger); err != nil {
+			return errors.Wrap(err, "new replication")
+		}
+		data = replicator
+	}
+	if bloom := config.BloomConfig(); bloom.Enabled && !config.Local() {
+		var load bloomRepoPkg.Loader
+		if cfg := config.StandbyConfig(); cfg.Enabled {
+			// peers share the config, so the first admin token is accepted by them
+			var token string
+			if tokens := config.AdminTokens(); len(tokens) != 0 {
+				token = tokens[0]
+			}
+			load = standbyPkg.New(cfg, token, logger).Bloom
+		}
+		data = bloomRepoPkg.New(ctx, data, bloom, load, logger)
+	}
+
+	var history historyPkg.Interface
+	if config.HistoryConfig().Enabled {
+		if config.Local() {
+			history = historyPkg.NewMemory(clock.Real())
+		} else {
+			pg := config.PGConfig()
+			pool, err := postgresPkg.NewPostgres(ctx, pg.Host, pg.Port, pg.User, pg.Password, pg.DBName, logger)
+			if err != nil {
+				return errors.Wrap(err, "new history postgres")
+			}
+			failover(pool, "history", pg)
+			history = historyPkg.NewPostgres(pool, logger)
+		}
+	}
+
+	avatarCfg := config.AvatarConfig()
+	avatars, err := avatarPkg.NewStorage(avatarCfg)
+	if err != nil {
+		return errors.Wrap(err, "new avatar storage")
+	}
+
+	denylist, err := denylistPkg.New(config.DenylistConfig(), client)
+	if err != nil {
+		return errors.Wrap(err, "new denylist")
+	}
+
+	passwordPolicy, err := passwordPkg.New(config.PasswordPolicyConfig(), tenants)
+	if err != nil {
+		return errors.Wrap(err, "new password policy")
+	}
+
+	oidc := config.OIDCConfig()
+	tombstones := config.TombstoneConfig()
+	normalizer := normalizePkg.New(config.NamePolicy())
+	opts := []userPkg.Option{
+		userPkg.WithListTTL(config.ListCacheTTL()),
+		userPkg.WithCodec(codec),
+		userPkg.WithPasswordAttempts(config.PasswordMaxAttempts(), config.PasswordAttemptsWindow()),
+		userPkg.WithNormalizer(normalizer),
+		userPkg.WithExternalLogin(oidc.AutoProvision, oidc.SessionTTL),
+		userPkg.WithDenylist(denylist),
+		userPkg.WithPasswordPolicy(passwordPolicy),
+		userPkg.WithTenants(tenants),
+		userPkg.WithTombstoneRetention(tombstones.Retention),
+	}
+	if avatars != nil {
+		opts = append(opts, userPkg.WithAvatars(avatars, avatarCfg))
+	}
+	if history != nil {
+		opts = append(opts, userPkg.WithHistory(history))
+	}
+	// restored users are not suspicious, they are not counted by the detection
+	var anomaly anomalyPkg.Interface
+	if cfg := config.AnomalyConfig(); cfg.Enabled && !bootstrap {
+		anomaly = anomalyPkg.New(cfg, maintenance, logger)
+		opts = append(opts, userPkg.WithSubscribers(anomaly.Handle))
+	}
+	var (
+		events    sarama.SyncProducer
+		analytics []eventsPkg.Sink
+		security  []eventsPkg.Sink
+		queues    []*eventsPkg.Async
+	)
+	// restored users are not published back to the topic they are read from
+	if cfg := config.EventsConfig(); !bootstrap {
+		if cfg.KafkaTopic != "" || cfg.Analytics.KafkaTopic != "" || cfg.Security.KafkaTopic != "" {
+			producerCfg := sarama.NewConfig()
+			producerCfg.Producer.Return.Successes = true
+			if events, err = sarama.NewSyncProducer(config.Brokers(), producerCfg); err != nil {
+				return errors.Wrap(err, "new events SyncProducer")
+			}
+		}
+		publishers := make(map[string]eventsPkg.Handler)
+		if cfg.KafkaTopic != "" {
+			publishers["kafka"] = eventsPkg.KafkaPublisher(events, cfg, logger)
+		}
+		if cfg.Webhook.URL != "" {
+			publishers["webhook"] = eventsPkg.WebhookPublisher(cfg.Webhook, http.DefaultClient, logger)
+		}
+		for _, name := range []string{"kafka", "webhook"} {
+			if publishers[name] == nil {
+				continue
+			}
+			spool, err := eventsPkg.NewSpool(cfg.Spool, name)
+			if err != nil {
+				return errors.Wrapf(err, "new %s events spool", name)
+			}
+			queues = append(queues, eventsPkg.NewAsync(name, publishers[name], cfg.QueueSize, spool, logger))
+		}
+		for _, queue := range queues {
+			opts = append(opts, userPkg.WithSubscribers(queue.Handle))
+		}
+		if analytics, err = eventsPkg.NewAnalyticsSinks(cfg.Analytics, events); err != nil {
+			return errors.Wrap(err, "new analytics sinks")
+		}
+		for _, sink := range analytics {
+			opts = append(opts, userPkg.WithSubscribers(eventsPkg.AnalyticsPublisher(sink, cfg.Analytics, logger)))
+		}
+		if security, err = eventsPkg.NewSecuritySinks(cfg.Security, events); err != nil {
+			return errors.Wrap(err, "new security sinks")
+		}
+		if len(security) != 0 {
+			opts = append(opts, userPkg.WithSecurity(eventsPkg.SecurityPublisher(security, clock.Real(), logger)))
+		}
+	}
+	user := userPkg.New(data, logger, client, opts...)
+	if bootstrap {
+		return bootstrapFromTopic(ctx, config.Brokers(), config.EventsConfig(), user, logger)
+	}
 
 	tracer, closer, err := jaegerPkg.New(config.JService(), config.JHost())
 	if err != nil {
-		logger.Errorf("Jaeger initialise err: %v", err)
-		return
+		return errors.Wrap(err, "jaeger initialise")
 	}
-	defer func() {
-		_ = closer.Close()
-	}()
 	opentracing.SetGlobalTracer(tracer)
 
-	server := apiDataPkg.New(user, logger)
+	var indexes []reindexPkg.Index
+	if index, ok := data.(reindexPkg.Index); ok {
+		indexes = append(indexes, index)
+	}
+	// reindex runs one job at a time
+	jobs := workerpoolPkg.New("jobs", workerpoolPkg.Config{Workers: 1}, logger)
+	pools = append(pools, jobs)
+	reindex := reindexPkg.New(data, client, jobs, logger, indexes...)
 
-	stopCh := make(chan struct{}, 0)
-	go func() {
-		if err = runGRPCServer(ctx, server, config.GRPCDataAddr(), logger); err != nil {
-			retErr = errors.Wrap(err, "gRPC server")
+	backupStorage, err := backupPkg.NewStorage(config.BackupConfig())
+	if err != nil {
+		return errors.Wrap(err, "new backup storage")
+	}
+	backup := backupPkg.New(data, user, backupStorage, logger)
+
+	methods := grpcPkg.NewMethods(config.MethodsConfig(), pb.User_ServiceDesc, pb.Admin_ServiceDesc, pb.Replica_ServiceDesc)
+	// roles of callers are resolved by the fields policy, it allows impersonation to the role
+	roles, err := grpcPkg.NewFields(config.FieldsConfig())
+	if err != nil {
+		return errors.Wrap(err, "fields policy")
+	}
+	// calls are authorized by the policies after the impersonation, so both identities are known
+	var (
+		authorizer grpcPkg.Authorizer
+		authz      = lifecyclePkg.Component{Name: "authz"}
+	)
+	if cfg := config.AuthzConfig(); cfg.Enabled {
+		engine, err := authzPkg.New(cfg, roles, logger)
+		if err != nil {
+			return errors.Wrap(err, "authz policies")
 		}
-		close(stopCh)
-	}()
-	go func() {
-		if err = runHTTPServer(ctx, config.HTTPDataAddr(), logger); err != nil {
-			retErr = errors.Wrap(err, "HTTP server")
+		authorizer = engine
+		authz.Run = func(ctx context.Context) error {
+			engine.Run(ctx)
+			return nil
 		}
-		close(stopCh)
-	}()
-	go func() {
-		if err = runService(ctx, config.Brokers(), logger, user); err != nil {
-			retErr = errors.Wrap(err, "consumer service")
+		authz.Stop = func(context.Context) error {
+			return engine.Close()
 		}
-		close(stopCh)
-	}()
+	}
+	server := apiDataPkg.New(user, logger, avatarCfg, methods, pagetokenPkg.New(config.PageTokenConfig(), clock.Real()),
+		config.ResponseSizeConfig(), clock.Real())
+	verifier := verifyPkg.New(data, logger)
+	// deletes and mass updates of callers are held for approval, internal jobs and replication are not
+	callers, approval := user, approvalPkg.Interface(nil)
+	if cfg := config.ApprovalConfig(); cfg.Enabled {
+		callers, approval = approvalPkg.New(cfg, user, client, logger)
+	}
+	// the filter is exported to new replicas
+	names, _ := data.(bloomRepoPkg.Exporter)
+	var costs *costPkg.Budget
+	if costBudget.Enabled {
+		costs = costPkg.New(costBudget, clock.Real(), logger)
+	}
+	// calls are counted by every replica and summed in the store
+	var (
+		tracker *usagePkg.Tracker
+		usage   usagePkg.Interface
+	)
+	if cfg := config.UsageConfig(); cfg.Enabled {
+		tracker = usagePkg.New(usageStore, cfg, clock.Real(), logger)
+		usage = tracker
+	}
+	var generator fakePkg.Interface
+	if cfg := config.GenerateConfig(); cfg.Enabled {
+		generator = fakePkg.New(cfg, user, logger)
+	}
+	admin := apiAdminPkg.New(denylist, reindex, backup, callers, maintenance, verifier, anomaly, approval, tenants, names, usage,
+		generator, config.ResponseSizeConfig(), logger)
+	var conflicts apiReplicaPkg.Conflicts
+	if replicator != nil {
+		conflicts = replicator
+	}
+	replica := apiReplicaPkg.New(user, data, conflicts, logger)
 
-	select {
-	case <-ctx.Done():
-	case <-stopCh:
+	elector := leaderPkg.New(client, config.LeaderConfig(), logger)
+	consumer := metricsPkg.New(consts.GroupData, config.ConsumerConfig())
+
+	var ldapSync *ldapsyncPkg.Syncer
+	if cfg := config.LDAPSyncConfig(); cfg.Enabled {
+		ldapSync = ldapsyncPkg.New(cfg, user, normalizer, logger)
+	}
+
+	var seeder *seedPkg.Seeder
+	if seed.Enabled {
+		seeder = seedPkg.New(seed, user, normalizer, logger)
+	}
+
+	var pruner *historyPkg.Pruner
+	if retention := config.HistoryConfig().Retention; history != nil && retention.Enabled() {
+		pruner = historyPkg.NewPruner(history, retention, clock.Real(), logger)
+	}
+
+	// gauges are refreshed by every replica, so any replica answers a scrape
+	userStats := lifecyclePkg.Component{Name: "user stats"}
+	if cfg := config.UserStatsConfig(); cfg.Enabled {
+		collector := userstatsPkg.New(statsStore, cfg, clock.Real(), logger)
+		userStats.DependsOn = []string{"repo"}
+		userStats.Run = func(ctx context.Context) error {
+			collector.Run(ctx)
+			return nil
+		}
 	}
-	return retErr
+
+	usageReport := lifecyclePkg.Component{Name: "usage"}
+	if tracker != nil {
+		usageReport.DependsOn = []string{"repo"}
+		usageReport.Run = func(ctx context.Context) error {
+			tracker.Run(ctx)
+			return nil
+		}
+	}
+
+	var tombstonePruner *tombstonePkg.Pruner
+	if tombstones.Retention > 0 {
+		tombstonePruner = tombstonePkg.NewPruner(user, tombstones, clock.Real(), logger)
+	}
+
+	countReconciler := usercountPkg.NewReconciler(user, config.UserCountConfig(), clock.Real(), logger)
+
+	var cdc *cdcPkg.Listener
+	closeCDC := func() {}
+	if cfg := config.CDCConfig(); cfg.Enabled && !config.Local() {
+		pg := config.PGConfig()
+		pool, err := postgresPkg.NewPostgres(ctx, pg.Host, pg.Port, pg.User, pg.Password, pg.DBName, logger)
+		if err != nil {
+			return errors.Wrap(err, "new cdc postgres")
+		}
+		closeCDC = pool.Close
+		handlers := []cdcPkg.Handler{func(ctx context.Context, change cdcPkg.Change) error {
+			if change.Action == cdcPkg.ActionTruncate {
+				logger.Warnln("cdc: users table is truncated, cached users expire by TTL")
+			}
+			return user.Invalidate(ctx, change.Names()...)
+		}}
+		if names, ok := data.(bloomRepoPkg.Names); ok {
+			handlers = append(handlers, func(_ context.Context, change cdcPkg.Change) error {
+				if change.Name != "" {
+					names.AddName(change.Name)
+				}
+				return nil
+			})
+		}
+		cdc = cdcPkg.New(pool, cfg, logger, handlers...)
+	}
+
+	var reconcile *reconcilePkg.Scanner
+	if cfg := config.ReconcileConfig(); cfg.Enabled {
+		reconcile = reconcilePkg.New(cfg, client, codec, data, logger)
+	}
+
+	manager := lifecyclePkg.New(logger)
+	manager.Add(
+		lifecyclePkg.Component{
+			Name: "repo",
+			Stop: func(context.Context) error {
+				data.Close()
+				if history != nil {
+					history.Close()
+				}
+				closeMaintenance()
+				closeCDC()
+				return nil
+			},
+		},
+		lifecyclePkg.Component{
+			Name: "events",
+			Stop: func(ctx context.Context) error {
+				// queued events are delivered before the producer is closed
+				for _, queue := range queues {
+					if err := queue.Close(ctx); err != nil {
+						logger.Errorf("close events queue: %v", err)
+					}
+				}
+				for _, sink := range analytics {
+					if err := sink.Close(); err != nil {
+						logger.Errorf("close analytics sink: %v", err)
+					}
+				}
+				for _, sink := range security {
+					if err := sink.Close(); err != nil {
+						logger.Errorf("close security sink: %v", err)
+					}
+				}
+				if events == nil {
+					return nil
+				}
+				return events.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name: "redis",
+			Stop: func(context.Context) error {
+				return client.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name: "tracer",
+			Stop: func(context.Context) error {
+				return closer.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name: "grpc",
+			// calls are counted until the server is stopped, then the last counts are aggregated
+			DependsOn: []string{"repo", "redis", "tracer", "usage"},
+			Run: func(ctx context.Context) error {
+				return runGRPCServer(ctx, server, admin, replica, maintenance, methods, roles, config.ImpersonationConfig(), authorizer, grpcPkg.NewNormalizer(config.NormalizeConfig(), clock.Real()), usage, costs, config.GRPCDataAddr(), config.DebugToken(), config.AdminTokens(), logger)
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "consumer",
+			DependsOn: []string{"repo", "redis", "tracer"},
+			Run: func(ctx context.Context) error {
+				return runService(ctx, config.Brokers(), consumer, logger, callers)
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "maintenance",
+			DependsOn: []string{"repo"},
+			Run: func(ctx context.Context) error {
+				// the mode is changed by any replica, others read it from the store
+				maintenance.Run(ctx, config.MaintenanceConfig().RefreshInterval)
+				return nil
+			},
+		},
+		authz,
+		userStats,
+		usageReport,
+		lifecyclePkg.Component{
+			Name:      "failover",
+			DependsOn: []string{"repo"},
+			Run: func(ctx context.Context) error {
+				var wg sync.WaitGroup
+				for _, f := range failovers {
+					wg.Add(1)
+					go func(f *postgresPkg.Failover) {
+						defer wg.Done()
+						f.Run(ctx)
+					}(f)
+				}
+				wg.Wait()
+				return nil
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "tenants",
+			DependsOn: []string{"repo"},
+			Run: func(ctx context.Context) error {
+				// overrides are changed by any replica, others read them from the store
+				tenants.Run(ctx, config.TenantConfig().RefreshInterval)
+				return nil
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "leader",
+			DependsOn: []string{"repo", "redis"},
+			Run: func(ctx context.Context) error {
+				elector.Run(ctx, func(ctx context.Context) {
+					// seeding is done before other jobs, the empty store is seeded by one replica only
+					if seeder != nil {
+						if _, err := seeder.Run(ctx); err != nil && ctx.Err() == nil {
+							logger.Errorf("seed users: %v", err)
+						}
+					}
+					if replicator != nil && config.ReplicationConfig().CatchUpOnStart {
+						replicator.CatchUp()
+					}
+					var wg sync.WaitGroup
+					if ldapSync != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							ldapSync.Run(ctx)
+						}()
+					}
+					if reconcile != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							reconcile.Run(ctx)
+						}()
+					}
+					if pruner != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							pruner.Run(ctx)
+						}()
+					}
+					if cdc != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							cdc.Run(ctx)
+						}()
+					}
+					if tombstonePruner != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							tombstonePruner.Run(ctx)
+						}()
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						countReconciler.Run(ctx)
+					}()
+					if verify := config.VerifyConfig(); verify.OnStartup {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							if _, err := verifier.Verify(ctx, verify.Repair); err != nil && ctx.Err() == nil {
+								logger.Errorf("verify users: %v", err)
+							}
+						}()
+					}
+					if sharded != nil {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							sharded.Run(ctx)
+						}()
+					}
+					runSingletons(ctx, user, config.ListCacheTTL(), config.ListWarmupLimit(), logger)
+					wg.Wait()
+				})
+				return nil
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "http",
+			DependsOn: []string{"leader"},
+			Run: func(ctx context.Context) error {
+				return runHTTPServer(ctx, config.HTTPDataAddr(), elector, pools, ldapSync, reconcile, replicator, consumer, maintenance, logger)
+			},
+		},
+	)
+	return manager.Run(ctx)
 }
 
-func runGRPCServer(ctx context.Context, server pb.UserServer, grpcSrv string, logger *zap.SugaredLogger) (retErr error) {
+func runGRPCServer(
+	ctx context.Context,
+	server pb.UserServer,
+	admin pb.AdminServer,
+	replica pb.ReplicaServer,
+	maintenance *maintenancePkg.Mode,
+	methods *grpcPkg.Methods,
+	roles *grpcPkg.Fields,
+	impersonation grpcPkg.ImpersonationConfig,
+	authz grpcPkg.Authorizer,
+	normalizer *grpcPkg.Normalizer,
+	usage usagePkg.Interface,
+	costs *costPkg.Budget,
+	grpcSrv string,
+	debugToken string,
+	adminTokens []string,
+	logger *zap.SugaredLogger,
+) (retErr error) {
 	listener, err := net.Listen("tcp", grpcSrv)
 	if err != nil {
 		log.Fatalln("Listener create:", err)
 	}
 
+	unary := []grpc.UnaryServerInterceptor{
+		grpcOpentracing.UnaryServerInterceptor(),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		grpcOpentracing.StreamServerInterceptor(),
+	}
+	if usage != nil {
+		// before the authentication, so rejected calls are counted too
+		unary = append(unary, usagePkg.UnaryInterceptor(usage))
+		stream = append(stream, usagePkg.StreamInterceptor(usage))
+	}
+	if costs != nil {
+		unary = append(unary, costPkg.UnaryInterceptor(costs))
+		stream = append(stream, costPkg.StreamInterceptor(costs))
+	}
+	unary = append(unary,
+		grpcPkg.AdminAuthUnaryInterceptor(adminTokens),
+		grpcPkg.MethodsUnaryInterceptor(methods),
+		grpcPkg.ImpersonationUnaryInterceptor(impersonation, roles),
+		grpcPkg.NormalizeUnaryInterceptor(normalizer),
+	)
+	stream = append(stream,
+		grpcPkg.AdminAuthStreamInterceptor(adminTokens),
+		grpcPkg.MethodsStreamInterceptor(methods),
+		grpcPkg.ImpersonationStreamInterceptor(impersonation, roles),
+		grpcPkg.NormalizeStreamInterceptor(normalizer),
+	)
+	if authz != nil {
+		unary = append(unary, grpcPkg.AuthzUnaryInterceptor(authz))
+		stream = append(stream, grpcPkg.AuthzStreamInterceptor(authz))
+	}
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(grpcOpentracing.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(grpcOpentracing.StreamServerInterceptor()),
+		grpc.ChainUnaryInterceptor(append(unary, grpcPkg.ValidateUnaryInterceptor(), grpcPkg.DebugUnaryInterceptor(debugToken))...),
+		grpc.ChainStreamInterceptor(append(stream, grpcPkg.ValidateStreamInterceptor())...),
 	)
 	pb.RegisterUserServer(grpcServer, server)
+	pb.RegisterAdminServer(grpcServer, admin)
+	pb.RegisterReplicaServer(grpcServer, replica)
+	// clients with health checking stop sending calls before the server is stopped
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	// reads are served in maintenance mode, so only the maintenance service is not serving
+	maintenance.Watch(func(state maintenancePkg.State) {
+		serving := healthpb.HealthCheckResponse_SERVING
+		if state.Enabled {
+			serving = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus(maintenancePkg.HealthService, serving)
+	})
 
 	logger.Infoln("Start gRPC")
 
@@ -152,13 +845,62 @@ func runGRPCServer(ctx context.Context, server pb.UserServer, grpcSrv string, lo
 	select {
 	case <-stopCh:
 	case <-ctx.Done():
+		healthServer.Shutdown()
 		grpcServer.Stop()
 	}
 	logger.Infoln("gRPC stopped")
 	return
 }
 
-func runService(ctx context.Context, brokers []string, logger *zap.SugaredLogger, user userPkg.Interface) error {
+// bootstrapFromTopic restores users of the compacted events topic into the empty store.
+func bootstrapFromTopic(
+	ctx context.Context,
+	brokers []string,
+	cfg eventsPkg.Config,
+	user userPkg.Interface,
+	logger *zap.SugaredLogger,
+) error {
+	if cfg.KafkaTopic == "" {
+		return errors.New("bootstrap: events topic is not configured")
+	}
+	if !cfg.IncludePasswordHash {
+		logger.Warnln("Events are published without password hashes, passwords of bootstrapped users must be reset")
+	}
+	stored, err := user.List(ctx, false, 1, 0, nil, "")
+	if err != nil {
+		return errors.Wrap(err, "bootstrap: store check")
+	}
+	if len(stored) != 0 {
+		return errors.New("bootstrap: store is not empty")
+	}
+
+	client, err := sarama.NewClient(brokers, sarama.NewConfig())
+	if err != nil {
+		return errors.Wrap(err, "bootstrap: new client")
+	}
+	defer client.Close()
+
+	snapshot, err := eventsPkg.ReadTopic(ctx, client, cfg.KafkaTopic)
+	if err != nil {
+		return errors.Wrap(err, "bootstrap")
+	}
+	users := snapshot.Users()
+	for _, u := range users {
+		if _, err = user.Restore(ctx, u, false); err != nil {
+			return errors.Wrapf(err, "bootstrap: user [%s]", u.Name)
+		}
+	}
+	logger.Infow("Store bootstrapped from topic", "topic", cfg.KafkaTopic, "users", len(users))
+	return nil
+}
+
+func runService(
+	ctx context.Context,
+	brokers []string,
+	consumer *metricsPkg.Consumer,
+	logger *zap.SugaredLogger,
+	user userPkg.Interface,
+) error {
 	cfg := sarama.NewConfig()
 	cfg.Producer.Return.Successes = true
 	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
@@ -173,7 +915,7 @@ func runService(ctx context.Context, brokers []string, logger *zap.SugaredLogger
 		return errors.Wrap(err, "new ConsumerGroup")
 	}
 
-	handler := dataPkg.NewHandler(user, logger, producer)
+	handler := dataPkg.NewHandler(user, logger, producer, consumer)
 
 	go func() {
 		for {
@@ -188,11 +930,150 @@ func runService(ctx context.Context, brokers []string, logger *zap.SugaredLogger
 	return income.Close()
 }
 
-func runHTTPServer(ctx context.Context, httpSrv string, logger *zap.SugaredLogger) (retErr error) {
+// runSingletons runs jobs, which must be run by exactly one replica, until ctx is done.
+func runSingletons(ctx context.Context, user userPkg.Interface, ttl time.Duration, limit uint64, logger *zap.SugaredLogger) {
+	if limit == 0 {
+		<-ctx.Done()
+		return
+	}
+	if ttl <= 0 {
+		ttl = 5 * time.Second
+	}
+	logger.Infoln("Start list cache warm-up")
+
+	// expired pages are cached again in half of TTL
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+	for {
+		for _, order := range []bool{false, true} {
+			if _, err := user.List(ctx, order, limit, 0, nil, ""); err != nil && ctx.Err() == nil {
+				logger.Errorf("list cache warm-up: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			logger.Infoln("List cache warm-up stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runHTTPServer(
+	ctx context.Context,
+	httpSrv string,
+	elector leaderPkg.Elector,
+	pools []*workerpoolPkg.Pool,
+	ldapSync *ldapsyncPkg.Syncer,
+	reconcile *reconcilePkg.Scanner,
+	replicator *replicateRepoPkg.Repo,
+	consumer *metricsPkg.Consumer,
+	maintenance *maintenancePkg.Mode,
+	logger *zap.SugaredLogger,
+) (retErr error) {
 	mux := http.NewServeMux()
 	mux.Handle("/counters", expvar.Handler())
+	// readiness fails, while the consumer lags behind the topic
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
+		if err := consumer.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	expvar.Publish("Responses capped by size", counter.ResponsesCapped)
 	expvar.Publish("Hit cache", counter.Hit)
 	expvar.Publish("Miss cache", counter.Miss)
+	expvar.Publish("Hit list cache", counter.ListHit)
+	expvar.Publish("Miss list cache", counter.ListMiss)
+	expvar.Publish("Bloom filter skip", counter.BloomSkip)
+	expvar.Publish("Bloom filter rebuild", counter.BloomRebuild)
+	expvar.Publish("Bloom filter loaded", counter.BloomLoaded)
+	expvar.Publish("Bloom filter load failed", counter.BloomLoadFailed)
+	expvar.Publish("Hit rate list cache", expvar.Func(func() interface{} {
+		return counter.Ratio(counter.ListHit, counter.ListMiss)
+	}))
+	expvar.Publish("Hit repo cache", counter.RepoCacheHit)
+	expvar.Publish("Miss repo cache", counter.RepoCacheMiss)
+	expvar.Publish("Repo gets collapsed", counter.RepoGetsCollapsed)
+	expvar.Publish("Slow repo operations", counter.SlowOps)
+	expvar.Publish("Repo latency", counter.RepoLatency)
+	expvar.Publish("Repo errors", counter.RepoErrors)
+	expvar.Publish("Repo failovers", counter.RepoFailovers)
+	expvar.Publish("Repo reconnects failed", counter.RepoReconnectsFailed)
+	expvar.Publish("Scan budget lists", counter.ScanBudget)
+	expvar.Publish("Request cost units", counter.RequestCost)
+	expvar.Publish("Cost budget exceeded", counter.CostBudgetExceeded)
+	expvar.Publish("Canary repo compared", counter.CanaryCompared)
+	expvar.Publish("Canary repo mismatch", counter.CanaryMismatch)
+	expvar.Publish("Verify violations", counter.VerifyViolations)
+	expvar.Publish("Anomaly alerts", counter.AnomalyAlerts)
+	expvar.Publish("Anomaly alerts active", counter.AnomalyActive)
+	expvar.Publish("Authorization decisions", counter.AuthzDecisions)
+	expvar.Publish("Users", counter.UsersTotal)
+	expvar.Publish("Users by tenant", counter.UsersByTenant)
+	expvar.Publish("Users by status", counter.UsersByStatus)
+	expvar.Publish("Local cache entries", counter.LocalEntries)
+	expvar.Publish("Local cache memory bytes", counter.LocalMemory)
+	expvar.Publish("Local cache evictions", counter.LocalEvictions)
+	expvar.Publish("Local cache resize", counter.LocalResize)
+	expvar.Publish("Local cache lock wait", counter.LocalLockWait)
+	expvar.Publish("Leader", expvar.Func(func() interface{} {
+		return elector.IsLeader()
+	}))
+	expvar.Publish("Worker pools", expvar.Func(func() interface{} {
+		stats := make(map[string]workerpoolPkg.Stats, len(pools))
+		for _, pool := range pools {
+			stats[pool.Name()] = pool.Stats()
+		}
+		return stats
+	}))
+	if ldapSync != nil {
+		expvar.Publish("LDAP sync", expvar.Func(func() interface{} {
+			return ldapSync.Last()
+		}))
+	}
+	expvar.Publish("Events dropped", counter.EventsDropped)
+	expvar.Publish("Webhook events sent", counter.WebhookSent)
+	expvar.Publish("Webhook events failed", counter.WebhookFailed)
+	expvar.Publish("History pruned events", counter.HistoryPruned)
+	expvar.Publish("Tombstones pruned", counter.TombstonesPruned)
+	expvar.Publish("User counts corrected", counter.UserCountsCorrected)
+	expvar.Publish("Users generated", counter.UsersGenerated)
+	expvar.Publish("CDC applied changes", counter.CDCApplied)
+	expvar.Publish("Shard moved users", counter.ShardMoved)
+	expvar.Publish("Shard skipped lists", counter.ShardSkipped)
+	expvar.Publish("Replica mutations sent", counter.ReplicaSent)
+	expvar.Publish("Replica mutations dropped", counter.ReplicaDropped)
+	expvar.Publish("Replica conflicts", counter.ReplicaConflicts)
+	if replicator != nil {
+		expvar.Publish("Replication", expvar.Func(func() interface{} {
+			return replicator.Stats()
+		}))
+	}
+	expvar.Publish("Consumer processed messages", counter.ConsumerProcessed)
+	expvar.Publish("Consumer failed messages", counter.ConsumerFailed)
+	expvar.Publish("Failure rate consumer", expvar.Func(func() interface{} {
+		return counter.Rate(counter.ConsumerFailed, counter.ConsumerProcessed)
+	}))
+	expvar.Publish("Consumer rebalances", counter.ConsumerRebalances)
+	expvar.Publish("Consumer processing latency", counter.ConsumerLatency)
+	expvar.Publish("Consumer partitions", expvar.Func(func() interface{} {
+		return consumer.Partitions()
+	}))
+	expvar.Publish("Maintenance", expvar.Func(func() interface{} {
+		return maintenance.State()
+	}))
+	expvar.Publish("Reconcile cache sampled", counter.CacheSampled)
+	expvar.Publish("Reconcile cache diverged", counter.CacheDiverged)
+	expvar.Publish("Divergence rate cache", expvar.Func(func() interface{} {
+		return counter.Rate(counter.CacheDiverged, counter.CacheSampled)
+	}))
+	if reconcile != nil {
+		expvar.Publish("Reconcile cache", expvar.Func(func() interface{} {
+			return reconcile.Last()
+		}))
+	}
 
 	srv := http.Server{
 		Addr:    httpSrv,
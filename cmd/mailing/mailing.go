@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -16,12 +17,16 @@ import (
 	configPkg "gitlab.ozon.dev/iTukaev/homework/internal/config"
 	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
+	selfcheckPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/selfcheck"
 	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
 	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
 )
 
 func main() {
+	check := flag.Bool("check", false, "probe dependencies, log readiness report and exit")
+	flag.Parse()
+
 	config, err := yamlPkg.New()
 	if err != nil {
 		log.Fatalln("Config init error:", err)
@@ -38,6 +43,16 @@ func main() {
 		cancel()
 	}()
 
+	if _, err = selfcheckPkg.Run(ctx, logger,
+		selfcheckPkg.Kafka(config.Brokers()),
+		selfcheckPkg.Redis(config.RedisConfig()),
+	); err != nil {
+		logger.Fatalln(err)
+	}
+	if *check {
+		return
+	}
+
 	tracer, closer, err := jaegerPkg.New(config.JService(), config.JHost())
 	if err != nil {
 		logger.Errorf("Jaeger initialise err: %v", err)
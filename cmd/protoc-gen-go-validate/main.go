@@ -0,0 +1,241 @@
+// protoc-gen-go-validate generates Validate methods of messages by the validate.rules field options
+// and wrappers of servers, which validate requests of unary methods before the handlers.
+// Only files importing validate/validate.proto are generated.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/api/validate"
+)
+
+const rulesFile = "validate/validate.proto"
+
+var (
+	contextPackage  = protogen.GoImportPath("context")
+	regexpPackage   = protogen.GoImportPath("regexp")
+	utf8Package     = protogen.GoImportPath("unicode/utf8")
+	validatePackage = protogen.GoImportPath("gitlab.ozon.dev/iTukaev/homework/pkg/api/validate")
+)
+
+func main() {
+	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
+		for _, f := range gen.Files {
+			if !f.Generate || !validated(f.Desc) {
+				continue
+			}
+			if err := generateFile(gen, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// validated reports whether messages of the file have Validate methods.
+func validated(file protoreflect.FileDescriptor) bool {
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if imports.Get(i).Path() == rulesFile {
+			return true
+		}
+	}
+	return false
+}
+
+func generateFile(gen *protogen.Plugin, f *protogen.File) error {
+	g := gen.NewGeneratedFile(f.GeneratedFilenamePrefix+".pb.validate.go", f.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-validate. DO NOT EDIT.")
+	g.P("// source: ", f.Desc.Path())
+	g.P()
+	g.P("package ", f.GoPackageName)
+	g.P()
+
+	for _, message := range messages(f.Messages) {
+		if err := generateMessage(g, message); err != nil {
+			return err
+		}
+	}
+	for _, service := range f.Services {
+		generateService(g, service)
+	}
+	return nil
+}
+
+// messages returns the messages with their nested ones, map entries are left out.
+func messages(list []*protogen.Message) []*protogen.Message {
+	var all []*protogen.Message
+	for _, message := range list {
+		if message.Desc.IsMapEntry() {
+			continue
+		}
+		all = append(all, message)
+		all = append(all, messages(message.Messages)...)
+	}
+	return all
+}
+
+func generateMessage(g *protogen.GeneratedFile, message *protogen.Message) error {
+	name := message.GoIdent.GoName
+	var patterns []string
+	g.P("// Validate checks the field rules of ", name, ".")
+	g.P("func (x *", name, ") Validate() error {")
+	g.P("if x == nil {")
+	g.P("return nil")
+	g.P("}")
+	for _, field := range message.Fields {
+		rules, ok := proto.GetExtension(field.Desc.Options(), validate.E_Rules).(*validate.FieldRules)
+		if !ok || rules == nil {
+			continue
+		}
+		pattern, err := generateField(g, message, field, rules)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Desc.FullName(), err)
+		}
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	g.P("return nil")
+	g.P("}")
+	g.P()
+	for _, pattern := range patterns {
+		g.P(pattern)
+	}
+	if len(patterns) != 0 {
+		g.P()
+	}
+	return nil
+}
+
+// generateField writes checks of the field and returns declaration of its pattern, if it has one.
+func generateField(g *protogen.GeneratedFile, message *protogen.Message, field *protogen.Field, rules *validate.FieldRules) (string, error) {
+	violation := func(reason string) {
+		g.P("return &", g.QualifiedGoIdent(validatePackage.Ident("Error")), "{Field: ",
+			strconv.Quote(string(field.Desc.Name())), ", Reason: ", strconv.Quote(reason), "}")
+	}
+	value := "x.Get" + field.GoName + "()"
+
+	switch {
+	case field.Desc.IsList() || field.Desc.IsMap():
+		if rules.MinLen != 0 || rules.MaxLen != 0 || rules.Pattern != "" || rules.Nested {
+			return "", fmt.Errorf("lists and maps have required and max_items rules only")
+		}
+		if rules.Required {
+			g.P("if len(", value, ") == 0 {")
+			violation("is required")
+			g.P("}")
+		}
+		if rules.MaxItems != 0 {
+			g.P("if len(", value, ") > ", rules.MaxItems, " {")
+			violation(fmt.Sprintf("must have at most %d items", rules.MaxItems))
+			g.P("}")
+		}
+		return "", nil
+
+	case field.Desc.Kind() == protoreflect.MessageKind:
+		if rules.MinLen != 0 || rules.MaxLen != 0 || rules.Pattern != "" || rules.MaxItems != 0 {
+			return "", fmt.Errorf("messages have required and nested rules only")
+		}
+		if rules.Nested && !validated(field.Message.Desc.ParentFile()) {
+			return "", fmt.Errorf("nested message %s has no Validate method", field.Message.Desc.FullName())
+		}
+		if rules.Required {
+			g.P("if ", value, " == nil {")
+			violation("is required")
+			g.P("}")
+		}
+		if rules.Nested {
+			g.P("if err := ", g.QualifiedGoIdent(validatePackage.Ident("Nested")), "(",
+				strconv.Quote(string(field.Desc.Name())), ", ", value, "); err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
+		return "", nil
+
+	case field.Desc.Kind() == protoreflect.StringKind:
+		if rules.MaxItems != 0 || rules.Nested {
+			return "", fmt.Errorf("strings have required, min_len, max_len and pattern rules only")
+		}
+		// rules of optional fields are checked, if the field is set
+		if field.Desc.HasOptionalKeyword() {
+			g.P("if x.", field.GoName, " != nil {")
+			defer g.P("}")
+		}
+		if rules.Required {
+			g.P("if ", value, ` == "" {`)
+			violation("is required")
+			g.P("}")
+		}
+		if rules.MinLen != 0 {
+			g.P("if n := ", g.QualifiedGoIdent(utf8Package.Ident("RuneCountInString")), "(", value, `); n != 0 && n < `, rules.MinLen, " {")
+			violation(fmt.Sprintf("must be at least %d characters", rules.MinLen))
+			g.P("}")
+		}
+		if rules.MaxLen != 0 {
+			g.P("if ", g.QualifiedGoIdent(utf8Package.Ident("RuneCountInString")), "(", value, ") > ", rules.MaxLen, " {")
+			violation(fmt.Sprintf("must be at most %d characters", rules.MaxLen))
+			g.P("}")
+		}
+		if rules.Pattern == "" {
+			return "", nil
+		}
+		if _, err := regexp.Compile(rules.Pattern); err != nil {
+			return "", fmt.Errorf("pattern: %w", err)
+		}
+		pattern := "_" + message.GoIdent.GoName + "_" + field.GoName + "_Pattern"
+		g.P("if v := ", value, `; v != "" && !`, pattern, ".MatchString(v) {")
+		violation("must match " + rules.Pattern)
+		g.P("}")
+		return "var " + pattern + " = " + g.QualifiedGoIdent(regexpPackage.Ident("MustCompile")) + "(" +
+			strconv.Quote(rules.Pattern) + ")", nil
+
+	case field.Desc.Kind() == protoreflect.BoolKind || field.Desc.Kind() == protoreflect.BytesKind:
+		return "", fmt.Errorf("%s fields have no rules", field.Desc.Kind())
+
+	default:
+		if rules.MinLen != 0 || rules.MaxLen != 0 || rules.Pattern != "" || rules.MaxItems != 0 || rules.Nested {
+			return "", fmt.Errorf("numbers have required rule only")
+		}
+		if rules.Required {
+			g.P("if ", value, " == 0 {")
+			violation("is required")
+			g.P("}")
+		}
+		return "", nil
+	}
+}
+
+func generateService(g *protogen.GeneratedFile, service *protogen.Service) {
+	server := service.GoName + "Server"
+	wrapper := "validate" + server
+	g.P("// Validate", server, " validates requests of unary methods before the server is called, it is used")
+	g.P("// by servers called directly, e.g. by the HTTP gateway, which does not run interceptors.")
+	g.P("func Validate", server, "(server ", server, ") ", server, " {")
+	g.P("return &", wrapper, "{", server, ": server}")
+	g.P("}")
+	g.P()
+	g.P("type ", wrapper, " struct {")
+	g.P(server)
+	g.P("}")
+	g.P()
+	for _, method := range service.Methods {
+		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() || !validated(method.Input.Desc.ParentFile()) {
+			continue
+		}
+		g.P("func (s *", wrapper, ") ", method.GoName, "(ctx ", g.QualifiedGoIdent(contextPackage.Ident("Context")),
+			", in *", g.QualifiedGoIdent(method.Input.GoIdent), ") (*", g.QualifiedGoIdent(method.Output.GoIdent), ", error) {")
+		g.P("if err := in.Validate(); err != nil {")
+		g.P("return nil, ", g.QualifiedGoIdent(validatePackage.Ident("Status")), "(err)")
+		g.P("}")
+		g.P("return s.", server, ".", method.GoName, "(ctx, in)")
+		g.P("}")
+		g.P()
+	}
+}
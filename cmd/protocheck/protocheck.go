@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/protocheck"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+// files of the service API, imported third party protos are not checked
+var files = []protoreflect.FileDescriptor{
+	pb.File_api_proto,
+	pbModels.File_models_user_proto,
+}
+
+func main() {
+	changelogPath := flag.String("changelog", "CHANGELOG.md", "changelog with the last released version")
+	releasedDir := flag.String("released", "api/released", "directory of released descriptor sets")
+	release := flag.Bool("release", false, "save current descriptors as the last released version")
+	flag.Parse()
+
+	changelog, err := readChangelog(*changelogPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	releasedPath := filepath.Join(*releasedDir, changelog.Released+".binpb")
+	current := currentSet()
+
+	if *release {
+		if err = writeSet(releasedPath, current); err != nil {
+			log.Fatalln(err)
+		}
+		log.Printf("descriptors of %s saved to %s\n", changelog.Released, releasedPath)
+		return
+	}
+
+	released, err := readSet(releasedPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	changes := protocheck.Compare(released, current)
+	unaccepted := changelog.Unaccepted(changes)
+	for _, change := range changes {
+		log.Println("breaking change:", change)
+	}
+	if len(unaccepted) != 0 {
+		log.Fatalf("%d breaking changes against %s are not listed in the Breaking section of %s\n",
+			len(unaccepted), changelog.Released, *changelogPath)
+	}
+	log.Printf("API is compatible with %s\n", changelog.Released)
+}
+
+func readChangelog(path string) (protocheck.Changelog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return protocheck.Changelog{}, errors.Wrap(err, "changelog open")
+	}
+	defer file.Close()
+	return protocheck.ParseChangelog(file)
+}
+
+func currentSet() *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+	}
+	return set
+}
+
+func readSet(path string) (*descriptorpb.FileDescriptorSet, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Errorf("released descriptors %s not found, save them with -release", path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "released descriptors read")
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, set); err != nil {
+		return nil, errors.Wrap(err, "released descriptors unmarshal")
+	}
+	return set, nil
+}
+
+func writeSet(path string, set *descriptorpb.FileDescriptorSet) error {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(set)
+	if err != nil {
+		return errors.Wrap(err, "descriptors marshal")
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "released directory")
+	}
+	return errors.Wrap(os.WriteFile(path, data, 0o644), "descriptors write")
+}
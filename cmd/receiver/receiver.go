@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"expvar"
+	"flag"
 	"log"
 	"net"
 	"net/http"
@@ -20,10 +21,14 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/encoding/protojson"
 
+	apiExportPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/export"
+	apiOidcPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/oidc"
+	apiPushPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/push"
 	apiReceiverPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/receiver"
 	configPkg "gitlab.ozon.dev/iTukaev/homework/internal/config"
 	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
 	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	authzPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/authz"
 	botPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot"
 	cmdAddPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command/add"
 	cmdDeletePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command/delete"
@@ -31,13 +36,22 @@ import (
 	cmdHelpPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command/help"
 	cmdListPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command/list"
 	cmdUpdatePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command/update"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	selfcheckPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/selfcheck"
 	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
 	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
 	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+	lifecyclePkg "gitlab.ozon.dev/iTukaev/homework/pkg/lifecycle"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	oidcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/oidc"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 )
 
 func main() {
+	check := flag.Bool("check", false, "probe dependencies, log readiness report and exit")
+	flag.Parse()
+
 	config, err := yamlPkg.New()
 	if err != nil {
 		log.Fatalln("Config init error:", err)
@@ -48,42 +62,42 @@ func main() {
 	}
 	logger.Infoln("Start main")
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer func() {
-		logger.Infoln("Shutting down...")
-		_ = logger.Sync()
-		cancel()
-	}()
-
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
 
-	go func() {
-		if err = start(ctx, config, logger); err != nil {
-			logger.Errorln(err)
-			c <- os.Interrupt
-		}
-	}()
+	if _, err = selfcheckPkg.Run(ctx, logger,
+		selfcheckPkg.Kafka(config.Brokers()),
+	); err != nil {
+		logger.Fatalln(err)
+	}
+	if *check {
+		return
+	}
 
-	select {
-	case <-c:
+	if err = start(ctx, config, logger); err != nil {
+		logger.Errorln(err)
 	}
+	_ = logger.Sync()
 }
 
-func start(ctx context.Context, config configPkg.Interface, logger *zap.SugaredLogger) (retErr error) {
-	tracer, cancel, err := jaegerPkg.New(config.JService(), config.JHost())
+func start(ctx context.Context, config configPkg.Interface, logger *zap.SugaredLogger) error {
+	tracer, closer, err := jaegerPkg.New(config.JService(), config.JHost())
 	if err != nil {
-		logger.Errorf("Jaeger initialise err: %v", err)
-		return
+		return errors.Wrap(err, "jaeger initialise")
 	}
-	defer func() {
-		_ = cancel.Close()
-	}()
 	opentracing.SetGlobalTracer(tracer)
 
-	conn, err := grpc.Dial(config.GRPCDataAddr(),
+	interceptors := []grpc.UnaryClientInterceptor{
+		otgrpc.OpenTracingClientInterceptor(tracer),
+		grpcPkg.DebugForwardUnaryInterceptor,
+		grpcPkg.SessionForwardUnaryInterceptor,
+	}
+	if hedge := config.HedgeConfig(); hedge.Enabled {
+		interceptors = append(interceptors, grpcPkg.HedgeUnaryInterceptor(hedge))
+	}
+	conn, err := grpcPkg.Dial(config.GRPCDataAddr(), config.BalancerConfig(),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithUnaryInterceptor(otgrpc.OpenTracingClientInterceptor(tracer)),
+		grpc.WithChainUnaryInterceptor(interceptors...),
 		grpc.WithStreamInterceptor(otgrpc.OpenTracingStreamClientInterceptor(tracer)),
 	)
 	if err != nil {
@@ -99,33 +113,120 @@ func start(ctx context.Context, config configPkg.Interface, logger *zap.SugaredL
 		return errors.Wrap(err, "new SyncProducer")
 	}
 
-	server := apiReceiverPkg.New(client, logger, producer)
+	// mutations are rejected by the receiver too, while the data service is in maintenance
+	maintenance := maintenancePkg.New(maintenancePkg.NewRemote(pb.NewAdminClient(conn)), logger)
+	if err = maintenance.Refresh(ctx); err != nil {
+		logger.Errorln(err)
+	}
 
-	stopCh := make(chan struct{}, 0)
-	go func() {
-		if err = runGRPCServer(ctx, server, config.GRPCAddr(), logger); err != nil {
-			retErr = errors.Wrap(err, "gRPC server")
+	methods := grpcPkg.NewMethods(config.MethodsConfig(), pb.User_ServiceDesc)
+	fields, err := grpcPkg.NewFields(config.FieldsConfig())
+	if err != nil {
+		return errors.Wrap(err, "fields policy")
+	}
+	// calls are authorized by the policies after the impersonation, so both identities are known
+	var (
+		authorizer grpcPkg.Authorizer
+		authz      = lifecyclePkg.Component{Name: "authz"}
+	)
+	if cfg := config.AuthzConfig(); cfg.Enabled {
+		engine, err := authzPkg.New(cfg, fields, logger)
+		if err != nil {
+			return errors.Wrap(err, "authz policies")
 		}
-		close(stopCh)
-	}()
-	go func() {
-		if err = runHTTPServer(ctx, server, config.HTTPAddr(), logger); err != nil {
-			retErr = errors.Wrap(err, "HTTP server")
+		authorizer = engine
+		authz.Run = func(ctx context.Context) error {
+			engine.Run(ctx)
+			return nil
 		}
-		close(stopCh)
-	}()
-	go func() {
-		if err = runBot(ctx, client, config.BotKey(), logger); err != nil {
-			retErr = errors.Wrap(err, "tg bot")
+		authz.Stop = func(context.Context) error {
+			return engine.Close()
 		}
-		close(stopCh)
-	}()
+	}
+	server := apiReceiverPkg.New(client, logger, producer, maintenance, methods)
 
-	select {
-	case <-ctx.Done():
-	case <-stopCh:
+	pages := pagetokenPkg.New(config.PageTokenConfig(), clock.Real())
+	normalizer := grpcPkg.NewNormalizer(config.NormalizeConfig(), clock.Real())
+	var dedup *grpcPkg.Dedup
+	if cfg := config.DedupConfig(); cfg.Enabled {
+		dedup = grpcPkg.NewDedup(cfg, clock.Real())
 	}
-	return retErr
+	var limiter *grpcPkg.RateLimiter
+	if cfg := config.RateLimitConfig(); cfg.Enabled {
+		limiter = grpcPkg.NewRateLimiter(cfg, clock.Real())
+	}
+	unary, stream := serverInterceptors(methods, fields, config.ImpersonationConfig(), authorizer, normalizer, dedup, limiter, config.ListQuotaConfig(), logger)
+	export := apiExportPkg.New(client, config.ExportConfig(), methods, fields, pages, logger)
+	push := apiPushPkg.New(client, config.PushConfig(), methods, fields, authorizer, logger)
+
+	var oidc *apiOidcPkg.Handler
+	if cfg := config.OIDCConfig(); cfg.Enabled {
+		oidc = apiOidcPkg.New(oidcPkg.New(cfg), client, cfg.RedirectURL, logger)
+	}
+
+	manager := lifecyclePkg.New(logger)
+	manager.Add(
+		lifecyclePkg.Component{
+			Name: "tracer",
+			Stop: func(context.Context) error {
+				return closer.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "data client",
+			DependsOn: []string{"tracer"},
+			Stop: func(context.Context) error {
+				return conn.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name: "producer",
+			Stop: func(context.Context) error {
+				return producer.Close()
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "maintenance",
+			DependsOn: []string{"data client"},
+			Run: func(ctx context.Context) error {
+				maintenance.Run(ctx, config.MaintenanceConfig().RefreshInterval)
+				return nil
+			},
+		},
+		authz,
+		lifecyclePkg.Component{
+			Name:      "grpc",
+			DependsOn: []string{"data client", "producer"},
+			Run: func(ctx context.Context) error {
+				return runGRPCServer(ctx, server, unary, stream, config.GRPCAddr(), logger)
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "events push",
+			DependsOn: []string{"data client"},
+			Run: func(ctx context.Context) error {
+				push.Run(ctx)
+				return nil
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "http",
+			DependsOn: []string{"grpc"},
+			Run: func(ctx context.Context) error {
+				// the gateway calls the server directly, so it runs the interceptors of the gRPC server by the adapter
+				gateway := grpcPkg.GatewayServer(server, unary...)
+				return runHTTPServer(ctx, gateway, config.ImpersonationConfig(), export, push, oidc, config.HTTPAddr(), logger)
+			},
+		},
+		lifecyclePkg.Component{
+			Name:      "bot",
+			DependsOn: []string{"data client"},
+			Run: func(ctx context.Context) error {
+				return runBot(ctx, client, config.BotKey(), logger)
+			},
+		},
+	)
+	return manager.Run(ctx)
 }
 
 func runBot(ctx context.Context, client pb.UserClient, apiKey string, logger *zap.SugaredLogger) error {
@@ -174,18 +275,80 @@ func runBot(ctx context.Context, client pb.UserClient, apiKey string, logger *za
 	return nil
 }
 
-func runGRPCServer(ctx context.Context, server pb.UserServer, grpcSrv string, logger *zap.SugaredLogger) (retErr error) {
+// serverInterceptors returns the interceptors of the gRPC server, unary ones are run for the HTTP gateway too,
+// so authorization, limits, metrics, request IDs and recovery are the same for both protocols.
+func serverInterceptors(
+	methods *grpcPkg.Methods,
+	fields *grpcPkg.Fields,
+	impersonation grpcPkg.ImpersonationConfig,
+	authz grpcPkg.Authorizer,
+	normalizer *grpcPkg.Normalizer,
+	dedup *grpcPkg.Dedup,
+	limiter *grpcPkg.RateLimiter,
+	quota grpcPkg.ListQuotaConfig,
+	logger *zap.SugaredLogger,
+) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	unary := []grpc.UnaryServerInterceptor{
+		grpcPkg.RequestIDUnaryInterceptor,
+		grpcPkg.MetricsUnaryInterceptor,
+		grpcOpentracing.UnaryServerInterceptor(),
+		grpcPkg.RecoveryUnaryInterceptor(logger),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		grpcPkg.RequestIDStreamInterceptor,
+		grpcPkg.MetricsStreamInterceptor,
+		grpcPkg.RecoveryStreamInterceptor(logger),
+	}
+	if limiter != nil {
+		unary = append(unary, grpcPkg.RateLimitUnaryInterceptor(limiter))
+		stream = append(stream, grpcPkg.RateLimitStreamInterceptor(limiter))
+	}
+	unary = append(unary,
+		grpcPkg.MethodsUnaryInterceptor(methods),
+		grpcPkg.ImpersonationUnaryInterceptor(impersonation, fields),
+		grpcPkg.NormalizeUnaryInterceptor(normalizer),
+	)
+	stream = append(stream,
+		grpcPkg.MethodsStreamInterceptor(methods),
+		grpcPkg.ImpersonationStreamInterceptor(impersonation, fields),
+		grpcPkg.NormalizeStreamInterceptor(normalizer),
+	)
+	if authz != nil {
+		unary = append(unary, grpcPkg.AuthzUnaryInterceptor(authz))
+		stream = append(stream, grpcPkg.AuthzStreamInterceptor(authz))
+	}
+	unary = append(unary, grpcPkg.ValidateUnaryInterceptor())
+	if dedup != nil {
+		// before fields, so duplicates share the response already filtered for the caller
+		unary = append(unary, grpcPkg.DedupUnaryInterceptor(dedup))
+	}
+	unary = append(unary,
+		grpcPkg.ListQuotaUnaryInterceptor(quota),
+		grpcPkg.FieldsUnaryInterceptor(fields),
+	)
+	stream = append(stream,
+		grpcPkg.ValidateStreamInterceptor(),
+		grpcPkg.FieldsStreamInterceptor(fields),
+	)
+	return unary, stream
+}
+
+func runGRPCServer(
+	ctx context.Context,
+	server pb.UserServer,
+	unary []grpc.UnaryServerInterceptor,
+	stream []grpc.StreamServerInterceptor,
+	grpcSrv string,
+	logger *zap.SugaredLogger,
+) (retErr error) {
 	listener, err := net.Listen("tcp", grpcSrv)
 	if err != nil {
 		return errors.Wrap(err, "listener")
 	}
 
 	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpcPkg.MetricsUnaryInterceptor,
-			grpcOpentracing.UnaryServerInterceptor(),
-		),
-		grpc.StreamInterceptor(grpcPkg.MetricsStreamInterceptor),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
 	pb.RegisterUserServer(grpcServer, server)
 
@@ -207,7 +370,16 @@ func runGRPCServer(ctx context.Context, server pb.UserServer, grpcSrv string, lo
 	return
 }
 
-func runHTTPServer(ctx context.Context, server pb.UserServer, httpSrv string, logger *zap.SugaredLogger) (retErr error) {
+func runHTTPServer(
+	ctx context.Context,
+	server pb.UserServer,
+	impersonation grpcPkg.ImpersonationConfig,
+	export *apiExportPkg.Handler,
+	push *apiPushPkg.Handler,
+	oidc *apiOidcPkg.Handler,
+	httpSrv string,
+	logger *zap.SugaredLogger,
+) (retErr error) {
 	gwMux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 			MarshalOptions: protojson.MarshalOptions{
@@ -217,19 +389,43 @@ func runHTTPServer(ctx context.Context, server pb.UserServer, httpSrv string, lo
 				DiscardUnknown: true,
 			},
 		}),
+		// fields are stripped by the interceptors, so ETag is a hash of the response seen by the caller
+		runtime.WithForwardResponseOption(grpcPkg.ETagForwardResponse),
+		runtime.WithErrorHandler(grpcPkg.RetryAfterErrorHandler),
+		runtime.WithOutgoingHeaderMatcher(grpcPkg.OutgoingHeaderMatcher),
 	)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", gwMux)
+	mux.Handle("/", grpcPkg.ETagHandler(gwMux))
 
 	fs := http.FileServer(http.Dir("./swagger"))
 	mux.Handle("/swagger/", http.StripPrefix("/swagger/", fs))
 
+	export.Register(mux)
+	push.Register(mux, impersonation)
+	if oidc != nil {
+		oidc.Register(mux)
+	}
+
 	mux.Handle("/counters", expvar.Handler())
 	expvar.Publish("Validation service request", counter.Request)
 	expvar.Publish("Validation service response", counter.Response)
 	expvar.Publish("Validation service success", counter.Success)
 	expvar.Publish("Validation service error", counter.Errors)
+	expvar.Publish("Hedged data calls", counter.HedgeCalls)
+	expvar.Publish("Hedged data calls won", counter.HedgeWins)
+	expvar.Publish("List quota truncated", counter.ListTruncated)
+	expvar.Publish("Duplicates suppressed", counter.DuplicatesSuppressed)
+	expvar.Publish("Not modified responses", counter.NotModified)
+	expvar.Publish("Impersonated requests", counter.Impersonations)
+	expvar.Publish("Impersonation denied", counter.ImpersonationDenied)
+	expvar.Publish("Authorization decisions", counter.AuthzDecisions)
+	expvar.Publish("Handler panics", counter.Panics)
+	expvar.Publish("Rate limited calls", counter.RateLimited)
+	expvar.Publish("Exported rows", counter.ExportedRows)
+	expvar.Publish("Events push connections", counter.PushConnections)
+	expvar.Publish("Events pushed", counter.PushEvents)
+	expvar.Publish("Events push slow consumers closed", counter.PushSlowClosed)
 
 	if err := pb.RegisterUserHandlerServer(ctx, gwMux, server); err != nil {
 		return errors.Wrap(err, "HTTP gateway register")
@@ -237,7 +433,7 @@ func runHTTPServer(ctx context.Context, server pb.UserServer, httpSrv string, lo
 
 	srv := http.Server{
 		Addr:    httpSrv,
-		Handler: mux,
+		Handler: grpcPkg.RequestIDHandler(mux),
 	}
 	logger.Infoln("Start HTTP gateway")
 	stopCh := make(chan struct{}, 0)
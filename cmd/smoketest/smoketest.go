@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+const (
+	pollInterval = 100 * time.Millisecond
+	// smokeAttribute marks users created by the smoke test
+	smokeAttribute = "smoke"
+)
+
+type step struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type scenario struct {
+	client   pb.UserClient
+	name     string
+	timeout  time.Duration
+	created  bool
+	fullName string
+}
+
+func main() {
+	addr := flag.String("addr", "", "receiver gRPC address, config value is used if empty")
+	prefix := flag.String("prefix", "smoke_", "prefix of the disposable user name")
+	timeout := flag.Duration("timeout", 10*time.Second, "max time to wait for a step result")
+	maxLatency := flag.Duration("max-latency", 3*time.Second, "max allowed latency of a step")
+	flag.Parse()
+
+	if *addr == "" {
+		config, err := yamlPkg.New()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		*addr = config.GRPCAddr()
+	}
+	conn, err := grpc.Dial(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer conn.Close()
+
+	s := &scenario{
+		client:  pb.NewUserClient(conn),
+		name:    fmt.Sprintf("%s%d", *prefix, time.Now().UnixNano()),
+		timeout: *timeout,
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "meta", "smoketest-"+s.name)
+
+	failed := s.run(ctx, *maxLatency)
+	s.cleanup(ctx)
+	if failed {
+		os.Exit(1)
+	}
+	log.Println("smoke test passed")
+}
+
+// run executes steps in order and stops on the first failure, it reports whether scenario failed.
+func (s *scenario) run(ctx context.Context, maxLatency time.Duration) bool {
+	steps := []step{
+		{name: "create", run: s.create},
+		{name: "get", run: s.get},
+		{name: "update", run: s.update},
+		{name: "list", run: s.list},
+		{name: "delete", run: s.delete},
+	}
+	for _, st := range steps {
+		start := time.Now()
+		err := st.run(ctx)
+		latency := time.Since(start)
+		if err == nil && latency > maxLatency {
+			err = errors.Errorf("latency %s exceeds %s", latency, maxLatency)
+		}
+		if err != nil {
+			log.Printf("FAIL %-6s %10s: %v\n", st.name, latency, err)
+			return true
+		}
+		log.Printf("OK   %-6s %10s\n", st.name, latency)
+	}
+	return false
+}
+
+func (s *scenario) create(ctx context.Context) error {
+	resp, err := s.client.UserCreate(ctx, &pb.UserCreateRequest{
+		User: &pbModels.User{
+			Name:       s.name,
+			Password:   "smoke",
+			Email:      s.name + "@smoke.test",
+			FullName:   "Smoke Test",
+			Attributes: map[string]string{smokeAttribute: s.name},
+		},
+		PubSub: pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = s.wait(ctx, resp.GetUid()); err != nil {
+		return err
+	}
+	s.created = true
+	return nil
+}
+
+func (s *scenario) get(ctx context.Context) error {
+	resp, err := s.client.UserGet(ctx, &pb.UserGetRequest{
+		Name:   s.name,
+		PubSub: pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	user, err := s.waitUser(ctx, resp.GetUid())
+	if err != nil {
+		return err
+	}
+	if user.Email != s.name+"@smoke.test" {
+		return errors.Errorf("unexpected email [%s]", user.Email)
+	}
+	return nil
+}
+
+func (s *scenario) update(ctx context.Context) error {
+	s.fullName = "Smoke Test Updated"
+	resp, err := s.client.UserUpdate(ctx, &pb.UserUpdateRequest{
+		Name: s.name,
+		Profile: &pbModels.Profile{
+			FullName: &s.fullName,
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{models.MaskFullName}},
+		PubSub:     pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = s.wait(ctx, resp.GetUid()); err != nil {
+		return err
+	}
+
+	get, err := s.client.UserGet(ctx, &pb.UserGetRequest{
+		Name:   s.name,
+		PubSub: pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	user, err := s.waitUser(ctx, get.GetUid())
+	if err != nil {
+		return err
+	}
+	if user.FullName != s.fullName {
+		return errors.Errorf("full name is [%s], expected [%s]", user.FullName, s.fullName)
+	}
+	return nil
+}
+
+func (s *scenario) list(ctx context.Context) error {
+	resp, err := s.client.UserList(ctx, &pb.UserListRequest{
+		Limit:      10,
+		Attributes: map[string]string{smokeAttribute: s.name},
+		PubSub:     pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := s.wait(ctx, resp.GetUid())
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err = json.Unmarshal(data, &users); err != nil {
+		return errors.Wrap(err, "unmarshal list")
+	}
+	if len(users) != 1 || users[0].Name != s.name {
+		return errors.Errorf("list contains %d users, expected [%s] only", len(users), s.name)
+	}
+	return nil
+}
+
+func (s *scenario) delete(ctx context.Context) error {
+	resp, err := s.client.UserDelete(ctx, &pb.UserDeleteRequest{
+		Name:   s.name,
+		PubSub: pb.Wait_cache,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = s.wait(ctx, resp.GetUid()); err != nil {
+		return err
+	}
+	s.created = false
+	return nil
+}
+
+// cleanup removes the user, if the scenario has failed before delete step.
+func (s *scenario) cleanup(ctx context.Context) {
+	if !s.created {
+		return
+	}
+	if err := s.delete(ctx); err != nil {
+		log.Printf("cleanup: user [%s] is not deleted: %v\n", s.name, err)
+		return
+	}
+	log.Printf("cleanup: user [%s] deleted\n", s.name)
+}
+
+// wait polls result of the request until it is ready or timeout is reached.
+func (s *scenario) wait(ctx context.Context, uid string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		resp, err := s.client.Data(ctx, &pb.DataRequest{Uid: uid})
+		if err == nil {
+			return resp.GetBody().GetValue(), nil
+		}
+		if status.Code(err) != codes.NotFound {
+			return nil, errors.Wrapf(err, "result [%s]", uid)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Errorf("result [%s] is not ready in %s", uid, s.timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scenario) waitUser(ctx context.Context, uid string) (models.User, error) {
+	data, err := s.wait(ctx, uid)
+	if err != nil {
+		return models.User{}, err
+	}
+	var user models.User
+	if err = json.Unmarshal(data, &user); err != nil {
+		return models.User{}, errors.Wrap(err, "unmarshal user")
+	}
+	return user, nil
+}
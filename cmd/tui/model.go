@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	bold  = "\033[1m"
+	reset = "\033[0m"
+)
+
+// sparks are sparkline levels from the lowest to the highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// polled is the message with results of a poll of the data service, failed sources keep
+// zero values and their errors.
+type polled struct {
+	at time.Time
+
+	count, total int64
+	countErr     error
+
+	// next is since of the following poll, zero keeps the current one
+	next         int64
+	mutations    []string
+	mutationsErr error
+
+	metrics    metrics
+	metricsErr error
+}
+
+// tick is the message starting the next poll.
+type tick struct{}
+
+// metrics are expvar variables of the data service used by the dashboard.
+type metrics struct {
+	Latency  map[string]histogram `json:"Repo latency"`
+	ByTenant map[string]int64     `json:"Users by tenant"`
+}
+
+// histogram is a part of the expvar histogram used by the dashboard.
+type histogram struct {
+	Count uint64  `json:"count"`
+	SumMs float64 `json:"sum_ms"`
+}
+
+// model is the dashboard, failed sources keep their previous values and their errors are shown.
+type model struct {
+	// poll reads the data service, mutations are changes since the time
+	poll     func(since int64) polled
+	interval time.Duration
+	tenant   string
+	width    int
+	limit    int
+
+	at    time.Time
+	since int64
+	total int64
+	count int64
+	// byTenant is "Users by tenant" of the metrics
+	byTenant  map[string]int64
+	mutations []string
+	// latency is average milliseconds of refresh intervals by backend/table/method of "Repo latency"
+	latency map[string][]float64
+	// last are counts and sums of the previous refresh to get interval averages
+	last map[string]histogram
+	errs []string
+}
+
+func newModel(poll func(since int64) polled, interval time.Duration, tenant string, width, limit int, since int64) *model {
+	return &model{
+		poll:     poll,
+		interval: interval,
+		tenant:   tenant,
+		width:    width,
+		limit:    limit,
+		since:    since,
+		byTenant: make(map[string]int64),
+		latency:  make(map[string][]float64),
+		last:     make(map[string]histogram),
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return m.refresh()
+}
+
+// Update applies polls and schedules the next one by the interval after the previous is applied,
+// so polls of a slow service don't pile up.
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	case tick:
+		return m, m.refresh()
+	case polled:
+		m.apply(msg)
+		return m, tea.Tick(m.interval, func(time.Time) tea.Msg {
+			return tick{}
+		})
+	}
+	return m, nil
+}
+
+// refresh returns the command polling changes since the current time of the model.
+func (m *model) refresh() tea.Cmd {
+	poll, since := m.poll, m.since
+	return func() tea.Msg {
+		return poll(since)
+	}
+}
+
+func (m *model) apply(p polled) {
+	m.at = p.at
+	m.errs = m.errs[:0]
+	if p.countErr != nil {
+		m.errs = append(m.errs, "user count: "+p.countErr.Error())
+	} else {
+		m.count, m.total = p.count, p.total
+	}
+	if p.mutationsErr != nil {
+		m.errs = append(m.errs, "mutations: "+p.mutationsErr.Error())
+	} else {
+		m.applyMutations(p.next, p.mutations)
+	}
+	if p.metricsErr != nil {
+		m.errs = append(m.errs, "metrics: "+p.metricsErr.Error())
+	} else {
+		m.applyMetrics(p.metrics)
+	}
+}
+
+// applyMutations adds changes of the poll, there is no stream of mutations.
+func (m *model) applyMutations(next int64, lines []string) {
+	if next != 0 {
+		m.since = next
+	}
+	sort.Strings(lines)
+	// changes of the second of the previous poll come again, they are shown once
+	shown := make(map[string]bool, len(m.mutations))
+	for _, line := range m.mutations {
+		shown[line] = true
+	}
+	for _, line := range lines {
+		if !shown[line] {
+			m.mutations = append(m.mutations, line)
+		}
+	}
+	if len(m.mutations) > m.limit {
+		m.mutations = m.mutations[len(m.mutations)-m.limit:]
+	}
+}
+
+func (m *model) applyMetrics(vars metrics) {
+	if vars.ByTenant != nil {
+		m.byTenant = vars.ByTenant
+	}
+	for key, h := range vars.Latency {
+		last, ok := m.last[key]
+		m.last[key] = h
+		if !ok {
+			continue
+		}
+		avg := 0.0
+		if calls := h.Count - last.Count; calls != 0 {
+			avg = (h.SumMs - last.SumMs) / float64(calls)
+		}
+		points := append(m.latency[key], avg)
+		if len(points) > m.width {
+			points = points[len(points)-m.width:]
+		}
+		m.latency[key] = points
+	}
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	at := "polling"
+	if !m.at.IsZero() {
+		at = m.at.Format("15:04:05")
+	}
+	fmt.Fprintf(&b, "%susers%s  %s\n", bold, reset, at)
+	tenant := m.tenant
+	if tenant == "" {
+		tenant = "(none)"
+	}
+	fmt.Fprintf(&b, "  total %d, tenant %s %d\n", m.total, tenant, m.count)
+	tenants := make([]string, 0, len(m.byTenant))
+	for t := range m.byTenant {
+		tenants = append(tenants, t)
+	}
+	sort.Strings(tenants)
+	for _, t := range tenants {
+		fmt.Fprintf(&b, "  %-24s %d\n", t, m.byTenant[t])
+	}
+
+	fmt.Fprintf(&b, "\n%srecent mutations%s\n", bold, reset)
+	if len(m.mutations) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, line := range m.mutations {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "\n%srepository latency%s, average ms per refresh\n", bold, reset)
+	keys := make([]string, 0, len(m.latency))
+	for key := range m.latency {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		b.WriteString("  no calls yet\n")
+	}
+	for _, key := range keys {
+		points := m.latency[key]
+		fmt.Fprintf(&b, "  %-40s %s %8.2f\n", key, sparkline(points), points[len(points)-1])
+	}
+
+	for _, err := range m.errs {
+		fmt.Fprintf(&b, "\nerror: %s", err)
+	}
+	b.WriteString("\n\nq to quit\n")
+	return b.String()
+}
+
+// sparkline scales the points from zero to the maximal one.
+func sparkline(points []float64) string {
+	max := 0.0
+	for _, p := range points {
+		if p > max {
+			max = p
+		}
+	}
+	res := make([]rune, 0, len(points))
+	for _, p := range points {
+		level := 0
+		if max > 0 {
+			level = int(p / max * float64(len(sparks)-1))
+		}
+		res = append(res, sparks[level])
+	}
+	return string(res)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_Update(t *testing.T) {
+	var polls []int64
+	poll := func(since int64) polled {
+		polls = append(polls, since)
+		return polled{}
+	}
+	m := newModel(poll, time.Millisecond, "acme", 3, 2, 1660000000)
+
+	// the first poll starts on init and reads changes since the start time
+	msg := m.Init()()
+	assert.Equal(t, []int64{1660000000}, polls)
+	assert.IsType(t, polled{}, msg)
+
+	at := time.Date(2022, 8, 13, 12, 0, 0, 0, time.UTC)
+	_, cmd := m.Update(polled{
+		at:        at,
+		count:     2,
+		total:     5,
+		next:      1660000100,
+		mutations: []string{"12:00:00  written  petr (active)", "11:59:59  deleted  ivan"},
+		metrics: metrics{
+			Latency:  map[string]histogram{"postgres/users/get": {Count: 10, SumMs: 20}},
+			ByTenant: map[string]int64{"acme": 2, "": 3},
+		},
+	})
+	assert.Equal(t, at, m.at)
+	assert.Equal(t, int64(2), m.count)
+	assert.Equal(t, int64(5), m.total)
+	assert.Equal(t, []string{"11:59:59  deleted  ivan", "12:00:00  written  petr (active)"}, m.mutations)
+	assert.Equal(t, map[string]int64{"acme": 2, "": 3}, m.byTenant)
+	assert.Empty(t, m.latency, "averages start with the second poll")
+	// the next poll is scheduled after the interval and reads changes since the previous poll
+	require.NotNil(t, cmd)
+	assert.Equal(t, tick{}, cmd())
+	_, cmd = m.Update(tick{})
+	require.NotNil(t, cmd)
+	cmd()
+	assert.Equal(t, []int64{1660000000, 1660000100}, polls)
+
+	// failed sources keep their values, repeated mutations are shown once and the last ones are kept
+	m.Update(polled{
+		at:        at.Add(time.Second),
+		countErr:  errors.New("unavailable"),
+		mutations: []string{"12:00:00  written  petr (active)", "12:00:01  written  olga (active)"},
+		metrics: metrics{
+			Latency: map[string]histogram{"postgres/users/get": {Count: 14, SumMs: 32}},
+		},
+	})
+	assert.Equal(t, int64(2), m.count)
+	assert.Equal(t, int64(1660000100), m.since, "since is kept without the next one")
+	assert.Equal(t, []string{"12:00:00  written  petr (active)", "12:00:01  written  olga (active)"}, m.mutations)
+	assert.Equal(t, map[string]int64{"acme": 2, "": 3}, m.byTenant)
+	assert.Equal(t, map[string][]float64{"postgres/users/get": {3}}, m.latency)
+	assert.Equal(t, []string{"user count: unavailable"}, m.errs)
+
+	// errors are cleared by the next successful poll
+	m.Update(polled{at: at.Add(2 * time.Second)})
+	assert.Empty(t, m.errs)
+	assert.Equal(t, int64(0), m.count)
+}
+
+func TestModel_UpdateKeys(t *testing.T) {
+	m := newModel(func(int64) polled { return polled{} }, time.Second, "", 3, 2, 0)
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("q")},
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyCtrlC},
+	} {
+		_, cmd := m.Update(key)
+		require.NotNil(t, cmd, key.String())
+		assert.Equal(t, tea.Quit(), cmd(), key.String())
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.Nil(t, cmd)
+}
+
+func TestModel_View(t *testing.T) {
+	m := newModel(func(int64) polled { return polled{} }, time.Second, "", 3, 2, 0)
+	assert.Contains(t, m.View(), "polling")
+	assert.Contains(t, m.View(), "none")
+	assert.Contains(t, m.View(), "no calls yet")
+
+	m.Update(polled{
+		at:         time.Date(2022, 8, 13, 12, 0, 0, 0, time.Local),
+		total:      5,
+		count:      3,
+		mutations:  []string{"12:00:00  written  petr (active)"},
+		metricsErr: errors.New("status 503"),
+	})
+	m.latency["postgres/users/get"] = []float64{1, 2, 4}
+	view := m.View()
+	assert.Contains(t, view, "12:00:00")
+	assert.Contains(t, view, "total 5, tenant (none) 3")
+	assert.Contains(t, view, "written  petr (active)")
+	assert.Contains(t, view, "▂▄█     4.00")
+	assert.Contains(t, view, "error: metrics: status 503")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▁▁", sparkline([]float64{0, 0, 0}))
+	assert.Equal(t, "▁▄█", sparkline([]float64{0, 1, 2}))
+	assert.Empty(t, sparkline(nil))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+// poller reads the data service for the dashboard.
+type poller struct {
+	ctx     context.Context
+	client  pb.UserClient
+	http    *http.Client
+	metrics string
+	tenant  string
+}
+
+func main() {
+	interval := flag.Duration("interval", 2*time.Second, "refresh interval")
+	tenant := flag.String("tenant", "", "tenant of the user count, users without tenant if empty")
+	width := flag.Int("width", 40, "points of latency sparklines")
+	limit := flag.Int("limit", 10, "recent mutations shown")
+	since := flag.Duration("since", 10*time.Minute, "show mutations made within the period on start")
+	flag.Parse()
+
+	config, err := yamlPkg.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	conn, err := grpc.Dial(config.GRPCDataAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	p := &poller{
+		ctx:     ctx,
+		client:  pb.NewUserClient(conn),
+		http:    &http.Client{Timeout: *interval},
+		metrics: metricsURL(config.HTTPDataAddr()),
+		tenant:  *tenant,
+	}
+	m := newModel(p.poll, *interval, *tenant, *width, *limit, time.Now().Add(-*since).Unix())
+	if _, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err != nil &&
+		!errors.Is(err, tea.ErrProgramKilled) {
+		log.Fatalln(err)
+	}
+}
+
+// metricsURL returns the expvar URL of the HTTP address, the host defaults to localhost.
+func metricsURL(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return "http://" + addr + "/counters"
+}
+
+// poll reads all sources, it is run by bubbletea out of the update loop.
+func (p *poller) poll(since int64) polled {
+	res := polled{at: time.Now()}
+	res.count, res.total, res.countErr = p.count()
+	res.next, res.mutations, res.mutationsErr = p.changes(since)
+	res.metrics, res.metricsErr = p.vars()
+	return res
+}
+
+func (p *poller) count() (int64, int64, error) {
+	resp, err := p.client.UserCount(p.ctx, &pb.UserCountRequest{Tenant: p.tenant})
+	if err != nil {
+		return 0, 0, err
+	}
+	return resp.GetCount(), resp.GetTotal(), nil
+}
+
+// changes returns mutations since the time and since of the next poll.
+func (p *poller) changes(since int64) (int64, []string, error) {
+	stream, err := p.client.UserChanges(p.ctx, &pb.UserChangesRequest{Since: since, IncludeDeleted: true})
+	if err != nil {
+		return 0, nil, err
+	}
+	var (
+		next  int64
+		lines []string
+	)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return next, lines, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if resp.GetNextSince() != 0 {
+			next = resp.GetNextSince()
+		}
+		for _, deleted := range resp.GetDeleted() {
+			lines = append(lines, fmt.Sprintf("%s  deleted  %s",
+				time.Unix(deleted.GetDeletedAt(), 0).Format("15:04:05"), deleted.GetName()))
+		}
+		for _, user := range resp.GetUsers() {
+			lines = append(lines, fmt.Sprintf("%s  written  %s (%s)",
+				time.Unix(user.GetUpdatedAt(), 0).Format("15:04:05"), user.GetName(), user.GetStatus()))
+		}
+	}
+}
+
+func (p *poller) vars() (metrics, error) {
+	var vars metrics
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, p.metrics, nil)
+	if err != nil {
+		return vars, err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return vars, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return vars, fmt.Errorf("status %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&vars)
+	return vars, err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -16,11 +17,18 @@ import (
 	configPkg "gitlab.ozon.dev/iTukaev/homework/internal/config"
 	yamlPkg "gitlab.ozon.dev/iTukaev/homework/internal/config/yaml"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	selfcheckPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/selfcheck"
 	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
 )
 
 func main() {
+	check := flag.Bool("check", false, "probe dependencies, log readiness report and exit")
+	flag.Parse()
+
 	config, err := yamlPkg.New()
 	if err != nil {
 		log.Fatalln("Config init error:", err)
@@ -37,6 +45,16 @@ func main() {
 		cancel()
 	}()
 
+	if _, err = selfcheckPkg.Run(ctx, logger,
+		selfcheckPkg.Kafka(config.Brokers()),
+		selfcheckPkg.Redis(config.RedisConfig()),
+	); err != nil {
+		logger.Fatalln(err)
+	}
+	if *check {
+		return
+	}
+
 	tracer, closer, err := jaegerPkg.New(config.JService(), config.JHost())
 	if err != nil {
 		logger.Errorf("Jaeger initialise err: %v", err)
@@ -75,7 +93,16 @@ func runService(ctx context.Context, config configPkg.Interface, logger *zap.Sug
 		return errors.Wrap(err, "new ConsumerGroup")
 	}
 
-	handler := validator.NewHandler(logger, producer)
+	client, err := redisPkg.New(ctx, config.RedisConfig())
+	if err != nil {
+		return errors.Wrap(err, "new redis client")
+	}
+	denylist, err := denylistPkg.New(config.DenylistConfig(), client)
+	if err != nil {
+		return errors.Wrap(err, "new denylist")
+	}
+
+	handler := validator.NewHandler(logger, producer, normalizePkg.New(config.NamePolicy()), denylist)
 
 	go func() {
 		for {
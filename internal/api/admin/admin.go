@@ -0,0 +1,781 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	anomalyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	approvalPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval"
+	backupPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	fakePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	reindexPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reindex"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	verifyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify"
+	bloomRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const (
+	// backupChunkSize is a size of backup chunks streamed to the client.
+	backupChunkSize = 64 << 10
+	// impersonationPeriod is listed, if the request has no since time.
+	impersonationPeriod = 24 * time.Hour
+	// usagePeriod is reported, if the request has no since time.
+	usagePeriod = 24 * time.Hour
+	// listAtLimit is a page size of UserListAt, if the request has no limit, and its maximum.
+	listAtLimit    = 100
+	listAtMaxLimit = 1000
+	// cacheStateChunk is a number of filter words in one message of the cache state, 64KiB.
+	cacheStateChunk = 8 << 10
+)
+
+func New(
+	denylist denylistPkg.Interface,
+	reindex reindexPkg.Interface,
+	backup backupPkg.Interface,
+	user userPkg.Interface,
+	maintenance *maintenancePkg.Mode,
+	verifier verifyPkg.Interface,
+	anomaly anomalyPkg.Interface,
+	approval approvalPkg.Interface,
+	tenants tenantPkg.Interface,
+	names bloomRepoPkg.Exporter,
+	usage usagePkg.Interface,
+	generator fakePkg.Interface,
+	size grpcPkg.ResponseSizeConfig,
+	logger *zap.SugaredLogger,
+) pb.AdminServer {
+	return &core{
+		denylist:    denylist,
+		reindex:     reindex,
+		backup:      backup,
+		user:        user,
+		maintenance: maintenance,
+		verifier:    verifier,
+		anomaly:     anomaly,
+		approval:    approval,
+		tenants:     tenants,
+		names:       names,
+		usage:       usage,
+		generator:   generator,
+		size:        size,
+		logger:      logger,
+	}
+}
+
+type core struct {
+	denylist    denylistPkg.Interface
+	reindex     reindexPkg.Interface
+	backup      backupPkg.Interface
+	user        userPkg.Interface
+	maintenance *maintenancePkg.Mode
+	verifier    verifyPkg.Interface
+	// anomaly is nil, if the detection is disabled
+	anomaly anomalyPkg.Interface
+	// approval is nil, if the two-man rule is disabled
+	approval approvalPkg.Interface
+	tenants  tenantPkg.Interface
+	// names is nil, if the bloom filter is disabled
+	names bloomRepoPkg.Exporter
+	// usage is nil, if the usage reporting is disabled
+	usage usagePkg.Interface
+	// generator is nil, if the generation of fake users is disabled
+	generator fakePkg.Interface
+	size      grpcPkg.ResponseSizeConfig
+	logger    *zap.SugaredLogger
+	pb.UnimplementedAdminServer
+}
+
+func (c *core) DenylistAdd(ctx context.Context, in *pb.DenylistAddRequest) (*pb.DenylistAddResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "denylist add", in.GetValue(), in.GetPattern())
+
+	if err := c.denylist.Add(ctx, in.GetValue(), in.GetPattern()); err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("denylist add", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.DenylistAddResponse{}, nil
+}
+
+func (c *core) DenylistRemove(ctx context.Context, in *pb.DenylistRemoveRequest) (*pb.DenylistRemoveResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "denylist remove", in.GetValue(), in.GetPattern())
+
+	if err := c.denylist.Remove(ctx, in.GetValue(), in.GetPattern()); err != nil {
+		if errors.Is(err, denylistPkg.ErrStaticEntry) {
+			return nil, apperr.Status(codes.FailedPrecondition, err)
+		}
+		c.logger.Errorw("denylist remove", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.DenylistRemoveResponse{}, nil
+}
+
+func (c *core) DenylistList(ctx context.Context, _ *pb.DenylistListRequest) (*pb.DenylistListResponse, error) {
+	entries, err := c.denylist.List(ctx)
+	if err != nil {
+		c.logger.Errorw("denylist list", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	resp := &pb.DenylistListResponse{
+		Entries: make([]*pb.DenylistEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, &pb.DenylistEntry{
+			Value:   entry.Value,
+			Pattern: entry.Pattern,
+			Static:  entry.Static,
+		})
+	}
+	return resp, nil
+}
+
+func (c *core) ReindexStart(ctx context.Context, in *pb.ReindexStartRequest) (*pb.ReindexStartResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "reindex start", in.GetIndexes(), in.GetResume())
+
+	job, err := c.reindex.Start(ctx, in.GetIndexes(), in.GetResume())
+	if err != nil {
+		switch {
+		case errors.Is(err, reindexPkg.ErrJobRunning):
+			return nil, apperr.Status(codes.FailedPrecondition, err)
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("reindex start", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.ReindexStartResponse{
+		Job: jobToPb(job),
+	}, nil
+}
+
+func (c *core) ReindexStatus(ctx context.Context, _ *pb.ReindexStatusRequest) (*pb.ReindexStatusResponse, error) {
+	job, err := c.reindex.Status(ctx)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, status.Error(codes.NotFound, "no reindex jobs")
+		}
+		c.logger.Errorw("reindex status", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.ReindexStatusResponse{
+		Job: jobToPb(job),
+	}, nil
+}
+
+func (c *core) PasswordExpire(ctx context.Context, in *pb.PasswordExpireRequest) (*pb.PasswordExpireResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "password expire", in.GetNames(), in.GetAttributes(), in.GetAll())
+
+	if len(in.GetNames()) == 0 && len(in.GetAttributes()) == 0 && !in.GetAll() {
+		return nil, status.Error(codes.InvalidArgument, "names or attributes must be set, all must be set to affect all users")
+	}
+	attributes := in.GetAttributes()
+	if len(in.GetNames()) != 0 {
+		attributes = nil
+	}
+	expired, err := c.user.ExpirePasswords(ctx, in.GetNames(), attributes)
+	if err != nil {
+		if id, ok := approvalPkg.PendingID(err); ok {
+			return &pb.PasswordExpireResponse{
+				OperationId: id,
+			}, nil
+		}
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.NotFound, err)
+		}
+		c.logger.Errorw("password expire", append(apperr.Fields(err), "meta", meta, "expired", expired)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.PasswordExpireResponse{
+		Expired: expired,
+	}, nil
+}
+
+func (c *core) BackupCreate(in *pb.BackupCreateRequest, stream pb.Admin_BackupCreateServer) error {
+	ctx := stream.Context()
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "backup create", in.GetStore(), in.GetKey())
+
+	var (
+		summary backupPkg.Summary
+		err     error
+	)
+	if in.GetStore() {
+		summary, err = c.backup.Store(ctx, in.GetKey())
+	} else {
+		w := bufio.NewWriterSize(chunkWriter(func(chunk []byte) error {
+			return stream.Send(&pb.BackupCreateResponse{Chunk: chunk})
+		}), backupChunkSize)
+		if summary, err = c.backup.Create(ctx, w); err == nil {
+			err = w.Flush()
+		}
+	}
+	if err != nil {
+		return c.backupError(meta, "backup create", err)
+	}
+	return stream.Send(&pb.BackupCreateResponse{
+		Summary: &pb.BackupSummary{
+			Key:       summary.Key,
+			Users:     summary.Users,
+			Size:      summary.Size,
+			Checksum:  summary.Checksum,
+			CreatedAt: summary.CreatedAt.Unix(),
+		},
+	})
+}
+
+func (c *core) BackupRestore(stream pb.Admin_BackupRestoreServer) error {
+	ctx := stream.Context()
+	meta := grpcPkg.GetMetaFromContext(ctx)
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "key or backup chunks must be sent")
+	}
+	if err != nil {
+		c.logger.Errorw("backup restore, receive chunk", append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+	c.logger.Infoln(meta, "backup restore", first.GetKey(), first.GetOverwrite())
+
+	var result backupPkg.Result
+	if first.GetKey() != "" {
+		result, err = c.backup.Load(ctx, first.GetKey(), first.GetChecksum(), first.GetOverwrite())
+	} else {
+		r := &chunkReader{stream: stream, chunk: first.GetChunk()}
+		result, err = c.backup.Restore(ctx, r, first.GetChecksum(), first.GetOverwrite())
+	}
+	if err != nil {
+		return c.backupError(meta, "backup restore", err)
+	}
+	return stream.SendAndClose(&pb.BackupRestoreResponse{
+		Users:       result.Users,
+		Created:     result.Created,
+		Overwritten: result.Overwritten,
+		Skipped:     result.Skipped,
+		Failed:      result.Failed,
+	})
+}
+
+func (c *core) UserStateAt(ctx context.Context, in *pb.UserStateAtRequest) (*pb.UserStateAtResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "user state at", in.GetName(), in.GetAt(), in.GetRestore())
+
+	if in.GetAt() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "field: [at] must be positive")
+	}
+	user, err := c.user.StateAt(ctx, in.GetName(), time.Unix(in.GetAt(), 0), in.GetRestore())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.NotFound, err)
+		case errors.Is(err, errorsPkg.ErrHistoryDisabled):
+			return nil, apperr.Status(codes.FailedPrecondition, err)
+		}
+		c.logger.Errorw("user state at", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	user.Password = ""
+	return &pb.UserStateAtResponse{
+		User: adaptor.ToUserPbModel(user),
+	}, nil
+}
+
+func (c *core) UserListAt(ctx context.Context, in *pb.UserListAtRequest) (*pb.UserListAtResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "user list at", in.GetAsOf(), in.GetOrder(), in.GetLimit(), in.GetAfter())
+
+	if in.GetAsOf() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "field: [as_of] must be positive")
+	}
+	limit := in.GetLimit()
+	switch {
+	case limit == 0:
+		limit = listAtLimit
+	case limit > listAtMaxLimit:
+		return nil, status.Errorf(codes.InvalidArgument, "field: [limit] must not exceed %d", listAtMaxLimit)
+	}
+	users, err := c.user.ListAt(ctx, time.Unix(in.GetAsOf(), 0), in.GetOrder(), limit, in.GetAfter(),
+		in.GetAttributes(), in.GetStatus())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrHistoryDisabled):
+			return nil, apperr.Status(codes.FailedPrecondition, err)
+		}
+		c.logger.Errorw("user list at", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	resp := &pb.UserListAtResponse{
+		Users: make([]*pbModels.User, 0, len(users)),
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, adaptor.ToUserPbModel(user))
+	}
+	if n := grpcPkg.FitUsers(resp.Users, c.size); n < len(resp.Users) {
+		resp.Users = resp.Users[:n]
+		resp.NextAfter = resp.Users[n-1].GetName()
+	}
+	return resp, nil
+}
+
+func (c *core) MaintenanceSet(ctx context.Context, in *pb.MaintenanceSetRequest) (*pb.MaintenanceSetResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "maintenance set", in.GetEnabled(), in.GetReason())
+
+	state, err := c.maintenance.Set(ctx, in.GetEnabled(), in.GetReason(), meta)
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("maintenance set", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.MaintenanceSetResponse{
+		State: maintenancePkg.ToPb(state),
+	}, nil
+}
+
+func (c *core) MaintenanceGet(context.Context, *pb.MaintenanceGetRequest) (*pb.MaintenanceGetResponse, error) {
+	return &pb.MaintenanceGetResponse{
+		State: maintenancePkg.ToPb(c.maintenance.State()),
+	}, nil
+}
+
+func (c *core) ImpersonationList(ctx context.Context, in *pb.ImpersonationListRequest) (*pb.ImpersonationListResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "impersonation list", in.GetSince(), in.GetLimit())
+
+	if in.GetSince() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "field: [since] must not be negative")
+	}
+	since := time.Now().Add(-impersonationPeriod)
+	if in.GetSince() > 0 {
+		since = time.Unix(in.GetSince(), 0)
+	}
+	sessions, err := c.user.Impersonations(ctx, since, in.GetLimit())
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrHistoryDisabled) {
+			return nil, apperr.Status(codes.FailedPrecondition, err)
+		}
+		c.logger.Errorw("impersonation list", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	resp := &pb.ImpersonationListResponse{
+		Sessions: make([]*pb.ImpersonationSession, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &pb.ImpersonationSession{
+			RealActor: session.RealActor,
+			Actor:     session.Actor,
+			FirstAt:   session.FirstAt.Unix(),
+			LastAt:    session.LastAt.Unix(),
+			Changes:   uint64(session.Changes),
+		})
+	}
+	return resp, nil
+}
+
+func (c *core) VerifyData(ctx context.Context, in *pb.VerifyDataRequest) (*pb.VerifyDataResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "verify data", in.GetRepair())
+
+	report, err := c.verifier.Verify(ctx, in.GetRepair())
+	if err != nil {
+		c.logger.Errorw("verify data", append(apperr.Fields(err), "meta", meta, "checked", report.Checked)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	resp := &pb.VerifyDataResponse{
+		Checked:    report.Checked,
+		Found:      report.Found,
+		Repaired:   report.Repaired,
+		Violations: make([]*pb.DataViolation, 0, len(report.Violations)),
+	}
+	for _, violation := range report.Violations {
+		resp.Violations = append(resp.Violations, &pb.DataViolation{
+			Class:    violation.Class,
+			Name:     violation.Name,
+			Detail:   violation.Detail,
+			Repaired: violation.Repaired,
+		})
+	}
+	return resp, nil
+}
+
+func (c *core) CacheGet(ctx context.Context, in *pb.CacheGetRequest) (*pb.CacheGetResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "cache get", in.GetName())
+
+	cached, err := c.user.CacheGet(ctx, in.GetName())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.NotFound, err)
+		}
+		c.logger.Errorw("cache get", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	cached.User.Password = ""
+	return &pb.CacheGetResponse{
+		User: adaptor.ToUserPbModel(cached.User),
+		Ttl:  int64(cached.TTL.Seconds()),
+	}, nil
+}
+
+func (c *core) CacheEvict(ctx context.Context, in *pb.CacheEvictRequest) (*pb.CacheEvictResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "cache evict", in.GetName())
+
+	evicted, err := c.user.CacheEvict(ctx, in.GetName())
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("cache evict", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.CacheEvictResponse{
+		Evicted: evicted,
+	}, nil
+}
+
+func (c *core) CacheStats(ctx context.Context, _ *pb.CacheStatsRequest) (*pb.CacheStatsResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "cache stats")
+
+	stats, err := c.user.CacheStats(ctx)
+	if err != nil {
+		c.logger.Errorw("cache stats", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.CacheStatsResponse{
+		Hits:           stats.Hits,
+		Misses:         stats.Misses,
+		ListHits:       stats.ListHits,
+		ListMisses:     stats.ListMisses,
+		Keys:           stats.Keys,
+		ListGeneration: stats.ListGeneration,
+	}, nil
+}
+
+// CacheStateExport streams the current bloom filter, the state is copied once,
+// so names added meanwhile are not sent.
+func (c *core) CacheStateExport(_ *pb.CacheStateExportRequest, stream pb.Admin_CacheStateExportServer) error {
+	meta := grpcPkg.GetMetaFromContext(stream.Context())
+	c.logger.Infoln(meta, "cache state export")
+
+	if c.names == nil {
+		return apperr.Status(codes.FailedPrecondition, errorsPkg.ErrBloomDisabled)
+	}
+	state, ok := c.names.FilterState()
+	if !ok {
+		return apperr.Status(codes.Unavailable, errorsPkg.ErrBloomNotBuilt)
+	}
+	bits := state.Bits
+	for {
+		n := len(bits)
+		if n > cacheStateChunk {
+			n = cacheStateChunk
+		}
+		resp := &pb.CacheStateExportResponse{Size: state.Size, Hashes: state.Hashes, Bits: bits[:n]}
+		if bits = bits[n:]; len(bits) == 0 {
+			resp.Checksum = state.Checksum()
+		}
+		if err := stream.Send(resp); err != nil {
+			c.logger.Errorw("cache state export, send", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+		if len(bits) == 0 {
+			return nil
+		}
+	}
+}
+
+func (c *core) AnomalyList(ctx context.Context, _ *pb.AnomalyListRequest) (*pb.AnomalyListResponse, error) {
+	if c.anomaly == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrAnomalyDisabled)
+	}
+	return &pb.AnomalyListResponse{
+		Alerts: toAlertsPb(c.anomaly.Alerts()),
+	}, nil
+}
+
+func (c *core) AnomalyConfirm(ctx context.Context, _ *pb.AnomalyConfirmRequest) (*pb.AnomalyConfirmResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "anomaly confirm")
+
+	if c.anomaly == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrAnomalyDisabled)
+	}
+	confirmed, state, err := c.anomaly.Confirm(ctx, meta)
+	if err != nil {
+		c.logger.Errorw("anomaly confirm", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.AnomalyConfirmResponse{
+		Confirmed:   toAlertsPb(confirmed),
+		Maintenance: maintenancePkg.ToPb(state),
+	}, nil
+}
+
+func toAlertsPb(alerts []anomalyPkg.Alert) []*pb.AnomalyAlert {
+	result := make([]*pb.AnomalyAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		result = append(result, &pb.AnomalyAlert{
+			Kind:     alert.Kind,
+			Actor:    alert.Actor,
+			Count:    uint64(alert.Count),
+			RaisedAt: alert.RaisedAt.Unix(),
+		})
+	}
+	return result
+}
+
+func (c *core) OperationList(ctx context.Context, _ *pb.OperationListRequest) (*pb.OperationListResponse, error) {
+	if c.approval == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrApprovalDisabled)
+	}
+	ops, err := c.approval.Pending(ctx)
+	if err != nil {
+		c.logger.Errorw("operation list", apperr.Fields(err)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	result := make([]*pb.PendingOperation, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, toOperationPb(op))
+	}
+	return &pb.OperationListResponse{
+		Operations: result,
+	}, nil
+}
+
+func (c *core) OperationApprove(ctx context.Context, in *pb.OperationApproveRequest) (*pb.OperationApproveResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "operation approve", in.GetId())
+
+	if c.approval == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrApprovalDisabled)
+	}
+	op, expired, err := c.approval.Approve(ctx, in.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, approvalPkg.ErrNotFound), errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.NotFound, err)
+		case errors.Is(err, approvalPkg.ErrSelfApproval):
+			return nil, apperr.Status(codes.PermissionDenied, err)
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("operation approve", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.OperationApproveResponse{
+		Operation: toOperationPb(op),
+		Expired:   expired,
+	}, nil
+}
+
+func toOperationPb(op approvalPkg.Operation) *pb.PendingOperation {
+	return &pb.PendingOperation{
+		Id:         op.ID,
+		Kind:       op.Kind,
+		Requester:  op.Requester,
+		Names:      op.Names,
+		Attributes: op.Attributes,
+		CreatedAt:  op.CreatedAt,
+		ExpiresAt:  op.ExpiresAt,
+	}
+}
+
+func (c *core) TenantOverridesList(ctx context.Context, _ *pb.TenantOverridesListRequest) (*pb.TenantOverridesListResponse, error) {
+	list := c.tenants.List()
+	result := make([]*pb.TenantOverrides, 0, len(list))
+	for _, overrides := range list {
+		result = append(result, tenantPkg.ToPb(overrides))
+	}
+	return &pb.TenantOverridesListResponse{
+		Overrides: result,
+	}, nil
+}
+
+func (c *core) TenantOverridesGet(ctx context.Context, in *pb.TenantOverridesGetRequest) (*pb.TenantOverridesGetResponse, error) {
+	overrides, ok := c.tenants.Get(in.GetTenant())
+	if !ok {
+		return nil, status.Error(codes.NotFound, errors.Wrapf(errorsPkg.ErrTenantNotFound, "tenant [%s]", in.GetTenant()).Error())
+	}
+	return &pb.TenantOverridesGetResponse{
+		Overrides: tenantPkg.ToPb(overrides),
+	}, nil
+}
+
+func (c *core) TenantOverridesSet(ctx context.Context, in *pb.TenantOverridesSetRequest) (*pb.TenantOverridesSetResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "tenant overrides set", in.GetOverrides().GetTenant())
+
+	overrides, err := c.tenants.Set(ctx, tenantPkg.FromPb(in.GetOverrides()), meta)
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("tenant overrides set", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.TenantOverridesSetResponse{
+		Overrides: tenantPkg.ToPb(overrides),
+	}, nil
+}
+
+func (c *core) TenantOverridesDelete(ctx context.Context, in *pb.TenantOverridesDeleteRequest) (*pb.TenantOverridesDeleteResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "tenant overrides delete", in.GetTenant())
+
+	if err := c.tenants.Delete(ctx, in.GetTenant(), meta); err != nil {
+		if errors.Is(err, errorsPkg.ErrTenantNotFound) {
+			return nil, apperr.Status(codes.NotFound, err)
+		}
+		c.logger.Errorw("tenant overrides delete", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.TenantOverridesDeleteResponse{}, nil
+}
+
+func (c *core) UsageReport(ctx context.Context, in *pb.UsageReportRequest) (*pb.UsageReportResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "usage report", in.GetSince(), in.GetUntil(), in.GetClient())
+
+	if c.usage == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrUsageDisabled)
+	}
+	now := time.Now()
+	since, until := now.Add(-usagePeriod), now
+	if in.GetSince() != 0 {
+		since = time.Unix(in.GetSince(), 0)
+	}
+	if in.GetUntil() != 0 {
+		until = time.Unix(in.GetUntil(), 0)
+	}
+	if !since.Before(until) {
+		return nil, status.Error(codes.InvalidArgument, "field: [since] must be before [until]")
+	}
+	// the period, which since falls into, is reported as a whole
+	report, err := c.usage.Report(ctx, since.Truncate(usagePkg.Period).Unix(), until.Unix(), in.GetClient())
+	if err != nil {
+		c.logger.Errorw("usage report", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.UsageReportResponse{
+		Clients: usagePkg.ToPb(report),
+	}, nil
+}
+
+func (c *core) UserGenerate(ctx context.Context, in *pb.UserGenerateRequest) (*pb.UserGenerateResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "user generate", in.GetCount(), in.GetPrefix())
+
+	if c.generator == nil {
+		return nil, apperr.Status(codes.FailedPrecondition, errorsPkg.ErrGenerateDisabled)
+	}
+	report, err := c.generator.Generate(ctx, int(in.GetCount()), in.GetPrefix())
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		}
+		c.logger.Errorw("user generate", append(apperr.Fields(err), "meta", meta, "created", len(report.Created))...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.UserGenerateResponse{
+		Created: report.Created,
+		Failed:  report.Failed,
+	}, nil
+}
+
+func (c *core) backupError(meta, op string, err error) error {
+	switch {
+	case errors.Is(err, backupPkg.ErrStorageDisabled):
+		return apperr.Status(codes.FailedPrecondition, err)
+	case errors.Is(err, backupPkg.ErrCorrupted):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, blob.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	c.logger.Errorln(meta, op, err)
+	return apperr.Status(codes.Internal, err)
+}
+
+// chunkWriter sends every write as a stream message.
+type chunkWriter func(chunk []byte) error
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// chunkReader reads chunks of the stream messages.
+type chunkReader struct {
+	stream pb.Admin_BackupRestoreServer
+	chunk  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		in, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = in.GetChunk()
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func jobToPb(job reindexPkg.Job) *pb.ReindexJob {
+	return &pb.ReindexJob{
+		Id:        job.ID,
+		Indexes:   job.Indexes,
+		Status:    job.Status,
+		Page:      job.Page,
+		Processed: job.Processed,
+		Resumed:   job.Resumed,
+		Error:     job.Error,
+		StartedAt: job.StartedAt.Unix(),
+		UpdatedAt: job.UpdatedAt.Unix(),
+	}
+}
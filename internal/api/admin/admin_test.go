@@ -0,0 +1,1063 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	anomalyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	anomalyMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly/mock"
+	approvalPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval"
+	approvalMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval/mock"
+	backupPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup"
+	backupMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup/mock"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	denylistMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist/mock"
+	fakePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake"
+	fakeMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake/mock"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	reindexPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reindex"
+	reindexMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reindex/mock"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	tenantMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant/mock"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	usageMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage/mock"
+	verifyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify"
+	verifyMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify/mock"
+	bloomRepoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+	bloomPkg "gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+)
+
+func TestAdminApi_DenylistRemove(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name      string
+		removeErr error
+		expCode   codes.Code
+	}{
+		{
+			name:      "success",
+			removeErr: nil,
+			expCode:   codes.OK,
+		},
+		{
+			name:      "failed, static entry",
+			removeErr: denylistPkg.ErrStaticEntry,
+			expCode:   codes.FailedPrecondition,
+		},
+		{
+			name:      "failed, unexpected error",
+			removeErr: errorsPkg.ErrUnexpected,
+			expCode:   codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			denylist := denylistMockPkg.NewMockInterface(ctl)
+			denylist.EXPECT().Remove(gomock.Any(), "admin", false).Return(c.removeErr).Times(1)
+
+			server := New(denylist, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			_, err := server.DenylistRemove(context.Background(), &pb.DenylistRemoveRequest{Value: "admin"})
+			assert.Equal(t, c.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestAdminApi_ReindexStart(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name     string
+		startErr error
+		expCode  codes.Code
+	}{
+		{
+			name:     "success",
+			startErr: nil,
+			expCode:  codes.OK,
+		},
+		{
+			name:     "failed, job is running",
+			startErr: reindexPkg.ErrJobRunning,
+			expCode:  codes.FailedPrecondition,
+		},
+		{
+			name:     "failed, unknown index",
+			startErr: errorsPkg.ErrValidation,
+			expCode:  codes.InvalidArgument,
+		},
+		{
+			name:     "failed, unexpected error",
+			startErr: errorsPkg.ErrUnexpected,
+			expCode:  codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reindex := reindexMockPkg.NewMockInterface(ctl)
+			reindex.EXPECT().Start(gomock.Any(), []string{"bloom"}, true).
+				Return(reindexPkg.Job{ID: "1", Status: reindexPkg.StatusRunning}, c.startErr).Times(1)
+
+			server := New(nil, reindex, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.ReindexStart(context.Background(), &pb.ReindexStartRequest{
+				Indexes: []string{"bloom"},
+				Resume:  true,
+			})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.startErr == nil {
+				assert.Equal(t, reindexPkg.StatusRunning, resp.GetJob().GetStatus())
+			}
+		})
+	}
+}
+
+func TestAdminApi_PasswordExpire(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name       string
+		req        *pb.PasswordExpireRequest
+		expireErr  error
+		calls      int
+		expNames   []string
+		expAttrs   map[string]string
+		expExpired uint64
+		expCode    codes.Code
+	}{
+		{
+			name:       "success, names",
+			req:        &pb.PasswordExpireRequest{Names: []string{"ivan"}, Attributes: map[string]string{"tenant": "ozon"}},
+			calls:      1,
+			expNames:   []string{"ivan"},
+			expExpired: 1,
+			expCode:    codes.OK,
+		},
+		{
+			name:       "success, attributes",
+			req:        &pb.PasswordExpireRequest{Attributes: map[string]string{"tenant": "ozon"}},
+			calls:      1,
+			expAttrs:   map[string]string{"tenant": "ozon"},
+			expExpired: 1,
+			expCode:    codes.OK,
+		},
+		{
+			name:    "failed, no filter",
+			req:     &pb.PasswordExpireRequest{},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:      "failed, user not found",
+			req:       &pb.PasswordExpireRequest{Names: []string{"ivan"}},
+			expireErr: errorsPkg.ErrUserNotFound,
+			calls:     1,
+			expNames:  []string{"ivan"},
+			expCode:   codes.NotFound,
+		},
+		{
+			name:      "failed, unexpected error",
+			req:       &pb.PasswordExpireRequest{All: true},
+			expireErr: errorsPkg.ErrUnexpected,
+			calls:     1,
+			expCode:   codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().ExpirePasswords(gomock.Any(), c.expNames, c.expAttrs).
+				Return(c.expExpired, c.expireErr).Times(c.calls)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.PasswordExpire(context.Background(), c.req)
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expExpired, resp.GetExpired())
+		})
+	}
+}
+
+func TestAdminApi_UserStateAt(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	state := modeltest.Ivan()
+
+	cases := []struct {
+		name       string
+		req        *pb.UserStateAtRequest
+		stateErr   error
+		calls      int
+		expCode    codes.Code
+		expHasUser bool
+	}{
+		{
+			name:       "success",
+			req:        &pb.UserStateAtRequest{Name: state.Name, At: 1660000000, Restore: true},
+			calls:      1,
+			expCode:    codes.OK,
+			expHasUser: true,
+		},
+		{
+			name:    "failed, no time",
+			req:     &pb.UserStateAtRequest{Name: state.Name},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:     "failed, user not found",
+			req:      &pb.UserStateAtRequest{Name: state.Name, At: 1660000000},
+			stateErr: errorsPkg.ErrUserNotFound,
+			calls:    1,
+			expCode:  codes.NotFound,
+		},
+		{
+			name:     "failed, history disabled",
+			req:      &pb.UserStateAtRequest{Name: state.Name, At: 1660000000},
+			stateErr: errorsPkg.ErrHistoryDisabled,
+			calls:    1,
+			expCode:  codes.FailedPrecondition,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().StateAt(gomock.Any(), state.Name, time.Unix(c.req.GetAt(), 0), c.req.GetRestore()).
+				Return(state, c.stateErr).Times(c.calls)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.UserStateAt(context.Background(), c.req)
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expHasUser, resp.GetUser() != nil)
+			if c.expHasUser {
+				assert.Equal(t, state.ID, resp.GetUser().GetId())
+				assert.Empty(t, resp.GetUser().GetPassword())
+			}
+		})
+	}
+}
+
+func TestAdminApi_UserListAt(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	state := modeltest.Ivan()
+	state.Password = ""
+
+	cases := []struct {
+		name     string
+		req      *pb.UserListAtRequest
+		listErr  error
+		calls    int
+		expLimit uint64
+		expCode  codes.Code
+		expLen   int
+	}{
+		{
+			name:     "success, default limit",
+			req:      &pb.UserListAtRequest{AsOf: 1660000000, Status: "active"},
+			calls:    1,
+			expLimit: listAtLimit,
+			expCode:  codes.OK,
+			expLen:   1,
+		},
+		{
+			name:     "success, page",
+			req:      &pb.UserListAtRequest{AsOf: 1660000000, Order: true, Limit: 10, After: "petr"},
+			calls:    1,
+			expLimit: 10,
+			expCode:  codes.OK,
+			expLen:   1,
+		},
+		{
+			name:    "failed, no time",
+			req:     &pb.UserListAtRequest{},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, limit exceeded",
+			req:     &pb.UserListAtRequest{AsOf: 1660000000, Limit: listAtMaxLimit + 1},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:     "failed, history disabled",
+			req:      &pb.UserListAtRequest{AsOf: 1660000000},
+			listErr:  errorsPkg.ErrHistoryDisabled,
+			calls:    1,
+			expLimit: listAtLimit,
+			expCode:  codes.FailedPrecondition,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			users := []models.User{state}
+			if c.listErr != nil {
+				users = nil
+			}
+			user.EXPECT().ListAt(gomock.Any(), time.Unix(c.req.GetAsOf(), 0), c.req.GetOrder(), c.expLimit, c.req.GetAfter(),
+				c.req.GetAttributes(), c.req.GetStatus()).
+				Return(users, c.listErr).Times(c.calls)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.UserListAt(context.Background(), c.req)
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Len(t, resp.GetUsers(), c.expLen)
+		})
+	}
+}
+
+func TestAdminApi_UserListAtSize(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ivan, petr := modeltest.Ivan(), modeltest.Ivan()
+	petr.Name = "petr"
+
+	cases := []struct {
+		name         string
+		size         grpcPkg.ResponseSizeConfig
+		expLen       int
+		expNextAfter string
+	}{
+		{
+			name:   "success, fits",
+			expLen: 2,
+		},
+		{
+			name:         "success, cut by size",
+			size:         grpcPkg.ResponseSizeConfig{MaxBytes: 1},
+			expLen:       1,
+			expNextAfter: ivan.Name,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().ListAt(gomock.Any(), gomock.Any(), false, uint64(listAtLimit), "", nil, "").
+				Return([]models.User{ivan, petr}, nil)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, c.size, loggerPkg.NewFatal())
+			resp, err := server.UserListAt(context.Background(), &pb.UserListAtRequest{AsOf: 1660000000})
+			assert.NoError(t, err)
+			assert.Len(t, resp.GetUsers(), c.expLen)
+			assert.Equal(t, c.expNextAfter, resp.GetNextAfter())
+		})
+	}
+}
+
+func TestAdminApi_ImpersonationList(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	at := time.Unix(1660000000, 0)
+	session := historyPkg.Session{RealActor: "support", Actor: "ivan", FirstAt: at, LastAt: at.Add(time.Minute), Changes: 2}
+
+	cases := []struct {
+		name        string
+		req         *pb.ImpersonationListRequest
+		sessionsErr error
+		calls       int
+		expCode     codes.Code
+	}{
+		{
+			name:    "success",
+			req:     &pb.ImpersonationListRequest{Since: at.Unix(), Limit: 10},
+			calls:   1,
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, negative since",
+			req:     &pb.ImpersonationListRequest{Since: -1},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:        "failed, history disabled",
+			req:         &pb.ImpersonationListRequest{Since: at.Unix()},
+			sessionsErr: errorsPkg.ErrHistoryDisabled,
+			calls:       1,
+			expCode:     codes.FailedPrecondition,
+		},
+		{
+			name:        "failed, history crashed",
+			req:         &pb.ImpersonationListRequest{Since: at.Unix()},
+			sessionsErr: errorsPkg.ErrUnexpected,
+			calls:       1,
+			expCode:     codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().Impersonations(gomock.Any(), at, c.req.GetLimit()).
+				Return([]historyPkg.Session{session}, c.sessionsErr).Times(c.calls)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.ImpersonationList(context.Background(), c.req)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, []*pb.ImpersonationSession{{RealActor: "support", Actor: "ivan",
+					FirstAt: at.Unix(), LastAt: at.Add(time.Minute).Unix(), Changes: 2}}, resp.GetSessions())
+			}
+		})
+	}
+}
+
+func TestAdminApi_BackupCreate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	summary := backupPkg.Summary{Users: 2, Size: 4, Checksum: "abcd"}
+	cases := []struct {
+		name      string
+		store     bool
+		backupErr error
+		expChunks int
+		expCode   codes.Code
+	}{
+		{
+			name:      "success, streamed",
+			expChunks: 1,
+			expCode:   codes.OK,
+		},
+		{
+			name:    "success, stored",
+			store:   true,
+			expCode: codes.OK,
+		},
+		{
+			name:      "failed, storage disabled",
+			store:     true,
+			backupErr: backupPkg.ErrStorageDisabled,
+			expCode:   codes.FailedPrecondition,
+		},
+		{
+			name:      "failed, unexpected error",
+			backupErr: errorsPkg.ErrUnexpected,
+			expCode:   codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backup := backupMockPkg.NewMockInterface(ctl)
+			stream := apiMockPkg.NewMockAdmin_BackupCreateServer(ctl)
+			stream.EXPECT().Context().Return(context.Background()).AnyTimes()
+
+			if c.store {
+				backup.EXPECT().Store(gomock.Any(), "daily").Return(summary, c.backupErr).Times(1)
+			} else {
+				backup.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, w io.Writer) (backupPkg.Summary, error) {
+						if _, err := w.Write([]byte("data")); err != nil {
+							return backupPkg.Summary{}, err
+						}
+						return summary, c.backupErr
+					}).Times(1)
+			}
+			stream.EXPECT().Send(&pb.BackupCreateResponse{Chunk: []byte("data")}).Return(nil).Times(c.expChunks)
+			if c.backupErr == nil {
+				stream.EXPECT().Send(gomock.Any()).
+					DoAndReturn(func(resp *pb.BackupCreateResponse) error {
+						assert.Equal(t, "abcd", resp.GetSummary().GetChecksum())
+						return nil
+					}).Times(1)
+			}
+
+			server := New(nil, nil, backup, nil, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			err := server.BackupCreate(&pb.BackupCreateRequest{Store: c.store, Key: "daily"}, stream)
+			assert.Equal(t, c.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestAdminApi_BackupRestore(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	result := backupPkg.Result{Users: 2, Created: 1, Skipped: 1}
+	cases := []struct {
+		name       string
+		requests   []*pb.BackupRestoreRequest
+		restoreErr error
+		expCode    codes.Code
+	}{
+		{
+			name: "success, streamed",
+			requests: []*pb.BackupRestoreRequest{
+				{Checksum: "abcd", Overwrite: true, Chunk: []byte("da")},
+				{Chunk: []byte("ta")},
+			},
+			expCode: codes.OK,
+		},
+		{
+			name:     "success, stored",
+			requests: []*pb.BackupRestoreRequest{{Key: "daily", Checksum: "abcd", Overwrite: true}},
+			expCode:  codes.OK,
+		},
+		{
+			name:    "failed, empty request",
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:       "failed, corrupted backup",
+			requests:   []*pb.BackupRestoreRequest{{Checksum: "abcd", Overwrite: true, Chunk: []byte("data")}},
+			restoreErr: backupPkg.ErrCorrupted,
+			expCode:    codes.DataLoss,
+		},
+		{
+			name:       "failed, backup not found",
+			requests:   []*pb.BackupRestoreRequest{{Key: "daily", Checksum: "abcd", Overwrite: true}},
+			restoreErr: blob.ErrNotFound,
+			expCode:    codes.NotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backup := backupMockPkg.NewMockInterface(ctl)
+			stream := apiMockPkg.NewMockAdmin_BackupRestoreServer(ctl)
+			stream.EXPECT().Context().Return(context.Background()).AnyTimes()
+			var calls []*gomock.Call
+			for _, req := range c.requests {
+				calls = append(calls, stream.EXPECT().Recv().Return(req, nil).Times(1))
+			}
+			calls = append(calls, stream.EXPECT().Recv().Return(nil, io.EOF).AnyTimes())
+			gomock.InOrder(calls...)
+
+			switch {
+			case len(c.requests) == 0:
+			case c.requests[0].GetKey() != "":
+				backup.EXPECT().Load(gomock.Any(), "daily", "abcd", true).Return(result, c.restoreErr).Times(1)
+			default:
+				backup.EXPECT().Restore(gomock.Any(), gomock.Any(), "abcd", true).
+					DoAndReturn(func(_ context.Context, r io.Reader, _ string, _ bool) (backupPkg.Result, error) {
+						data, err := io.ReadAll(r)
+						assert.NoError(t, err)
+						assert.Equal(t, "data", string(data))
+						return result, c.restoreErr
+					}).Times(1)
+			}
+			if c.expCode == codes.OK {
+				stream.EXPECT().SendAndClose(&pb.BackupRestoreResponse{Users: 2, Created: 1, Skipped: 1}).
+					Return(nil).Times(1)
+			}
+
+			server := New(nil, nil, backup, nil, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			assert.Equal(t, c.expCode, status.Code(server.BackupRestore(stream)))
+		})
+	}
+}
+
+func TestAdminApi_MaintenanceSet(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      *pb.MaintenanceSetRequest
+		expCode codes.Code
+	}{
+		{
+			name:    "success, enabled",
+			in:      &pb.MaintenanceSetRequest{Enabled: true, Reason: "upgrade"},
+			expCode: codes.OK,
+		},
+		{
+			name:    "success, disabled",
+			in:      &pb.MaintenanceSetRequest{},
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, no reason",
+			in:      &pb.MaintenanceSetRequest{Enabled: true},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mode := maintenancePkg.New(maintenancePkg.NewMemory(), loggerPkg.NewFatal())
+			server := New(nil, nil, nil, nil, mode, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+
+			resp, err := server.MaintenanceSet(context.Background(), c.in)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode != codes.OK {
+				return
+			}
+			assert.Equal(t, c.in.GetEnabled(), resp.GetState().GetEnabled())
+			assert.Equal(t, c.in.GetReason(), resp.GetState().GetReason())
+
+			got, err := server.MaintenanceGet(context.Background(), &pb.MaintenanceGetRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, resp.GetState().GetEnabled(), got.GetState().GetEnabled())
+		})
+	}
+}
+
+func TestAdminApi_VerifyData(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name      string
+		report    verifyPkg.Report
+		verifyErr error
+		expCode   codes.Code
+	}{
+		{
+			name: "success",
+			report: verifyPkg.Report{Checked: 2, Found: 1, Repaired: 1, Violations: []verifyPkg.Violation{
+				{Class: verifyPkg.ClassMissingField, Name: "ivan", Detail: "field: [status] is empty", Repaired: true},
+			}},
+			expCode: codes.OK,
+		},
+		{
+			name:      "failed, repository error",
+			verifyErr: errorsPkg.ErrUnexpected,
+			expCode:   codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verifier := verifyMockPkg.NewMockInterface(ctl)
+			verifier.EXPECT().Verify(gomock.Any(), true).Return(c.report, c.verifyErr).Times(1)
+
+			server := New(nil, nil, nil, nil, nil, verifier, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.VerifyData(context.Background(), &pb.VerifyDataRequest{Repair: true})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.verifyErr == nil {
+				assert.Equal(t, c.report.Checked, resp.GetChecked())
+				assert.Equal(t, c.report.Found, resp.GetFound())
+				assert.Len(t, resp.GetViolations(), len(c.report.Violations))
+				assert.True(t, resp.GetViolations()[0].GetRepaired())
+			}
+		})
+	}
+}
+
+func TestAdminApi_AnomalyConfirm(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	alert := anomalyPkg.Alert{Kind: anomalyPkg.KindMassDelete, Actor: "bot", Count: 101, RaisedAt: time.Unix(1660412960, 0)}
+
+	cases := []struct {
+		name       string
+		disabled   bool
+		confirmErr error
+		expCode    codes.Code
+	}{
+		{
+			name:    "success",
+			expCode: codes.OK,
+		},
+		{
+			name:     "failed, detection is disabled",
+			disabled: true,
+			expCode:  codes.FailedPrecondition,
+		},
+		{
+			name:       "failed, maintenance store error",
+			confirmErr: errorsPkg.ErrUnexpected,
+			expCode:    codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var server pb.AdminServer
+			if c.disabled {
+				server = New(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			} else {
+				anomaly := anomalyMockPkg.NewMockInterface(ctl)
+				anomaly.EXPECT().Confirm(gomock.Any(), gomock.Any()).
+					Return([]anomalyPkg.Alert{alert}, maintenancePkg.State{}, c.confirmErr).Times(1)
+				server = New(nil, nil, nil, nil, nil, nil, anomaly, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			}
+
+			resp, err := server.AnomalyConfirm(context.Background(), &pb.AnomalyConfirmRequest{})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, []*pb.AnomalyAlert{{Kind: alert.Kind, Actor: "bot", Count: 101, RaisedAt: 1660412960}}, resp.GetConfirmed())
+				assert.False(t, resp.GetMaintenance().GetEnabled())
+			}
+		})
+	}
+}
+
+func TestAdminApi_OperationApprove(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	op := approvalPkg.Operation{ID: "42", Kind: approvalPkg.KindPasswordExpire, Requester: "bot",
+		Attributes: map[string]string{"org": "ozon"}, CreatedAt: 1660412960, ExpiresAt: 1660499360}
+
+	cases := []struct {
+		name       string
+		approveErr error
+		expCode    codes.Code
+	}{
+		{
+			name:    "success",
+			expCode: codes.OK,
+		},
+		{
+			name:       "failed, operation not found",
+			approveErr: approvalPkg.ErrNotFound,
+			expCode:    codes.NotFound,
+		},
+		{
+			name:       "failed, approved by requester",
+			approveErr: approvalPkg.ErrSelfApproval,
+			expCode:    codes.PermissionDenied,
+		},
+		{
+			name:       "failed, store error",
+			approveErr: errorsPkg.ErrUnexpected,
+			expCode:    codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			approval := approvalMockPkg.NewMockInterface(ctl)
+			approval.EXPECT().Approve(gomock.Any(), "42").Return(op, uint64(150), c.approveErr).Times(1)
+			server := New(nil, nil, nil, nil, nil, nil, nil, approval, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+
+			resp, err := server.OperationApprove(context.Background(), &pb.OperationApproveRequest{Id: "42"})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, "42", resp.GetOperation().GetId())
+				assert.Equal(t, op.Attributes, resp.GetOperation().GetAttributes())
+				assert.Equal(t, uint64(150), resp.GetExpired())
+			}
+		})
+	}
+}
+
+func TestAdminApi_TenantOverridesSet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	overrides := tenantPkg.Overrides{Tenant: "staff", MaxPasswordAttempts: 3,
+		Password: &passwordPkg.Policy{MinLength: 16, MaxAge: time.Hour}, Features: map[string]bool{tenantPkg.FeatureAvatars: false}}
+
+	cases := []struct {
+		name    string
+		setErr  error
+		expCode codes.Code
+	}{
+		{
+			name:    "success",
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, invalid overrides",
+			setErr:  errorsPkg.ErrValidation,
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, store error",
+			setErr:  errorsPkg.ErrUnexpected,
+			expCode: codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tenants := tenantMockPkg.NewMockInterface(ctl)
+			tenants.EXPECT().Set(gomock.Any(), overrides, gomock.Any()).Return(overrides, c.setErr).Times(1)
+			server := New(nil, nil, nil, nil, nil, nil, nil, nil, tenants, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+
+			resp, err := server.TenantOverridesSet(context.Background(),
+				&pb.TenantOverridesSetRequest{Overrides: tenantPkg.ToPb(overrides)})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, int64(3600), resp.GetOverrides().GetPassword().GetMaxAge())
+				assert.Equal(t, map[string]bool{tenantPkg.FeatureAvatars: false}, resp.GetOverrides().GetFeatures())
+			}
+		})
+	}
+}
+
+func TestAdminApi_CacheGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	cached := models.CachedUser{User: modeltest.Ivan(), TTL: 42 * time.Second}
+
+	cases := []struct {
+		name    string
+		getErr  error
+		expCode codes.Code
+	}{
+		{
+			name:    "success",
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, not cached",
+			getErr:  errorsPkg.ErrUserNotFound,
+			expCode: codes.NotFound,
+		},
+		{
+			name:    "failed, cache error",
+			getErr:  errorsPkg.ErrUnexpected,
+			expCode: codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().CacheGet(gomock.Any(), cached.User.Name).Return(cached, c.getErr).Times(1)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.CacheGet(context.Background(), &pb.CacheGetRequest{Name: cached.User.Name})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.getErr == nil {
+				assert.Equal(t, cached.User.ID, resp.GetUser().GetId())
+				assert.Empty(t, resp.GetUser().GetPassword())
+				assert.Equal(t, int64(42), resp.GetTtl())
+			}
+		})
+	}
+}
+
+func TestAdminApi_CacheEvict(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name       string
+		evicted    bool
+		evictErr   error
+		expCode    codes.Code
+		expEvicted bool
+	}{
+		{
+			name:       "success",
+			evicted:    true,
+			expCode:    codes.OK,
+			expEvicted: true,
+		},
+		{
+			name:    "success, not cached",
+			expCode: codes.OK,
+		},
+		{
+			name:     "failed, invalid name",
+			evictErr: errorsPkg.ErrValidation,
+			expCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			user.EXPECT().CacheEvict(gomock.Any(), "ivan").Return(c.evicted, c.evictErr).Times(1)
+
+			server := New(nil, nil, nil, user, nil, nil, nil, nil, nil, nil, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			resp, err := server.CacheEvict(context.Background(), &pb.CacheEvictRequest{Name: "ivan"})
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expEvicted, resp.GetEvicted())
+		})
+	}
+}
+
+type filterStates struct {
+	state bloomPkg.State
+	built bool
+}
+
+func (f filterStates) FilterState() (bloomPkg.State, bool) {
+	return f.state, f.built
+}
+
+func TestAdminApi_CacheStateExport(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	filter := bloomPkg.New(100000, 0.01)
+	filter.Add("ivan")
+	state := filter.State()
+
+	cases := []struct {
+		name    string
+		names   bloomRepoPkg.Exporter
+		expSent int
+		expCode codes.Code
+	}{
+		{
+			name:    "success, in chunks",
+			names:   filterStates{state: state, built: true},
+			expSent: (len(state.Bits) + cacheStateChunk - 1) / cacheStateChunk,
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, not built",
+			names:   filterStates{},
+			expCode: codes.Unavailable,
+		},
+		{
+			name:    "failed, disabled",
+			expCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stream := apiMockPkg.NewMockAdmin_CacheStateExportServer(ctl)
+			stream.EXPECT().Context().Return(context.Background()).AnyTimes()
+			var received []*pb.CacheStateExportResponse
+			stream.EXPECT().Send(gomock.Any()).
+				DoAndReturn(func(resp *pb.CacheStateExportResponse) error {
+					received = append(received, resp)
+					return nil
+				}).Times(c.expSent)
+
+			server := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, c.names, nil, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+			err := server.CacheStateExport(&pb.CacheStateExportRequest{}, stream)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expSent == 0 {
+				return
+			}
+
+			var got bloomPkg.State
+			for i, resp := range received {
+				got.Size, got.Hashes = resp.GetSize(), resp.GetHashes()
+				got.Bits = append(got.Bits, resp.GetBits()...)
+				// only the last message has the checksum
+				assert.Equal(t, i == len(received)-1, resp.GetChecksum() != "")
+			}
+			assert.Equal(t, state.Checksum(), received[len(received)-1].GetChecksum())
+			assert.Equal(t, state.Checksum(), got.Checksum())
+		})
+	}
+}
+
+func TestAdminApi_UsageReport(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	report := []usagePkg.ClientUsage{{Client: "shop", Requests: 4, Errors: 1,
+		Methods: []usagePkg.Usage{{Client: "shop", Method: "/ozon.dev.homework.api.User/UserGet", Requests: 4, Errors: 1}}}}
+
+	cases := []struct {
+		name      string
+		disabled  bool
+		in        *pb.UsageReportRequest
+		expReport bool
+		expCode   codes.Code
+	}{
+		{
+			name:      "success, the period since falls into is reported",
+			in:        &pb.UsageReportRequest{Since: 7300, Until: 10800, Client: "shop"},
+			expReport: true,
+			expCode:   codes.OK,
+		},
+		{
+			name:     "failed, usage reporting is disabled",
+			disabled: true,
+			in:       &pb.UsageReportRequest{},
+			expCode:  codes.FailedPrecondition,
+		},
+		{
+			name:    "failed, since is not before until",
+			in:      &pb.UsageReportRequest{Since: 10800, Until: 7200},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var usage usagePkg.Interface
+			if !c.disabled {
+				mock := usageMockPkg.NewMockInterface(ctl)
+				if c.expReport {
+					mock.EXPECT().Report(gomock.Any(), int64(7200), int64(10800), "shop").Return(report, nil).Times(1)
+				}
+				usage = mock
+			}
+			server := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, usage, nil, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+
+			resp, err := server.UsageReport(context.Background(), c.in)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Len(t, resp.GetClients(), 1)
+				assert.Equal(t, 0.25, resp.GetClients()[0].GetErrorRate())
+			}
+		})
+	}
+}
+
+func TestAdminApi_UserGenerate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	report := fakePkg.Report{
+		Created: []string{"demo_ivan.petrov1234"},
+		Failed:  []string{"demo_anna.ivanova4321: user already exists"},
+	}
+
+	cases := []struct {
+		name     string
+		disabled bool
+		err      error
+		expCode  codes.Code
+	}{
+		{
+			name:    "success, failed users are reported",
+			expCode: codes.OK,
+		},
+		{
+			name:     "failed, generation is disabled",
+			disabled: true,
+			expCode:  codes.FailedPrecondition,
+		},
+		{
+			name:    "failed, count above the limit",
+			err:     errors.Wrap(errorsPkg.ErrValidation, "field: [count] must be from 1 to 10000"),
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, canceled",
+			err:     context.Canceled,
+			expCode: codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var generator fakePkg.Interface
+			if !c.disabled {
+				mock := fakeMockPkg.NewMockInterface(ctl)
+				mock.EXPECT().Generate(gomock.Any(), 2, "demo_").Return(report, c.err).Times(1)
+				generator = mock
+			}
+			server := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, generator, grpcPkg.ResponseSizeConfig{}, loggerPkg.NewFatal())
+
+			resp, err := server.UserGenerate(context.Background(), &pb.UserGenerateRequest{Count: 2, Prefix: "demo_"})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, report.Created, resp.GetCreated())
+				assert.Equal(t, report.Failed, resp.GetFailed())
+			}
+		})
+	}
+}
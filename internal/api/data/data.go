@@ -2,6 +2,8 @@ package data
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
@@ -10,22 +12,59 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
 	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
 	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
 	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 )
 
-func New(user userPkg.Interface, logger *zap.SugaredLogger) pb.UserServer {
+const (
+	// getIfChangedMaxWait caps the wait of UserGetIfChanged, it is used without a deadline as well
+	getIfChangedMaxWait = 30 * time.Second
+	// getIfChangedMargin is left of the deadline to answer not_modified in time
+	getIfChangedMargin = 100 * time.Millisecond
+	// userChangesLimit is a page of UserChanges, if the request has no limit
+	userChangesLimit = 500
+)
+
+// New returns the user service, page tokens of UserAllList are disabled with nil pages.
+// The clock tells the time UserChanges are exported at.
+func New(
+	user userPkg.Interface,
+	logger *zap.SugaredLogger,
+	avatarCfg avatarPkg.Config,
+	methods *grpcPkg.Methods,
+	pages *pagetokenPkg.Codec,
+	size grpcPkg.ResponseSizeConfig,
+	clk clock.Clock,
+) pb.UserServer {
 	return &core{
-		user:   user,
-		logger: logger,
+		user:      user,
+		logger:    logger,
+		avatarCfg: avatarCfg,
+		methods:   methods,
+		pages:     pages,
+		size:      size,
+		clock:     clk,
 	}
 }
 
 type core struct {
-	user   userPkg.Interface
-	logger *zap.SugaredLogger
+	user      userPkg.Interface
+	logger    *zap.SugaredLogger
+	avatarCfg avatarPkg.Config
+	methods   *grpcPkg.Methods
+	pages     *pagetokenPkg.Codec
+	size      grpcPkg.ResponseSizeConfig
+	clock     clock.Clock
 	pb.UnimplementedUserServer
 }
 
@@ -33,25 +72,124 @@ func (c *core) UserAllList(in *pb.UserAllListRequest, stream pb.User_UserAllList
 	meta := grpcPkg.GetMetaFromContext(stream.Context())
 	c.logger.Debugln(meta, "all users list", in.GetOrder(), in.GetLimit())
 
-	offset := uint64(0)
+	// chunks start after the last name, the token of the chunk resumes the list after it
+	listFilter := pagetokenPkg.Filter(filter.ListKey(in.GetAttributes(), in.GetStatus()))
+	var after string
+	if token := in.GetPageToken(); token != "" {
+		cursor, err := c.pages.Decode(token, in.GetOrder(), listFilter)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		after = cursor.Last
+	}
+
+	// pages are read from the same snapshot, so users written meanwhile are not skipped or repeated;
+	// all users are listed by design, so deep pages are not limited by the scan budget
+	listCtx, release := repoPkg.WithListSnapshot(repoPkg.WithoutScanBudget(stream.Context()))
+	defer release()
+	batch := adaptor.GetUserBatch()
+	defer batch.Release()
 	for {
-		users, err := c.user.List(stream.Context(), in.GetOrder(), in.GetLimit(), offset)
+		ctx, partial := repoPkg.WithPartial(listCtx)
+		users, err := c.user.ListAfter(ctx, in.GetOrder(), in.GetLimit(), after, in.GetAttributes(), in.GetStatus())
 		if err != nil {
-			c.logger.Errorln(meta, "get list", err)
-			return status.Error(codes.Internal, err.Error())
+			if errors.Is(err, errorsPkg.ErrValidation) {
+				return apperr.Status(codes.InvalidArgument, err)
+			}
+			c.logger.Errorw("all users list", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
 		}
 
 		if len(users) == 0 {
 			return nil
 		}
+		for i := range users {
+			users[i].Password = ""
+		}
+
+		after = users[len(users)-1].Name
+		// chunks over the response size are split, every part resumes after its last user
+		for chunk := batch.Fill(users); len(chunk) != 0; {
+			n := grpcPkg.FitUsers(chunk, c.size)
+			if err = stream.Send(&pb.UserAllListResponse{
+				Users:         chunk[:n],
+				Partial:       partial.IsPartial(),
+				NextPageToken: c.pages.Encode(chunk[n-1].GetName(), in.GetOrder(), listFilter),
+			}); err != nil {
+				c.logger.Errorln(meta, "all users list, send chunk", err)
+				return status.Error(codes.Internal, err.Error())
+			}
+			chunk = chunk[n:]
+		}
+	}
+}
+
+// UserChanges sends tombstones first, so a user deleted and created again with the same name
+// is applied in order. Password hashes are never sent.
+func (c *core) UserChanges(in *pb.UserChangesRequest, stream pb.User_UserChangesServer) error {
+	ctx := stream.Context()
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Debugln(meta, "user changes", in.GetSince(), in.GetLimit())
+
+	if in.GetSince() < 0 {
+		return status.Error(codes.InvalidArgument, "field: [since] must not be negative")
+	}
+	limit := in.GetLimit()
+	if limit == 0 {
+		limit = userChangesLimit
+	}
+	// users written during the export are returned by the next sync again, since is inclusive
+	nextSince := c.clock.Now().Unix()
+
+	var tombstones []models.Tombstone
+	if in.GetIncludeDeleted() {
+		var err error
+		if tombstones, err = c.user.Tombstones(ctx, in.GetSince()); err != nil {
+			if errors.Is(err, errorsPkg.ErrTombstonesPruned) {
+				return apperr.Status(codes.FailedPrecondition, err)
+			}
+			c.logger.Errorw("user changes", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+	}
+	first := &pb.UserChangesResponse{
+		Deleted:   make([]*pb.UserTombstone, 0, len(tombstones)),
+		NextSince: nextSince,
+	}
+	for _, tombstone := range tombstones {
+		first.Deleted = append(first.Deleted, &pb.UserTombstone{
+			Id:        tombstone.ID,
+			Name:      tombstone.Name,
+			DeletedAt: tombstone.DeletedAt,
+		})
+	}
+	if err := stream.Send(first); err != nil {
+		c.logger.Errorln(meta, "user changes, send tombstones", err)
+		return status.Error(codes.Internal, err.Error())
+	}
 
-		if err = stream.Send(&pb.UserAllListResponse{
-			Users: adaptor.ToUserListPbModel(users),
+	batch := adaptor.GetUserBatch()
+	defer batch.Release()
+	var after string
+	for {
+		users, err := c.user.Changes(ctx, in.GetSince(), after, limit)
+		if err != nil {
+			c.logger.Errorw("user changes", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		for i := range users {
+			users[i].Password = ""
+		}
+		if err = stream.Send(&pb.UserChangesResponse{
+			Users: batch.Fill(users),
 		}); err != nil {
-			c.logger.Errorln(meta, "all users list, send chunk", err)
+			c.logger.Errorln(meta, "user changes, send chunk", err)
 			return status.Error(codes.Internal, err.Error())
 		}
-		offset++
+		after = users[len(users)-1].Name
 	}
 }
 
@@ -67,3 +205,422 @@ func (c *core) Data(ctx context.Context, in *pb.DataRequest) (*pb.DataResponse,
 		},
 	}, nil
 }
+
+func (c *core) UserAvatarUpload(stream pb.User_UserAvatarUploadServer) error {
+	meta := grpcPkg.GetMetaFromContext(stream.Context())
+	maxSize := avatarPkg.MaxSize(c.avatarCfg)
+
+	var (
+		name string
+		data []byte
+	)
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.logger.Errorln(meta, "avatar upload, receive chunk", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+		if name == "" {
+			name = in.GetName()
+		}
+		data = append(data, in.GetChunk()...)
+		if int64(len(data)) > maxSize {
+			return status.Errorf(codes.InvalidArgument, "avatar is bigger than %d bytes", maxSize)
+		}
+	}
+	c.logger.Debugln(meta, "avatar upload", name, len(data))
+
+	url, err := c.user.AvatarUpload(stream.Context(), name, data)
+	if err != nil {
+		return c.avatarError(meta, "avatar upload", err)
+	}
+	return stream.SendAndClose(&pb.UserAvatarUploadResponse{
+		Url: url,
+	})
+}
+
+func (c *core) UserAvatarGet(ctx context.Context, in *pb.UserAvatarGetRequest) (*pb.UserAvatarGetResponse, error) {
+	obj, data, err := c.user.AvatarGet(ctx, in.GetName())
+	if err != nil {
+		return nil, c.avatarError(grpcPkg.GetMetaFromContext(ctx), "avatar get", err)
+	}
+	return &pb.UserAvatarGetResponse{
+		ContentType: obj.ContentType,
+		Data:        data,
+	}, nil
+}
+
+// UserImport applies imported users one by one and sends the result of every row back.
+// Row errors are reported in responses, best-effort import is stopped on conflict with fail strategy only.
+// Atomic import receives all rows first and writes them, only if every row passes the import checks.
+func (c *core) UserImport(stream pb.User_UserImportServer) error {
+	meta := grpcPkg.GetMetaFromContext(stream.Context())
+	c.logger.Debugln(meta, "user import")
+
+	var (
+		strategy models.ImportStrategy
+		rows     []*pb.UserImportRequest
+	)
+	for row := uint64(1); ; row++ {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.logger.Errorln(meta, "user import, receive row", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+		if row == 1 {
+			strategy = models.ImportStrategy(in.GetStrategy().String())
+		}
+		if len(rows) != 0 || (row == 1 && in.GetAtomic()) {
+			rows = append(rows, in)
+			continue
+		}
+
+		resp := c.importRow(stream.Context(), meta, row, in, strategy)
+		if err = stream.Send(resp); err != nil {
+			c.logger.Errorln(meta, "user import, send result", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+		if strategy == models.ImportFail && resp.GetResult().GetCode() == uint32(codes.AlreadyExists) {
+			return status.Errorf(codes.FailedPrecondition, "row %d: user [%s] already exists", row, resp.Name)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return c.importAtomic(stream, meta, rows, strategy)
+}
+
+// importAtomic checks all rows first, if one fails, the others are reported as aborted and nothing is written.
+// Rows are written after the checks, so only a storage failure can leave the import partially applied,
+// the failed row and the rest are reported then.
+func (c *core) importAtomic(stream pb.User_UserImportServer, meta string, rows []*pb.UserImportRequest, strategy models.ImportStrategy) error {
+	ctx := stream.Context()
+	resps := make([]*pb.UserImportResponse, len(rows))
+	failed := 0
+	names := make(map[string]struct{}, len(rows))
+	for i, in := range rows {
+		resp := &pb.UserImportResponse{Row: uint64(i + 1), Name: in.GetUser().GetName()}
+		importErr := errors.Wrap(errorsPkg.ErrValidation, "field: [user] cannot be empty")
+		importStatus := models.ImportFailed
+		if in.GetUser() != nil {
+			importStatus, importErr = c.user.ImportCheck(ctx, *adaptor.ToUserCoreModel(in.GetUser()), strategy)
+		}
+		// the earlier row of the batch takes the name, as if it was imported already
+		if _, ok := names[resp.Name]; ok && importErr == nil && strategy == models.ImportFail {
+			importStatus, importErr = models.ImportFailed, errors.Wrapf(errorsPkg.ErrUserAlreadyExists, "user [%s] is imported by an earlier row", resp.Name)
+		}
+		names[resp.Name] = struct{}{}
+		if importErr != nil {
+			failed++
+			c.fillImportResult(meta, resp, importStatus, importErr)
+		}
+		resps[i] = resp
+	}
+
+	for i, in := range rows {
+		resp := resps[i]
+		switch {
+		case resp.GetResult() != nil:
+		case failed != 0:
+			resp.Status = string(models.ImportFailed)
+			resp.Error = "not imported, another row of the atomic import failed"
+			resp.Result = &pb.BatchResult{Index: uint64(i), Code: uint32(codes.Aborted), Reason: resp.Error}
+		default:
+			*resp = *c.importRow(ctx, meta, uint64(i+1), in, strategy)
+			if resp.GetResult().GetCode() != uint32(codes.OK) {
+				failed++
+			}
+		}
+		if err := stream.Send(resp); err != nil {
+			c.logger.Errorln(meta, "user import, send result", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	if failed != 0 {
+		return status.Errorf(codes.Aborted, "atomic import: %d of %d rows failed", failed, len(rows))
+	}
+	return nil
+}
+
+// importRow imports the user of the row.
+func (c *core) importRow(ctx context.Context, meta string, row uint64, in *pb.UserImportRequest, strategy models.ImportStrategy) *pb.UserImportResponse {
+	resp := &pb.UserImportResponse{
+		Row:  row,
+		Name: in.GetUser().GetName(),
+	}
+	importErr := errors.Wrap(errorsPkg.ErrValidation, "field: [user] cannot be empty")
+	importStatus := models.ImportFailed
+	if in.GetUser() != nil {
+		importStatus, importErr = c.user.Import(ctx, *adaptor.ToUserCoreModel(in.GetUser()), strategy)
+	}
+	c.fillImportResult(meta, resp, importStatus, importErr)
+	return resp
+}
+
+// fillImportResult sets the status and the batch result of the row.
+func (c *core) fillImportResult(meta string, resp *pb.UserImportResponse, importStatus models.ImportStatus, importErr error) {
+	resp.Status = string(importStatus)
+	resp.Result = &pb.BatchResult{Index: resp.Row - 1, Code: uint32(codes.OK)}
+	if importErr == nil {
+		return
+	}
+	resp.Error = apperr.Message(importErr)
+	resp.Result.Reason = resp.Error
+	switch {
+	case errors.Is(importErr, errorsPkg.ErrValidation):
+		resp.Result.Code = uint32(codes.InvalidArgument)
+	case errors.Is(importErr, errorsPkg.ErrUserAlreadyExists):
+		resp.Result.Code = uint32(codes.AlreadyExists)
+	default:
+		resp.Result.Code = uint32(codes.Internal)
+		c.logger.Errorw("user import", append(apperr.Fields(importErr), "meta", meta, "row", resp.Row)...)
+	}
+}
+
+func (c *core) UserCheckPassword(ctx context.Context, in *pb.UserCheckPasswordRequest) (*pb.UserCheckPasswordResponse, error) {
+	valid, err := c.user.CheckPassword(ctx, in.GetName(), in.GetPassword())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrTooManyAttempts):
+			return nil, apperr.Status(codes.ResourceExhausted, err)
+		case errors.Is(err, errorsPkg.ErrPasswordExpired):
+			return nil, apperr.StatusReason(codes.FailedPrecondition, apperr.ReasonPasswordExpired, err)
+		default:
+			c.logger.Errorw("check password", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+			return nil, apperr.Status(codes.Internal, err)
+		}
+	}
+	return &pb.UserCheckPasswordResponse{
+		Valid: valid,
+	}, nil
+}
+
+func (c *core) UserLoginExternal(ctx context.Context, in *pb.UserLoginExternalRequest) (*pb.UserLoginExternalResponse, error) {
+	session, err := c.user.LoginExternal(ctx, models.Identity{
+		Issuer:            in.GetIssuer(),
+		Subject:           in.GetSubject(),
+		Email:             in.GetEmail(),
+		Name:              in.GetName(),
+		PreferredUsername: in.GetPreferredUsername(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.PermissionDenied, err)
+		case errors.Is(err, errorsPkg.ErrUserAlreadyExists):
+			return nil, apperr.Status(codes.AlreadyExists, err)
+		default:
+			c.logger.Errorw("login external", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+			return nil, apperr.Status(codes.Internal, err)
+		}
+	}
+	return &pb.UserLoginExternalResponse{
+		Token:     session.Token,
+		Name:      session.Name,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (c *core) UserGetIfChanged(ctx context.Context, in *pb.UserGetIfChangedRequest) (*pb.UserGetIfChangedResponse, error) {
+	wait := getIfChangedMaxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline) - getIfChangedMargin; left < wait {
+			wait = left
+		}
+	}
+
+	user, changed, err := c.user.GetIfChanged(ctx, in.GetName(), in.GetKnownVersion(), wait)
+	if err != nil {
+		switch {
+		case errors.Is(err, errorsPkg.ErrValidation):
+			return nil, apperr.Status(codes.InvalidArgument, err)
+		case errors.Is(err, errorsPkg.ErrUserNotFound):
+			return nil, apperr.Status(codes.NotFound, err)
+		default:
+			c.logger.Errorw("get if changed", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+			return nil, apperr.Status(codes.Internal, err)
+		}
+	}
+	if !changed {
+		return &pb.UserGetIfChangedResponse{
+			Version:     in.GetKnownVersion(),
+			NotModified: true,
+		}, nil
+	}
+	version := user.Version()
+	user.Password = ""
+	return &pb.UserGetIfChangedResponse{
+		User:    adaptor.ToUserPbModel(user),
+		Version: version,
+	}, nil
+}
+
+func (c *core) UserCount(ctx context.Context, in *pb.UserCountRequest) (*pb.UserCountResponse, error) {
+	count, total, err := c.user.Count(ctx, in.GetTenant())
+	if err != nil {
+		c.logger.Errorw("user count", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+	return &pb.UserCountResponse{
+		Count: count,
+		Total: total,
+	}, nil
+}
+
+// meUpdatePaths are the fields the user edits itself, attributes carry the role and are left
+// to admins, the password is changed by MeChangePassword.
+var meUpdatePaths = []string{models.MaskEmail, models.MaskFullName}
+
+// me returns the user of the session token and the context acting on its behalf.
+func (c *core) me(ctx context.Context) (context.Context, models.User, error) {
+	user, err := c.user.SessionUser(ctx, grpcPkg.SessionFromContext(ctx))
+	if err != nil {
+		return ctx, models.User{}, err
+	}
+	return grpcPkg.WithIdentity(ctx, grpcPkg.Identity{Real: user.Name}), user, nil
+}
+
+func (c *core) MeGet(ctx context.Context, _ *pb.MeGetRequest) (*pb.MeGetResponse, error) {
+	_, user, err := c.me(ctx)
+	if err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me get", err)
+	}
+	user.Password = ""
+	return &pb.MeGetResponse{
+		User: adaptor.ToUserPbModel(user),
+	}, nil
+}
+
+func (c *core) MeUpdate(ctx context.Context, in *pb.MeUpdateRequest) (*pb.MeUpdateResponse, error) {
+	ctx, user, err := c.me(ctx)
+	if err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me update", err)
+	}
+	paths := in.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = meUpdatePaths
+	}
+	for _, path := range paths {
+		if path != models.MaskEmail && path != models.MaskFullName {
+			return nil, status.Errorf(codes.InvalidArgument, "field: [update_mask] path [%s] is not allowed, only [email] and [full_name] are", path)
+		}
+	}
+
+	profile := in.GetProfile()
+	for _, path := range paths {
+		switch path {
+		case models.MaskEmail:
+			if err = models.ValidateEmail(profile.GetEmail()); err != nil {
+				return nil, apperr.Status(codes.InvalidArgument, err)
+			}
+		case models.MaskFullName:
+			if profile.GetFullName() == "" {
+				return nil, status.Error(codes.InvalidArgument, "field: [full_name] is empty")
+			}
+		}
+	}
+
+	if err = c.user.Update(ctx, models.UserUpdate{
+		User: models.User{Name: user.Name, Email: profile.GetEmail(), FullName: profile.GetFullName()},
+		Mask: paths,
+	}); err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me update", err)
+	}
+	if user, err = c.user.Get(ctx, user.Name); err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me update", err)
+	}
+	user.Password = ""
+	return &pb.MeUpdateResponse{
+		User: adaptor.ToUserPbModel(user),
+	}, nil
+}
+
+func (c *core) MeDelete(ctx context.Context, _ *pb.MeDeleteRequest) (*pb.MeDeleteResponse, error) {
+	ctx, user, err := c.me(ctx)
+	if err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me delete", err)
+	}
+	if err = c.user.Delete(ctx, user.Name); err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me delete", err)
+	}
+	// the session is not resolved for a deleted user anyway, it is removed not to wait for expiration
+	if err = c.user.Logout(ctx, grpcPkg.SessionFromContext(ctx)); err != nil {
+		c.logger.Errorw("me delete logout", append(apperr.Fields(err), "meta", grpcPkg.GetMetaFromContext(ctx))...)
+	}
+	return &pb.MeDeleteResponse{}, nil
+}
+
+func (c *core) MeChangePassword(ctx context.Context, in *pb.MeChangePasswordRequest) (*pb.MeChangePasswordResponse, error) {
+	ctx, user, err := c.me(ctx)
+	if err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me change password", err)
+	}
+	if in.GetNewPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "field: [new_password] is empty")
+	}
+
+	// the old password is checked even with a valid session, so a left open session does not
+	// take over the account; an expired password is the reason to change it
+	valid, err := c.user.CheckPassword(ctx, user.Name, in.GetOldPassword())
+	switch {
+	case errors.Is(err, errorsPkg.ErrPasswordExpired):
+		valid = true
+	case err != nil:
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me change password", err)
+	}
+	if !valid {
+		return nil, status.Error(codes.PermissionDenied, "old password is invalid")
+	}
+
+	if err = c.user.Update(ctx, models.UserUpdate{
+		User: models.User{Name: user.Name, Password: in.GetNewPassword()},
+		Mask: []string{models.MaskPassword},
+	}); err != nil {
+		return nil, c.meError(grpcPkg.GetMetaFromContext(ctx), "me change password", err)
+	}
+	return &pb.MeChangePasswordResponse{}, nil
+}
+
+func (c *core) ServiceInfo(context.Context, *pb.ServiceInfoRequest) (*pb.ServiceInfoResponse, error) {
+	return c.methods.Info(), nil
+}
+
+func (c *core) avatarError(meta, msg string, err error) error {
+	switch {
+	case errors.Is(err, errorsPkg.ErrValidation):
+		return apperr.Status(codes.InvalidArgument, err)
+	case errors.Is(err, errorsPkg.ErrUserNotFound), errors.Is(err, errorsPkg.ErrAvatarNotFound):
+		return apperr.Status(codes.NotFound, err)
+	case errors.Is(err, errorsPkg.ErrAvatarsDisabled):
+		return apperr.Status(codes.Unimplemented, err)
+	default:
+		c.logger.Errorw(msg, append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+}
+
+func (c *core) meError(meta, msg string, err error) error {
+	switch {
+	case errors.Is(err, errorsPkg.ErrUnauthenticated):
+		return apperr.Status(codes.Unauthenticated, err)
+	case errors.Is(err, errorsPkg.ErrValidation):
+		return apperr.Status(codes.InvalidArgument, err)
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		return apperr.Status(codes.NotFound, err)
+	case errors.Is(err, errorsPkg.ErrTooManyAttempts):
+		return apperr.Status(codes.ResourceExhausted, err)
+	default:
+		c.logger.Errorw(msg, append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+}
@@ -2,22 +2,54 @@ package data
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
 	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
 	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
 	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 )
 
+// protoEq matches messages by proto.Equal, sizes of sent messages are cached in them.
+func protoEq(m proto.Message) gomock.Matcher {
+	return protoMatcher{m}
+}
+
+type protoMatcher struct {
+	m proto.Message
+}
+
+func (p protoMatcher) Matches(x interface{}) bool {
+	m, ok := x.(proto.Message)
+	return ok && proto.Equal(p.m, m)
+}
+
+func (p protoMatcher) String() string {
+	return fmt.Sprintf("proto equal to %v", p.m)
+}
+
 func TestDataApi_UserAllList(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -65,16 +97,16 @@ func TestDataApi_UserAllList(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			mockUser := userMockPkg.NewMockInterface(ctl)
 			mockStream := apiMockPkg.NewMockUser_UserAllListServer(ctl)
-			userCtl := New(mockUser, loggerPkg.NewFatal())
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
 
 			gomock.InOrder(
 				mockStream.EXPECT().Context().Return(ctx).Times(2),
-				mockUser.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				mockUser.EXPECT().ListAfter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(c.first, c.listErr).Times(1),
-				mockStream.EXPECT().Send(c.toSend).
+				mockStream.EXPECT().Send(protoEq(c.toSend)).
 					Return(c.sendErr).MaxTimes(1),
 				mockStream.EXPECT().Context().Return(ctx).MaxTimes(1),
-				mockUser.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				mockUser.EXPECT().ListAfter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(c.second, c.listErr).MaxTimes(1),
 			)
 			err := userCtl.UserAllList(&pb.UserAllListRequest{}, mockStream)
@@ -83,3 +115,712 @@ func TestDataApi_UserAllList(t *testing.T) {
 		})
 	}
 }
+
+func TestDataApi_UserAllListPageToken(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+	pages := pagetokenPkg.New(pagetokenPkg.Config{Secret: "secret"}, clock.NewFake(time.Unix(1660412960, 0)))
+	attributes := map[string]string{"team": "core"}
+	listFilter := pagetokenPkg.Filter(filter.ListKey(attributes, ""))
+
+	cases := []struct {
+		name     string
+		token    string
+		expAfter string
+		expCode  codes.Code
+	}{
+		{
+			name:    "success, first page",
+			expCode: codes.OK,
+		},
+		{
+			name:     "success, next page",
+			token:    pages.Encode("ivan", true, listFilter),
+			expAfter: "ivan",
+			expCode:  codes.OK,
+		},
+		{
+			name:    "failed, other filter",
+			token:   pages.Encode("ivan", true, pagetokenPkg.Filter(filter.ListKey(nil, ""))),
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, garbage",
+			token:   "token",
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			mockStream := apiMockPkg.NewMockUser_UserAllListServer(ctl)
+			mockStream.EXPECT().Context().Return(ctx).AnyTimes()
+
+			var tokens []string
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *pb.UserAllListResponse) error {
+				tokens = append(tokens, resp.GetNextPageToken())
+				return nil
+			}).AnyTimes()
+			if c.expCode == codes.OK {
+				gomock.InOrder(
+					mockUser.EXPECT().ListAfter(gomock.Any(), true, uint64(2), c.expAfter, attributes, "").
+						Return([]models.User{{Name: "olga"}, {Name: "kate"}}, nil),
+					mockUser.EXPECT().ListAfter(gomock.Any(), true, uint64(2), "kate", attributes, "").
+						Return(nil, nil),
+				)
+			}
+
+			err := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, pages, grpcPkg.ResponseSizeConfig{}, clock.Real()).
+				UserAllList(&pb.UserAllListRequest{Order: true, Limit: 2, Attributes: attributes, PageToken: c.token}, mockStream)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode != codes.OK {
+				return
+			}
+			require.Len(t, tokens, 1)
+			cursor, err := pages.Decode(tokens[0], true, listFilter)
+			require.NoError(t, err)
+			assert.Equal(t, "kate", cursor.Last)
+		})
+	}
+}
+
+func TestDataApi_UserAllListSize(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	pages := pagetokenPkg.New(pagetokenPkg.Config{Secret: "secret"}, clock.NewFake(time.Unix(1660412960, 0)))
+	listFilter := pagetokenPkg.Filter(filter.ListKey(nil, ""))
+
+	mockUser := userMockPkg.NewMockInterface(ctl)
+	mockStream := apiMockPkg.NewMockUser_UserAllListServer(ctl)
+	mockStream.EXPECT().Context().Return(context.Background()).AnyTimes()
+
+	// every user is over the cap, so the chunk is sent by one user
+	var sent [][]string
+	mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *pb.UserAllListResponse) error {
+		cursor, err := pages.Decode(resp.GetNextPageToken(), false, listFilter)
+		require.NoError(t, err)
+		var names []string
+		for _, user := range resp.GetUsers() {
+			names = append(names, user.GetName())
+		}
+		sent = append(sent, append(names, cursor.Last))
+		return nil
+	}).Times(2)
+	gomock.InOrder(
+		mockUser.EXPECT().ListAfter(gomock.Any(), false, uint64(2), "", nil, "").
+			Return([]models.User{{Name: "kate"}, {Name: "olga"}}, nil),
+		mockUser.EXPECT().ListAfter(gomock.Any(), false, uint64(2), "olga", nil, "").
+			Return(nil, nil),
+	)
+
+	err := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, pages, grpcPkg.ResponseSizeConfig{MaxBytes: 1}, clock.Real()).
+		UserAllList(&pb.UserAllListRequest{Limit: 2}, mockStream)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"kate", "kate"}, {"olga", "olga"}}, sent)
+}
+
+func TestDataApi_UserChanges(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+	ivan := models.User{ID: "1", Name: "ivan", Password: "hash", UpdatedAt: 1660000100}
+	petr := models.User{ID: "2", Name: "petr", Password: "hash", UpdatedAt: 1660000200}
+	tombstone := models.Tombstone{ID: "3", Name: "olga", DeletedAt: 1660000300}
+
+	cases := []struct {
+		name           string
+		since          int64
+		includeDeleted bool
+		tombstonesErr  error
+		changesErr     error
+		expDeleted     []*pb.UserTombstone
+		expCode        codes.Code
+	}{
+		{
+			name:           "success",
+			since:          1660000000,
+			includeDeleted: true,
+			expDeleted:     []*pb.UserTombstone{{Id: "3", Name: "olga", DeletedAt: 1660000300}},
+			expCode:        codes.OK,
+		},
+		{
+			name:       "success, without deleted",
+			since:      1660000000,
+			expDeleted: []*pb.UserTombstone{},
+			expCode:    codes.OK,
+		},
+		{
+			name:    "failed, negative since",
+			since:   -1,
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:           "failed, tombstones pruned",
+			since:          1660000000,
+			includeDeleted: true,
+			tombstonesErr:  errorsPkg.ErrTombstonesPruned,
+			expCode:        codes.FailedPrecondition,
+		},
+		{
+			name:           "failed, tombstones crashed",
+			since:          1660000000,
+			includeDeleted: true,
+			tombstonesErr:  errorsPkg.ErrUnexpected,
+			expCode:        codes.Internal,
+		},
+		{
+			name:       "failed, changes crashed",
+			since:      1660000000,
+			changesErr: errorsPkg.ErrUnexpected,
+			expCode:    codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			mockStream := apiMockPkg.NewMockUser_UserChangesServer(ctl)
+			mockStream.EXPECT().Context().Return(ctx).AnyTimes()
+
+			var sent []*pb.UserChangesResponse
+			// users of the sent chunk are reused by the next one, as gRPC encodes it by Send
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *pb.UserChangesResponse) error {
+				users := make([]*pbModels.User, 0, len(resp.GetUsers()))
+				for _, user := range resp.GetUsers() {
+					users = append(users, proto.Clone(user).(*pbModels.User))
+				}
+				sent = append(sent, &pb.UserChangesResponse{
+					Users:     users,
+					Deleted:   resp.GetDeleted(),
+					NextSince: resp.GetNextSince(),
+				})
+				return nil
+			}).AnyTimes()
+			if c.since >= 0 && c.includeDeleted {
+				mockUser.EXPECT().Tombstones(gomock.Any(), c.since).Return([]models.Tombstone{tombstone}, c.tombstonesErr)
+			}
+			if c.since >= 0 && c.tombstonesErr == nil {
+				gomock.InOrder(
+					mockUser.EXPECT().Changes(gomock.Any(), c.since, "", uint64(2)).
+						Return([]models.User{ivan, petr}, c.changesErr),
+					mockUser.EXPECT().Changes(gomock.Any(), c.since, "petr", uint64(2)).
+						Return(nil, nil).MaxTimes(1),
+				)
+			}
+
+			clk := clock.NewFake(time.Unix(1660000400, 0))
+			err := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clk).
+				UserChanges(&pb.UserChangesRequest{Since: c.since, Limit: 2, IncludeDeleted: c.includeDeleted}, mockStream)
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode != codes.OK {
+				return
+			}
+			require.Len(t, sent, 2)
+			assert.Equal(t, clk.Now().Unix(), sent[0].GetNextSince())
+			assert.Equal(t, c.expDeleted, sent[0].GetDeleted())
+			require.Len(t, sent[1].GetUsers(), 2)
+			assert.Empty(t, sent[1].GetUsers()[0].GetPassword())
+			assert.Equal(t, int64(1660000200), sent[1].GetUsers()[1].GetUpdatedAt())
+		})
+	}
+}
+
+func TestDataApi_UserAvatarUpload(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	cases := []struct {
+		name      string
+		chunks    [][]byte
+		uploadErr error
+		expCode   codes.Code
+	}{
+		{
+			name:    "success",
+			chunks:  [][]byte{[]byte("ab"), []byte("cd")},
+			expCode: codes.OK,
+		},
+		{
+			name:    "failed, too big",
+			chunks:  [][]byte{[]byte("abc"), []byte("def")},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:      "failed, user not found",
+			chunks:    [][]byte{[]byte("ab")},
+			uploadErr: errorsPkg.ErrUserNotFound,
+			expCode:   codes.NotFound,
+		},
+		{
+			name:      "failed, avatars disabled",
+			chunks:    [][]byte{[]byte("ab")},
+			uploadErr: errorsPkg.ErrAvatarsDisabled,
+			expCode:   codes.Unimplemented,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			mockStream := apiMockPkg.NewMockUser_UserAvatarUploadServer(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{MaxSize: 4}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			mockStream.EXPECT().Context().Return(ctx).AnyTimes()
+			calls := make([]*gomock.Call, 0, len(c.chunks)+1)
+			for _, chunk := range c.chunks {
+				calls = append(calls, mockStream.EXPECT().Recv().
+					Return(&pb.UserAvatarUploadRequest{Name: "Ivan", Chunk: chunk}, nil).MaxTimes(1))
+			}
+			calls = append(calls, mockStream.EXPECT().Recv().Return(nil, io.EOF).MaxTimes(1))
+			gomock.InOrder(calls...)
+			mockUser.EXPECT().AvatarUpload(gomock.Any(), "Ivan", []byte("abcd")).
+				Return("/v1/user/Ivan/avatar", c.uploadErr).MaxTimes(1)
+			mockUser.EXPECT().AvatarUpload(gomock.Any(), "Ivan", []byte("ab")).
+				Return("", c.uploadErr).MaxTimes(1)
+			mockStream.EXPECT().SendAndClose(&pb.UserAvatarUploadResponse{Url: "/v1/user/Ivan/avatar"}).
+				Return(nil).MaxTimes(1)
+
+			err := userCtl.UserAvatarUpload(mockStream)
+			assert.Equal(t, c.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestDataApi_UserImport(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	cases := []struct {
+		name       string
+		strategy   pb.ImportStrategy
+		atomic     bool
+		checkErrs  []error
+		statuses   []models.ImportStatus
+		errs       []error
+		expResult  []string
+		expResults []codes.Code
+		expCode    codes.Code
+	}{
+		{
+			name:       "success, skip",
+			strategy:   pb.ImportStrategy_skip,
+			statuses:   []models.ImportStatus{models.ImportCreated, models.ImportSkipped},
+			errs:       []error{nil, nil},
+			expResult:  []string{"created", "skipped"},
+			expResults: []codes.Code{codes.OK, codes.OK},
+			expCode:    codes.OK,
+		},
+		{
+			name:       "success, row error is reported",
+			strategy:   pb.ImportStrategy_merge,
+			statuses:   []models.ImportStatus{models.ImportFailed, models.ImportMerged},
+			errs:       []error{errorsPkg.ErrValidation, nil},
+			expResult:  []string{"failed", "merged"},
+			expResults: []codes.Code{codes.InvalidArgument, codes.OK},
+			expCode:    codes.OK,
+		},
+		{
+			name:       "failed, conflict with fail strategy",
+			strategy:   pb.ImportStrategy_fail,
+			statuses:   []models.ImportStatus{models.ImportFailed, models.ImportCreated},
+			errs:       []error{errorsPkg.ErrUserAlreadyExists, nil},
+			expResult:  []string{"failed"},
+			expResults: []codes.Code{codes.AlreadyExists},
+			expCode:    codes.FailedPrecondition,
+		},
+		{
+			name:       "success, atomic",
+			strategy:   pb.ImportStrategy_merge,
+			atomic:     true,
+			checkErrs:  []error{nil, nil},
+			statuses:   []models.ImportStatus{models.ImportCreated, models.ImportMerged},
+			errs:       []error{nil, nil},
+			expResult:  []string{"created", "merged"},
+			expResults: []codes.Code{codes.OK, codes.OK},
+			expCode:    codes.OK,
+		},
+		{
+			name:       "failed, atomic row check",
+			strategy:   pb.ImportStrategy_fail,
+			atomic:     true,
+			checkErrs:  []error{nil, errorsPkg.ErrUserAlreadyExists},
+			expResult:  []string{"failed", "failed"},
+			expResults: []codes.Code{codes.Aborted, codes.AlreadyExists},
+			expCode:    codes.Aborted,
+		},
+		{
+			name:       "failed, atomic write",
+			strategy:   pb.ImportStrategy_skip,
+			atomic:     true,
+			checkErrs:  []error{nil, nil},
+			statuses:   []models.ImportStatus{models.ImportFailed},
+			errs:       []error{errorsPkg.ErrUnexpected},
+			expResult:  []string{"failed", "failed"},
+			expResults: []codes.Code{codes.Internal, codes.Aborted},
+			expCode:    codes.Aborted,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			mockStream := apiMockPkg.NewMockUser_UserImportServer(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			mockStream.EXPECT().Context().Return(ctx).AnyTimes()
+			names := []string{"ivan", "petr"}
+			recv := make([]*gomock.Call, 0, len(names)+1)
+			for i, name := range names {
+				// strategy of the first message is used only
+				strategy := c.strategy
+				if i != 0 {
+					strategy = pb.ImportStrategy_overwrite
+				}
+				recv = append(recv, mockStream.EXPECT().Recv().
+					Return(&pb.UserImportRequest{User: &pbModels.User{Name: name}, Strategy: strategy, Atomic: c.atomic}, nil).MaxTimes(1))
+				if i < len(c.checkErrs) {
+					checkStatus := models.ImportCreated
+					if c.checkErrs[i] != nil {
+						checkStatus = models.ImportFailed
+					}
+					mockUser.EXPECT().ImportCheck(gomock.Any(), models.User{Name: name}, models.ImportStrategy(c.strategy.String())).
+						Return(checkStatus, c.checkErrs[i])
+				}
+				if i < len(c.errs) {
+					mockUser.EXPECT().Import(gomock.Any(), models.User{Name: name}, models.ImportStrategy(c.strategy.String())).
+						Return(c.statuses[i], c.errs[i]).MaxTimes(1)
+				}
+			}
+			recv = append(recv, mockStream.EXPECT().Recv().Return(nil, io.EOF).MaxTimes(1))
+			gomock.InOrder(recv...)
+
+			var (
+				result  []string
+				results []codes.Code
+			)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(resp *pb.UserImportResponse) error {
+				assert.Equal(t, uint64(len(result)+1), resp.GetRow())
+				assert.Equal(t, resp.GetRow()-1, resp.GetResult().GetIndex())
+				result = append(result, resp.GetStatus())
+				results = append(results, codes.Code(resp.GetResult().GetCode()))
+				return nil
+			}).AnyTimes()
+
+			err := userCtl.UserImport(mockStream)
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expResult, result)
+			assert.Equal(t, c.expResults, results)
+		})
+	}
+}
+
+func TestDataApi_UserCheckPassword(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name     string
+		valid    bool
+		checkErr error
+		expCode  codes.Code
+	}{
+		{
+			name:    "success",
+			valid:   true,
+			expCode: codes.OK,
+		},
+		{
+			name:     "failed, too many attempts",
+			checkErr: errorsPkg.ErrTooManyAttempts,
+			expCode:  codes.ResourceExhausted,
+		},
+		{
+			name:     "failed, password expired",
+			checkErr: errorsPkg.ErrPasswordExpired,
+			expCode:  codes.FailedPrecondition,
+		},
+		{
+			name:     "failed, validation error",
+			checkErr: errorsPkg.ErrValidation,
+			expCode:  codes.InvalidArgument,
+		},
+		{
+			name:     "failed, unexpected error",
+			checkErr: errorsPkg.ErrUnexpected,
+			expCode:  codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			mockUser.EXPECT().CheckPassword(gomock.Any(), "ivan", "secret").
+				Return(c.valid, c.checkErr).Times(1)
+
+			resp, err := userCtl.UserCheckPassword(context.Background(),
+				&pb.UserCheckPasswordRequest{Name: "ivan", Password: "secret"})
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.valid, resp.GetValid())
+		})
+	}
+}
+
+func TestDataApi_UserGetIfChanged(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	user := models.User{ID: "id-1", Name: "ivan"}
+
+	cases := []struct {
+		name        string
+		user        models.User
+		changed     bool
+		getErr      error
+		expCode     codes.Code
+		expVersion  string
+		expModified bool
+	}{
+		{
+			name:        "success, changed",
+			user:        user,
+			changed:     true,
+			expCode:     codes.OK,
+			expVersion:  user.Version(),
+			expModified: true,
+		},
+		{
+			name:       "success, not modified",
+			expCode:    codes.OK,
+			expVersion: "known",
+		},
+		{
+			name:    "failed, deleted",
+			changed: true,
+			getErr:  errorsPkg.ErrUserNotFound,
+			expCode: codes.NotFound,
+		},
+		{
+			name:    "failed, unexpected error",
+			changed: true,
+			getErr:  errorsPkg.ErrUnexpected,
+			expCode: codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			mockUser.EXPECT().GetIfChanged(gomock.Any(), "ivan", "known", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _, _ string, wait time.Duration) (models.User, bool, error) {
+					assert.Less(t, wait, time.Second)
+					return c.user, c.changed, c.getErr
+				}).Times(1)
+
+			resp, err := userCtl.UserGetIfChanged(ctx, &pb.UserGetIfChangedRequest{Name: "ivan", KnownVersion: "known"})
+			require.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expVersion, resp.GetVersion())
+			assert.Equal(t, c.expModified, resp.GetUser() != nil)
+			assert.Equal(t, c.expCode == codes.OK && !c.changed, resp.GetNotModified())
+		})
+	}
+}
+
+func TestDataApi_UserCount(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name     string
+		countErr error
+		expCode  codes.Code
+		expResp  *pb.UserCountResponse
+	}{
+		{
+			name:    "success",
+			expCode: codes.OK,
+			expResp: &pb.UserCountResponse{Count: 3, Total: 5},
+		},
+		{
+			name:     "failed, unexpected error",
+			countErr: errorsPkg.ErrUnexpected,
+			expCode:  codes.Internal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			mockUser.EXPECT().Count(gomock.Any(), "acme").Return(int64(3), int64(5), c.countErr).Times(1)
+
+			resp, err := userCtl.UserCount(context.Background(), &pb.UserCountRequest{Tenant: "acme"})
+			require.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expResp.GetCount(), resp.GetCount())
+			assert.Equal(t, c.expResp.GetTotal(), resp.GetTotal())
+		})
+	}
+}
+
+func TestDataApi_MeUpdate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token"))
+	user := models.User{ID: "id-1", Name: "ivan", Password: "secret", Email: "ivan@email.com", FullName: "Ivan"}
+
+	cases := []struct {
+		name       string
+		ctx        context.Context
+		sessionErr error
+		profile    *pbModels.Profile
+		mask       []string
+		expMask    []string
+		expCode    codes.Code
+	}{
+		{
+			name:    "success, default mask",
+			ctx:     ctx,
+			profile: &pbModels.Profile{Email: proto.String("new@email.com"), FullName: proto.String("Ivan Ivanov")},
+			expMask: []string{models.MaskEmail, models.MaskFullName},
+			expCode: codes.OK,
+		},
+		{
+			name:    "success, full name",
+			ctx:     ctx,
+			profile: &pbModels.Profile{FullName: proto.String("Ivan Ivanov")},
+			mask:    []string{models.MaskFullName},
+			expMask: []string{models.MaskFullName},
+			expCode: codes.OK,
+		},
+		{
+			name:       "failed, no session",
+			ctx:        context.Background(),
+			sessionErr: errorsPkg.ErrUnauthenticated,
+			expCode:    codes.Unauthenticated,
+		},
+		{
+			name:    "failed, attributes are not allowed",
+			ctx:     ctx,
+			profile: &pbModels.Profile{Attributes: map[string]string{"role": "admin"}},
+			mask:    []string{models.MaskAttributes},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, password is not allowed",
+			ctx:     ctx,
+			profile: &pbModels.Profile{Password: proto.String("new")},
+			mask:    []string{models.MaskPassword},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, invalid email",
+			ctx:     ctx,
+			profile: &pbModels.Profile{Email: proto.String("email")},
+			mask:    []string{models.MaskEmail},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			token := ""
+			if c.sessionErr == nil {
+				token = "token"
+			}
+			mockUser.EXPECT().SessionUser(gomock.Any(), token).Return(user, c.sessionErr).Times(1)
+			if c.expCode == codes.OK {
+				mockUser.EXPECT().Update(gomock.Any(), models.UserUpdate{
+					User: models.User{Name: "ivan", Email: c.profile.GetEmail(), FullName: c.profile.GetFullName()},
+					Mask: c.expMask,
+				}).DoAndReturn(func(ctx context.Context, _ models.UserUpdate) error {
+					identity := grpcPkg.IdentityFromContext(ctx)
+					assert.Equal(t, "ivan", identity.Actor(), "the change is made on behalf of the session user")
+					return nil
+				}).Times(1)
+				mockUser.EXPECT().Get(gomock.Any(), "ivan").Return(user, nil).Times(1)
+			}
+
+			resp, err := userCtl.MeUpdate(c.ctx, &pb.MeUpdateRequest{
+				Profile:    c.profile,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: c.mask},
+			})
+			assert.Equal(t, c.expCode, status.Code(err))
+			if c.expCode == codes.OK {
+				assert.Equal(t, "ivan", resp.GetUser().GetName())
+				assert.Empty(t, resp.GetUser().GetPassword())
+			}
+		})
+	}
+}
+
+func TestDataApi_MeChangePassword(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "token"))
+	user := models.User{ID: "id-1", Name: "ivan"}
+
+	cases := []struct {
+		name      string
+		valid     bool
+		checkErr  error
+		updateErr error
+		expCode   codes.Code
+	}{
+		{
+			name:    "success",
+			valid:   true,
+			expCode: codes.OK,
+		},
+		{
+			name:     "success, expired password",
+			checkErr: errorsPkg.ErrPasswordExpired,
+			expCode:  codes.OK,
+		},
+		{
+			name:    "failed, invalid old password",
+			expCode: codes.PermissionDenied,
+		},
+		{
+			name:     "failed, too many attempts",
+			checkErr: errorsPkg.ErrTooManyAttempts,
+			expCode:  codes.ResourceExhausted,
+		},
+		{
+			name:      "failed, weak password",
+			valid:     true,
+			updateErr: errorsPkg.ErrValidation,
+			expCode:   codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			userCtl := New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, nil, nil, grpcPkg.ResponseSizeConfig{}, clock.Real())
+
+			mockUser.EXPECT().SessionUser(gomock.Any(), "token").Return(user, nil).Times(1)
+			mockUser.EXPECT().CheckPassword(gomock.Any(), "ivan", "old").Return(c.valid, c.checkErr).Times(1)
+			if c.valid || errors.Is(c.checkErr, errorsPkg.ErrPasswordExpired) {
+				mockUser.EXPECT().Update(gomock.Any(), models.UserUpdate{
+					User: models.User{Name: "ivan", Password: "new"},
+					Mask: []string{models.MaskPassword},
+				}).Return(c.updateErr).Times(1)
+			}
+
+			_, err := userCtl.MeChangePassword(ctx, &pb.MeChangePasswordRequest{OldPassword: "old", NewPassword: "new"})
+			assert.Equal(t, c.expCode, status.Code(err))
+		})
+	}
+}
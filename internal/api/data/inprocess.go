@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const (
+	inProcessBufferSize = 1 << 20
+	inProcessTarget     = "inprocess"
+)
+
+// InProcess is the user service served over an in-memory listener. It embeds the service
+// into tests and single binary deployments, calls pass interceptors and message encoding
+// as network ones do, but neither a port nor Kafka are used.
+type InProcess struct {
+	Server pb.UserServer
+	Conn   *grpc.ClientConn
+
+	server   *grpc.Server
+	listener *bufconn.Listener
+	served   chan struct{}
+}
+
+// NewInProcess serves the user service by the core, opts are applied to the gRPC server.
+func NewInProcess(
+	user userPkg.Interface,
+	logger *zap.SugaredLogger,
+	avatarCfg avatarPkg.Config,
+	opts ...grpc.ServerOption,
+) (*InProcess, error) {
+	p := &InProcess{
+		Server: New(user, logger, avatarCfg, grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc), nil,
+			grpcPkg.ResponseSizeConfig{}, clock.Real()),
+		server:   grpc.NewServer(opts...),
+		listener: bufconn.Listen(inProcessBufferSize),
+		served:   make(chan struct{}),
+	}
+	pb.RegisterUserServer(p.server, p.Server)
+
+	go func() {
+		defer close(p.served)
+		if err := p.server.Serve(p.listener); err != nil {
+			logger.Errorln("in-process gRPC serve:", err)
+		}
+	}()
+
+	conn, err := grpc.Dial(inProcessTarget,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return p.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		p.server.Stop()
+		<-p.served
+		return nil, errors.Wrap(err, "in-process dial")
+	}
+	p.Conn = conn
+	return p, nil
+}
+
+// Client returns the user client of the in-process channel.
+func (p *InProcess) Client() pb.UserClient {
+	return pb.NewUserClient(p.Conn)
+}
+
+// Close closes the channel and stops the server after the running calls are finished.
+func (p *InProcess) Close() error {
+	err := p.Conn.Close()
+	p.server.GracefulStop()
+	<-p.served
+	return errors.Wrap(err, "in-process close")
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestInProcess(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	mockUser := userMockPkg.NewMockInterface(ctl)
+	p, err := NewInProcess(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, p.Close())
+	}()
+	client := p.Client()
+
+	t.Run("success, unary call", func(t *testing.T) {
+		mockUser.EXPECT().CheckPassword(gomock.Any(), "ivan", "secret").Return(true, nil).Times(1)
+
+		resp, err := client.UserCheckPassword(ctx, &pb.UserCheckPasswordRequest{Name: "ivan", Password: "secret"})
+		require.NoError(t, err)
+		assert.True(t, resp.GetValid())
+	})
+
+	t.Run("failed, status passed through the channel", func(t *testing.T) {
+		mockUser.EXPECT().CheckPassword(gomock.Any(), "ivan", "secret").
+			Return(false, errorsPkg.ErrTooManyAttempts).Times(1)
+
+		_, err := client.UserCheckPassword(ctx, &pb.UserCheckPasswordRequest{Name: "ivan", Password: "secret"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("success, server stream", func(t *testing.T) {
+		gomock.InOrder(
+			mockUser.EXPECT().ListAfter(gomock.Any(), false, uint64(2), "", gomock.Any(), "").
+				Return([]models.User{{Name: "ivan"}, {Name: "petr"}}, nil).Times(1),
+			mockUser.EXPECT().ListAfter(gomock.Any(), false, uint64(2), "petr", gomock.Any(), "").
+				Return([]models.User{}, nil).Times(1),
+		)
+
+		stream, err := client.UserAllList(ctx, &pb.UserAllListRequest{Limit: 2})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Len(t, resp.GetUsers(), 2)
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("failed, write methods are served through Kafka only", func(t *testing.T) {
+		_, err := client.UserCreate(ctx, &pb.UserCreateRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
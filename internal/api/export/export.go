@@ -0,0 +1,386 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	filterPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
+)
+
+const (
+	Path = "/v1/users/export.csv"
+
+	// chunkRows is a page of users requested from the data service, every page is flushed to the client
+	chunkRows = 500
+	// attributePrefix selects a column of the attribute, e.g. attributes.team
+	attributePrefix = "attributes."
+	// deletedAtColumn is appended to delta exports of deleted users, it is set for their rows only
+	deletedAtColumn = "deleted_at"
+	// NextSinceHeader of delta exports is the since of the next delta export
+	NextSinceHeader = "X-Export-Next-Since"
+	// NextPageTokenTrailer of full exports cut by the limit is the page_token of the next export
+	NextPageTokenTrailer = "X-Export-Next-Page-Token"
+)
+
+// Config of the CSV export.
+type Config struct {
+	// MaxRows guards the export, requests of more rows are rejected. Zero disables the export.
+	MaxRows uint64 `mapstructure:"max_rows"`
+}
+
+// defaultColumns are exported, if the request has no columns.
+var defaultColumns = []string{"id", "name", "email", "full_name", "status", "created_at"}
+
+// allListMethod is the data service method, which streams users to the export.
+var allListMethod = "/" + pb.User_ServiceDesc.ServiceName + "/UserAllList"
+
+// changesMethod is the data service method, which streams users to the delta export.
+var changesMethod = "/" + pb.User_ServiceDesc.ServiceName + "/UserChanges"
+
+// page of users received from the data stream.
+type page interface {
+	proto.Message
+	GetUsers() []*pbModels.User
+}
+
+// Handler streams users as CSV. Pages are read from the data stream only after the previous
+// one is written, so a slow client slows the stream down instead of buffering users.
+type Handler struct {
+	user    pb.UserClient
+	cfg     Config
+	methods *grpcPkg.Methods
+	fields  *grpcPkg.Fields
+	// pages issue tokens of the next export, they must be signed by the secret of the data service
+	pages  *pagetokenPkg.Codec
+	logger *zap.SugaredLogger
+}
+
+func New(
+	user pb.UserClient,
+	cfg Config,
+	methods *grpcPkg.Methods,
+	fields *grpcPkg.Fields,
+	pages *pagetokenPkg.Codec,
+	logger *zap.SugaredLogger,
+) *Handler {
+	return &Handler{
+		user:    user,
+		cfg:     cfg,
+		methods: methods,
+		fields:  fields,
+		pages:   pages,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(Path, h.Export)
+}
+
+// Export serves GET with optional query parameters:
+//   - columns: comma separated user fields and attributes.<key> columns;
+//   - limit: number of rows, up to the configured maximum;
+//   - order: desc sorts users in descending order;
+//   - status and attributes[<key>]=<value> filter users as UserAllList does;
+//   - page_token: the export starts after the last row of the export, which issued the token;
+//   - since: UNIX time, only users written at it or later are exported;
+//   - include_deleted: true adds users deleted since the time to the delta export.
+//
+// Delta export of since takes no other parameters, since a filtered or short delta misses changes.
+// With include_deleted its rows have the deleted_at column, deleted users have only id and name
+// besides it. Since before the tombstone retention is rejected, a full export is required then.
+// The next delta is exported since the time of NextSinceHeader. Full export cut by the limit
+// sends the token of the next one in NextPageTokenTrailer, unless names are hidden from the caller.
+// Fields hidden from the caller role are exported empty.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.cfg.MaxRows == 0 {
+		http.Error(w, "export is disabled by config", http.StatusNotImplemented)
+		return
+	}
+
+	query := r.URL.Query()
+	delta, err := parseDelta(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := allListMethod
+	if delta != nil {
+		method = changesMethod
+	}
+	if err = h.methods.Check(method); err != nil {
+		http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+		return
+	}
+	columns, err := parseColumns(query.Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := h.parseLimit(query.Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(grpcPkg.HTTPIncomingContext(r))
+	defer cancel()
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	h.logger.Debugf("[%s] users export: [%v %d %v]", meta, columns, limit, delta)
+
+	recv, err := h.stream(ctx, query, delta)
+	if err != nil {
+		h.logger.Errorf("[%s] users export: stream: %v", meta, err)
+		http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	writer := csv.NewWriter(w)
+	// the response is started with the first page, so errors of the stream start get their status
+	started := false
+	start := func(first page) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="users.csv"`)
+		header := columns
+		if changes, ok := first.(*pb.UserChangesResponse); ok {
+			w.Header().Set(NextSinceHeader, strconv.FormatInt(changes.GetNextSince(), 10))
+		}
+		if delta == nil && h.pages != nil {
+			w.Header().Set("Trailer", NextPageTokenTrailer)
+		}
+		if delta.GetIncludeDeleted() {
+			header = append(append([]string(nil), columns...), deletedAtColumn)
+		}
+		_ = writer.Write(header)
+		started = true
+	}
+
+	var rows uint64
+	// last is the name of the last exported user of the full export
+	var last string
+	for rows < limit {
+		next, err := recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if !started {
+				h.logger.Errorf("[%s] users export: next chunk: %v", meta, err)
+				http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+				return
+			}
+			// the client sees the broken connection instead of a short export
+			h.logger.Errorf("[%s] users export: next chunk after %d rows: %v", meta, rows, err)
+			panic(http.ErrAbortHandler)
+		}
+		if !started {
+			start(next)
+		}
+		h.fields.Filter(ctx, next)
+
+		records := make([][]string, 0, len(next.GetUsers()))
+		if changes, ok := next.(*pb.UserChangesResponse); ok {
+			for _, tombstone := range changes.GetDeleted() {
+				records = append(records, tombstoneRecord(tombstone, columns))
+			}
+		}
+		for _, user := range next.GetUsers() {
+			record := record(user, columns)
+			if delta.GetIncludeDeleted() {
+				record = append(record, "")
+			}
+			records = append(records, record)
+		}
+		for i, record := range records {
+			if rows == limit {
+				if delta != nil {
+					// a short delta loses changes, the client has to run a full export instead
+					h.logger.Errorf("[%s] users export: delta is above the maximum [%d] rows", meta, limit)
+					panic(http.ErrAbortHandler)
+				}
+				break
+			}
+			if err = writer.Write(record); err != nil {
+				h.logger.Infof("[%s] users export: write after %d rows: %v", meta, rows, err)
+				return
+			}
+			rows++
+			if delta == nil {
+				last = next.GetUsers()[i].GetName()
+			}
+		}
+		writer.Flush()
+		if err = writer.Error(); err != nil {
+			h.logger.Infof("[%s] users export: write after %d rows: %v", meta, rows, err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if !started {
+		start(nil)
+		writer.Flush()
+	}
+	if delta == nil && h.pages != nil && rows == limit && last != "" {
+		filter := pagetokenPkg.Filter(filterPkg.ListKey(parseAttributes(query), query.Get("status")))
+		w.Header().Set(NextPageTokenTrailer, h.pages.Encode(last, query.Get("order") == "desc", filter))
+	}
+	counter.ExportedRows.Add(rows)
+}
+
+// stream requests users of the export, the delta stream is requested, if delta is set.
+func (h *Handler) stream(ctx context.Context, query url.Values, delta *pb.UserChangesRequest) (func() (page, error), error) {
+	if delta != nil {
+		stream, err := h.user.UserChanges(ctx, delta)
+		if err != nil {
+			return nil, err
+		}
+		return func() (page, error) { return stream.Recv() }, nil
+	}
+	stream, err := h.user.UserAllList(ctx, &pb.UserAllListRequest{
+		Order:      query.Get("order") == "desc",
+		Limit:      chunkRows,
+		Attributes: parseAttributes(query),
+		Status:     query.Get("status"),
+		PageToken:  query.Get("page_token"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() (page, error) { return stream.Recv() }, nil
+}
+
+// parseDelta returns the request of the delta export, it is nil without since.
+func parseDelta(query url.Values) (*pb.UserChangesRequest, error) {
+	value := query.Get("since")
+	if value == "" {
+		return nil, nil
+	}
+	since, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || since < 0 {
+		return nil, errors.Errorf("since [%s] must be a UNIX time", value)
+	}
+	var includeDeleted bool
+	if value := query.Get("include_deleted"); value != "" {
+		if includeDeleted, err = strconv.ParseBool(value); err != nil {
+			return nil, errors.Errorf("include_deleted [%s] must be a boolean", value)
+		}
+	}
+	for param := range query {
+		if param != "since" && param != "include_deleted" && param != "columns" {
+			return nil, errors.Errorf("parameter [%s] is not supported by the delta export", param)
+		}
+	}
+	return &pb.UserChangesRequest{Since: since, Limit: chunkRows, IncludeDeleted: includeDeleted}, nil
+}
+
+func (h *Handler) parseLimit(value string) (uint64, error) {
+	if value == "" {
+		return h.cfg.MaxRows, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || limit == 0 {
+		return 0, errors.Errorf("limit [%s] must be a positive number", value)
+	}
+	if limit > h.cfg.MaxRows {
+		return 0, errors.Errorf("limit [%d] is above the maximum [%d] rows", limit, h.cfg.MaxRows)
+	}
+	return limit, nil
+}
+
+var userFields = (&pbModels.User{}).ProtoReflect().Descriptor().Fields()
+
+// parseColumns checks the columns are user fields or attribute keys, password is never exported.
+func parseColumns(value string) ([]string, error) {
+	if value == "" {
+		return defaultColumns, nil
+	}
+	columns := strings.Split(value, ",")
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		columns[i] = column
+		if key := strings.TrimPrefix(column, attributePrefix); key != column {
+			if key == "" {
+				return nil, errors.Errorf("column [%s] has no attribute key", column)
+			}
+			continue
+		}
+		if column == "password" || userFields.ByName(protoreflect.Name(column)) == nil {
+			return nil, errors.Errorf("unknown column [%s]", column)
+		}
+	}
+	return columns, nil
+}
+
+// parseAttributes takes attributes[<key>]=<value> parameters.
+func parseAttributes(query map[string][]string) map[string]string {
+	attributes := make(map[string]string)
+	for param, values := range query {
+		if !strings.HasPrefix(param, "attributes[") || !strings.HasSuffix(param, "]") || len(values) == 0 {
+			continue
+		}
+		attributes[strings.TrimSuffix(strings.TrimPrefix(param, "attributes["), "]")] = values[0]
+	}
+	return attributes
+}
+
+// tombstoneRecord has id and name of the deleted user and its deleted_at column.
+func tombstoneRecord(tombstone *pb.UserTombstone, columns []string) []string {
+	values := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		switch column {
+		case "id":
+			values = append(values, tombstone.GetId())
+		case "name":
+			values = append(values, tombstone.GetName())
+		default:
+			values = append(values, "")
+		}
+	}
+	return append(values, strconv.FormatInt(tombstone.GetDeletedAt(), 10))
+}
+
+func record(user *pbModels.User, columns []string) []string {
+	values := make([]string, 0, len(columns))
+	msg := user.ProtoReflect()
+	for _, column := range columns {
+		if key := strings.TrimPrefix(column, attributePrefix); key != column {
+			values = append(values, user.GetAttributes()[key])
+			continue
+		}
+		fd := userFields.ByName(protoreflect.Name(column))
+		if fd.IsMap() {
+			attributes, _ := json.Marshal(user.GetAttributes())
+			values = append(values, string(attributes))
+			continue
+		}
+		values = append(values, msg.Get(fd).String())
+	}
+	return values
+}
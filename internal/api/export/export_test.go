@@ -0,0 +1,276 @@
+package export
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
+)
+
+func TestHandler_Export(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ivan := &pbModels.User{Id: "1", Name: "ivan", Email: "ivan@example.com", Attributes: map[string]string{"team": "core"}}
+	petr := &pbModels.User{Id: "2", Name: "petr", Email: "petr@example.com"}
+	fields, err := grpcPkg.NewFields(grpcPkg.FieldsConfig{
+		Tokens: map[string]string{"public": "guest"},
+		Hidden: map[string][]string{"public": {"email"}},
+	})
+	require.NoError(t, err)
+	pages := pagetokenPkg.New(pagetokenPkg.Config{Secret: "secret"}, clock.NewFake(time.Unix(1660412960, 0)))
+
+	cases := []struct {
+		name      string
+		target    string
+		token     string
+		pageToken string
+		pages     [][]*pbModels.User
+		recvErr   error
+		streamed  bool
+		// limited export stops reading before the end of the stream
+		limited bool
+		expCode int
+		expBody string
+		// expLast is the last name of the next page token, it is empty without the token
+		expLast string
+	}{
+		{
+			name:     "success, default columns",
+			target:   Path,
+			pages:    [][]*pbModels.User{{ivan}, {petr}},
+			streamed: true,
+			expCode:  http.StatusOK,
+			expBody: "id,name,email,full_name,status,created_at\n" +
+				"1,ivan,ivan@example.com,,,0\n2,petr,petr@example.com,,,0\n",
+		},
+		{
+			name:     "success, columns and limit",
+			target:   Path + "?columns=name,attributes.team,attributes&limit=1",
+			pages:    [][]*pbModels.User{{ivan, petr}},
+			streamed: true,
+			limited:  true,
+			expCode:  http.StatusOK,
+			expBody:  "name,attributes.team,attributes\nivan,core,\"{\"\"team\"\":\"\"core\"\"}\"\n",
+			expLast:  "ivan",
+		},
+		{
+			name:      "success, page token",
+			target:    Path + "?columns=name&page_token=token",
+			pageToken: "token",
+			pages:     [][]*pbModels.User{{petr}},
+			streamed:  true,
+			expCode:   http.StatusOK,
+			expBody:   "name\npetr\n",
+		},
+		{
+			name:     "success, no names",
+			target:   Path + "?columns=name&limit=1",
+			pages:    [][]*pbModels.User{{{Id: "1"}}},
+			streamed: true,
+			limited:  true,
+			expCode:  http.StatusOK,
+			expBody:  "name\n\n",
+		},
+		{
+			name:     "success, hidden fields",
+			target:   Path + "?columns=name,email",
+			token:    "guest",
+			pages:    [][]*pbModels.User{{ivan}},
+			streamed: true,
+			expCode:  http.StatusOK,
+			expBody:  "name,email\nivan,\n",
+		},
+		{
+			name:     "success, no users",
+			target:   Path + "?columns=name",
+			streamed: true,
+			expCode:  http.StatusOK,
+			expBody:  "name\n",
+		},
+		{
+			name:    "failed, unknown column",
+			target:  Path + "?columns=name,password",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "failed, limit above the guard",
+			target:  Path + "?limit=11",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "failed, invalid filter",
+			target:   Path + "?status=unknown",
+			recvErr:  status.Error(codes.InvalidArgument, "status"),
+			streamed: true,
+			expCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := apiMockPkg.NewMockUserClient(ctl)
+			if c.streamed {
+				stream := apiMockPkg.NewMockUser_UserAllListClient(ctl)
+				for _, page := range c.pages {
+					stream.EXPECT().Recv().Return(&pb.UserAllListResponse{Users: page}, nil)
+				}
+				if c.recvErr != nil {
+					stream.EXPECT().Recv().Return(nil, c.recvErr)
+				} else if !c.limited {
+					stream.EXPECT().Recv().Return(nil, io.EOF)
+				}
+				user.EXPECT().UserAllList(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, in *pb.UserAllListRequest, _ ...grpc.CallOption) (pb.User_UserAllListClient, error) {
+						assert.Equal(t, c.pageToken, in.GetPageToken())
+						return stream, nil
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, c.target, nil)
+			if c.token != "" {
+				req.Header.Set("Grpc-Metadata-Role-Token", c.token)
+			}
+			rec := httptest.NewRecorder()
+			methods := grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc)
+			New(user, Config{MaxRows: 10}, methods, fields, pages, loggerPkg.NewFatal()).Export(rec, req)
+
+			assert.Equal(t, c.expCode, rec.Code)
+			if c.expCode == http.StatusOK {
+				assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+				assert.Equal(t, c.expBody, rec.Body.String())
+
+				token := rec.Result().Trailer.Get(NextPageTokenTrailer)
+				if c.expLast == "" {
+					assert.Empty(t, token)
+					return
+				}
+				cursor, err := pages.Decode(token, false, pagetokenPkg.Filter("<nil>"))
+				require.NoError(t, err)
+				assert.Equal(t, c.expLast, cursor.Last)
+			}
+		})
+	}
+}
+
+func TestHandler_ExportDelta(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ivan := &pbModels.User{Id: "1", Name: "ivan", Email: "ivan@example.com"}
+	fields, err := grpcPkg.NewFields(grpcPkg.FieldsConfig{})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		target  string
+		pages   []*pb.UserChangesResponse
+		expReq  *pb.UserChangesRequest
+		expCode int
+		expBody string
+		expNext string
+	}{
+		{
+			name:   "success, changed and deleted users",
+			target: Path + "?since=100&include_deleted=true&columns=id,name,email",
+			pages: []*pb.UserChangesResponse{
+				{Deleted: []*pb.UserTombstone{{Id: "2", Name: "petr", DeletedAt: 150}}, NextSince: 200},
+				{Users: []*pbModels.User{ivan}},
+			},
+			expReq:  &pb.UserChangesRequest{Since: 100, Limit: chunkRows, IncludeDeleted: true},
+			expCode: http.StatusOK,
+			expBody: "id,name,email,deleted_at\n2,petr,,150\n1,ivan,ivan@example.com,\n",
+			expNext: "200",
+		},
+		{
+			name:   "success, changed users",
+			target: Path + "?since=100&columns=id,name",
+			pages: []*pb.UserChangesResponse{
+				{NextSince: 200},
+				{Users: []*pbModels.User{ivan}},
+			},
+			expReq:  &pb.UserChangesRequest{Since: 100, Limit: chunkRows},
+			expCode: http.StatusOK,
+			expBody: "id,name\n1,ivan\n",
+			expNext: "200",
+		},
+		{
+			name:    "failed, pruned tombstones",
+			target:  Path + "?since=100&include_deleted=true",
+			expReq:  &pb.UserChangesRequest{Since: 100, Limit: chunkRows, IncludeDeleted: true},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "failed, invalid since",
+			target:  Path + "?since=-1",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "failed, filtered delta",
+			target:  Path + "?since=100&status=active",
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := apiMockPkg.NewMockUserClient(ctl)
+			if c.expReq != nil {
+				stream := apiMockPkg.NewMockUser_UserChangesClient(ctl)
+				for _, page := range c.pages {
+					stream.EXPECT().Recv().Return(page, nil)
+				}
+				if c.pages == nil {
+					stream.EXPECT().Recv().Return(nil, status.Error(codes.FailedPrecondition, "tombstones are pruned"))
+				} else {
+					stream.EXPECT().Recv().Return(nil, io.EOF)
+				}
+				user.EXPECT().UserChanges(gomock.Any(), c.expReq).Return(stream, nil)
+			}
+
+			rec := httptest.NewRecorder()
+			methods := grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc)
+			New(user, Config{MaxRows: 10}, methods, fields, nil, loggerPkg.NewFatal()).
+				Export(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+
+			assert.Equal(t, c.expCode, rec.Code)
+			if c.expCode == http.StatusOK {
+				assert.Equal(t, c.expBody, rec.Body.String())
+				assert.Equal(t, c.expNext, rec.Header().Get(NextSinceHeader))
+			}
+		})
+	}
+}
+
+func TestHandler_ExportDisabled(t *testing.T) {
+	methods := grpcPkg.NewMethods(grpcPkg.MethodsConfig{Disabled: []string{allListMethod}}, pb.User_ServiceDesc)
+	fields, err := grpcPkg.NewFields(grpcPkg.FieldsConfig{})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	New(nil, Config{MaxRows: 10}, methods, fields, nil, loggerPkg.NewFatal()).
+		Export(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+
+	rec = httptest.NewRecorder()
+	New(nil, Config{}, grpcPkg.NewMethods(grpcPkg.MethodsConfig{}), fields, nil, loggerPkg.NewFatal()).
+		Export(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
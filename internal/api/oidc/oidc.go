@@ -0,0 +1,181 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	oidcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/oidc"
+)
+
+const (
+	LoginPath    = "/v1/oidc/login"
+	CallbackPath = "/v1/oidc/callback"
+
+	stateCookie   = "oidc_state"
+	sessionCookie = "session"
+	stateTTL      = 10 * time.Minute
+)
+
+type Provider interface {
+	AuthCodeURL(ctx context.Context, state, nonce string) (string, error)
+	Exchange(ctx context.Context, code, nonce string) (oidcPkg.Claims, error)
+}
+
+// Handler serves login via OpenID Connect provider. Verified identity is passed
+// to the data service, which maps it to the local user and issues session token.
+type Handler struct {
+	provider Provider
+	user     pb.UserClient
+	secure   bool
+	logger   *zap.SugaredLogger
+}
+
+func New(provider Provider, user pb.UserClient, redirectURL string, logger *zap.SugaredLogger) *Handler {
+	return &Handler{
+		provider: provider,
+		user:     user,
+		secure:   strings.HasPrefix(redirectURL, "https://"),
+		logger:   logger,
+	}
+}
+
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(LoginPath, h.Login)
+	mux.HandleFunc(CallbackPath, h.Callback)
+}
+
+// Login redirects to the provider, state and nonce are kept in the cookie till the callback.
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	state, err := randomHex(16)
+	if err != nil {
+		h.error(w, http.StatusInternalServerError, "login", err)
+		return
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		h.error(w, http.StatusInternalServerError, "login", err)
+		return
+	}
+	authURL, err := h.provider.AuthCodeURL(r.Context(), state, nonce)
+	if err != nil {
+		h.error(w, http.StatusBadGateway, "login", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state + "." + nonce,
+		Path:     CallbackPath,
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		h.error(w, http.StatusUnauthorized, "callback", status.Errorf(codes.Unauthenticated, "provider error: %s", reason))
+		return
+	}
+
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		h.error(w, http.StatusBadRequest, "callback", status.Error(codes.InvalidArgument, "state cookie is missing"))
+		return
+	}
+	state, nonce, _ := strings.Cut(cookie.Value, ".")
+	if subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		h.error(w, http.StatusBadRequest, "callback", status.Error(codes.InvalidArgument, "state mismatch"))
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: CallbackPath, MaxAge: -1})
+
+	claims, err := h.provider.Exchange(r.Context(), query.Get("code"), nonce)
+	if err != nil {
+		h.error(w, http.StatusUnauthorized, "callback", err)
+		return
+	}
+
+	resp, err := h.user.UserLoginExternal(r.Context(), &pb.UserLoginExternalRequest{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	})
+	if err != nil {
+		h.error(w, httpStatus(status.Code(err)), "callback", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    resp.GetToken(),
+		Path:     "/",
+		Expires:  time.Unix(resp.GetExpiresAt(), 0),
+		HttpOnly: true,
+		Secure:   h.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	h.json(w, http.StatusOK, map[string]interface{}{
+		"token":      resp.GetToken(),
+		"name":       resp.GetName(),
+		"expires_at": resp.GetExpiresAt(),
+	})
+}
+
+func (h *Handler) error(w http.ResponseWriter, code int, msg string, err error) {
+	if code >= http.StatusInternalServerError {
+		h.logger.Errorf("oidc %s: %v", msg, err)
+	} else {
+		h.logger.Warnf("oidc %s: %v", msg, err)
+	}
+	message := err.Error()
+	if s, ok := status.FromError(err); ok {
+		message = s.Message()
+	}
+	h.json(w, code, map[string]string{"error": message})
+}
+
+func (h *Handler) json(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Errorf("oidc write response: %v", err)
+	}
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func randomHex(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
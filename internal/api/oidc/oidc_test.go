@@ -0,0 +1,123 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+	oidcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/oidc"
+)
+
+type provider struct {
+	nonce string
+	err   error
+}
+
+func (p *provider) AuthCodeURL(_ context.Context, state, nonce string) (string, error) {
+	p.nonce = nonce
+	return "https://idp.example.com/authorize?state=" + state, nil
+}
+
+func (p *provider) Exchange(_ context.Context, code, nonce string) (oidcPkg.Claims, error) {
+	if p.err != nil {
+		return oidcPkg.Claims{}, p.err
+	}
+	if code != "code" || nonce != p.nonce {
+		return oidcPkg.Claims{}, oidcPkg.ErrInvalidToken
+	}
+	return oidcPkg.Claims{Issuer: "https://idp.example.com", Subject: "42", PreferredUsername: "ivan"}, nil
+}
+
+func TestHandler_Callback(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name        string
+		state       string
+		exchangeErr error
+		loginErr    error
+		loginCalls  int
+		expCode     int
+	}{
+		{
+			name:       "success",
+			loginCalls: 1,
+			expCode:    http.StatusOK,
+		},
+		{
+			name:    "failed, state mismatch",
+			state:   "forged",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "failed, invalid token",
+			exchangeErr: errors.Wrap(oidcPkg.ErrInvalidToken, "token is expired"),
+			expCode:     http.StatusUnauthorized,
+		},
+		{
+			name:       "failed, subject is not linked",
+			loginErr:   status.Error(codes.PermissionDenied, "user not found"),
+			loginCalls: 1,
+			expCode:    http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := apiMockPkg.NewMockUserClient(ctl)
+			p := &provider{err: c.exchangeErr}
+			h := New(p, mockUser, "http://localhost:9000"+CallbackPath, loggerPkg.NewFatal())
+			mux := http.NewServeMux()
+			h.Register(mux)
+
+			login := httptest.NewRecorder()
+			mux.ServeHTTP(login, httptest.NewRequest(http.MethodGet, LoginPath, nil))
+			require.Equal(t, http.StatusFound, login.Code)
+			location, err := login.Result().Location()
+			require.NoError(t, err)
+
+			state := c.state
+			if state == "" {
+				state = location.Query().Get("state")
+			}
+			mockUser.EXPECT().UserLoginExternal(gomock.Any(), &pb.UserLoginExternalRequest{
+				Issuer:            "https://idp.example.com",
+				Subject:           "42",
+				PreferredUsername: "ivan",
+			}).Return(&pb.UserLoginExternalResponse{Token: "token", Name: "ivan", ExpiresAt: 1660000000}, c.loginErr).
+				Times(c.loginCalls)
+
+			req := httptest.NewRequest(http.MethodGet, CallbackPath+"?code=code&state="+state, nil)
+			for _, cookie := range login.Result().Cookies() {
+				req.AddCookie(cookie)
+			}
+			callback := httptest.NewRecorder()
+			mux.ServeHTTP(callback, req)
+
+			assert.Equal(t, c.expCode, callback.Code)
+			if c.expCode == http.StatusOK {
+				assert.Contains(t, callback.Body.String(), `"token":"token"`)
+				var session *http.Cookie
+				for _, cookie := range callback.Result().Cookies() {
+					if cookie.Name == sessionCookie {
+						session = cookie
+					}
+				}
+				require.NotNil(t, session)
+				assert.True(t, session.HttpOnly)
+			}
+		})
+	}
+}
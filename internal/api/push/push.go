@@ -0,0 +1,589 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	websocketPkg "gitlab.ozon.dev/iTukaev/homework/pkg/websocket"
+)
+
+const (
+	Path = "/v1/users/events"
+
+	defaultInterval     = time.Second
+	defaultBuffer       = 256
+	defaultPingInterval = 30 * time.Second
+	defaultPongTimeout  = time.Minute
+	defaultWriteTimeout = 10 * time.Second
+	defaultReplay       = 1024
+
+	// meta identifies polls of the data service in its logs
+	meta = "events-push"
+)
+
+var errStopping = errors.New("service is stopping")
+
+// changesMethod is the data service method, which events are polled from.
+var changesMethod = "/" + pb.User_ServiceDesc.ServiceName + "/UserChanges"
+
+// queryMetadata are query parameters taken as metadata, browsers can not set headers of WebSocket requests.
+var queryMetadata = map[string]string{
+	"meta":       "meta",
+	"role_token": grpcPkg.RoleTokenMetaKey,
+}
+
+// Config of the user events pushed over WebSocket.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Roles of callers allowed to subscribe by their role token, empty roles allow all callers.
+	Roles []string `mapstructure:"roles"`
+	// Interval of polling the data service for changes, default is 1s.
+	Interval time.Duration `mapstructure:"interval"`
+	// Buffer is the number of events queued for a connection, the connection is closed
+	// as a slow consumer, when its queue is full. Default is 256.
+	Buffer int `mapstructure:"buffer"`
+	// PingInterval between pings of the connection, default is 30s.
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// PongTimeout closes the connection, if no pong or message is received within it, default is 1m.
+	PongTimeout time.Duration `mapstructure:"pong_timeout"`
+	// WriteTimeout of one event or ping, default is 10s.
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// Replay is the number of the last events kept to replay SSE streams after Last-Event-ID,
+	// default is 1024. Changes are polled without connections then, so reconnecting clients
+	// miss nothing. Negative replay disables it, changes are polled only for connections.
+	Replay int `mapstructure:"replay"`
+}
+
+// Event is a message of the connection. User of user_deleted has only id and name.
+// ID is unique within the process, it is Last-Event-ID of SSE streams.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	At   int64           `json:"at"`
+	User json.RawMessage `json:"user"`
+}
+
+// event is a change polled from the data service and shared by connections.
+type event struct {
+	// seq is a number of the event in order of publishing
+	seq  uint64
+	typ  string
+	at   int64
+	user *pbModels.User
+}
+
+// subscriber is a connection, done is closed with the close code and the reason, when the connection
+// has to be closed by the server.
+type subscriber struct {
+	events chan event
+	done   chan struct{}
+	code   uint16
+	reason string
+}
+
+// Handler pushes user events to WebSocket connections. Changes are polled from the data service
+// once for all connections, while there is any. Every connection has its queue, so a slow one
+// does not delay others, it is closed instead.
+type Handler struct {
+	user       pb.UserClient
+	cfg        Config
+	methods    *grpcPkg.Methods
+	fields     *grpcPkg.Fields
+	authorizer grpcPkg.Authorizer
+	logger     *zap.SugaredLogger
+
+	// boot tells IDs of events of the process from IDs of previous ones
+	boot string
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	stopped     bool
+	seq         uint64
+	// ring keeps the last published events to replay them
+	ring []event
+}
+
+// New returns handler of the config, nil authorizer allows calls of all callers of the roles.
+func New(
+	user pb.UserClient,
+	cfg Config,
+	methods *grpcPkg.Methods,
+	fields *grpcPkg.Fields,
+	authorizer grpcPkg.Authorizer,
+	logger *zap.SugaredLogger,
+) *Handler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = defaultBuffer
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.PongTimeout <= 0 {
+		cfg.PongTimeout = defaultPongTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+	if cfg.Replay == 0 {
+		cfg.Replay = defaultReplay
+	}
+	return &Handler{
+		user:        user,
+		cfg:         cfg,
+		methods:     methods,
+		fields:      fields,
+		authorizer:  authorizer,
+		logger:      logger,
+		boot:        strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Register serves the events behind the impersonation, so policies see both identities of the caller.
+func (h *Handler) Register(mux *http.ServeMux, impersonation grpcPkg.ImpersonationConfig) {
+	mux.Handle(Path, withQueryMetadata(grpcPkg.ImpersonationHandler(impersonation, h.fields, http.HandlerFunc(h.Events))))
+	mux.Handle(SSEPath, withQueryMetadata(grpcPkg.ImpersonationHandler(impersonation, h.fields, http.HandlerFunc(h.Stream))))
+}
+
+// withQueryMetadata sets Grpc-Metadata-* headers of the query parameters, which are not sent as headers.
+func withQueryMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		for param, key := range queryMetadata {
+			header := runtime.MetadataHeaderPrefix + key
+			if value := query.Get(param); value != "" && r.Header.Get(header) == "" {
+				r.Header.Set(header, value)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Events upgrades GET to WebSocket and sends user events as JSON text messages.
+// Query parameters filter events of the connection:
+//   - types: comma separated user_created, user_updated and user_deleted;
+//   - names: comma separated user names;
+//   - status and attributes[<key>]=<value> filter created and updated users.
+//
+// Metadata is taken from Grpc-Metadata-* headers, meta and role_token query parameters are taken,
+// if the headers are not set. Fields hidden from the caller role are not sent. Created and updated
+// events are told apart by the time of the creation, so a user created and updated within a second
+// is created. The server pings the connection and closes it, if the client does not answer.
+// A connection, which does not read its events, is closed with 1008 policy violation.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.accept(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	sub, _, _, err := h.subscribe("")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(sub)
+
+	conn, err := websocketPkg.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	counter.PushConnections.Add(1)
+	defer counter.PushConnections.Add(-1)
+
+	callerMeta := grpcPkg.GetMetaFromContext(ctx)
+	h.logger.Debugf("[%s] events push: connected [%+v]", callerMeta, *filter)
+	if err = h.serve(ctx, conn, sub, filter); err != nil {
+		h.logger.Infof("[%s] events push: %v", callerMeta, err)
+		return
+	}
+	h.logger.Debugf("[%s] events push: disconnected", callerMeta)
+}
+
+// accept checks the subscription is allowed and returns its filter, rejected requests are answered.
+func (h *Handler) accept(w http.ResponseWriter, r *http.Request) (*filter, bool) {
+	if !h.cfg.Enabled {
+		http.Error(w, "events push is disabled by config", http.StatusNotImplemented)
+		return nil, false
+	}
+	if err := h.authorize(r.Context()); err != nil {
+		http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+		return nil, false
+	}
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return filter, true
+}
+
+// authorize checks the method is enabled, the role of the caller and the policies of UserChanges.
+func (h *Handler) authorize(ctx context.Context) error {
+	if err := h.methods.Check(changesMethod); err != nil {
+		return err
+	}
+	if len(h.cfg.Roles) != 0 {
+		role, allowed := h.fields.Role(ctx), false
+		for _, r := range h.cfg.Roles {
+			allowed = allowed || r == role
+		}
+		if !allowed {
+			return status.Errorf(codes.PermissionDenied, "role [%s] is not allowed to subscribe", role)
+		}
+	}
+	if h.authorizer != nil {
+		return h.authorizer.Authorize(ctx, changesMethod, nil)
+	}
+	return nil
+}
+
+// serve writes events and pings, until the client disconnects, stops answering or falls behind.
+func (h *Handler) serve(ctx context.Context, conn *websocketPkg.Conn, sub *subscriber, filter *filter) error {
+	// messages of the client are not expected, they are read for pongs and the close frame
+	closed := make(chan error, 1)
+	_ = conn.SetReadDeadline(time.Now().Add(h.cfg.PongTimeout))
+	conn.SetPongHandler(func() {
+		_ = conn.SetReadDeadline(time.Now().Add(h.cfg.PongTimeout))
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+			_ = conn.SetReadDeadline(time.Now().Add(h.cfg.PongTimeout))
+		}
+	}()
+
+	write := func(op byte, payload []byte) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+		return conn.WriteMessage(op, payload)
+	}
+	ping := time.NewTicker(h.cfg.PingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case err := <-closed:
+			var closeErr *websocketPkg.CloseError
+			if errors.As(err, &closeErr) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return errors.Wrap(err, "read")
+		case <-sub.done:
+			_ = conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			_ = conn.WriteClose(sub.code, sub.reason)
+			return errors.New(sub.reason)
+		case <-ping.C:
+			if err := write(websocketPkg.OpPing, nil); err != nil {
+				return errors.Wrap(err, "ping")
+			}
+		case e := <-sub.events:
+			if !filter.match(e) {
+				continue
+			}
+			message, err := h.marshal(ctx, e)
+			if err != nil {
+				return errors.Wrap(err, "marshal")
+			}
+			if err = write(websocketPkg.OpText, message); err != nil {
+				return errors.Wrap(err, "write")
+			}
+			counter.PushEvents.Inc()
+		}
+	}
+}
+
+// marshal strips fields hidden from the caller of the user shared by connections.
+func (h *Handler) marshal(ctx context.Context, e event) ([]byte, error) {
+	user := proto.Clone(e.user)
+	h.fields.Filter(ctx, user)
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Event{ID: h.id(e), Type: e.typ, At: e.at, User: data})
+}
+
+func (h *Handler) id(e event) string {
+	return h.boot + "-" + strconv.FormatUint(e.seq, 10)
+}
+
+// subscribe adds the subscriber. Events after the last event ID are returned to replay them,
+// they are all kept events, if some are lost, then false is returned too.
+func (h *Handler) subscribe(lastID string) (*subscriber, []event, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		return nil, nil, false, errStopping
+	}
+	sub := &subscriber{events: make(chan event, h.cfg.Buffer), done: make(chan struct{})}
+	h.subscribers[sub] = struct{}{}
+	if lastID == "" {
+		return sub, nil, true, nil
+	}
+	replay, complete := h.after(lastID)
+	return sub, replay, complete, nil
+}
+
+// after returns kept events after the one of the ID, h.mu is held. Events may be lost, if the ID
+// is of the previous process or its event is not kept anymore, false is returned then.
+func (h *Handler) after(id string) ([]event, bool) {
+	all := append([]event(nil), h.ring...)
+	boot, value, found := strings.Cut(id, "-")
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if !found || err != nil || boot != h.boot || seq > h.seq {
+		return all, false
+	}
+	first := h.seq + 1 - uint64(len(h.ring))
+	if seq+1 < first {
+		return all, false
+	}
+	return all[seq+1-first:], true
+}
+
+func (h *Handler) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+}
+
+// drop removes the subscriber and tells its connection to close, h.mu is held.
+func (h *Handler) drop(sub *subscriber, code uint16, reason string) {
+	delete(h.subscribers, sub)
+	sub.code, sub.reason = code, reason
+	close(sub.done)
+}
+
+// publish numbers and keeps the events and queues them to all subscribers, slow ones are dropped
+// instead of waiting for them.
+func (h *Handler) publish(events []event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range events {
+		h.seq++
+		events[i].seq = h.seq
+	}
+	if h.cfg.Replay > 0 {
+		h.ring = append(h.ring, events...)
+		if len(h.ring) > h.cfg.Replay {
+			h.ring = h.ring[len(h.ring)-h.cfg.Replay:]
+		}
+	}
+	for sub := range h.subscribers {
+		if !queue(sub, events) {
+			counter.PushSlowClosed.Inc()
+			h.drop(sub, websocketPkg.ClosePolicyViolation, "slow consumer")
+		}
+	}
+}
+
+// queue returns false, if the queue of the subscriber is full.
+func queue(sub *subscriber, events []event) bool {
+	for _, e := range events {
+		select {
+		case sub.events <- e:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Handler) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers) != 0
+}
+
+// Run polls the data service for changes, while there are connections or the replay is enabled.
+// Connections are closed with 1001 going away, when the context is done.
+func (h *Handler) Run(ctx context.Context) {
+	defer func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.stopped = true
+		for sub := range h.subscribers {
+			h.drop(sub, websocketPkg.CloseGoingAway, "server is stopping")
+		}
+	}()
+	if !h.cfg.Enabled {
+		<-ctx.Done()
+		return
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "meta", meta)
+	var (
+		since int64
+		// seen are changes at or after since of the next poll, they are returned by it again
+		seen map[string]struct{}
+	)
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if h.cfg.Replay < 0 && !h.active() {
+			// changes made without connections are not sent
+			since, seen = 0, nil
+			continue
+		}
+		if since == 0 {
+			since = time.Now().Unix()
+		}
+
+		events, next, err := h.poll(ctx, since)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Errorw("events push: poll changes", "since", since, "error", err)
+			}
+			continue
+		}
+		fresh := events[:0]
+		nextSeen := make(map[string]struct{})
+		for _, e := range events {
+			key := e.key()
+			if e.at >= next {
+				nextSeen[key] = struct{}{}
+			}
+			if _, ok := seen[key]; !ok {
+				fresh = append(fresh, e)
+			}
+		}
+		since, seen = next, nextSeen
+		h.publish(fresh)
+	}
+}
+
+// poll returns changes since the time and since of the next poll. Deletions go first,
+// as the data service sends them.
+func (h *Handler) poll(ctx context.Context, since int64) ([]event, int64, error) {
+	stream, err := h.user.UserChanges(ctx, &pb.UserChangesRequest{Since: since, IncludeDeleted: true})
+	if err != nil {
+		return nil, 0, err
+	}
+	var (
+		events []event
+		next   = since
+	)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return events, next, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.GetNextSince() != 0 {
+			next = resp.GetNextSince()
+		}
+		for _, tombstone := range resp.GetDeleted() {
+			events = append(events, event{
+				typ:  eventsPkg.TypeUserDeleted,
+				at:   tombstone.GetDeletedAt(),
+				user: &pbModels.User{Id: tombstone.GetId(), Name: tombstone.GetName()},
+			})
+		}
+		for _, user := range resp.GetUsers() {
+			typ := eventsPkg.TypeUserUpdated
+			if user.GetCreatedAt() == user.GetUpdatedAt() {
+				typ = eventsPkg.TypeUserCreated
+			}
+			events = append(events, event{typ: typ, at: user.GetUpdatedAt(), user: user})
+		}
+	}
+}
+
+func (e event) key() string {
+	return e.typ + "/" + e.user.GetId() + "/" + strconv.FormatInt(e.at, 10)
+}
+
+// filter of events of the connection, empty fields match all events.
+type filter struct {
+	types      map[string]bool
+	names      map[string]bool
+	status     string
+	attributes map[string]string
+}
+
+var eventTypes = []string{eventsPkg.TypeUserCreated, eventsPkg.TypeUserUpdated, eventsPkg.TypeUserDeleted}
+
+func parseFilter(query url.Values) (*filter, error) {
+	f := &filter{status: query.Get("status"), attributes: make(map[string]string)}
+	if value := query.Get("types"); value != "" {
+		f.types = make(map[string]bool)
+		for _, typ := range strings.Split(value, ",") {
+			typ = strings.TrimSpace(typ)
+			known := false
+			for _, t := range eventTypes {
+				known = known || t == typ
+			}
+			if !known {
+				return nil, errors.Errorf("unknown event type [%s], types are %s", typ, strings.Join(eventTypes, ", "))
+			}
+			f.types[typ] = true
+		}
+	}
+	if value := query.Get("names"); value != "" {
+		f.names = make(map[string]bool)
+		for _, name := range strings.Split(value, ",") {
+			f.names[strings.TrimSpace(name)] = true
+		}
+	}
+	for param, values := range query {
+		if strings.HasPrefix(param, "attributes[") && strings.HasSuffix(param, "]") && len(values) != 0 {
+			f.attributes[strings.TrimSuffix(strings.TrimPrefix(param, "attributes["), "]")] = values[0]
+		}
+	}
+	return f, nil
+}
+
+// match tells, if the event is sent to the connection. Deleted users have no status and attributes,
+// so only types and names filter their events.
+func (f *filter) match(e event) bool {
+	if f.types != nil && !f.types[e.typ] {
+		return false
+	}
+	if f.names != nil && !f.names[e.user.GetName()] {
+		return false
+	}
+	if e.typ == eventsPkg.TypeUserDeleted {
+		return true
+	}
+	if f.status != "" && e.user.GetStatus() != f.status {
+		return false
+	}
+	for key, value := range f.attributes {
+		if e.user.GetAttributes()[key] != value {
+			return false
+		}
+	}
+	return true
+}
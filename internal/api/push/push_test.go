@@ -0,0 +1,341 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+	websocketPkg "gitlab.ozon.dev/iTukaev/homework/pkg/websocket"
+)
+
+var (
+	ivan = &pbModels.User{Id: "1", Name: "ivan", Email: "ivan@example.com", Status: "active",
+		Attributes: map[string]string{"team": "core"}, CreatedAt: 100, UpdatedAt: 100}
+	petr = &pbModels.User{Id: "2", Name: "petr", Email: "petr@example.com", Status: "disabled",
+		CreatedAt: 100, UpdatedAt: 150}
+)
+
+// changes returns the stream of the responses.
+func changes(ctl *gomock.Controller, responses ...*pb.UserChangesResponse) pb.User_UserChangesClient {
+	stream := apiMockPkg.NewMockUser_UserChangesClient(ctl)
+	for _, resp := range responses {
+		stream.EXPECT().Recv().Return(resp, nil)
+	}
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	return stream
+}
+
+func newFields(t *testing.T) *grpcPkg.Fields {
+	fields, err := grpcPkg.NewFields(grpcPkg.FieldsConfig{
+		DefaultRole: "public",
+		Tokens:      map[string]string{"admin": "secret"},
+		Hidden:      map[string][]string{"public": {"email"}},
+	})
+	require.NoError(t, err)
+	return fields
+}
+
+func TestHandler_Events(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// the data service returns changes at since or later, polls start at the current time
+	var (
+		mu      sync.Mutex
+		base    = time.Now().Unix() + 10
+		now     int64
+		users   []*pbModels.User
+		deleted []*pb.UserTombstone
+	)
+	user := apiMockPkg.NewMockUserClient(ctl)
+	user.EXPECT().UserChanges(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, in *pb.UserChangesRequest, _ ...grpc.CallOption) (pb.User_UserChangesClient, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			resp := &pb.UserChangesResponse{NextSince: now}
+			for _, tombstone := range deleted {
+				if tombstone.GetDeletedAt() >= in.GetSince() {
+					resp.Deleted = append(resp.Deleted, tombstone)
+				}
+			}
+			for _, user := range users {
+				if user.GetUpdatedAt() >= in.GetSince() {
+					resp.Users = append(resp.Users, user)
+				}
+			}
+			return changes(ctl, resp), nil
+		}).AnyTimes()
+	write := func(at int64, changed []*pbModels.User, tombstones []*pb.UserTombstone) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = at
+		users = append(users, changed...)
+		deleted = append(deleted, tombstones...)
+	}
+
+	handler := New(user, Config{Enabled: true, Interval: 10 * time.Millisecond},
+		grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc), newFields(t), nil, loggerPkg.NewFatal())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.Run(ctx)
+
+	mux := http.NewServeMux()
+	handler.Register(mux, grpcPkg.ImpersonationConfig{})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ws := "ws" + strings.TrimPrefix(srv.URL, "http") + Path
+	conn, err := websocket.Dial(ws+"?types=user_created,user_deleted", "", srv.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	// the connection with the role token sees emails
+	admin, err := websocket.Dial(ws+"?role_token=secret&names=petr", "", srv.URL)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	receive := func(conn *websocket.Conn) Event {
+		var e Event
+		require.NoError(t, websocket.JSON.Receive(conn, &e))
+		return e
+	}
+
+	// changes at since of the next poll are polled again, they are sent once
+	write(base+2, []*pbModels.User{
+		{Id: "1", Name: "ivan", Email: "ivan@example.com", CreatedAt: base + 2, UpdatedAt: base + 2},
+		{Id: "2", Name: "petr", Email: "petr@example.com", CreatedAt: base - 100, UpdatedAt: base + 2},
+	}, []*pb.UserTombstone{{Id: "3", Name: "oleg", DeletedAt: base + 1}})
+	e := receive(conn)
+	assert.Equal(t, eventsPkg.TypeUserDeleted, e.Type)
+	assert.JSONEq(t, `{"id": "3", "name": "oleg"}`, compact(t, e.User, "id", "name"))
+	e = receive(conn)
+	assert.Equal(t, eventsPkg.TypeUserCreated, e.Type)
+	assert.Equal(t, base+2, e.At)
+	assert.JSONEq(t, `{"name": "ivan", "email": ""}`, compact(t, e.User, "name", "email"),
+		"the email is hidden from the default role")
+	e = receive(admin)
+	assert.Equal(t, eventsPkg.TypeUserUpdated, e.Type)
+	assert.JSONEq(t, `{"name": "petr", "email": "petr@example.com"}`, compact(t, e.User, "name", "email"))
+
+	write(base+3, []*pbModels.User{{Id: "4", Name: "anna", CreatedAt: base + 3, UpdatedAt: base + 3}}, nil)
+	e = receive(conn)
+	assert.Equal(t, eventsPkg.TypeUserCreated, e.Type)
+	assert.JSONEq(t, `{"name": "anna"}`, compact(t, e.User, "name"))
+
+	// connections are closed, when the server is stopping
+	cancel()
+	var message string
+	assert.Error(t, websocket.Message.Receive(conn, &message))
+}
+
+// compact keeps the keys of the JSON object.
+func compact(t *testing.T, data json.RawMessage, keys ...string) string {
+	var object map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &object))
+	res := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		res[key] = object[key]
+	}
+	out, err := json.Marshal(res)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestHandler_EventsRejected(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		methods grpcPkg.MethodsConfig
+		target  string
+		expCode int
+	}{
+		{
+			name:    "disabled",
+			target:  Path,
+			expCode: http.StatusNotImplemented,
+		},
+		{
+			name:    "method disabled",
+			cfg:     Config{Enabled: true},
+			methods: grpcPkg.MethodsConfig{Disabled: []string{changesMethod}},
+			target:  Path,
+			expCode: http.StatusNotImplemented,
+		},
+		{
+			name:    "role is not allowed",
+			cfg:     Config{Enabled: true, Roles: []string{"admin"}},
+			target:  Path + "?role_token=guess",
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:    "unknown type",
+			cfg:     Config{Enabled: true},
+			target:  Path + "?types=user_renamed",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "not websocket",
+			cfg:     Config{Enabled: true, Roles: []string{"admin"}},
+			target:  Path + "?role_token=secret",
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handler := New(nil, c.cfg, grpcPkg.NewMethods(c.methods, pb.User_ServiceDesc), newFields(t), nil, loggerPkg.NewFatal())
+			mux := http.NewServeMux()
+			handler.Register(mux, grpcPkg.ImpersonationConfig{})
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+			assert.Equal(t, c.expCode, rec.Code)
+		})
+	}
+}
+
+func TestHandler_PublishSlowConsumer(t *testing.T) {
+	handler := New(nil, Config{Enabled: true, Buffer: 2}, nil, nil, nil, loggerPkg.NewFatal())
+	fast, _, _, _ := handler.subscribe("")
+	slow, _, _, _ := handler.subscribe("")
+
+	e := event{typ: eventsPkg.TypeUserCreated, user: ivan}
+	handler.publish([]event{e})
+	<-fast.events
+	handler.publish([]event{e, e})
+
+	assert.Len(t, fast.events, 2)
+	select {
+	case <-slow.done:
+		assert.Equal(t, uint16(websocketPkg.ClosePolicyViolation), slow.code)
+	default:
+		t.Fatal("slow consumer is not dropped")
+	}
+	assert.True(t, handler.active())
+	_, ok := handler.subscribers[slow]
+	assert.False(t, ok)
+}
+
+func TestHandler_After(t *testing.T) {
+	handler := New(nil, Config{Enabled: true, Replay: 2}, nil, nil, nil, loggerPkg.NewFatal())
+	handler.publish([]event{{user: ivan}, {user: petr}, {user: ivan}})
+	id := func(seq int) string {
+		return handler.boot + "-" + strconv.Itoa(seq)
+	}
+
+	cases := []struct {
+		name        string
+		id          string
+		expSeq      []uint64
+		expComplete bool
+	}{
+		{
+			name:        "oldest kept",
+			id:          id(1),
+			expSeq:      []uint64{2, 3},
+			expComplete: true,
+		},
+		{
+			name:        "last",
+			id:          id(3),
+			expComplete: true,
+		},
+		{
+			name:   "not kept",
+			id:     id(0),
+			expSeq: []uint64{2, 3},
+		},
+		{
+			name:   "not published",
+			id:     id(4),
+			expSeq: []uint64{2, 3},
+		},
+		{
+			name:   "previous process",
+			id:     "abc-2",
+			expSeq: []uint64{2, 3},
+		},
+		{
+			name:   "malformed",
+			id:     "2",
+			expSeq: []uint64{2, 3},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, replay, complete, err := handler.subscribe(c.id)
+			require.NoError(t, err)
+			var seq []uint64
+			for _, e := range replay {
+				seq = append(seq, e.seq)
+			}
+			assert.Equal(t, c.expSeq, seq)
+			assert.Equal(t, c.expComplete, complete)
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	deleted := event{typ: eventsPkg.TypeUserDeleted, user: &pbModels.User{Id: "3", Name: "oleg"}}
+	created := event{typ: eventsPkg.TypeUserCreated, user: ivan}
+	updated := event{typ: eventsPkg.TypeUserUpdated, user: petr}
+
+	cases := []struct {
+		name  string
+		query string
+		exp   []bool
+	}{
+		{
+			name: "no filter",
+			exp:  []bool{true, true, true},
+		},
+		{
+			name:  "types",
+			query: "types=user_deleted, user_updated",
+			exp:   []bool{true, false, true},
+		},
+		{
+			name:  "names",
+			query: "names=ivan,oleg",
+			exp:   []bool{true, true, false},
+		},
+		{
+			name:  "status, deleted users pass",
+			query: "status=active",
+			exp:   []bool{true, true, false},
+		},
+		{
+			name:  "attributes",
+			query: "attributes[team]=core&types=user_created,user_updated",
+			exp:   []bool{false, true, false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Path+"?"+strings.ReplaceAll(c.query, " ", "%20"), nil)
+			f, err := parseFilter(req.URL.Query())
+			require.NoError(t, err)
+			assert.Equal(t, c.exp, []bool{f.match(deleted), f.match(created), f.match(updated)})
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package push
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const (
+	SSEPath = Path + "/sse"
+
+	// resetEvent tells the client, that events after its Last-Event-ID may be lost,
+	// all kept events follow it
+	resetEvent = "reset"
+)
+
+// Stream serves GET as text/event-stream of the same events and filters as Events does.
+// Every event has its ID, the stream requested with Last-Event-ID starts with the kept events
+// after it, so EventSource reconnects without losing events. If some are lost, the stream starts
+// with the reset event followed by all kept events. Comments keep the connection alive.
+// A stream, which does not read its events, is ended, its client reconnects and replays them.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	filter, ok := h.accept(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	ctx := r.Context()
+	lastID := r.Header.Get("Last-Event-ID")
+	sub, replay, complete, err := h.subscribe(lastID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(sub)
+	counter.PushConnections.Add(1)
+	defer counter.PushConnections.Add(-1)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// proxies must not buffer the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(e event) error {
+		if !filter.match(e) {
+			return nil
+		}
+		data, err := h.marshal(ctx, e)
+		if err != nil {
+			return errors.Wrap(err, "marshal")
+		}
+		if _, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", h.id(e), e.typ, data); err != nil {
+			return errors.Wrap(err, "write")
+		}
+		counter.PushEvents.Inc()
+		return nil
+	}
+
+	callerMeta := grpcPkg.GetMetaFromContext(ctx)
+	h.logger.Debugf("[%s] events stream: connected after [%s], replay [%d %t] [%+v]",
+		callerMeta, lastID, len(replay), complete, *filter)
+	if !complete {
+		if _, err = fmt.Fprintf(w, "event: %s\ndata: {}\n\n", resetEvent); err != nil {
+			return
+		}
+	}
+	for _, e := range replay {
+		if err = write(e); err != nil {
+			h.logger.Infof("[%s] events stream: replay: %v", callerMeta, err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	ping := time.NewTicker(h.cfg.PingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Debugf("[%s] events stream: disconnected", callerMeta)
+			return
+		case <-sub.done:
+			h.logger.Infof("[%s] events stream: %s", callerMeta, sub.reason)
+			return
+		case <-ping.C:
+			if _, err = fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e := <-sub.events:
+			if err = write(e); err != nil {
+				h.logger.Infof("[%s] events stream: %v", callerMeta, err)
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,98 @@
+package push
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+// readEvent returns fields of the next event of the stream, comments are skipped.
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	fields := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) != 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		fields[field] = value
+	}
+}
+
+func TestHandler_Stream(t *testing.T) {
+	handler := New(nil, Config{Enabled: true}, grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc),
+		newFields(t), nil, loggerPkg.NewFatal())
+	mux := http.NewServeMux()
+	handler.Register(mux, grpcPkg.ImpersonationConfig{})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	oleg := &pbModels.User{Id: "3", Name: "oleg"}
+	handler.publish([]event{
+		{typ: eventsPkg.TypeUserCreated, at: 100, user: ivan},
+		{typ: eventsPkg.TypeUserUpdated, at: 150, user: petr},
+		{typ: eventsPkg.TypeUserDeleted, at: 160, user: oleg},
+	})
+	id := func(seq string) string {
+		return handler.boot + "-" + seq
+	}
+
+	stream := func(ctx context.Context, query, lastID string) *bufio.Reader {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+SSEPath+query, nil)
+		require.NoError(t, err)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+		return bufio.NewReader(resp.Body)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// events after the last one are replayed with the filter of the stream
+	r := stream(ctx, "?types=user_created,user_deleted", id("1"))
+	e := readEvent(t, r)
+	assert.Equal(t, id("3"), e["id"])
+	assert.Equal(t, eventsPkg.TypeUserDeleted, e["event"])
+	assert.Contains(t, e["data"], `"name":"oleg"`)
+
+	anna := &pbModels.User{Id: "4", Name: "anna", Email: "anna@example.com"}
+	handler.publish([]event{{typ: eventsPkg.TypeUserCreated, at: 200, user: anna}})
+	e = readEvent(t, r)
+	assert.Equal(t, id("4"), e["id"])
+	assert.Contains(t, e["data"], `"email":""`, "the email is hidden from the default role")
+
+	// all kept events follow the reset event of the unknown ID
+	r = stream(ctx, "?names=petr&role_token=secret", "previous-7")
+	assert.Equal(t, resetEvent, readEvent(t, r)["event"])
+	e = readEvent(t, r)
+	assert.Equal(t, id("2"), e["id"])
+	assert.Contains(t, e["data"], `"email":"petr@example.com"`)
+}
@@ -13,6 +13,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
@@ -21,22 +22,40 @@ import (
 	"gitlab.ozon.dev/iTukaev/homework/pkg/helper"
 )
 
-func New(user pb.UserClient, logger *zap.SugaredLogger, producer sarama.SyncProducer) pb.UserServer {
+// Maintenance returns error, while the data service is in maintenance mode.
+type Maintenance interface {
+	Check() error
+}
+
+func New(
+	user pb.UserClient,
+	logger *zap.SugaredLogger,
+	producer sarama.SyncProducer,
+	maintenance Maintenance,
+	methods *grpc.Methods,
+) pb.UserServer {
 	return &core{
-		producer: producer,
-		user:     user,
-		logger:   logger,
+		producer:    producer,
+		user:        user,
+		maintenance: maintenance,
+		methods:     methods,
+		logger:      logger,
 	}
 }
 
 type core struct {
-	producer sarama.SyncProducer
-	user     pb.UserClient
+	producer    sarama.SyncProducer
+	user        pb.UserClient
+	maintenance Maintenance
+	methods     *grpc.Methods
 	pb.UnimplementedUserServer
 	logger *zap.SugaredLogger
 }
 
 func (c *core) UserCreate(ctx context.Context, in *pb.UserCreateRequest) (*pb.UserCreateResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
 	meta := grpc.GetMetaFromContext(ctx)
 	uid := uuid.New().String()
 	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
@@ -47,8 +66,9 @@ func (c *core) UserCreate(ctx context.Context, in *pb.UserCreateRequest) (*pb.Us
 
 	msg, err := json.Marshal(user)
 	if err != nil {
-		c.logger.Errorf("[%s] marshal err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserCreate")
+		c.logger.Errorw("marshal", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	if err = c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
@@ -56,8 +76,9 @@ func (c *core) UserCreate(ctx context.Context, in *pb.UserCreateRequest) (*pb.Us
 		Key:   sarama.StringEncoder(consts.UserCreate),
 		Value: sarama.ByteEncoder(msg),
 	}); err != nil {
-		c.logger.Errorf("[%s] send message err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserCreate")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	return &pb.UserCreateResponse{
@@ -66,6 +87,9 @@ func (c *core) UserCreate(ctx context.Context, in *pb.UserCreateRequest) (*pb.Us
 }
 
 func (c *core) UserUpdate(ctx context.Context, in *pb.UserUpdateRequest) (*pb.UserUpdateResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
 	meta := grpc.GetMetaFromContext(ctx)
 	uid := uuid.New().String()
 	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
@@ -76,12 +100,17 @@ func (c *core) UserUpdate(ctx context.Context, in *pb.UserUpdateRequest) (*pb.Us
 		NameSet(in.GetName()).
 		PasswordSet(in.Profile.GetPassword()).
 		EmailSet(in.Profile.GetEmail()).
-		FullNameSet(in.Profile.GetFullName())
+		FullNameSet(in.Profile.GetFullName()).
+		AttributesSet(in.Profile.GetAttributes())
 
-	msg, err := json.Marshal(user)
+	msg, err := json.Marshal(models.UserUpdate{
+		User: *user,
+		Mask: in.GetUpdateMask().GetPaths(),
+	})
 	if err != nil {
-		c.logger.Errorf("[%s] marshal err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserUpdate")
+		c.logger.Errorw("marshal", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	if err = c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
@@ -89,8 +118,9 @@ func (c *core) UserUpdate(ctx context.Context, in *pb.UserUpdateRequest) (*pb.Us
 		Key:   sarama.StringEncoder(consts.UserUpdate),
 		Value: sarama.ByteEncoder(msg),
 	}); err != nil {
-		c.logger.Errorf("[%s] send message err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserUpdate")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	return &pb.UserUpdateResponse{
@@ -99,6 +129,9 @@ func (c *core) UserUpdate(ctx context.Context, in *pb.UserUpdateRequest) (*pb.Us
 }
 
 func (c *core) UserDelete(ctx context.Context, in *pb.UserDeleteRequest) (*pb.UserDeleteResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
 	meta := grpc.GetMetaFromContext(ctx)
 	uid := uuid.New().String()
 	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
@@ -110,8 +143,9 @@ func (c *core) UserDelete(ctx context.Context, in *pb.UserDeleteRequest) (*pb.Us
 		Key:   sarama.StringEncoder(consts.UserDelete),
 		Value: sarama.ByteEncoder(in.GetName()),
 	}); err != nil {
-		c.logger.Errorf("[%s] send message err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserDelete")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	return &pb.UserDeleteResponse{
@@ -119,6 +153,90 @@ func (c *core) UserDelete(ctx context.Context, in *pb.UserDeleteRequest) (*pb.Us
 	}, nil
 }
 
+func (c *core) UserRename(ctx context.Context, in *pb.UserRenameRequest) (*pb.UserRenameResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	meta := grpc.GetMetaFromContext(ctx)
+	uid := uuid.New().String()
+	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
+
+	c.logger.Debugf("[%s] user rename: [%s %s]", meta, in.GetName(), in.GetNewName())
+
+	msg, err := json.Marshal(models.NewUserRename().
+		NameSet(in.GetName()).
+		NewNameSet(in.GetNewName()))
+	if err != nil {
+		err = apperr.Wrap(err, "receiver.UserRename")
+		c.logger.Errorw("marshal", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	if err = c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
+		Topic: consts.TopicValidate,
+		Key:   sarama.StringEncoder(consts.UserRename),
+		Value: sarama.ByteEncoder(msg),
+	}); err != nil {
+		err = apperr.Wrap(err, "receiver.UserRename")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	return &pb.UserRenameResponse{
+		Uid: uid,
+	}, nil
+}
+
+func (c *core) UserDisable(ctx context.Context, in *pb.UserDisableRequest) (*pb.UserDisableResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	meta := grpc.GetMetaFromContext(ctx)
+	uid := uuid.New().String()
+	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
+
+	c.logger.Debugf("[%s] user disable: [%s]", meta, in.GetName())
+
+	if err := c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
+		Topic: consts.TopicValidate,
+		Key:   sarama.StringEncoder(consts.UserDisable),
+		Value: sarama.ByteEncoder(in.GetName()),
+	}); err != nil {
+		err = apperr.Wrap(err, "receiver.UserDisable")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	return &pb.UserDisableResponse{
+		Uid: uid,
+	}, nil
+}
+
+func (c *core) UserEnable(ctx context.Context, in *pb.UserEnableRequest) (*pb.UserEnableResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	meta := grpc.GetMetaFromContext(ctx)
+	uid := uuid.New().String()
+	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
+
+	c.logger.Debugf("[%s] user enable: [%s]", meta, in.GetName())
+
+	if err := c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
+		Topic: consts.TopicValidate,
+		Key:   sarama.StringEncoder(consts.UserEnable),
+		Value: sarama.ByteEncoder(in.GetName()),
+	}); err != nil {
+		err = apperr.Wrap(err, "receiver.UserEnable")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	return &pb.UserEnableResponse{
+		Uid: uid,
+	}, nil
+}
+
 func (c *core) UserGet(ctx context.Context, in *pb.UserGetRequest) (*pb.UserGetResponse, error) {
 	meta := grpc.GetMetaFromContext(ctx)
 	uid := uuid.New().String()
@@ -131,8 +249,9 @@ func (c *core) UserGet(ctx context.Context, in *pb.UserGetRequest) (*pb.UserGetR
 		Key:   sarama.StringEncoder(consts.UserGet),
 		Value: sarama.ByteEncoder(in.GetName()),
 	}); err != nil {
-		c.logger.Errorf("[%s] send message err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserGet")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	return &pb.UserGetResponse{
@@ -140,22 +259,50 @@ func (c *core) UserGet(ctx context.Context, in *pb.UserGetRequest) (*pb.UserGetR
 	}, nil
 }
 
+func (c *core) UserGetById(ctx context.Context, in *pb.UserGetByIdRequest) (*pb.UserGetByIdResponse, error) {
+	meta := grpc.GetMetaFromContext(ctx)
+	uid := uuid.New().String()
+	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
+
+	c.logger.Debugf("[%s] user get by id: [%s]", meta, in.GetId())
+
+	if err := c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
+		Topic: consts.TopicValidate,
+		Key:   sarama.StringEncoder(consts.UserGetByID),
+		Value: sarama.ByteEncoder(in.GetId()),
+	}); err != nil {
+		err = apperr.Wrap(err, "receiver.UserGetById")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
+	}
+
+	return &pb.UserGetByIdResponse{
+		Uid: uid,
+	}, nil
+}
+
 func (c *core) UserList(ctx context.Context, in *pb.UserListRequest) (*pb.UserListResponse, error) {
 	meta := grpc.GetMetaFromContext(ctx)
 	uid := uuid.New().String()
 	ctx = helper.InjectUidPubToCtx(ctx, uid, in.GetPubSub().String())
 
-	c.logger.Debugf("[%s] user list: [%v %v %v]", meta, in.GetLimit(), in.GetOffset(), in.GetOrder())
+	c.logger.Debugf("[%s] user list: [%v %v %v %s]", meta, in.GetLimit(), in.GetOffset(), in.GetOrder(), in.GetStatus())
 
+	if err := models.ValidateStatus(in.GetStatus()); err != nil {
+		return nil, apperr.Status(codes.InvalidArgument, err)
+	}
 	params := models.NewUserListParams().
 		LimitSet(in.GetLimit()).
 		OffsetSet(in.GetOffset()).
-		OrderSet(in.GetOrder())
+		OrderSet(in.GetOrder()).
+		AttributesSet(in.GetAttributes()).
+		StatusSet(in.GetStatus())
 
 	msg, err := json.Marshal(params)
 	if err != nil {
-		c.logger.Errorf("[%s] marshal err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserList")
+		c.logger.Errorw("marshal", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	if err = c.sendMessageWithCtx(ctx, &sarama.ProducerMessage{
@@ -163,8 +310,9 @@ func (c *core) UserList(ctx context.Context, in *pb.UserListRequest) (*pb.UserLi
 		Key:   sarama.StringEncoder(consts.UserList),
 		Value: sarama.ByteEncoder(msg),
 	}); err != nil {
-		c.logger.Errorf("[%s] send message err: %v", meta, err)
-		return nil, status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserList")
+		c.logger.Errorw("send message", append(apperr.Fields(err), "meta", meta)...)
+		return nil, apperr.Status(codes.Internal, err)
 	}
 
 	return &pb.UserListResponse{
@@ -177,12 +325,16 @@ func (c *core) UserAllList(in *pb.UserAllListRequest, stream pb.User_UserAllList
 	c.logger.Debugf("[%s] all users list: [%v %v]", meta, in.GetOrder(), in.GetLimit())
 
 	dataStream, err := c.user.UserAllList(stream.Context(), &pb.UserAllListRequest{
-		Order: in.GetOrder(),
-		Limit: in.GetLimit(),
+		Order:      in.GetOrder(),
+		Limit:      in.GetLimit(),
+		Attributes: in.GetAttributes(),
+		Status:     in.GetStatus(),
+		PageToken:  in.GetPageToken(),
 	})
 	if err != nil {
-		c.logger.Errorf("[%s] all user list: stream: %v", meta, err)
-		return status.Error(codes.Internal, err.Error())
+		err = apperr.Wrap(err, "receiver.UserAllList")
+		c.logger.Errorw("all user list: stream", append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
 	}
 
 	for {
@@ -192,11 +344,41 @@ func (c *core) UserAllList(in *pb.UserAllListRequest, stream pb.User_UserAllList
 		}
 		if err != nil {
 			c.logger.Errorf("[%s] all users list: next chunk: %v", meta, err)
-			return status.Error(codes.Internal, err.Error())
+			// invalid page tokens are errors of the client
+			return status.Convert(err).Err()
+		}
+		if err = stream.Send(next); err != nil {
+			err = apperr.Wrap(err, "receiver.UserAllList")
+			c.logger.Errorw("all users list: send chunk", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+	}
+}
+
+func (c *core) UserChanges(in *pb.UserChangesRequest, stream pb.User_UserChangesServer) error {
+	meta := grpc.GetMetaFromContext(stream.Context())
+	c.logger.Debugf("[%s] user changes: [%v %v]", meta, in.GetSince(), in.GetLimit())
+
+	dataStream, err := c.user.UserChanges(stream.Context(), in)
+	if err != nil {
+		err = apperr.Wrap(err, "receiver.UserChanges")
+		c.logger.Errorw("user changes: stream", append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+
+	for {
+		next, err := dataStream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			c.logger.Errorf("[%s] user changes: next chunk: %v", meta, err)
+			return status.Convert(err).Err()
 		}
 		if err = stream.Send(next); err != nil {
-			c.logger.Errorf("[%s] all users list: send chunk: %v", meta, err)
-			return status.Error(codes.Internal, err.Error())
+			err = apperr.Wrap(err, "receiver.UserChanges")
+			c.logger.Errorw("user changes: send chunk", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
 		}
 	}
 }
@@ -205,6 +387,147 @@ func (c *core) Data(ctx context.Context, in *pb.DataRequest) (*pb.DataResponse,
 	return c.user.Data(ctx, in)
 }
 
+func (c *core) UserAvatarUpload(stream pb.User_UserAvatarUploadServer) error {
+	meta := grpc.GetMetaFromContext(stream.Context())
+	c.logger.Debugf("[%s] avatar upload", meta)
+
+	upload, err := c.user.UserAvatarUpload(stream.Context())
+	if err != nil {
+		err = apperr.Wrap(err, "receiver.UserAvatarUpload")
+		c.logger.Errorw("avatar upload: stream", append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			err = apperr.Wrap(err, "receiver.UserAvatarUpload")
+			c.logger.Errorw("avatar upload: next chunk", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+		// on send error the real status is returned by CloseAndRecv
+		if err = upload.Send(in); err != nil {
+			break
+		}
+	}
+
+	resp, err := upload.CloseAndRecv()
+	if err != nil {
+		c.logger.Errorf("[%s] avatar upload: %v", meta, err)
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (c *core) UserAvatarGet(ctx context.Context, in *pb.UserAvatarGetRequest) (*pb.UserAvatarGetResponse, error) {
+	return c.user.UserAvatarGet(ctx, in)
+}
+
+func (c *core) UserCheckPassword(ctx context.Context, in *pb.UserCheckPasswordRequest) (*pb.UserCheckPasswordResponse, error) {
+	return c.user.UserCheckPassword(ctx, in)
+}
+
+// UserGetIfChanged is proxied as is, the deadline of the call is passed to the data service,
+// so it answers not_modified before the deadline.
+func (c *core) UserGetIfChanged(ctx context.Context, in *pb.UserGetIfChangedRequest) (*pb.UserGetIfChangedResponse, error) {
+	return c.user.UserGetIfChanged(ctx, in)
+}
+
+func (c *core) UserCount(ctx context.Context, in *pb.UserCountRequest) (*pb.UserCountResponse, error) {
+	return c.user.UserCount(ctx, in)
+}
+
+// Me methods are proxied synchronously with the session token, the data service resolves
+// the user of the token, so the receiver never addresses users by name for them.
+func (c *core) MeGet(ctx context.Context, in *pb.MeGetRequest) (*pb.MeGetResponse, error) {
+	return c.user.MeGet(ctx, in)
+}
+
+func (c *core) MeUpdate(ctx context.Context, in *pb.MeUpdateRequest) (*pb.MeUpdateResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	return c.user.MeUpdate(ctx, in)
+}
+
+func (c *core) MeDelete(ctx context.Context, in *pb.MeDeleteRequest) (*pb.MeDeleteResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	return c.user.MeDelete(ctx, in)
+}
+
+func (c *core) MeChangePassword(ctx context.Context, in *pb.MeChangePasswordRequest) (*pb.MeChangePasswordResponse, error) {
+	if err := c.rejected(); err != nil {
+		return nil, err
+	}
+	return c.user.MeChangePassword(ctx, in)
+}
+
+func (c *core) UserImport(stream pb.User_UserImportServer) error {
+	meta := grpc.GetMetaFromContext(stream.Context())
+	c.logger.Debugf("[%s] user import", meta)
+
+	dataStream, err := c.user.UserImport(stream.Context())
+	if err != nil {
+		err = apperr.Wrap(err, "receiver.UserImport")
+		c.logger.Errorw("user import: stream", append(apperr.Fields(err), "meta", meta)...)
+		return apperr.Status(codes.Internal, err)
+	}
+
+	go func() {
+		defer func() {
+			_ = dataStream.CloseSend()
+		}()
+		for {
+			in, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				c.logger.Errorf("[%s] user import: next row: %v", meta, err)
+				return
+			}
+			// on send error the real status is returned by Recv
+			if err = dataStream.Send(in); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		resp, err := dataStream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			c.logger.Errorf("[%s] user import: %v", meta, err)
+			return err
+		}
+		if err = stream.Send(resp); err != nil {
+			err = apperr.Wrap(err, "receiver.UserImport")
+			c.logger.Errorw("user import: send result", append(apperr.Fields(err), "meta", meta)...)
+			return apperr.Status(codes.Internal, err)
+		}
+	}
+}
+
+func (c *core) ServiceInfo(context.Context, *pb.ServiceInfoRequest) (*pb.ServiceInfoResponse, error) {
+	return c.methods.Info(), nil
+}
+
+// rejected returns Unavailable in maintenance mode. Mutations sent to the topic are not
+// answered by the data service synchronously, so they are rejected before they are sent.
+func (c *core) rejected() error {
+	if err := c.maintenance.Check(); err != nil {
+		return apperr.Status(codes.Unavailable, err)
+	}
+	return nil
+}
+
 func (c *core) sendMessageWithCtx(ctx context.Context, message *sarama.ProducerMessage) error {
 	if err := helper.InjectHeaders(ctx, message); err != nil {
 		return err
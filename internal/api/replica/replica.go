@@ -0,0 +1,163 @@
+package replica
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	replicatePkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/replicate"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+// Conflicts reports replicated writes rejected by the repository.
+type Conflicts interface {
+	// Conflicts returns the number of conflicts and the latest ones, the newest first.
+	Conflicts() (uint64, []replicatePkg.Conflict)
+}
+
+// New returns server applying mutations replicated from the active region.
+// Mutations are applied by the core, so the cache and the history of the region are kept up to date.
+// Conflicts are nil, if the replication is disabled.
+func New(user userPkg.Interface, data repoPkg.Interface, conflicts Conflicts, logger *zap.SugaredLogger) pb.ReplicaServer {
+	return &core{
+		user:      user,
+		data:      data,
+		conflicts: conflicts,
+		logger:    logger,
+	}
+}
+
+type core struct {
+	user      userPkg.Interface
+	data      repoPkg.Interface
+	conflicts Conflicts
+	logger    *zap.SugaredLogger
+	pb.UnimplementedReplicaServer
+}
+
+func (c *core) ReplicaApply(ctx context.Context, in *pb.ReplicaApplyRequest) (*pb.ReplicaApplyResponse, error) {
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	ctx = replicatePkg.Replicated(ctx)
+
+	var err error
+	switch mutation := in.GetMutation().(type) {
+	case *pb.ReplicaApplyRequest_Upsert:
+		c.logger.Debugln(meta, "replica upsert", mutation.Upsert.GetName())
+		// the stored user written later is kept
+		if _, err = c.user.Restore(ctx, adaptor.ToUserCoreState(mutation.Upsert), true); errors.Is(err, replicatePkg.ErrStale) {
+			err = nil
+		}
+	case *pb.ReplicaApplyRequest_Delete:
+		c.logger.Debugln(meta, "replica delete", mutation.Delete)
+		// the user is already deleted by the repeated mutation
+		if err = c.user.Delete(ctx, mutation.Delete); errors.Is(err, errorsPkg.ErrUserNotFound) {
+			err = nil
+		}
+	case *pb.ReplicaApplyRequest_Rename:
+		c.logger.Debugln(meta, "replica rename", mutation.Rename.GetOldName(), mutation.Rename.GetNewName())
+		err = c.rename(ctx, mutation.Rename.GetOldName(), mutation.Rename.GetNewName())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "mutation must be set")
+	}
+	if err != nil {
+		return nil, c.error(meta, "replica apply", err)
+	}
+	return &pb.ReplicaApplyResponse{}, nil
+}
+
+// rename is repeated safely: the user missing by the old name is renamed, if the new one exists.
+func (c *core) rename(ctx context.Context, oldName, newName string) error {
+	err := c.user.Rename(ctx, oldName, newName)
+	if !errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return err
+	}
+	if _, getErr := c.user.Get(ctx, newName); getErr != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *core) ReplicaCatchUp(stream pb.Replica_ReplicaCatchUpServer) error {
+	ctx := replicatePkg.Replicated(stream.Context())
+	meta := grpcPkg.GetMetaFromContext(ctx)
+	c.logger.Infoln(meta, "replica catch up")
+
+	var resp pb.ReplicaCatchUpResponse
+	received := make(map[string]struct{})
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.logger.Errorln(meta, "replica catch up, receive user", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+		user := adaptor.ToUserCoreState(in.GetUser())
+		if _, err = c.user.Restore(ctx, user, true); err != nil && !errors.Is(err, replicatePkg.ErrStale) {
+			return c.error(meta, "replica catch up", err)
+		}
+		received[user.Name] = struct{}{}
+		resp.Upserted++
+	}
+
+	// users are collected first, since deletion while the snapshot is read may block it
+	var missing []string
+	err := c.data.UserSnapshot(ctx, func(user models.User) error {
+		if _, ok := received[user.Name]; !ok {
+			missing = append(missing, user.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.error(meta, "replica catch up, snapshot", err)
+	}
+	for _, name := range missing {
+		if err = c.user.Delete(ctx, name); err != nil && !errors.Is(err, errorsPkg.ErrUserNotFound) {
+			return c.error(meta, "replica catch up, delete", err)
+		}
+		resp.Deleted++
+	}
+	c.logger.Infoln(meta, "replica caught up", resp.GetUpserted(), resp.GetDeleted())
+	return stream.SendAndClose(&resp)
+}
+
+func (c *core) ReplicaConflicts(context.Context, *pb.ReplicaConflictsRequest) (*pb.ReplicaConflictsResponse, error) {
+	if c.conflicts == nil {
+		return nil, status.Error(codes.FailedPrecondition, "replication is disabled")
+	}
+	total, conflicts := c.conflicts.Conflicts()
+	resp := &pb.ReplicaConflictsResponse{
+		Total:     total,
+		Conflicts: make([]*pb.ReplicaConflict, 0, len(conflicts)),
+	}
+	for _, conflict := range conflicts {
+		resp.Conflicts = append(resp.Conflicts, &pb.ReplicaConflict{
+			Name:        conflict.Name,
+			Id:          conflict.ID,
+			StoredHlc:   conflict.StoredHLC,
+			RejectedHlc: conflict.RejectedHLC,
+			DetectedAt:  conflict.DetectedAt.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+func (c *core) error(meta, op string, err error) error {
+	if errors.Is(err, errorsPkg.ErrValidation) {
+		return apperr.Status(codes.InvalidArgument, err)
+	}
+	c.logger.Errorw(op, append(apperr.Fields(err), "meta", meta)...)
+	return apperr.Status(codes.Internal, err)
+}
@@ -0,0 +1,164 @@
+package replica
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	replicatePkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/replicate"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+)
+
+var user = modeltest.Ivan()
+
+func TestReplicaApi_ReplicaApply(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name    string
+		in      *pb.ReplicaApplyRequest
+		prepare func(mockUser *userMockPkg.MockInterface)
+		expCode codes.Code
+	}{
+		{
+			name: "success, upsert",
+			in:   &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(user)}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Restore(gomock.Any(), user, true).Return(models.ImportOverwritten, nil).Times(1)
+			},
+		},
+		{
+			name: "success, stored user is written later",
+			in:   &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(user)}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Restore(gomock.Any(), user, true).
+					Return(models.ImportFailed, apperr.WrapKey(replicatePkg.ErrStale, "core.Restore", "name", user.Name)).Times(1)
+			},
+		},
+		{
+			name: "success, delete of deleted user",
+			in:   &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Delete{Delete: user.Name}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Delete(gomock.Any(), user.Name).Return(errorsPkg.ErrUserNotFound).Times(1)
+			},
+		},
+		{
+			name: "success, repeated rename",
+			in: &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Rename{
+				Rename: &pb.ReplicaRename{OldName: user.Name, NewName: "petr"},
+			}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Rename(gomock.Any(), user.Name, "petr").Return(errorsPkg.ErrUserNotFound).Times(1)
+				mockUser.EXPECT().Get(gomock.Any(), "petr").Return(user, nil).Times(1)
+			},
+		},
+		{
+			name: "failed, renamed user not found",
+			in: &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Rename{
+				Rename: &pb.ReplicaRename{OldName: user.Name, NewName: "petr"},
+			}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Rename(gomock.Any(), user.Name, "petr").Return(errorsPkg.ErrUserNotFound).Times(1)
+				mockUser.EXPECT().Get(gomock.Any(), "petr").Return(models.User{}, errorsPkg.ErrUserNotFound).Times(1)
+			},
+			expCode: codes.Internal,
+		},
+		{
+			name: "failed, invalid user",
+			in:   &pb.ReplicaApplyRequest{Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(user)}},
+			prepare: func(mockUser *userMockPkg.MockInterface) {
+				mockUser.EXPECT().Restore(gomock.Any(), user, true).Return(models.ImportFailed, errorsPkg.ErrValidation).Times(1)
+			},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "failed, no mutation",
+			in:      &pb.ReplicaApplyRequest{},
+			prepare: func(*userMockPkg.MockInterface) {},
+			expCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			c.prepare(mockUser)
+
+			server := New(mockUser, nil, nil, loggerPkg.NewFatal())
+			_, err := server.ReplicaApply(context.Background(), c.in)
+			assert.Equal(t, c.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestReplicaApi_ReplicaCatchUp(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockUser := userMockPkg.NewMockInterface(ctl)
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	stream := apiMockPkg.NewMockReplica_ReplicaCatchUpServer(ctl)
+
+	stream.EXPECT().Context().Return(context.Background()).AnyTimes()
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(&pb.ReplicaCatchUpRequest{User: adaptor.ToUserPbModel(user)}, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	mockUser.EXPECT().Restore(gomock.Any(), user, true).Return(models.ImportCreated, nil).Times(1)
+	mockRepo.EXPECT().UserSnapshot(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(models.User) error) error {
+			require.NoError(t, fn(user))
+			return fn(models.User{Name: "stale"})
+		}).Times(1)
+	mockUser.EXPECT().Delete(gomock.Any(), "stale").Return(nil).Times(1)
+	stream.EXPECT().SendAndClose(&pb.ReplicaCatchUpResponse{Upserted: 1, Deleted: 1}).Return(nil).Times(1)
+
+	server := New(mockUser, mockRepo, nil, loggerPkg.NewFatal())
+	assert.NoError(t, server.ReplicaCatchUp(stream))
+}
+
+type conflicts []replicatePkg.Conflict
+
+func (c conflicts) Conflicts() (uint64, []replicatePkg.Conflict) {
+	return uint64(len(c)) + 1, c
+}
+
+func TestReplicaApi_ReplicaConflicts(t *testing.T) {
+	ctx := context.Background()
+	detected := time.Unix(1660000000, 0)
+
+	_, err := New(nil, nil, nil, loggerPkg.NewFatal()).ReplicaConflicts(ctx, &pb.ReplicaConflictsRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	server := New(nil, nil, conflicts{
+		{Name: user.Name, ID: user.ID, StoredHLC: 2, RejectedHLC: 1, DetectedAt: detected},
+	}, loggerPkg.NewFatal())
+	resp, err := server.ReplicaConflicts(ctx, &pb.ReplicaConflictsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.GetTotal())
+	require.Len(t, resp.GetConflicts(), 1)
+	assert.Equal(t, &pb.ReplicaConflict{
+		Name:        user.Name,
+		Id:          user.ID,
+		StoredHlc:   2,
+		RejectedHlc: 1,
+		DetectedAt:  detected.Unix(),
+	}, resp.GetConflicts()[0])
+}
@@ -0,0 +1,224 @@
+package apperr
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+const domain = "homework"
+
+// ReasonPasswordExpired is ErrorInfo reason of the login rejected until the password is changed.
+const ReasonPasswordExpired = "PASSWORD_EXPIRED"
+
+// ReasonMaintenance is ErrorInfo reason of the mutation rejected in maintenance mode.
+const ReasonMaintenance = "MAINTENANCE"
+
+// ReasonMethodDisabled is ErrorInfo reason of the call of the method disabled by config.
+const ReasonMethodDisabled = "METHOD_DISABLED"
+
+// ReasonPolicyDenied is ErrorInfo reason of the call denied by the authorization policies.
+const ReasonPolicyDenied = "POLICY_DENIED"
+
+// ReasonRateLimited is ErrorInfo reason of the call rejected by the rate limit of the client.
+const ReasonRateLimited = "RATE_LIMITED"
+
+// Error keeps context of the failed operation, e.g. "op=repo.UserGet name=alice: user not found".
+type Error struct {
+	Op     string
+	Entity string
+	Key    string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("op=" + e.Op)
+	if e.Entity != "" {
+		b.WriteString(" " + e.Entity + "=" + e.Key)
+	}
+	b.WriteString(": " + e.Err.Error())
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns err with the operation context, nil is returned if err is nil.
+func Wrap(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Err: err}
+}
+
+// WrapKey returns err with the operation context and key of the entity, e.g. name of the user.
+func WrapKey(err error, op, entity, key string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Entity: entity, Key: key, Err: err}
+}
+
+// retryError is the rejected request, which may succeed after the delay, e.g. a throttled one.
+type retryError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryError) Unwrap() error {
+	return e.err
+}
+
+// WithRetryAfter returns err with the delay, after which the request may succeed.
+// Status passes it in RetryInfo details, so clients back off precisely.
+// Non-positive delay is ignored, nil is returned if err is nil.
+func WithRetryAfter(err error, delay time.Duration) error {
+	if err == nil || delay <= 0 {
+		return err
+	}
+	return &retryError{err: err, delay: delay}
+}
+
+// RetryAfter returns the delay of the error, ok is false if it has none.
+func RetryAfter(err error) (delay time.Duration, ok bool) {
+	var e *retryError
+	if !errors.As(err, &e) {
+		return 0, false
+	}
+	return e.delay, true
+}
+
+// Ops returns operations of all wrapping layers, outermost first.
+func Ops(err error) []string {
+	var ops []string
+	for _, e := range list(err) {
+		ops = append(ops, e.Op)
+	}
+	return ops
+}
+
+// Message returns error text without the operation context.
+func Message(err error) string {
+	var e *Error
+	for errors.As(err, &e) {
+		err = e.Err
+	}
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// Fields returns context of the error as key-value pairs for structured logs.
+func Fields(err error) []interface{} {
+	fields := make([]interface{}, 0, 4)
+	if ops := Ops(err); len(ops) != 0 {
+		fields = append(fields, "op", strings.Join(ops, " > "))
+	}
+	entities := keys(err)
+	names := make([]string, 0, len(entities))
+	for entity := range entities {
+		names = append(names, entity)
+	}
+	sort.Strings(names)
+	for _, entity := range names {
+		fields = append(fields, entity, entities[entity])
+	}
+	return append(fields, "error", Message(err))
+}
+
+// Status returns gRPC status error. Operation context is passed in ErrorInfo details,
+// delay of WithRetryAfter is passed in RetryInfo details. Maintenance errors are Unavailable with reason MAINTENANCE, whatever the code is.
+func Status(code codes.Code, err error) error {
+	if errors.Is(err, errorsPkg.ErrMaintenance) {
+		return StatusReason(codes.Unavailable, ReasonMaintenance, err)
+	}
+	if len(Ops(err)) == 0 {
+		return retryInfo(status.New(code, Message(err)), err).Err()
+	}
+	return StatusReason(code, reason(code), err)
+}
+
+// StatusReason returns gRPC status error with ErrorInfo reason, which lets clients
+// tell the error from others with the same code, e.g. PASSWORD_EXPIRED.
+func StatusReason(code codes.Code, reason string, err error) error {
+	st := status.New(code, Message(err))
+	metadata := keys(err)
+	if ops := Ops(err); len(ops) != 0 {
+		metadata["op"] = strings.Join(ops, " > ")
+	}
+	detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return retryInfo(detailed, err).Err()
+}
+
+// retryInfo adds RetryInfo details to the status, if the error has the delay.
+func retryInfo(st *status.Status, err error) *status.Status {
+	delay, ok := RetryAfter(err)
+	if !ok {
+		return st
+	}
+	detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if detailsErr != nil {
+		return st
+	}
+	return detailed
+}
+
+// list returns apperr layers of the error, outermost first.
+func list(err error) []*Error {
+	var layers []*Error
+	var e *Error
+	for errors.As(err, &e) {
+		layers = append(layers, e)
+		err = e.Err
+	}
+	return layers
+}
+
+// keys returns entity keys, key of the outer layer wins.
+func keys(err error) map[string]string {
+	result := make(map[string]string)
+	for _, e := range list(err) {
+		if e.Entity == "" {
+			continue
+		}
+		if _, ok := result[e.Entity]; !ok {
+			result[e.Entity] = e.Key
+		}
+	}
+	return result
+}
+
+// reason converts code name to UPPER_SNAKE_CASE, e.g. NotFound to NOT_FOUND.
+func reason(code codes.Code) string {
+	var b strings.Builder
+	for i, r := range code.String() {
+		if i != 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package apperr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func TestWrap(t *testing.T) {
+	err := WrapKey(WrapKey(errorsPkg.ErrUserNotFound, "repo.UserGet", "name", "alice"), "core.UserGet", "name", "Alice")
+
+	assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+	assert.EqualError(t, err, "op=core.UserGet name=Alice: op=repo.UserGet name=alice: user not found")
+	assert.Equal(t, []string{"core.UserGet", "repo.UserGet"}, Ops(err))
+	assert.Equal(t, "user not found", Message(err))
+	assert.Equal(t, []interface{}{"op", "core.UserGet > repo.UserGet", "name", "Alice", "error", "user not found"}, Fields(err))
+
+	assert.NoError(t, Wrap(nil, "repo.UserList"))
+	assert.NoError(t, WrapKey(nil, "repo.UserGet", "name", "alice"))
+}
+
+func TestStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		expMessage string
+		expDetails map[string]string
+	}{
+		{
+			name:       "with context",
+			err:        WrapKey(Wrap(errorsPkg.ErrTimeout, "repo.UserGet"), "core.UserGet", "name", "alice"),
+			expMessage: errorsPkg.ErrTimeout.Error(),
+			expDetails: map[string]string{"op": "core.UserGet > repo.UserGet", "name": "alice"},
+		},
+		{
+			name:       "without context",
+			err:        errors.New("connection refused"),
+			expMessage: "connection refused",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st, ok := status.FromError(Status(codes.Unavailable, c.err))
+			require.True(t, ok)
+			assert.Equal(t, codes.Unavailable, st.Code())
+			assert.Equal(t, c.expMessage, st.Message())
+
+			if c.expDetails == nil {
+				assert.Empty(t, st.Details())
+				return
+			}
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, "UNAVAILABLE", info.GetReason())
+			assert.Equal(t, c.expDetails, info.GetMetadata())
+		})
+	}
+}
+
+func TestStatus_Maintenance(t *testing.T) {
+	err := WrapKey(errors.Wrap(errorsPkg.ErrMaintenance, "reason [upgrade]"), "core.Create", "name", "alice")
+	st, ok := status.FromError(Status(codes.Internal, err))
+	require.True(t, ok)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, "reason [upgrade]: service is in maintenance", st.Message())
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, ReasonMaintenance, info.GetReason())
+}
+
+func TestStatusReason(t *testing.T) {
+	err := WrapKey(errorsPkg.ErrPasswordExpired, "core.UserCheckPassword", "name", "alice")
+	st, ok := status.FromError(StatusReason(codes.FailedPrecondition, ReasonPasswordExpired, err))
+	require.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Equal(t, errorsPkg.ErrPasswordExpired.Error(), st.Message())
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, ReasonPasswordExpired, info.GetReason())
+	assert.Equal(t, map[string]string{"op": "core.UserCheckPassword", "name": "alice"}, info.GetMetadata())
+}
+
+func TestStatus_RetryAfter(t *testing.T) {
+	err := WrapKey(WithRetryAfter(errorsPkg.ErrTooManyAttempts, time.Minute), "core.UserCheckPassword", "name", "alice")
+	delay, ok := RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, delay)
+	assert.Equal(t, errorsPkg.ErrTooManyAttempts.Error(), Message(err))
+
+	st, ok := status.FromError(Status(codes.ResourceExhausted, err))
+	require.True(t, ok)
+	require.Len(t, st.Details(), 2)
+	info, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, info.GetRetryDelay().AsDuration())
+
+	assert.Equal(t, errorsPkg.ErrTooManyAttempts, WithRetryAfter(errorsPkg.ErrTooManyAttempts, 0))
+}
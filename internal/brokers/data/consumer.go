@@ -1,29 +1,40 @@
 package data
 
 import (
+	"time"
+
 	"github.com/Shopify/sarama"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	metricsPkg "gitlab.ozon.dev/iTukaev/homework/internal/brokers/metrics"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/helper"
 )
 
-func NewHandler(user userPkg.Interface, logger *zap.SugaredLogger, producer sarama.SyncProducer) *Handler {
+func NewHandler(
+	user userPkg.Interface,
+	logger *zap.SugaredLogger,
+	producer sarama.SyncProducer,
+	metrics *metricsPkg.Consumer,
+) *Handler {
 	return &Handler{
-		logger: logger,
-		sender: newSender(user, logger, producer),
+		logger:  logger,
+		sender:  newSender(user, logger, producer),
+		metrics: metrics,
 	}
 }
 
 type Handler struct {
-	logger *zap.SugaredLogger
-	sender sender
+	logger  *zap.SugaredLogger
+	sender  sender
+	metrics *metricsPkg.Consumer
 }
 
-func (h *Handler) Setup(sarama.ConsumerGroupSession) error {
+func (h *Handler) Setup(session sarama.ConsumerGroupSession) error {
+	h.metrics.Rebalanced(session.Claims())
 	return nil
 }
 
@@ -33,7 +44,10 @@ func (h *Handler) Cleanup(sarama.ConsumerGroupSession) error {
 
 func (h *Handler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
-		return h.handleMessage(session, msg)
+		start := time.Now()
+		err := h.handleMessage(session, msg)
+		h.metrics.Observe(msg, claim.HighWaterMarkOffset(), time.Since(start), err)
+		return err
 	}
 	return nil
 }
@@ -41,6 +55,7 @@ func (h *Handler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama
 func (h *Handler) handleMessage(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) error {
 	uid, pub := helper.ExtractUidPubFromMessage(msg)
 	ctx := helper.InjectUidPubToCtx(session.Context(), uid, pub)
+	ctx = helper.InjectIdentityFromMessage(ctx, msg)
 
 	switch string(msg.Key) {
 	case consts.UserCreate:
@@ -58,11 +73,26 @@ func (h *Handler) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 			return errors.Wrap(err, "user delete")
 		}
 		session.MarkMessage(msg, "")
+	case consts.UserRename:
+		if err := h.sender.userRename(ctx, msg); err != nil {
+			return errors.Wrap(err, "user rename")
+		}
+		session.MarkMessage(msg, "")
+	case consts.UserDisable, consts.UserEnable:
+		if err := h.sender.userStatus(ctx, msg); err != nil {
+			return errors.Wrapf(err, "user %s", msg.Key)
+		}
+		session.MarkMessage(msg, "")
 	case consts.UserGet:
 		if err := h.sender.userGet(ctx, msg); err != nil {
 			return errors.Wrap(err, "user get")
 		}
 		session.MarkMessage(msg, "")
+	case consts.UserGetByID:
+		if err := h.sender.userGetByID(ctx, msg); err != nil {
+			return errors.Wrap(err, "user get by id")
+		}
+		session.MarkMessage(msg, "")
 	case consts.UserList:
 		if err := h.sender.userList(ctx, msg); err != nil {
 			return errors.Wrap(err, "user list")
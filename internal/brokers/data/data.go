@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
@@ -24,7 +25,10 @@ type sender interface {
 	userCreate(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userUpdate(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userDelete(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userRename(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userStatus(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userGet(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userGetByID(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userList(ctx context.Context, msg *sarama.ConsumerMessage) error
 }
 
@@ -60,9 +64,9 @@ func (c *core) userCreate(ctx context.Context, msg *sarama.ConsumerMessage) erro
 	}
 
 	if err := c.user.Create(ctx, user); err != nil {
-		if errors.Is(err, errorsPkg.ErrUserAlreadyExists) {
-			c.logger.Errorf("user create: %v", err)
-			return c.sendErrorWithCtx(ctx, message, err.Error())
+		if errors.Is(err, errorsPkg.ErrUserAlreadyExists) || errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user create", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
 		}
 		return err
 	}
@@ -75,22 +79,22 @@ func (c *core) userUpdate(ctx context.Context, msg *sarama.ConsumerMessage) erro
 	ctx = opentracing.ContextWithSpan(ctx, span)
 	defer span.Finish()
 
-	var user models.User
-	if err := json.Unmarshal(msg.Value, &user); err != nil {
+	var update models.UserUpdate
+	if err := json.Unmarshal(msg.Value, &update); err != nil {
 		return errors.Wrap(err, "message unmarshal")
 	}
 
-	c.logger.Debugf("user [%s]", user.String())
+	c.logger.Debugf("user [%s], mask %v", update.String(), update.Mask)
 
 	message := &sarama.ProducerMessage{
 		Topic: consts.TopicMailing,
 		Key:   sarama.StringEncoder(consts.UserUpdate),
 	}
 
-	if err := c.user.Update(ctx, user); err != nil {
-		if errors.Is(err, errorsPkg.ErrUserNotFound) {
-			c.logger.Errorf("user update: %v", err)
-			return c.sendErrorWithCtx(ctx, message, err.Error())
+	if err := c.user.Update(ctx, update); err != nil {
+		if errors.Is(err, errorsPkg.ErrUserNotFound) || errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user update", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
 		}
 		return err
 	}
@@ -113,9 +117,73 @@ func (c *core) userDelete(ctx context.Context, msg *sarama.ConsumerMessage) erro
 	}
 
 	if err := c.user.Delete(ctx, name); err != nil {
-		if errors.Is(err, errorsPkg.ErrUserNotFound) {
-			c.logger.Errorf("user delete: %v", err)
-			return c.sendErrorWithCtx(ctx, message, err.Error())
+		// the pending delete is reported with the operation ID, it is not consumed again
+		if errors.Is(err, errorsPkg.ErrUserNotFound) || errors.Is(err, errorsPkg.ErrValidation) ||
+			errors.Is(err, errorsPkg.ErrApprovalPending) {
+			c.logger.Errorw("user delete", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
+		}
+		return err
+	}
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+// userStatus disables or enables the user by the message key.
+func (c *core) userStatus(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, brokerDataService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
+
+	name, key := string(msg.Value), string(msg.Key)
+
+	c.logger.Debugf("name: [%s], status change: [%s]", name, key)
+
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicMailing,
+		Key:   sarama.StringEncoder(key),
+	}
+
+	change := c.user.Disable
+	if key == consts.UserEnable {
+		change = c.user.Enable
+	}
+	if err := change(ctx, name); err != nil {
+		if errors.Is(err, errorsPkg.ErrUserNotFound) ||
+			errors.Is(err, errorsPkg.ErrStatusTransition) ||
+			errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user "+key, apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
+		}
+		return err
+	}
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+func (c *core) userRename(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, brokerDataService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
+
+	var rename models.UserRename
+	if err := json.Unmarshal(msg.Value, &rename); err != nil {
+		return errors.Wrap(err, "message unmarshal")
+	}
+
+	c.logger.Debugf("name: [%s], new name: [%s]", rename.Name, rename.NewName)
+
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicMailing,
+		Key:   sarama.StringEncoder(consts.UserRename),
+	}
+
+	if err := c.user.Rename(ctx, rename.Name, rename.NewName); err != nil {
+		if errors.Is(err, errorsPkg.ErrUserNotFound) ||
+			errors.Is(err, errorsPkg.ErrUserAlreadyExists) ||
+			errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user rename", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
 		}
 		return err
 	}
@@ -139,13 +207,47 @@ func (c *core) userGet(ctx context.Context, msg *sarama.ConsumerMessage) error {
 
 	user, err := c.user.Get(ctx, name)
 	if err != nil {
-		if errors.Is(err, errorsPkg.ErrUserNotFound) {
-			c.logger.Errorf("user get: %v", err)
-			return c.sendErrorWithCtx(ctx, message, err.Error())
+		if errors.Is(err, errorsPkg.ErrUserNotFound) || errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user get", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
 		}
 		return err
 	}
 
+	user.Password = ""
+	data, err := json.Marshal(user)
+	if err != nil {
+		return errors.Wrap(err, "marshal user")
+	}
+	message.Value = sarama.ByteEncoder(data)
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+func (c *core) userGetByID(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, brokerDataService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
+
+	id := string(msg.Value)
+
+	c.logger.Debugf("id: [%s]", id)
+
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicMailing,
+		Key:   sarama.StringEncoder(consts.UserGetByID),
+	}
+
+	user, err := c.user.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, errorsPkg.ErrUserNotFound) || errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user get by id", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, message, apperr.Message(err))
+		}
+		return err
+	}
+
+	user.Password = ""
 	data, err := json.Marshal(user)
 	if err != nil {
 		return errors.Wrap(err, "marshal user")
@@ -165,13 +267,23 @@ func (c *core) userList(ctx context.Context, msg *sarama.ConsumerMessage) error
 		return errors.Wrap(err, "unmarshal list parameters")
 	}
 
-	c.logger.Debugf("parameters: [%d %d %v]", params.Limit, params.Offset, params.Order)
+	c.logger.Debugf("parameters: [%d %d %v %s]", params.Limit, params.Offset, params.Order, params.Status)
 
-	list, err := c.user.List(ctx, params.Order, params.Limit, params.Offset)
+	list, err := c.user.List(ctx, params.Order, params.Limit, params.Offset, params.Attributes, params.Status)
 	if err != nil {
+		if errors.Is(err, errorsPkg.ErrValidation) {
+			c.logger.Errorw("user list", apperr.Fields(err)...)
+			return c.sendErrorWithCtx(ctx, &sarama.ProducerMessage{
+				Topic: consts.TopicMailing,
+				Key:   sarama.StringEncoder(consts.UserList),
+			}, apperr.Message(err))
+		}
 		return err
 	}
 
+	for i := range list {
+		list[i].Password = ""
+	}
 	data, err := json.Marshal(list)
 	if err != nil {
 		return errors.Wrap(err, "marshal user")
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+)
+
+// Config of the consumer metrics.
+type Config struct {
+	// MaxLag fails readiness, if lag of any claimed partition exceeds it, 0 disables the check.
+	MaxLag int64 `mapstructure:"max_lag"`
+}
+
+// Partition is a state of the claimed partition after the last handled message.
+type Partition struct {
+	Topic         string `json:"topic"`
+	Partition     int32  `json:"partition"`
+	Offset        int64  `json:"offset"`
+	HighWaterMark int64  `json:"high_water_mark"`
+	// Lag is a count of messages produced after the handled one.
+	Lag       int64  `json:"lag"`
+	Processed uint64 `json:"processed"`
+	Failed    uint64 `json:"failed"`
+}
+
+type key struct {
+	topic     string
+	partition int32
+}
+
+// Consumer collects per-partition lag of the consumer group. Totals, latency and rebalances
+// are counted by the counter package, so they are published like other counters.
+type Consumer struct {
+	group string
+	cfg   Config
+
+	mu         sync.Mutex
+	partitions map[key]*Partition
+}
+
+func New(group string, cfg Config) *Consumer {
+	return &Consumer{
+		group:      group,
+		cfg:        cfg,
+		partitions: make(map[key]*Partition),
+	}
+}
+
+// Rebalanced is called by the session setup. Partitions, which are not claimed anymore,
+// are forgotten, so lag of another consumer does not fail readiness.
+func (c *Consumer) Rebalanced(claims map[string][]int32) {
+	counter.ConsumerRebalances.Inc()
+
+	claimed := make(map[key]struct{})
+	for topic, partitions := range claims {
+		for _, partition := range partitions {
+			claimed[key{topic: topic, partition: partition}] = struct{}{}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.partitions {
+		if _, ok := claimed[k]; !ok {
+			delete(c.partitions, k)
+		}
+	}
+}
+
+// Observe records the handled message. highWaterMark is the offset of the next message
+// produced to the partition.
+func (c *Consumer) Observe(msg *sarama.ConsumerMessage, highWaterMark int64, elapsed time.Duration, err error) {
+	counter.ConsumerProcessed.Inc()
+	counter.ConsumerLatency.ObserveExemplar(elapsed, traceID(msg))
+	if err != nil {
+		counter.ConsumerFailed.Inc()
+	}
+
+	lag := highWaterMark - msg.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := key{topic: msg.Topic, partition: msg.Partition}
+	p, ok := c.partitions[k]
+	if !ok {
+		p = &Partition{Topic: msg.Topic, Partition: msg.Partition}
+		c.partitions[k] = p
+	}
+	p.Offset, p.HighWaterMark, p.Lag = msg.Offset, highWaterMark, lag
+	p.Processed++
+	if err != nil {
+		p.Failed++
+	}
+}
+
+// Partitions returns claimed partitions ordered by topic and partition.
+func (c *Consumer) Partitions() []Partition {
+	c.mu.Lock()
+	partitions := make([]Partition, 0, len(c.partitions))
+	for _, p := range c.partitions {
+		partitions = append(partitions, *p)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Topic != partitions[j].Topic {
+			return partitions[i].Topic < partitions[j].Topic
+		}
+		return partitions[i].Partition < partitions[j].Partition
+	})
+	return partitions
+}
+
+// Ready returns error, if lag of any claimed partition exceeds the configured maximum.
+func (c *Consumer) Ready() error {
+	if c.cfg.MaxLag <= 0 {
+		return nil
+	}
+	for _, p := range c.Partitions() {
+		if p.Lag > c.cfg.MaxLag {
+			return fmt.Errorf("group [%s]: partition [%s/%d] lag %d exceeds %d",
+				c.group, p.Topic, p.Partition, p.Lag, c.cfg.MaxLag)
+		}
+	}
+	return nil
+}
+
+// traceID returns ID of the sampled trace the message is produced by.
+func traceID(msg *sarama.ConsumerMessage) string {
+	for _, header := range msg.Headers {
+		if string(header.Key) == jaegerPkg.TraceContextHeader {
+			return jaegerPkg.SampledTraceIDFromHeader(string(header.Value))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+)
+
+func TestConsumer_Ready(t *testing.T) {
+	cases := []struct {
+		name          string
+		maxLag        int64
+		offset        int64
+		highWaterMark int64
+		claims        map[string][]int32
+		expLag        int64
+		expErr        bool
+	}{
+		{
+			name:          "success, lag below maximum",
+			maxLag:        10,
+			offset:        90,
+			highWaterMark: 100,
+			claims:        map[string][]int32{"data": {0}},
+			expLag:        9,
+		},
+		{
+			name:          "success, check disabled",
+			offset:        0,
+			highWaterMark: 100,
+			claims:        map[string][]int32{"data": {0}},
+			expLag:        99,
+		},
+		{
+			name:          "success, partition is not claimed anymore",
+			maxLag:        10,
+			offset:        0,
+			highWaterMark: 100,
+			claims:        map[string][]int32{"data": {1}},
+		},
+		{
+			name:          "failed, lag exceeds maximum",
+			maxLag:        10,
+			offset:        80,
+			highWaterMark: 100,
+			claims:        map[string][]int32{"data": {0}},
+			expLag:        19,
+			expErr:        true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			consumer := New("group", Config{MaxLag: c.maxLag})
+			consumer.Observe(&sarama.ConsumerMessage{Topic: "data", Offset: c.offset - 1}, c.offset, time.Millisecond, nil)
+			consumer.Observe(&sarama.ConsumerMessage{Topic: "data", Offset: c.offset}, c.highWaterMark, time.Millisecond, errors.New("failed"))
+			consumer.Rebalanced(c.claims)
+
+			partitions := consumer.Partitions()
+			if c.expLag != 0 {
+				assert.Equal(t, []Partition{{
+					Topic:         "data",
+					Offset:        c.offset,
+					HighWaterMark: c.highWaterMark,
+					Lag:           c.expLag,
+					Processed:     2,
+					Failed:        1,
+				}}, partitions)
+			} else {
+				assert.Empty(t, partitions)
+			}
+
+			err := consumer.Ready()
+			if c.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	cases := []struct {
+		name       string
+		header     string
+		expTraceID string
+	}{
+		{
+			name:       "sampled",
+			header:     "4bf92f3577b34da6:a3ce929d0e0e4736:0:1",
+			expTraceID: "4bf92f3577b34da6",
+		},
+		{
+			name:   "not sampled",
+			header: "4bf92f3577b34da6:a3ce929d0e0e4736:0:0",
+		},
+		{
+			name:   "malformed",
+			header: "trace",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			msg := &sarama.ConsumerMessage{Headers: []*sarama.RecordHeader{
+				{Key: []byte("uid"), Value: []byte("1")},
+				{Key: []byte(jaegerPkg.TraceContextHeader), Value: []byte(c.header)},
+			}}
+			assert.Equal(t, c.expTraceID, traceID(msg))
+		})
+	}
+}
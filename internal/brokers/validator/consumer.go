@@ -7,13 +7,20 @@ import (
 
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/helper"
 )
 
-func NewHandler(logger *zap.SugaredLogger, producer sarama.SyncProducer) *Handler {
+func NewHandler(
+	logger *zap.SugaredLogger,
+	producer sarama.SyncProducer,
+	normalizer normalizePkg.Interface,
+	denylist denylistPkg.Interface,
+) *Handler {
 	return &Handler{
 		logger: logger,
-		sender: newSender(logger, producer),
+		sender: newSender(logger, producer, normalizer, denylist),
 	}
 }
 
@@ -40,6 +47,7 @@ func (h *Handler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama
 func (h *Handler) handleMessage(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) error {
 	uid, pub := helper.ExtractUidPubFromMessage(msg)
 	ctx := helper.InjectUidPubToCtx(session.Context(), uid, pub)
+	ctx = helper.InjectIdentityFromMessage(ctx, msg)
 
 	switch string(msg.Key) {
 	case consts.UserCreate:
@@ -57,11 +65,26 @@ func (h *Handler) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 			return errors.Wrap(err, "user delete")
 		}
 		session.MarkMessage(msg, "")
+	case consts.UserRename:
+		if err := h.sender.userRename(ctx, msg); err != nil {
+			return errors.Wrap(err, "user rename")
+		}
+		session.MarkMessage(msg, "")
+	case consts.UserDisable, consts.UserEnable:
+		if err := h.sender.userStatus(ctx, msg); err != nil {
+			return errors.Wrapf(err, "user %s", msg.Key)
+		}
+		session.MarkMessage(msg, "")
 	case consts.UserGet:
 		if err := h.sender.userGet(ctx, msg); err != nil {
 			return errors.Wrap(err, "user get")
 		}
 		session.MarkMessage(msg, "")
+	case consts.UserGetByID:
+		if err := h.sender.userGetByID(ctx, msg); err != nil {
+			return errors.Wrap(err, "user get by id")
+		}
+		session.MarkMessage(msg, "")
 	default:
 		session.MarkMessage(msg, "invalid_key")
 		return errors.Wrap(errorsPkg.ErrValidation, "invalid message key")
@@ -3,9 +3,9 @@ package validator
 import (
 	"context"
 	"encoding/json"
-	"regexp"
 
 	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -13,6 +13,8 @@ import (
 	"gitlab.ozon.dev/iTukaev/homework/internal/consts"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/helper"
 )
 
@@ -20,27 +22,35 @@ const (
 	validateService = "validate"
 )
 
-var (
-	email = regexp.MustCompile(`^.+@[A-Za-z0-9\-_\.]+$`)
-)
-
 type sender interface {
 	userCreate(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userUpdate(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userDelete(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userRename(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userStatus(ctx context.Context, msg *sarama.ConsumerMessage) error
 	userGet(ctx context.Context, msg *sarama.ConsumerMessage) error
+	userGetByID(ctx context.Context, msg *sarama.ConsumerMessage) error
 }
 
-func newSender(logger *zap.SugaredLogger, producer sarama.SyncProducer) sender {
+func newSender(
+	logger *zap.SugaredLogger,
+	producer sarama.SyncProducer,
+	normalizer normalizePkg.Interface,
+	denylist denylistPkg.Interface,
+) sender {
 	return &core{
-		producer: producer,
-		logger:   logger,
+		producer:   producer,
+		logger:     logger,
+		normalizer: normalizer,
+		denylist:   denylist,
 	}
 }
 
 type core struct {
-	producer sarama.SyncProducer
-	logger   *zap.SugaredLogger
+	producer   sarama.SyncProducer
+	logger     *zap.SugaredLogger
+	normalizer normalizePkg.Interface
+	denylist   denylistPkg.Interface
 }
 
 func (c *core) userCreate(ctx context.Context, msg *sarama.ConsumerMessage) error {
@@ -60,9 +70,18 @@ func (c *core) userCreate(ctx context.Context, msg *sarama.ConsumerMessage) erro
 		Key:   sarama.StringEncoder(consts.UserCreate),
 		Value: sarama.ByteEncoder(msg.Value),
 	}
+	if err := c.normalizeName(&user.Name, message, user); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
 	if err := createValidator(user); err != nil {
 		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
 	}
+	if err := c.denylist.Check(ctx, user.Name); err != nil {
+		if errors.Is(err, errorsPkg.ErrNameReserved) {
+			return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+		}
+		return err
+	}
 
 	return c.sendMessageWithCtx(ctx, message)
 }
@@ -72,21 +91,30 @@ func (c *core) userUpdate(ctx context.Context, msg *sarama.ConsumerMessage) erro
 	ctx = opentracing.ContextWithSpan(ctx, span)
 	defer span.Finish()
 
-	user := models.NewUser()
-	if err := json.Unmarshal(msg.Value, &user); err != nil {
+	update := &models.UserUpdate{}
+	if err := json.Unmarshal(msg.Value, update); err != nil {
 		return errors.Wrap(err, "message unmarshal")
 	}
 
-	c.logger.Debugf("user [%s]", user.String())
+	c.logger.Debugf("user [%s], mask %v", update.String(), update.Mask)
 
 	message := &sarama.ProducerMessage{
 		Topic: consts.TopicData,
 		Key:   sarama.StringEncoder(consts.UserUpdate),
 		Value: sarama.ByteEncoder(msg.Value),
 	}
-	if err := updateValidator(user); err != nil {
+	if err := c.normalizeName(&update.Name, message, update); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	if err := updateValidator(update); err != nil {
 		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
 	}
+	if err := c.denylist.Check(ctx, update.Name); err != nil {
+		if errors.Is(err, errorsPkg.ErrNameReserved) {
+			return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+		}
+		return err
+	}
 
 	return c.sendMessageWithCtx(ctx, message)
 }
@@ -96,39 +124,144 @@ func (c *core) userDelete(ctx context.Context, msg *sarama.ConsumerMessage) erro
 	ctx = opentracing.ContextWithSpan(ctx, span)
 	defer span.Finish()
 
-	name := string(msg.Value)
-
 	message := &sarama.ProducerMessage{
 		Topic: consts.TopicData,
 		Key:   sarama.StringEncoder(consts.UserDelete),
-		Value: sarama.ByteEncoder(msg.Value),
 	}
-	if err := deleteValidator(name); err != nil {
+	name, err := c.normalizer.Name(string(msg.Value))
+	if err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	message.Value = sarama.StringEncoder(name)
+	if err = deleteValidator(name); err != nil {
 		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
 	}
 
 	return c.sendMessageWithCtx(ctx, message)
 }
 
-func (c *core) userGet(ctx context.Context, msg *sarama.ConsumerMessage) error {
+func (c *core) userRename(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, validateService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
+
+	rename := models.NewUserRename()
+	if err := json.Unmarshal(msg.Value, rename); err != nil {
+		return errors.Wrap(err, "message unmarshal")
+	}
+
+	c.logger.Debugf("user [%s], new name [%s]", rename.Name, rename.NewName)
+
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicData,
+		Key:   sarama.StringEncoder(consts.UserRename),
+	}
+	var err error
+	if rename.Name, err = c.normalizer.Name(rename.Name); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	if rename.NewName, err = c.normalizer.Name(rename.NewName); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	if err = renameValidator(rename); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	if err = c.denylist.Check(ctx, rename.NewName); err != nil {
+		if errors.Is(err, errorsPkg.ErrNameReserved) {
+			return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+		}
+		return err
+	}
+
+	data, err := json.Marshal(rename)
+	if err != nil {
+		return errors.Wrap(err, "marshal normalized rename")
+	}
+	message.Value = sarama.ByteEncoder(data)
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+// userStatus validates the name of disable and enable messages.
+func (c *core) userStatus(ctx context.Context, msg *sarama.ConsumerMessage) error {
 	span := helper.GetSpanFromMessage(msg, validateService)
 	ctx = opentracing.ContextWithSpan(ctx, span)
 	defer span.Finish()
 
-	name := string(msg.Value)
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicData,
+		Key:   sarama.ByteEncoder(msg.Key),
+	}
+	name, err := c.normalizer.Name(string(msg.Value))
+	if err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	message.Value = sarama.StringEncoder(name)
+	if err = deleteValidator(name); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+func (c *core) userGet(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, validateService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
 
 	message := &sarama.ProducerMessage{
 		Topic: consts.TopicData,
 		Key:   sarama.StringEncoder(consts.UserGet),
+	}
+	name, err := c.normalizer.Name(string(msg.Value))
+	if err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+	message.Value = sarama.StringEncoder(name)
+	if err = getValidator(name); err != nil {
+		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
+	}
+
+	return c.sendMessageWithCtx(ctx, message)
+}
+
+func (c *core) userGetByID(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	span := helper.GetSpanFromMessage(msg, validateService)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	defer span.Finish()
+
+	message := &sarama.ProducerMessage{
+		Topic: consts.TopicData,
+		Key:   sarama.StringEncoder(consts.UserGetByID),
 		Value: sarama.ByteEncoder(msg.Value),
 	}
-	if err := getValidator(name); err != nil {
+	if err := getByIDValidator(string(msg.Value)); err != nil {
 		return c.sendValidationErrorWithCtx(ctx, message, err.Error())
 	}
 
 	return c.sendMessageWithCtx(ctx, message)
 }
 
+// normalizeName replaces user name with the normalized one
+// and updates message value with marshaled value, which contains the name.
+func (c *core) normalizeName(name *string, message *sarama.ProducerMessage, value interface{}) error {
+	normalized, err := c.normalizer.Name(*name)
+	if err != nil {
+		return err
+	}
+	if normalized == *name {
+		return nil
+	}
+
+	*name = normalized
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "marshal normalized user")
+	}
+	message.Value = sarama.ByteEncoder(data)
+	return nil
+}
+
 func createValidator(user *models.User) error {
 	if user.Name == "" {
 		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
@@ -136,29 +269,47 @@ func createValidator(user *models.User) error {
 	if user.Password == "" {
 		return errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty")
 	}
-	if !email.MatchString(user.Email) {
-		return errors.Wrap(errorsPkg.ErrValidation, "field: [email] has invalid format")
+	if err := models.ValidateEmail(user.Email); err != nil {
+		return err
 	}
 	if user.FullName == "" {
 		return errors.Wrap(errorsPkg.ErrValidation, "field: [full_name] cannot be empty")
 	}
-	return nil
+	if err := models.ValidateWritable(user.Attributes, nil); err != nil {
+		return err
+	}
+	return models.ValidateAttributes(user.Attributes)
 }
 
-func updateValidator(user *models.User) error {
-	if user.Name == "" {
+// updateValidator checks only fields listed in the update mask.
+func updateValidator(update *models.UserUpdate) error {
+	if update.Name == "" {
 		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
 	}
-	if user.Password == "" {
-		return errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty")
+	paths := update.Paths()
+	if err := models.ValidateMask(paths); err != nil {
+		return err
 	}
-	if !email.MatchString(user.Email) {
-		return errors.Wrap(errorsPkg.ErrValidation, "field: [email] has invalid format")
+	for _, path := range paths {
+		switch path {
+		case models.MaskPassword:
+			if update.Password == "" {
+				return errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty")
+			}
+		case models.MaskEmail:
+			if err := models.ValidateEmail(update.Email); err != nil {
+				return err
+			}
+		case models.MaskFullName:
+			if update.FullName == "" {
+				return errors.Wrap(errorsPkg.ErrValidation, "field: [full_name] cannot be empty")
+			}
+		}
 	}
-	if user.FullName == "" {
-		return errors.Wrap(errorsPkg.ErrValidation, "field: [full_name] cannot be empty")
+	if err := models.ValidateWritable(update.Attributes, paths); err != nil {
+		return err
 	}
-	return nil
+	return models.ValidateAttributes(update.Attributes)
 }
 
 func deleteValidator(name string) error {
@@ -168,6 +319,19 @@ func deleteValidator(name string) error {
 	return nil
 }
 
+func renameValidator(rename *models.UserRename) error {
+	if rename.Name == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
+	}
+	if rename.NewName == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [new_name] cannot be empty")
+	}
+	if rename.Name == rename.NewName {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [new_name] is equal to the current name")
+	}
+	return nil
+}
+
 func getValidator(name string) error {
 	if name == "" {
 		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
@@ -175,6 +339,13 @@ func getValidator(name string) error {
 	return nil
 }
 
+func getByIDValidator(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [id] must be UUID")
+	}
+	return nil
+}
+
 func (c *core) sendValidationErrorWithCtx(
 	ctx context.Context,
 	message *sarama.ProducerMessage,
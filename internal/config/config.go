@@ -1,7 +1,48 @@
 package config
 
 import (
+	"time"
+
+	exportPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/export"
+	pushPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/push"
+	metricsPkg "gitlab.ozon.dev/iTukaev/homework/internal/brokers/metrics"
+	anomalyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	approvalPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval"
+	authzPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/authz"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	backupPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup"
+	cdcPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cdc"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	costPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cost"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	fakePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	ldapsyncPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/ldapsync"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	reconcilePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reconcile"
+	seedPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/seed"
+	standbyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/standby"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	tombstonePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tombstone"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	usercountPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usercount"
+	userstatsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/userstats"
+	verifyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify"
+	bloomModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom/models"
+	budgetPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/budget"
+	cachedPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/cached"
+	canaryPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/canary"
+	instrumentedPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/instrumented"
+	oplogPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/oplog"
 	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	replicatePkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/replicate"
+	shardPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/shard"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	leaderPkg "gitlab.ozon.dev/iTukaev/homework/pkg/leader"
+	oidcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/oidc"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
 )
 
@@ -24,6 +65,23 @@ type Transport interface {
 	GRPCDataAddr() string
 	HTTPAddr() string
 	HTTPDataAddr() string
+	DebugToken() string
+	AdminTokens() []string
+	OIDCConfig() oidcPkg.Config
+	HedgeConfig() grpcPkg.HedgeConfig
+	BalancerConfig() grpcPkg.BalancerConfig
+	ListQuotaConfig() grpcPkg.ListQuotaConfig
+	NormalizeConfig() grpcPkg.NormalizeConfig
+	DedupConfig() grpcPkg.DedupConfig
+	RateLimitConfig() grpcPkg.RateLimitConfig
+	ResponseSizeConfig() grpcPkg.ResponseSizeConfig
+	MethodsConfig() grpcPkg.MethodsConfig
+	FieldsConfig() grpcPkg.FieldsConfig
+	ImpersonationConfig() grpcPkg.ImpersonationConfig
+	AuthzConfig() authzPkg.Config
+	ExportConfig() exportPkg.Config
+	PushConfig() pushPkg.Config
+	PageTokenConfig() pagetokenPkg.Config
 }
 
 type Data interface {
@@ -31,4 +89,43 @@ type Data interface {
 	Local() bool
 	WorkersCount() int
 	RedisConfig() redisPkg.Config
+	ListCacheTTL() time.Duration
+	CacheFormat() string
+	ListWarmupLimit() uint64
+	PasswordMaxAttempts() int
+	PasswordAttemptsWindow() time.Duration
+	PasswordPolicyConfig() passwordPkg.Config
+	SlowQueryThreshold() time.Duration
+	OpLogConfig() oplogPkg.Config
+	RepoMetricsConfig() instrumentedPkg.Config
+	CanaryConfig() canaryPkg.Config
+	ScanBudgetConfig() budgetPkg.Config
+	CostBudgetConfig() costPkg.Config
+	RepoCacheConfig() cachedPkg.Config
+	ShardConfig() shardPkg.Config
+	BloomConfig() bloomModels.Config
+	StandbyConfig() standbyPkg.Config
+	NamePolicy() normalizePkg.Policy
+	DenylistConfig() denylistPkg.Config
+	AvatarConfig() avatarPkg.Config
+	BackupConfig() backupPkg.Config
+	HistoryConfig() historyPkg.Config
+	TombstoneConfig() tombstonePkg.Config
+	VerifyConfig() verifyPkg.Config
+	AnomalyConfig() anomalyPkg.Config
+	ApprovalConfig() approvalPkg.Config
+	LeaderConfig() leaderPkg.Config
+	LDAPSyncConfig() ldapsyncPkg.Config
+	ReconcileConfig() reconcilePkg.Config
+	CDCConfig() cdcPkg.Config
+	SeedConfig() seedPkg.Config
+	EventsConfig() eventsPkg.Config
+	ReplicationConfig() replicatePkg.Config
+	ConsumerConfig() metricsPkg.Config
+	MaintenanceConfig() maintenancePkg.Config
+	TenantConfig() tenantPkg.Config
+	UserStatsConfig() userstatsPkg.Config
+	UserCountConfig() usercountPkg.Config
+	UsageConfig() usagePkg.Config
+	GenerateConfig() fakePkg.Config
 }
@@ -2,12 +2,52 @@ package yaml
 
 import (
 	"log"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
+	exportPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/export"
+	pushPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/push"
+	metricsPkg "gitlab.ozon.dev/iTukaev/homework/internal/brokers/metrics"
 	configPkg "gitlab.ozon.dev/iTukaev/homework/internal/config"
+	anomalyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	approvalPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/approval"
+	authzPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/authz"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	backupPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/backup"
+	cdcPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cdc"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	costPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cost"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	fakePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/fake"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	ldapsyncPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/ldapsync"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	reconcilePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reconcile"
+	seedPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/seed"
+	standbyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/standby"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	tombstonePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tombstone"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	usercountPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usercount"
+	userstatsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/userstats"
+	verifyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/verify"
+	bloomModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom/models"
+	budgetPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/budget"
+	cachedPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/cached"
+	canaryPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/canary"
+	instrumentedPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/instrumented"
+	oplogPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/oplog"
 	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	replicatePkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/replicate"
+	shardPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/shard"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	leaderPkg "gitlab.ozon.dev/iTukaev/homework/pkg/leader"
+	oidcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/oidc"
+	pagetokenPkg "gitlab.ozon.dev/iTukaev/homework/pkg/pagetoken"
 	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
 )
 
@@ -44,6 +84,126 @@ func (config) HTTPDataAddr() string {
 	return viper.GetString("http_data")
 }
 
+func (config) DebugToken() string {
+	return viper.GetString("debug_token")
+}
+
+func (config) AdminTokens() []string {
+	return viper.GetStringSlice("admin_tokens")
+}
+
+func (config) BalancerConfig() grpcPkg.BalancerConfig {
+	var balancer grpcPkg.BalancerConfig
+	if err := viper.UnmarshalKey("balancer", &balancer); err != nil {
+		log.Fatalf("Balancer config unmarshal error: %v\n", err)
+	}
+	return balancer
+}
+
+func (config) ListQuotaConfig() grpcPkg.ListQuotaConfig {
+	var quota grpcPkg.ListQuotaConfig
+	if err := viper.UnmarshalKey("list_quota", &quota); err != nil {
+		log.Fatalf("List quota config unmarshal error: %v\n", err)
+	}
+	return quota
+}
+
+func (config) NormalizeConfig() grpcPkg.NormalizeConfig {
+	var normalize grpcPkg.NormalizeConfig
+	if err := viper.UnmarshalKey("normalize", &normalize); err != nil {
+		log.Fatalf("Normalize config unmarshal error: %v\n", err)
+	}
+	return normalize
+}
+
+func (config) DedupConfig() grpcPkg.DedupConfig {
+	var dedup grpcPkg.DedupConfig
+	if err := viper.UnmarshalKey("dedup", &dedup); err != nil {
+		log.Fatalf("Dedup config unmarshal error: %v\n", err)
+	}
+	return dedup
+}
+
+func (config) RateLimitConfig() grpcPkg.RateLimitConfig {
+	var limit grpcPkg.RateLimitConfig
+	if err := viper.UnmarshalKey("rate_limit", &limit); err != nil {
+		log.Fatalf("Rate limit config unmarshal error: %v\n", err)
+	}
+	return limit
+}
+
+func (config) ResponseSizeConfig() grpcPkg.ResponseSizeConfig {
+	var size grpcPkg.ResponseSizeConfig
+	if err := viper.UnmarshalKey("response_size", &size); err != nil {
+		log.Fatalf("Response size config unmarshal error: %v\n", err)
+	}
+	return size
+}
+
+func (config) MethodsConfig() grpcPkg.MethodsConfig {
+	var methods grpcPkg.MethodsConfig
+	if err := viper.UnmarshalKey("methods", &methods); err != nil {
+		log.Fatalf("Methods config unmarshal error: %v\n", err)
+	}
+	return methods
+}
+
+func (config) FieldsConfig() grpcPkg.FieldsConfig {
+	var fields grpcPkg.FieldsConfig
+	if err := viper.UnmarshalKey("fields", &fields); err != nil {
+		log.Fatalf("Fields config unmarshal error: %v\n", err)
+	}
+	return fields
+}
+
+func (config) ImpersonationConfig() grpcPkg.ImpersonationConfig {
+	var impersonation grpcPkg.ImpersonationConfig
+	if err := viper.UnmarshalKey("impersonation", &impersonation); err != nil {
+		log.Fatalf("Impersonation config unmarshal error: %v\n", err)
+	}
+	return impersonation
+}
+
+func (config) AuthzConfig() authzPkg.Config {
+	var authz authzPkg.Config
+	if err := viper.UnmarshalKey("authz", &authz); err != nil {
+		log.Fatalf("Authz config unmarshal error: %v\n", err)
+	}
+	return authz
+}
+
+func (config) ExportConfig() exportPkg.Config {
+	var export exportPkg.Config
+	if err := viper.UnmarshalKey("export", &export); err != nil {
+		log.Fatalf("Export config unmarshal error: %v\n", err)
+	}
+	return export
+}
+
+func (config) PushConfig() pushPkg.Config {
+	var push pushPkg.Config
+	if err := viper.UnmarshalKey("push", &push); err != nil {
+		log.Fatalf("Push config unmarshal error: %v\n", err)
+	}
+	return push
+}
+
+func (config) PageTokenConfig() pagetokenPkg.Config {
+	var pageToken pagetokenPkg.Config
+	if err := viper.UnmarshalKey("page_token", &pageToken); err != nil {
+		log.Fatalf("Page token config unmarshal error: %v\n", err)
+	}
+	return pageToken
+}
+
+func (config) HedgeConfig() grpcPkg.HedgeConfig {
+	var hedge grpcPkg.HedgeConfig
+	if err := viper.UnmarshalKey("hedge", &hedge); err != nil {
+		log.Fatalf("Hedge config unmarshal error: %v\n", err)
+	}
+	return hedge
+}
+
 func (config) LogLevel() string {
 	return viper.GetString("log")
 }
@@ -64,6 +224,222 @@ func (config) RedisConfig() redisPkg.Config {
 	return cfg
 }
 
+func (config) BloomConfig() bloomModels.Config {
+	var cfg bloomModels.Config
+	if err := viper.UnmarshalKey("bloom", &cfg); err != nil {
+		log.Fatalf("Bloom filter config unmarshal error: %v\n", err)
+	}
+	return cfg
+}
+
+func (config) StandbyConfig() standbyPkg.Config {
+	var cfg standbyPkg.Config
+	if err := viper.UnmarshalKey("warm_standby", &cfg); err != nil {
+		log.Fatalf("Warm standby config unmarshal error: %v\n", err)
+	}
+	return cfg
+}
+
+func (config) NamePolicy() normalizePkg.Policy {
+	var policy normalizePkg.Policy
+	if err := viper.UnmarshalKey("name_policy", &policy); err != nil {
+		log.Fatalf("Name policy config unmarshal error: %v\n", err)
+	}
+	return policy
+}
+
+func (config) AvatarConfig() avatarPkg.Config {
+	var avatar avatarPkg.Config
+	if err := viper.UnmarshalKey("avatar", &avatar); err != nil {
+		log.Fatalf("Avatar config unmarshal error: %v\n", err)
+	}
+	return avatar
+}
+
+func (config) BackupConfig() backupPkg.Config {
+	var backup backupPkg.Config
+	if err := viper.UnmarshalKey("backup", &backup); err != nil {
+		log.Fatalf("Backup config unmarshal error: %v\n", err)
+	}
+	return backup
+}
+
+func (config) HistoryConfig() historyPkg.Config {
+	var history historyPkg.Config
+	if err := viper.UnmarshalKey("history", &history); err != nil {
+		log.Fatalf("History config unmarshal error: %v\n", err)
+	}
+	return history
+}
+
+func (config) TombstoneConfig() tombstonePkg.Config {
+	var tombstone tombstonePkg.Config
+	if err := viper.UnmarshalKey("tombstones", &tombstone); err != nil {
+		log.Fatalf("Tombstones config unmarshal error: %v\n", err)
+	}
+	return tombstone
+}
+
+func (config) VerifyConfig() verifyPkg.Config {
+	var verify verifyPkg.Config
+	if err := viper.UnmarshalKey("verify", &verify); err != nil {
+		log.Fatalf("Verify config unmarshal error: %v\n", err)
+	}
+	return verify
+}
+
+func (config) AnomalyConfig() anomalyPkg.Config {
+	var anomaly anomalyPkg.Config
+	if err := viper.UnmarshalKey("anomaly", &anomaly); err != nil {
+		log.Fatalf("Anomaly config unmarshal error: %v\n", err)
+	}
+	return anomaly
+}
+
+func (config) ApprovalConfig() approvalPkg.Config {
+	var approval approvalPkg.Config
+	if err := viper.UnmarshalKey("approval", &approval); err != nil {
+		log.Fatalf("Approval config unmarshal error: %v\n", err)
+	}
+	return approval
+}
+
+func (config) CDCConfig() cdcPkg.Config {
+	var cdc cdcPkg.Config
+	if err := viper.UnmarshalKey("cdc", &cdc); err != nil {
+		log.Fatalf("CDC config unmarshal error: %v\n", err)
+	}
+	return cdc
+}
+
+func (config) OIDCConfig() oidcPkg.Config {
+	var oidc oidcPkg.Config
+	if err := viper.UnmarshalKey("oidc", &oidc); err != nil {
+		log.Fatalf("OIDC config unmarshal error: %v\n", err)
+	}
+	return oidc
+}
+
+func (config) ReconcileConfig() reconcilePkg.Config {
+	var reconcile reconcilePkg.Config
+	if err := viper.UnmarshalKey("reconcile", &reconcile); err != nil {
+		log.Fatalf("Reconcile config unmarshal error: %v\n", err)
+	}
+	return reconcile
+}
+
+func (config) SeedConfig() seedPkg.Config {
+	var seed seedPkg.Config
+	if err := viper.UnmarshalKey("seed", &seed); err != nil {
+		log.Fatalf("Seed config unmarshal error: %v\n", err)
+	}
+	return seed
+}
+
+func (config) EventsConfig() eventsPkg.Config {
+	var events eventsPkg.Config
+	if err := viper.UnmarshalKey("events", &events); err != nil {
+		log.Fatalf("Events config unmarshal error: %v\n", err)
+	}
+	return events
+}
+
+func (config) ReplicationConfig() replicatePkg.Config {
+	var replication replicatePkg.Config
+	if err := viper.UnmarshalKey("replication", &replication); err != nil {
+		log.Fatalf("Replication config unmarshal error: %v\n", err)
+	}
+	return replication
+}
+
+func (config) ConsumerConfig() metricsPkg.Config {
+	var consumer metricsPkg.Config
+	if err := viper.UnmarshalKey("consumer", &consumer); err != nil {
+		log.Fatalf("Consumer config unmarshal error: %v\n", err)
+	}
+	return consumer
+}
+
+func (config) MaintenanceConfig() maintenancePkg.Config {
+	var maintenance maintenancePkg.Config
+	if err := viper.UnmarshalKey("maintenance", &maintenance); err != nil {
+		log.Fatalf("Maintenance config unmarshal error: %v\n", err)
+	}
+	return maintenance
+}
+
+func (config) TenantConfig() tenantPkg.Config {
+	var tenant tenantPkg.Config
+	if err := viper.UnmarshalKey("tenant_overrides", &tenant); err != nil {
+		log.Fatalf("Tenant overrides config unmarshal error: %v\n", err)
+	}
+	return tenant
+}
+
+func (config) UserStatsConfig() userstatsPkg.Config {
+	var stats userstatsPkg.Config
+	if err := viper.UnmarshalKey("user_stats", &stats); err != nil {
+		log.Fatalf("User stats config unmarshal error: %v\n", err)
+	}
+	return stats
+}
+
+func (config) UserCountConfig() usercountPkg.Config {
+	var count usercountPkg.Config
+	if err := viper.UnmarshalKey("user_counters", &count); err != nil {
+		log.Fatalf("User counters config unmarshal error: %v\n", err)
+	}
+	return count
+}
+
+func (config) UsageConfig() usagePkg.Config {
+	var usage usagePkg.Config
+	if err := viper.UnmarshalKey("usage", &usage); err != nil {
+		log.Fatalf("Usage config unmarshal error: %v\n", err)
+	}
+	return usage
+}
+
+func (config) GenerateConfig() fakePkg.Config {
+	var generate fakePkg.Config
+	if err := viper.UnmarshalKey("generate", &generate); err != nil {
+		log.Fatalf("Generate config unmarshal error: %v\n", err)
+	}
+	return generate
+}
+
+func (config) LDAPSyncConfig() ldapsyncPkg.Config {
+	var ldapSync ldapsyncPkg.Config
+	if err := viper.UnmarshalKey("ldap_sync", &ldapSync); err != nil {
+		log.Fatalf("LDAP sync config unmarshal error: %v\n", err)
+	}
+	return ldapSync
+}
+
+func (config) PasswordPolicyConfig() passwordPkg.Config {
+	var policy passwordPkg.Config
+	if err := viper.UnmarshalKey("password_policy", &policy); err != nil {
+		log.Fatalf("Password policy config unmarshal error: %v\n", err)
+	}
+	return policy
+}
+
+func (config) DenylistConfig() denylistPkg.Config {
+	var denylist denylistPkg.Config
+	if err := viper.UnmarshalKey("denylist", &denylist); err != nil {
+		log.Fatalf("Denylist config unmarshal error: %v\n", err)
+	}
+	return denylist
+}
+
+func (config) LeaderConfig() leaderPkg.Config {
+	var leader leaderPkg.Config
+	if err := viper.UnmarshalKey("leader", &leader); err != nil {
+		log.Fatalf("Leader config unmarshal error: %v\n", err)
+	}
+	return leader
+}
+
 func (config) Local() bool {
 	return viper.GetBool("local")
 }
@@ -72,6 +448,86 @@ func (config) WorkersCount() int {
 	return viper.GetInt("workers")
 }
 
+func (config) ListCacheTTL() time.Duration {
+	return viper.GetDuration("list_cache_ttl")
+}
+
+func (config) CacheFormat() string {
+	return viper.GetString("cache_format")
+}
+
+func (config) ListWarmupLimit() uint64 {
+	return viper.GetUint64("list_warmup_limit")
+}
+
+func (config) PasswordMaxAttempts() int {
+	return viper.GetInt("password_check.max_attempts")
+}
+
+func (config) PasswordAttemptsWindow() time.Duration {
+	return viper.GetDuration("password_check.window")
+}
+
+func (config) CanaryConfig() canaryPkg.Config {
+	var canary canaryPkg.Config
+	if err := viper.UnmarshalKey("canary", &canary); err != nil {
+		log.Fatalf("Canary config unmarshal error: %v\n", err)
+	}
+	return canary
+}
+
+func (config) OpLogConfig() oplogPkg.Config {
+	var oplog oplogPkg.Config
+	if err := viper.UnmarshalKey("oplog", &oplog); err != nil {
+		log.Fatalf("Operation log config unmarshal error: %v\n", err)
+	}
+	return oplog
+}
+
+func (config) ScanBudgetConfig() budgetPkg.Config {
+	var budget budgetPkg.Config
+	if err := viper.UnmarshalKey("scan_budget", &budget); err != nil {
+		log.Fatalf("Scan budget config unmarshal error: %v\n", err)
+	}
+	return budget
+}
+
+func (config) RepoCacheConfig() cachedPkg.Config {
+	var cached cachedPkg.Config
+	if err := viper.UnmarshalKey("repo_cache", &cached); err != nil {
+		log.Fatalf("Repo cache config unmarshal error: %v\n", err)
+	}
+	return cached
+}
+
+func (config) CostBudgetConfig() costPkg.Config {
+	var cost costPkg.Config
+	if err := viper.UnmarshalKey("cost_budget", &cost); err != nil {
+		log.Fatalf("Cost budget config unmarshal error: %v\n", err)
+	}
+	return cost
+}
+
+func (config) ShardConfig() shardPkg.Config {
+	var shard shardPkg.Config
+	if err := viper.UnmarshalKey("shard", &shard); err != nil {
+		log.Fatalf("Shard config unmarshal error: %v\n", err)
+	}
+	return shard
+}
+
+func (config) SlowQueryThreshold() time.Duration {
+	return viper.GetDuration("slow_query_threshold")
+}
+
+func (config) RepoMetricsConfig() instrumentedPkg.Config {
+	var metrics instrumentedPkg.Config
+	if err := viper.UnmarshalKey("repo_metrics", &metrics); err != nil {
+		log.Fatalf("Repo metrics config unmarshal error: %v\n", err)
+	}
+	return metrics
+}
+
 func (config) Brokers() []string {
 	return viper.GetStringSlice("brokers")
 }
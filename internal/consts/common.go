@@ -4,7 +4,11 @@ const (
 	UserCreate  = "create"
 	UserUpdate  = "update"
 	UserDelete  = "delete"
+	UserRename  = "rename"
+	UserDisable = "disable"
+	UserEnable  = "enable"
 	UserGet     = "get"
+	UserGetByID = "get_by_id"
 	UserList    = "list"
 	UserAllList = "all_list"
 )
@@ -22,9 +22,139 @@ var (
 	Response *core
 	Success  *core
 	Errors   *core
+	// SlowOps counts slow repository calls by method
+	SlowOps *core
+	// RepoLatency and RepoErrors are per backend/table/method repository calls,
+	// errors are counted by class
+	RepoLatency *histogramVec
+	RepoErrors  *core
+	// RepoFailovers counts primaries lost by postgres pools by pool,
+	// RepoReconnectsFailed counts failed reconnects to the primary by pool
+	RepoFailovers        *core
+	RepoReconnectsFailed *core
+	// CanaryCompared and CanaryMismatch count candidate repository calls by method
+	CanaryCompared *core
+	CanaryMismatch *core
+	// VerifyViolations counts violations of user invariants found by the verifier by class
+	VerifyViolations *core
+	// AnomalyAlerts counts alerts of suspicious mutation patterns by kind
+	AnomalyAlerts *core
+	// AnomalyActive is the number of alerts not confirmed by an admin
+	AnomalyActive *gauge
 
 	Hit  *simple
 	Miss *simple
+
+	ListHit  *simple
+	ListMiss *simple
+
+	// RepoCacheHit and RepoCacheMiss count gets of the cache in front of the repository,
+	// RepoGetsCollapsed counts gets answered by the read of a concurrent get of the same user
+	RepoCacheHit      *simple
+	RepoCacheMiss     *simple
+	RepoGetsCollapsed *simple
+
+	BloomSkip    *simple
+	BloomRebuild *simple
+	// BloomLoaded and BloomLoadFailed count filters loaded from peers on start,
+	// failed loads are replaced by the rebuild from the database
+	BloomLoaded     *simple
+	BloomLoadFailed *simple
+
+	// CacheSampled and CacheDiverged count cached users compared with the repository by reconciliation
+	CacheSampled  *simple
+	CacheDiverged *simple
+
+	// HedgeCalls counts hedged client calls, HedgeWins counts calls completed by the hedge first
+	HedgeCalls *simple
+	HedgeWins  *simple
+
+	// ListTruncated counts UserList requests, which limit was lowered by the quota
+	ListTruncated *simple
+	// ResponsesCapped counts list responses cut by the serialized size of users
+	ResponsesCapped *simple
+
+	// ScanBudget counts lists over the scan budget of the repository by action: rejected or truncated
+	ScanBudget *core
+
+	// NotModified counts HTTP GET requests answered by 304, since the ETag is unchanged
+	NotModified *simple
+
+	// DuplicatesSuppressed counts duplicated mutations answered by the result of the first call by method
+	DuplicatesSuppressed *core
+
+	// Impersonations counts requests made with act-as, ImpersonationDenied counts rejected ones
+	Impersonations      *simple
+	ImpersonationDenied *simple
+
+	// AuthzDecisions counts decisions of the authorization policies by effect
+	AuthzDecisions *core
+
+	// Panics counts calls, which handlers panicked, by method
+	Panics *core
+	// RateLimited counts calls rejected by the rate limit of callers by method
+	RateLimited *core
+	// RequestCost sums cost units of calls by method, CostBudgetExceeded counts minutes,
+	// in which the caller spent more than the cost budget, by caller
+	RequestCost        *core
+	CostBudgetExceeded *core
+
+	// ExportedRows counts users written by the CSV export
+	ExportedRows *simple
+	// UsersGenerated counts fake users created by the generator of demo and load test data
+	UsersGenerated *simple
+
+	// PushConnections is the number of open WebSocket and SSE connections of user events,
+	// PushEvents counts events sent to them, PushSlowClosed counts connections closed as slow consumers
+	PushConnections *gauge
+	PushEvents      *simple
+	PushSlowClosed  *simple
+
+	// HistoryPruned counts user history events removed by the retention
+	HistoryPruned *simple
+	// TombstonesPruned counts tombstones of deleted users removed by the retention
+	TombstonesPruned *simple
+	// UserCountsCorrected counts tenants, which user counters were corrected by the reconciliation
+	UserCountsCorrected *simple
+
+	// CDCApplied counts changes of the users table read from the replication slot
+	CDCApplied *simple
+	// ShardMoved counts users moved to their shards by resharding
+	ShardMoved *simple
+	// ShardSkipped counts shards skipped by best-effort lists
+	ShardSkipped *simple
+
+	// EventsDropped counts user events dropped by overflowed queues of asynchronous subscribers
+	EventsDropped *simple
+	// WebhookSent and WebhookFailed count user events delivered to the webhook and abandoned after retries
+	WebhookSent   *simple
+	WebhookFailed *simple
+
+	// ReplicaSent counts mutations applied by passive regions, ReplicaDropped counts mutations
+	// dropped by overflowed queues, passive regions catch up with the snapshot then
+	ReplicaSent    *simple
+	ReplicaDropped *simple
+	// ReplicaConflicts counts replicated writes rejected, since the stored user was written later
+	ReplicaConflicts *simple
+
+	// ConsumerProcessed and ConsumerFailed count messages handled by the consumer group,
+	// failed messages are not committed and consumed again
+	ConsumerProcessed  *simple
+	ConsumerFailed     *simple
+	ConsumerRebalances *simple
+	ConsumerLatency    *histogram
+
+	// UsersTotal, UsersByTenant and UsersByStatus are numbers of stored users of the last user stats refresh
+	UsersTotal    *gauge
+	UsersByTenant *gaugeVec
+	UsersByStatus *gaugeVec
+
+	// local cache
+	LocalEntries   *gauge
+	LocalMemory    *gauge
+	LocalEvictions *simple
+	LocalResize    *simple
+	LocalLockWait  *histogram
 )
 
 func init() {
@@ -40,8 +170,118 @@ func init() {
 	Errors = new(core)
 	Errors.data = make(map[string]uint64)
 
+	SlowOps = new(core)
+	SlowOps.data = make(map[string]uint64)
+
+	RepoLatency = newHistogramVec(repoLatencyBuckets)
+	RepoErrors = new(core)
+	RepoErrors.data = make(map[string]uint64)
+	RepoFailovers = new(core)
+	RepoFailovers.data = make(map[string]uint64)
+	RepoReconnectsFailed = new(core)
+	RepoReconnectsFailed.data = make(map[string]uint64)
+
+	CanaryCompared = new(core)
+	CanaryCompared.data = make(map[string]uint64)
+
+	CanaryMismatch = new(core)
+	CanaryMismatch.data = make(map[string]uint64)
+
+	VerifyViolations = new(core)
+	VerifyViolations.data = make(map[string]uint64)
+
+	AnomalyAlerts = new(core)
+	AnomalyAlerts.data = make(map[string]uint64)
+	AnomalyActive = new(gauge)
+
 	Hit = new(simple)
 	Miss = new(simple)
+
+	ListHit = new(simple)
+	ListMiss = new(simple)
+
+	RepoCacheHit = new(simple)
+	RepoCacheMiss = new(simple)
+	RepoGetsCollapsed = new(simple)
+
+	BloomSkip = new(simple)
+	BloomRebuild = new(simple)
+	BloomLoaded = new(simple)
+	BloomLoadFailed = new(simple)
+
+	CacheSampled = new(simple)
+	CacheDiverged = new(simple)
+
+	HedgeCalls = new(simple)
+	HedgeWins = new(simple)
+
+	ListTruncated = new(simple)
+	ResponsesCapped = new(simple)
+
+	ScanBudget = new(core)
+	ScanBudget.data = make(map[string]uint64)
+
+	NotModified = new(simple)
+	DuplicatesSuppressed = new(core)
+	DuplicatesSuppressed.data = make(map[string]uint64)
+
+	Impersonations = new(simple)
+	ImpersonationDenied = new(simple)
+
+	AuthzDecisions = new(core)
+	AuthzDecisions.data = make(map[string]uint64)
+
+	Panics = new(core)
+	Panics.data = make(map[string]uint64)
+
+	RateLimited = new(core)
+	RateLimited.data = make(map[string]uint64)
+
+	RequestCost = new(core)
+	RequestCost.data = make(map[string]uint64)
+	CostBudgetExceeded = new(core)
+	CostBudgetExceeded.data = make(map[string]uint64)
+
+	ExportedRows = new(simple)
+	UsersGenerated = new(simple)
+
+	PushConnections = new(gauge)
+	PushEvents = new(simple)
+	PushSlowClosed = new(simple)
+
+	HistoryPruned = new(simple)
+	TombstonesPruned = new(simple)
+	UserCountsCorrected = new(simple)
+
+	CDCApplied = new(simple)
+
+	ShardMoved = new(simple)
+	ShardSkipped = new(simple)
+
+	EventsDropped = new(simple)
+	WebhookSent = new(simple)
+	WebhookFailed = new(simple)
+
+	ReplicaSent = new(simple)
+	ReplicaDropped = new(simple)
+	ReplicaConflicts = new(simple)
+
+	ConsumerProcessed = new(simple)
+	ConsumerFailed = new(simple)
+	ConsumerRebalances = new(simple)
+	ConsumerLatency = newHistogram(consumerLatencyBuckets)
+
+	UsersTotal = new(gauge)
+	UsersByTenant = new(gaugeVec)
+	UsersByTenant.data = make(map[string]int64)
+	UsersByStatus = new(gaugeVec)
+	UsersByStatus.data = make(map[string]int64)
+
+	LocalEntries = new(gauge)
+	LocalMemory = new(gauge)
+	LocalEvictions = new(simple)
+	LocalResize = new(simple)
+	LocalLockWait = newHistogram(lockWaitBuckets)
 }
 
 func (c *core) Inc(param string) {
@@ -51,6 +291,13 @@ func (c *core) Inc(param string) {
 	c.data[param]++
 }
 
+func (c *core) Add(param string, delta uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[param] += delta
+}
+
 func (c *core) String() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -66,7 +313,33 @@ func (s *simple) Inc() {
 	atomic.AddUint64(&s.data, 1)
 }
 
+func (s *simple) Add(delta uint64) {
+	atomic.AddUint64(&s.data, delta)
+}
+
+func (s *simple) Value() uint64 {
+	return atomic.LoadUint64(&s.data)
+}
+
 func (s *simple) String() string {
 	res := atomic.LoadUint64(&s.data)
 	return strconv.FormatUint(res, 10)
 }
+
+// Rate returns part/total in percents.
+func Rate(part, total *simple) string {
+	p, t := part.Value(), total.Value()
+	if t == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(p)*100/float64(t), 'f', 2, 64)
+}
+
+// Ratio returns hit/(hit+miss) in percents.
+func Ratio(hit, miss *simple) string {
+	h, m := hit.Value(), miss.Value()
+	if h+m == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(h)*100/float64(h+m), 'f', 2, 64)
+}
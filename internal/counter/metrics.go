@@ -0,0 +1,232 @@
+package counter
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type gauge struct {
+	data int64
+}
+
+func (g *gauge) Add(delta int64) {
+	atomic.AddInt64(&g.data, delta)
+}
+
+func (g *gauge) Set(value int64) {
+	atomic.StoreInt64(&g.data, value)
+}
+
+func (g *gauge) Value() int64 {
+	return atomic.LoadInt64(&g.data)
+}
+
+func (g *gauge) String() string {
+	return strconv.FormatInt(g.Value(), 10)
+}
+
+// gaugeVec is a set of gauges by key replaced at once, keys missing in the new set are dropped.
+type gaugeVec struct {
+	mu   sync.Mutex
+	data map[string]int64
+}
+
+// Replace sets the gauges to the values.
+func (v *gaugeVec) Replace(values map[string]int64) {
+	data := make(map[string]int64, len(values))
+	for key, value := range values {
+		data[key] = value
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.data = data
+}
+
+// Value returns the gauge of the key, it is zero for missing keys.
+func (v *gaugeVec) Value(key string) int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.data[key]
+}
+
+func (v *gaugeVec) String() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	data, _ := json.Marshal(v.data)
+	return string(data)
+}
+
+// lockWaitBuckets are upper bounds of the lock wait histogram.
+var lockWaitBuckets = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// consumerLatencyBuckets are upper bounds of the message processing histogram.
+var consumerLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// histogram counts observations in cumulative buckets, like Prometheus histogram does.
+// The last traced observation of a bucket is kept as its exemplar, like OpenMetrics does,
+// so a slow bucket links to a trace of the slow call.
+type histogram struct {
+	mu      sync.Mutex
+	bounds  []time.Duration
+	buckets []uint64
+	count   uint64
+	sum     time.Duration
+	// exemplars are kept by the first bucket of the observation, the last one is +Inf
+	exemplars []*exemplar
+}
+
+type exemplar struct {
+	TraceID   string  `json:"trace_id"`
+	ValueMs   float64 `json:"value_ms"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	return &histogram{
+		bounds:    bounds,
+		buckets:   make([]uint64, len(bounds)),
+		exemplars: make([]*exemplar, len(bounds)+1),
+	}
+}
+
+func (h *histogram) Observe(d time.Duration) {
+	h.ObserveExemplar(d, "")
+}
+
+// ObserveExemplar observes the duration of the traced call, empty traceID keeps exemplars as they are.
+func (h *histogram) ObserveExemplar(d time.Duration, traceID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += d
+	first := len(h.bounds)
+	for i := len(h.bounds) - 1; i >= 0; i-- {
+		if d <= h.bounds[i] {
+			h.buckets[i]++
+			first = i
+		}
+	}
+	if traceID != "" {
+		h.exemplars[first] = &exemplar{
+			TraceID:   traceID,
+			ValueMs:   float64(d) / float64(time.Millisecond),
+			Timestamp: time.Now().Unix(),
+		}
+	}
+}
+
+func (h *histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make(map[string]uint64, len(h.bounds)+1)
+	exemplars := make(map[string]*exemplar)
+	for i, bound := range h.bounds {
+		buckets["le_"+bound.String()] = h.buckets[i]
+		if h.exemplars[i] != nil {
+			exemplars["le_"+bound.String()] = h.exemplars[i]
+		}
+	}
+	buckets["le_inf"] = h.count
+	if inf := h.exemplars[len(h.bounds)]; inf != nil {
+		exemplars["le_inf"] = inf
+	}
+
+	data, _ := json.Marshal(struct {
+		Count     uint64               `json:"count"`
+		SumMs     float64              `json:"sum_ms"`
+		Buckets   map[string]uint64    `json:"buckets"`
+		Exemplars map[string]*exemplar `json:"exemplars,omitempty"`
+	}{
+		Count:     h.count,
+		SumMs:     float64(h.sum) / float64(time.Millisecond),
+		Buckets:   buckets,
+		Exemplars: exemplars,
+	})
+	return string(data)
+}
+
+// repoLatencyBuckets are upper bounds of the repository call histograms.
+var repoLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	25 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	2 * time.Second,
+}
+
+// histogramVec is a set of histograms with the same buckets by key.
+type histogramVec struct {
+	mu         sync.Mutex
+	bounds     []time.Duration
+	histograms map[string]*histogram
+}
+
+func newHistogramVec(bounds []time.Duration) *histogramVec {
+	return &histogramVec{
+		bounds:     bounds,
+		histograms: make(map[string]*histogram),
+	}
+}
+
+func (v *histogramVec) Observe(key string, d time.Duration) {
+	v.ObserveExemplar(key, d, "")
+}
+
+// ObserveExemplar observes the duration of the traced call of the key.
+func (v *histogramVec) ObserveExemplar(key string, d time.Duration, traceID string) {
+	v.mu.Lock()
+	h, ok := v.histograms[key]
+	if !ok {
+		h = newHistogram(v.bounds)
+		v.histograms[key] = h
+	}
+	v.mu.Unlock()
+
+	h.ObserveExemplar(d, traceID)
+}
+
+// Count returns the number of observations of the key.
+func (v *histogramVec) Count(key string) uint64 {
+	v.mu.Lock()
+	h, ok := v.histograms[key]
+	v.mu.Unlock()
+	if !ok {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (v *histogramVec) String() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	data := make(map[string]json.RawMessage, len(v.histograms))
+	for key, h := range v.histograms {
+		data[key] = json.RawMessage(h.String())
+	}
+	res, _ := json.Marshal(data)
+	return string(res)
+}
@@ -0,0 +1,45 @@
+package counter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram_Exemplars(t *testing.T) {
+	h := newHistogram([]time.Duration{time.Millisecond, time.Second})
+	h.ObserveExemplar(500*time.Millisecond, "slow")
+	h.ObserveExemplar(2*time.Second, "slowest")
+	h.Observe(600 * time.Millisecond)
+	h.ObserveExemplar(100*time.Microsecond, "")
+
+	var data struct {
+		Count     uint64            `json:"count"`
+		Buckets   map[string]uint64 `json:"buckets"`
+		Exemplars map[string]exemplar
+	}
+	require.NoError(t, json.Unmarshal([]byte(h.String()), &data))
+	assert.Equal(t, uint64(4), data.Count)
+	assert.Equal(t, map[string]uint64{"le_1ms": 1, "le_1s": 3, "le_inf": 4}, data.Buckets)
+
+	// untraced observations keep exemplars of their buckets
+	require.Len(t, data.Exemplars, 2)
+	assert.Equal(t, "slow", data.Exemplars["le_1s"].TraceID)
+	assert.Equal(t, float64(500), data.Exemplars["le_1s"].ValueMs)
+	assert.Equal(t, "slowest", data.Exemplars["le_inf"].TraceID)
+}
+
+func TestGaugeVec_Replace(t *testing.T) {
+	v := &gaugeVec{data: make(map[string]int64)}
+	assert.Equal(t, "{}", v.String())
+
+	v.Replace(map[string]int64{"acme": 2, "none": 1})
+	v.Replace(map[string]int64{"acme": 3})
+	assert.Equal(t, int64(3), v.Value("acme"))
+	// keys missing in the new set are dropped
+	assert.Zero(t, v.Value("none"))
+	assert.Equal(t, `{"acme":3}`, v.String())
+}
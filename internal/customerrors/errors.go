@@ -8,4 +8,32 @@ var (
 	ErrTimeout           = errors.New("deadline exceeded")
 	ErrUnexpected        = errors.New("unexpected error")
 	ErrValidation        = errors.New("validation error")
+	ErrAvatarNotFound    = errors.New("avatar not found")
+	ErrAvatarsDisabled   = errors.New("avatars are disabled")
+	ErrTooManyAttempts   = errors.New("too many attempts")
+	ErrStatusTransition  = errors.New("invalid status transition")
+	ErrPasswordExpired   = errors.New("password expired")
+	ErrHistoryDisabled   = errors.New("user history is disabled")
+	ErrMaintenance       = errors.New("service is in maintenance")
+	ErrUnauthenticated   = errors.New("unauthenticated")
+	ErrPolicyDenied      = errors.New("denied by authorization policy")
+	ErrAnomalyDisabled   = errors.New("anomaly detection is disabled")
+	// ErrApprovalPending is returned for operations made pending by the two-man rule, they are run,
+	// when another admin approves them.
+	ErrApprovalPending  = errors.New("operation is pending approval")
+	ErrApprovalDisabled = errors.New("approval of operations is disabled")
+	ErrTenantNotFound   = errors.New("tenant overrides not found")
+	ErrBloomDisabled    = errors.New("bloom filter is disabled")
+	// ErrBloomNotBuilt is returned, until the first bloom filter is built or loaded.
+	ErrBloomNotBuilt = errors.New("bloom filter is not built yet")
+	// ErrTombstonesPruned is returned for deletions, which may be removed by the tombstone retention.
+	ErrTombstonesPruned = errors.New("tombstones are pruned")
+	ErrUsageDisabled    = errors.New("usage reporting is disabled")
+	ErrGenerateDisabled = errors.New("generation of fake users is disabled")
+	// ErrNameReserved is a validation error with its own reason code,
+	// errors.Is(ErrNameReserved, ErrValidation) is true.
+	ErrNameReserved = errors.WithMessage(ErrValidation, "name_reserved")
+	// ErrScanBudget is returned for lists estimated to read more rows than the repository allows,
+	// it is a validation error, so the caller narrows the request.
+	ErrScanBudget = errors.WithMessage(ErrValidation, "scan_budget")
 )
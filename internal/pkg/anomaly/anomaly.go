@@ -0,0 +1,258 @@
+//go:generate mockgen -source=anomaly.go -destination=./mock/anomaly_mock.go -package=mock
+
+// Package anomaly detects suspicious patterns of mutations made by one caller: mass deletes,
+// grants of privileged roles and creation bursts. Alerts are counted by metrics and optionally
+// enable the maintenance mode, so further mutations wait for an admin to confirm the alerts.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+)
+
+// Kinds of alerts.
+const (
+	// KindMassDelete is a number of users deleted by the caller.
+	KindMassDelete = "mass_delete"
+	// KindRoleEscalation is a number of privileged roles granted by the caller.
+	KindRoleEscalation = "role_escalation"
+	// KindCreateBurst is a number of users created by the caller.
+	KindCreateBurst = "create_burst"
+)
+
+// approvalReasonPrefix starts the maintenance reason set by the approval mode,
+// so the confirmation does not disable the maintenance enabled by an admin.
+const approvalReasonPrefix = "anomaly: "
+
+// approvalActor is the actor of maintenance changes made by the detector.
+const approvalActor = "anomaly-detector"
+
+// Config of the detection. Thresholds are mutations of one caller within the window,
+// zero threshold disables the kind.
+type Config struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Window  time.Duration `mapstructure:"window"`
+
+	MaxDeletes     int `mapstructure:"max_deletes"`
+	MaxEscalations int `mapstructure:"max_escalations"`
+	MaxCreates     int `mapstructure:"max_creates"`
+	// PrivilegedRoles are roles, which grants are escalations, "admin" by default.
+	PrivilegedRoles []string `mapstructure:"privileged_roles"`
+
+	// Approval enables the maintenance mode on alerts, mutations are rejected until
+	// the alerts are confirmed by AnomalyConfirm.
+	Approval bool `mapstructure:"approval"`
+}
+
+// Alert of the caller, it is raised once until it is confirmed.
+type Alert struct {
+	Kind  string
+	Actor string
+	// Count of the caller mutations of the kind within the window
+	Count    int
+	RaisedAt time.Time
+}
+
+type Interface interface {
+	// Handle counts the mutation of the user event, it is a handler of the core events.
+	Handle(ctx context.Context, event eventsPkg.Event)
+	// Alerts returns unconfirmed alerts, the earliest go first.
+	Alerts() []Alert
+	// Confirm clears the alerts and the windows of their callers. The maintenance mode
+	// enabled by the approval is disabled on behalf of the actor.
+	Confirm(ctx context.Context, actor string) ([]Alert, maintenancePkg.State, error)
+}
+
+// New returns detector of the config, mode is used by the approval.
+func New(cfg Config, mode *maintenancePkg.Mode, logger *zap.SugaredLogger) Interface {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if len(cfg.PrivilegedRoles) == 0 {
+		cfg.PrivilegedRoles = []string{"admin"}
+	}
+	privileged := make(map[string]bool, len(cfg.PrivilegedRoles))
+	for _, role := range cfg.PrivilegedRoles {
+		privileged[role] = true
+	}
+	return &detector{
+		cfg:        cfg,
+		privileged: privileged,
+		mode:       mode,
+		logger:     logger,
+		windows:    make(map[key][]time.Time),
+		alerts:     make(map[key]Alert),
+		now:        time.Now,
+	}
+}
+
+type key struct {
+	kind  string
+	actor string
+}
+
+type detector struct {
+	cfg        Config
+	privileged map[string]bool
+	mode       *maintenancePkg.Mode
+	logger     *zap.SugaredLogger
+
+	mu sync.Mutex
+	// windows keep times of the caller mutations within the window
+	windows map[key][]time.Time
+	alerts  map[key]Alert
+	// swept is the time idle windows were removed last
+	swept time.Time
+	now   func() time.Time
+}
+
+func (d *detector) Handle(ctx context.Context, event eventsPkg.Event) {
+	change := event.Base()
+	// the real caller is accountable for the changes it makes acting as others
+	actor := change.Actor
+	if change.RealActor != "" {
+		actor = change.RealActor
+	}
+
+	var kind string
+	switch e := event.(type) {
+	case eventsPkg.UserCreated:
+		if d.privileged[e.User.Attributes[models.RoleAttribute]] {
+			d.count(ctx, KindRoleEscalation, actor, d.cfg.MaxEscalations)
+		}
+		kind = KindCreateBurst
+	case eventsPkg.UserUpdated:
+		role := e.User.Attributes[models.RoleAttribute]
+		if role == e.Previous.Attributes[models.RoleAttribute] || !d.privileged[role] {
+			return
+		}
+		kind = KindRoleEscalation
+	case eventsPkg.UserDeleted:
+		kind = KindMassDelete
+	default:
+		return
+	}
+	d.count(ctx, kind, actor, d.threshold(kind))
+}
+
+func (d *detector) threshold(kind string) int {
+	switch kind {
+	case KindMassDelete:
+		return d.cfg.MaxDeletes
+	case KindRoleEscalation:
+		return d.cfg.MaxEscalations
+	default:
+		return d.cfg.MaxCreates
+	}
+}
+
+// count adds the mutation to the window of the caller and raises the alert,
+// if the window exceeds the threshold.
+func (d *detector) count(ctx context.Context, kind, actor string, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	k := key{kind: kind, actor: actor}
+	now := d.now()
+
+	d.mu.Lock()
+	d.sweep(now)
+	window := d.windows[k]
+	start := 0
+	for start < len(window) && now.Sub(window[start]) >= d.cfg.Window {
+		start++
+	}
+	window = append(window[start:], now)
+	d.windows[k] = window
+	_, raised := d.alerts[k]
+	if raised || len(window) <= threshold {
+		d.mu.Unlock()
+		return
+	}
+	alert := Alert{Kind: kind, Actor: actor, Count: len(window), RaisedAt: now}
+	d.alerts[k] = alert
+	d.mu.Unlock()
+
+	counter.AnomalyAlerts.Inc(kind)
+	counter.AnomalyActive.Add(1)
+	d.logger.Warnw("anomaly detected", "kind", kind, "actor", actor, "count", alert.Count, "window", d.cfg.Window)
+	if d.cfg.Approval {
+		d.requireApproval(ctx, alert)
+	}
+}
+
+// sweep removes windows of callers idle for the window once a window, so they are not kept forever.
+func (d *detector) sweep(now time.Time) {
+	if now.Sub(d.swept) < d.cfg.Window {
+		return
+	}
+	d.swept = now
+	for k, window := range d.windows {
+		if now.Sub(window[len(window)-1]) >= d.cfg.Window {
+			delete(d.windows, k)
+		}
+	}
+}
+
+// requireApproval enables the maintenance mode, unless it is enabled already.
+func (d *detector) requireApproval(ctx context.Context, alert Alert) {
+	if d.mode.State().Enabled {
+		return
+	}
+	reason := fmt.Sprintf("%s%s by [%s], confirm with AnomalyConfirm", approvalReasonPrefix, alert.Kind, alert.Actor)
+	if _, err := d.mode.Set(ctx, true, reason, approvalActor); err != nil {
+		d.logger.Errorw("anomaly approval", "kind", alert.Kind, "actor", alert.Actor, "error", err.Error())
+	}
+}
+
+func (d *detector) Alerts() []Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sorted()
+}
+
+func (d *detector) sorted() []Alert {
+	alerts := make([]Alert, 0, len(d.alerts))
+	for _, alert := range d.alerts {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].RaisedAt.Before(alerts[j].RaisedAt)
+	})
+	return alerts
+}
+
+func (d *detector) Confirm(ctx context.Context, actor string) ([]Alert, maintenancePkg.State, error) {
+	state := d.mode.State()
+	if state.Enabled && strings.HasPrefix(state.Reason, approvalReasonPrefix) {
+		var err error
+		if state, err = d.mode.Set(ctx, false, "", actor); err != nil {
+			return nil, maintenancePkg.State{}, errors.Wrap(err, "anomaly confirm")
+		}
+	}
+
+	d.mu.Lock()
+	confirmed := d.sorted()
+	for k := range d.alerts {
+		delete(d.windows, k)
+	}
+	d.alerts = make(map[key]Alert)
+	d.mu.Unlock()
+	counter.AnomalyActive.Add(-int64(len(confirmed)))
+	if len(confirmed) != 0 {
+		d.logger.Infow("anomaly confirmed", "alerts", len(confirmed), "actor", actor)
+	}
+	return confirmed, state, nil
+}
@@ -0,0 +1,120 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestDetector(t *testing.T) {
+	created := func(actor, realActor string, role string) eventsPkg.Event {
+		user := models.User{Name: "ivan"}
+		if role != "" {
+			user.Attributes = map[string]string{models.RoleAttribute: role}
+		}
+		return eventsPkg.UserCreated{Change: eventsPkg.Change{Actor: actor, RealActor: realActor, User: user}}
+	}
+	deleted := func(actor string) eventsPkg.Event {
+		return eventsPkg.UserDeleted{Change: eventsPkg.Change{Actor: actor}}
+	}
+	granted := func(actor, role string) eventsPkg.Event {
+		return eventsPkg.UserUpdated{
+			Change:   eventsPkg.Change{Actor: actor, User: models.User{Attributes: map[string]string{models.RoleAttribute: role}}},
+			Previous: models.User{Attributes: map[string]string{models.RoleAttribute: "member"}},
+		}
+	}
+	cfg := Config{Window: time.Minute, MaxDeletes: 2, MaxEscalations: 1, MaxCreates: 3}
+
+	cases := []struct {
+		name   string
+		events []eventsPkg.Event
+		// step is the time between the events
+		step time.Duration
+		exp  []Alert
+	}{
+		{
+			name:   "mass delete",
+			events: []eventsPkg.Event{deleted("bot"), deleted("bot"), deleted("bot"), deleted("bot")},
+			exp:    []Alert{{Kind: KindMassDelete, Actor: "bot", Count: 3}},
+		},
+		{
+			name:   "deletes of several callers",
+			events: []eventsPkg.Event{deleted("bot"), deleted("bot"), deleted("admin"), deleted("admin")},
+		},
+		{
+			name:   "deletes out of the window",
+			events: []eventsPkg.Event{deleted("bot"), deleted("bot"), deleted("bot")},
+			step:   40 * time.Second,
+		},
+		{
+			name:   "role escalations",
+			events: []eventsPkg.Event{granted("support", "member"), granted("support", "admin"), created("support", "", "admin")},
+			exp:    []Alert{{Kind: KindRoleEscalation, Actor: "support", Count: 2}},
+		},
+		{
+			name: "creation burst of the real caller",
+			events: []eventsPkg.Event{
+				created("a", "importer", ""), created("b", "importer", ""), created("c", "importer", ""), created("d", "importer", ""),
+			},
+			exp: []Alert{{Kind: KindCreateBurst, Actor: "importer", Count: 4}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := New(cfg, maintenancePkg.New(maintenancePkg.NewMemory(), loggerPkg.NewFatal()), loggerPkg.NewFatal()).(*detector)
+			now := time.Unix(1660412960, 0)
+			d.now = func() time.Time { return now }
+			for _, event := range c.events {
+				d.Handle(context.Background(), event)
+				now = now.Add(c.step)
+			}
+
+			alerts := d.Alerts()
+			for i := range alerts {
+				alerts[i].RaisedAt = time.Time{}
+			}
+			if len(c.exp) == 0 {
+				assert.Empty(t, alerts)
+				return
+			}
+			assert.Equal(t, c.exp, alerts)
+		})
+	}
+}
+
+func TestDetector_Approval(t *testing.T) {
+	ctx := context.Background()
+	mode := maintenancePkg.New(maintenancePkg.NewMemory(), loggerPkg.NewFatal())
+	d := New(Config{MaxDeletes: 1, Approval: true}, mode, loggerPkg.NewFatal())
+
+	for i := 0; i < 2; i++ {
+		d.Handle(ctx, eventsPkg.UserDeleted{Change: eventsPkg.Change{Actor: "bot", User: models.User{Name: fmt.Sprint(i)}}})
+	}
+	state := mode.State()
+	require.True(t, state.Enabled, "mutations wait for the confirmation")
+	assert.Contains(t, state.Reason, KindMassDelete)
+
+	confirmed, state, err := d.Confirm(ctx, "admin")
+	require.NoError(t, err)
+	assert.Len(t, confirmed, 1)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, "admin", state.Actor)
+	assert.Empty(t, d.Alerts())
+
+	// the maintenance of an admin is kept
+	_, err = mode.Set(ctx, true, "migration", "admin")
+	require.NoError(t, err)
+	_, state, err = d.Confirm(ctx, "admin")
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: anomaly.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	anomaly "gitlab.ozon.dev/iTukaev/homework/internal/pkg/anomaly"
+	events "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	maintenance "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Alerts mocks base method.
+func (m *MockInterface) Alerts() []anomaly.Alert {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alerts")
+	ret0, _ := ret[0].([]anomaly.Alert)
+	return ret0
+}
+
+// Alerts indicates an expected call of Alerts.
+func (mr *MockInterfaceMockRecorder) Alerts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alerts", reflect.TypeOf((*MockInterface)(nil).Alerts))
+}
+
+// Confirm mocks base method.
+func (m *MockInterface) Confirm(ctx context.Context, actor string) ([]anomaly.Alert, maintenance.State, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, actor)
+	ret0, _ := ret[0].([]anomaly.Alert)
+	ret1, _ := ret[1].(maintenance.State)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockInterfaceMockRecorder) Confirm(ctx, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockInterface)(nil).Confirm), ctx, actor)
+}
+
+// Handle mocks base method.
+func (m *MockInterface) Handle(ctx context.Context, event events.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Handle", ctx, event)
+}
+
+// Handle indicates an expected call of Handle.
+func (mr *MockInterfaceMockRecorder) Handle(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockInterface)(nil).Handle), ctx, event)
+}
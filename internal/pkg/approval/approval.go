@@ -0,0 +1,278 @@
+//go:generate mockgen -source=approval.go -destination=./mock/approval_mock.go -package=mock
+
+// Package approval enforces the two-man rule of destructive operations: deletes of a caller
+// and mass updates beyond the thresholds become pending operations, which another admin approves.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+// Kinds of operations.
+const (
+	KindUserDelete     = "user_delete"
+	KindPasswordExpire = "password_expire"
+)
+
+const (
+	operationPrefix = "operation_"
+	deletesPrefix   = "approval_deletes_"
+
+	defaultWindow = time.Hour
+	defaultTTL    = 24 * time.Hour
+	// scanCount is a hint of keys scanned by a call of the list
+	scanCount = 100
+)
+
+var (
+	// ErrNotFound is returned for unknown, expired and already approved operations.
+	ErrNotFound = errors.New("operation not found")
+	// ErrSelfApproval is returned, if the approver is the requester of the operation.
+	ErrSelfApproval = errors.New("operation is approved by its requester")
+)
+
+// Config of the approval. Zero threshold leaves the operations of the kind without approval.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DeleteThreshold is the number of deletes of one caller within the window made without approval.
+	DeleteThreshold int           `mapstructure:"delete_threshold"`
+	Window          time.Duration `mapstructure:"window"`
+	// MassThreshold is the number of users a mass update changes without approval.
+	MassThreshold int `mapstructure:"mass_threshold"`
+	// TTL of pending operations, they are dropped, if nobody approves them in time.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// Operation waiting for approval.
+type Operation struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Requester is the real identity of the caller, impersonation does not change it.
+	Requester  string            `json:"requester"`
+	Names      []string          `json:"names,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	CreatedAt  int64             `json:"created_at"`
+	ExpiresAt  int64             `json:"expires_at"`
+}
+
+// pendingError tells the ID of the pending operation.
+type pendingError struct {
+	id string
+}
+
+func (e *pendingError) Error() string {
+	return fmt.Sprintf("operation [%s] is pending approval", e.id)
+}
+
+func (e *pendingError) Unwrap() error {
+	return errorsPkg.ErrApprovalPending
+}
+
+// PendingID returns the ID of the operation, which the error made pending.
+func PendingID(err error) (string, bool) {
+	var pending *pendingError
+	if errors.As(err, &pending) {
+		return pending.id, true
+	}
+	return "", false
+}
+
+type Interface interface {
+	// Pending returns operations waiting for approval, the earliest go first.
+	Pending(ctx context.Context) ([]Operation, error)
+	// Approve runs the pending operation on behalf of the approver. Number of expired
+	// passwords is returned for the mass update.
+	Approve(ctx context.Context, id string) (Operation, uint64, error)
+}
+
+// New returns the core, which makes pending operations of deletes and mass updates beyond
+// the thresholds, and approvals of them. Approved operations are run by user.
+func New(cfg Config, user userPkg.Interface, client *redis.Client, logger *zap.SugaredLogger) (userPkg.Interface, Interface) {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultWindow
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	g := &gate{
+		Interface: user,
+		cfg:       cfg,
+		cache:     client,
+		logger:    logger,
+	}
+	return g, g
+}
+
+// gate passes all calls to the core except deletes and mass updates beyond the thresholds.
+type gate struct {
+	userPkg.Interface
+	cfg    Config
+	cache  *redis.Client
+	logger *zap.SugaredLogger
+}
+
+func (g *gate) Delete(ctx context.Context, name string) error {
+	if g.cfg.DeleteThreshold <= 0 {
+		return g.Interface.Delete(ctx, name)
+	}
+	requester := grpcPkg.IdentityFromContext(ctx).Real
+	key := deletesPrefix + requester
+	deletes, err := g.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return apperr.WrapKey(err, "approval.Delete", "name", name)
+	}
+	if deletes == 1 {
+		if err = g.cache.Expire(ctx, key, g.cfg.Window).Err(); err != nil {
+			g.logger.Errorf("expire approval deletes: %v", err)
+		}
+	}
+	if deletes <= int64(g.cfg.DeleteThreshold) {
+		return g.Interface.Delete(ctx, name)
+	}
+	return apperr.WrapKey(g.hold(ctx, Operation{Kind: KindUserDelete, Requester: requester, Names: []string{name}}),
+		"approval.Delete", "name", name)
+}
+
+func (g *gate) ExpirePasswords(ctx context.Context, names []string, attributes map[string]string) (uint64, error) {
+	if g.cfg.MassThreshold <= 0 {
+		return g.Interface.ExpirePasswords(ctx, names, attributes)
+	}
+	affected := len(names)
+	if len(names) == 0 {
+		// a page over the threshold is enough to tell the cohort exceeds it
+		users, err := g.Interface.List(ctx, false, uint64(g.cfg.MassThreshold)+1, 0, attributes, "")
+		if err != nil {
+			return 0, apperr.Wrap(err, "approval.ExpirePasswords")
+		}
+		affected = len(users)
+	}
+	if affected <= g.cfg.MassThreshold {
+		return g.Interface.ExpirePasswords(ctx, names, attributes)
+	}
+	requester := grpcPkg.IdentityFromContext(ctx).Real
+	return 0, apperr.Wrap(g.hold(ctx, Operation{Kind: KindPasswordExpire, Requester: requester, Names: names, Attributes: attributes}),
+		"approval.ExpirePasswords")
+}
+
+// hold stores the operation and returns the error of its ID.
+func (g *gate) hold(ctx context.Context, op Operation) error {
+	now := time.Now()
+	op.ID = uuid.New().String()
+	op.CreatedAt, op.ExpiresAt = now.Unix(), now.Add(g.cfg.TTL).Unix()
+	data, err := json.Marshal(op)
+	if err != nil {
+		return errors.Wrap(err, "marshal operation")
+	}
+	if err = g.cache.Set(ctx, operationPrefix+op.ID, data, g.cfg.TTL).Err(); err != nil {
+		return errors.Wrap(err, "store operation")
+	}
+	g.logger.Warnw("operation pending approval", "id", op.ID, "kind", op.Kind, "requester", op.Requester,
+		"names", op.Names, "attributes", op.Attributes)
+	return &pendingError{id: op.ID}
+}
+
+func (g *gate) Pending(ctx context.Context) ([]Operation, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	for {
+		page, next, err := g.cache.Scan(ctx, cursor, operationPrefix+"*", scanCount).Result()
+		if err != nil {
+			return nil, apperr.Wrap(err, "approval.Pending")
+		}
+		keys = append(keys, page...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return []Operation{}, nil
+	}
+
+	values, err := g.cache.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, apperr.Wrap(err, "approval.Pending")
+	}
+	ops := make([]Operation, 0, len(values))
+	for _, value := range values {
+		// the operation expired or approved after the scan
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var op Operation
+		if err = json.Unmarshal([]byte(data), &op); err != nil {
+			return nil, apperr.Wrap(errors.Wrap(err, "unmarshal operation"), "approval.Pending")
+		}
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].CreatedAt < ops[j].CreatedAt
+	})
+	return ops, nil
+}
+
+func (g *gate) Approve(ctx context.Context, id string) (Operation, uint64, error) {
+	approver := grpcPkg.IdentityFromContext(ctx).Real
+	if approver == "" {
+		return Operation{}, 0, apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation, "approver meta is empty"),
+			"approval.Approve", "id", id)
+	}
+	key := operationPrefix + id
+	data, err := g.cache.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = ErrNotFound
+		}
+		return Operation{}, 0, apperr.WrapKey(err, "approval.Approve", "id", id)
+	}
+	var op Operation
+	if err = json.Unmarshal(data, &op); err != nil {
+		return Operation{}, 0, apperr.WrapKey(errors.Wrap(err, "unmarshal operation"), "approval.Approve", "id", id)
+	}
+	if op.Requester == approver {
+		return Operation{}, 0, apperr.WrapKey(ErrSelfApproval, "approval.Approve", "id", id)
+	}
+	// the operation is claimed by the removal, so concurrent approvals run it once
+	deleted, err := g.cache.Del(ctx, key).Result()
+	if err != nil {
+		return Operation{}, 0, apperr.WrapKey(err, "approval.Approve", "id", id)
+	}
+	if deleted == 0 {
+		return Operation{}, 0, apperr.WrapKey(ErrNotFound, "approval.Approve", "id", id)
+	}
+	g.logger.Infow("operation approved", "id", op.ID, "kind", op.Kind, "requester", op.Requester, "approver", approver)
+
+	var expired uint64
+	switch op.Kind {
+	case KindUserDelete:
+		for _, name := range op.Names {
+			if err = g.Interface.Delete(ctx, name); err != nil {
+				break
+			}
+		}
+	case KindPasswordExpire:
+		expired, err = g.Interface.ExpirePasswords(ctx, op.Names, op.Attributes)
+	default:
+		err = errors.Wrapf(errorsPkg.ErrUnexpected, "unknown kind [%s]", op.Kind)
+	}
+	if err != nil {
+		return op, expired, apperr.WrapKey(err, "approval.Approve", "id", id)
+	}
+	return op, expired, nil
+}
@@ -0,0 +1,121 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func Test_Delete(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	cfg := Config{Enabled: true, DeleteThreshold: 2}
+
+	cases := []struct {
+		name       string
+		deletes    int64
+		expDeleted bool
+		expPending bool
+	}{
+		{
+			name:       "first delete of the window",
+			deletes:    1,
+			expDeleted: true,
+		},
+		{
+			name:       "delete within the threshold",
+			deletes:    2,
+			expDeleted: true,
+		},
+		{
+			name:       "delete over the threshold",
+			deletes:    3,
+			expPending: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := grpcPkg.WithIdentity(context.Background(), grpcPkg.Identity{Real: "bot", Effective: "bot"})
+			client, mock := redismock.NewClientMock()
+			mock.ExpectIncr(deletesPrefix + "bot").SetVal(c.deletes)
+			if c.deletes == 1 {
+				mock.ExpectExpire(deletesPrefix+"bot", defaultWindow).SetVal(true)
+			}
+			if c.expPending {
+				mock.Regexp().ExpectSet(operationPrefix+".+", ".+", defaultTTL).SetVal("OK")
+			}
+			user := userMockPkg.NewMockInterface(ctl)
+			if c.expDeleted {
+				user.EXPECT().Delete(gomock.Any(), "ivan").Return(nil).Times(1)
+			}
+			gated, _ := New(cfg, user, client, loggerPkg.NewFatal())
+
+			err := gated.Delete(ctx, "ivan")
+			if c.expPending {
+				assert.ErrorIs(t, err, errorsPkg.ErrApprovalPending)
+				id, ok := PendingID(err)
+				assert.True(t, ok)
+				assert.NotEmpty(t, id)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Approve(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	op := Operation{ID: "42", Kind: KindUserDelete, Requester: "bot", Names: []string{"ivan"}}
+	data, err := json.Marshal(op)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		approver string
+		expErr   error
+	}{
+		{
+			name:     "approved by another admin",
+			approver: "admin",
+		},
+		{
+			name:     "approved by the requester",
+			approver: "bot",
+			expErr:   ErrSelfApproval,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := grpcPkg.WithIdentity(context.Background(), grpcPkg.Identity{Real: c.approver, Effective: c.approver})
+			client, mock := redismock.NewClientMock()
+			mock.ExpectGet(operationPrefix + "42").SetVal(string(data))
+			user := userMockPkg.NewMockInterface(ctl)
+			if c.expErr == nil {
+				mock.ExpectDel(operationPrefix + "42").SetVal(1)
+				user.EXPECT().Delete(gomock.Any(), "ivan").Return(nil).Times(1)
+			}
+			_, approval := New(Config{Enabled: true, DeleteThreshold: 1}, user, client, loggerPkg.NewFatal())
+
+			approved, _, err := approval.Approve(ctx, "42")
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, op, approved)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,350 @@
+// Package authz authorizes calls by declarative policies: ordered rules of CEL expressions
+// evaluated against the method, the caller and the target user of the request. The policies
+// file is reloaded, when it changes, and decisions are written to the decision log for audits.
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+// Effects of rules.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// defaultRule is the rule name of decisions made by the default effect.
+const defaultRule = "default"
+
+const defaultReloadInterval = 10 * time.Second
+
+// Config of the authorization.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// File with the policies in YAML, see Policies.
+	File string `mapstructure:"file"`
+	// ReloadInterval is an interval of checking the file for changes.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	// DecisionLog is a file, which receives all decisions as JSON lines, it is appended.
+	// Empty value logs denied calls only.
+	DecisionLog string `mapstructure:"decision_log"`
+}
+
+// Policies of the file. Rules are evaluated in order, the first rule, which condition is true,
+// decides the call. Calls matching no rule get the default effect.
+type Policies struct {
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule of the policies. When is a CEL expression returning bool, its variables are
+// the fields of Input: method, actor, act_as, role, target and tenant.
+type Rule struct {
+	Name   string `yaml:"name"`
+	Effect string `yaml:"effect"`
+	When   string `yaml:"when"`
+}
+
+// Input of the rules.
+type Input struct {
+	// Method is the full name of the called method.
+	Method string `json:"method"`
+	// Actor is the request meta of the caller.
+	Actor string `json:"actor"`
+	// ActAs is the identity the caller acts as, it is empty without impersonation.
+	ActAs string `json:"act_as,omitempty"`
+	// Role of the caller resolved by its role token.
+	Role string `json:"role"`
+	// Target is the name of the user the request is about, Tenant is its tenant attribute
+	// sent with the request. They are empty for streams and requests without them.
+	Target string `json:"target,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Decision of the call written to the decision log.
+type Decision struct {
+	Input
+	Effect string `json:"effect"`
+	// Rule is the name of the deciding rule or "default".
+	Rule string `json:"rule"`
+	// TS is a time of the decision in UNIX milliseconds.
+	TS int64 `json:"ts"`
+}
+
+// Engine evaluates the policies of the file.
+type Engine struct {
+	cfg    Config
+	roles  *grpcPkg.Fields
+	logger *zap.SugaredLogger
+	env    *cel.Env
+
+	mu       sync.RWMutex
+	policies compiled
+	modTime  time.Time
+
+	logMu sync.Mutex
+	log   *os.File
+}
+
+type compiled struct {
+	allow bool
+	rules []rule
+}
+
+type rule struct {
+	name    string
+	allow   bool
+	program cel.Program
+}
+
+// New returns engine of the policies file, roles resolve roles of callers.
+func New(cfg Config, roles *grpcPkg.Fields, logger *zap.SugaredLogger) (*Engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("method", cel.StringType),
+		cel.Variable("actor", cel.StringType),
+		cel.Variable("act_as", cel.StringType),
+		cel.Variable("role", cel.StringType),
+		cel.Variable("target", cel.StringType),
+		cel.Variable("tenant", cel.StringType),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "authz: new environment")
+	}
+	e := &Engine{
+		cfg:    cfg,
+		roles:  roles,
+		logger: logger,
+		env:    env,
+	}
+	if err = e.Reload(); err != nil {
+		return nil, err
+	}
+	if cfg.DecisionLog != "" {
+		if e.log, err = os.OpenFile(cfg.DecisionLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err != nil {
+			return nil, errors.Wrap(err, "authz: open decision log")
+		}
+	}
+	return e, nil
+}
+
+// Reload reads the policies file, if it is changed. Invalid policies are rejected,
+// the engine keeps the previous ones.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.cfg.File)
+	if err != nil {
+		return errors.Wrap(err, "authz: stat policies")
+	}
+	e.mu.RLock()
+	unchanged := info.ModTime().Equal(e.modTime)
+	e.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(e.cfg.File)
+	if err != nil {
+		return errors.Wrap(err, "authz: read policies")
+	}
+	var policies Policies
+	if err = yaml.Unmarshal(data, &policies); err != nil {
+		return errors.Wrap(err, "authz: parse policies")
+	}
+	c, err := e.compile(policies)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.policies, e.modTime = c, info.ModTime()
+	e.mu.Unlock()
+	e.logger.Infow("authorization policies loaded", "file", e.cfg.File, "rules", len(c.rules), "default", policies.Default)
+	return nil
+}
+
+func (e *Engine) compile(policies Policies) (compiled, error) {
+	allow, err := allowed(policies.Default)
+	if err != nil {
+		return compiled{}, errors.Wrap(err, "authz: default")
+	}
+	c := compiled{allow: allow, rules: make([]rule, 0, len(policies.Rules))}
+	for i, r := range policies.Rules {
+		if r.Name == "" {
+			return compiled{}, errors.Wrapf(errorsPkg.ErrValidation, "authz: rule %d: field: [name] cannot be empty", i)
+		}
+		allow, err := allowed(r.Effect)
+		if err != nil {
+			return compiled{}, errors.Wrapf(err, "authz: rule [%s]", r.Name)
+		}
+		ast, issues := e.env.Compile(r.When)
+		if issues != nil && issues.Err() != nil {
+			return compiled{}, errors.Wrapf(issues.Err(), "authz: rule [%s]", r.Name)
+		}
+		if ast.OutputType() != cel.BoolType {
+			return compiled{}, errors.Wrapf(errorsPkg.ErrValidation, "authz: rule [%s]: field: [when] must be bool, not %s",
+				r.Name, ast.OutputType())
+		}
+		program, err := e.env.Program(ast)
+		if err != nil {
+			return compiled{}, errors.Wrapf(err, "authz: rule [%s]", r.Name)
+		}
+		c.rules = append(c.rules, rule{name: r.Name, allow: allow, program: program})
+	}
+	return c, nil
+}
+
+func allowed(effect string) (bool, error) {
+	switch effect {
+	case EffectAllow:
+		return true, nil
+	case EffectDeny:
+		return false, nil
+	}
+	return false, errors.Wrapf(errorsPkg.ErrValidation, "effect [%s] must be allow or deny", effect)
+}
+
+// Run reloads the changed policies until ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	interval := e.cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Reload(); err != nil {
+				e.logger.Errorln(err)
+			}
+		}
+	}
+}
+
+// Authorize returns PermissionDenied with reason POLICY_DENIED, if the policies deny the call.
+// The request is nil for streams.
+func (e *Engine) Authorize(ctx context.Context, method string, req interface{}) error {
+	identity := grpcPkg.IdentityFromContext(ctx)
+	input := Input{
+		Method: method,
+		Actor:  identity.Real,
+		Role:   e.roles.Role(ctx),
+	}
+	if identity.Impersonated() {
+		input.ActAs = identity.Effective
+	}
+	if msg, ok := req.(proto.Message); ok {
+		input.Target, input.Tenant = target(msg.ProtoReflect())
+	}
+
+	decision := e.Decide(input)
+	counter.AuthzDecisions.Inc(decision.Effect)
+	e.record(decision)
+	if decision.Effect == EffectAllow {
+		return nil
+	}
+	return apperr.StatusReason(codes.PermissionDenied, apperr.ReasonPolicyDenied,
+		errors.Wrapf(errorsPkg.ErrPolicyDenied, "rule [%s]", decision.Rule))
+}
+
+// Decide evaluates the rules. The rule failed to evaluate denies the call, so errors
+// of the policies do not open access.
+func (e *Engine) Decide(input Input) Decision {
+	e.mu.RLock()
+	policies := e.policies
+	e.mu.RUnlock()
+
+	decision := Decision{Input: input, Rule: defaultRule, TS: time.Now().UnixMilli()}
+	vars := map[string]interface{}{
+		"method": input.Method,
+		"actor":  input.Actor,
+		"act_as": input.ActAs,
+		"role":   input.Role,
+		"target": input.Target,
+		"tenant": input.Tenant,
+	}
+	allow := policies.allow
+	for _, r := range policies.rules {
+		out, _, err := r.program.Eval(vars)
+		if err != nil {
+			e.logger.Errorw("authorization rule", "rule", r.name, "method", input.Method, "error", err.Error())
+			allow, decision.Rule = false, r.name
+			break
+		}
+		if matched, _ := out.Value().(bool); matched {
+			allow, decision.Rule = r.allow, r.name
+			break
+		}
+	}
+	decision.Effect = EffectDeny
+	if allow {
+		decision.Effect = EffectAllow
+	}
+	return decision
+}
+
+// record writes the decision to the decision log, denied calls are logged without it.
+func (e *Engine) record(decision Decision) {
+	if e.log == nil {
+		if decision.Effect == EffectDeny {
+			e.logger.Warnw("call denied by policy", "rule", decision.Rule, "method", decision.Method,
+				"actor", decision.Actor, "act_as", decision.ActAs, "role", decision.Role, "target", decision.Target)
+		}
+		return
+	}
+	line, err := json.Marshal(decision)
+	if err != nil {
+		e.logger.Errorw("record decision", "method", decision.Method, "error", err.Error())
+		return
+	}
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+	if _, err = e.log.Write(append(line, '\n')); err != nil {
+		e.logger.Errorw("record decision", "method", decision.Method, "error", err.Error())
+	}
+}
+
+// Close closes the decision log.
+func (e *Engine) Close() error {
+	if e.log == nil {
+		return nil
+	}
+	return e.log.Close()
+}
+
+// target returns the name and the tenant attribute of the user of the request,
+// which is the request itself or its user field.
+func target(m protoreflect.Message) (name, tenant string) {
+	fields := m.Descriptor().Fields()
+	if fd := fields.ByName("user"); fd != nil && fd.Kind() == protoreflect.MessageKind && m.Has(fd) {
+		return target(m.Get(fd).Message())
+	}
+	if fd := fields.ByName("name"); fd != nil && fd.Kind() == protoreflect.StringKind {
+		name = m.Get(fd).String()
+	}
+	if fd := fields.ByName("attributes"); fd != nil && fd.IsMap() {
+		value := m.Get(fd).Map().Get(protoreflect.ValueOfString(passwordPkg.TenantAttribute).MapKey())
+		if value.IsValid() {
+			tenant = value.String()
+		}
+	}
+	return name, tenant
+}
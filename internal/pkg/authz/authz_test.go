@@ -0,0 +1,182 @@
+package authz
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const policies = `
+default: allow
+rules:
+  - name: admin-api
+    effect: deny
+    when: method.startsWith("/gitlab.ozon.dev.iTukaev.homework.api.Admin/") && role != "admin"
+  - name: impersonation
+    effect: deny
+    when: act_as != "" && role != "support"
+  - name: staff-tenant
+    effect: deny
+    when: tenant == "staff" && role != "admin"
+`
+
+func newEngine(t *testing.T, cfg Config) *Engine {
+	t.Helper()
+	roles, err := grpcPkg.NewFields(grpcPkg.FieldsConfig{
+		DefaultRole: "public",
+		Tokens:      map[string]string{"admin": "root", "support": "secret"},
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	cfg.File = filepath.Join(dir, "authz.yaml")
+	require.NoError(t, os.WriteFile(cfg.File, []byte(policies), 0o600))
+	engine, err := New(cfg, roles, loggerPkg.NewFatal())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, engine.Close())
+	})
+	return engine
+}
+
+func TestEngine_Authorize(t *testing.T) {
+	decisionLog := filepath.Join(t.TempDir(), "decisions.log")
+	engine := newEngine(t, Config{DecisionLog: decisionLog})
+
+	cases := []struct {
+		name    string
+		method  string
+		md      metadata.MD
+		req     interface{}
+		expRule string
+	}{
+		{
+			name:    "success, default",
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.User/UserDelete",
+			md:      metadata.Pairs("meta", "ivan"),
+			req:     &pb.UserDeleteRequest{Name: "ivan"},
+			expRule: defaultRule,
+		},
+		{
+			name:    "success, admin method by admin",
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistAdd",
+			md:      metadata.Pairs("meta", "ops", grpcPkg.RoleTokenMetaKey, "root"),
+			expRule: defaultRule,
+		},
+		{
+			name:    "failed, admin method by other role",
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistAdd",
+			md:      metadata.Pairs("meta", "ivan"),
+			expRule: "admin-api",
+		},
+		{
+			name:    "failed, tenant of the request user",
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.User/UserCreate",
+			md:      metadata.Pairs("meta", "ivan", grpcPkg.RoleTokenMetaKey, "secret"),
+			req:     &pb.UserCreateRequest{User: &pbModels.User{Name: "petr", Attributes: map[string]string{"tenant": "staff"}}},
+			expRule: "staff-tenant",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), c.md)
+			err := engine.Authorize(ctx, c.method, c.req)
+			if c.expRule == defaultRule {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			st, _ := status.FromError(err)
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, apperr.ReasonPolicyDenied, info.GetReason())
+		})
+	}
+
+	// every decision is written to the log with the deciding rule
+	file, err := os.Open(decisionLog)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var rules []string
+	for scanner.Scan() {
+		var decision Decision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &decision))
+		rules = append(rules, decision.Rule)
+	}
+	require.Len(t, rules, len(cases))
+	for i, c := range cases {
+		assert.Equal(t, c.expRule, rules[i])
+	}
+}
+
+func TestEngine_Decide(t *testing.T) {
+	engine := newEngine(t, Config{})
+
+	cases := []struct {
+		name      string
+		input     Input
+		expEffect string
+		expRule   string
+	}{
+		{
+			name:      "act-as by support",
+			input:     Input{Method: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet", Actor: "agent", ActAs: "ivan", Role: "support"},
+			expEffect: EffectAllow,
+			expRule:   defaultRule,
+		},
+		{
+			name:      "act-as by other role",
+			input:     Input{Method: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet", Actor: "agent", ActAs: "ivan", Role: "public"},
+			expEffect: EffectDeny,
+			expRule:   "impersonation",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decision := engine.Decide(c.input)
+			assert.Equal(t, c.expEffect, decision.Effect)
+			assert.Equal(t, c.expRule, decision.Rule)
+		})
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	engine := newEngine(t, Config{})
+	input := Input{Method: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistAdd", Role: "public"}
+
+	// the rule of a non-bool expression is rejected, the previous policies are kept
+	invalid := "default: allow\nrules:\n  - name: broken\n    effect: deny\n    when: method\n"
+	require.NoError(t, os.WriteFile(engine.cfg.File, []byte(invalid), 0o600))
+	require.NoError(t, os.Chtimes(engine.cfg.File, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(t, engine.Reload())
+	assert.Equal(t, "admin-api", engine.Decide(input).Rule)
+
+	valid := "default: deny\n"
+	require.NoError(t, os.WriteFile(engine.cfg.File, []byte(valid), 0o600))
+	require.NoError(t, os.Chtimes(engine.cfg.File, time.Now(), time.Now().Add(2*time.Minute)))
+	require.NoError(t, engine.Reload())
+	decision := engine.Decide(input)
+	assert.Equal(t, EffectDeny, decision.Effect)
+	assert.Equal(t, defaultRule, decision.Rule)
+}
@@ -0,0 +1,74 @@
+package avatar
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+	localBlobPkg "gitlab.ozon.dev/iTukaev/homework/pkg/blob/local"
+	s3BlobPkg "gitlab.ozon.dev/iTukaev/homework/pkg/blob/s3"
+)
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+
+	defaultMaxSize = 1 << 20
+)
+
+var allowedTypes = map[string]struct{}{
+	"image/png":  {},
+	"image/jpeg": {},
+	"image/gif":  {},
+	"image/webp": {},
+}
+
+type Config struct {
+	// Storage is a storage type: local or s3, empty value disables avatars.
+	Storage string `mapstructure:"storage"`
+	MaxSize int64  `mapstructure:"max_size"`
+	// PublicURL is a base URL of stored objects,
+	// if empty, avatar URL points to UserAvatarGet HTTP endpoint.
+	PublicURL string              `mapstructure:"public_url"`
+	Local     localBlobPkg.Config `mapstructure:"local"`
+	S3        s3BlobPkg.Config    `mapstructure:"s3"`
+}
+
+// NewStorage returns storage by config or nil, if avatars are disabled.
+func NewStorage(cfg Config) (blob.Storage, error) {
+	switch cfg.Storage {
+	case "":
+		return nil, nil
+	case StorageLocal:
+		return localBlobPkg.New(cfg.Local)
+	case StorageS3:
+		return s3BlobPkg.New(cfg.S3), nil
+	default:
+		return nil, errors.Errorf("unknown avatar storage: [%s]", cfg.Storage)
+	}
+}
+
+// Validate returns content type of the image or ErrValidation,
+// if the image is too big or has unsupported type.
+func Validate(cfg Config, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.Wrap(errorsPkg.ErrValidation, "field: [avatar] cannot be empty")
+	}
+	if int64(len(data)) > MaxSize(cfg) {
+		return "", errors.Wrapf(errorsPkg.ErrValidation, "field: [avatar] is bigger than %d bytes", MaxSize(cfg))
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := allowedTypes[contentType]; !ok {
+		return "", errors.Wrapf(errorsPkg.ErrValidation, "field: [avatar] unsupported type: [%s]", contentType)
+	}
+	return contentType, nil
+}
+
+func MaxSize(cfg Config) int64 {
+	if cfg.MaxSize > 0 {
+		return cfg.MaxSize
+	}
+	return defaultMaxSize
+}
@@ -0,0 +1,50 @@
+package avatar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func Test_Validate(t *testing.T) {
+	cfg := Config{MaxSize: 16}
+
+	cases := []struct {
+		name    string
+		data    []byte
+		expType string
+		expErr  error
+	}{
+		{
+			name:    "success, png",
+			data:    []byte("\x89PNG\r\n\x1a\n0000"),
+			expType: "image/png",
+			expErr:  nil,
+		},
+		{
+			name:   "failed, empty",
+			data:   nil,
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, too big",
+			data:   []byte("\x89PNG\r\n\x1a\n000000000000"),
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, not an image",
+			data:   []byte("plain text"),
+			expErr: errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			contentType, err := Validate(cfg, c.data)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expType, contentType)
+		})
+	}
+}
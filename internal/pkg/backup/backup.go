@@ -0,0 +1,410 @@
+//go:generate mockgen -source=backup.go -destination=./mock/backup_mock.go -package=mock
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+	localBlobPkg "gitlab.ozon.dev/iTukaev/homework/pkg/blob/local"
+	s3BlobPkg "gitlab.ozon.dev/iTukaev/homework/pkg/blob/s3"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+
+	version     = 1
+	contentType = "application/gzip"
+	// maxLine limits size of a single user in the backup
+	maxLine = 1 << 20
+)
+
+var (
+	ErrStorageDisabled = errors.New("backup storage is disabled")
+	// ErrCorrupted is returned for backups, which fail format or checksum verification.
+	ErrCorrupted = errors.New("backup is corrupted")
+)
+
+// Config of the backup storage, backups are streamed to clients only, if storage is empty.
+type Config struct {
+	// Storage is a storage type: local or s3.
+	Storage string              `mapstructure:"storage"`
+	Local   localBlobPkg.Config `mapstructure:"local"`
+	S3      s3BlobPkg.Config    `mapstructure:"s3"`
+}
+
+// NewStorage returns storage by config or nil, if it is disabled.
+func NewStorage(cfg Config) (blob.Storage, error) {
+	switch cfg.Storage {
+	case "":
+		return nil, nil
+	case StorageLocal:
+		return localBlobPkg.New(cfg.Local)
+	case StorageS3:
+		return s3BlobPkg.New(cfg.S3), nil
+	default:
+		return nil, errors.Errorf("unknown backup storage: [%s]", cfg.Storage)
+	}
+}
+
+// Summary describes the written backup. Checksum is SHA-256 of the compressed backup.
+type Summary struct {
+	Key       string    `json:"key,omitempty"`
+	Users     uint64    `json:"users"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Result of the restore, failed list contains user names with errors.
+type Result struct {
+	Users       uint64   `json:"users"`
+	Created     uint64   `json:"created"`
+	Overwritten uint64   `json:"overwritten"`
+	Skipped     uint64   `json:"skipped"`
+	Failed      []string `json:"failed,omitempty"`
+}
+
+type Interface interface {
+	// Create writes compressed snapshot of all users to w. The snapshot is spooled to a temporary
+	// file first, so its transaction is finished before w receives it.
+	Create(ctx context.Context, w io.Writer) (Summary, error)
+	// Store writes compressed snapshot of all users to the storage by the key.
+	Store(ctx context.Context, key string) (Summary, error)
+	// Restore verifies the whole backup read from r and then restores its users. The backup is
+	// spooled to a temporary file, so users are not held in memory.
+	// The backup is compared with checksum of Summary, if it is not empty.
+	Restore(ctx context.Context, r io.Reader, checksum string, overwrite bool) (Result, error)
+	// Load restores backup from the storage by the key.
+	Load(ctx context.Context, key, checksum string, overwrite bool) (Result, error)
+}
+
+// New returns backup service, storage may be nil.
+func New(data repoPkg.Interface, user userPkg.Interface, storage blob.Storage, logger *zap.SugaredLogger) Interface {
+	return &service{
+		data:    data,
+		user:    user,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+type service struct {
+	data    repoPkg.Interface
+	user    userPkg.Interface
+	storage blob.Storage
+	logger  *zap.SugaredLogger
+}
+
+// header is the first line of the backup.
+type header struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// footer is the last line of the backup, Checksum is SHA-256 of the user lines.
+type footer struct {
+	Users    uint64 `json:"users"`
+	Checksum string `json:"checksum"`
+}
+
+func (s *service) Create(ctx context.Context, w io.Writer) (Summary, error) {
+	file, summary, err := s.spool(ctx)
+	if err != nil {
+		return Summary{}, errors.Wrap(err, "backup create")
+	}
+	defer remove(file)
+
+	if _, err = io.Copy(w, file); err != nil {
+		return Summary{}, errors.Wrap(err, "backup create: send")
+	}
+	s.logger.Infow("backup created", "users", summary.Users, "size", summary.Size, "checksum", summary.Checksum,
+		"meta", grpcPkg.GetMetaFromContext(ctx))
+	return summary, nil
+}
+
+func (s *service) Store(ctx context.Context, key string) (Summary, error) {
+	if s.storage == nil {
+		return Summary{}, ErrStorageDisabled
+	}
+	if key == "" {
+		key = fmt.Sprintf("users-%s.jsonl.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+	file, summary, err := s.spool(ctx)
+	if err != nil {
+		return Summary{}, errors.Wrap(err, "backup store")
+	}
+	defer remove(file)
+
+	if streamer, ok := s.storage.(blob.Streamer); ok {
+		err = streamer.PutStream(ctx, key, contentType, file, summary.Size)
+	} else {
+		var data []byte
+		if data, err = io.ReadAll(file); err == nil {
+			err = s.storage.Put(ctx, key, contentType, data)
+		}
+	}
+	if err != nil {
+		return Summary{}, errors.Wrap(err, "backup store")
+	}
+	summary.Key = key
+	s.logger.Infow("backup stored", "key", key, "users", summary.Users, "size", summary.Size,
+		"checksum", summary.Checksum, "meta", grpcPkg.GetMetaFromContext(ctx))
+	return summary, nil
+}
+
+// spool writes the snapshot to a temporary file, which is returned at its start. The snapshot
+// transaction is finished before the backup is sent, so slow receivers don't hold it open.
+func (s *service) spool(ctx context.Context) (*os.File, Summary, error) {
+	file, err := os.CreateTemp("", "backup-*.jsonl.gz")
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	summary, err := s.write(ctx, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		remove(file)
+		return nil, Summary{}, err
+	}
+	return file, summary, nil
+}
+
+// write writes compressed snapshot of all users to w. Passwords stored before they were hashed
+// are hashed, so backups don't contain plain passwords.
+func (s *service) write(ctx context.Context, w io.Writer) (Summary, error) {
+	summary := Summary{CreatedAt: time.Now().UTC()}
+	checksum := sha256.New()
+	counter := &countWriter{w: io.MultiWriter(w, checksum)}
+	zw := gzip.NewWriter(counter)
+
+	lines := sha256.New()
+	encoder := json.NewEncoder(io.MultiWriter(zw, lines))
+	if err := json.NewEncoder(zw).Encode(header{Version: version, CreatedAt: summary.CreatedAt}); err != nil {
+		return Summary{}, err
+	}
+	err := s.data.UserSnapshot(ctx, func(user models.User) error {
+		user.AvatarURL = ""
+		if user.Password != "" && !passwordPkg.Hashed(user.Password) {
+			var err error
+			if user.Password, err = passwordPkg.Hash(user.Password); err != nil {
+				return err
+			}
+		}
+		summary.Users++
+		return encoder.Encode(user)
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+	if err = json.NewEncoder(zw).Encode(footer{Users: summary.Users, Checksum: sum(lines)}); err != nil {
+		return Summary{}, err
+	}
+	if err = zw.Close(); err != nil {
+		return Summary{}, err
+	}
+	summary.Size, summary.Checksum = counter.n, sum(checksum)
+	return summary, nil
+}
+
+func (s *service) Restore(ctx context.Context, r io.Reader, checksum string, overwrite bool) (Result, error) {
+	file, users, err := verify(r, checksum)
+	if err != nil {
+		return Result{}, err
+	}
+	defer remove(file)
+
+	result := Result{Users: users}
+	err = decode(file, users, func(user models.User) error {
+		status, err := s.user.Restore(ctx, user, overwrite)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", user.Name, err))
+		case status == models.ImportCreated:
+			result.Created++
+		case status == models.ImportOverwritten:
+			result.Overwritten++
+		default:
+			result.Skipped++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "backup restore")
+	}
+	s.logger.Infow("backup restored", "users", result.Users, "created", result.Created,
+		"overwritten", result.Overwritten, "skipped", result.Skipped, "failed", result.Failed,
+		"meta", grpcPkg.GetMetaFromContext(ctx))
+	return result, nil
+}
+
+func (s *service) Load(ctx context.Context, key, checksum string, overwrite bool) (Result, error) {
+	if s.storage == nil {
+		return Result{}, ErrStorageDisabled
+	}
+	var r io.Reader
+	if streamer, ok := s.storage.(blob.Streamer); ok {
+		_, body, err := streamer.GetStream(ctx, key)
+		if err != nil {
+			return Result{}, errors.Wrapf(err, "backup load [%s]", key)
+		}
+		defer body.Close()
+		r = body
+	} else {
+		_, data, err := s.storage.Get(ctx, key)
+		if err != nil {
+			return Result{}, errors.Wrapf(err, "backup load [%s]", key)
+		}
+		r = bytes.NewReader(data)
+	}
+	return s.Restore(ctx, r, checksum, overwrite)
+}
+
+// verify copies the backup to a temporary file and verifies its format and checksums line by line,
+// the file is returned at its start with the number of users only if all checks passed.
+func verify(r io.Reader, checksum string) (*os.File, uint64, error) {
+	file, err := os.CreateTemp("", "restore-*.jsonl.gz")
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "backup read")
+	}
+	users, err := check(io.TeeReader(r, file), checksum)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		remove(file)
+		return nil, 0, err
+	}
+	return file, users, nil
+}
+
+// check reads the whole backup and returns the number of its users, if its checksums are valid.
+// The footer is known after the last line only, so the previous line is kept until the next one.
+func check(r io.Reader, checksum string) (uint64, error) {
+	compressed := sha256.New()
+	zr, err := gzip.NewReader(io.TeeReader(r, compressed))
+	if err != nil {
+		return 0, errors.Wrap(ErrCorrupted, err.Error())
+	}
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+
+	var (
+		hdr    header
+		ftr    footer
+		lines  uint64
+		users  uint64
+		last   []byte
+		digest = sha256.New()
+	)
+	for scanner.Scan() {
+		lines++
+		if lines == 1 {
+			if err = json.Unmarshal(scanner.Bytes(), &hdr); err != nil || hdr.Version != version {
+				return 0, errors.Wrapf(ErrCorrupted, "unsupported header [%s]", scanner.Bytes())
+			}
+			continue
+		}
+		if last != nil {
+			var user models.User
+			if err = json.Unmarshal(last, &user); err != nil {
+				return 0, errors.Wrapf(ErrCorrupted, "user line: %v", err)
+			}
+			digest.Write(last)
+			digest.Write([]byte{'\n'})
+			users++
+		}
+		last = append(last[:0], scanner.Bytes()...)
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, errors.Wrap(ErrCorrupted, err.Error())
+	}
+	if err = zr.Close(); err != nil {
+		return 0, errors.Wrap(ErrCorrupted, err.Error())
+	}
+	// the rest of the stream is read, so checksum covers the whole backup
+	if _, err = io.Copy(compressed, r); err != nil {
+		return 0, errors.Wrap(err, "backup read")
+	}
+	if checksum != "" && checksum != sum(compressed) {
+		return 0, errors.Wrap(ErrCorrupted, "checksum mismatch")
+	}
+
+	if lines < 2 {
+		return 0, errors.Wrap(ErrCorrupted, "header or footer is missing")
+	}
+	if err = json.Unmarshal(last, &ftr); err != nil {
+		return 0, errors.Wrap(ErrCorrupted, "footer is missing")
+	}
+	if ftr.Users != users || ftr.Checksum != sum(digest) {
+		return 0, errors.Wrap(ErrCorrupted, "users checksum mismatch")
+	}
+	return users, nil
+}
+
+// decode passes users of the verified backup to fn one by one.
+func decode(r io.Reader, users uint64, fn func(user models.User) error) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	// the header is skipped
+	scanner.Scan()
+	for i := uint64(0); i < users && scanner.Scan(); i++ {
+		var user models.User
+		if err = json.Unmarshal(scanner.Bytes(), &user); err != nil {
+			return err
+		}
+		if err = fn(user); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// remove closes and removes the temporary file.
+func remove(file *os.File) {
+	_ = file.Close()
+	_ = os.Remove(file.Name())
+}
+
+func sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,156 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	localBlobPkg "gitlab.ozon.dev/iTukaev/homework/pkg/blob/local"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+var users = []models.User{
+	modeltest.Arnold(),
+	modeltest.Boris(),
+	modeltest.From(modeltest.Ivan()).WithStatus(models.StatusDisabled).Build(),
+}
+
+// backedUp matches the user read from the backup, its plain password is hashed by the backup.
+type backedUp models.User
+
+func (m backedUp) Matches(x interface{}) bool {
+	got, ok := x.(models.User)
+	if !ok || !passwordPkg.Hashed(got.Password) || !passwordPkg.Matches(got.Password, m.Password) {
+		return false
+	}
+	got.Password = m.Password
+	return gomock.Eq(models.User(m)).Matches(got)
+}
+
+func (m backedUp) String() string {
+	return fmt.Sprintf("is %v backed up", models.User(m))
+}
+
+func snapshot(data *repoMockPkg.MockInterface) {
+	data.EXPECT().UserSnapshot(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(models.User) error) error {
+			for _, user := range users {
+				if err := fn(user); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Times(1)
+}
+
+func TestService_Restore(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	data := repoMockPkg.NewMockInterface(ctl)
+	snapshot(data)
+	var backup bytes.Buffer
+	summary, err := New(data, nil, nil, loggerPkg.NewFatal()).Create(context.Background(), &backup)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(users)), summary.Users)
+	assert.Equal(t, int64(backup.Len()), summary.Size)
+
+	corrupted := append([]byte(nil), backup.Bytes()...)
+	corrupted[len(corrupted)/2] ^= 0xff
+
+	cases := []struct {
+		name       string
+		backup     []byte
+		checksum   string
+		restoreErr error
+		expResult  Result
+		expErr     error
+	}{
+		{
+			name:      "success",
+			backup:    backup.Bytes(),
+			checksum:  summary.Checksum,
+			expResult: Result{Users: 3, Created: 2, Skipped: 1},
+		},
+		{
+			name:       "success, failed users are reported",
+			backup:     backup.Bytes(),
+			restoreErr: errorsPkg.ErrValidation,
+			expResult: Result{Users: 3, Created: 2, Failed: []string{
+				users[2].Name + ": " + errorsPkg.ErrValidation.Error(),
+			}},
+		},
+		{
+			name:     "failed, checksum mismatch",
+			backup:   backup.Bytes(),
+			checksum: "00",
+			expErr:   ErrCorrupted,
+		},
+		{
+			name:   "failed, corrupted data",
+			backup: corrupted,
+			expErr: ErrCorrupted,
+		},
+		{
+			name:   "failed, not a backup",
+			backup: []byte("name,password\n"),
+			expErr: ErrCorrupted,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := userMockPkg.NewMockInterface(ctl)
+			if c.expErr == nil {
+				user.EXPECT().Restore(gomock.Any(), backedUp(users[0]), true).Return(models.ImportCreated, nil).Times(1)
+				user.EXPECT().Restore(gomock.Any(), backedUp(users[1]), true).Return(models.ImportCreated, nil).Times(1)
+				status := models.ImportSkipped
+				if c.restoreErr != nil {
+					status = models.ImportFailed
+				}
+				user.EXPECT().Restore(gomock.Any(), backedUp(users[2]), true).Return(status, c.restoreErr).Times(1)
+			}
+
+			result, err := New(nil, user, nil, loggerPkg.NewFatal()).
+				Restore(context.Background(), bytes.NewReader(c.backup), c.checksum, true)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expResult, result)
+		})
+	}
+}
+
+func TestService_StoreLoad(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	data := repoMockPkg.NewMockInterface(ctl)
+	user := userMockPkg.NewMockInterface(ctl)
+
+	_, err := New(data, user, nil, loggerPkg.NewFatal()).Store(context.Background(), "")
+	assert.ErrorIs(t, err, ErrStorageDisabled)
+
+	storage, err := localBlobPkg.New(localBlobPkg.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	s := New(data, user, storage, loggerPkg.NewFatal())
+
+	snapshot(data)
+	summary, err := s.Store(context.Background(), "users.jsonl.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "users.jsonl.gz", summary.Key)
+
+	user.EXPECT().Restore(gomock.Any(), gomock.Any(), false).Return(models.ImportSkipped, nil).Times(len(users))
+	result, err := s.Load(context.Background(), summary.Key, summary.Checksum, false)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Users: 3, Skipped: 3}, result)
+}
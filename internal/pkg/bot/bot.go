@@ -10,10 +10,13 @@ import (
 	"go.uber.org/zap"
 
 	commandPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/bot/command"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
 )
 
 const (
 	contextTimeout = 5 * time.Second
+	workers        = 10
+	queueSize      = 100
 )
 
 type Interface interface {
@@ -31,8 +34,13 @@ func New(id string, logger *zap.SugaredLogger) (Interface, error) {
 	bot.Debug = false
 
 	return &commander{
-		bot:    bot,
-		route:  make(map[string]commandPkg.Interface),
+		bot:   bot,
+		route: make(map[string]commandPkg.Interface),
+		pool: workerpool.New("bot", workerpool.Config{
+			Workers:     workers,
+			QueueSize:   queueSize,
+			TaskTimeout: contextTimeout,
+		}, logger),
 		logger: logger,
 	}, nil
 }
@@ -40,6 +48,7 @@ func New(id string, logger *zap.SugaredLogger) (Interface, error) {
 type commander struct {
 	bot    *tgbotapi.BotAPI
 	route  map[string]commandPkg.Interface
+	pool   *workerpool.Pool
 	logger *zap.SugaredLogger
 }
 
@@ -55,24 +64,29 @@ func (c *commander) Run(ctx context.Context) {
 	updates := c.bot.GetUpdatesChan(u)
 
 	for update := range updates {
-		if update.Message != nil {
-			go c.handleMessage(ctx, update.Message)
+		if update.Message == nil {
+			continue
+		}
+		message := update.Message
+		if err := c.pool.Submit(ctx, func(ctx context.Context) error {
+			return c.handleMessage(ctx, message)
+		}); err != nil {
+			c.logger.Errorf("message [%d] is dropped: %v", message.MessageID, err)
 		}
 	}
 }
 
 func (c *commander) Stop() {
 	c.bot.StopReceivingUpdates()
+	c.pool.Close()
 }
 
-func (c *commander) handleMessage(ctx context.Context, message *tgbotapi.Message) {
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, contextTimeout)
-	defer cancel()
-
+// handleMessage is run by the pool worker, ctx is limited by contextTimeout.
+func (c *commander) handleMessage(ctx context.Context, message *tgbotapi.Message) error {
 	msg := tgbotapi.NewMessage(message.Chat.ID, "")
 	if cmdName := message.Command(); cmdName != "" {
 		if cmd, ok := c.route[cmdName]; ok {
-			msg.Text = cmd.Process(ctxWithTimeout, message.CommandArguments())
+			msg.Text = cmd.Process(ctx, message.CommandArguments())
 		} else {
 			msg.Text = fmt.Sprintf("command [%s] not found", cmdName)
 		}
@@ -83,4 +97,5 @@ func (c *commander) handleMessage(ctx context.Context, message *tgbotapi.Message
 	if err != nil {
 		c.logger.Error("answer error:", err)
 	}
+	return err
 }
@@ -0,0 +1,215 @@
+// Package cdc reads changes of the users table from a Postgres logical replication slot,
+// so writes bypassing the service, e.g. migrations and manual fixes, invalidate its caches.
+//
+// The slot of the built-in pgoutput plugin is read by SQL functions, so it works through
+// the connection pooler as well. It requires wal_level=logical and the publication
+// of the users table with the full replica identity, see migrations.
+package cdc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+const (
+	usersSchema = "public"
+	usersTable  = "users"
+	nameColumn  = "name"
+
+	defaultSlot        = "users_cdc"
+	defaultPublication = "users_cdc"
+	defaultInterval    = time.Second
+	defaultBatchSize   = 1000
+)
+
+// Config of the CDC listener, it reads the slot of the configured database.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Slot is the logical replication slot, it is created on start if missing.
+	Slot string `mapstructure:"slot"`
+	// Publication of the users table.
+	Publication string `mapstructure:"publication"`
+	// Interval between polls of the slot.
+	Interval time.Duration `mapstructure:"interval"`
+	// BatchSize limits changes read by a poll.
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// Action of the change.
+type Action string
+
+const (
+	ActionInsert   Action = "insert"
+	ActionUpdate   Action = "update"
+	ActionDelete   Action = "delete"
+	ActionTruncate Action = "truncate"
+)
+
+// Change of a user row.
+type Change struct {
+	Action Action
+	// Name after the change, it is empty for deletions.
+	Name string
+	// OldName before the change, it is set for deletions and renames.
+	OldName string
+}
+
+// Names returns the names affected by the change, truncate affects all users and has no names.
+func (c Change) Names() []string {
+	var names []string
+	if c.OldName != "" {
+		names = append(names, c.OldName)
+	}
+	if c.Name != "" {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Handler applies the change. Changes made by the service are read as well,
+// so handlers must be idempotent, e.g. drop cache entries.
+type Handler func(ctx context.Context, change Change) error
+
+// Listener applies changes of the slot to handlers. The slot is advanced past applied
+// transactions only, so changes are applied at least once. It must be run on the leader only,
+// since the slot is read by one session at a time.
+type Listener struct {
+	pool     pgxtype.Querier
+	cfg      Config
+	handlers []Handler
+	logger   *zap.SugaredLogger
+}
+
+func New(pool pgxtype.Querier, cfg Config, logger *zap.SugaredLogger, handlers ...Handler) *Listener {
+	if cfg.Slot == "" {
+		cfg.Slot = defaultSlot
+	}
+	if cfg.Publication == "" {
+		cfg.Publication = defaultPublication
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	return &Listener{
+		pool:     pool,
+		cfg:      cfg,
+		handlers: handlers,
+		logger:   logger,
+	}
+}
+
+// Run creates the slot, if it is missing, and polls it every interval until ctx is done.
+func (l *Listener) Run(ctx context.Context) {
+	l.logger.Infow("Start CDC listener", "slot", l.cfg.Slot, "publication", l.cfg.Publication,
+		"interval", l.cfg.Interval)
+	if err := l.createSlot(ctx); err != nil {
+		l.logger.Errorf("cdc: %v", err)
+		return
+	}
+	ticker := time.NewTicker(l.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		// a full batch is followed by the next one without waiting
+		for {
+			read, err := l.Poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				l.logger.Errorf("cdc: %v", err)
+			}
+			if err != nil || read < l.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			l.logger.Infoln("CDC listener stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Listener) createSlot(ctx context.Context) error {
+	const query = "SELECT pg_create_logical_replication_slot($1, 'pgoutput') " +
+		"WHERE NOT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)"
+	if _, err := l.pool.Exec(ctx, query, l.cfg.Slot); err != nil {
+		return errors.Wrapf(err, "create slot [%s]", l.cfg.Slot)
+	}
+	return nil
+}
+
+// Poll applies a batch of changes and advances the slot past the last applied transaction.
+// Number of messages read is returned, it is the batch size, if more changes may be pending.
+func (l *Listener) Poll(ctx context.Context) (int, error) {
+	const peek = "SELECT lsn::text, data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2, " +
+		"'proto_version', '1', 'publication_names', $3)"
+	rows, err := l.pool.Query(ctx, peek, l.cfg.Slot, l.cfg.BatchSize, l.cfg.Publication)
+	if err != nil {
+		return 0, errors.Wrap(err, "cdc peek")
+	}
+	defer rows.Close()
+
+	// relations are sent again by every decoding session, so the decoder is not kept
+	decoder := newDecoder()
+	var (
+		read    int
+		pending []Change
+		// committed is the LSN of the last commit, changes of its transaction are complete
+		committed string
+		applied   []Change
+	)
+	for rows.Next() {
+		var (
+			lsn  string
+			data []byte
+		)
+		if err = rows.Scan(&lsn, &data); err != nil {
+			return 0, errors.Wrap(err, "cdc peek")
+		}
+		read++
+		change, ok, commit, err := decoder.decode(data)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cdc lsn [%s]", lsn)
+		}
+		if ok {
+			pending = append(pending, change)
+		}
+		if commit {
+			applied, pending, committed = append(applied, pending...), nil, lsn
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "cdc peek")
+	}
+	rows.Close()
+	// transactions are decoded whole, so a batch without a commit has no changes
+	if committed == "" {
+		return 0, nil
+	}
+
+	for _, change := range applied {
+		for _, handler := range l.handlers {
+			if err = handler(ctx, change); err != nil {
+				// the slot is not advanced, the batch is applied again by the next poll
+				return read, errors.Wrapf(err, "cdc apply [%s %v]", change.Action, change.Names())
+			}
+		}
+	}
+	counter.CDCApplied.Add(uint64(len(applied)))
+
+	// changes are consumed up to the commit, the slot confirms the end of its record
+	const consume = "SELECT count(*) FROM pg_logical_slot_get_binary_changes($1, $2::pg_lsn, NULL, " +
+		"'proto_version', '1', 'publication_names', $3)"
+	if _, err = l.pool.Exec(ctx, consume, l.cfg.Slot, committed, l.cfg.Publication); err != nil {
+		return read, errors.Wrapf(err, "cdc advance to [%s]", committed)
+	}
+	return read, nil
+}
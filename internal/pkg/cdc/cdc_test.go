@@ -0,0 +1,206 @@
+package cdc
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// message builds pgoutput messages of the tests.
+type message []byte
+
+func (m message) byte(b byte) message {
+	return append(m, b)
+}
+
+func (m message) uint16(v uint16) message {
+	field := make([]byte, 2)
+	binary.BigEndian.PutUint16(field, v)
+	return append(m, field...)
+}
+
+func (m message) uint32(v uint32) message {
+	field := make([]byte, 4)
+	binary.BigEndian.PutUint32(field, v)
+	return append(m, field...)
+}
+
+func (m message) string(s string) message {
+	return append(append(m, s...), 0)
+}
+
+// tuple of text values, nil is null.
+func (m message) tuple(values ...*string) message {
+	m = m.uint16(uint16(len(values)))
+	for _, value := range values {
+		if value == nil {
+			m = m.byte(columnNull)
+			continue
+		}
+		m = m.byte(columnText).uint32(uint32(len(*value)))
+		m = append(m, *value...)
+	}
+	return m
+}
+
+func text(s string) *string {
+	return &s
+}
+
+func relationMsg(id uint32, name string) message {
+	m := message{msgRelation}.uint32(id).string("public").string(name).byte('f').uint16(2)
+	return m.byte(1).string("id").uint32(2950).uint32(0).byte(0).string("name").uint32(1043).uint32(0)
+}
+
+var (
+	beginMsg  = message{'B'}.uint32(0).uint32(1).uint32(0).uint32(0).uint32(7)
+	commitMsg = message{msgCommit}.byte(0).uint32(0).uint32(1).uint32(0).uint32(2).uint32(0).uint32(0)
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		name      string
+		msg       message
+		expChange Change
+		expOk     bool
+		expErr    bool
+	}{
+		{
+			name:      "insert",
+			msg:       message{msgInsert}.uint32(1).byte(tupleNew).tuple(text("1"), text("ivan")),
+			expChange: Change{Action: ActionInsert, Name: "ivan"},
+			expOk:     true,
+		},
+		{
+			name: "update with rename",
+			msg: message{msgUpdate}.uint32(1).byte(tupleOld).tuple(text("1"), text("ivan")).
+				byte(tupleNew).tuple(text("1"), text("petr")),
+			expChange: Change{Action: ActionUpdate, Name: "petr", OldName: "ivan"},
+			expOk:     true,
+		},
+		{
+			name:      "update without old tuple",
+			msg:       message{msgUpdate}.uint32(1).byte(tupleNew).tuple(text("1"), text("ivan")),
+			expChange: Change{Action: ActionUpdate, Name: "ivan"},
+			expOk:     true,
+		},
+		{
+			name:      "delete",
+			msg:       message{msgDelete}.uint32(1).byte(tupleOld).tuple(text("1"), text("ivan")),
+			expChange: Change{Action: ActionDelete, OldName: "ivan"},
+			expOk:     true,
+		},
+		{
+			name:      "delete by key without name",
+			msg:       message{msgDelete}.uint32(1).byte(tupleKey).tuple(text("1"), nil),
+			expChange: Change{Action: ActionDelete},
+			expOk:     true,
+		},
+		{
+			name:      "truncate",
+			msg:       message{msgTruncate}.uint32(2).byte(0).uint32(2).uint32(1),
+			expChange: Change{Action: ActionTruncate},
+			expOk:     true,
+		},
+		{
+			name: "insert of other table",
+			msg:  message{msgInsert}.uint32(2).byte(tupleNew).tuple(text("1"), text("ivan")),
+		},
+		{
+			name: "begin",
+			msg:  beginMsg,
+		},
+		{
+			name:   "truncated message",
+			msg:    message{msgInsert}.uint32(1).byte(tupleNew).uint16(2).byte(columnText).uint32(10),
+			expErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := newDecoder()
+			for _, rel := range []message{relationMsg(1, "users"), relationMsg(2, "users_tombstones")} {
+				_, _, _, err := d.decode(rel)
+				require.NoError(t, err)
+			}
+
+			change, ok, commit, err := d.decode(c.msg)
+			assert.Equal(t, c.expErr, err != nil)
+			assert.Equal(t, c.expOk, ok)
+			assert.False(t, commit)
+			assert.Equal(t, c.expChange, change)
+		})
+	}
+}
+
+func TestListener_Poll(t *testing.T) {
+	const (
+		peek = "SELECT lsn::text, data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2, " +
+			"'proto_version', '1', 'publication_names', $3)"
+		consume = "SELECT count(*) FROM pg_logical_slot_get_binary_changes($1, $2::pg_lsn, NULL, " +
+			"'proto_version', '1', 'publication_names', $3)"
+	)
+	insert := message{msgInsert}.uint32(1).byte(tupleNew).tuple(text("1"), text("ivan"))
+	// the second transaction is not committed within the batch
+	rows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"lsn", "data"}).
+			AddRow("0/10", []byte(beginMsg)).
+			AddRow("0/10", []byte(relationMsg(1, "users"))).
+			AddRow("0/10", []byte(insert)).
+			AddRow("0/20", []byte(commitMsg)).
+			AddRow("0/30", []byte(beginMsg)).
+			AddRow("0/30", []byte(insert))
+	}
+
+	cases := []struct {
+		name       string
+		handlerErr error
+		expApplied []Change
+		expErr     error
+	}{
+		{
+			name:       "success",
+			expApplied: []Change{{Action: ActionInsert, Name: "ivan"}},
+		},
+		{
+			name:       "failed, handler error",
+			handlerErr: errorsPkg.ErrUnexpected,
+			expApplied: []Change{{Action: ActionInsert, Name: "ivan"}},
+			expErr:     errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mock.Close()
+
+			mock.ExpectQuery(peek).WithArgs("users_cdc", 10, "users_cdc").WillReturnRows(rows())
+			if c.handlerErr == nil {
+				// the slot is advanced to the last commit only
+				mock.ExpectExec(consume).WithArgs("users_cdc", "0/20", "users_cdc").
+					WillReturnResult(pgxmock.NewResult("SELECT", 1))
+			}
+
+			var applied []Change
+			l := New(mock, Config{BatchSize: 10}, zap.NewNop().Sugar(), func(_ context.Context, change Change) error {
+				applied = append(applied, change)
+				return c.handlerErr
+			})
+			read, err := l.Poll(context.Background())
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, 6, read)
+			assert.Equal(t, c.expApplied, applied)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
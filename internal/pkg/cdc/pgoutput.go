@@ -0,0 +1,228 @@
+package cdc
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// Messages of the pgoutput protocol version 1, others are skipped.
+const (
+	msgCommit   = 'C'
+	msgRelation = 'R'
+	msgInsert   = 'I'
+	msgUpdate   = 'U'
+	msgDelete   = 'D'
+	msgTruncate = 'T'
+
+	tupleNew = 'N'
+	tupleKey = 'K'
+	tupleOld = 'O'
+
+	columnNull      = 'n'
+	columnUnchanged = 'u'
+	columnText      = 't'
+)
+
+// relation is a table described by the relation message, which precedes its first change in the session.
+type relation struct {
+	namespace string
+	name      string
+	columns   []string
+}
+
+// decoder keeps relations of the stream, changes refer to them by ID.
+type decoder struct {
+	relations map[uint32]relation
+}
+
+func newDecoder() *decoder {
+	return &decoder{relations: make(map[uint32]relation)}
+}
+
+// decode returns the change of the users table in the message, ok is false for other messages.
+// commit is true for the commit message, changes of the transaction are complete then.
+func (d *decoder) decode(data []byte) (change Change, ok, commit bool, err error) {
+	if len(data) == 0 {
+		return Change{}, false, false, errors.New("pgoutput: empty message")
+	}
+	r := &reader{data: data[1:]}
+	switch data[0] {
+	case msgCommit:
+		return Change{}, false, true, nil
+	case msgRelation:
+		d.relation(r)
+		return Change{}, false, false, r.err
+	case msgInsert, msgUpdate, msgDelete:
+		rel, ok := d.usersRelation(r.uint32())
+		if !ok {
+			// changes of other tables of the publication are not cached
+			return Change{}, false, false, r.err
+		}
+		if change, err = decodeRow(data[0], rel, r); err != nil {
+			return Change{}, false, false, err
+		}
+	case msgTruncate:
+		count := r.uint32()
+		r.byte()
+		for i := uint32(0); i < count && r.err == nil; i++ {
+			if _, ok := d.usersRelation(r.uint32()); ok {
+				change = Change{Action: ActionTruncate}
+			}
+		}
+		if change.Action == "" {
+			return Change{}, false, false, r.err
+		}
+	default:
+		return Change{}, false, false, nil
+	}
+	if r.err != nil {
+		return Change{}, false, false, errors.Wrapf(r.err, "pgoutput: message [%c]", data[0])
+	}
+	return change, true, false, nil
+}
+
+// decodeRow reads tuples of the insert, update or delete of the user.
+func decodeRow(msg byte, rel relation, r *reader) (Change, error) {
+	kind := r.byte()
+	var change Change
+	switch msg {
+	case msgInsert:
+		change.Action = ActionInsert
+	case msgUpdate:
+		change.Action = ActionUpdate
+		// the old tuple is sent, if the replica identity columns are changed or the identity is full
+		if kind == tupleKey || kind == tupleOld {
+			change.OldName = rel.userName(r.tuple())
+			kind = r.byte()
+		}
+	case msgDelete:
+		if kind != tupleKey && kind != tupleOld {
+			return Change{}, r.failed("delete")
+		}
+		return Change{Action: ActionDelete, OldName: rel.userName(r.tuple())}, r.err
+	}
+	if kind != tupleNew {
+		return Change{}, r.failed(string(change.Action))
+	}
+	change.Name = rel.userName(r.tuple())
+	if change.OldName == change.Name {
+		change.OldName = ""
+	}
+	return change, r.err
+}
+
+func (d *decoder) relation(r *reader) {
+	id := r.uint32()
+	rel := relation{namespace: r.string(), name: r.string()}
+	r.byte()
+	count := r.uint16()
+	for i := uint16(0); i < count && r.err == nil; i++ {
+		r.byte()
+		rel.columns = append(rel.columns, r.string())
+		r.uint32()
+		r.uint32()
+	}
+	if r.err == nil {
+		d.relations[id] = rel
+	}
+}
+
+// usersRelation returns the relation of the ID, if it is the users table.
+func (d *decoder) usersRelation(id uint32) (relation, bool) {
+	rel, ok := d.relations[id]
+	return rel, ok && rel.name == usersTable && (rel.namespace == "" || rel.namespace == usersSchema)
+}
+
+// userName returns the name column of the tuple, it is empty, if the tuple has no name,
+// e.g. a key tuple of the table without the full replica identity.
+func (rel relation) userName(tuple []*string) string {
+	for i, column := range rel.columns {
+		if column == nameColumn && i < len(tuple) && tuple[i] != nil {
+			return *tuple[i]
+		}
+	}
+	return ""
+}
+
+// reader reads fields of the message, the first error stops reading.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) failed(msg string) error {
+	if r.err != nil {
+		return errors.Wrapf(r.err, "pgoutput: %s", msg)
+	}
+	return errors.Errorf("pgoutput: unexpected %s message", msg)
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.New("message is truncated")
+		return nil
+	}
+	field := r.data[:n]
+	r.data = r.data[n:]
+	return field
+}
+
+func (r *reader) byte() byte {
+	if field := r.next(1); field != nil {
+		return field[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if field := r.next(2); field != nil {
+		return binary.BigEndian.Uint16(field)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if field := r.next(4); field != nil {
+		return binary.BigEndian.Uint32(field)
+	}
+	return 0
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.data, 0)
+	if end < 0 {
+		r.err = errors.New("string is not terminated")
+		return ""
+	}
+	value := string(r.data[:end])
+	r.data = r.data[end+1:]
+	return value
+}
+
+// tuple returns text values of columns, null and unchanged TOAST values are nil.
+func (r *reader) tuple() []*string {
+	count := r.uint16()
+	values := make([]*string, 0, count)
+	for i := uint16(0); i < count && r.err == nil; i++ {
+		switch kind := r.byte(); kind {
+		case columnNull, columnUnchanged:
+			values = append(values, nil)
+		case columnText:
+			value := string(r.next(int(r.uint32())))
+			values = append(values, &value)
+		default:
+			if r.err == nil {
+				r.err = errors.Errorf("unknown column kind [%c]", kind)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,232 @@
+// Package codec encodes users kept by the cache. Payloads are tagged by their format and version,
+// so instances of a rolling format migration read entries written by each other and re-encode them.
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+// Formats of cached values. Proto and msgpack payloads are smaller than JSON ones,
+// proto is the fastest to encode.
+const (
+	FormatJSON    = "json"
+	FormatProto   = "proto"
+	FormatMsgpack = "msgpack"
+)
+
+// Tags of the formats are the first byte of a payload, the second one is a version of the payload
+// layout. JSON payloads are not tagged, they start with '{' or '[', so they are read
+// by instances not knowing the tags.
+const (
+	tagJSON byte = iota + 1
+	tagProto
+	tagMsgpack
+)
+
+const version byte = 1
+
+// ErrUnknownFormat is returned for payloads of formats or versions unknown to this instance,
+// e.g. written by a newer one, they are read as cache misses.
+var ErrUnknownFormat = errors.New("unknown cache format")
+
+type Interface interface {
+	// EncodeUser returns the payload of the configured format.
+	EncodeUser(user models.User) ([]byte, error)
+	// DecodeUser decodes the payload of any known format, stale reports a payload of another format
+	// or version, which the caller re-encodes.
+	DecodeUser(data []byte) (user models.User, stale bool, err error)
+	// EncodeUsers returns the payload of the list page.
+	EncodeUsers(users []models.User) ([]byte, error)
+	DecodeUsers(data []byte) (users []models.User, stale bool, err error)
+}
+
+type format interface {
+	appendUser(dst []byte, user models.User) ([]byte, error)
+	user(data []byte) (models.User, error)
+	appendUsers(dst []byte, users []models.User) ([]byte, error)
+	users(data []byte) ([]models.User, error)
+}
+
+var (
+	tags    = map[string]byte{FormatJSON: tagJSON, FormatProto: tagProto, FormatMsgpack: tagMsgpack}
+	formats = map[byte]format{tagJSON: jsonFormat{}, tagProto: protoFormat{}, tagMsgpack: msgpackFormat{}}
+)
+
+// New returns codec of the format, empty format is JSON.
+func New(name string) (Interface, error) {
+	if name == "" {
+		name = FormatJSON
+	}
+	tag, ok := tags[name]
+	if !ok {
+		return nil, errors.Wrapf(errorsPkg.ErrValidation, "field: [cache_format] unknown format [%s]", name)
+	}
+	return &codec{tag: tag}, nil
+}
+
+// Default returns the JSON codec.
+func Default() Interface {
+	return &codec{tag: tagJSON}
+}
+
+type codec struct {
+	tag byte
+}
+
+func (c *codec) header() []byte {
+	if c.tag == tagJSON {
+		return nil
+	}
+	return []byte{c.tag, version}
+}
+
+func (c *codec) EncodeUser(user models.User) ([]byte, error) {
+	data, err := formats[c.tag].appendUser(c.header(), user)
+	return data, errors.Wrap(err, "encode user")
+}
+
+func (c *codec) DecodeUser(data []byte) (models.User, bool, error) {
+	f, payload, stale, err := c.format(data)
+	if err != nil {
+		return models.User{}, false, err
+	}
+	user, err := f.user(payload)
+	if err != nil {
+		return models.User{}, false, errors.Wrap(err, "decode user")
+	}
+	return user, stale, nil
+}
+
+func (c *codec) EncodeUsers(users []models.User) ([]byte, error) {
+	data, err := formats[c.tag].appendUsers(c.header(), users)
+	return data, errors.Wrap(err, "encode users")
+}
+
+func (c *codec) DecodeUsers(data []byte) ([]models.User, bool, error) {
+	f, payload, stale, err := c.format(data)
+	if err != nil {
+		return nil, false, err
+	}
+	users, err := f.users(payload)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "decode users")
+	}
+	return users, stale, nil
+}
+
+// format returns the format of the payload and the payload without the tag.
+func (c *codec) format(data []byte) (format, []byte, bool, error) {
+	if len(data) != 0 && (data[0] == '{' || data[0] == '[') {
+		return jsonFormat{}, data, c.tag != tagJSON, nil
+	}
+	if len(data) < 2 {
+		return nil, nil, false, errors.Wrap(ErrUnknownFormat, "payload is too short")
+	}
+	f, ok := formats[data[0]]
+	if !ok || data[0] == tagJSON || data[1] != version {
+		return nil, nil, false, errors.Wrapf(ErrUnknownFormat, "tag [%d], version [%d]", data[0], data[1])
+	}
+	return f, data[2:], data[0] != c.tag, nil
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) appendUser(dst []byte, user models.User) ([]byte, error) {
+	data, err := json.Marshal(user)
+	return append(dst, data...), err
+}
+
+func (jsonFormat) user(data []byte) (models.User, error) {
+	var user models.User
+	err := json.Unmarshal(data, &user)
+	return user, err
+}
+
+func (jsonFormat) appendUsers(dst []byte, users []models.User) ([]byte, error) {
+	// empty page is written as an array, so it is recognized as JSON
+	if users == nil {
+		users = []models.User{}
+	}
+	data, err := json.Marshal(users)
+	return append(dst, data...), err
+}
+
+func (jsonFormat) users(data []byte) ([]models.User, error) {
+	users := make([]models.User, 0)
+	err := json.Unmarshal(data, &users)
+	return users, err
+}
+
+// protoFormat encodes users by the API model, a page is encoded as repeated users of the field 1.
+type protoFormat struct{}
+
+const protoUsersField protowire.Number = 1
+
+func (protoFormat) appendUser(dst []byte, user models.User) ([]byte, error) {
+	return proto.MarshalOptions{}.MarshalAppend(dst, adaptor.ToUserPbModel(user))
+}
+
+func (protoFormat) user(data []byte) (models.User, error) {
+	var user pbModels.User
+	if err := proto.Unmarshal(data, &user); err != nil {
+		return models.User{}, err
+	}
+	return fromPb(&user), nil
+}
+
+func (protoFormat) appendUsers(dst []byte, users []models.User) ([]byte, error) {
+	var err error
+	for _, user := range adaptor.ToUserListPbModel(users) {
+		dst = protowire.AppendTag(dst, protoUsersField, protowire.BytesType)
+		dst = protowire.AppendVarint(dst, uint64(proto.Size(user)))
+		if dst, err = (proto.MarshalOptions{}).MarshalAppend(dst, user); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func (protoFormat) users(data []byte) ([]models.User, error) {
+	users := make([]models.User, 0)
+	for len(data) != 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		if number != protoUsersField || typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(number, typ, data); n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		var user pbModels.User
+		if err := proto.Unmarshal(value, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, fromPb(&user))
+	}
+	return users, nil
+}
+
+// fromPb converts the API model, the avatar URL is kept by the cache unlike replicated states.
+func fromPb(u *pbModels.User) models.User {
+	user := adaptor.ToUserCoreState(u)
+	user.AvatarURL = u.GetAvatarUrl()
+	return user
+}
@@ -0,0 +1,106 @@
+package codec
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	user := modeltest.NewUser().WithAttributes(map[string]string{"team": "core", "long": strings.Repeat("a", 300)}).Build()
+	user.AvatarURL, user.HLC, user.UpdatedAt, user.PasswordExpiresAt = "http://avatar", math.MaxUint64, 1660412960, -1
+	users := []models.User{user, modeltest.Boris(), {Name: "empty"}}
+
+	for _, format := range []string{FormatJSON, FormatProto, FormatMsgpack} {
+		t.Run(format, func(t *testing.T) {
+			c, err := New(format)
+			require.NoError(t, err)
+
+			data, err := c.EncodeUser(user)
+			require.NoError(t, err)
+			decoded, stale, err := c.DecodeUser(data)
+			require.NoError(t, err)
+			assert.False(t, stale)
+			assert.Equal(t, user, decoded)
+
+			data, err = c.EncodeUsers(users)
+			require.NoError(t, err)
+			list, stale, err := c.DecodeUsers(data)
+			require.NoError(t, err)
+			assert.False(t, stale)
+			assert.Equal(t, users, list)
+
+			data, err = c.EncodeUsers(nil)
+			require.NoError(t, err)
+			list, _, err = c.DecodeUsers(data)
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		})
+	}
+}
+
+func TestCodec_Migration(t *testing.T) {
+	user := modeltest.Ivan()
+	proto, err := New(FormatProto)
+	require.NoError(t, err)
+	msgpack, err := New(FormatMsgpack)
+	require.NoError(t, err)
+
+	legacy, err := json.Marshal(user)
+	require.NoError(t, err)
+	decoded, stale, err := proto.DecodeUser(legacy)
+	require.NoError(t, err)
+	assert.True(t, stale, "untagged JSON is re-encoded")
+	assert.Equal(t, user, decoded)
+
+	data, err := msgpack.EncodeUser(user)
+	require.NoError(t, err)
+	decoded, stale, err = proto.DecodeUser(data)
+	require.NoError(t, err)
+	assert.True(t, stale, "payload of the other format is re-encoded")
+	assert.Equal(t, user, decoded)
+
+	json, err := New(FormatJSON)
+	require.NoError(t, err)
+	data, err = json.EncodeUser(user)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, data, "JSON is not tagged, so instances not knowing the tags read it")
+
+	_, _, err = proto.DecodeUser([]byte{tagProto, version + 1})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	_, _, err = proto.DecodeUser([]byte{0x7f, version})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	_, _, err = proto.DecodeUser(nil)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestMsgpack_UnknownFields(t *testing.T) {
+	data := appendMsgpackLen(nil, 4, 0x80, 0xde, 0xdf)
+	data = appendMsgpackString(appendMsgpackString(data, "name"), "ivan")
+	// fields of a newer version: float, nested map and array
+	data = append(appendMsgpackString(data, "score"), 0xcb, 0, 0, 0, 0, 0, 0, 0, 0)
+	data = append(appendMsgpackString(data, "profile"), 0x81, 0xa1, 'k', 0x92, 0xc3, 0xd0, 0xff)
+	data = appendMsgpackInt(appendMsgpackString(data, "created_at"), -2)
+
+	user, err := msgpackFormat{}.user(data)
+	require.NoError(t, err)
+	assert.Equal(t, models.User{Name: "ivan", CreatedAt: -2}, user)
+
+	_, err = msgpackFormat{}.user(data[:len(data)-1])
+	assert.ErrorIs(t, err, errMsgpack)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("")
+	assert.NoError(t, err)
+	_, err = New("xml")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+}
@@ -0,0 +1,340 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// msgpackFormat encodes users as MessagePack maps keyed by JSON names of the fields, empty fields
+// are omitted. Only types of the user are written, unknown keys of any type are skipped on read.
+type msgpackFormat struct{}
+
+func (msgpackFormat) appendUser(dst []byte, user models.User) ([]byte, error) {
+	return appendMsgpackUser(dst, user), nil
+}
+
+func (msgpackFormat) user(data []byte) (models.User, error) {
+	r := &msgpackReader{data: data}
+	user := r.user()
+	return user, r.err
+}
+
+func (msgpackFormat) appendUsers(dst []byte, users []models.User) ([]byte, error) {
+	dst = appendMsgpackLen(dst, len(users), 0x90, 0xdc, 0xdd)
+	for _, user := range users {
+		dst = appendMsgpackUser(dst, user)
+	}
+	return dst, nil
+}
+
+func (msgpackFormat) users(data []byte) ([]models.User, error) {
+	r := &msgpackReader{data: data}
+	n := r.len(0x90, 0xdc, 0xdd)
+	users := make([]models.User, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		users = append(users, r.user())
+	}
+	return users, r.err
+}
+
+type msgpackString struct {
+	key   string
+	value string
+}
+
+type msgpackInt struct {
+	key   string
+	value int64
+}
+
+func appendMsgpackUser(dst []byte, u models.User) []byte {
+	texts := [...]msgpackString{
+		{"id", u.ID}, {"name", u.Name}, {"password", u.Password}, {"email", u.Email},
+		{"full_name", u.FullName}, {"status", u.Status}, {"avatar_url", u.AvatarURL},
+	}
+	ints := [...]msgpackInt{
+		{"created_at", u.CreatedAt}, {"password_changed_at", u.PasswordChangedAt},
+		{"password_expires_at", u.PasswordExpiresAt}, {"updated_at", u.UpdatedAt},
+	}
+
+	fields := 0
+	for _, f := range texts {
+		if f.value != "" {
+			fields++
+		}
+	}
+	for _, f := range ints {
+		if f.value != 0 {
+			fields++
+		}
+	}
+	if u.HLC != 0 {
+		fields++
+	}
+	if len(u.Attributes) != 0 {
+		fields++
+	}
+
+	dst = appendMsgpackLen(dst, fields, 0x80, 0xde, 0xdf)
+	for _, f := range texts {
+		if f.value != "" {
+			dst = appendMsgpackString(appendMsgpackString(dst, f.key), f.value)
+		}
+	}
+	for _, f := range ints {
+		if f.value != 0 {
+			dst = appendMsgpackInt(appendMsgpackString(dst, f.key), f.value)
+		}
+	}
+	if u.HLC != 0 {
+		dst = appendMsgpackUint(appendMsgpackString(dst, "hlc"), u.HLC)
+	}
+	if len(u.Attributes) != 0 {
+		dst = appendMsgpackLen(appendMsgpackString(dst, "attributes"), len(u.Attributes), 0x80, 0xde, 0xdf)
+		for key, value := range u.Attributes {
+			dst = appendMsgpackString(appendMsgpackString(dst, key), value)
+		}
+	}
+	return dst
+}
+
+// appendMsgpackLen writes the header of the map, array or string of the length, fix is the type byte
+// of short lengths, which is ORed with the length.
+func appendMsgpackLen(dst []byte, n int, fix, type16, type32 byte) []byte {
+	limit := 16
+	if fix == 0xa0 {
+		limit = 32
+	}
+	switch {
+	case n < limit:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return appendBigEndian(append(dst, type16), uint64(n), 2)
+	default:
+		return appendBigEndian(append(dst, type32), uint64(n), 4)
+	}
+}
+
+func appendMsgpackString(dst []byte, s string) []byte {
+	if len(s) >= 32 && len(s) <= math.MaxUint8 {
+		dst = append(dst, 0xd9, byte(len(s)))
+	} else {
+		dst = appendMsgpackLen(dst, len(s), 0xa0, 0xda, 0xdb)
+	}
+	return append(dst, s...)
+}
+
+func appendMsgpackInt(dst []byte, v int64) []byte {
+	if v >= -32 && v < 128 {
+		return append(dst, byte(v))
+	}
+	return appendBigEndian(append(dst, 0xd3), uint64(v), 8)
+}
+
+func appendMsgpackUint(dst []byte, v uint64) []byte {
+	if v < 128 {
+		return append(dst, byte(v))
+	}
+	return appendBigEndian(append(dst, 0xcf), v, 8)
+}
+
+func appendBigEndian(dst []byte, v uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		dst = append(dst, byte(v>>(8*i)))
+	}
+	return dst
+}
+
+var errMsgpack = errors.New("malformed msgpack")
+
+// msgpackReader reads values until the first error, which is kept.
+type msgpackReader struct {
+	data []byte
+	err  error
+}
+
+func (r *msgpackReader) user() models.User {
+	var u models.User
+	fields := r.len(0x80, 0xde, 0xdf)
+	for i := 0; i < fields && r.err == nil; i++ {
+		switch key := r.string(); key {
+		case "id":
+			u.ID = r.string()
+		case "name":
+			u.Name = r.string()
+		case "password":
+			u.Password = r.string()
+		case "email":
+			u.Email = r.string()
+		case "full_name":
+			u.FullName = r.string()
+		case "status":
+			u.Status = r.string()
+		case "avatar_url":
+			u.AvatarURL = r.string()
+		case "created_at":
+			u.CreatedAt = r.int()
+		case "password_changed_at":
+			u.PasswordChangedAt = r.int()
+		case "password_expires_at":
+			u.PasswordExpiresAt = r.int()
+		case "updated_at":
+			u.UpdatedAt = r.int()
+		case "hlc":
+			u.HLC = uint64(r.int())
+		case "attributes":
+			n := r.len(0x80, 0xde, 0xdf)
+			u.Attributes = make(map[string]string, n)
+			for j := 0; j < n && r.err == nil; j++ {
+				key := r.string()
+				u.Attributes[key] = r.string()
+			}
+		default:
+			r.skip()
+		}
+	}
+	return u
+}
+
+func (r *msgpackReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.Wrap(errMsgpack, "unexpected end")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *msgpackReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// len reads the header of the map, array or string written by appendMsgpackLen.
+func (r *msgpackReader) len(fix, type16, type32 byte) int {
+	mask := byte(0xf0)
+	if fix == 0xa0 {
+		mask = 0xe0
+	}
+	switch b := r.byte(); {
+	case r.err != nil:
+		return 0
+	case b&mask == fix:
+		return int(b &^ mask)
+	case b == 0xd9 && fix == 0xa0:
+		return int(r.byte())
+	case b == type16:
+		if v := r.next(2); v != nil {
+			return int(binary.BigEndian.Uint16(v))
+		}
+	case b == type32:
+		if v := r.next(4); v != nil {
+			return int(binary.BigEndian.Uint32(v))
+		}
+	default:
+		r.err = errors.Wrapf(errMsgpack, "unexpected type [%#x]", b)
+	}
+	return 0
+}
+
+func (r *msgpackReader) string() string {
+	return string(r.next(r.len(0xa0, 0xda, 0xdb)))
+}
+
+func (r *msgpackReader) int() int64 {
+	b := r.byte()
+	switch {
+	case r.err != nil:
+		return 0
+	case b < 0x80 || b >= 0xe0:
+		return int64(int8(b))
+	case b < 0xcc || b > 0xd3:
+		r.err = errors.Wrapf(errMsgpack, "unexpected type [%#x]", b)
+		return 0
+	}
+	// unsigned 0xcc-0xcf and signed 0xd0-0xd3 integers of 1, 2, 4 and 8 bytes
+	size := 1 << ((b - 0xcc) % 4)
+	v := r.next(size)
+	if v == nil {
+		return 0
+	}
+	var u uint64
+	for _, c := range v {
+		u = u<<8 | uint64(c)
+	}
+	if b >= 0xd0 {
+		// sign extension of the shorter signed integers
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift
+	}
+	return int64(u)
+}
+
+// skip reads a value of any type.
+func (r *msgpackReader) skip() {
+	b := r.byte()
+	switch {
+	case r.err != nil:
+	case b < 0x80 || b >= 0xe0 || b == 0xc0 || b == 0xc2 || b == 0xc3:
+	case b&0xf0 == 0x80:
+		for i := 0; i < 2*int(b&0x0f); i++ {
+			r.skip()
+		}
+	case b&0xf0 == 0x90:
+		for i := 0; i < int(b&0x0f); i++ {
+			r.skip()
+		}
+	case b&0xe0 == 0xa0:
+		r.next(int(b & 0x1f))
+	case b >= 0xcc && b <= 0xd3:
+		r.next(1 << ((b - 0xcc) % 4))
+	case b == 0xca:
+		r.next(4)
+	case b == 0xcb:
+		r.next(8)
+	case b == 0xc4 || b == 0xd9:
+		r.next(int(r.byte()))
+	case b == 0xc5 || b == 0xda:
+		if v := r.next(2); v != nil {
+			r.next(int(binary.BigEndian.Uint16(v)))
+		}
+	case b == 0xc6 || b == 0xdb:
+		if v := r.next(4); v != nil {
+			r.next(int(binary.BigEndian.Uint32(v)))
+		}
+	case b == 0xdc || b == 0xde:
+		n := 0
+		if v := r.next(2); v != nil {
+			n = int(binary.BigEndian.Uint16(v))
+		}
+		if b == 0xde {
+			n *= 2
+		}
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip()
+		}
+	case b == 0xdd || b == 0xdf:
+		n := 0
+		if v := r.next(4); v != nil {
+			n = int(binary.BigEndian.Uint32(v))
+		}
+		if b == 0xdf {
+			n *= 2
+		}
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip()
+		}
+	default:
+		r.err = errors.Wrapf(errMsgpack, "unsupported type [%#x]", b)
+	}
+}
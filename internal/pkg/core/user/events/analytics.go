@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// AnalyticsSchemaVersion is incremented by incompatible changes of AnalyticsEvent,
+// new fields are added without it.
+const AnalyticsSchemaVersion = 1
+
+// AnalyticsConfig of the flattened change feed. Events are written to the topic, the file or both,
+// empty topic and file disable the feed.
+type AnalyticsConfig struct {
+	KafkaTopic string `mapstructure:"kafka_topic"`
+	// File receives events as JSON lines, it is appended.
+	File string `mapstructure:"file"`
+	// Sample is a fraction of users, which events are published, zero publishes all of them.
+	// Users are sampled by ID, so all events of a sampled user are published.
+	Sample float64 `mapstructure:"sample"`
+}
+
+// AnalyticsEvent is a flat row of the change feed, e.g. for JSONEachRow of ClickHouse.
+// Fields are named by the JSON names of the user, values of the fields are not published.
+type AnalyticsEvent struct {
+	SchemaVersion int      `json:"schema_version"`
+	UserID        string   `json:"user_id"`
+	Action        string   `json:"action"`
+	ChangedFields []string `json:"changed_fields"`
+	Actor         string   `json:"actor"`
+	RealActor     string   `json:"real_actor"`
+	// TS is a time of the event in UNIX milliseconds.
+	TS int64 `json:"ts"`
+}
+
+// bookkeepingFields change with every write, they are not reported as changed.
+var bookkeepingFields = map[string]bool{"UpdatedAt": true, "HLC": true}
+
+// fieldNames are JSON names of the user fields by Go names.
+var fieldNames = func() map[string]string {
+	names := make(map[string]string)
+	userType := reflect.TypeOf(models.User{})
+	for i := 0; i < userType.NumField(); i++ {
+		field := userType.Field(i)
+		names[field.Name] = strings.Split(field.Tag.Get("json"), ",")[0]
+	}
+	return names
+}()
+
+// NewAnalyticsEvent flattens the event. Created users report their non-empty fields as changed,
+// deleted users report no fields.
+func NewAnalyticsEvent(event Event, now time.Time) AnalyticsEvent {
+	change := event.Base()
+	var fields []string
+	switch e := event.(type) {
+	case UserCreated:
+		fields = models.Diff(models.User{}, e.User)
+	case UserUpdated:
+		fields = models.Diff(e.Previous, e.User)
+	}
+	changed := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !bookkeepingFields[field] {
+			changed = append(changed, fieldNames[field])
+		}
+	}
+	return AnalyticsEvent{
+		SchemaVersion: AnalyticsSchemaVersion,
+		UserID:        change.User.ID,
+		Action:        change.Action,
+		ChangedFields: changed,
+		Actor:         change.Actor,
+		RealActor:     change.RealActor,
+		TS:            now.UnixMilli(),
+	}
+}
+
+// AnalyticsPublisher returns handler, which writes flattened events of the sampled users to the sink.
+func AnalyticsPublisher(sink Sink, cfg AnalyticsConfig, logger *zap.SugaredLogger) Handler {
+	return func(_ context.Context, event Event) {
+		user := event.Base().User
+		if !sampled(user.ID, cfg.Sample) {
+			return
+		}
+		value, err := json.Marshal(NewAnalyticsEvent(event, time.Now()))
+		if err != nil {
+			logger.Errorw("publish analytics event", "name", user.Name, "error", err.Error())
+			return
+		}
+		if err = sink.Write(user.ID, value); err != nil {
+			logger.Errorw("publish analytics event", "name", user.Name, "error", err.Error())
+		}
+	}
+}
+
+// sampled reports whether events of the user are published, the user ID is hashed,
+// so the decision is the same on all replicas.
+func sampled(id string, sample float64) bool {
+	if sample <= 0 || sample >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return float64(h.Sum32()) < sample*(1<<32)
+}
+
+// NewAnalyticsSinks returns sinks of the config, producer is used for the topic.
+func NewAnalyticsSinks(cfg AnalyticsConfig, producer sarama.SyncProducer) ([]Sink, error) {
+	sinks, err := newSinks(cfg.KafkaTopic, cfg.File, producer)
+	return sinks, errors.Wrap(err, "analytics")
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestNewAnalyticsEvent(t *testing.T) {
+	now := time.Unix(1660412960, 0)
+	user := models.User{ID: "1", Name: "ivan", Password: "secret", Email: "ivan@email.com", CreatedAt: 1, Status: models.StatusActive, UpdatedAt: 2}
+	disabled := user
+	disabled.Status, disabled.UpdatedAt, disabled.HLC = models.StatusDisabled, 3, 4
+	change := func(action string, user models.User) Change {
+		return Change{Action: action, Actor: "admin", RealActor: "support", User: user}
+	}
+
+	cases := []struct {
+		name       string
+		event      Event
+		expChanged []string
+	}{
+		{
+			name:       "created",
+			event:      UserCreated{Change: change("create", user)},
+			expChanged: []string{"id", "name", "password", "email", "created_at", "status"},
+		},
+		{
+			name:       "updated",
+			event:      UserUpdated{Change: change("status", disabled), Previous: user},
+			expChanged: []string{"status"},
+		},
+		{
+			name:       "deleted",
+			event:      UserDeleted{Change: change("delete", user)},
+			expChanged: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			event := NewAnalyticsEvent(c.event, now)
+			assert.Equal(t, AnalyticsEvent{
+				SchemaVersion: AnalyticsSchemaVersion,
+				UserID:        "1",
+				Action:        c.event.Base().Action,
+				ChangedFields: c.expChanged,
+				Actor:         "admin",
+				RealActor:     "support",
+				TS:            now.UnixMilli(),
+			}, event)
+		})
+	}
+}
+
+func TestAnalyticsPublisher(t *testing.T) {
+	cfg := AnalyticsConfig{File: filepath.Join(t.TempDir(), "analytics.jsonl"), Sample: 0.5}
+	sinks, err := NewAnalyticsSinks(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, sinks, 1)
+	handler := AnalyticsPublisher(sinks[0], cfg, loggerPkg.NewFatal())
+
+	const users = 1000
+	for i := 0; i < users; i++ {
+		id := fmt.Sprintf("id-%d", i)
+		handler(context.Background(), UserCreated{Change: Change{Action: "create", User: models.User{ID: id}}})
+		handler(context.Background(), UserDeleted{Change: Change{Action: "delete", User: models.User{ID: id}}})
+	}
+	require.NoError(t, sinks[0].Close())
+
+	file, err := os.Open(cfg.File)
+	require.NoError(t, err)
+	defer file.Close()
+	actions := make(map[string][]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event AnalyticsEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, AnalyticsSchemaVersion, event.SchemaVersion)
+		actions[event.UserID] = append(actions[event.UserID], event.Action)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.InDelta(t, users/2, len(actions), users/10, "about a half of users is sampled")
+	for id, userActions := range actions {
+		assert.Equal(t, []string{"create", "delete"}, userActions, "all events of the sampled user [%s] are published", id)
+	}
+}
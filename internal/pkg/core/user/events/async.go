@@ -0,0 +1,170 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+const defaultQueueSize = 1000
+
+// Async delivers events to the handler in its own goroutine, so slow side effects, e.g. Kafka
+// and webhooks, don't hold the user lock and the response of the request. Events are handled
+// one by one in order of publishing. When the queue is full, events are spilled to the spool
+// and delivered after the queued ones, so they survive restarts too; without the spool they
+// are dropped. The handler gets a background context, since the request is finished by then.
+type Async struct {
+	name    string
+	handler Handler
+	queue   chan Event
+	logger  *zap.SugaredLogger
+
+	// mu guards the spool, spilling is set, while the spool has events, so following events
+	// are spilled after them
+	mu       sync.Mutex
+	spool    *Spool
+	spilling bool
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewAsync starts delivery to the handler, name tells apart queues in logs. Events of the spool,
+// which were left by the previous process, are delivered first. The spool may be nil,
+// it is closed by Close.
+func NewAsync(name string, handler Handler, size int, spool *Spool, logger *zap.SugaredLogger) *Async {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	a := &Async{
+		name:     name,
+		handler:  handler,
+		queue:    make(chan Event, size),
+		logger:   logger,
+		spool:    spool,
+		spilling: spool != nil && !spool.Empty(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Handle enqueues the event, it is the handler subscribed to the bus.
+func (a *Async) Handle(_ context.Context, event Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.spilling {
+		select {
+		case a.queue <- event:
+			return
+		default:
+		}
+	}
+	if a.spool == nil {
+		counter.EventsDropped.Inc()
+		a.logger.Warnw("event dropped, queue is full", "queue", a.name, "name", event.Base().User.Name)
+		return
+	}
+	if err := a.spool.Push(event); err != nil {
+		counter.EventsDropped.Inc()
+		a.logger.Errorw("event dropped, spool failed", "queue", a.name, "name", event.Base().User.Name, "error", err.Error())
+		return
+	}
+	a.spilling = true
+}
+
+// Close delivers queued events and stops, events must not be handled after it.
+// Spilled events are kept for the next start. Delivery is abandoned, when ctx is done.
+func (a *Async) Close(ctx context.Context) error {
+	a.once.Do(func() {
+		close(a.stop)
+		close(a.queue)
+	})
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if a.spool == nil {
+		return nil
+	}
+	return a.spool.Close()
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+	for {
+		select {
+		case event, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			a.handler(context.Background(), event)
+			continue
+		default:
+		}
+		// the queue is empty, so spilled events are the oldest ones
+		if a.replay() {
+			continue
+		}
+		event, ok := <-a.queue
+		if !ok {
+			return
+		}
+		a.handler(context.Background(), event)
+	}
+}
+
+// replay delivers the next spilled event, it reports false, if there is none or Async is closed.
+func (a *Async) replay() bool {
+	select {
+	case <-a.stop:
+		return false
+	default:
+	}
+	a.mu.Lock()
+	if !a.spilling {
+		a.mu.Unlock()
+		return false
+	}
+	event, next, err := a.spool.Peek()
+	switch {
+	case err != nil:
+		// undecodable events are skipped, the unreadable spool is discarded
+		a.logger.Errorw("event replay", "queue", a.name, "error", err.Error())
+		counter.EventsDropped.Inc()
+		if next == a.spool.read {
+			err = a.spool.Discard()
+		} else {
+			err = a.spool.Commit(next)
+		}
+		a.spilling = !a.spool.Empty()
+		a.mu.Unlock()
+		a.commitFailed(err)
+		return true
+	case event == nil:
+		a.spilling = false
+		a.mu.Unlock()
+		return false
+	}
+	a.mu.Unlock()
+
+	a.handler(context.Background(), event)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.commitFailed(a.spool.Commit(next))
+	return true
+}
+
+// commitFailed logs failed commits, events are delivered again after restart only.
+func (a *Async) commitFailed(err error) {
+	if err != nil {
+		a.logger.Errorw("event replay commit", "queue", a.name, "error", err.Error())
+	}
+}
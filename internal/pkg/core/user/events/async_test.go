@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestAsync(t *testing.T) {
+	var (
+		names   []string
+		release = make(chan struct{})
+	)
+	async := NewAsync("test", func(_ context.Context, event Event) {
+		<-release
+		names = append(names, event.Base().User.Name)
+	}, 2, nil, loggerPkg.NewFatal())
+
+	// the first event is being handled, two of them are queued and the last one is dropped
+	for _, name := range []string{"ivan", "petr", "boris", "olga"} {
+		async.Handle(context.Background(), UserCreated{Change: Change{User: models.User{Name: name}}})
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+
+	require.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, []string{"ivan", "petr", "boris"}, names)
+}
+
+func TestAsync_Close(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	async := NewAsync("test", func(context.Context, Event) { <-release }, 1, nil, loggerPkg.NewFatal())
+	async.Handle(context.Background(), UserCreated{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, async.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestAsync_Spool(t *testing.T) {
+	cfg := SpoolConfig{Dir: t.TempDir()}
+	spool, err := NewSpool(cfg, "test")
+	require.NoError(t, err)
+
+	var (
+		release   = make(chan struct{})
+		delivered = make(chan string, 10)
+	)
+	handler := func(_ context.Context, event Event) {
+		<-release
+		delivered <- event.Base().User.Name
+	}
+	async := NewAsync("test", handler, 1, spool, loggerPkg.NewFatal())
+	// the first event is being handled, the second one is queued and the rest are spilled
+	for _, name := range []string{"ivan", "petr", "boris", "olga"} {
+		async.Handle(context.Background(), UserCreated{Change: Change{User: models.User{Name: name}}})
+		time.Sleep(10 * time.Millisecond)
+	}
+	// queued events are delivered on close, spilled ones are kept
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, async.Close(ctx), context.Canceled)
+	close(release)
+	require.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, []string{"ivan", "petr"}, receive(t, delivered, 2))
+
+	// spilled events are delivered on start before the following ones
+	spool, err = NewSpool(cfg, "test")
+	require.NoError(t, err)
+	async = NewAsync("test", handler, 1, spool, loggerPkg.NewFatal())
+	async.Handle(context.Background(), UserCreated{Change: Change{User: models.User{Name: "anna"}}})
+	assert.Equal(t, []string{"boris", "olga", "anna"}, receive(t, delivered, 3))
+	require.NoError(t, async.Close(context.Background()))
+	assert.True(t, spool.Empty())
+}
+
+func receive(t *testing.T, ch <-chan string, count int) []string {
+	var names []string
+	for len(names) < count {
+		select {
+		case name := <-ch:
+			names = append(names, name)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v of %d names", names, count)
+		}
+	}
+	return names
+}
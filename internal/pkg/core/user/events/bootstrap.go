@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// Snapshot is the current state of users folded from events of the topic.
+type Snapshot struct {
+	users map[string]models.User
+}
+
+func NewSnapshot() *Snapshot {
+	return &Snapshot{users: make(map[string]models.User)}
+}
+
+// Apply folds the message into the snapshot. Messages of the key must be applied in order
+// of the partition, the tombstone and the deletion event remove the user.
+func (s *Snapshot) Apply(msg *sarama.ConsumerMessage) error {
+	id := string(msg.Key)
+	if msg.Value == nil {
+		delete(s.users, id)
+		return nil
+	}
+	var event message
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return errors.Wrapf(err, "event [%s/%d:%d]", msg.Topic, msg.Partition, msg.Offset)
+	}
+	if event.Type == TypeUserDeleted {
+		delete(s.users, id)
+		return nil
+	}
+	s.users[id] = event.User
+	return nil
+}
+
+// Users returns users of the snapshot ordered by name.
+func (s *Snapshot) Users() []models.User {
+	users := make([]models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return users
+}
+
+// ReadTopic reads all partitions of the topic from the oldest message to the newest one
+// produced before the call and folds them into the snapshot.
+func ReadTopic(ctx context.Context, client sarama.Client, topic string) (*Snapshot, error) {
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return nil, errors.Wrapf(err, "partitions of [%s]", topic)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return nil, errors.Wrap(err, "new consumer")
+	}
+	defer consumer.Close()
+
+	snapshot := NewSnapshot()
+	for _, partition := range partitions {
+		if err = readPartition(ctx, client, consumer, topic, partition, snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+func readPartition(
+	ctx context.Context,
+	client sarama.Client,
+	consumer sarama.Consumer,
+	topic string,
+	partition int32,
+	snapshot *Snapshot,
+) error {
+	oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return errors.Wrapf(err, "oldest offset of [%s/%d]", topic, partition)
+	}
+	newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return errors.Wrapf(err, "newest offset of [%s/%d]", topic, partition)
+	}
+	if newest <= oldest {
+		return nil
+	}
+
+	pc, err := consumer.ConsumePartition(topic, partition, oldest)
+	if err != nil {
+		return errors.Wrapf(err, "consume [%s/%d]", topic, partition)
+	}
+	defer pc.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-pc.Errors():
+			return errors.Wrapf(err, "consume [%s/%d]", topic, partition)
+		case msg := <-pc.Messages():
+			if err = snapshot.Apply(msg); err != nil {
+				return err
+			}
+			// compaction leaves gaps, so the end is the last offset before the newest one
+			if msg.Offset >= newest-1 {
+				return nil
+			}
+		}
+	}
+}
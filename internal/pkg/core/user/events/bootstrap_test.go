@@ -0,0 +1,74 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+func TestSnapshot_Apply(t *testing.T) {
+	event := func(id, typ string, user models.User) *sarama.ConsumerMessage {
+		value, err := json.Marshal(message{Type: typ, User: user})
+		require.NoError(t, err)
+		return &sarama.ConsumerMessage{Key: []byte(id), Value: value}
+	}
+	ivan := models.User{ID: "1", Name: "ivan"}
+	petr := models.User{ID: "2", Name: "petr"}
+	boris := models.User{ID: "3", Name: "boris"}
+
+	cases := []struct {
+		name     string
+		messages []*sarama.ConsumerMessage
+		exp      []models.User
+		expErr   bool
+	}{
+		{
+			name: "success, the latest state of the key is kept",
+			messages: []*sarama.ConsumerMessage{
+				event("2", TypeUserCreated, petr),
+				event("1", TypeUserCreated, models.User{ID: "1", Name: "vanya"}),
+				event("1", TypeUserUpdated, ivan),
+			},
+			exp: []models.User{ivan, petr},
+		},
+		{
+			name: "success, deletion and tombstone remove the user",
+			messages: []*sarama.ConsumerMessage{
+				event("1", TypeUserCreated, ivan),
+				event("2", TypeUserCreated, petr),
+				event("3", TypeUserCreated, boris),
+				event("1", TypeUserDeleted, ivan),
+				{Key: []byte("3")},
+			},
+			exp: []models.User{petr},
+		},
+		{
+			name:     "failed, broken event",
+			messages: []*sarama.ConsumerMessage{{Key: []byte("1"), Value: []byte("{")}},
+			expErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			snapshot := NewSnapshot()
+			var err error
+			for _, msg := range c.messages {
+				if err = snapshot.Apply(msg); err != nil {
+					break
+				}
+			}
+			if c.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.exp, snapshot.Users())
+		})
+	}
+}
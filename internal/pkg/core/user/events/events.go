@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// Change is common to all user events.
+type Change struct {
+	// Action tells apart changes of the same event type, e.g. update, rename and status change.
+	// Values are the history actions.
+	Action string
+	// Actor is a request meta of the change, or the identity the caller acts as.
+	Actor string
+	// RealActor is the request meta of the caller acting as Actor, empty otherwise.
+	RealActor string
+	// User is the whole user after the change, or before it for deletion.
+	User models.User
+}
+
+func (c Change) Base() Change {
+	return c
+}
+
+// Event is one of UserCreated, UserUpdated and UserDeleted.
+type Event interface {
+	Base() Change
+}
+
+type UserCreated struct {
+	Change
+}
+
+type UserUpdated struct {
+	Change
+	// OldName is set, if the user is renamed.
+	OldName string
+	// Previous is the whole user before the change, the changed fields are told by models.Diff.
+	Previous models.User
+}
+
+type UserDeleted struct {
+	Change
+}
+
+// Handler reacts on the event. The change is already made, so handlers log their failures.
+type Handler func(ctx context.Context, event Event)
+
+// Bus delivers events of the core to subscribers in process.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds the handler, handlers are called in order of subscription.
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish calls all handlers before it returns, so they see the change made
+// under the user lock and before the response of the request. Slow handlers, e.g. Kafka
+// and webhooks, are subscribed via Async, so the request doesn't wait for them.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
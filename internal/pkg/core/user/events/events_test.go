@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+	bus.Subscribe(func(_ context.Context, event Event) {
+		calls = append(calls, "first "+event.Base().User.Name)
+	})
+	bus.Subscribe(func(_ context.Context, event Event) {
+		if updated, ok := event.(UserUpdated); ok {
+			calls = append(calls, "second "+updated.OldName)
+		}
+	})
+
+	bus.Publish(context.Background(), UserUpdated{
+		Change:  Change{Action: "rename", User: models.User{Name: "petr"}},
+		OldName: "ivan",
+	})
+	bus.Publish(context.Background(), UserDeleted{Change: Change{Action: "delete", User: models.User{Name: "boris"}}})
+
+	assert.Equal(t, []string{"first petr", "second ivan", "first boris"}, calls)
+}
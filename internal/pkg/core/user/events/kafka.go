@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+const (
+	TypeUserCreated = "user_created"
+	TypeUserUpdated = "user_updated"
+	TypeUserDeleted = "user_deleted"
+)
+
+// Config of the events published outside of the service.
+type Config struct {
+	// KafkaTopic receives all user events, empty topic disables publishing.
+	KafkaTopic string `mapstructure:"kafka_topic"`
+	// IncludePasswordHash publishes password hashes, so the topic alone keeps
+	// the whole state of users and the store can be bootstrapped from it. Passwords are hashed
+	// on write, legacy plaintext ones, which are not rehashed yet, are never published.
+	IncludePasswordHash bool `mapstructure:"include_password_hash"`
+	// Analytics is a flattened change feed, it is published independently of KafkaTopic.
+	Analytics AnalyticsConfig `mapstructure:"analytics"`
+	// Security is a stream of security events for SIEM, it is published independently of KafkaTopic.
+	Security SecurityConfig `mapstructure:"security"`
+	// Webhook receives all user events as the topic does, it is posted independently of KafkaTopic.
+	Webhook WebhookConfig `mapstructure:"webhook"`
+	// QueueSize is a number of events waiting for the topic and the webhook, default is 1000.
+	// They are published asynchronously, events are spooled, when the queue is full.
+	QueueSize int `mapstructure:"queue_size"`
+	// Spool keeps events, which didn't fit the queue, they are published after restart too.
+	Spool SpoolConfig `mapstructure:"spool"`
+}
+
+// message is a user event in Kafka. Every message keeps the whole user after the change,
+// so the latest message of the key is enough on a log-compacted topic.
+type message struct {
+	Type      string      `json:"type"`
+	Action    string      `json:"action"`
+	Actor     string      `json:"actor"`
+	RealActor string      `json:"real_actor,omitempty"`
+	OldName   string      `json:"old_name,omitempty"`
+	User      models.User `json:"user"`
+}
+
+// KafkaPublisher returns handler, which sends events to the topic keyed by user ID,
+// so events of the user are kept in order. Deletion is followed by a tombstone,
+// so compaction removes the deleted user from the topic.
+func KafkaPublisher(producer sarama.SyncProducer, cfg Config, logger *zap.SugaredLogger) Handler {
+	return func(_ context.Context, event Event) {
+		change := event.Base()
+		msg := newMessage(event)
+		if !cfg.IncludePasswordHash || !passwordPkg.Hashed(msg.User.Password) {
+			msg.User.Password = ""
+		}
+
+		value, err := json.Marshal(msg)
+		if err != nil {
+			logger.Errorw("publish user event", "name", change.User.Name, "error", err.Error())
+			return
+		}
+		messages := []*sarama.ProducerMessage{{
+			Topic: cfg.KafkaTopic,
+			Key:   sarama.StringEncoder(change.User.ID),
+			Value: sarama.ByteEncoder(value),
+		}}
+		if msg.Type == TypeUserDeleted {
+			messages = append(messages, &sarama.ProducerMessage{
+				Topic: cfg.KafkaTopic,
+				Key:   sarama.StringEncoder(change.User.ID),
+			})
+		}
+		if err = producer.SendMessages(messages); err != nil {
+			logger.Errorw("publish user event", "name", change.User.Name, "type", msg.Type, "error", err.Error())
+		}
+	}
+}
+
+func newMessage(event Event) message {
+	change := event.Base()
+	msg := message{
+		Action:    change.Action,
+		Actor:     change.Actor,
+		RealActor: change.RealActor,
+		User:      change.User,
+	}
+	switch e := event.(type) {
+	case UserCreated:
+		msg.Type = TypeUserCreated
+	case UserUpdated:
+		msg.Type, msg.OldName = TypeUserUpdated, e.OldName
+	case UserDeleted:
+		msg.Type = TypeUserDeleted
+	}
+	return msg
+}
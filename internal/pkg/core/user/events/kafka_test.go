@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestKafkaPublisher_Password(t *testing.T) {
+	hash, err := passwordPkg.Hash("secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		include  bool
+		password string
+		expected string
+	}{
+		{
+			name:     "hash is published",
+			include:  true,
+			password: hash,
+			expected: hash,
+		},
+		{
+			name:     "legacy plaintext password is not published",
+			include:  true,
+			password: "secret",
+		},
+		{
+			name:     "hash is not published without the option",
+			password: hash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := mocks.NewSyncProducer(t, nil)
+			defer producer.Close()
+			producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(m *sarama.ProducerMessage) error {
+				value, err := m.Value.Encode()
+				require.NoError(t, err)
+				var msg message
+				require.NoError(t, json.Unmarshal(value, &msg))
+				assert.Equal(t, tt.expected, msg.User.Password)
+				return nil
+			})
+
+			publish := KafkaPublisher(producer, Config{KafkaTopic: "users", IncludePasswordHash: tt.include}, loggerPkg.NewFatal())
+			publish(context.Background(), UserCreated{Change: Change{User: models.User{ID: "1", Name: "ivan", Password: tt.password}}})
+		})
+	}
+}
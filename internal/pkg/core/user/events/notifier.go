@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Notifier wakes callers waiting for changes of users by name. It is a bus handler,
+// so callers are woken after the change is made and the cache is invalidated.
+type Notifier struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{waiters: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Wait returns a channel closed on the next change of the user, cancel must be called,
+// if the channel is not needed anymore. Waiting starts before the call returns,
+// so a change made right after the user is read is not missed.
+func (n *Notifier) Wait(name string) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.waiters[name] == nil {
+		n.waiters[name] = make(map[chan struct{}]struct{})
+	}
+	n.waiters[name][ch] = struct{}{}
+
+	return ch, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if _, ok := n.waiters[name][ch]; ok {
+			n.remove(name, ch)
+		}
+	}
+}
+
+// Handle wakes waiters of the changed user. Renamed user wakes waiters of both names,
+// since the old name is not found anymore.
+func (n *Notifier) Handle(_ context.Context, event Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.wake(event.Base().User.Name)
+	if e, ok := event.(UserUpdated); ok && e.OldName != "" {
+		n.wake(e.OldName)
+	}
+}
+
+// Wake wakes waiters of the users changed bypassing the bus, e.g. by direct writes to the database.
+func (n *Notifier) Wake(names ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, name := range names {
+		n.wake(name)
+	}
+}
+
+func (n *Notifier) wake(name string) {
+	for ch := range n.waiters[name] {
+		close(ch)
+		n.remove(name, ch)
+	}
+}
+
+func (n *Notifier) remove(name string, ch chan struct{}) {
+	delete(n.waiters[name], ch)
+	if len(n.waiters[name]) == 0 {
+		delete(n.waiters, name)
+	}
+}
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestNotifier_Handle(t *testing.T) {
+	notifier := NewNotifier()
+	ivan, cancelIvan := notifier.Wait("ivan")
+	defer cancelIvan()
+	petr, cancelPetr := notifier.Wait("petr")
+	defer cancelPetr()
+	boris, cancelBoris := notifier.Wait("boris")
+	cancelBoris()
+
+	notifier.Handle(context.Background(), UserCreated{Change: Change{User: models.User{Name: "anna"}}})
+	assert.False(t, closed(ivan))
+	assert.False(t, closed(petr))
+
+	notifier.Handle(context.Background(), UserUpdated{Change: Change{User: models.User{Name: "petr"}}, OldName: "ivan"})
+	assert.True(t, closed(ivan))
+	assert.True(t, closed(petr))
+
+	notifier.Handle(context.Background(), UserDeleted{Change: Change{User: models.User{Name: "boris"}}})
+	assert.False(t, closed(boris))
+	assert.Empty(t, notifier.waiters)
+}
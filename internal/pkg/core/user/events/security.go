@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+// SecuritySchemaVersion is incremented by incompatible changes of SecurityEvent,
+// new fields and types are added without it.
+const SecuritySchemaVersion = 1
+
+// Types of security events.
+const (
+	// SecurityLoginSuccess is a valid password or an external login.
+	SecurityLoginSuccess = "login_success"
+	// SecurityLoginFailure is an invalid password, a check of the locked user or a rejected external login.
+	SecurityLoginFailure = "login_failure"
+	// SecurityLockout is the failed attempt, which locks password checks of the user until the window ends.
+	SecurityLockout = "lockout"
+	// SecurityRoleChange is a change of the role attribute, created users report their role too.
+	SecurityRoleChange = "role_change"
+	// SecurityImpersonation is a change made by the caller acting as another identity.
+	SecurityImpersonation = "impersonation"
+	// SecurityErasure is a deletion of the user.
+	SecurityErasure = "erasure"
+)
+
+// Reasons of failed logins.
+const (
+	SecurityReasonInvalidCredentials = "invalid_credentials"
+	SecurityReasonLocked             = "locked"
+	SecurityReasonPasswordExpired    = "password_expired"
+	SecurityReasonUnknownSubject     = "unknown_subject"
+	SecurityReasonInactive           = "inactive"
+)
+
+// SecurityConfig of the security event stream. Events are written to the topic, the file, syslog
+// or all of them, none of them disables the stream.
+type SecurityConfig struct {
+	KafkaTopic string `mapstructure:"kafka_topic"`
+	// File receives events as JSON lines, it is appended.
+	File   string       `mapstructure:"file"`
+	Syslog SyslogConfig `mapstructure:"syslog"`
+}
+
+// SyslogConfig of the syslog sink, events are sent with the auth facility and the notice severity.
+type SyslogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Network and Address of the server, e.g. "udp" and "siem:514", empty ones use the local syslog.
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	// Tag of the messages, the name of the binary by default.
+	Tag string `mapstructure:"tag"`
+}
+
+// SecurityEvent is an event of the SIEM stream encoded as a JSON object. Passwords and other
+// secrets are never published.
+type SecurityEvent struct {
+	SchemaVersion int    `json:"schema_version"`
+	Type          string `json:"type"`
+	// UserID is empty for logins of unknown users.
+	UserID string `json:"user_id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	// Actor is a request meta of the caller, or the identity the caller acts as.
+	Actor string `json:"actor"`
+	// RealActor is the request meta of the caller acting as Actor, empty otherwise.
+	RealActor string `json:"real_actor,omitempty"`
+	// Reason of failed logins, one of the SecurityReason values.
+	Reason string `json:"reason,omitempty"`
+	// Details depend on the type: login "method" and "issuer", lockout "attempts" and "window",
+	// role change "old_role" and "new_role", impersonation and erasure "action".
+	Details map[string]string `json:"details,omitempty"`
+	// TS is a time of the event in UNIX milliseconds.
+	TS int64 `json:"ts"`
+}
+
+// SecurityEmitter publishes the security event, failures are logged.
+type SecurityEmitter func(ctx context.Context, event SecurityEvent)
+
+// SecurityPublisher returns emitter, which stamps the schema version and the time of the clock
+// to the events and writes them to all sinks.
+func SecurityPublisher(sinks []Sink, clk clock.Clock, logger *zap.SugaredLogger) SecurityEmitter {
+	return func(_ context.Context, event SecurityEvent) {
+		event.SchemaVersion = SecuritySchemaVersion
+		if event.TS == 0 {
+			event.TS = clk.Now().UnixMilli()
+		}
+		value, err := json.Marshal(event)
+		if err != nil {
+			logger.Errorw("publish security event", "type", event.Type, "name", event.Name, "error", err.Error())
+			return
+		}
+		key := event.UserID
+		if key == "" {
+			key = event.Name
+		}
+		for _, sink := range sinks {
+			if err = sink.Write(key, value); err != nil {
+				logger.Errorw("publish security event", "type", event.Type, "name", event.Name, "error", err.Error())
+			}
+		}
+	}
+}
+
+// SecurityHandler returns handler, which emits role changes, erasures and impersonated changes
+// of the user events.
+func SecurityHandler(emit SecurityEmitter) Handler {
+	return func(ctx context.Context, event Event) {
+		change := event.Base()
+		base := SecurityEvent{
+			UserID:    change.User.ID,
+			Name:      change.User.Name,
+			Actor:     change.Actor,
+			RealActor: change.RealActor,
+		}
+		if change.RealActor != "" {
+			impersonation := base
+			impersonation.Type = SecurityImpersonation
+			impersonation.Details = map[string]string{"action": change.Action}
+			emit(ctx, impersonation)
+		}
+
+		var oldRole, newRole string
+		switch e := event.(type) {
+		case UserCreated:
+			newRole = e.User.Attributes[models.RoleAttribute]
+		case UserUpdated:
+			oldRole, newRole = e.Previous.Attributes[models.RoleAttribute], e.User.Attributes[models.RoleAttribute]
+		case UserDeleted:
+			erasure := base
+			erasure.Type = SecurityErasure
+			erasure.Details = map[string]string{"action": change.Action}
+			emit(ctx, erasure)
+		}
+		if oldRole != newRole {
+			roleChange := base
+			roleChange.Type = SecurityRoleChange
+			roleChange.Details = map[string]string{"old_role": oldRole, "new_role": newRole}
+			emit(ctx, roleChange)
+		}
+	}
+}
+
+// NewSecuritySinks returns sinks of the config, producer is used for the topic.
+func NewSecuritySinks(cfg SecurityConfig, producer sarama.SyncProducer) ([]Sink, error) {
+	sinks, err := newSinks(cfg.KafkaTopic, cfg.File, producer)
+	if err != nil {
+		return nil, errors.Wrap(err, "security")
+	}
+	if cfg.Syslog.Enabled {
+		writer, err := syslog.Dial(cfg.Syslog.Network, cfg.Syslog.Address, syslog.LOG_AUTH|syslog.LOG_NOTICE, cfg.Syslog.Tag)
+		if err != nil {
+			for _, sink := range sinks {
+				_ = sink.Close()
+			}
+			return nil, errors.Wrap(err, "security: dial syslog")
+		}
+		sinks = append(sinks, &syslogSink{writer: writer})
+	}
+	return sinks, nil
+}
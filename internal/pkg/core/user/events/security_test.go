@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestSecurityHandler(t *testing.T) {
+	member := models.User{ID: "1", Name: "ivan", Attributes: map[string]string{models.RoleAttribute: "member"}}
+	admin := models.User{ID: "1", Name: "ivan", Attributes: map[string]string{models.RoleAttribute: "admin"}}
+
+	cases := []struct {
+		name  string
+		event Event
+		exp   []SecurityEvent
+	}{
+		{
+			name:  "created with role",
+			event: UserCreated{Change: Change{Action: "create", Actor: "admin", User: admin}},
+			exp: []SecurityEvent{
+				{Type: SecurityRoleChange, UserID: "1", Name: "ivan", Actor: "admin",
+					Details: map[string]string{"old_role": "", "new_role": "admin"}},
+			},
+		},
+		{
+			name:  "role changed by impersonated caller",
+			event: UserUpdated{Change: Change{Action: "update", Actor: "admin", RealActor: "support", User: admin}, Previous: member},
+			exp: []SecurityEvent{
+				{Type: SecurityImpersonation, UserID: "1", Name: "ivan", Actor: "admin", RealActor: "support",
+					Details: map[string]string{"action": "update"}},
+				{Type: SecurityRoleChange, UserID: "1", Name: "ivan", Actor: "admin", RealActor: "support",
+					Details: map[string]string{"old_role": "member", "new_role": "admin"}},
+			},
+		},
+		{
+			name:  "updated without role change",
+			event: UserUpdated{Change: Change{Action: "status", Actor: "admin", User: member}, Previous: member},
+		},
+		{
+			name:  "deleted",
+			event: UserDeleted{Change: Change{Action: "delete", Actor: "admin", User: member}},
+			exp: []SecurityEvent{
+				{Type: SecurityErasure, UserID: "1", Name: "ivan", Actor: "admin",
+					Details: map[string]string{"action": "delete"}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var emitted []SecurityEvent
+			SecurityHandler(func(_ context.Context, event SecurityEvent) {
+				emitted = append(emitted, event)
+			})(context.Background(), c.event)
+			assert.Equal(t, c.exp, emitted)
+		})
+	}
+}
+
+func TestSecurityPublisher(t *testing.T) {
+	cfg := SecurityConfig{File: filepath.Join(t.TempDir(), "security.jsonl")}
+	sinks, err := NewSecuritySinks(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, sinks, 1)
+	emit := SecurityPublisher(sinks, clock.NewFake(time.UnixMilli(1660412961000)), loggerPkg.NewFatal())
+
+	emit(context.Background(), SecurityEvent{Type: SecurityLoginFailure, Name: "ivan", Actor: "gateway",
+		Reason: SecurityReasonInvalidCredentials, TS: 1660412960000})
+	emit(context.Background(), SecurityEvent{Type: SecurityLockout, Name: "ivan"})
+	require.NoError(t, sinks[0].Close())
+
+	data, err := os.ReadFile(cfg.File)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, map[string]interface{}{
+		"schema_version": float64(SecuritySchemaVersion),
+		"type":           SecurityLoginFailure,
+		"name":           "ivan",
+		"actor":          "gateway",
+		"reason":         SecurityReasonInvalidCredentials,
+		"ts":             float64(1660412960000),
+	}, event, "the documented schema is kept")
+
+	// events without the time are stamped by the clock
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, float64(1660412961000), event["ts"])
+}
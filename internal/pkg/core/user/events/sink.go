@@ -0,0 +1,83 @@
+package events
+
+import (
+	"log/syslog"
+	"os"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+)
+
+// Sink receives encoded events keyed by user ID.
+type Sink interface {
+	Write(key string, event []byte) error
+	Close() error
+}
+
+// newSinks returns sinks of the topic and the file, empty ones are skipped.
+func newSinks(topic, path string, producer sarama.SyncProducer) ([]Sink, error) {
+	var sinks []Sink
+	if topic != "" {
+		sinks = append(sinks, &kafkaSink{producer: producer, topic: topic})
+	}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, errors.Wrap(err, "open file")
+		}
+		sinks = append(sinks, &fileSink{file: file})
+	}
+	return sinks, nil
+}
+
+// kafkaSink sends events keyed by user ID, the producer is closed by its owner.
+type kafkaSink struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func (s *kafkaSink) Write(key string, event []byte) error {
+	_, _, err := s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: s.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(event),
+	})
+	return err
+}
+
+func (s *kafkaSink) Close() error {
+	return nil
+}
+
+// fileSink appends events as JSON lines.
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (s *fileSink) Write(_ string, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(append(event, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// syslogSink sends events as messages of the auth facility, the writer reconnects on failures.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func (s *syslogSink) Write(_ string, event []byte) error {
+	return s.writer.Notice(string(event))
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,245 @@
+package events
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+const (
+	defaultSpoolMaxSize = 256 << 20
+	// spoolHeader keeps the offset of the next event to deliver
+	spoolHeader = 8
+	// spoolLength prefixes every event with its length
+	spoolLength = 4
+)
+
+// ErrSpoolFull is returned by Push, when the event exceeds MaxSize of the spool.
+var ErrSpoolFull = errors.New("spool is full")
+
+// SpoolConfig of files of events, which didn't fit queues of Async. Empty Dir disables
+// spooling, so such events are dropped.
+type SpoolConfig struct {
+	// Dir keeps a file per queue, e.g. webhook.spool, events of the file are delivered on start.
+	Dir string `mapstructure:"dir"`
+	// MaxSize of the file in bytes, default is 256 MiB. Events are dropped, when it is exceeded.
+	MaxSize int64 `mapstructure:"max_size"`
+}
+
+// Spool is a file of events in order of publishing. Delivered events are committed by offset
+// in the header, so events are delivered at least once: an event, which is delivered right
+// before the process stops, may be delivered again. The file is truncated, when all events
+// are delivered. Spool is not safe for concurrent use.
+//
+// Events are only appended and read in order, so an append-only file with the committed offset
+// is enough, an embedded key-value store, e.g. bbolt or badger, would add a dependency
+// and a compaction of deleted keys without giving the queue anything.
+type Spool struct {
+	file    *os.File
+	maxSize int64
+	// offset of the next event and the end of the last one
+	read, size int64
+}
+
+// spooled is an event in the file.
+type spooled struct {
+	Type     string       `json:"type"`
+	Change   Change       `json:"change"`
+	OldName  string       `json:"old_name,omitempty"`
+	Previous *models.User `json:"previous,omitempty"`
+}
+
+// NewSpool opens the spool of the queue, it is nil, if spooling is disabled. Events of the tail,
+// which were written partially, are discarded.
+func NewSpool(cfg SpoolConfig, name string) (*Spool, error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultSpoolMaxSize
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "spool: mkdir")
+	}
+	file, err := os.OpenFile(filepath.Join(cfg.Dir, name+".spool"), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "spool: open")
+	}
+	s := &Spool{file: file, maxSize: cfg.MaxSize}
+	if err = s.recover(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Empty reports whether all events are delivered.
+func (s *Spool) Empty() bool {
+	return s.read == s.size
+}
+
+// Push appends the event.
+func (s *Spool) Push(event Event) error {
+	data, err := json.Marshal(newSpooled(event))
+	if err != nil {
+		return errors.Wrap(err, "spool: marshal")
+	}
+	if s.size+spoolLength+int64(len(data)) > s.maxSize {
+		return ErrSpoolFull
+	}
+	buf := make([]byte, spoolLength, spoolLength+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	n, err := s.file.WriteAt(append(buf, data...), s.size)
+	if err != nil {
+		// the partial event is overwritten by the next one
+		return errors.Wrap(err, "spool: write")
+	}
+	s.size += int64(n)
+	return nil
+}
+
+// Peek returns the next event and the offset committing it, the event is nil, if the spool is empty.
+func (s *Spool) Peek() (Event, int64, error) {
+	if s.Empty() {
+		return nil, s.read, nil
+	}
+	data, next, err := s.at(s.read)
+	if err != nil {
+		return nil, s.read, err
+	}
+	var event spooled
+	if err = json.Unmarshal(data, &event); err != nil {
+		// the event is skipped, so it doesn't block the following ones
+		return nil, next, errors.Wrap(err, "spool: unmarshal")
+	}
+	return event.event(), next, nil
+}
+
+// Commit marks events before the offset delivered, the file is truncated, when all of them are.
+// Events are not delivered again by the process, even if the offset fails to be written.
+func (s *Spool) Commit(offset int64) error {
+	s.read = offset
+	if s.Empty() {
+		if err := s.file.Truncate(spoolHeader); err != nil {
+			return errors.Wrap(err, "spool: truncate")
+		}
+		s.read, s.size = spoolHeader, spoolHeader
+	}
+	var header [spoolHeader]byte
+	binary.BigEndian.PutUint64(header[:], uint64(s.read))
+	_, err := s.file.WriteAt(header[:], 0)
+	return errors.Wrap(err, "spool: commit")
+}
+
+// Discard drops events, which are not delivered yet, e.g. the file is unreadable.
+func (s *Spool) Discard() error {
+	return s.Commit(s.size)
+}
+
+func (s *Spool) Close() error {
+	return errors.Wrap(s.file.Close(), "spool: close")
+}
+
+// recover reads the offset of the header and finds the end of the last whole event.
+func (s *Spool) recover() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "spool: stat")
+	}
+	if info.Size() < spoolHeader {
+		return s.Commit(0)
+	}
+
+	var header [spoolHeader]byte
+	if _, err = s.file.ReadAt(header[:], 0); err != nil {
+		return errors.Wrap(err, "spool: read header")
+	}
+	s.read, s.size = int64(binary.BigEndian.Uint64(header[:])), info.Size()
+	if s.read < spoolHeader || s.read > s.size {
+		return errors.Errorf("spool: offset [%d] is out of the file of %d bytes", s.read, s.size)
+	}
+	end := s.read
+	for end < s.size {
+		_, next, err := s.at(end)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		end = next
+	}
+	if end != s.size {
+		if err = s.file.Truncate(end); err != nil {
+			return errors.Wrap(err, "spool: truncate")
+		}
+		s.size = end
+	}
+	return nil
+}
+
+// at reads the event at the offset and returns the offset of the next one.
+func (s *Spool) at(offset int64) ([]byte, int64, error) {
+	var length [spoolLength]byte
+	if err := s.readAt(length[:], offset); err != nil {
+		return nil, offset, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(length[:]))
+	if int64(len(data)) > s.size-offset-spoolLength {
+		return nil, offset, io.ErrUnexpectedEOF
+	}
+	if err := s.readAt(data, offset+spoolLength); err != nil {
+		return nil, offset, err
+	}
+	return data, offset + spoolLength + int64(len(data)), nil
+}
+
+func (s *Spool) readAt(buf []byte, offset int64) error {
+	_, err := s.file.ReadAt(buf, offset)
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return errors.Wrap(err, "spool: read")
+}
+
+// newSpooled returns the event in the file. Legacy plaintext passwords are not written,
+// publishers don't send them anyway.
+func newSpooled(event Event) spooled {
+	e := spooled{Type: TypeUserCreated, Change: event.Base()}
+	if !passwordPkg.Hashed(e.Change.User.Password) {
+		e.Change.User.Password = ""
+	}
+	switch event := event.(type) {
+	case UserUpdated:
+		previous := event.Previous
+		if !passwordPkg.Hashed(previous.Password) {
+			previous.Password = ""
+		}
+		e.Type, e.OldName, e.Previous = TypeUserUpdated, event.OldName, &previous
+	case UserDeleted:
+		e.Type = TypeUserDeleted
+	}
+	return e
+}
+
+func (e spooled) event() Event {
+	switch e.Type {
+	case TypeUserUpdated:
+		updated := UserUpdated{Change: e.Change, OldName: e.OldName}
+		if e.Previous != nil {
+			updated.Previous = *e.Previous
+		}
+		return updated
+	case TypeUserDeleted:
+		return UserDeleted{Change: e.Change}
+	default:
+		return UserCreated{Change: e.Change}
+	}
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+func TestSpool(t *testing.T) {
+	hash, err := passwordPkg.Hash("secret")
+	require.NoError(t, err)
+	cfg := SpoolConfig{Dir: t.TempDir()}
+	path := filepath.Join(cfg.Dir, "test.spool")
+
+	events := []Event{
+		UserCreated{Change: Change{Action: "create", Actor: "admin", User: models.User{ID: "1", Name: "ivan", Password: hash}}},
+		UserUpdated{
+			Change:   Change{Action: "rename", User: models.User{ID: "1", Name: "petr", Password: hash}},
+			OldName:  "ivan",
+			Previous: models.User{ID: "1", Name: "ivan", Password: hash},
+		},
+		UserDeleted{Change: Change{Action: "delete", User: models.User{ID: "1", Name: "petr"}}},
+	}
+	spool, err := NewSpool(cfg, "test")
+	require.NoError(t, err)
+	for _, event := range events {
+		require.NoError(t, spool.Push(event))
+	}
+
+	// the first event is committed, the others are read after reopening
+	event, next, err := spool.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, events[0], event)
+	require.NoError(t, spool.Commit(next))
+	require.NoError(t, spool.Close())
+
+	spool, err = NewSpool(cfg, "test")
+	require.NoError(t, err)
+	for _, expected := range events[1:] {
+		event, next, err = spool.Peek()
+		require.NoError(t, err)
+		assert.Equal(t, expected, event)
+		require.NoError(t, spool.Commit(next))
+	}
+	event, _, err = spool.Peek()
+	require.NoError(t, err)
+	assert.Nil(t, event)
+	assert.True(t, spool.Empty())
+	require.NoError(t, spool.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, spoolHeader, info.Size())
+}
+
+func TestSpool_Recover(t *testing.T) {
+	cfg := SpoolConfig{Dir: t.TempDir()}
+	spool, err := NewSpool(cfg, "test")
+	require.NoError(t, err)
+	require.NoError(t, spool.Push(UserCreated{Change: Change{User: models.User{Name: "ivan", Password: "plain"}}}))
+	require.NoError(t, spool.Push(UserCreated{Change: Change{User: models.User{Name: "petr"}}}))
+	require.NoError(t, spool.Close())
+
+	// the second event is written partially
+	path := filepath.Join(cfg.Dir, "test.spool")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+
+	spool, err = NewSpool(cfg, "test")
+	require.NoError(t, err)
+	defer spool.Close()
+	event, next, err := spool.Peek()
+	require.NoError(t, err)
+	// plaintext passwords are not written
+	assert.Equal(t, UserCreated{Change: Change{User: models.User{Name: "ivan"}}}, event)
+	require.NoError(t, spool.Commit(next))
+	assert.True(t, spool.Empty())
+}
+
+func TestSpool_Full(t *testing.T) {
+	cfg := SpoolConfig{Dir: t.TempDir(), MaxSize: 4 << 10}
+	spool, err := NewSpool(cfg, "test")
+	require.NoError(t, err)
+	defer spool.Close()
+
+	var pushed int
+	for ; pushed < 100; pushed++ {
+		if err = spool.Push(UserCreated{Change: Change{User: models.User{Name: "ivan"}}}); err != nil {
+			break
+		}
+	}
+	assert.ErrorIs(t, err, ErrSpoolFull)
+	assert.NotZero(t, pushed)
+
+	info, err := os.Stat(filepath.Join(cfg.Dir, "test.spool"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), cfg.MaxSize)
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+// SignatureHeader keeps HMAC-SHA256 of the webhook body by the secret, e.g. "sha256=5d41...".
+const SignatureHeader = "X-Signature"
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	defaultWebhookRetries = 3
+)
+
+// webhookBackoff is a delay of the first retry, it doubles every retry.
+var webhookBackoff = 500 * time.Millisecond
+
+// WebhookConfig of the webhook, empty URL disables it. Events are posted as JSON of the messages
+// of the Kafka topic, passwords are never posted.
+type WebhookConfig struct {
+	URL string `mapstructure:"url"`
+	// Secret signs bodies in SignatureHeader, receivers verify events by it.
+	Secret  string        `mapstructure:"secret"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Retries of failed posts, default is 3. The event is dropped after them.
+	Retries int `mapstructure:"retries"`
+}
+
+// WebhookPublisher returns handler, which posts events to the URL. Failed posts are repeated
+// with backoff, unless the receiver rejects the event with a client error.
+// The handler is slow, it is subscribed via Async.
+func WebhookPublisher(cfg WebhookConfig, client *http.Client, logger *zap.SugaredLogger) Handler {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = defaultWebhookRetries
+	}
+	return func(ctx context.Context, event Event) {
+		msg := newMessage(event)
+		msg.User.Password = ""
+		body, err := json.Marshal(msg)
+		if err != nil {
+			logger.Errorw("post webhook event", "name", msg.User.Name, "error", err.Error())
+			return
+		}
+
+		for attempt := 0; ; attempt++ {
+			var retry bool
+			if retry, err = post(ctx, client, cfg, body); err == nil {
+				counter.WebhookSent.Inc()
+				return
+			}
+			if !retry || attempt == cfg.Retries {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case <-time.After(webhookBackoff << attempt):
+				continue
+			}
+			break
+		}
+		counter.WebhookFailed.Inc()
+		logger.Errorw("post webhook event", "name", msg.User.Name, "type", msg.Type, "error", err.Error())
+	}
+}
+
+// post sends the body and reports whether the failed post may be repeated.
+func post(ctx context.Context, client *http.Client, cfg WebhookConfig, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "new request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(cfg.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "post")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, errors.Errorf("webhook response: [%s]", resp.Status)
+	default:
+		return false, errors.Errorf("webhook response: [%s]", resp.Status)
+	}
+}
+
+// Sign returns value of SignatureHeader of the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
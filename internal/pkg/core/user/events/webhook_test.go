@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	webhookBackoff = time.Millisecond
+	tests := []struct {
+		name     string
+		statuses []int
+		calls    int32
+	}{
+		{
+			name:     "delivered",
+			statuses: []int{http.StatusNoContent},
+			calls:    1,
+		},
+		{
+			name:     "server errors are repeated",
+			statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			calls:    3,
+		},
+		{
+			name:     "client errors are not repeated",
+			statuses: []int{http.StatusBadRequest},
+			calls:    1,
+		},
+		{
+			name:     "retries exhausted",
+			statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			calls:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+
+				var msg message
+				require.NoError(t, json.Unmarshal(body, &msg))
+				assert.Equal(t, message{Type: TypeUserUpdated, Action: "rename", OldName: "ivan", User: models.User{Name: "petr"}}, msg)
+
+				w.WriteHeader(tt.statuses[atomic.AddInt32(&calls, 1)-1])
+			}))
+			defer server.Close()
+
+			publish := WebhookPublisher(WebhookConfig{URL: server.URL, Secret: "secret", Retries: 2}, server.Client(), loggerPkg.NewFatal())
+			publish(context.Background(), UserUpdated{
+				Change:  Change{Action: "rename", User: models.User{Name: "petr", Password: "hash"}},
+				OldName: "ivan",
+			})
+			assert.Equal(t, tt.calls, atomic.LoadInt32(&calls))
+		})
+	}
+}
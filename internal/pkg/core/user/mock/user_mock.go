@@ -7,9 +7,12 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	history "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	blob "gitlab.ozon.dev/iTukaev/homework/pkg/blob"
 )
 
 // MockInterface is a mock of Interface interface.
@@ -35,6 +38,128 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AvatarGet mocks base method.
+func (m *MockInterface) AvatarGet(ctx context.Context, name string) (blob.Object, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvatarGet", ctx, name)
+	ret0, _ := ret[0].(blob.Object)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AvatarGet indicates an expected call of AvatarGet.
+func (mr *MockInterfaceMockRecorder) AvatarGet(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvatarGet", reflect.TypeOf((*MockInterface)(nil).AvatarGet), ctx, name)
+}
+
+// AvatarUpload mocks base method.
+func (m *MockInterface) AvatarUpload(ctx context.Context, name string, data []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvatarUpload", ctx, name, data)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AvatarUpload indicates an expected call of AvatarUpload.
+func (mr *MockInterfaceMockRecorder) AvatarUpload(ctx, name, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvatarUpload", reflect.TypeOf((*MockInterface)(nil).AvatarUpload), ctx, name, data)
+}
+
+// CacheEvict mocks base method.
+func (m *MockInterface) CacheEvict(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheEvict", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheEvict indicates an expected call of CacheEvict.
+func (mr *MockInterfaceMockRecorder) CacheEvict(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheEvict", reflect.TypeOf((*MockInterface)(nil).CacheEvict), ctx, name)
+}
+
+// CacheGet mocks base method.
+func (m *MockInterface) CacheGet(ctx context.Context, name string) (models.CachedUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheGet", ctx, name)
+	ret0, _ := ret[0].(models.CachedUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheGet indicates an expected call of CacheGet.
+func (mr *MockInterfaceMockRecorder) CacheGet(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheGet", reflect.TypeOf((*MockInterface)(nil).CacheGet), ctx, name)
+}
+
+// CacheStats mocks base method.
+func (m *MockInterface) CacheStats(ctx context.Context) (models.CacheStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStats", ctx)
+	ret0, _ := ret[0].(models.CacheStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockInterfaceMockRecorder) CacheStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockInterface)(nil).CacheStats), ctx)
+}
+
+// Changes mocks base method.
+func (m *MockInterface) Changes(ctx context.Context, since int64, after string, limit uint64) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changes", ctx, since, after, limit)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changes indicates an expected call of Changes.
+func (mr *MockInterfaceMockRecorder) Changes(ctx, since, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockInterface)(nil).Changes), ctx, since, after, limit)
+}
+
+// CheckPassword mocks base method.
+func (m *MockInterface) CheckPassword(ctx context.Context, name, password string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPassword", ctx, name, password)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckPassword indicates an expected call of CheckPassword.
+func (mr *MockInterfaceMockRecorder) CheckPassword(ctx, name, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPassword", reflect.TypeOf((*MockInterface)(nil).CheckPassword), ctx, name, password)
+}
+
+// Count mocks base method.
+func (m *MockInterface) Count(ctx context.Context, tenant string) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, tenant)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockInterfaceMockRecorder) Count(ctx, tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockInterface)(nil).Count), ctx, tenant)
+}
+
 // Create mocks base method.
 func (m *MockInterface) Create(ctx context.Context, user models.User) error {
 	m.ctrl.T.Helper()
@@ -78,6 +203,49 @@ func (mr *MockInterfaceMockRecorder) Delete(ctx, name interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockInterface)(nil).Delete), ctx, name)
 }
 
+// Disable mocks base method.
+func (m *MockInterface) Disable(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockInterfaceMockRecorder) Disable(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockInterface)(nil).Disable), ctx, name)
+}
+
+// Enable mocks base method.
+func (m *MockInterface) Enable(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockInterfaceMockRecorder) Enable(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockInterface)(nil).Enable), ctx, name)
+}
+
+// ExpirePasswords mocks base method.
+func (m *MockInterface) ExpirePasswords(ctx context.Context, names []string, attributes map[string]string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePasswords", ctx, names, attributes)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePasswords indicates an expected call of ExpirePasswords.
+func (mr *MockInterfaceMockRecorder) ExpirePasswords(ctx, names, attributes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePasswords", reflect.TypeOf((*MockInterface)(nil).ExpirePasswords), ctx, names, attributes)
+}
+
 // Get mocks base method.
 func (m *MockInterface) Get(ctx context.Context, name string) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -93,31 +261,289 @@ func (mr *MockInterfaceMockRecorder) Get(ctx, name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), ctx, name)
 }
 
+// GetByID mocks base method.
+func (m *MockInterface) GetByID(ctx context.Context, id string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockInterfaceMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockInterface)(nil).GetByID), ctx, id)
+}
+
+// GetIfChanged mocks base method.
+func (m *MockInterface) GetIfChanged(ctx context.Context, name, knownVersion string, wait time.Duration) (models.User, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIfChanged", ctx, name, knownVersion, wait)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetIfChanged indicates an expected call of GetIfChanged.
+func (mr *MockInterfaceMockRecorder) GetIfChanged(ctx, name, knownVersion, wait interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIfChanged", reflect.TypeOf((*MockInterface)(nil).GetIfChanged), ctx, name, knownVersion, wait)
+}
+
+// Impersonations mocks base method.
+func (m *MockInterface) Impersonations(ctx context.Context, since time.Time, limit uint64) ([]history.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonations", ctx, since, limit)
+	ret0, _ := ret[0].([]history.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonations indicates an expected call of Impersonations.
+func (mr *MockInterfaceMockRecorder) Impersonations(ctx, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonations", reflect.TypeOf((*MockInterface)(nil).Impersonations), ctx, since, limit)
+}
+
+// Import mocks base method.
+func (m *MockInterface) Import(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, user, strategy)
+	ret0, _ := ret[0].(models.ImportStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockInterfaceMockRecorder) Import(ctx, user, strategy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockInterface)(nil).Import), ctx, user, strategy)
+}
+
+// ImportCheck mocks base method.
+func (m *MockInterface) ImportCheck(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCheck", ctx, user, strategy)
+	ret0, _ := ret[0].(models.ImportStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCheck indicates an expected call of ImportCheck.
+func (mr *MockInterfaceMockRecorder) ImportCheck(ctx, user, strategy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCheck", reflect.TypeOf((*MockInterface)(nil).ImportCheck), ctx, user, strategy)
+}
+
+// Invalidate mocks base method.
+func (m *MockInterface) Invalidate(ctx context.Context, names ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range names {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Invalidate", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockInterfaceMockRecorder) Invalidate(ctx interface{}, names ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, names...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockInterface)(nil).Invalidate), varargs...)
+}
+
 // List mocks base method.
-func (m *MockInterface) List(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error) {
+func (m *MockInterface) List(ctx context.Context, order bool, limit, offset uint64, attributes map[string]string, status string) ([]models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, order, limit, offset)
+	ret := m.ctrl.Call(m, "List", ctx, order, limit, offset, attributes, status)
 	ret0, _ := ret[0].([]models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockInterfaceMockRecorder) List(ctx, order, limit, offset interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) List(ctx, order, limit, offset, attributes, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockInterface)(nil).List), ctx, order, limit, offset, attributes, status)
+}
+
+// ListAfter mocks base method.
+func (m *MockInterface) ListAfter(ctx context.Context, order bool, limit uint64, after string, attributes map[string]string, status string) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, order, limit, after, attributes, status)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockInterfaceMockRecorder) ListAfter(ctx, order, limit, after, attributes, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockInterface)(nil).ListAfter), ctx, order, limit, after, attributes, status)
+}
+
+// ListAt mocks base method.
+func (m *MockInterface) ListAt(ctx context.Context, at time.Time, order bool, limit uint64, after string, attributes map[string]string, status string) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAt", ctx, at, order, limit, after, attributes, status)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAt indicates an expected call of ListAt.
+func (mr *MockInterfaceMockRecorder) ListAt(ctx, at, order, limit, after, attributes, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAt", reflect.TypeOf((*MockInterface)(nil).ListAt), ctx, at, order, limit, after, attributes, status)
+}
+
+// LoginExternal mocks base method.
+func (m *MockInterface) LoginExternal(ctx context.Context, identity models.Identity) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginExternal", ctx, identity)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginExternal indicates an expected call of LoginExternal.
+func (mr *MockInterfaceMockRecorder) LoginExternal(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginExternal", reflect.TypeOf((*MockInterface)(nil).LoginExternal), ctx, identity)
+}
+
+// Logout mocks base method.
+func (m *MockInterface) Logout(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockInterfaceMockRecorder) Logout(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockInterface)(nil).Logout), ctx, token)
+}
+
+// PruneTombstones mocks base method.
+func (m *MockInterface) PruneTombstones(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneTombstones", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneTombstones indicates an expected call of PruneTombstones.
+func (mr *MockInterfaceMockRecorder) PruneTombstones(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneTombstones", reflect.TypeOf((*MockInterface)(nil).PruneTombstones), ctx, now)
+}
+
+// ReconcileCounts mocks base method.
+func (m *MockInterface) ReconcileCounts(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileCounts", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileCounts indicates an expected call of ReconcileCounts.
+func (mr *MockInterfaceMockRecorder) ReconcileCounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileCounts", reflect.TypeOf((*MockInterface)(nil).ReconcileCounts), ctx)
+}
+
+// Rename mocks base method.
+func (m *MockInterface) Rename(ctx context.Context, oldName, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, oldName, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockInterfaceMockRecorder) Rename(ctx, oldName, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockInterface)(nil).Rename), ctx, oldName, newName)
+}
+
+// Restore mocks base method.
+func (m *MockInterface) Restore(ctx context.Context, user models.User, overwrite bool) (models.ImportStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, user, overwrite)
+	ret0, _ := ret[0].(models.ImportStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockInterfaceMockRecorder) Restore(ctx, user, overwrite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockInterface)(nil).Restore), ctx, user, overwrite)
+}
+
+// SessionUser mocks base method.
+func (m *MockInterface) SessionUser(ctx context.Context, token string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionUser", ctx, token)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SessionUser indicates an expected call of SessionUser.
+func (mr *MockInterfaceMockRecorder) SessionUser(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionUser", reflect.TypeOf((*MockInterface)(nil).SessionUser), ctx, token)
+}
+
+// StateAt mocks base method.
+func (m *MockInterface) StateAt(ctx context.Context, name string, at time.Time, restore bool) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateAt", ctx, name, at, restore)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateAt indicates an expected call of StateAt.
+func (mr *MockInterfaceMockRecorder) StateAt(ctx, name, at, restore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateAt", reflect.TypeOf((*MockInterface)(nil).StateAt), ctx, name, at, restore)
+}
+
+// Tombstones mocks base method.
+func (m *MockInterface) Tombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tombstones", ctx, since)
+	ret0, _ := ret[0].([]models.Tombstone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tombstones indicates an expected call of Tombstones.
+func (mr *MockInterfaceMockRecorder) Tombstones(ctx, since interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockInterface)(nil).List), ctx, order, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tombstones", reflect.TypeOf((*MockInterface)(nil).Tombstones), ctx, since)
 }
 
 // Update mocks base method.
-func (m *MockInterface) Update(ctx context.Context, user models.User) error {
+func (m *MockInterface) Update(ctx context.Context, update models.UserUpdate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, user)
+	ret := m.ctrl.Call(m, "Update", ctx, update)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockInterfaceMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Update(ctx, update interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), ctx, update)
 }
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// DNAttribute links the user to its directory entry, it is written by the directory sync only.
+const DNAttribute = "ldap_dn"
+
+// TenantAttribute selects policies of the user tenant. It is set on creation, which authorization
+// policies check, and kept by later writes, so clients don't move users to a laxer tenant policy.
+const TenantAttribute = "tenant"
+
+const (
+	MaxAttributes           = 32
+	MaxAttributeKeyLength   = 64
+	MaxAttributeValueLength = 256
+)
+
+// ValidateAttributes returns ErrValidation, if the attributes exceed the limits. Updates and merges
+// add attributes to the stored ones, so the limits are checked on the result before it is written.
+func ValidateAttributes(attributes map[string]string) error {
+	if len(attributes) > MaxAttributes {
+		return errors.Wrapf(errorsPkg.ErrValidation, "field: [attributes] more than %d keys", MaxAttributes)
+	}
+	for key, value := range attributes {
+		if key == "" || len(key) > MaxAttributeKeyLength {
+			return errors.Wrapf(errorsPkg.ErrValidation,
+				"field: [attributes] key length must be from 1 to %d", MaxAttributeKeyLength)
+		}
+		if len(value) > MaxAttributeValueLength {
+			return errors.Wrapf(errorsPkg.ErrValidation,
+				"field: [attributes.%s] value is longer than %d", key, MaxAttributeValueLength)
+		}
+	}
+	return nil
+}
+
+// reserved attributes are written by the service itself, e.g. links to the external identity
+// and to the directory entry, clients can't set or remove them.
+var reserved = map[string]struct{}{
+	SubjectAttribute: {},
+	DNAttribute:      {},
+}
+
+// ValidateWritable returns ErrValidation, if attributes or update paths written by a client
+// address reserved attributes.
+func ValidateWritable(attributes map[string]string, paths []string) error {
+	for key := range attributes {
+		if isReserved(key) {
+			return errors.Wrapf(errorsPkg.ErrValidation, "field: [attributes.%s] is reserved", key)
+		}
+	}
+	for _, path := range paths {
+		if !strings.HasPrefix(path, attributesPrefix) {
+			continue
+		}
+		if key := strings.TrimPrefix(path, attributesPrefix); isReserved(key) {
+			return errors.Wrapf(errorsPkg.ErrValidation, "field: [attributes.%s] is reserved", key)
+		}
+	}
+	return nil
+}
+
+// KeepReserved returns the written attributes with reserved attributes of the stored user,
+// so attributes replaced by a client keep them. The written map is not changed.
+func KeepReserved(written, stored map[string]string) map[string]string {
+	var kept map[string]string
+	for key := range reserved {
+		value, ok := stored[key]
+		if !ok {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string, len(written)+1)
+			for k, v := range written {
+				kept[k] = v
+			}
+		}
+		kept[key] = value
+	}
+	if kept == nil {
+		return written
+	}
+	return kept
+}
+
+// KeepTenant returns the written attributes with the tenant of the stored user, the written map
+// is not changed. ErrValidation is returned, if the written tenant differs from the stored one.
+func KeepTenant(written, stored map[string]string) (map[string]string, error) {
+	tenant, ok := stored[TenantAttribute]
+	if value, set := written[TenantAttribute]; set && value != tenant {
+		return nil, errors.Wrapf(errorsPkg.ErrValidation, "field: [attributes.%s] is set on creation only", TenantAttribute)
+	}
+	if !ok || written[TenantAttribute] == tenant {
+		return written, nil
+	}
+	kept := make(map[string]string, len(written)+1)
+	for k, v := range written {
+		kept[k] = v
+	}
+	kept[TenantAttribute] = tenant
+	return kept, nil
+}
+
+func isReserved(key string) bool {
+	_, ok := reserved[key]
+	return ok
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func Test_ValidateWritable(t *testing.T) {
+	cases := []struct {
+		name       string
+		attributes map[string]string
+		paths      []string
+		expErr     error
+	}{
+		{
+			name:       "success",
+			attributes: map[string]string{"team": "core"},
+			paths:      []string{MaskEmail, "attributes.team"},
+		},
+		{
+			name:       "failed, reserved attribute set",
+			attributes: map[string]string{SubjectAttribute: "issuer|subject"},
+			expErr:     errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, reserved attribute removed",
+			paths:  []string{"attributes." + SubjectAttribute},
+			expErr: errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateWritable(c.attributes, c.paths), c.expErr)
+		})
+	}
+}
+
+func Test_KeepReserved(t *testing.T) {
+	written := map[string]string{"team": "core"}
+	stored := map[string]string{"team": "ops", SubjectAttribute: "issuer|subject"}
+
+	kept := KeepReserved(written, stored)
+	assert.Equal(t, map[string]string{"team": "core", SubjectAttribute: "issuer|subject"}, kept)
+	assert.Equal(t, map[string]string{"team": "core"}, written)
+
+	assert.Nil(t, KeepReserved(nil, map[string]string{"team": "ops"}))
+}
+
+func Test_KeepTenant(t *testing.T) {
+	cases := []struct {
+		name    string
+		written map[string]string
+		stored  map[string]string
+		exp     map[string]string
+		expErr  error
+	}{
+		{
+			name:    "success, stored tenant kept",
+			written: map[string]string{"team": "core"},
+			stored:  map[string]string{TenantAttribute: "staff"},
+			exp:     map[string]string{"team": "core", TenantAttribute: "staff"},
+		},
+		{
+			name:    "success, same tenant written",
+			written: map[string]string{TenantAttribute: "staff"},
+			stored:  map[string]string{TenantAttribute: "staff"},
+			exp:     map[string]string{TenantAttribute: "staff"},
+		},
+		{
+			name:    "success, no tenant",
+			written: map[string]string{"team": "core"},
+			exp:     map[string]string{"team": "core"},
+		},
+		{
+			name:    "failed, tenant changed",
+			written: map[string]string{TenantAttribute: "service"},
+			stored:  map[string]string{TenantAttribute: "staff"},
+			expErr:  errorsPkg.ErrValidation,
+		},
+		{
+			name:    "failed, tenant added",
+			written: map[string]string{TenantAttribute: "service"},
+			expErr:  errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kept, err := KeepTenant(c.written, c.stored)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.exp, kept)
+		})
+	}
+}
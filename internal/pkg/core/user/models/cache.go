@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// CachedUser is the user as it is kept by the cache.
+type CachedUser struct {
+	User User
+	// TTL is the remaining time to live of the entry.
+	TTL time.Duration
+}
+
+// CacheStats of the cache. Hits and misses are counted by this instance since its start,
+// keys and the list generation are shared by all instances.
+type CacheStats struct {
+	Hits           uint64
+	Misses         uint64
+	ListHits       uint64
+	ListMisses     uint64
+	Keys           int64
+	ListGeneration int64
+}
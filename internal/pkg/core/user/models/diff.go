@@ -0,0 +1,26 @@
+package models
+
+import "reflect"
+
+// Diff returns names of the different fields, nil and empty maps are equal.
+func Diff(a, b User) []string {
+	var fields []string
+	aValue, bValue := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < aValue.NumField(); i++ {
+		x, y := aValue.Field(i).Interface(), bValue.Field(i).Interface()
+		if reflect.DeepEqual(x, y) || empty(x) && empty(y) {
+			continue
+		}
+		fields = append(fields, aValue.Type().Field(i).Name)
+	}
+	return fields
+}
+
+// empty reports whether the value is zero, nil and empty maps are equal.
+func empty(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Map || value.Kind() == reflect.Slice {
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	exp := User{Name: "alice", Password: "secret", Attributes: map[string]string{}}
+	got := User{Name: "alice", Password: "other", Email: "alice@example.com"}
+	assert.Equal(t, []string{"Password", "Email"}, Diff(exp, got))
+	assert.Empty(t, Diff(exp, exp))
+}
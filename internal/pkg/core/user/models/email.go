@@ -0,0 +1,19 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+var email = regexp.MustCompile(`^.+@[A-Za-z0-9\-_\.]+$`)
+
+// ValidateEmail returns ErrValidation for malformed email.
+func ValidateEmail(value string) error {
+	if !email.MatchString(value) {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [email] has invalid format")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// ImportStrategy defines how an imported user is applied, if the name is already taken.
+type ImportStrategy string
+
+const (
+	ImportSkip      ImportStrategy = "skip"
+	ImportOverwrite ImportStrategy = "overwrite"
+	ImportMerge     ImportStrategy = "merge"
+	ImportFail      ImportStrategy = "fail"
+)
+
+// Validate returns ErrValidation for unknown strategy.
+func (s ImportStrategy) Validate() error {
+	switch s {
+	case ImportSkip, ImportOverwrite, ImportMerge, ImportFail:
+		return nil
+	}
+	return errors.Wrapf(errorsPkg.ErrValidation, "field: [strategy] unknown value: [%s]", s)
+}
+
+// ImportStatus is a result of the imported row.
+type ImportStatus string
+
+const (
+	ImportCreated     ImportStatus = "created"
+	ImportSkipped     ImportStatus = "skipped"
+	ImportOverwritten ImportStatus = "overwritten"
+	ImportMerged      ImportStatus = "merged"
+	ImportFailed      ImportStatus = "failed"
+)
+
+// Overwrite replaces fields of the existing user, its ID, creation time, status, password
+// timestamps and reserved attributes are kept. Timestamps are set again, if the password is changed.
+func Overwrite(existing, imported User) User {
+	imported.ID = existing.ID
+	imported.Name = existing.Name
+	imported.CreatedAt = existing.CreatedAt
+	imported.Status = existing.Status
+	imported.PasswordChangedAt = existing.PasswordChangedAt
+	imported.PasswordExpiresAt = existing.PasswordExpiresAt
+	imported.Attributes = KeepReserved(imported.Attributes, existing.Attributes)
+	return imported
+}
+
+// Merge copies not empty fields of the imported user to the existing one.
+// Attributes are merged by key, imported values win.
+func Merge(existing, imported User) User {
+	if imported.Password != "" {
+		existing.Password = imported.Password
+	}
+	if imported.Email != "" {
+		existing.Email = imported.Email
+	}
+	if imported.FullName != "" {
+		existing.FullName = imported.FullName
+	}
+	if len(imported.Attributes) != 0 {
+		attributes := make(map[string]string, len(existing.Attributes)+len(imported.Attributes))
+		for key, value := range existing.Attributes {
+			attributes[key] = value
+		}
+		for key, value := range imported.Attributes {
+			attributes[key] = value
+		}
+		existing.Attributes = attributes
+	}
+	return existing
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+const (
+	MaskPassword   = "password"
+	MaskEmail      = "email"
+	MaskFullName   = "full_name"
+	MaskAttributes = "attributes"
+
+	// attributesPrefix addresses a single attribute, e.g. "attributes.team".
+	attributesPrefix = MaskAttributes + "."
+)
+
+// UserUpdate is an update message, only fields listed in Mask are applied.
+// Empty mask updates password, email, full name and, if set, attributes.
+type UserUpdate struct {
+	User
+	Mask []string `json:"update_mask,omitempty"`
+}
+
+// Paths returns update mask, default one is used when mask is empty.
+func (u *UserUpdate) Paths() []string {
+	if len(u.Mask) != 0 {
+		return u.Mask
+	}
+	paths := []string{MaskPassword, MaskEmail, MaskFullName}
+	if u.Attributes != nil {
+		paths = append(paths, MaskAttributes)
+	}
+	return paths
+}
+
+// ValidateMask returns ErrValidation, if mask contains unknown path.
+func ValidateMask(paths []string) error {
+	for _, path := range paths {
+		switch {
+		case path == MaskPassword, path == MaskEmail, path == MaskFullName, path == MaskAttributes:
+		case strings.HasPrefix(path, attributesPrefix) && len(path) > len(attributesPrefix):
+		default:
+			return errors.Wrapf(errorsPkg.ErrValidation, "field: [update_mask] unknown path: [%s]", path)
+		}
+	}
+	return nil
+}
+
+// ApplyMask copies fields listed in paths from src to dst.
+// Path "attributes.<key>" sets single attribute or removes it, if src has no such key.
+func ApplyMask(dst *User, src User, paths []string) error {
+	if err := ValidateMask(paths); err != nil {
+		return err
+	}
+	// dst attributes may be shared with repository, so they are changed on a copy
+	if dst.Attributes != nil {
+		attributes := make(map[string]string, len(dst.Attributes))
+		for key, value := range dst.Attributes {
+			attributes[key] = value
+		}
+		dst.Attributes = attributes
+	}
+
+	for _, path := range paths {
+		switch path {
+		case MaskPassword:
+			dst.Password = src.Password
+		case MaskEmail:
+			dst.Email = src.Email
+		case MaskFullName:
+			dst.FullName = src.FullName
+		case MaskAttributes:
+			dst.Attributes = src.Attributes
+		default:
+			key := strings.TrimPrefix(path, attributesPrefix)
+			value, ok := src.Attributes[key]
+			if !ok {
+				delete(dst.Attributes, key)
+				continue
+			}
+			if dst.Attributes == nil {
+				dst.Attributes = make(map[string]string)
+			}
+			dst.Attributes[key] = value
+		}
+	}
+	return nil
+}
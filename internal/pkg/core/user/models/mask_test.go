@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func Test_ApplyMask(t *testing.T) {
+	cases := []struct {
+		name   string
+		src    User
+		paths  []string
+		exp    User
+		expErr error
+	}{
+		{
+			name:  "full name only",
+			src:   User{Password: "new", FullName: "Ivan"},
+			paths: []string{MaskFullName},
+			exp:   User{Name: "ivan", Password: "old", FullName: "Ivan", Attributes: map[string]string{"team": "a"}},
+		},
+		{
+			name:  "set and remove attribute",
+			src:   User{Attributes: map[string]string{"role": "dev"}},
+			paths: []string{"attributes.role", "attributes.team"},
+			exp:   User{Name: "ivan", Password: "old", Attributes: map[string]string{"role": "dev"}},
+		},
+		{
+			name:  "replace attributes",
+			src:   User{Attributes: map[string]string{"role": "dev"}},
+			paths: []string{MaskAttributes},
+			exp:   User{Name: "ivan", Password: "old", Attributes: map[string]string{"role": "dev"}},
+		},
+		{
+			name:   "unknown path",
+			paths:  []string{"name"},
+			expErr: errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old := map[string]string{"team": "a"}
+			dst := User{Name: "ivan", Password: "old", Attributes: old}
+
+			err := ApplyMask(&dst, c.src, c.paths)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, c.exp, dst)
+			}
+			assert.Equal(t, map[string]string{"team": "a"}, old)
+		})
+	}
+}
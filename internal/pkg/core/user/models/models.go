@@ -3,25 +3,71 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 )
 
 type User struct {
+	// ID is an immutable identifier, name can be changed by rename.
+	ID        string `json:"id" db:"id"`
 	Name      string `json:"name" db:"name"`
 	Password  string `json:"password" db:"password"`
 	Email     string `json:"email" db:"email"`
 	FullName  string `json:"full_name" db:"full_name"`
 	CreatedAt int64  `json:"created_at" db:"created_at"`
+	Status    string `json:"status" db:"status"`
+	// PasswordChangedAt is a time of the last password change in UNIX format.
+	PasswordChangedAt int64 `json:"password_changed_at" db:"password_changed_at"`
+	// PasswordExpiresAt is a time in UNIX format, since which the password must be changed, 0 if it never expires.
+	PasswordExpiresAt int64             `json:"password_expires_at,omitempty" db:"password_expires_at"`
+	Attributes        map[string]string `json:"attributes,omitempty" db:"attributes"`
+	AvatarURL         string            `json:"avatar_url,omitempty" db:"-"`
+	// HLC is a hybrid logical clock timestamp of the last write, it resolves replicated writes.
+	HLC uint64 `json:"hlc,omitempty" db:"hlc"`
+	// UpdatedAt is a time of the last write in UNIX format, incremental exports select users by it.
+	UpdatedAt int64 `json:"updated_at,omitempty" db:"updated_at"`
 }
 
 func (u *User) String() string {
-	return fmt.Sprintf("name: [%s], full_name: [%s], email: [%s], created_at: [%v]",
-		u.Name, u.FullName, u.Email, time.Unix(u.CreatedAt, 0))
+	return fmt.Sprintf("id: [%s], name: [%s], full_name: [%s], email: [%s], created_at: [%v], status: [%s], attributes: %v",
+		u.ID, u.Name, u.FullName, u.Email, time.Unix(u.CreatedAt, 0), u.Status, u.Attributes)
+}
+
+// PasswordExpired reports whether the password must be changed before the next login.
+func (u *User) PasswordExpired(now time.Time) bool {
+	return u.PasswordExpiresAt != 0 && now.Unix() >= u.PasswordExpiresAt
+}
+
+// Active reports whether the user can log in.
+func (u *User) Active() bool {
+	return u.Status == StatusActive
+}
+
+// Version identifies the stored state of the user, any change of it changes the version.
+// Avatar URL is not stored and may be signed per request, so it is not versioned.
+func (u *User) Version() string {
+	stored := *u
+	stored.AvatarURL = ""
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
 }
 
 type UserListParams struct {
-	Limit  uint64 `json:"limit"`
-	Offset uint64 `json:"offset"`
-	Order  bool   `json:"order"`
+	Limit      uint64            `json:"limit"`
+	Offset     uint64            `json:"offset"`
+	Order      bool              `json:"order"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Status     string            `json:"status,omitempty"`
+}
+
+type UserRename struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name"`
 }
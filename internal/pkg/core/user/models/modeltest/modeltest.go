@@ -0,0 +1,210 @@
+// Package modeltest contains builders and fixtures of users for tests.
+package modeltest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/google/uuid"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// Role is kept in the "role" attribute, users have no dedicated field for it.
+type Role string
+
+const (
+	RoleAttribute = "role"
+
+	Admin  Role = "admin"
+	Member Role = "member"
+)
+
+//go:embed testdata/users.json
+var fixturesFS embed.FS
+
+var fixtures map[string]models.User
+
+func init() {
+	data, err := fixturesFS.ReadFile("testdata/users.json")
+	if err != nil {
+		panic(err)
+	}
+	if err = json.Unmarshal(data, &fixtures); err != nil {
+		panic(err)
+	}
+}
+
+// Fixture returns copy of the golden user, it panics if there is no such fixture.
+func Fixture(key string) models.User {
+	user, ok := fixtures[key]
+	if !ok {
+		panic(fmt.Sprintf("modeltest: unknown fixture [%s]", key))
+	}
+	return copyUser(user)
+}
+
+// Ivan returns golden user without attributes.
+func Ivan() models.User {
+	return Fixture("ivan")
+}
+
+// Boris returns golden user with attribute team=core.
+func Boris() models.User {
+	return Fixture("boris")
+}
+
+// Arnold returns golden user without attributes.
+func Arnold() models.User {
+	return Fixture("arnold")
+}
+
+// UserBuilder builds user, which is valid by default.
+type UserBuilder struct {
+	user models.User
+}
+
+// NewUser returns builder of the user based on Ivan fixture.
+func NewUser() *UserBuilder {
+	return &UserBuilder{user: Ivan()}
+}
+
+// From returns builder based on the user.
+func From(user models.User) *UserBuilder {
+	return &UserBuilder{user: copyUser(user)}
+}
+
+func (b *UserBuilder) WithID(id string) *UserBuilder {
+	b.user.ID = id
+	return b
+}
+
+func (b *UserBuilder) WithName(name string) *UserBuilder {
+	b.user.Name = name
+	return b
+}
+
+func (b *UserBuilder) WithPassword(password string) *UserBuilder {
+	b.user.Password = password
+	return b
+}
+
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+func (b *UserBuilder) WithFullName(fullName string) *UserBuilder {
+	b.user.FullName = fullName
+	return b
+}
+
+func (b *UserBuilder) WithCreatedAt(createdAt int64) *UserBuilder {
+	b.user.CreatedAt = createdAt
+	return b
+}
+
+func (b *UserBuilder) WithStatus(status string) *UserBuilder {
+	b.user.Status = status
+	return b
+}
+
+func (b *UserBuilder) WithPasswordExpiresAt(expiresAt int64) *UserBuilder {
+	b.user.PasswordExpiresAt = expiresAt
+	return b
+}
+
+func (b *UserBuilder) WithAttribute(key, value string) *UserBuilder {
+	if b.user.Attributes == nil {
+		b.user.Attributes = make(map[string]string)
+	}
+	b.user.Attributes[key] = value
+	return b
+}
+
+func (b *UserBuilder) WithAttributes(attributes map[string]string) *UserBuilder {
+	b.user.Attributes = copyAttributes(attributes)
+	return b
+}
+
+func (b *UserBuilder) WithRole(role Role) *UserBuilder {
+	return b.WithAttribute(RoleAttribute, string(role))
+}
+
+func (b *UserBuilder) WithAvatarURL(url string) *UserBuilder {
+	b.user.AvatarURL = url
+	return b
+}
+
+func (b *UserBuilder) WithHLC(hlc uint64) *UserBuilder {
+	b.user.HLC = hlc
+	return b
+}
+
+// Build returns copy of the built user, so builder can be reused.
+func (b *UserBuilder) Build() models.User {
+	return copyUser(b.user)
+}
+
+// RandomUser returns user, which passes validation of create request.
+func RandomUser(r *rand.Rand) models.User {
+	id := r.Int63()
+	uid, err := uuid.NewRandomFromReader(r)
+	if err != nil {
+		panic(err)
+	}
+	user := models.User{
+		ID:        uid.String(),
+		Name:      fmt.Sprintf("user_%x", id),
+		Password:  randomString(r, 12),
+		Email:     fmt.Sprintf("user_%x@email.com", id),
+		FullName:  fmt.Sprintf("User %s", randomString(r, 8)),
+		CreatedAt: 1660000000 + r.Int63n(10000000),
+		Status:    models.StatusActive,
+	}
+	user.PasswordChangedAt = user.CreatedAt
+	return user
+}
+
+// RandomUsers returns n users with unique names.
+func RandomUsers(r *rand.Rand, n int) []models.User {
+	users := make([]models.User, 0, n)
+	names := make(map[string]struct{}, n)
+	for len(users) < n {
+		user := RandomUser(r)
+		if _, ok := names[user.Name]; ok {
+			continue
+		}
+		names[user.Name] = struct{}{}
+		users = append(users, user)
+	}
+	return users
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func randomString(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func copyUser(user models.User) models.User {
+	user.Attributes = copyAttributes(user.Attributes)
+	return user
+}
+
+func copyAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	result := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		result[key] = value
+	}
+	return result
+}
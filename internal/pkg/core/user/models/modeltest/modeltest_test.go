@@ -0,0 +1,40 @@
+package modeltest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserBuilder(t *testing.T) {
+	builder := NewUser().WithName("a").WithRole(Admin)
+	user := builder.Build()
+
+	assert.Equal(t, "a", user.Name)
+	assert.Equal(t, "ivan@email.com", user.Email)
+	assert.Equal(t, map[string]string{RoleAttribute: "admin"}, user.Attributes)
+
+	// built user does not share attributes with the builder
+	user.Attributes["team"] = "core"
+	assert.Len(t, builder.Build().Attributes, 1)
+}
+
+func TestFixture(t *testing.T) {
+	boris := Boris()
+	boris.Attributes["team"] = "edge"
+	assert.Equal(t, "core", Boris().Attributes["team"])
+
+	assert.Panics(t, func() { Fixture("unknown") })
+}
+
+func TestRandomUsers(t *testing.T) {
+	users := RandomUsers(rand.New(rand.NewSource(1)), 100)
+	assert.Len(t, users, 100)
+	for _, user := range users {
+		assert.NotEmpty(t, user.Name)
+		assert.NotEmpty(t, user.Password)
+		assert.Regexp(t, `^.+@[A-Za-z0-9\-_\.]+$`, user.Email)
+		assert.NotEmpty(t, user.FullName)
+	}
+}
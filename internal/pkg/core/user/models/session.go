@@ -0,0 +1,28 @@
+package models
+
+// SubjectAttribute keeps identity of the external provider linked to the user.
+const SubjectAttribute = "oidc_subject"
+
+// RoleAttribute keeps the role of the user, its changes are security events.
+const RoleAttribute = "role"
+
+// Identity is a user authenticated by the external provider.
+type Identity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	Name              string
+	PreferredUsername string
+}
+
+// Key returns value of SubjectAttribute, subjects are unique within the issuer only.
+func (i Identity) Key() string {
+	return i.Issuer + "|" + i.Subject
+}
+
+// Session is a local session of the user logged in via the external provider.
+type Session struct {
+	Token     string `json:"token"`
+	Name      string `json:"name"`
+	ExpiresAt int64  `json:"expires_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// Account statuses. Only active users can log in, pending ones wait for activation.
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+	StatusPending  = "pending"
+)
+
+// statusTransitions lists statuses reachable from the status.
+var statusTransitions = map[string][]string{
+	StatusActive:   {StatusDisabled},
+	StatusPending:  {StatusActive, StatusDisabled},
+	StatusDisabled: {StatusActive},
+}
+
+// ValidateStatus returns ErrValidation for unknown status, empty status is valid for filters.
+func ValidateStatus(status string) error {
+	if _, ok := statusTransitions[status]; ok || status == "" {
+		return nil
+	}
+	return errors.Wrapf(errorsPkg.ErrValidation, "field: [status] unknown value: [%s]", status)
+}
+
+// Transition returns ErrStatusTransition, if the user can't be moved from one status to another.
+func Transition(from, to string) error {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+	return errors.Wrapf(errorsPkg.ErrStatusTransition, "from [%s] to [%s]", from, to)
+}
@@ -0,0 +1,9 @@
+package models
+
+// Tombstone is left by the deleted user, so incremental exports see the deletion.
+type Tombstone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// DeletedAt is a time of the deletion in UNIX format.
+	DeletedAt int64 `json:"deleted_at"`
+}
@@ -6,6 +6,11 @@ func NewUser() *User {
 	return &User{}
 }
 
+func (u *User) IDSet(ID string) *User {
+	u.ID = ID
+	return u
+}
+
 func (u *User) NameSet(Name string) *User {
 	u.Name = Name
 	return u
@@ -30,3 +35,38 @@ func (u *User) CreatedAtSet(CreatedAt int64) *User {
 	u.CreatedAt = CreatedAt
 	return u
 }
+
+func (u *User) StatusSet(Status string) *User {
+	u.Status = Status
+	return u
+}
+
+func (u *User) PasswordChangedAtSet(PasswordChangedAt int64) *User {
+	u.PasswordChangedAt = PasswordChangedAt
+	return u
+}
+
+func (u *User) PasswordExpiresAtSet(PasswordExpiresAt int64) *User {
+	u.PasswordExpiresAt = PasswordExpiresAt
+	return u
+}
+
+func (u *User) AttributesSet(Attributes map[string]string) *User {
+	u.Attributes = Attributes
+	return u
+}
+
+func (u *User) AvatarURLSet(AvatarURL string) *User {
+	u.AvatarURL = AvatarURL
+	return u
+}
+
+func (u *User) HLCSet(HLC uint64) *User {
+	u.HLC = HLC
+	return u
+}
+
+func (u *User) UpdatedAtSet(UpdatedAt int64) *User {
+	u.UpdatedAt = UpdatedAt
+	return u
+}
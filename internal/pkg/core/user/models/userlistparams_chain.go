@@ -20,3 +20,13 @@ func (u *UserListParams) OrderSet(Order bool) *UserListParams {
 	u.Order = Order
 	return u
 }
+
+func (u *UserListParams) AttributesSet(Attributes map[string]string) *UserListParams {
+	u.Attributes = Attributes
+	return u
+}
+
+func (u *UserListParams) StatusSet(Status string) *UserListParams {
+	u.Status = Status
+	return u
+}
@@ -0,0 +1,17 @@
+// Code generated by chaingen. DO NOT EDIT.
+
+package models
+
+func NewUserRename() *UserRename {
+	return &UserRename{}
+}
+
+func (u *UserRename) NameSet(Name string) *UserRename {
+	u.Name = Name
+	return u
+}
+
+func (u *UserRename) NewNameSet(NewName string) *UserRename {
+	u.NewName = NewName
+	return u
+}
@@ -4,46 +4,340 @@ package user
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
 	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	denylistPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
 	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/keymutex"
 )
 
 const (
 	ctxTimeout     = 5 * time.Second
 	expirationTime = 1 * time.Minute
+
+	listExpirationTime = 5 * time.Second
+	listGenerationKey  = "list_generation"
+
+	passwordMaxAttempts    = 5
+	passwordAttemptsWindow = 1 * time.Minute
+	passwordAttemptsPrefix = "password_attempts_"
+
+	sessionExpirationTime = 24 * time.Hour
+	sessionPrefix         = "session_"
+
+	expirePageSize = 1000
 )
 
 type Interface interface {
 	Create(ctx context.Context, user models.User) error
-	Update(ctx context.Context, user models.User) error
+	// Update applies fields of the update listed in its mask to the stored user.
+	Update(ctx context.Context, update models.UserUpdate) error
 	Delete(ctx context.Context, name string) error
+	// Rename changes the user name and moves data kept by the name: cache entries and avatar.
+	Rename(ctx context.Context, oldName, newName string) error
 	Get(ctx context.Context, name string) (models.User, error)
-	List(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error)
+	GetByID(ctx context.Context, id string) (models.User, error)
+	// GetIfChanged returns the user and true, if its version differs from the known one.
+	// Otherwise it waits for a change of the user up to the wait and returns false, if there was none.
+	GetIfChanged(ctx context.Context, name, knownVersion string, wait time.Duration) (models.User, bool, error)
+	// Import creates the user or resolves the name conflict by the strategy.
+	Import(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error)
+	// ImportCheck returns the status, which the user would be imported with, nothing is written.
+	ImportCheck(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error)
+	// Restore writes the backed up user as is: with its ID, password, status and timestamps.
+	// Existing user is skipped, or replaced with overwrite.
+	Restore(ctx context.Context, user models.User, overwrite bool) (models.ImportStatus, error)
+	// List returns page of users, which have all the given attributes and the status, if it is not empty.
+	List(ctx context.Context, order bool, limit, offset uint64, attributes map[string]string, status string) ([]models.User, error)
+	// ListAfter returns page of users as List does, the page starts after the name in the order.
+	// Pages are not cached.
+	ListAfter(ctx context.Context, order bool, limit uint64, after string, attributes map[string]string, status string) ([]models.User, error)
+	// Changes returns a page of users written at the time in UNIX format or later ordered by name,
+	// the page starts after the name. Pages are not cached.
+	Changes(ctx context.Context, since int64, after string, limit uint64) ([]models.User, error)
+	// Tombstones returns users deleted at the time in UNIX format or later. The time before
+	// the tombstone retention is ErrTombstonesPruned, zero time returns all kept tombstones.
+	Tombstones(ctx context.Context, since int64) ([]models.Tombstone, error)
+	// PruneTombstones removes tombstones out of the retention at the time, number of removed is returned.
+	PruneTombstones(ctx context.Context, now time.Time) (int64, error)
+	// Count returns the number of users of the tenant and of all users by the counters maintained
+	// with writes, empty tenant counts users without the tenant attribute.
+	Count(ctx context.Context, tenant string) (count, total int64, err error)
+	// ReconcileCounts recounts users and corrects drifted counters, number of corrected tenants is returned.
+	ReconcileCounts(ctx context.Context) (int64, error)
+	// Disable moves active or pending user to disabled status, disabled user can't log in.
+	Disable(ctx context.Context, name string) error
+	// Enable moves disabled or pending user to active status, directory users are enabled by the sync only.
+	Enable(ctx context.Context, name string) error
 	Data(ctx context.Context, uid string) ([]byte, error)
+	// AvatarUpload stores user avatar and returns its URL.
+	AvatarUpload(ctx context.Context, name string, data []byte) (string, error)
+	AvatarGet(ctx context.Context, name string) (blob.Object, []byte, error)
+	// CheckPassword reports whether the password matches the stored one.
+	// Failed attempts are limited per user, unknown and not active users are reported as invalid password.
+	CheckPassword(ctx context.Context, name, password string) (bool, error)
+	// LoginExternal maps identity of the external provider to the local user and starts its session.
+	LoginExternal(ctx context.Context, identity models.Identity) (models.Session, error)
+	// SessionUser returns the active user of the session token issued by LoginExternal,
+	// ErrUnauthenticated is returned for unknown and expired sessions and sessions of inactive users.
+	SessionUser(ctx context.Context, token string) (models.User, error)
+	// Logout ends the session of the token.
+	Logout(ctx context.Context, token string) error
+	// ExpirePasswords forces password rotation of the named users or users having all the attributes,
+	// all users are affected if both are empty. Number of newly expired passwords is returned.
+	ExpirePasswords(ctx context.Context, names []string, attributes map[string]string) (uint64, error)
+	// StateAt reconstructs the user from its history as it was at the time, with restore the state
+	// replaces the current user. Deleted user is looked up by its last name.
+	StateAt(ctx context.Context, name string, at time.Time, restore bool) (models.User, error)
+	// ListAt returns page of users as ListAfter does, but as they were at the time by their history.
+	// Users deleted at the time are not listed, passwords are cleared.
+	ListAt(ctx context.Context, at time.Time, order bool, limit uint64, after string, attributes map[string]string, status string) ([]models.User, error)
+	// Impersonations returns sessions of callers acting as other identities with changes
+	// since the time, the latest sessions go first. Zero limit returns all sessions.
+	Impersonations(ctx context.Context, since time.Time, limit uint64) ([]historyPkg.Session, error)
+	// Invalidate drops cached users of the names and cached lists after changes made bypassing
+	// the core, e.g. by direct writes to the database. Their GetIfChanged callers are woken.
+	Invalidate(ctx context.Context, names ...string) error
+	// CacheGet returns the cached user without reading the database, ErrUserNotFound is returned,
+	// if the user is not cached.
+	CacheGet(ctx context.Context, name string) (models.CachedUser, error)
+	// CacheEvict drops the cached user and cached lists, it reports whether the user was cached.
+	CacheEvict(ctx context.Context, name string) (bool, error)
+	CacheStats(ctx context.Context) (models.CacheStats, error)
+}
+
+type Option func(c *core)
+
+type directoryKey struct{}
+
+// WithDirectory marks changes made by the directory sync: they write reserved attributes,
+// which link users to directory entries, and enable directory users disabled by the sync.
+func WithDirectory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, directoryKey{}, true)
+}
+
+func fromDirectory(ctx context.Context) bool {
+	directory, _ := ctx.Value(directoryKey{}).(bool)
+	return directory
+}
+
+// validateWritable checks attributes written by a client, the directory sync writes reserved ones.
+func validateWritable(ctx context.Context, attributes map[string]string, paths []string) error {
+	if fromDirectory(ctx) {
+		return nil
+	}
+	return models.ValidateWritable(attributes, paths)
+}
+
+// WithListTTL sets expiration time of cached UserList pages.
+func WithListTTL(ttl time.Duration) Option {
+	return func(c *core) {
+		if ttl > 0 {
+			c.listTTL = ttl
+		}
+	}
+}
+
+// WithPasswordAttempts limits failed CheckPassword attempts of the user within the window.
+func WithPasswordAttempts(max int, window time.Duration) Option {
+	return func(c *core) {
+		if max > 0 {
+			c.maxAttempts = max
+		}
+		if window > 0 {
+			c.attemptsWindow = window
+		}
+	}
+}
+
+// WithExternalLogin sets session lifetime of users logged in via the external provider.
+// With autoProvision unknown identity gets new local user, otherwise its login is rejected.
+func WithExternalLogin(autoProvision bool, sessionTTL time.Duration) Option {
+	return func(c *core) {
+		c.autoProvision = autoProvision
+		if sessionTTL > 0 {
+			c.sessionTTL = sessionTTL
+		}
+	}
+}
+
+// WithDenylist rejects reserved names of created, imported, renamed and provisioned users.
+// Names from requests are checked by the validator service as well, so they are rejected early.
+func WithDenylist(denylist denylistPkg.Interface) Option {
+	return func(c *core) {
+		c.denylist = denylist
+	}
+}
+
+// WithPasswordPolicy checks passwords of created users and passwords changed by update.
+func WithPasswordPolicy(policy passwordPkg.Interface) Option {
+	return func(c *core) {
+		c.passwordPolicy = policy
+	}
 }
 
-func New(data repoPkg.Interface, logger *zap.SugaredLogger, client *redis.Client) Interface {
-	return &core{
-		data:   data,
-		logger: logger,
-		cache:  client,
+// WithNormalizer sets user name normalizer, names are normalized
+// before they are used as repository and cache keys.
+func WithNormalizer(normalizer normalizePkg.Interface) Option {
+	return func(c *core) {
+		c.normalizer = normalizer
+	}
+}
+
+// WithCodec sets the format of cached users and list pages, JSON is used by default.
+func WithCodec(codec codecPkg.Interface) Option {
+	return func(c *core) {
+		c.codec = codec
+	}
+}
+
+// WithAvatars enables user avatars kept in the storage.
+func WithAvatars(storage blob.Storage, cfg avatarPkg.Config) Option {
+	return func(c *core) {
+		c.avatars = storage
+		c.avatarCfg = cfg
+	}
+}
+
+// WithHistory records every user change to the history.
+func WithHistory(history historyPkg.Interface) Option {
+	return func(c *core) {
+		c.history = history
+	}
+}
+
+// WithTombstoneRetention keeps tombstones of deleted users for the retention, zero keeps them forever.
+func WithTombstoneRetention(retention time.Duration) Option {
+	return func(c *core) {
+		c.tombstoneRetention = retention
+	}
+}
+
+// WithClock sets the clock of timestamps, password and session expiration, the system one is used by default.
+func WithClock(clk clock.Clock) Option {
+	return func(c *core) {
+		c.clock = clk
+	}
+}
+
+// WithSubscribers subscribes handlers to the user events, e.g. publishers to brokers.
+// They are called after the cache is invalidated and the change is recorded to the history.
+func WithSubscribers(handlers ...eventsPkg.Handler) Option {
+	return func(c *core) {
+		c.subscribers = append(c.subscribers, handlers...)
+	}
+}
+
+// WithSecurity emits login, lockout, role change, impersonation and erasure events to the stream.
+func WithSecurity(emit eventsPkg.SecurityEmitter) Option {
+	return func(c *core) {
+		c.security = emit
+		c.subscribers = append(c.subscribers, eventsPkg.SecurityHandler(emit))
+	}
+}
+
+// WithTenants applies the password checks limit, the avatar quota and the features
+// of the tenant overrides to its users.
+func WithTenants(tenants tenantPkg.Interface) Option {
+	return func(c *core) {
+		c.tenants = tenants
+	}
+}
+
+func New(data repoPkg.Interface, logger *zap.SugaredLogger, client *redis.Client, opts ...Option) Interface {
+	c := &core{
+		data:       data,
+		logger:     logger,
+		cache:      client,
+		codec:      codecPkg.Default(),
+		listTTL:    listExpirationTime,
+		normalizer: normalizePkg.New(normalizePkg.Policy{}),
+		locks:      keymutex.New(0),
+		clock:      clock.Real(),
+
+		maxAttempts:    passwordMaxAttempts,
+		attemptsWindow: passwordAttemptsWindow,
+		sessionTTL:     sessionExpirationTime,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.bus = eventsPkg.NewBus()
+	c.bus.Subscribe(c.invalidate)
+	c.notifier = eventsPkg.NewNotifier()
+	c.bus.Subscribe(c.notifier.Handle)
+	if c.history != nil {
+		c.bus.Subscribe(historyPkg.Recorder(c.history, logger))
+	}
+	for _, handler := range c.subscribers {
+		c.bus.Subscribe(handler)
 	}
+	return c
 }
 
 type core struct {
-	data   repoPkg.Interface
-	logger *zap.SugaredLogger
-	cache  *redis.Client
+	data       repoPkg.Interface
+	logger     *zap.SugaredLogger
+	cache      *redis.Client
+	codec      codecPkg.Interface
+	listTTL    time.Duration
+	normalizer normalizePkg.Interface
+	avatars    blob.Storage
+	avatarCfg  avatarPkg.Config
+	// locks serializes mutations of the same user
+	locks *keymutex.Striped
+	clock clock.Clock
+
+	maxAttempts    int
+	attemptsWindow time.Duration
+
+	autoProvision bool
+	sessionTTL    time.Duration
+	denylist      denylistPkg.Interface
+
+	passwordPolicy passwordPkg.Interface
+	history        historyPkg.Interface
+	// tenants override the config for users of a tenant, nil without overrides
+	tenants tenantPkg.Interface
+	// tombstoneRetention is the period deleted users are reported to delta exports for
+	tombstoneRetention time.Duration
+
+	// bus delivers events of the changes to side effects: cache invalidation, history and subscribers
+	bus         *eventsPkg.Bus
+	subscribers []eventsPkg.Handler
+	// security emits events of logins and lockouts, nil without the stream
+	security eventsPkg.SecurityEmitter
+	// notifier wakes GetIfChanged callers waiting for the user
+	notifier *eventsPkg.Notifier
 }
 
 func (c *core) Create(ctx context.Context, user models.User) error {
@@ -51,41 +345,112 @@ func (c *core) Create(ctx context.Context, user models.User) error {
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
 	defer cancel()
 
+	var err error
+	if user.Name, err = c.normalizer.Name(user.Name); err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	if err = c.checkName(ctx, user.Name); err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	if err = validateWritable(ctx, user.Attributes, nil); err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	if user.Status == "" {
+		user.Status = models.StatusActive
+	}
+	if err = c.checkPassword(user); err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	if user.Password, err = passwordPkg.Hash(user.Password); err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	c.passwordChanged(&user, c.createdAt(user))
+	unlock, err := c.lock(ctx, user.Name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
+	}
+	defer unlock()
+
 	if _, err := c.cache.Get(ctx, user.Name).Bytes(); err == nil {
 		counter.Hit.Inc()
-		return errorsPkg.ErrUserAlreadyExists
+		return apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "core.UserCreate", "name", user.Name)
 	}
 
 	if _, err := c.data.UserGet(ctx, user.Name); err == nil {
 		counter.Miss.Inc()
-		return errorsPkg.ErrUserAlreadyExists
+		return apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "core.UserCreate", "name", user.Name)
 	} else if !errors.Is(err, errorsPkg.ErrUserNotFound) {
-		return err
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
 	}
+	user.UpdatedAt = c.clock.Now().Unix()
 	if err := c.data.UserCreate(ctx, user); err != nil {
-		return err
+		return apperr.WrapKey(err, "core.UserCreate", "name", user.Name)
 	}
+	c.bus.Publish(ctx, eventsPkg.UserCreated{Change: c.change(ctx, historyPkg.ActionCreate, user)})
 
 	return nil
 }
 
-func (c *core) Update(ctx context.Context, user models.User) error {
-	c.logger.Debugln("Update", user)
+func (c *core) Update(ctx context.Context, update models.UserUpdate) error {
+	c.logger.Debugln("Update", update.User, update.Mask)
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
 	defer cancel()
 
-	old, err := c.data.UserGet(ctx, user.Name)
-	if err != nil {
-		return err
+	var err error
+	if update.Name, err = c.normalizer.Name(update.Name); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
 	}
-	if err = c.data.UserUpdate(ctx, user); err != nil {
-		return err
+	if err = validateWritable(ctx, update.Attributes, update.Paths()); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
 	}
+	unlock, err := c.lock(ctx, update.Name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+	}
+	defer unlock()
 
-	user.CreatedAt = old.CreatedAt
-	if err = c.cache.Set(ctx, user.Name, &user, expirationTime).Err(); err != nil {
-		c.logger.Errorf("set to cache: %v", err)
+	user, err := c.data.UserGet(ctx, update.Name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+	}
+	user.Name = update.Name
+	previous, password := user, user.Password
+	if err = models.ApplyMask(&user, update.User, update.Paths()); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+	}
+	if !fromDirectory(ctx) {
+		user.Attributes = models.KeepReserved(user.Attributes, previous.Attributes)
+	}
+	// the password policy and its expiration are resolved by the stored tenant
+	if user.Attributes, err = models.KeepTenant(user.Attributes, previous.Attributes); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+	}
+	// a single attribute path adds to the stored ones, which the validator doesn't see
+	if err = models.ValidateAttributes(user.Attributes); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+	}
+	if !samePassword(password, user.Password) {
+		if err = c.checkPassword(user); err != nil {
+			return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+		}
+		if user.Password, err = passwordPkg.Hash(user.Password); err != nil {
+			return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
+		}
+		c.passwordChanged(&user, c.clock.Now())
+	} else if user.PasswordExpired(c.clock.Now()) && containsPath(update.Paths(), models.MaskPassword) {
+		return apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation, "field: [password] must differ from the expired one"),
+			"core.UserUpdate", "name", update.Name)
+	} else {
+		user.Password = password
+	}
+	user.UpdatedAt = c.clock.Now().Unix()
+	if err = c.data.UserUpdate(ctx, user); err != nil {
+		return apperr.WrapKey(err, "core.UserUpdate", "name", update.Name)
 	}
+	c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: c.change(ctx, historyPkg.ActionUpdate, user), Previous: previous})
 
 	return nil
 }
@@ -95,77 +460,1549 @@ func (c *core) Delete(ctx context.Context, name string) error {
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
 	defer cancel()
 
-	if _, err := c.data.UserGet(ctx, name); err != nil {
-		return err
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserDelete", "name", name)
+	}
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserDelete", "name", name)
+	}
+	defer unlock()
+
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserDelete", "name", name)
 	}
 	if err := c.data.UserDelete(ctx, name); err != nil {
-		return err
+		return apperr.WrapKey(err, "core.UserDelete", "name", name)
+	}
+	c.bus.Publish(ctx, eventsPkg.UserDeleted{Change: c.change(ctx, historyPkg.ActionDelete, user)})
+
+	if c.avatars != nil {
+		if err := c.avatars.Delete(ctx, name); err != nil {
+			c.logger.Errorf("remove avatar: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *core) Rename(ctx context.Context, oldName, newName string) error {
+	c.logger.Debugln("Rename", oldName, newName)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	oldName, err := c.normalizer.Name(oldName)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserRename", "name", oldName)
+	}
+	if newName, err = c.normalizer.Name(newName); err != nil {
+		return apperr.WrapKey(err, "core.UserRename", "new_name", newName)
+	}
+	if oldName == newName {
+		return apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation, "field: [new_name] is equal to the current name"),
+			"core.UserRename", "name", oldName)
+	}
+	if err = c.checkName(ctx, newName); err != nil {
+		return apperr.WrapKey(err, "core.UserRename", "new_name", newName)
+	}
+	unlock, err := c.lock(ctx, oldName, newName)
+	if err != nil {
+		return apperr.WrapKey(err, "core.UserRename", "name", oldName)
+	}
+	defer unlock()
+
+	if err = c.data.UserRename(ctx, oldName, newName); err != nil {
+		return apperr.WrapKey(err, "core.UserRename", "name", oldName)
+	}
+	// rename doesn't return the user, the event carries its name only, if it can't be read
+	user, err := c.data.UserGet(ctx, newName)
+	if err != nil {
+		c.logger.Errorf("read renamed user: %v", err)
+		user = models.User{Name: newName}
+	}
+	previous := user
+	previous.Name = oldName
+	c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: c.change(ctx, historyPkg.ActionRename, user), OldName: oldName, Previous: previous})
+
+	if c.avatars != nil {
+		c.moveAvatar(ctx, oldName, newName)
 	}
 
-	if err := c.cache.Del(ctx, name).Err(); err != nil {
-		if !errors.Is(err, redis.Nil) {
-			c.logger.Errorf("remove from cache: %v", err)
+	return nil
+}
+
+func (c *core) Import(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error) {
+	c.logger.Debugln("Import", user, strategy)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if err := strategy.Validate(); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	var err error
+	if user.Name, err = c.normalizer.Name(user.Name); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	if err = models.ValidateWritable(user.Attributes, nil); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	unlock, err := c.lock(ctx, user.Name)
+	if err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	defer unlock()
+
+	existing, err := c.data.UserGet(ctx, user.Name)
+	if errors.Is(err, errorsPkg.ErrUserNotFound) {
+		if err = importValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		if err = c.checkName(ctx, user.Name); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		if err = c.checkPassword(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		if user.Password, err = passwordPkg.Hash(user.Password); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		if user.ID == "" {
+			user.ID = uuid.New().String()
+		}
+		user.Status = models.StatusActive
+		c.passwordChanged(&user, c.createdAt(user))
+		user.UpdatedAt = c.clock.Now().Unix()
+		if err = c.data.UserCreate(ctx, user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		c.bus.Publish(ctx, eventsPkg.UserCreated{Change: c.change(ctx, historyPkg.ActionImport, user)})
+		return models.ImportCreated, nil
+	}
+	if err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+
+	previous, password := existing, existing.Password
+	var status models.ImportStatus
+	switch strategy {
+	case models.ImportSkip:
+		return models.ImportSkipped, nil
+	case models.ImportFail:
+		return models.ImportFailed, apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "core.UserImport", "name", user.Name)
+	case models.ImportOverwrite:
+		if err = importValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		existing, status = models.Overwrite(existing, user), models.ImportOverwritten
+	case models.ImportMerge:
+		if err = mergeValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		existing, status = models.Merge(existing, user), models.ImportMerged
+	}
+	if existing.Attributes, err = models.KeepTenant(existing.Attributes, previous.Attributes); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	if err = models.ValidateAttributes(existing.Attributes); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	if !samePassword(password, existing.Password) {
+		if err = c.checkPassword(existing); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		if existing.Password, err = passwordPkg.Hash(existing.Password); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+		}
+		c.passwordChanged(&existing, c.clock.Now())
+	} else if user.Password != "" && existing.PasswordExpired(c.clock.Now()) {
+		return models.ImportFailed, apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation,
+			"field: [password] must differ from the expired one"), "core.UserImport", "name", user.Name)
+	} else {
+		existing.Password = password
+	}
+	existing.UpdatedAt = c.clock.Now().Unix()
+	if err = c.data.UserUpdate(ctx, existing); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImport", "name", user.Name)
+	}
+	c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: c.change(ctx, historyPkg.ActionImport, existing), Previous: previous})
+
+	return status, nil
+}
+
+func (c *core) ImportCheck(ctx context.Context, user models.User, strategy models.ImportStrategy) (models.ImportStatus, error) {
+	c.logger.Debugln("ImportCheck", user.Name, strategy)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if err := strategy.Validate(); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+	var err error
+	if user.Name, err = c.normalizer.Name(user.Name); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+	if err = models.ValidateWritable(user.Attributes, nil); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+
+	existing, err := c.data.UserGet(ctx, user.Name)
+	switch {
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		if err = importValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+		if err = c.checkName(ctx, user.Name); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+		if err = c.checkPassword(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+		return models.ImportCreated, nil
+	case err != nil:
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+
+	previous, password := existing, existing.Password
+	var status models.ImportStatus
+	switch strategy {
+	case models.ImportSkip:
+		return models.ImportSkipped, nil
+	case models.ImportFail:
+		return models.ImportFailed, apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "core.UserImportCheck", "name", user.Name)
+	case models.ImportOverwrite:
+		if err = importValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+		existing, status = models.Overwrite(existing, user), models.ImportOverwritten
+	case models.ImportMerge:
+		if err = mergeValidator(user); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+		existing, status = models.Merge(existing, user), models.ImportMerged
+	}
+	if existing.Attributes, err = models.KeepTenant(existing.Attributes, previous.Attributes); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+	if err = models.ValidateAttributes(existing.Attributes); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+	}
+	if !samePassword(password, existing.Password) {
+		if err = c.checkPassword(existing); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.UserImportCheck", "name", user.Name)
+		}
+	} else if user.Password != "" && existing.PasswordExpired(c.clock.Now()) {
+		return models.ImportFailed, apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation,
+			"field: [password] must differ from the expired one"), "core.UserImportCheck", "name", user.Name)
+	}
+	return status, nil
+}
+
+func (c *core) Restore(ctx context.Context, user models.User, overwrite bool) (models.ImportStatus, error) {
+	c.logger.Debugln("Restore", user.Name, overwrite)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if err := restoreValidator(user); err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.Restore", "name", user.Name)
+	}
+	unlock, err := c.lock(ctx, user.Name)
+	if err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.Restore", "name", user.Name)
+	}
+	defer unlock()
+
+	// backups made before passwords were hashed keep them as is
+	if user.Password != "" && !passwordPkg.Hashed(user.Password) {
+		if user.Password, err = passwordPkg.Hash(user.Password); err != nil {
+			return models.ImportFailed, apperr.WrapKey(err, "core.Restore", "name", user.Name)
 		}
 	}
+	// the restored state is a new write for incremental exports
+	user.UpdatedAt = c.clock.Now().Unix()
+	status := models.ImportCreated
+	existing, err := c.data.UserGet(ctx, user.Name)
+	switch {
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		err = c.data.UserCreate(ctx, user)
+	case err != nil:
+	case !overwrite:
+		return models.ImportSkipped, nil
+	default:
+		status = models.ImportOverwritten
+		err = c.data.UserUpdate(ctx, user)
+		// update is made by name, so the stored user keeps its ID
+		user.ID = existing.ID
+	}
+	if err != nil {
+		return models.ImportFailed, apperr.WrapKey(err, "core.Restore", "name", user.Name)
+	}
+	change := c.change(ctx, historyPkg.ActionRestore, user)
+	if status == models.ImportCreated {
+		c.bus.Publish(ctx, eventsPkg.UserCreated{Change: change})
+	} else {
+		c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: change, Previous: existing})
+	}
+	return status, nil
+}
+
+// restoreValidator checks fields, which are generated on create and must be kept by backups.
+func restoreValidator(user models.User) error {
+	if user.Name == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
+	}
+	if user.ID == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [id] cannot be empty")
+	}
+	if user.Status == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [status] cannot be empty")
+	}
+	return models.ValidateStatus(user.Status)
+}
+
+// importValidator checks the whole imported user as the validator service checks created users,
+// imports are not passed through it.
+func importValidator(user models.User) error {
+	if user.Name == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
+	}
+	if user.Password == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty")
+	}
+	if err := models.ValidateEmail(user.Email); err != nil {
+		return err
+	}
+	if user.FullName == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [full_name] cannot be empty")
+	}
+	return models.ValidateAttributes(user.Attributes)
+}
 
+// mergeValidator checks the imported fields merged to the existing user, attributes are checked
+// after the merge.
+func mergeValidator(user models.User) error {
+	if user.Email != "" {
+		return models.ValidateEmail(user.Email)
+	}
 	return nil
 }
 
+// moveAvatar copies avatar to the new name and removes the old one. Rename is not failed
+// on avatar errors, since the user is already renamed in the repository.
+func (c *core) moveAvatar(ctx context.Context, oldName, newName string) {
+	obj, data, err := c.avatars.Get(ctx, oldName)
+	if err != nil {
+		if !errors.Is(err, blob.ErrNotFound) {
+			c.logger.Errorf("get avatar: %v", err)
+		}
+		return
+	}
+	if err = c.avatars.Put(ctx, newName, obj.ContentType, data); err != nil {
+		c.logger.Errorf("move avatar: %v", err)
+		return
+	}
+	if err = c.avatars.Delete(ctx, oldName); err != nil {
+		c.logger.Errorf("remove avatar: %v", err)
+	}
+}
+
 func (c *core) Get(ctx context.Context, name string) (models.User, error) {
 	c.logger.Debugln("Get", name)
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
 	defer cancel()
 
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return models.User{}, apperr.WrapKey(err, "core.UserGet", "name", name)
+	}
+
 	if data, err := c.cache.Get(ctx, name).Bytes(); err == nil {
 		counter.Hit.Inc()
-		var user models.User
-		if err = json.Unmarshal(data, &user); err == nil {
+		user, stale, err := c.codec.DecodeUser(data)
+		if err == nil {
+			if stale {
+				c.reencode(ctx, name, data, func() ([]byte, error) { return c.codec.EncodeUser(user) })
+			}
 			return user, nil
 		}
-		c.logger.Errorf("unmarshal cached data: %v", err)
+		c.logger.Errorf("decode cached data: %v", err)
 	}
 
 	counter.Miss.Inc()
 	user, err := c.data.UserGet(ctx, name)
 	if err != nil {
-		return user, err
+		return user, apperr.WrapKey(err, "core.UserGet", "name", name)
+	}
+	c.fillAvatarURL(ctx, &user)
+	data, err := c.codec.EncodeUser(user)
+	if err != nil {
+		c.logger.Errorf("encode user: %v", err)
+		return user, nil
 	}
-	if err = c.cache.Set(ctx, name, &user, expirationTime).Err(); err != nil {
+	if err = c.cache.Set(ctx, name, data, expirationTime).Err(); err != nil {
 		c.logger.Errorf("set user to cache: %v", err)
 	}
 
 	return user, nil
 }
 
-func (c *core) List(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error) {
-	c.logger.Debugln("List", order, limit, offset)
+func (c *core) GetIfChanged(ctx context.Context, name, knownVersion string, wait time.Duration) (models.User, bool, error) {
+	c.logger.Debugln("GetIfChanged", name, knownVersion)
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return models.User{}, false, apperr.WrapKey(err, "core.UserGetIfChanged", "name", name)
+	}
+
+	timeout := c.clock.After(wait)
+	for {
+		// waiting starts before the user is read, so a change made in between wakes the loop
+		changed, cancel := c.notifier.Wait(name)
+		user, err := c.Get(ctx, name)
+		if err != nil || knownVersion == "" || user.Version() != knownVersion {
+			cancel()
+			return user, true, err
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			cancel()
+			return models.User{}, false, nil
+		case <-ctx.Done():
+			cancel()
+			return models.User{}, false, nil
+		}
+	}
+}
+
+func (c *core) GetByID(ctx context.Context, id string) (models.User, error) {
+	c.logger.Debugln("GetByID", id)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return models.User{}, apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation, "field: [id] must be UUID"),
+			"core.UserGetByID", "id", id)
+	}
+	user, err := c.data.UserGetByID(ctx, id)
+	if err != nil {
+		return user, apperr.WrapKey(err, "core.UserGetByID", "id", id)
+	}
+	c.fillAvatarURL(ctx, &user)
+
+	return user, nil
+}
+
+func (c *core) List(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	attributes map[string]string,
+	status string,
+) ([]models.User, error) {
+	c.logger.Debugln("List", order, limit, offset, attributes, status)
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
 	defer cancel()
 
-	key := fmt.Sprintf("%v_%d_%d", order, limit, offset)
-	if data, err := c.cache.Get(ctx, key).Bytes(); err == nil {
-		counter.Hit.Inc()
-		users := make([]models.User, 0)
-		if err = json.Unmarshal(data, &users); err == nil {
-			return users, nil
+	if err := models.ValidateStatus(status); err != nil {
+		return nil, apperr.Wrap(err, "core.UserList")
+	}
+	// pages of a list snapshot are read from the repository, cached pages may be of other versions
+	var key string
+	cacheable := repoPkg.ListSnapshotFromContext(ctx) == nil
+	if cacheable {
+		key, cacheable = c.listKey(ctx, order, limit, offset, attributes, status)
+	}
+	if cacheable {
+		if data, err := c.cache.Get(ctx, key).Bytes(); err == nil {
+			counter.ListHit.Inc()
+			users, stale, err := c.codec.DecodeUsers(data)
+			if err == nil {
+				if stale {
+					c.reencode(ctx, key, data, func() ([]byte, error) { return c.codec.EncodeUsers(users) })
+				}
+				return users, nil
+			}
+			c.logger.Errorf("decode cached data: %v", err)
 		}
-		c.logger.Errorf("unmarshal cached data: %v", err)
 	}
 
-	counter.Miss.Inc()
-	users, err := c.data.UserList(ctx, order, limit, offset)
+	counter.ListMiss.Inc()
+	ctx, partial := repoPkg.WithPartial(ctx)
+	users, err := c.data.UserList(ctx, order, limit, offset, filter.ListParams(attributes, status))
 	if err != nil {
-		return users, err
+		return users, apperr.Wrap(err, "core.UserList")
 	}
-	if err = c.cache.Set(ctx, key, users, expirationTime).Err(); err != nil {
-		c.logger.Errorf("set users list to cache: %v", err)
+	// pages missing users of failed shards are not cached
+	if cacheable && !partial.IsPartial() {
+		data, err := c.codec.EncodeUsers(users)
+		if err != nil {
+			c.logger.Errorf("encode users list: %v", err)
+			return users, nil
+		}
+		if err = c.cache.Set(ctx, key, data, c.listTTL).Err(); err != nil {
+			c.logger.Errorf("set users list to cache: %v", err)
+		}
 	}
 
 	return users, nil
 }
 
-func (c *core) Data(ctx context.Context, uid string) ([]byte, error) {
-	c.logger.Debugln("Data", uid)
+// ListAfter pages by the last name instead of the offset, so pages of page tokens are not
+// shifted by writes made between them.
+func (c *core) ListAfter(
+	ctx context.Context,
+	order bool,
+	limit uint64,
+	after string,
+	attributes map[string]string,
+	status string,
+) ([]models.User, error) {
+	c.logger.Debugln("ListAfter", order, limit, after, attributes, status)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
 
-	return c.cache.Get(ctx, uid).Bytes()
+	if err := models.ValidateStatus(status); err != nil {
+		return nil, apperr.Wrap(err, "core.UserListAfter")
+	}
+	var where filter.And
+	if params, ok := filter.ListParams(attributes, status).(filter.And); ok {
+		where = params
+	}
+	if after != "" {
+		op := filter.OpGt
+		if order {
+			op = filter.OpLt
+		}
+		where = append(where, filter.Cond{Field: filter.FieldName, Op: op, Value: after})
+	}
+	var expr filter.Expr
+	if len(where) != 0 {
+		expr = where
+	}
+	users, err := c.data.UserList(ctx, order, limit, 0, expr)
+	if err != nil {
+		return nil, apperr.Wrap(err, "core.UserListAfter")
+	}
+	return users, nil
+}
+
+// Changes pages by the last name instead of the offset, so deletions made
+// during the export do not shift pages and skip users.
+func (c *core) Changes(ctx context.Context, since int64, after string, limit uint64) ([]models.User, error) {
+	c.logger.Debugln("Changes", since, after, limit)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	where := filter.And{filter.Cond{Field: filter.FieldUpdatedAt, Op: filter.OpGe, Value: since}}
+	if after != "" {
+		where = append(where, filter.Cond{Field: filter.FieldName, Op: filter.OpGt, Value: after})
+	}
+	users, err := c.data.UserList(ctx, false, limit, 0, where)
+	if err != nil {
+		return nil, apperr.Wrap(err, "core.UserChanges")
+	}
+	return users, nil
+}
+
+func (c *core) Tombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	c.logger.Debugln("Tombstones", since)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	// deletions before the retention may be pruned, so the delta would miss them;
+	// zero since is the first sync, which has no users to delete
+	if since != 0 && since < c.tombstoneHorizon(c.clock.Now()) {
+		return nil, apperr.Wrap(errors.Wrapf(errorsPkg.ErrTombstonesPruned, "since [%d]", since), "core.UserTombstones")
+	}
+	tombstones, err := c.data.UserTombstones(ctx, since)
+	if err != nil {
+		return nil, apperr.Wrap(err, "core.UserTombstones")
+	}
+	return tombstones, nil
+}
+
+func (c *core) PruneTombstones(ctx context.Context, now time.Time) (int64, error) {
+	c.logger.Debugln("PruneTombstones", now)
+	if c.tombstoneRetention <= 0 {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	pruned, err := c.data.UserTombstonesPrune(ctx, c.tombstoneHorizon(now))
+	if err != nil {
+		return pruned, apperr.Wrap(err, "core.PruneTombstones")
+	}
+	return pruned, nil
+}
+
+func (c *core) Count(ctx context.Context, tenant string) (int64, int64, error) {
+	c.logger.Debugln("Count", tenant)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	counts, err := c.data.UserCounts(ctx)
+	if err != nil {
+		return 0, 0, apperr.WrapKey(err, "core.UserCount", "tenant", tenant)
+	}
+	var total int64
+	for _, users := range counts {
+		total += users
+	}
+	return counts[tenant], total, nil
+}
+
+func (c *core) ReconcileCounts(ctx context.Context) (int64, error) {
+	c.logger.Debugln("ReconcileCounts")
+	corrected, err := c.data.UserCountsReconcile(ctx)
+	if err != nil {
+		return corrected, apperr.Wrap(err, "core.ReconcileCounts")
+	}
+	return corrected, nil
+}
+
+// tombstoneHorizon is the earliest time in UNIX format, deletions since which are kept at the time.
+func (c *core) tombstoneHorizon(now time.Time) int64 {
+	if c.tombstoneRetention <= 0 {
+		return 0
+	}
+	return now.Add(-c.tombstoneRetention).Unix()
+}
+
+func (c *core) Disable(ctx context.Context, name string) error {
+	c.logger.Debugln("Disable", name)
+	return c.transition(ctx, "core.UserDisable", name, models.StatusDisabled)
+}
+
+func (c *core) Enable(ctx context.Context, name string) error {
+	c.logger.Debugln("Enable", name)
+	return c.transition(ctx, "core.UserEnable", name, models.StatusActive)
+}
+
+// transition moves the user to the status allowed by models.Transition and writes it to the audit log.
+func (c *core) transition(ctx context.Context, op, name, to string) error {
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	defer unlock()
+
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil {
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	previous, from := user, user.Status
+	if err = models.Transition(from, to); err != nil {
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	// directory users are disabled by the sync, when they are removed from the directory
+	if to == models.StatusActive && user.Attributes[models.DNAttribute] != "" && !fromDirectory(ctx) {
+		err = errors.Wrapf(errorsPkg.ErrStatusTransition, "from [%s] to [%s]: user is managed by the directory", from, to)
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	user.Status = to
+	user.UpdatedAt = c.clock.Now().Unix()
+	if err = c.data.UserUpdate(ctx, user); err != nil {
+		return apperr.WrapKey(err, op, "name", name)
+	}
+	c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: c.change(ctx, historyPkg.ActionStatus, user), Previous: previous})
+	c.logger.Infow("user status changed", "name", name, "from", from, "to", to, "meta", grpcPkg.GetMetaFromContext(ctx))
+
+	return nil
+}
+
+// passwordChanged sets time of the password change and its expiration by the tenant policy.
+func (c *core) passwordChanged(user *models.User, now time.Time) {
+	user.PasswordChangedAt = now.Unix()
+	user.PasswordExpiresAt = 0
+	if c.passwordPolicy == nil {
+		return
+	}
+	if maxAge := c.passwordPolicy.MaxAge(*user); maxAge > 0 {
+		user.PasswordExpiresAt = now.Add(maxAge).Unix()
+	}
+}
+
+// createdAt returns creation time of the user, current time is used if it is not set.
+func (c *core) createdAt(user models.User) time.Time {
+	if user.CreatedAt == 0 {
+		return c.clock.Now()
+	}
+	return time.Unix(user.CreatedAt, 0)
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPassword returns ErrValidation, if the password is rejected by the policy.
+func (c *core) checkPassword(user models.User) error {
+	if c.passwordPolicy == nil {
+		return nil
+	}
+	return c.passwordPolicy.Check(user)
+}
+
+// checkName returns ErrNameReserved, if the name of a new user is denied.
+// Existing users keep their names, even if they are denied later.
+func (c *core) checkName(ctx context.Context, name string) error {
+	if c.denylist == nil {
+		return nil
+	}
+	return c.denylist.Check(ctx, name)
+}
+
+// lock waits until other mutations of the users are finished, returned function releases the lock.
+func (c *core) lock(ctx context.Context, names ...string) (func(), error) {
+	unlock, err := c.locks.LockAll(ctx, names...)
+	if err != nil {
+		return nil, errors.Wrap(errorsPkg.ErrTimeout, "wait for user lock")
+	}
+	return unlock, nil
+}
+
+// listKey returns cache key of the list page. Key contains current list generation,
+// so any mutation makes all previously cached pages unreachable.
+// If generation can't be read, the page must not be cached.
+func (c *core) listKey(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	attributes map[string]string,
+	status string,
+) (string, bool) {
+	gen, err := c.cache.Get(ctx, listGenerationKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.logger.Errorf("get list generation: %v", err)
+		return "", false
+	}
+
+	filter := make(url.Values, len(attributes))
+	for key, value := range attributes {
+		filter.Set(key, value)
+	}
+	return fmt.Sprintf("list_%d_%v_%d_%d_%s_%s", gen, order, limit, offset, status, filter.Encode()), true
+}
+
+// invalidateList drops all cached list pages by incrementing list generation.
+func (c *core) invalidateList(ctx context.Context) {
+	if err := c.cache.Incr(ctx, listGenerationKey).Err(); err != nil {
+		c.logger.Errorf("invalidate list cache: %v", err)
+	}
+}
+
+// reencodeScript replaces the cached value keeping its TTL, if it is not changed since it is read.
+var reencodeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+end
+return 0`)
+
+// reencode writes the value read in another format by the configured one, so entries are migrated
+// by reads. Failures are logged, the value is re-encoded by the next read.
+func (c *core) reencode(ctx context.Context, key string, data []byte, encode func() ([]byte, error)) {
+	encoded, err := encode()
+	if err != nil {
+		c.logger.Errorf("re-encode cached data: %v", err)
+		return
+	}
+	if err = reencodeScript.Run(ctx, c.cache, []string{key}, data, encoded).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		c.logger.Errorf("re-encode cached data: %v", err)
+	}
+}
+
+func (c *core) Data(ctx context.Context, uid string) ([]byte, error) {
+	c.logger.Debugln("Data", uid)
+
+	return c.cache.Get(ctx, uid).Bytes()
+}
+
+func (c *core) AvatarUpload(ctx context.Context, name string, data []byte) (string, error) {
+	c.logger.Debugln("AvatarUpload", name, len(data))
+	if c.avatars == nil {
+		return "", apperr.WrapKey(errorsPkg.ErrAvatarsDisabled, "core.AvatarUpload", "name", name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return "", apperr.WrapKey(err, "core.AvatarUpload", "name", name)
+	}
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		return "", apperr.WrapKey(err, "core.AvatarUpload", "name", name)
+	}
+	defer unlock()
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil {
+		return "", apperr.WrapKey(err, "core.AvatarUpload", "name", name)
+	}
+	tenant := tenantPkg.Of(user)
+	if !tenantPkg.Feature(c.tenants, tenant, tenantPkg.FeatureAvatars, true) {
+		return "", apperr.WrapKey(errors.Wrapf(errorsPkg.ErrAvatarsDisabled, "tenant [%s]", tenant),
+			"core.AvatarUpload", "name", name)
+	}
+	cfg := c.avatarCfg
+	if c.tenants != nil {
+		if overrides, ok := c.tenants.Get(tenant); ok && overrides.MaxAvatarSize > 0 {
+			cfg.MaxSize = overrides.MaxAvatarSize
+		}
+	}
+	contentType, err := avatarPkg.Validate(cfg, data)
+	if err != nil {
+		return "", apperr.WrapKey(err, "core.AvatarUpload", "name", name)
+	}
+	if err = c.avatars.Put(ctx, name, contentType, data); err != nil {
+		return "", apperr.WrapKey(err, "core.AvatarUpload", "name", name)
+	}
+
+	if err = c.cache.Del(ctx, name).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		c.logger.Errorf("remove from cache: %v", err)
+	}
+
+	return c.avatarURL(name), nil
+}
+
+func (c *core) AvatarGet(ctx context.Context, name string) (blob.Object, []byte, error) {
+	c.logger.Debugln("AvatarGet", name)
+	if c.avatars == nil {
+		return blob.Object{}, nil, apperr.WrapKey(errorsPkg.ErrAvatarsDisabled, "core.AvatarGet", "name", name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return blob.Object{}, nil, apperr.WrapKey(err, "core.AvatarGet", "name", name)
+	}
+	obj, data, err := c.avatars.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return blob.Object{}, nil, apperr.WrapKey(errorsPkg.ErrAvatarNotFound, "core.AvatarGet", "name", name)
+		}
+		return blob.Object{}, nil, apperr.WrapKey(err, "core.AvatarGet", "name", name)
+	}
+	return obj, data, nil
+}
+
+// fillAvatarURL sets avatar URL, if the user has uploaded one.
+func (c *core) fillAvatarURL(ctx context.Context, user *models.User) {
+	if c.avatars == nil {
+		return
+	}
+	if _, err := c.avatars.Stat(ctx, user.Name); err != nil {
+		if !errors.Is(err, blob.ErrNotFound) {
+			c.logger.Errorf("stat avatar: %v", err)
+		}
+		return
+	}
+	user.AvatarURL = c.avatarURL(user.Name)
+}
+
+func (c *core) avatarURL(name string) string {
+	if c.avatarCfg.PublicURL != "" {
+		return strings.TrimRight(c.avatarCfg.PublicURL, "/") + "/" + url.PathEscape(name)
+	}
+	return "/v1/user/" + url.PathEscape(name) + "/avatar"
+}
+
+func (c *core) CheckPassword(ctx context.Context, name, password string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return false, apperr.WrapKey(err, "core.UserCheckPassword", "name", name)
+	}
+	if password == "" {
+		return false, apperr.WrapKey(errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty"),
+			"core.UserCheckPassword", "name", name)
+	}
+	// the user is read before the limit check, its tenant may override the limit
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil && !errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return false, apperr.WrapKey(err, "core.UserCheckPassword", "name", name)
+	}
+	found := err == nil
+	key := passwordAttemptsPrefix + name
+	// the attempt is counted before the check, so concurrent checks don't exceed the limit
+	attempts, err := c.countAttempt(ctx, key)
+	if err != nil {
+		return false, apperr.WrapKey(err, "core.UserCheckPassword", "name", name)
+	}
+	maxAttempts := c.attemptsLimit(user)
+	if attempts > int64(maxAttempts) {
+		retryAfter := c.attemptsReset(ctx, key)
+		c.logger.Warnw("password check rejected", "name", name, "attempts", attempts, "retry_after", retryAfter)
+		c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginFailure, Name: name,
+			Reason: eventsPkg.SecurityReasonLocked, Details: passwordLogin})
+		return false, apperr.WrapKey(apperr.WithRetryAfter(errorsPkg.ErrTooManyAttempts, retryAfter),
+			"core.UserCheckPassword", "name", name)
+	}
+
+	if passwordEqual(comparedPassword(user, found), password) && found && user.Active() {
+		if err = c.cache.Del(ctx, key).Err(); err != nil && !errors.Is(err, redis.Nil) {
+			c.logger.Errorf("reset password attempts: %v", err)
+		}
+		if !passwordPkg.Hashed(user.Password) {
+			c.rehash(ctx, name, password)
+		}
+		if user.PasswordExpired(c.clock.Now()) {
+			c.logger.Warnw("password expired", "name", name, "expires_at", user.PasswordExpiresAt,
+				"meta", grpcPkg.GetMetaFromContext(ctx))
+			c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginFailure, UserID: user.ID, Name: name,
+				Reason: eventsPkg.SecurityReasonPasswordExpired, Details: passwordLogin})
+			return false, apperr.WrapKey(errorsPkg.ErrPasswordExpired, "core.UserCheckPassword", "name", name)
+		}
+		c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginSuccess, UserID: user.ID, Name: name,
+			Details: passwordLogin})
+		return true, nil
+	}
+
+	c.logger.Warnw("password check failed", "name", name, "attempts", attempts, "meta", grpcPkg.GetMetaFromContext(ctx))
+	c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginFailure, UserID: user.ID, Name: name,
+		Reason: eventsPkg.SecurityReasonInvalidCredentials, Details: passwordLogin})
+	if attempts == int64(maxAttempts) {
+		c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLockout, UserID: user.ID, Name: name,
+			Details: map[string]string{"attempts": strconv.FormatInt(attempts, 10), "window": c.attemptsWindow.String()}})
+	}
+	return false, nil
+}
+
+// attemptsLimit returns failed password checks allowed to the user within the window.
+func (c *core) attemptsLimit(user models.User) int {
+	if c.tenants == nil {
+		return c.maxAttempts
+	}
+	if overrides, ok := c.tenants.Get(tenantPkg.Of(user)); ok && overrides.MaxPasswordAttempts > 0 {
+		return overrides.MaxPasswordAttempts
+	}
+	return c.maxAttempts
+}
+
+// attemptsReset returns the time left until failed attempts are reset, it is the whole window,
+// if the time is unknown.
+func (c *core) attemptsReset(ctx context.Context, key string) time.Duration {
+	ttl, err := c.cache.TTL(ctx, key).Result()
+	if err != nil {
+		c.logger.Errorf("password attempts ttl: %v", err)
+	}
+	// negative TTL is returned for the key without expiration or the missing one
+	if err != nil || ttl <= 0 || ttl > c.attemptsWindow {
+		return c.attemptsWindow
+	}
+	return ttl
+}
+
+// countAttempt counts the password check within the window, the successful check resets attempts.
+// It returns attempts of the window including this one.
+func (c *core) countAttempt(ctx context.Context, key string) (int64, error) {
+	attempts, err := c.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, "count password attempt")
+	}
+	if attempts == 1 {
+		if err = c.cache.Expire(ctx, key, c.attemptsWindow).Err(); err != nil {
+			c.logger.Errorf("expire password attempts: %v", err)
+		}
+	}
+	return attempts, nil
+}
+
+// rehash replaces the password stored before passwords were hashed with its hash,
+// the check succeeds anyway, if it fails.
+func (c *core) rehash(ctx context.Context, name, password string) {
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		c.logger.Errorf("rehash password: %v", err)
+		return
+	}
+	defer unlock()
+
+	// the user is read again under the lock, so concurrent changes are not overwritten
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil || user.Password != password {
+		return
+	}
+	if user.Password, err = passwordPkg.Hash(password); err == nil {
+		err = c.data.UserUpdate(ctx, user)
+	}
+	if err != nil {
+		c.logger.Errorf("rehash password: %v", err)
+		return
+	}
+	if err = c.Invalidate(ctx, name); err != nil {
+		c.logger.Errorf("rehash password, invalidate: %v", err)
+	}
+}
+
+// passwordEqual compares the password with the stored bcrypt hash. Passwords stored before
+// they were hashed are compared by digests in constant time, digests have the same length,
+// so the length of the password is not disclosed as well.
+// comparedPassword returns the stored password of the user. Unknown user is compared with
+// the dummy hash at the cost of stored hashes, so the response time does not disclose it.
+func comparedPassword(user models.User, found bool) string {
+	if !found {
+		return passwordPkg.Dummy()
+	}
+	return user.Password
+}
+
+func passwordEqual(stored, password string) bool {
+	if passwordPkg.Hashed(stored) {
+		return passwordPkg.Matches(stored, password)
+	}
+	storedSum, passwordSum := sha256.Sum256([]byte(stored)), sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare(storedSum[:], passwordSum[:]) == 1
+}
+
+// samePassword reports whether the written password is the stored one: the stored hash itself,
+// e.g. kept by the mask or the merge, or the password matching it.
+func samePassword(stored, written string) bool {
+	return written == stored || passwordEqual(stored, written)
+}
+
+func (c *core) LoginExternal(ctx context.Context, identity models.Identity) (models.Session, error) {
+	c.logger.Debugln("LoginExternal", identity.Issuer, identity.Subject)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	users, err := c.data.UserList(ctx, false, 2, 0, filter.Eq(filter.Attribute(models.SubjectAttribute), identity.Key()))
+	if err != nil {
+		return models.Session{}, apperr.WrapKey(err, "core.UserLoginExternal", "subject", identity.Key())
+	}
+
+	var user models.User
+	switch len(users) {
+	case 0:
+		if !c.autoProvision {
+			c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginFailure,
+				Reason: eventsPkg.SecurityReasonUnknownSubject, Details: externalLogin(identity)})
+			return models.Session{}, apperr.WrapKey(errorsPkg.ErrUserNotFound, "core.UserLoginExternal", "subject", identity.Key())
+		}
+		if user, err = c.provision(ctx, identity); err != nil {
+			return models.Session{}, apperr.WrapKey(err, "core.UserLoginExternal", "subject", identity.Key())
+		}
+	case 1:
+		if !users[0].Active() {
+			c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginFailure, UserID: users[0].ID, Name: users[0].Name,
+				Reason: eventsPkg.SecurityReasonInactive, Details: externalLogin(identity)})
+			return models.Session{}, apperr.WrapKey(errors.Wrapf(errorsPkg.ErrUserNotFound, "user is %s", users[0].Status),
+				"core.UserLoginExternal", "name", users[0].Name)
+		}
+		user = users[0]
+	default:
+		return models.Session{}, apperr.WrapKey(errors.Wrap(errorsPkg.ErrUnexpected, "subject is linked to several users"),
+			"core.UserLoginExternal", "subject", identity.Key())
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return models.Session{}, apperr.WrapKey(err, "core.UserLoginExternal", "name", user.Name)
+	}
+	// session keeps the user ID, so it survives renames and never passes to a new user of the name
+	if err = c.cache.Set(ctx, sessionPrefix+token, user.ID, c.sessionTTL).Err(); err != nil {
+		return models.Session{}, apperr.WrapKey(err, "core.UserLoginExternal", "name", user.Name)
+	}
+	c.emitSecurity(ctx, eventsPkg.SecurityEvent{Type: eventsPkg.SecurityLoginSuccess, UserID: user.ID, Name: user.Name,
+		Details: externalLogin(identity)})
+	return models.Session{
+		Token:     token,
+		Name:      user.Name,
+		ExpiresAt: c.clock.Now().Add(c.sessionTTL).Unix(),
+	}, nil
+}
+
+func (c *core) SessionUser(ctx context.Context, token string) (models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if token == "" {
+		return models.User{}, apperr.Wrap(errors.Wrap(errorsPkg.ErrUnauthenticated, "session token is empty"), "core.SessionUser")
+	}
+	id, err := c.cache.Get(ctx, sessionPrefix+token).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = errors.Wrap(errorsPkg.ErrUnauthenticated, "session is unknown or expired")
+		}
+		return models.User{}, apperr.Wrap(err, "core.SessionUser")
+	}
+	user, err := c.GetByID(ctx, id)
+	switch {
+	case errors.Is(err, errorsPkg.ErrUserNotFound), errors.Is(err, errorsPkg.ErrValidation):
+		return models.User{}, apperr.Wrap(errors.Wrap(errorsPkg.ErrUnauthenticated, "user of the session is deleted"), "core.SessionUser")
+	case err != nil:
+		return models.User{}, apperr.Wrap(err, "core.SessionUser")
+	case !user.Active():
+		return models.User{}, apperr.WrapKey(errors.Wrapf(errorsPkg.ErrUnauthenticated, "user is %s", user.Status),
+			"core.SessionUser", "name", user.Name)
+	}
+	return user, nil
+}
+
+func (c *core) Logout(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if err := c.cache.Del(ctx, sessionPrefix+token).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return apperr.Wrap(err, "core.Logout")
+	}
+	return nil
+}
+
+// provision creates local user linked to the identity. Existing user with the same name
+// is never linked automatically, since the provider does not prove its ownership.
+func (c *core) provision(ctx context.Context, identity models.Identity) (models.User, error) {
+	name := identity.PreferredUsername
+	if name == "" {
+		name = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return models.User{}, err
+	}
+	if name == "" {
+		return models.User{}, errors.Wrap(errorsPkg.ErrValidation, "field: [preferred_username] cannot be empty")
+	}
+	if err = c.checkName(ctx, name); err != nil {
+		return models.User{}, err
+	}
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer unlock()
+
+	if _, err = c.data.UserGet(ctx, name); err == nil {
+		return models.User{}, errorsPkg.ErrUserAlreadyExists
+	} else if !errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return models.User{}, err
+	}
+
+	// password is unknown to anybody, the user logs in via the provider only
+	password, err := randomHex(32)
+	if err != nil {
+		return models.User{}, err
+	}
+	if password, err = passwordPkg.Hash(password); err != nil {
+		return models.User{}, err
+	}
+	fullName := identity.Name
+	if fullName == "" {
+		fullName = name
+	}
+	user := models.User{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Password:   password,
+		Email:      identity.Email,
+		FullName:   fullName,
+		CreatedAt:  c.clock.Now().Unix(),
+		Status:     models.StatusActive,
+		Attributes: map[string]string{models.SubjectAttribute: identity.Key()},
+	}
+	// nobody knows the password, so it never expires
+	user.PasswordChangedAt = user.CreatedAt
+	user.UpdatedAt = user.CreatedAt
+	if err = c.data.UserCreate(ctx, user); err != nil {
+		return models.User{}, err
+	}
+	c.bus.Publish(ctx, eventsPkg.UserCreated{Change: c.change(ctx, historyPkg.ActionCreate, user)})
+	c.logger.Infow("user provisioned", "name", name, "subject", identity.Key())
+
+	return user, nil
+}
+
+func (c *core) ExpirePasswords(ctx context.Context, names []string, attributes map[string]string) (uint64, error) {
+	c.logger.Debugln("ExpirePasswords", names, attributes)
+	now := c.clock.Now()
+
+	var expired uint64
+	expire := func(name string) error {
+		ok, err := c.expirePassword(ctx, name, now)
+		if err != nil {
+			return apperr.WrapKey(err, "core.ExpirePasswords", "name", name)
+		}
+		if ok {
+			expired++
+		}
+		return nil
+	}
+	defer func() {
+		c.logger.Infow("passwords expired", "names", names, "attributes", attributes, "expired", expired,
+			"meta", grpcPkg.GetMetaFromContext(ctx))
+	}()
+
+	if len(names) != 0 {
+		for _, name := range names {
+			if err := expire(name); err != nil {
+				return expired, err
+			}
+		}
+		return expired, nil
+	}
+	// the mass expiration pages through all matched users, it is guarded by the approval instead
+	ctx = repoPkg.WithoutScanBudget(ctx)
+	for page := uint64(0); ; page++ {
+		users, err := c.data.UserList(ctx, false, expirePageSize, page, filter.ListParams(attributes, ""))
+		if err != nil {
+			return expired, apperr.Wrap(err, "core.ExpirePasswords")
+		}
+		for _, user := range users {
+			if err = expire(user.Name); err != nil {
+				return expired, err
+			}
+		}
+		if len(users) < expirePageSize {
+			return expired, nil
+		}
+	}
+}
+
+// expirePassword makes the password expired since now, false is returned if it is already expired.
+func (c *core) expirePassword(ctx context.Context, name string, now time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return false, err
+	}
+	unlock, err := c.lock(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	user, err := c.data.UserGet(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if user.PasswordExpired(now) {
+		return false, nil
+	}
+	previous := user
+	user.PasswordExpiresAt = now.Unix()
+	user.UpdatedAt = now.Unix()
+	if err = c.data.UserUpdate(ctx, user); err != nil {
+		return false, err
+	}
+	c.bus.Publish(ctx, eventsPkg.UserUpdated{Change: c.change(ctx, historyPkg.ActionExpire, user), Previous: previous})
+	return true, nil
+}
+
+func (c *core) StateAt(ctx context.Context, name string, at time.Time, restore bool) (models.User, error) {
+	c.logger.Debugln("StateAt", name, at, restore)
+	if c.history == nil {
+		return models.User{}, apperr.WrapKey(errorsPkg.ErrHistoryDisabled, "core.UserStateAt", "name", name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return models.User{}, apperr.WrapKey(err, "core.UserStateAt", "name", name)
+	}
+	var id string
+	current, err := c.data.UserGet(ctx, name)
+	switch {
+	case err == nil:
+		id = current.ID
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		current = models.User{}
+		if id, err = c.history.UserID(ctx, name); err != nil {
+			return models.User{}, apperr.WrapKey(err, "core.UserStateAt", "name", name)
+		}
+	default:
+		return models.User{}, apperr.WrapKey(err, "core.UserStateAt", "name", name)
+	}
+
+	event, err := c.history.At(ctx, id, at)
+	if err != nil {
+		return models.User{}, apperr.WrapKey(err, "core.UserStateAt", "name", name)
+	}
+	if event.Action == historyPkg.ActionDelete {
+		return models.User{}, apperr.WrapKey(errors.Wrapf(errorsPkg.ErrUserNotFound, "user is deleted at [%s]", event.CreatedAt),
+			"core.UserStateAt", "name", name)
+	}
+	state := event.State
+	if !restore {
+		return state, nil
+	}
+	if state.Name != name {
+		return models.User{}, apperr.WrapKey(errors.Wrapf(errorsPkg.ErrValidation, "field: [name] user was named [%s] at the time", state.Name),
+			"core.UserStateAt", "name", name)
+	}
+	restored := state
+	if restored.Password == "" {
+		if restored, err = c.keepPassword(restored, current); err != nil {
+			return models.User{}, apperr.WrapKey(err, "core.UserStateAt", "name", name)
+		}
+	}
+	if _, err = c.Restore(ctx, restored, true); err != nil {
+		return models.User{}, err
+	}
+	c.logger.Infow("user state restored", "name", name, "at", at, "event", event.ID, "meta", grpcPkg.GetMetaFromContext(ctx))
+	return state, nil
+}
+
+// keepPassword returns the state with the password of the current user, since the history
+// doesn't keep passwords. Deleted user gets a random one, which is reset to log in.
+func (c *core) keepPassword(state, current models.User) (models.User, error) {
+	if current.ID != "" {
+		state.Password = current.Password
+		state.PasswordChangedAt, state.PasswordExpiresAt = current.PasswordChangedAt, current.PasswordExpiresAt
+		return state, nil
+	}
+	password, err := randomHex(32)
+	if err != nil {
+		return models.User{}, err
+	}
+	if state.Password, err = passwordPkg.Hash(password); err != nil {
+		return models.User{}, err
+	}
+	return state, nil
+}
+
+func (c *core) ListAt(
+	ctx context.Context,
+	at time.Time,
+	order bool,
+	limit uint64,
+	after string,
+	attributes map[string]string,
+	status string,
+) ([]models.User, error) {
+	c.logger.Debugln("ListAt", at, order, limit, after, attributes, status)
+	if c.history == nil {
+		return nil, apperr.Wrap(errorsPkg.ErrHistoryDisabled, "core.UserListAt")
+	}
+	if err := models.ValidateStatus(status); err != nil {
+		return nil, apperr.Wrap(err, "core.UserListAt")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	// the history has no indexes of states, so pages of all users are read and filtered,
+	// until the page of matching users is full
+	var users []models.User
+	for {
+		events, err := c.history.ListAt(ctx, at, order, after, limit)
+		if err != nil {
+			return nil, apperr.Wrap(err, "core.UserListAt")
+		}
+		for _, event := range events {
+			after = event.Name
+			if !matches(event.State, attributes, status) {
+				continue
+			}
+			state := event.State
+			state.Password = ""
+			users = append(users, state)
+			if limit > 0 && uint64(len(users)) == limit {
+				return users, nil
+			}
+		}
+		if limit == 0 || uint64(len(events)) < limit {
+			return users, nil
+		}
+	}
+}
+
+// matches reports whether the user has all the attributes and the status, if it is not empty.
+func matches(user models.User, attributes map[string]string, status string) bool {
+	if status != "" && user.Status != status {
+		return false
+	}
+	for key, value := range attributes {
+		if user.Attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *core) Impersonations(ctx context.Context, since time.Time, limit uint64) ([]historyPkg.Session, error) {
+	c.logger.Debugln("Impersonations", since, limit)
+	if c.history == nil {
+		return nil, apperr.Wrap(errorsPkg.ErrHistoryDisabled, "core.Impersonations")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	sessions, err := c.history.Impersonations(ctx, since, limit)
+	if err != nil {
+		return nil, apperr.Wrap(err, "core.Impersonations")
+	}
+	return sessions, nil
+}
+
+// change describes the change made by the request for the event.
+// The caller acting as another identity is kept as the real actor.
+func (c *core) change(ctx context.Context, action string, user models.User) eventsPkg.Change {
+	identity := grpcPkg.IdentityFromContext(ctx)
+	change := eventsPkg.Change{
+		Action: action,
+		Actor:  identity.Actor(),
+		User:   user,
+	}
+	if identity.Impersonated() {
+		change.RealActor = identity.Real
+	}
+	return change
+}
+
+// passwordLogin are details of security events of password checks.
+var passwordLogin = map[string]string{"method": "password"}
+
+// externalLogin returns details of security events of the external login.
+func externalLogin(identity models.Identity) map[string]string {
+	return map[string]string{"method": "external", "issuer": identity.Issuer, "subject": identity.Subject}
+}
+
+// emitSecurity emits the security event of the caller, if the stream is configured.
+func (c *core) emitSecurity(ctx context.Context, event eventsPkg.SecurityEvent) {
+	if c.security == nil {
+		return
+	}
+	identity := grpcPkg.IdentityFromContext(ctx)
+	event.Actor = identity.Actor()
+	if identity.Impersonated() {
+		event.RealActor = identity.Real
+	}
+	c.security(ctx, event)
+}
+
+// invalidate removes cached list pages and cached entries of the changed user.
+func (c *core) Invalidate(ctx context.Context, names ...string) error {
+	c.logger.Debugln("Invalidate", names)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	if err := c.cache.Incr(ctx, listGenerationKey).Err(); err != nil {
+		return apperr.Wrap(err, "core.Invalidate")
+	}
+	if len(names) != 0 {
+		if err := c.cache.Del(ctx, names...).Err(); err != nil && !errors.Is(err, redis.Nil) {
+			return apperr.Wrap(err, "core.Invalidate")
+		}
+	}
+	c.notifier.Wake(names...)
+	return nil
+}
+
+func (c *core) CacheGet(ctx context.Context, name string) (models.CachedUser, error) {
+	c.logger.Debugln("CacheGet", name)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return models.CachedUser{}, apperr.WrapKey(err, "core.CacheGet", "name", name)
+	}
+	data, err := c.cache.Get(ctx, name).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = errors.Wrapf(errorsPkg.ErrUserNotFound, "user [%s] is not cached", name)
+		}
+		return models.CachedUser{}, apperr.WrapKey(err, "core.CacheGet", "name", name)
+	}
+	var cached models.CachedUser
+	if cached.User, _, err = c.codec.DecodeUser(data); err != nil {
+		return models.CachedUser{}, apperr.WrapKey(err, "core.CacheGet", "name", name)
+	}
+	// the entry may expire in between, the zero TTL is returned then
+	if cached.TTL, err = c.cache.TTL(ctx, name).Result(); err != nil {
+		return models.CachedUser{}, apperr.WrapKey(err, "core.CacheGet", "name", name)
+	}
+	if cached.TTL < 0 {
+		cached.TTL = 0
+	}
+	return cached, nil
+}
+
+func (c *core) CacheEvict(ctx context.Context, name string) (bool, error) {
+	c.logger.Debugln("CacheEvict", name)
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	name, err := c.normalizer.Name(name)
+	if err != nil {
+		return false, apperr.WrapKey(err, "core.CacheEvict", "name", name)
+	}
+	if err = c.cache.Incr(ctx, listGenerationKey).Err(); err != nil {
+		return false, apperr.WrapKey(err, "core.CacheEvict", "name", name)
+	}
+	deleted, err := c.cache.Del(ctx, name).Result()
+	if err != nil {
+		return false, apperr.WrapKey(err, "core.CacheEvict", "name", name)
+	}
+	c.notifier.Wake(name)
+	return deleted != 0, nil
+}
+
+func (c *core) CacheStats(ctx context.Context) (models.CacheStats, error) {
+	c.logger.Debugln("CacheStats")
+	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	stats := models.CacheStats{
+		Hits:       counter.Hit.Value(),
+		Misses:     counter.Miss.Value(),
+		ListHits:   counter.ListHit.Value(),
+		ListMisses: counter.ListMiss.Value(),
+	}
+	var err error
+	if stats.Keys, err = c.cache.DBSize(ctx).Result(); err != nil {
+		return stats, apperr.Wrap(err, "core.CacheStats")
+	}
+	stats.ListGeneration, err = c.cache.Get(ctx, listGenerationKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return stats, apperr.Wrap(err, "core.CacheStats")
+	}
+	return stats, nil
+}
+
+func (c *core) invalidate(ctx context.Context, event eventsPkg.Event) {
+	c.invalidateList(ctx)
+
+	var names []string
+	switch e := event.(type) {
+	case eventsPkg.UserUpdated:
+		if e.OldName != "" {
+			names = append(names, e.OldName)
+		}
+		names = append(names, e.User.Name)
+	case eventsPkg.UserDeleted:
+		names = append(names, e.User.Name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	if err := c.cache.Del(ctx, names...).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		c.logger.Errorf("remove from cache: %v", err)
+	}
+}
+
+func randomHex(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", errors.Wrap(err, "random")
+	}
+	return hex.EncodeToString(data), nil
 }
@@ -2,28 +2,67 @@ package user
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redismock/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	denylistMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/denylist/mock"
+	historyPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+	historyMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history/mock"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
 )
 
 var (
-	user = models.User{
-		Name:      "Ivan",
-		Password:  "123",
-		Email:     "ivan@email.com",
-		FullName:  "Ivan the Dummy",
-		CreatedAt: 1660412940,
-	}
+	user = modeltest.Ivan()
 )
 
+// writtenUser matches the user written to the repository, UpdatedAt of which is stamped by the write.
+type writtenUser struct {
+	user  models.User
+	since int64
+}
+
+func written(user models.User) gomock.Matcher {
+	return writtenUser{user: user, since: time.Now().Unix()}
+}
+
+func (m writtenUser) Matches(x interface{}) bool {
+	got, ok := x.(models.User)
+	if !ok || got.UpdatedAt < m.since || got.UpdatedAt > time.Now().Unix() {
+		return false
+	}
+	got.UpdatedAt = m.user.UpdatedAt
+	// passwords are written hashed
+	if got.Password != m.user.Password && passwordPkg.Hashed(got.Password) && passwordPkg.Matches(got.Password, m.user.Password) {
+		got.Password = m.user.Password
+	}
+	return gomock.Eq(m.user).Matches(got)
+}
+
+func (m writtenUser) String() string {
+	return fmt.Sprintf("is %v written now", m.user)
+}
+
 func Test_Create(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -72,7 +111,7 @@ func Test_Create(t *testing.T) {
 			gomock.InOrder(
 				mockRepo.EXPECT().UserGet(gomock.Any(), c.user.Name).
 					Return(models.User{}, c.getErr).Times(1),
-				mockRepo.EXPECT().UserCreate(gomock.Any(), c.user).
+				mockRepo.EXPECT().UserCreate(gomock.Any(), written(c.user)).
 					Return(c.createErr).MaxTimes(1),
 			)
 
@@ -83,6 +122,51 @@ func Test_Create(t *testing.T) {
 	}
 }
 
+func Test_Denylist(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	reserved := errorsPkg.ErrNameReserved
+
+	cases := []struct {
+		name string
+		call func(c Interface) error
+	}{
+		{
+			name: "create",
+			call: func(c Interface) error {
+				return c.Create(context.Background(), user)
+			},
+		},
+		{
+			name: "import",
+			call: func(c Interface) error {
+				_, err := c.Import(context.Background(), user, models.ImportFail)
+				return err
+			},
+		},
+		{
+			name: "rename",
+			call: func(c Interface) error {
+				return c.Rename(context.Background(), "petr", user.Name)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(models.User{}, errorsPkg.ErrUserNotFound).AnyTimes()
+			mockDenylist := denylistMockPkg.NewMockInterface(ctl)
+			mockDenylist.EXPECT().Check(gomock.Any(), user.Name).Return(reserved).Times(1)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithDenylist(mockDenylist))
+			assert.ErrorIs(t, c.call(userCtl), reserved)
+		})
+	}
+}
+
 func Test_Update(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -123,18 +207,145 @@ func Test_Update(t *testing.T) {
 			mockRepo := repoMockPkg.NewMockInterface(ctl)
 			gomock.InOrder(
 				mockRepo.EXPECT().UserGet(gomock.Any(), c.user.Name).
-					Return(models.User{}, c.getErr).Times(1),
-				mockRepo.EXPECT().UserUpdate(gomock.Any(), c.user).
+					Return(c.user, c.getErr).Times(1),
+				mockRepo.EXPECT().UserUpdate(gomock.Any(), written(c.user)).
 					Return(c.updateErr).MaxTimes(1),
 			)
 
 			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
-			err := userCtl.Update(context.Background(), c.user)
+			err := userCtl.Update(context.Background(), models.UserUpdate{User: c.user})
 			assert.ErrorIs(t, err, c.expErr)
 		})
 	}
 }
 
+func Test_UpdateAttributesLimit(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	stored := make(map[string]string, models.MaxAttributes)
+	for i := 0; i < models.MaxAttributes; i++ {
+		stored[fmt.Sprintf("key%d", i)] = "value"
+	}
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+		Return(modeltest.From(user).WithAttributes(stored).Build(), nil).Times(1)
+	userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+
+	// the update has a single attribute, but the stored user gets one more than the limit
+	err := userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithAttributes(map[string]string{"team": "core"}).Build(),
+		Mask: []string{"attributes.team"},
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+}
+
+func Test_UpdateReservedAttributes(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	linked := modeltest.From(user).WithAttribute(models.SubjectAttribute, "issuer|subject").Build()
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(linked, nil).Times(1)
+	// replaced attributes keep the link
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), written(modeltest.From(user).
+		WithAttributes(map[string]string{"team": "core", models.SubjectAttribute: "issuer|subject"}).Build())).
+		Return(nil).Times(1)
+	userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+
+	err := userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithAttributes(map[string]string{"team": "core"}).Build(),
+		Mask: []string{models.MaskAttributes},
+	})
+	assert.NoError(t, err)
+
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithAttributes(map[string]string{models.SubjectAttribute: "issuer|victim"}).Build(),
+		Mask: []string{"attributes." + models.SubjectAttribute},
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	err = userCtl.Create(context.Background(), linked)
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+}
+
+func Test_PasswordPolicy(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	policy, err := passwordPkg.New(passwordPkg.Config{Default: passwordPkg.Policy{MinLength: 8}}, nil)
+	require.NoError(t, err)
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(user, nil).Times(2)
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), written(modeltest.From(user).WithEmail("new@email.com").Build())).
+		Return(nil).Times(1)
+	userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithPasswordPolicy(policy))
+
+	err = userCtl.Create(context.Background(), user)
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithPassword("short").Build(),
+		Mask: []string{models.MaskPassword},
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	// the stored password is not checked, when it is not changed
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithEmail("new@email.com").Build(),
+		Mask: []string{models.MaskEmail},
+	})
+	assert.NoError(t, err)
+
+	// imported users are checked by the policy as created ones
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(models.User{}, errorsPkg.ErrUserNotFound).Times(1)
+	status, err := userCtl.Import(context.Background(), user, models.ImportFail)
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+	assert.Equal(t, models.ImportFailed, status)
+}
+
+func Test_PasswordPolicyTenant(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	policy, err := passwordPkg.New(passwordPkg.Config{
+		Default: passwordPkg.Policy{MinLength: 12},
+		Tenants: map[string]passwordPkg.Policy{"service": {MinLength: 4}},
+	}, nil)
+	require.NoError(t, err)
+	staff := modeltest.From(user).WithAttribute(models.TenantAttribute, "staff").Build()
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(staff, nil).Times(2)
+	// replaced attributes keep the tenant
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), written(modeltest.From(user).
+		WithAttributes(map[string]string{"team": "core", models.TenantAttribute: "staff"}).Build())).
+		Return(nil).Times(1)
+	userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithPasswordPolicy(policy))
+
+	// the laxer tenant policy is not selected by the written tenant
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithPassword("weak").WithAttribute(models.TenantAttribute, "service").Build(),
+		Mask: []string{models.MaskPassword, "attributes." + models.TenantAttribute},
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: modeltest.From(user).WithAttributes(map[string]string{"team": "core"}).Build(),
+		Mask: []string{models.MaskAttributes},
+	})
+	assert.NoError(t, err)
+
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(staff, nil).Times(1)
+	status, err := userCtl.Import(context.Background(),
+		modeltest.From(user).WithPassword("weak").WithAttribute(models.TenantAttribute, "service").Build(), models.ImportMerge)
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+	assert.Equal(t, models.ImportFailed, status)
+}
+
 func Test_Delete(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -187,87 +398,1610 @@ func Test_Delete(t *testing.T) {
 	}
 }
 
-func Test_Get(t *testing.T) {
+func Test_Rename(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name      string
+		newName   string
+		renameErr error
+		calls     int
+		expErr    error
+	}{
+		{
+			name:    "success",
+			newName: "ivan_renamed",
+			calls:   1,
+		},
+		{
+			name:    "failed, new name is equal to the current one",
+			newName: user.Name,
+			expErr:  errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, new name is taken",
+			newName:   "boris",
+			renameErr: errorsPkg.ErrUserAlreadyExists,
+			calls:     1,
+			expErr:    errorsPkg.ErrUserAlreadyExists,
+		},
+		{
+			name:      "failed UserRename unexpected error",
+			newName:   "ivan_renamed",
+			renameErr: errorsPkg.ErrUnexpected,
+			calls:     1,
+			expErr:    errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserRename(gomock.Any(), user.Name, c.newName).
+				Return(c.renameErr).Times(c.calls)
+			renamed := modeltest.From(user).WithName(c.newName).Build()
+			published := 0
+			if c.expErr == nil {
+				published = 1
+			}
+			mockRepo.EXPECT().UserGet(gomock.Any(), c.newName).
+				Return(renamed, nil).Times(published)
+
+			var events []eventsPkg.Event
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client,
+				WithSubscribers(func(_ context.Context, event eventsPkg.Event) {
+					events = append(events, event)
+				}))
+			err := userCtl.Rename(context.Background(), user.Name, c.newName)
+			assert.ErrorIs(t, err, c.expErr)
+			require.Len(t, events, published)
+			if published != 0 {
+				updated, ok := events[0].(eventsPkg.UserUpdated)
+				require.True(t, ok)
+				assert.Equal(t, historyPkg.ActionRename, updated.Action)
+				assert.Equal(t, user.Name, updated.OldName)
+				assert.Equal(t, renamed, updated.User)
+				assert.Equal(t, []string{"Name"}, models.Diff(updated.Previous, updated.User))
+			}
+		})
+	}
+}
+
+func Test_Disable(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
 	client, _ := redismock.NewClientMock()
 
 	cases := []struct {
 		name    string
-		user    string
+		status  string
 		getErr  error
+		updated int
 		expErr  error
-		expUser models.User
 	}{
 		{
-			name:    "success",
-			user:    user.Name,
-			getErr:  nil,
-			expErr:  nil,
-			expUser: user,
+			name:    "success, active",
+			status:  models.StatusActive,
+			updated: 1,
 		},
 		{
-			name:    "failed UserGet unexpected error",
-			user:    user.Name,
-			getErr:  errorsPkg.ErrUnexpected,
-			expErr:  errorsPkg.ErrUnexpected,
-			expUser: models.User{},
+			name:    "success, pending",
+			status:  models.StatusPending,
+			updated: 1,
+		},
+		{
+			name:   "failed, already disabled",
+			status: models.StatusDisabled,
+			expErr: errorsPkg.ErrStatusTransition,
+		},
+		{
+			name:   "failed, user not found",
+			getErr: errorsPkg.ErrUserNotFound,
+			expErr: errorsPkg.ErrUserNotFound,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			mockRepo := repoMockPkg.NewMockInterface(ctl)
-			gomock.InOrder(
-				mockRepo.EXPECT().UserGet(gomock.Any(), c.user).
-					Return(c.expUser, c.getErr).Times(1),
-			)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(modeltest.From(user).WithStatus(c.status).Build(), c.getErr).Times(1)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), written(modeltest.From(user).WithStatus(models.StatusDisabled).Build())).
+				Return(nil).Times(c.updated)
 
 			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
-			expUser, err := userCtl.Get(context.Background(), c.user)
+			err := userCtl.Disable(context.Background(), user.Name)
 			assert.ErrorIs(t, err, c.expErr)
-			assert.Equal(t, expUser, c.expUser)
 		})
 	}
 }
 
-func Test_List(t *testing.T) {
+func Test_Enable(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
 	client, _ := redismock.NewClientMock()
 
 	cases := []struct {
 		name    string
-		listErr error
+		status  string
+		updated int
 		expErr  error
-		expList []models.User
 	}{
 		{
-			name:    "success",
-			listErr: nil,
-			expErr:  nil,
-			expList: []models.User{user},
+			name:    "success, disabled",
+			status:  models.StatusDisabled,
+			updated: 1,
 		},
 		{
-			name:    "failed UserList unexpected error",
-			listErr: errorsPkg.ErrUnexpected,
-			expErr:  errorsPkg.ErrUnexpected,
-			expList: nil,
+			name:    "success, pending",
+			status:  models.StatusPending,
+			updated: 1,
+		},
+		{
+			name:   "failed, already active",
+			status: models.StatusActive,
+			expErr: errorsPkg.ErrStatusTransition,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			mockRepo := repoMockPkg.NewMockInterface(ctl)
-			gomock.InOrder(
-				mockRepo.EXPECT().UserList(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(c.expList, c.listErr).Times(1),
-			)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(modeltest.From(user).WithStatus(c.status).Build(), nil).Times(1)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), written(modeltest.From(user).WithStatus(models.StatusActive).Build())).
+				Return(nil).Times(c.updated)
 
 			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
-			expList, err := userCtl.List(context.Background(), true, 1, 1)
+			err := userCtl.Enable(context.Background(), user.Name)
 			assert.ErrorIs(t, err, c.expErr)
-			assert.Equal(t, expList, c.expList)
+		})
+	}
+}
+
+func Test_Directory(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	linked := modeltest.From(user).WithAttribute(models.DNAttribute, "uid=user,dc=example,dc=com").Build()
+	disabled := modeltest.From(linked).WithStatus(models.StatusDisabled).Build()
+	moved := modeltest.From(linked).WithAttribute(models.DNAttribute, "uid=user,ou=people,dc=example,dc=com").Build()
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+
+	// clients neither enable directory users nor change their link
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(disabled, nil).Times(1)
+	err := userCtl.Enable(context.Background(), user.Name)
+	assert.ErrorIs(t, err, errorsPkg.ErrStatusTransition)
+
+	err = userCtl.Update(context.Background(), models.UserUpdate{
+		User: moved,
+		Mask: []string{"attributes." + models.DNAttribute},
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	// the directory sync does
+	ctx := WithDirectory(context.Background())
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(disabled, nil).Times(1)
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), written(linked)).Return(nil).Times(1)
+	assert.NoError(t, userCtl.Enable(ctx, user.Name))
+
+	mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(linked, nil).Times(1)
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), written(moved)).Return(nil).Times(1)
+	assert.NoError(t, userCtl.Update(ctx, models.UserUpdate{
+		User: moved,
+		Mask: []string{"attributes." + models.DNAttribute},
+	}))
+}
+
+func Test_ExpirePasswords(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	expired := modeltest.From(user).WithName("petr").WithPasswordExpiresAt(1).Build()
+	attributes := map[string]string{"tenant": "ozon"}
+
+	cases := []struct {
+		name       string
+		names      []string
+		attributes map[string]string
+		getErr     error
+		expExpired uint64
+		expErr     error
+	}{
+		{
+			name:       "success, by names",
+			names:      []string{user.Name, expired.Name},
+			expExpired: 1,
+		},
+		{
+			name:       "success, by attributes",
+			attributes: attributes,
+			expExpired: 1,
+		},
+		{
+			name:   "failed, user not found",
+			names:  []string{user.Name},
+			getErr: errorsPkg.ErrUserNotFound,
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			if len(c.names) == 0 {
+				mockRepo.EXPECT().UserList(gomock.Any(), false, uint64(expirePageSize), uint64(0), filter.ListParams(c.attributes, "")).
+					Return([]models.User{user, expired}, nil).Times(1)
+			}
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(user, c.getErr).Times(1)
+			mockRepo.EXPECT().UserGet(gomock.Any(), expired.Name).Return(expired, nil).MaxTimes(1)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, updated models.User) error {
+					assert.Equal(t, user.Name, updated.Name)
+					assert.True(t, updated.PasswordExpired(time.Now()))
+					return nil
+				}).Times(int(c.expExpired))
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			count, err := userCtl.ExpirePasswords(context.Background(), c.names, c.attributes)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expExpired, count)
+		})
+	}
+}
+
+func Test_Import(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	imported := modeltest.From(user).WithID("").WithCreatedAt(0).
+		WithFullName("Ivan Imported").WithRole(modeltest.Admin).Build()
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	cases := []struct {
+		name      string
+		imported  models.User
+		strategy  models.ImportStrategy
+		stored    *models.User
+		getErr    error
+		created   int
+		updated   *models.User
+		expStatus models.ImportStatus
+		expErr    error
+	}{
+		{
+			name:      "success, created",
+			imported:  imported,
+			strategy:  models.ImportFail,
+			getErr:    errorsPkg.ErrUserNotFound,
+			created:   1,
+			expStatus: models.ImportCreated,
+		},
+		{
+			name:      "success, skipped",
+			imported:  imported,
+			strategy:  models.ImportSkip,
+			expStatus: models.ImportSkipped,
+		},
+		{
+			name:      "success, overwritten",
+			imported:  imported,
+			strategy:  models.ImportOverwrite,
+			updated:   userPtr(modeltest.From(imported).WithID(user.ID).WithCreatedAt(user.CreatedAt).Build()),
+			expStatus: models.ImportOverwritten,
+		},
+		{
+			name:      "success, overwritten with the same password keeps its expiration",
+			imported:  imported,
+			strategy:  models.ImportOverwrite,
+			stored:    userPtr(modeltest.From(user).WithPasswordExpiresAt(expiresAt).Build()),
+			updated:   userPtr(modeltest.From(imported).WithID(user.ID).WithCreatedAt(user.CreatedAt).WithPasswordExpiresAt(expiresAt).Build()),
+			expStatus: models.ImportOverwritten,
+		},
+		{
+			name:      "failed, overwritten with the expired password",
+			imported:  imported,
+			strategy:  models.ImportOverwrite,
+			stored:    userPtr(modeltest.From(user).WithPasswordExpiresAt(1).Build()),
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "success, merged",
+			imported:  models.User{Name: user.Name, FullName: imported.FullName},
+			strategy:  models.ImportMerge,
+			updated:   userPtr(modeltest.From(user).WithFullName(imported.FullName).Build()),
+			expStatus: models.ImportMerged,
+		},
+		{
+			name:      "failed, user exists",
+			imported:  imported,
+			strategy:  models.ImportFail,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrUserAlreadyExists,
+		},
+		{
+			name:      "failed, new user without password",
+			imported:  models.User{Name: user.Name, FullName: imported.FullName},
+			strategy:  models.ImportSkip,
+			getErr:    errorsPkg.ErrUserNotFound,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, new user with invalid email",
+			imported:  modeltest.From(imported).WithEmail("ivan").Build(),
+			strategy:  models.ImportFail,
+			getErr:    errorsPkg.ErrUserNotFound,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, merged invalid email",
+			imported:  models.User{Name: user.Name, Email: "ivan"},
+			strategy:  models.ImportMerge,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, unknown strategy",
+			imported:  imported,
+			strategy:  "replace",
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stored := user
+			if c.stored != nil {
+				stored = *c.stored
+			}
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(stored, c.getErr).MaxTimes(1)
+			mockRepo.EXPECT().UserCreate(gomock.Any(), gomock.Any()).
+				Return(nil).Times(c.created)
+			if c.updated != nil {
+				mockRepo.EXPECT().UserUpdate(gomock.Any(), written(*c.updated)).
+					Return(nil).Times(1)
+			}
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			status, err := userCtl.Import(context.Background(), c.imported, c.strategy)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expStatus, status)
+		})
+	}
+}
+
+func Test_ImportCheck(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	imported := modeltest.From(user).WithID("").WithCreatedAt(0).Build()
+
+	cases := []struct {
+		name      string
+		imported  models.User
+		strategy  models.ImportStrategy
+		getErr    error
+		expStatus models.ImportStatus
+		expErr    error
+	}{
+		{
+			name:      "success, created",
+			imported:  imported,
+			strategy:  models.ImportFail,
+			getErr:    errorsPkg.ErrUserNotFound,
+			expStatus: models.ImportCreated,
+		},
+		{
+			name:      "success, merged",
+			imported:  models.User{Name: user.Name},
+			strategy:  models.ImportMerge,
+			expStatus: models.ImportMerged,
+		},
+		{
+			name:      "failed, user exists",
+			imported:  imported,
+			strategy:  models.ImportFail,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrUserAlreadyExists,
+		},
+		{
+			name:      "failed, overwritten by invalid user",
+			imported:  models.User{Name: user.Name},
+			strategy:  models.ImportOverwrite,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, repository error",
+			imported:  imported,
+			strategy:  models.ImportSkip,
+			getErr:    errorsPkg.ErrUnexpected,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// nothing is written by the check
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(user, c.getErr).Times(1)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			status, err := userCtl.ImportCheck(context.Background(), c.imported, c.strategy)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expStatus, status)
+		})
+	}
+}
+
+func Test_Restore(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	restored := modeltest.From(user).WithStatus(models.StatusDisabled).WithPasswordExpiresAt(1).Build()
+
+	cases := []struct {
+		name      string
+		restored  models.User
+		overwrite bool
+		getErr    error
+		created   int
+		updated   int
+		expStatus models.ImportStatus
+		expErr    error
+	}{
+		{
+			name:      "success, created",
+			restored:  restored,
+			getErr:    errorsPkg.ErrUserNotFound,
+			created:   1,
+			expStatus: models.ImportCreated,
+		},
+		{
+			name:      "success, skipped",
+			restored:  restored,
+			expStatus: models.ImportSkipped,
+		},
+		{
+			name:      "success, overwritten",
+			restored:  restored,
+			overwrite: true,
+			updated:   1,
+			expStatus: models.ImportOverwritten,
+		},
+		{
+			name:      "failed, no id",
+			restored:  modeltest.From(restored).WithID("").Build(),
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed, unknown status",
+			restored:  modeltest.From(restored).WithStatus("deleted").Build(),
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrValidation,
+		},
+		{
+			name:      "failed UserGet unexpected error",
+			restored:  restored,
+			getErr:    errorsPkg.ErrUnexpected,
+			expStatus: models.ImportFailed,
+			expErr:    errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(user, c.getErr).MaxTimes(1)
+			mockRepo.EXPECT().UserCreate(gomock.Any(), written(restored)).
+				Return(nil).Times(c.created)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), written(restored)).
+				Return(nil).Times(c.updated)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			status, err := userCtl.Restore(context.Background(), c.restored, c.overwrite)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expStatus, status)
+		})
+	}
+}
+
+func Test_StateAt(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	at := time.Unix(1660000000, 0)
+	old := modeltest.From(user).WithFullName("Ivan Old").Build()
+	// the history keeps no passwords, the current one is restored
+	recorded := modeltest.From(old).WithPassword("").Build()
+
+	cases := []struct {
+		name     string
+		restore  bool
+		getErr   error
+		event    historyPkg.Event
+		atErr    error
+		restored int
+		created  int
+		expUser  models.User
+		expErr   error
+	}{
+		{
+			name:    "success",
+			event:   historyPkg.Event{Action: historyPkg.ActionUpdate, State: recorded},
+			expUser: recorded,
+		},
+		{
+			name:    "success, deleted user is found by name",
+			getErr:  errorsPkg.ErrUserNotFound,
+			event:   historyPkg.Event{Action: historyPkg.ActionUpdate, State: recorded},
+			expUser: recorded,
+		},
+		{
+			name:     "success, restored",
+			restore:  true,
+			event:    historyPkg.Event{Action: historyPkg.ActionUpdate, State: recorded},
+			restored: 1,
+			expUser:  recorded,
+		},
+		{
+			name:    "success, deleted user restored with a random password",
+			restore: true,
+			getErr:  errorsPkg.ErrUserNotFound,
+			event:   historyPkg.Event{Action: historyPkg.ActionUpdate, State: recorded},
+			created: 1,
+			expUser: recorded,
+		},
+		{
+			name:   "failed, deleted at the time",
+			event:  historyPkg.Event{Action: historyPkg.ActionDelete, State: old},
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+		{
+			name:   "failed, no history at the time",
+			atErr:  errorsPkg.ErrUserNotFound,
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+		{
+			name:    "failed, renamed since",
+			restore: true,
+			event:   historyPkg.Event{Action: historyPkg.ActionUpdate, State: modeltest.From(old).WithName("ivan_old").Build()},
+			expErr:  errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockHistory := historyMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(user, c.getErr).Times(1 + c.restored + c.created)
+			if c.getErr != nil {
+				mockHistory.EXPECT().UserID(gomock.Any(), user.Name).Return(user.ID, nil).Times(1)
+			}
+			mockHistory.EXPECT().At(gomock.Any(), user.ID, at).Return(c.event, c.atErr).Times(1)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), written(old)).Return(nil).Times(c.restored)
+			mockRepo.EXPECT().UserCreate(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, created models.User) error {
+					assert.True(t, passwordPkg.Hashed(created.Password))
+					assert.False(t, passwordPkg.Matches(created.Password, user.Password))
+					return nil
+				}).Times(c.created)
+			recordedState := written(recorded)
+			mockHistory.EXPECT().Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, event historyPkg.Event) error {
+					assert.Equal(t, historyPkg.ActionRestore, event.Action)
+					assert.True(t, recordedState.Matches(event.State))
+					return nil
+				}).Times(c.restored + c.created)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithHistory(mockHistory))
+			state, err := userCtl.StateAt(context.Background(), user.Name, at, c.restore)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expUser, state)
+		})
+	}
+
+	_, err := New(nil, loggerPkg.NewFatal(), client).StateAt(context.Background(), user.Name, at, false)
+	assert.ErrorIs(t, err, errorsPkg.ErrHistoryDisabled)
+}
+
+func Test_ListAt(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+	at := time.Unix(1660000000, 0)
+
+	core := modeltest.NewUser().WithName("anna").WithAttribute("team", "core").Build()
+	other := modeltest.NewUser().WithName("boris").WithAttribute("team", "other").Build()
+	next := modeltest.NewUser().WithName("ivan").WithAttribute("team", "core").Build()
+	event := func(user models.User) historyPkg.Event {
+		return historyPkg.Event{Name: user.Name, Action: historyPkg.ActionUpdate, State: user}
+	}
+	cleared := func(user models.User) models.User {
+		user.Password = ""
+		return user
+	}
+
+	mockHistory := historyMockPkg.NewMockInterface(ctl)
+	gomock.InOrder(
+		mockHistory.EXPECT().ListAt(gomock.Any(), at, false, "", uint64(2)).
+			Return([]historyPkg.Event{event(core), event(other)}, nil).Times(1),
+		// the page is not full after filtering, so the next one is read
+		mockHistory.EXPECT().ListAt(gomock.Any(), at, false, other.Name, uint64(2)).
+			Return([]historyPkg.Event{event(next)}, nil).Times(1),
+	)
+
+	userCtl := New(nil, loggerPkg.NewFatal(), client, WithHistory(mockHistory))
+	users, err := userCtl.ListAt(context.Background(), at, false, 2, "", map[string]string{"team": "core"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []models.User{cleared(core), cleared(next)}, users)
+
+	_, err = userCtl.ListAt(context.Background(), at, false, 2, "", nil, "unknown")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	_, err = New(nil, loggerPkg.NewFatal(), client).ListAt(context.Background(), at, false, 2, "", nil, "")
+	assert.ErrorIs(t, err, errorsPkg.ErrHistoryDisabled)
+}
+
+func userPtr(user models.User) *models.User {
+	return &user
+}
+
+func Test_Get(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name    string
+		user    string
+		getErr  error
+		expErr  error
+		expUser models.User
+	}{
+		{
+			name:    "success",
+			user:    user.Name,
+			getErr:  nil,
+			expErr:  nil,
+			expUser: user,
+		},
+		{
+			name:    "failed UserGet unexpected error",
+			user:    user.Name,
+			getErr:  errorsPkg.ErrUnexpected,
+			expErr:  errorsPkg.ErrUnexpected,
+			expUser: models.User{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			gomock.InOrder(
+				mockRepo.EXPECT().UserGet(gomock.Any(), c.user).
+					Return(c.expUser, c.getErr).Times(1),
+			)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			expUser, err := userCtl.Get(context.Background(), c.user)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, expUser, c.expUser)
+		})
+	}
+}
+
+func Test_GetReencoded(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, mockCache := redismock.NewClientMock()
+	codec, err := codecPkg.New(codecPkg.FormatProto)
+	require.NoError(t, err)
+	legacy, err := json.Marshal(user)
+	require.NoError(t, err)
+	encoded, err := codec.EncodeUser(user)
+	require.NoError(t, err)
+
+	mockCache.ExpectGet(user.Name).SetVal(string(legacy))
+	mockCache.ExpectEvalSha(reencodeScript.Hash(), []string{user.Name}, legacy, encoded).SetVal("OK")
+
+	userCtl := New(repoMockPkg.NewMockInterface(ctl), loggerPkg.NewFatal(), client, WithCodec(codec))
+	got, err := userCtl.Get(context.Background(), user.Name)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+	assert.NoError(t, mockCache.ExpectationsWereMet())
+}
+
+func Test_GetIfChanged(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name       string
+		known      string
+		deleted    bool
+		expChanged bool
+		expErr     error
+	}{
+		{
+			name:       "success, version differs",
+			known:      "stale",
+			expChanged: true,
+		},
+		{
+			name:       "success, no known version",
+			expChanged: true,
+		},
+		{
+			name:  "success, not modified",
+			known: user.Version(),
+		},
+		{
+			name:       "failed, deleted while waiting",
+			known:      user.Version(),
+			deleted:    true,
+			expChanged: true,
+			expErr:     errorsPkg.ErrUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			clk := clock.NewFake(time.Unix(1660412960, 0))
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithClock(clk))
+
+			read := make(chan struct{})
+			first := mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Do(func(context.Context, string) { close(read) }).
+				Return(user, nil).Times(1)
+			wait := 50 * time.Millisecond
+			if !c.deleted {
+				// the wait times out by the clock
+				go func() {
+					clk.BlockUntil(1)
+					<-read
+					clk.Advance(wait)
+				}()
+			} else {
+				gomock.InOrder(
+					first,
+					mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(user, nil).Times(1),
+					mockRepo.EXPECT().UserDelete(gomock.Any(), user.Name).Return(nil).Times(1),
+					mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(models.User{}, errorsPkg.ErrUserNotFound).Times(1),
+				)
+				go func() {
+					<-read
+					assert.NoError(t, userCtl.Delete(context.Background(), user.Name))
+				}()
+			}
+
+			got, changed, err := userCtl.GetIfChanged(context.Background(), user.Name, c.known, wait)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expChanged, changed)
+			if c.expChanged && c.expErr == nil {
+				assert.Equal(t, user, got)
+			}
+		})
+	}
+}
+
+func Test_GetByID(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name    string
+		id      string
+		calls   int
+		getErr  error
+		expErr  error
+		expUser models.User
+	}{
+		{
+			name:    "success",
+			id:      user.ID,
+			calls:   1,
+			expUser: user,
+		},
+		{
+			name:   "failed, id is not UUID",
+			id:     "ivan",
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed UserGetByID not found",
+			id:     user.ID,
+			calls:  1,
+			getErr: errorsPkg.ErrUserNotFound,
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGetByID(gomock.Any(), c.id).
+				Return(c.expUser, c.getErr).Times(c.calls)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			actualUser, err := userCtl.GetByID(context.Background(), c.id)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expUser, actualUser)
+		})
+	}
+}
+
+func Test_List(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name    string
+		listErr error
+		expErr  error
+		expList []models.User
+	}{
+		{
+			name:    "success",
+			listErr: nil,
+			expErr:  nil,
+			expList: []models.User{user},
+		},
+		{
+			name:    "failed UserList unexpected error",
+			listErr: errorsPkg.ErrUnexpected,
+			expErr:  errorsPkg.ErrUnexpected,
+			expList: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			gomock.InOrder(
+				mockRepo.EXPECT().UserList(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(c.expList, c.listErr).Times(1),
+			)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			expList, err := userCtl.List(context.Background(), true, 1, 1, nil, "")
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, expList, c.expList)
+		})
+	}
+}
+
+func Test_ListAfter(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name     string
+		order    bool
+		after    string
+		expWhere filter.Expr
+		listErr  error
+		expErr   error
+	}{
+		{
+			name:     "success, first page",
+			expWhere: filter.And{filter.Cond{Field: filter.FieldStatus, Op: filter.OpEq, Value: "active"}},
+		},
+		{
+			name:  "success, next page",
+			after: "Ivan",
+			expWhere: filter.And{
+				filter.Cond{Field: filter.FieldStatus, Op: filter.OpEq, Value: "active"},
+				filter.Cond{Field: filter.FieldName, Op: filter.OpGt, Value: "Ivan"},
+			},
+		},
+		{
+			name:  "success, next page of descending list",
+			order: true,
+			after: "Ivan",
+			expWhere: filter.And{
+				filter.Cond{Field: filter.FieldStatus, Op: filter.OpEq, Value: "active"},
+				filter.Cond{Field: filter.FieldName, Op: filter.OpLt, Value: "Ivan"},
+			},
+		},
+		{
+			name:     "failed UserList unexpected error",
+			expWhere: filter.And{filter.Cond{Field: filter.FieldStatus, Op: filter.OpEq, Value: "active"}},
+			listErr:  errorsPkg.ErrUnexpected,
+			expErr:   errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserList(gomock.Any(), c.order, uint64(10), uint64(0), c.expWhere).
+				Return([]models.User{user}, c.listErr).Times(1)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			_, err := userCtl.ListAfter(context.Background(), c.order, 10, c.after, nil, "active")
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func Test_Changes(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	const since = int64(1660412940)
+	cases := []struct {
+		name     string
+		after    string
+		expWhere filter.Expr
+		listErr  error
+		expErr   error
+	}{
+		{
+			name:     "success, first page",
+			expWhere: filter.And{filter.Cond{Field: filter.FieldUpdatedAt, Op: filter.OpGe, Value: since}},
+		},
+		{
+			name:  "success, next page",
+			after: "Ivan",
+			expWhere: filter.And{
+				filter.Cond{Field: filter.FieldUpdatedAt, Op: filter.OpGe, Value: since},
+				filter.Cond{Field: filter.FieldName, Op: filter.OpGt, Value: "Ivan"},
+			},
+		},
+		{
+			name:     "failed UserList unexpected error",
+			expWhere: filter.And{filter.Cond{Field: filter.FieldUpdatedAt, Op: filter.OpGe, Value: since}},
+			listErr:  errorsPkg.ErrUnexpected,
+			expErr:   errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			// pages follow by name, so users deleted during the export do not shift offsets
+			mockRepo.EXPECT().UserList(gomock.Any(), false, uint64(10), uint64(0), c.expWhere).
+				Return([]models.User{user}, c.listErr).Times(1)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			_, err := userCtl.Changes(context.Background(), since, c.after, 10)
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func Test_Tombstones(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	now := time.Now()
+	cases := []struct {
+		name      string
+		since     int64
+		retention time.Duration
+		expRead   bool
+		expErr    error
+	}{
+		{
+			name:      "success, within the retention",
+			since:     now.Add(-time.Hour).Unix(),
+			retention: 2 * time.Hour,
+			expRead:   true,
+		},
+		{
+			name:    "success, kept forever",
+			since:   1,
+			expRead: true,
+		},
+		{
+			name:      "success, first sync",
+			since:     0,
+			retention: time.Hour,
+			expRead:   true,
+		},
+		{
+			name:      "failed, before the retention",
+			since:     now.Add(-2 * time.Hour).Unix(),
+			retention: time.Hour,
+			expErr:    errorsPkg.ErrTombstonesPruned,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			if c.expRead {
+				mockRepo.EXPECT().UserTombstones(gomock.Any(), c.since).Return(nil, nil).Times(1)
+			}
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithTombstoneRetention(c.retention))
+			_, err := userCtl.Tombstones(context.Background(), c.since)
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func Test_PruneTombstones(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	now := time.Unix(1660412940, 0)
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserTombstonesPrune(gomock.Any(), now.Add(-time.Hour).Unix()).Return(int64(2), nil).Times(1)
+
+	pruned, err := New(mockRepo, loggerPkg.NewFatal(), client, WithTombstoneRetention(time.Hour)).
+		PruneTombstones(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruned)
+
+	// tombstones are kept forever without the retention
+	pruned, err = New(mockRepo, loggerPkg.NewFatal(), client).PruneTombstones(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+}
+
+func Test_Count(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, _ := redismock.NewClientMock()
+
+	cases := []struct {
+		name     string
+		tenant   string
+		counts   map[string]int64
+		err      error
+		expCount int64
+		expTotal int64
+		expErr   error
+	}{
+		{
+			name:     "success, tenant",
+			tenant:   "acme",
+			counts:   map[string]int64{"": 2, "acme": 3},
+			expCount: 3,
+			expTotal: 5,
+		},
+		{
+			name:     "success, without tenant",
+			counts:   map[string]int64{"": 2, "acme": 3},
+			expCount: 2,
+			expTotal: 5,
+		},
+		{
+			name:     "success, unknown tenant",
+			tenant:   "unknown",
+			counts:   map[string]int64{"acme": 3},
+			expTotal: 3,
+		},
+		{
+			name:   "failed, counts crashed",
+			err:    errorsPkg.ErrUnexpected,
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserCounts(gomock.Any()).Return(c.counts, c.err).Times(1)
+
+			count, total, err := New(mockRepo, loggerPkg.NewFatal(), client).Count(context.Background(), c.tenant)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expCount, count)
+			assert.Equal(t, c.expTotal, total)
+		})
+	}
+}
+
+func Test_Invalidate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, mockCache := redismock.NewClientMock()
+
+	mockCache.ExpectIncr(listGenerationKey).SetVal(2)
+	mockCache.ExpectDel("Ivan", "Petr").SetVal(1)
+
+	userCtl := New(repoMockPkg.NewMockInterface(ctl), loggerPkg.NewFatal(), client)
+	changed, cancel := userCtl.(*core).notifier.Wait("Petr")
+	defer cancel()
+
+	require.NoError(t, userCtl.Invalidate(context.Background(), "Ivan", "Petr"))
+	assert.NoError(t, mockCache.ExpectationsWereMet())
+	select {
+	case <-changed:
+	default:
+		t.Error("waiter of the changed user is not woken")
+	}
+}
+
+func Test_CacheGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	data, err := json.Marshal(user)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		cached    bool
+		expCached models.CachedUser
+		expErr    error
+	}{
+		{
+			name:      "success",
+			cached:    true,
+			expCached: models.CachedUser{User: user, TTL: 42 * time.Second},
+		},
+		{
+			name:   "failed, not cached",
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mockCache := redismock.NewClientMock()
+			if c.cached {
+				mockCache.ExpectGet(user.Name).SetVal(string(data))
+				mockCache.ExpectTTL(user.Name).SetVal(42 * time.Second)
+			} else {
+				mockCache.ExpectGet(user.Name).RedisNil()
+			}
+
+			userCtl := New(repoMockPkg.NewMockInterface(ctl), loggerPkg.NewFatal(), client)
+			cached, err := userCtl.CacheGet(context.Background(), user.Name)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expCached, cached)
+			assert.NoError(t, mockCache.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_CacheEvict(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	client, mockCache := redismock.NewClientMock()
+
+	mockCache.ExpectIncr(listGenerationKey).SetVal(2)
+	mockCache.ExpectDel(user.Name).SetVal(1)
+	mockCache.ExpectIncr(listGenerationKey).SetVal(3)
+	mockCache.ExpectDel(user.Name).SetVal(0)
+
+	userCtl := New(repoMockPkg.NewMockInterface(ctl), loggerPkg.NewFatal(), client)
+	changed, cancel := userCtl.(*core).notifier.Wait(user.Name)
+	defer cancel()
+
+	evicted, err := userCtl.CacheEvict(context.Background(), user.Name)
+	require.NoError(t, err)
+	assert.True(t, evicted)
+	select {
+	case <-changed:
+	default:
+		t.Error("waiter of the evicted user is not woken")
+	}
+	evicted, err = userCtl.CacheEvict(context.Background(), user.Name)
+	require.NoError(t, err)
+	assert.False(t, evicted, "the user is not cached anymore")
+	assert.NoError(t, mockCache.ExpectationsWereMet())
+}
+
+func Test_ListCached(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name     string
+		cached   bool
+		snapshot bool
+		expList  []models.User
+	}{
+		{
+			name:    "success, page from cache",
+			cached:  true,
+			expList: []models.User{user},
+		},
+		{
+			name:    "success, page from repo",
+			cached:  false,
+			expList: []models.User{user},
+		},
+		{
+			name:     "success, page of the list snapshot bypasses cache",
+			snapshot: true,
+			expList:  []models.User{user},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mockCache := redismock.NewClientMock()
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+
+			ctx := context.Background()
+			switch {
+			case c.snapshot:
+				var release func()
+				ctx, release = repoPkg.WithListSnapshot(ctx)
+				defer release()
+				mockRepo.EXPECT().UserList(gomock.Any(), true, uint64(1), uint64(1), nil).
+					Return(c.expList, nil).Times(1)
+			case c.cached:
+				mockCache.ExpectGet(listGenerationKey).SetVal("3")
+				data, _ := json.Marshal(c.expList)
+				mockCache.ExpectGet("list_3_true_1_1__").SetVal(string(data))
+			default:
+				mockCache.ExpectGet(listGenerationKey).SetVal("3")
+				mockCache.ExpectGet("list_3_true_1_1__").RedisNil()
+				mockRepo.EXPECT().UserList(gomock.Any(), true, uint64(1), uint64(1), nil).
+					Return(c.expList, nil).Times(1)
+			}
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			expList, err := userCtl.List(ctx, true, 1, 1, nil, "")
+			assert.NoError(t, err)
+			assert.Equal(t, c.expList, expList)
+			assert.NoError(t, mockCache.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_comparedPassword(t *testing.T) {
+	hash, err := passwordPkg.Hash(user.Password)
+	require.NoError(t, err)
+	stored := modeltest.From(user).WithPassword(hash).Build()
+	assert.Equal(t, hash, comparedPassword(stored, true))
+
+	// unknown users are compared by bcrypt as stored users are
+	compared := comparedPassword(models.User{}, false)
+	assert.True(t, passwordPkg.Hashed(compared))
+	assert.False(t, passwordEqual(compared, ""))
+	assert.False(t, passwordEqual(compared, user.Password))
+}
+
+func Test_CheckPassword(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	key := passwordAttemptsPrefix + user.Name
+	hash, err := passwordPkg.Hash(user.Password)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		password string
+		// attempts are failed attempts before the check
+		attempts int64
+		status   string
+		expired  bool
+		// plain is the password stored before passwords were hashed, it is hashed by the valid check
+		plain bool
+		// tenant of the user overrides the attempts limit with 3
+		tenant        bool
+		getErr        error
+		valid         bool
+		ttl           time.Duration
+		expErr        error
+		expRetryAfter time.Duration
+		expSecurity   []string
+	}{
+		{
+			name:        "success, valid password",
+			password:    user.Password,
+			attempts:    2,
+			valid:       true,
+			expSecurity: []string{eventsPkg.SecurityLoginSuccess},
+		},
+		{
+			name:        "success, invalid password",
+			password:    "wrong",
+			expSecurity: []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:        "success, invalid password locks the user",
+			password:    "wrong",
+			attempts:    4,
+			expSecurity: []string{eventsPkg.SecurityLoginFailure, eventsPkg.SecurityLockout},
+		},
+		{
+			name:        "success, valid password of the plain one",
+			password:    user.Password,
+			plain:       true,
+			valid:       true,
+			expSecurity: []string{eventsPkg.SecurityLoginSuccess},
+		},
+		{
+			name:        "success, invalid password of the plain one",
+			password:    "wrong",
+			plain:       true,
+			expSecurity: []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:        "success, unknown user",
+			password:    user.Password,
+			getErr:      errorsPkg.ErrUserNotFound,
+			expSecurity: []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:        "success, disabled user",
+			password:    user.Password,
+			status:      models.StatusDisabled,
+			expSecurity: []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:        "failed, password expired",
+			password:    user.Password,
+			expired:     true,
+			expErr:      errorsPkg.ErrPasswordExpired,
+			expSecurity: []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:          "failed, too many attempts",
+			password:      user.Password,
+			attempts:      5,
+			ttl:           time.Minute,
+			expErr:        errorsPkg.ErrTooManyAttempts,
+			expRetryAfter: time.Minute,
+			expSecurity:   []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:          "failed, too many attempts without expiration",
+			password:      user.Password,
+			attempts:      5,
+			ttl:           -1,
+			expErr:        errorsPkg.ErrTooManyAttempts,
+			expRetryAfter: passwordAttemptsWindow,
+			expSecurity:   []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:          "failed, too many attempts of the tenant limit",
+			password:      user.Password,
+			attempts:      3,
+			tenant:        true,
+			ttl:           time.Minute,
+			expErr:        errorsPkg.ErrTooManyAttempts,
+			expRetryAfter: time.Minute,
+			expSecurity:   []string{eventsPkg.SecurityLoginFailure},
+		},
+		{
+			name:   "failed, empty password",
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:     "failed UserGet unexpected error",
+			password: user.Password,
+			getErr:   errorsPkg.ErrUnexpected,
+			expErr:   errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mockCache := redismock.NewClientMock()
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+
+			if c.password != "" && !errors.Is(c.getErr, errorsPkg.ErrUnexpected) {
+				mockCache.ExpectIncr(key).SetVal(c.attempts + 1)
+				if c.attempts == 0 {
+					mockCache.ExpectExpire(key, passwordAttemptsWindow).SetVal(true)
+				}
+			}
+			if c.ttl != 0 {
+				mockCache.ExpectTTL(key).SetVal(c.ttl)
+			}
+			stored := modeltest.From(user).WithPassword(hash).Build()
+			if c.status != "" {
+				stored = modeltest.From(stored).WithStatus(c.status).Build()
+			}
+			if c.expired {
+				stored = modeltest.From(stored).WithPasswordExpiresAt(1).Build()
+			}
+			if c.plain {
+				stored = user
+			}
+			tenants := tenantPkg.New(tenantPkg.NewMemory(), loggerPkg.NewFatal())
+			if c.tenant {
+				stored = modeltest.From(stored).WithAttribute(passwordPkg.TenantAttribute, "staff").Build()
+				_, err := tenants.Set(context.Background(), tenantPkg.Overrides{Tenant: "staff", MaxPasswordAttempts: 3}, "admin")
+				require.NoError(t, err)
+			}
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).
+				Return(stored, c.getErr).MaxTimes(1)
+			if c.valid || c.expired {
+				mockCache.ExpectDel(key).SetVal(1)
+			}
+			if c.plain && c.valid {
+				mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(stored, nil).Times(1)
+				mockRepo.EXPECT().UserUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u models.User) error {
+					assert.True(t, passwordPkg.Matches(u.Password, user.Password))
+					return nil
+				}).Times(1)
+				mockCache.ExpectIncr(listGenerationKey).SetVal(1)
+				mockCache.ExpectDel(user.Name).SetVal(1)
+			}
+
+			var security []string
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client,
+				WithTenants(tenants),
+				WithSecurity(func(_ context.Context, event eventsPkg.SecurityEvent) {
+					assert.Equal(t, user.Name, event.Name)
+					security = append(security, event.Type)
+				}))
+			valid, err := userCtl.CheckPassword(context.Background(), user.Name, c.password)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.valid, valid)
+			retryAfter, _ := apperr.RetryAfter(err)
+			assert.Equal(t, c.expRetryAfter, retryAfter)
+			assert.Equal(t, c.expSecurity, security)
+			assert.NoError(t, mockCache.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_LoginExternal(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	identity := models.Identity{
+		Issuer:            "https://idp.example.com",
+		Subject:           "42",
+		Email:             "petr@example.com",
+		PreferredUsername: "Petr",
+	}
+	subject := filter.Eq(filter.Attribute(models.SubjectAttribute), identity.Key())
+	clk := clock.NewFake(time.Unix(1660412960, 0))
+
+	cases := []struct {
+		name      string
+		provision bool
+		linked    []models.User
+		getErr    error
+		created   int
+		expName   string
+		// expID matches the user ID kept by the session
+		expID  string
+		expErr error
+	}{
+		{
+			name:    "success, linked user",
+			linked:  []models.User{user},
+			expName: user.Name,
+			expID:   regexp.QuoteMeta(user.ID),
+		},
+		{
+			name:      "success, provisioned",
+			provision: true,
+			getErr:    errorsPkg.ErrUserNotFound,
+			created:   1,
+			expName:   "Petr",
+			expID:     "[0-9a-f-]{36}",
+		},
+		{
+			name:   "failed, unknown subject without provisioning",
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+		{
+			name:      "failed, name is taken by local user",
+			provision: true,
+			expErr:    errorsPkg.ErrUserAlreadyExists,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mockCache := redismock.NewClientMock()
+			mockCache.Regexp().ExpectSet(sessionPrefix+".+", c.expID, sessionExpirationTime).SetVal("OK")
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserList(gomock.Any(), false, uint64(2), uint64(0), subject).
+				Return(c.linked, nil).Times(1)
+			mockRepo.EXPECT().UserGet(gomock.Any(), "Petr").
+				Return(models.User{}, c.getErr).MaxTimes(1)
+			mockRepo.EXPECT().UserCreate(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, created models.User) error {
+					assert.Equal(t, map[string]string{models.SubjectAttribute: identity.Key()}, created.Attributes)
+					assert.NotEmpty(t, created.Password)
+					assert.Equal(t, clk.Now().Unix(), created.CreatedAt)
+					return nil
+				}).Times(c.created)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client, WithExternalLogin(c.provision, 0), WithClock(clk))
+			session, err := userCtl.LoginExternal(context.Background(), identity)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expName, session.Name)
+			if c.expErr == nil {
+				assert.Len(t, session.Token, 64)
+				assert.Equal(t, clk.Now().Add(sessionExpirationTime).Unix(), session.ExpiresAt)
+			}
+		})
+	}
+}
+
+func Test_SessionUser(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	disabled := modeltest.NewUser().WithStatus(models.StatusDisabled).Build()
+
+	cases := []struct {
+		name    string
+		token   string
+		id      string
+		stored  models.User
+		getErr  error
+		calls   int
+		expUser models.User
+		expErr  error
+	}{
+		{
+			name:    "success",
+			token:   "token",
+			id:      user.ID,
+			stored:  user,
+			calls:   1,
+			expUser: user,
+		},
+		{
+			name:   "failed, no token",
+			expErr: errorsPkg.ErrUnauthenticated,
+		},
+		{
+			name:   "failed, unknown token",
+			token:  "unknown",
+			expErr: errorsPkg.ErrUnauthenticated,
+		},
+		{
+			name:   "failed, user deleted",
+			token:  "token",
+			id:     user.ID,
+			getErr: errorsPkg.ErrUserNotFound,
+			calls:  1,
+			expErr: errorsPkg.ErrUnauthenticated,
+		},
+		{
+			name:   "failed, user disabled",
+			token:  "token",
+			id:     disabled.ID,
+			stored: disabled,
+			calls:  1,
+			expErr: errorsPkg.ErrUnauthenticated,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mockCache := redismock.NewClientMock()
+			switch {
+			case c.id != "":
+				mockCache.ExpectGet(sessionPrefix + c.token).SetVal(c.id)
+			case c.token != "":
+				mockCache.ExpectGet(sessionPrefix + c.token).RedisNil()
+			}
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGetByID(gomock.Any(), c.id).Return(c.stored, c.getErr).Times(c.calls)
+
+			userCtl := New(mockRepo, loggerPkg.NewFatal(), client)
+			got, err := userCtl.SessionUser(context.Background(), c.token)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expUser, got)
+			assert.NoError(t, mockCache.ExpectationsWereMet())
 		})
 	}
 }
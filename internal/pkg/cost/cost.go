@@ -0,0 +1,201 @@
+// Package cost charges every call of the data service by rows read by the repository, bytes
+// returned and time spent in the repository, so callers spending more than the budget per minute
+// are alerted before their query patterns overload the database.
+package cost
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	usagePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/usage"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+const (
+	defaultBudget = 100000
+	// window of the budget
+	window = time.Minute
+)
+
+// Config of the cost budget.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Budget is cost units a caller may spend per minute, default is 100000.
+	Budget float64 `mapstructure:"budget"`
+	// Weights of the cost parts in units, every part weighs 1, if no weight is set.
+	Weights Weights `mapstructure:"weights"`
+}
+
+// Weights convert the cost parts into units.
+type Weights struct {
+	// Row is units of a row read by the repository.
+	Row float64 `mapstructure:"row"`
+	// KiB is units of a KiB of serialized responses.
+	KiB float64 `mapstructure:"kib"`
+	// RepoMs is units of a millisecond spent in the repository.
+	RepoMs float64 `mapstructure:"repo_ms"`
+}
+
+// Cost of a call.
+type Cost struct {
+	Rows  uint64
+	Bytes uint64
+	Repo  time.Duration
+}
+
+// Units returns the cost in units of the weights.
+func (c Cost) Units(w Weights) float64 {
+	return float64(c.Rows)*w.Row +
+		float64(c.Bytes)/1024*w.KiB +
+		float64(c.Repo)/float64(time.Millisecond)*w.RepoMs
+}
+
+type meterKey struct{}
+
+// Meter collects rows read by the repository for a single call, it is safe for concurrent use.
+type Meter struct {
+	rows uint64
+}
+
+// WithMeter returns context, which collects rows read by the call.
+func WithMeter(ctx context.Context) (context.Context, *Meter) {
+	m := &Meter{}
+	return context.WithValue(ctx, meterKey{}, m), m
+}
+
+// AddRows adds rows read by the repository to the meter of ctx, it is no-op, if rows are not collected.
+func AddRows(ctx context.Context, rows uint64) {
+	if m, _ := ctx.Value(meterKey{}).(*Meter); m != nil {
+		atomic.AddUint64(&m.rows, rows)
+	}
+}
+
+// Rows returns rows read by the call.
+func (m *Meter) Rows() uint64 {
+	return atomic.LoadUint64(&m.rows)
+}
+
+// Budget sums cost of calls by caller within a minute.
+type Budget struct {
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+
+	mu     sync.Mutex
+	window int64
+	spent  map[string]float64
+}
+
+func New(cfg Config, clk clock.Clock, logger *zap.SugaredLogger) *Budget {
+	if cfg.Budget <= 0 {
+		cfg.Budget = defaultBudget
+	}
+	if cfg.Weights == (Weights{}) {
+		cfg.Weights = Weights{Row: 1, KiB: 1, RepoMs: 1}
+	}
+	logger.Infow("With cost budget started", "budget", cfg.Budget, "weights", cfg.Weights)
+	return &Budget{
+		cfg:    cfg,
+		clock:  clk,
+		logger: logger,
+		spent:  make(map[string]float64),
+	}
+}
+
+// Charge adds the cost of the call to the spending of the caller in the current minute.
+// It reports if the call made the caller exceed the budget, which happens once a minute.
+func (b *Budget) Charge(caller, method string, c Cost) bool {
+	units := c.Units(b.cfg.Weights)
+	counter.RequestCost.Add(method, uint64(math.Round(units)))
+
+	current := b.clock.Now().Truncate(window).Unix()
+	b.mu.Lock()
+	// spending of past minutes is not needed, so callers of one minute are kept only
+	if current != b.window {
+		b.window = current
+		b.spent = make(map[string]float64)
+	}
+	spent := b.spent[caller]
+	b.spent[caller] = spent + units
+	b.mu.Unlock()
+
+	if spent > b.cfg.Budget || spent+units <= b.cfg.Budget {
+		return false
+	}
+	counter.CostBudgetExceeded.Inc(caller)
+	b.logger.Warnw("caller exceeded cost budget", "caller", caller, "spent", spent+units, "budget", b.cfg.Budget,
+		"method", method, "rows", c.Rows, "bytes", c.Bytes, "repo", c.Repo)
+	return true
+}
+
+// measure returns context collecting rows and repo time of the call. Timings of the debug mode
+// are shared, since the repository adds time to the innermost ones only.
+func measure(ctx context.Context) (context.Context, *Meter, *timing.Timings) {
+	ctx, meter := WithMeter(ctx)
+	timings := timing.FromContext(ctx)
+	if timings == nil {
+		ctx, timings = timing.WithTimings(ctx)
+	}
+	return ctx, meter, timings
+}
+
+// UnaryInterceptor charges calls to the budget of their callers.
+func UnaryInterceptor(budget *Budget) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, meter, timings := measure(ctx)
+		resp, err := handler(ctx, req)
+		c := Cost{Rows: meter.Rows(), Repo: timings.Get(timing.Repo)}
+		if msg, ok := resp.(proto.Message); ok && err == nil {
+			c.Bytes = uint64(proto.Size(msg))
+		}
+		budget.Charge(usagePkg.Client(ctx), info.FullMethod, c)
+		return resp, err
+	}
+}
+
+// StreamInterceptor charges streams to the budget of their callers, when they are done.
+func StreamInterceptor(budget *Budget) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, meter, timings := measure(ss.Context())
+		stream := &meteredStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, stream)
+		budget.Charge(usagePkg.Client(ctx), info.FullMethod, Cost{
+			Rows:  meter.Rows(),
+			Bytes: stream.bytes,
+			Repo:  timings.Get(timing.Repo),
+		})
+		return err
+	}
+}
+
+// meteredStream counts bytes of sent messages.
+type meteredStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	bytes uint64
+}
+
+func (s *meteredStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *meteredStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(proto.Message); ok {
+		s.bytes += uint64(proto.Size(msg))
+	}
+	return s.ServerStream.SendMsg(m)
+}
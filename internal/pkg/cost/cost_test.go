@@ -0,0 +1,63 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+const userList = "/ozon.dev.homework.api.User/UserList"
+
+func TestBudget_Charge(t *testing.T) {
+	clk := clock.NewFake(time.Unix(120, 0))
+	budget := New(Config{Budget: 100}, clk, loggerPkg.NewFatal())
+
+	type call struct {
+		caller      string
+		advance     time.Duration
+		cost        Cost
+		expExceeded bool
+	}
+	calls := []call{
+		{caller: "shop", cost: Cost{Rows: 60}},
+		{caller: "shop", cost: Cost{Rows: 30, Bytes: 10 * 1024}},
+		{caller: "bot", cost: Cost{Rows: 50}},
+		{caller: "shop", cost: Cost{Repo: time.Millisecond}, expExceeded: true},
+		// the caller is alerted once a minute
+		{caller: "shop", cost: Cost{Rows: 100}},
+		{caller: "shop", advance: time.Minute, cost: Cost{Rows: 100}},
+		{caller: "shop", cost: Cost{Rows: 1}, expExceeded: true},
+	}
+	for i, c := range calls {
+		clk.Advance(c.advance)
+		assert.Equal(t, c.expExceeded, budget.Charge(c.caller, userList, c.cost), "call %d", i)
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	clk := clock.NewFake(time.Unix(120, 0))
+	budget := New(Config{Budget: 10, Weights: Weights{Row: 1}}, clk, loggerPkg.NewFatal())
+	interceptor := UnaryInterceptor(budget)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("meta", "shop"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: userList},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			AddRows(ctx, 10)
+			timing.Track(ctx, timing.Repo, time.Now())
+			return &pb.UserListResponse{}, nil
+		})
+	require.NoError(t, err)
+
+	// the rows read by the first call spent the budget
+	assert.True(t, budget.Charge("shop", userList, Cost{Rows: 1}))
+}
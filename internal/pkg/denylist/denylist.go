@@ -0,0 +1,193 @@
+//go:generate mockgen -source=denylist.go -destination=./mock/denylist_mock.go -package=mock
+
+package denylist
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+const (
+	namesKey    = "denylist_names"
+	patternsKey = "denylist_patterns"
+)
+
+var ErrStaticEntry = errors.New("entry is defined in config")
+
+// Config is a static part of the denylist, it cannot be changed at runtime.
+type Config struct {
+	Names    []string `mapstructure:"names"`
+	Patterns []string `mapstructure:"patterns"`
+}
+
+type Entry struct {
+	Value   string
+	Pattern bool
+	Static  bool
+}
+
+type Interface interface {
+	// Check returns ErrNameReserved, if the name is denied.
+	Check(ctx context.Context, name string) error
+	Add(ctx context.Context, value string, pattern bool) error
+	Remove(ctx context.Context, value string, pattern bool) error
+	List(ctx context.Context) ([]Entry, error)
+}
+
+// New returns denylist, which merges static entries from config
+// with runtime entries persisted in redis.
+func New(cfg Config, client *redis.Client) (Interface, error) {
+	d := &denylist{
+		client:   client,
+		names:    make(map[string]struct{}, len(cfg.Names)),
+		patterns: make(map[string]*regexp.Regexp, len(cfg.Patterns)),
+		compiled: make(map[string]*regexp.Regexp),
+	}
+	for _, name := range cfg.Names {
+		d.names[strings.ToLower(name)] = struct{}{}
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile pattern [%s]", pattern)
+		}
+		d.patterns[pattern] = re
+	}
+	return d, nil
+}
+
+type denylist struct {
+	client   *redis.Client
+	names    map[string]struct{}
+	patterns map[string]*regexp.Regexp
+
+	mu       sync.Mutex
+	compiled map[string]*regexp.Regexp
+}
+
+func (d *denylist) Check(ctx context.Context, name string) error {
+	lower := strings.ToLower(name)
+	if _, ok := d.names[lower]; ok {
+		return reserved(name)
+	}
+	for _, re := range d.patterns {
+		if re.MatchString(name) {
+			return reserved(name)
+		}
+	}
+
+	ok, err := d.client.SIsMember(ctx, namesKey, lower).Result()
+	if err != nil {
+		return errors.Wrap(err, "denylist names")
+	}
+	if ok {
+		return reserved(name)
+	}
+
+	patterns, err := d.client.SMembers(ctx, patternsKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "denylist patterns")
+	}
+	for _, pattern := range patterns {
+		re, err := d.compile(pattern)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(name) {
+			return reserved(name)
+		}
+	}
+	return nil
+}
+
+func (d *denylist) Add(ctx context.Context, value string, pattern bool) error {
+	if value == "" {
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [value] cannot be empty")
+	}
+	if pattern {
+		if _, err := d.compile(value); err != nil {
+			return errors.Wrapf(errorsPkg.ErrValidation, "field: [value] invalid pattern: %v", err)
+		}
+		return d.client.SAdd(ctx, patternsKey, value).Err()
+	}
+	return d.client.SAdd(ctx, namesKey, strings.ToLower(value)).Err()
+}
+
+func (d *denylist) Remove(ctx context.Context, value string, pattern bool) error {
+	if pattern {
+		if _, ok := d.patterns[value]; ok {
+			return errors.Wrapf(ErrStaticEntry, "pattern [%s]", value)
+		}
+		return d.client.SRem(ctx, patternsKey, value).Err()
+	}
+	value = strings.ToLower(value)
+	if _, ok := d.names[value]; ok {
+		return errors.Wrapf(ErrStaticEntry, "name [%s]", value)
+	}
+	return d.client.SRem(ctx, namesKey, value).Err()
+}
+
+func (d *denylist) List(ctx context.Context) ([]Entry, error) {
+	entries := make([]Entry, 0, len(d.names)+len(d.patterns))
+	for name := range d.names {
+		entries = append(entries, Entry{Value: name, Static: true})
+	}
+	for pattern := range d.patterns {
+		entries = append(entries, Entry{Value: pattern, Pattern: true, Static: true})
+	}
+
+	names, err := d.client.SMembers(ctx, namesKey).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "denylist names")
+	}
+	for _, name := range names {
+		if _, ok := d.names[name]; !ok {
+			entries = append(entries, Entry{Value: name})
+		}
+	}
+	patterns, err := d.client.SMembers(ctx, patternsKey).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "denylist patterns")
+	}
+	for _, pattern := range patterns {
+		if _, ok := d.patterns[pattern]; !ok {
+			entries = append(entries, Entry{Value: pattern, Pattern: true})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Pattern != entries[j].Pattern {
+			return !entries[i].Pattern
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries, nil
+}
+
+// compile caches runtime patterns, so they are not compiled on every check.
+func (d *denylist) compile(pattern string) (*regexp.Regexp, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if re, ok := d.compiled[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	d.compiled[pattern] = re
+	return re, nil
+}
+
+func reserved(name string) error {
+	return errors.Wrapf(errorsPkg.ErrNameReserved, "field: [name] [%s]", name)
+}
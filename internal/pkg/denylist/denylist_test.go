@@ -0,0 +1,88 @@
+package denylist
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func Test_Check(t *testing.T) {
+	cfg := Config{
+		Names:    []string{"Admin", "root"},
+		Patterns: []string{"^support"},
+	}
+
+	cases := []struct {
+		name     string
+		user     string
+		member   bool
+		patterns []string
+		expErr   error
+	}{
+		{
+			name:   "static name",
+			user:   "admin",
+			expErr: errorsPkg.ErrNameReserved,
+		},
+		{
+			name:   "static pattern",
+			user:   "support_team",
+			expErr: errorsPkg.ErrNameReserved,
+		},
+		{
+			name:   "runtime name",
+			user:   "Moderator",
+			member: true,
+			expErr: errorsPkg.ErrNameReserved,
+		},
+		{
+			name:     "runtime pattern",
+			user:     "bot_42",
+			patterns: []string{"^bot_[0-9]+$"},
+			expErr:   errorsPkg.ErrNameReserved,
+		},
+		{
+			name:     "allowed",
+			user:     "ivan",
+			patterns: []string{"^bot_[0-9]+$"},
+			expErr:   nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			mock.ExpectSIsMember(namesKey, strings.ToLower(c.user)).SetVal(c.member)
+			mock.ExpectSMembers(patternsKey).SetVal(c.patterns)
+
+			denylist, err := New(cfg, client)
+			require.NoError(t, err)
+
+			err = denylist.Check(context.Background(), c.user)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr != nil {
+				assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+			}
+		})
+	}
+}
+
+func Test_Remove(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	denylist, err := New(Config{Names: []string{"admin"}}, client)
+	require.NoError(t, err)
+
+	err = denylist.Remove(context.Background(), "ADMIN", false)
+	assert.ErrorIs(t, err, ErrStaticEntry)
+
+	mock.ExpectSRem(namesKey, "moderator").SetVal(1)
+	err = denylist.Remove(context.Background(), "Moderator", false)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,203 @@
+//go:generate mockgen -source=fake.go -destination=./mock/fake_mock.go -package=mock
+
+// Package fake creates realistic fake users, so demo environments and load tests are seeded in seconds.
+// Users are created by the core layer, so they are normalized and checked by the password policy
+// as users created by clients are.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+const (
+	// GeneratedAttribute marks fake users, so they are listed and deleted by the attribute.
+	GeneratedAttribute = "generated"
+
+	defaultMaxCount  = 10000
+	defaultBatchSize = 50
+	// attempts to create a user, a taken name is generated again
+	attempts     = 3
+	passwordSize = 16
+	// names are limited by the store, the prefix leaves a letter of the first and the last names
+	maxNameLength   = 30
+	maxPrefixLength = 20
+)
+
+// Config of the generator, it is disabled by default, since fake users must not get into production.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxCount limits users of one call, default is 10000.
+	MaxCount int `mapstructure:"max_count"`
+	// BatchSize is a number of users created concurrently, default is 50.
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// Report of the generation, lists contain user names.
+type Report struct {
+	Created []string `json:"created,omitempty"`
+	// Failed users with errors.
+	Failed []string `json:"failed,omitempty"`
+}
+
+type Interface interface {
+	// Generate creates count fake users, names start with the prefix. Users, which failed to be
+	// created, are reported, the error is returned for invalid arguments and done ctx only.
+	Generate(ctx context.Context, count int, prefix string) (Report, error)
+}
+
+type Generator struct {
+	cfg    Config
+	user   userPkg.Interface
+	logger *zap.SugaredLogger
+}
+
+func New(cfg Config, user userPkg.Interface, logger *zap.SugaredLogger) *Generator {
+	if cfg.MaxCount <= 0 {
+		cfg.MaxCount = defaultMaxCount
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	// fake values are random of the global source, without the seed every process repeats the names
+	gofakeit.Seed(0)
+	return &Generator{
+		cfg:    cfg,
+		user:   user,
+		logger: logger,
+	}
+}
+
+func (g *Generator) Generate(ctx context.Context, count int, prefix string) (Report, error) {
+	var report Report
+	if count <= 0 || count > g.cfg.MaxCount {
+		return report, errors.Wrapf(errorsPkg.ErrValidation, "field: [count] must be from 1 to %d", g.cfg.MaxCount)
+	}
+	if len(prefix) > maxPrefixLength {
+		return report, errors.Wrapf(errorsPkg.ErrValidation, "field: [prefix] is longer than %d", maxPrefixLength)
+	}
+
+	started := time.Now()
+	for done := 0; done < count; done += g.cfg.BatchSize {
+		size := g.cfg.BatchSize
+		if count-done < size {
+			size = count - done
+		}
+		g.batch(ctx, size, prefix, &report)
+		if err := ctx.Err(); err != nil {
+			return report, errors.Wrapf(err, "generate: created %d of %d", len(report.Created), count)
+		}
+	}
+	g.logger.Infow("Fake users generated", "prefix", prefix, "created", len(report.Created),
+		"failed", len(report.Failed), "duration", time.Since(started))
+	return report, nil
+}
+
+// batch creates users concurrently and adds them to the report.
+func (g *Generator) batch(ctx context.Context, size int, prefix string, report *Report) {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	wg.Add(size)
+	for i := 0; i < size; i++ {
+		go func() {
+			defer wg.Done()
+			name, err := g.create(ctx, prefix)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", name, err))
+				return
+			}
+			report.Created = append(report.Created, name)
+		}()
+	}
+	wg.Wait()
+}
+
+// create creates a fake user, the name is generated again, if it is taken.
+func (g *Generator) create(ctx context.Context, prefix string) (string, error) {
+	var (
+		user models.User
+		err  error
+	)
+	for attempt := 0; attempt < attempts; attempt++ {
+		if user, err = Build(prefix); err != nil {
+			return user.Name, err
+		}
+		err = g.user.Create(ctx, user)
+		if !errors.Is(err, errorsPkg.ErrUserAlreadyExists) {
+			break
+		}
+	}
+	if err != nil {
+		return user.Name, err
+	}
+	counter.UsersGenerated.Inc()
+	return user.Name, nil
+}
+
+// Build returns a fake user named with the prefix. Names and emails consist of lower case
+// latin letters, digits and dots, so they pass any normalization. Long first and last names
+// are cut in the user name, so it fits the store.
+func Build(prefix string) (models.User, error) {
+	if len(prefix) > maxPrefixLength {
+		return models.User{}, errors.Wrapf(errorsPkg.ErrValidation, "field: [prefix] is longer than %d", maxPrefixLength)
+	}
+	password, err := passwordPkg.Generate(passwordSize)
+	if err != nil {
+		return models.User{}, err
+	}
+	first, last := gofakeit.FirstName(), gofakeit.LastName()
+	login := plain(first) + "." + plain(last)
+	number := strconv.Itoa(gofakeit.Number(1000, 9999))
+	return models.User{
+		Name:     prefix + cut(plain(first), plain(last), maxNameLength-len(prefix)-len(number)) + number,
+		Password: password,
+		Email:    login + number + "@" + plain(gofakeit.LastName()) + "." + plain(gofakeit.DomainSuffix()),
+		FullName: first + " " + last,
+		Attributes: map[string]string{
+			GeneratedAttribute: "true",
+		},
+	}, nil
+}
+
+// cut joins the first and the last names by a dot, the longer one is cut, until they fit the size.
+func cut(first, last string, size int) string {
+	for len(first)+len(last)+1 > size && len(first)+len(last) > 2 {
+		if len(first) >= len(last) {
+			first = first[:len(first)-1]
+		} else {
+			last = last[:len(last)-1]
+		}
+	}
+	return first + "." + last
+}
+
+// plain returns lower case latin letters and digits of the value, e.g. "oconnor" of "O'Connor".
+func plain(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return -1
+	}, value)
+}
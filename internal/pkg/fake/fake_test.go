@@ -0,0 +1,167 @@
+package fake
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestBuild(t *testing.T) {
+	policy, err := passwordPkg.New(passwordPkg.Config{Default: passwordPkg.Policy{
+		MinLength: 12, Lower: true, Upper: true, Digit: true, Symbol: true, DisallowName: true,
+	}}, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		user, err := Build("demo_")
+		require.NoError(t, err)
+		assert.Regexp(t, `^demo_[a-z0-9]+\.[a-z0-9]+[0-9]{4}$`, user.Name)
+		assert.LessOrEqual(t, len(user.Name), maxNameLength)
+		assert.NoError(t, models.ValidateEmail(user.Email))
+		assert.NotEmpty(t, strings.TrimSpace(user.FullName))
+		assert.NoError(t, policy.Check(user))
+		assert.Equal(t, map[string]string{GeneratedAttribute: "true"}, user.Attributes)
+	}
+
+	// the longest prefix leaves a letter of the first and the last names
+	prefix := strings.Repeat("p", maxPrefixLength-1) + "_"
+	for i := 0; i < 100; i++ {
+		user, err := Build(prefix)
+		require.NoError(t, err)
+		assert.Regexp(t, `^p{19}_[a-z0-9]+\.[a-z0-9]+[0-9]{4}$`, user.Name)
+		assert.LessOrEqual(t, len(user.Name), maxNameLength)
+	}
+
+	_, err = Build(prefix + "p")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+}
+
+func Test_cut(t *testing.T) {
+	assert.Equal(t, "ivan.petrov", cut("ivan", "petrov", 20))
+	assert.Equal(t, "iva.petr", cut("ivan", "petrov", 8))
+	assert.Equal(t, "i.p", cut("ivan", "petrov", 3))
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	invalid := errors.Wrap(errorsPkg.ErrValidation, "field: [name] mixes scripts")
+
+	tests := []struct {
+		name    string
+		count   int
+		prefix  string
+		create  func(calls int) error
+		created int
+		failed  int
+		err     error
+	}{
+		{
+			name:    "created in batches",
+			count:   7,
+			create:  func(int) error { return nil },
+			created: 7,
+		},
+		{
+			name:  "taken names are generated again",
+			count: 1,
+			create: func(calls int) error {
+				if calls < 3 {
+					return errorsPkg.ErrUserAlreadyExists
+				}
+				return nil
+			},
+			created: 1,
+		},
+		{
+			name:   "attempts exhausted",
+			count:  1,
+			create: func(int) error { return errorsPkg.ErrUserAlreadyExists },
+			failed: 1,
+		},
+		{
+			name:   "rejected users are reported",
+			count:  2,
+			create: func(int) error { return invalid },
+			failed: 2,
+		},
+		{
+			name:  "count above the limit",
+			count: 11,
+			err:   errorsPkg.ErrValidation,
+		},
+		{
+			name:   "prefix above the limit",
+			count:  1,
+			prefix: "load_" + strings.Repeat("p", maxPrefixLength),
+			err:    errorsPkg.ErrValidation,
+		},
+		{
+			name:  "zero count",
+			count: 0,
+			err:   errorsPkg.ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			var (
+				mu    sync.Mutex
+				calls int
+			)
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			mockUser.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user models.User) error {
+				assert.True(t, strings.HasPrefix(user.Name, "load_"))
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				return tt.create(calls)
+			}).AnyTimes()
+
+			g := New(Config{MaxCount: 10, BatchSize: 3}, mockUser, loggerPkg.NewFatal())
+			prefix := "load_"
+			if tt.prefix != "" {
+				prefix = tt.prefix
+			}
+			report, err := g.Generate(context.Background(), tt.count, prefix)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Zero(t, calls)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, report.Created, tt.created)
+			assert.Len(t, report.Failed, tt.failed)
+		})
+	}
+}
+
+func TestGenerator_Generate_Canceled(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockUser := userMockPkg.NewMockInterface(ctl)
+	// the first batch cancels generation, following batches are not created
+	mockUser.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, models.User) error {
+		cancel()
+		return nil
+	}).Times(2)
+
+	g := New(Config{BatchSize: 2}, mockUser, loggerPkg.NewFatal())
+	report, err := g.Generate(ctx, 10, "")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, report.Created, 2)
+}
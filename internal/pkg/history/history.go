@@ -0,0 +1,291 @@
+//go:generate mockgen -source=history.go -destination=./mock/history_mock.go -package=mock
+
+package history
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	eventsPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/events"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRename  = "rename"
+	ActionImport  = "import"
+	ActionRestore = "restore"
+	ActionStatus  = "status"
+	ActionExpire  = "expire"
+)
+
+// Config of the user history.
+type Config struct {
+	Enabled   bool      `mapstructure:"enabled"`
+	Retention Retention `mapstructure:"retention"`
+}
+
+// Retention limits events kept in the history, zero limits keep events forever.
+type Retention struct {
+	// MaxAge removes events recorded earlier.
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// MaxRows removes the oldest events above the number.
+	MaxRows int64 `mapstructure:"max_rows"`
+	// Interval between prunes.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Enabled is true, if any limit is set.
+func (r Retention) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxRows > 0
+}
+
+// Event is a user change, State is the whole user after the change, or before it for deletion.
+type Event struct {
+	ID     int64
+	UserID string
+	// Name is a user name after the change.
+	Name   string
+	Action string
+	State  models.User
+	// Actor is a request meta of the change, or the identity the caller acts as.
+	Actor string
+	// RealActor is the request meta of the caller acting as Actor, empty otherwise.
+	RealActor string
+	CreatedAt time.Time
+}
+
+// Session of the caller acting as another identity, it is made of the changes recorded
+// in the history, so reads of the caller are not in it.
+type Session struct {
+	RealActor string
+	Actor     string
+	FirstAt   time.Time
+	LastAt    time.Time
+	// Changes is a number of the recorded changes.
+	Changes int64
+}
+
+type Interface interface {
+	// Record appends the event to the history, ID and creation time are set by the history.
+	Record(ctx context.Context, event Event) error
+	// UserID returns ID of the user, which was the last one recorded with the name.
+	UserID(ctx context.Context, name string) (string, error)
+	// At returns the last event of the user recorded not later than at.
+	// ErrUserNotFound is returned, if there is no such event.
+	At(ctx context.Context, userID string, at time.Time) (Event, error)
+	// ListAt returns the last events of users recorded not later than at, users deleted by them
+	// are left out. Events are ordered by the user name, descending with order, the list starts
+	// after the name. Zero limit returns all events.
+	ListAt(ctx context.Context, at time.Time, order bool, after string, limit uint64) ([]Event, error)
+	// Impersonations returns sessions of callers acting as other identities with changes
+	// recorded since the time, the latest sessions go first. Zero limit returns all sessions.
+	Impersonations(ctx context.Context, since time.Time, limit uint64) ([]Session, error)
+	// Prune removes events recorded before the time and the oldest events above maxRows,
+	// zero values disable the limits. It returns the number of removed events.
+	Prune(ctx context.Context, before time.Time, maxRows int64) (int64, error)
+	Close()
+}
+
+// Recorder returns handler of the core events, which appends them to the history.
+// Failure is logged only, since the change is already made.
+func Recorder(history Interface, logger *zap.SugaredLogger) eventsPkg.Handler {
+	return func(ctx context.Context, event eventsPkg.Event) {
+		change := event.Base()
+		if change.User.ID == "" {
+			logger.Errorw("record history", "name", change.User.Name, "action", change.Action, "error", "user state is unknown")
+			return
+		}
+		state := change.User
+		state.AvatarURL = ""
+		// even hashed passwords are not kept by the history, restored states keep the current password
+		state.Password = ""
+		err := history.Record(ctx, Event{
+			UserID:    state.ID,
+			Name:      state.Name,
+			Action:    change.Action,
+			State:     state,
+			Actor:     change.Actor,
+			RealActor: change.RealActor,
+		})
+		if err != nil {
+			logger.Errorw("record history", "name", state.Name, "action", change.Action, "error", err.Error())
+		}
+	}
+}
+
+// NewMemory returns history kept in memory, it is used with local storage.
+// Events are stamped by the clock.
+func NewMemory(clk clock.Clock) Interface {
+	return &memory{
+		users: make(map[string][]Event),
+		names: make(map[string]string),
+		clock: clk,
+	}
+}
+
+type memory struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	lastID int64
+	// users keeps events by user ID in order of recording
+	users map[string][]Event
+	names map[string]string
+}
+
+func (m *memory) Record(_ context.Context, event Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	event.ID = m.lastID
+	event.CreatedAt = m.clock.Now()
+	// attributes of the recorded state must not change with the caller's map
+	if event.State.Attributes != nil {
+		attributes := make(map[string]string, len(event.State.Attributes))
+		for key, value := range event.State.Attributes {
+			attributes[key] = value
+		}
+		event.State.Attributes = attributes
+	}
+	m.users[event.UserID] = append(m.users[event.UserID], event)
+	m.names[event.Name] = event.UserID
+	return nil
+}
+
+func (m *memory) UserID(_ context.Context, name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.names[name]
+	if !ok {
+		return "", errors.Wrapf(errorsPkg.ErrUserNotFound, "history of [%s]", name)
+	}
+	return id, nil
+}
+
+func (m *memory) At(_ context.Context, userID string, at time.Time) (Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := m.users[userID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if !events[i].CreatedAt.After(at) {
+			return events[i], nil
+		}
+	}
+	return Event{}, errors.Wrapf(errorsPkg.ErrUserNotFound, "history of [%s] at [%s]", userID, at)
+}
+
+func (m *memory) ListAt(_ context.Context, at time.Time, order bool, after string, limit uint64) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var list []Event
+	for _, events := range m.users {
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].CreatedAt.After(at) {
+				continue
+			}
+			event := events[i]
+			switch {
+			case event.Action == ActionDelete:
+			case after != "" && !order && event.Name <= after:
+			case after != "" && order && event.Name >= after:
+			default:
+				list = append(list, event)
+			}
+			break
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return (list[i].Name < list[j].Name) != order
+	})
+	if limit > 0 && uint64(len(list)) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *memory) Impersonations(_ context.Context, since time.Time, limit uint64) ([]Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type key struct{ real, actor string }
+	sessions := make(map[key]*Session)
+	for _, events := range m.users {
+		for _, event := range events {
+			if event.RealActor == "" || event.CreatedAt.Before(since) {
+				continue
+			}
+			k := key{real: event.RealActor, actor: event.Actor}
+			session, ok := sessions[k]
+			if !ok {
+				session = &Session{RealActor: event.RealActor, Actor: event.Actor, FirstAt: event.CreatedAt}
+				sessions[k] = session
+			}
+			if event.CreatedAt.Before(session.FirstAt) {
+				session.FirstAt = event.CreatedAt
+			}
+			if event.CreatedAt.After(session.LastAt) {
+				session.LastAt = event.CreatedAt
+			}
+			session.Changes++
+		}
+	}
+
+	list := make([]Session, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, *session)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastAt.After(list[j].LastAt)
+	})
+	if limit > 0 && uint64(len(list)) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *memory) Prune(_ context.Context, before time.Time, maxRows int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []Event
+	for _, userEvents := range m.users {
+		events = append(events, userEvents...)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	kept := make([]Event, 0, len(events))
+	for _, event := range events {
+		if !before.IsZero() && event.CreatedAt.Before(before) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	if maxRows > 0 && int64(len(kept)) > maxRows {
+		kept = kept[int64(len(kept))-maxRows:]
+	}
+
+	m.users = make(map[string][]Event)
+	m.names = make(map[string]string)
+	for _, event := range kept {
+		m.users[event.UserID] = append(m.users[event.UserID], event)
+		m.names[event.Name] = event.UserID
+	}
+	return int64(len(events) - len(kept)), nil
+}
+
+func (m *memory) Close() {}
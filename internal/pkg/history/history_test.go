@@ -0,0 +1,258 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+var start = time.Unix(1660412960, 0)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(start)
+	h := NewMemory(clk)
+	user := modeltest.Ivan()
+	renamed := modeltest.From(user).WithName("ivan_new").Build()
+
+	require.NoError(t, h.Record(ctx, Event{UserID: user.ID, Name: user.Name, Action: ActionCreate, State: user}))
+	created := clk.Now()
+	clk.Advance(time.Minute)
+	require.NoError(t, h.Record(ctx, Event{UserID: user.ID, Name: renamed.Name, Action: ActionRename, State: renamed}))
+
+	id, err := h.UserID(ctx, user.Name)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, id)
+	_, err = h.UserID(ctx, "unknown")
+	assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+
+	cases := []struct {
+		name      string
+		at        time.Time
+		expAction string
+		expErr    error
+	}{
+		{
+			name:      "latest",
+			at:        clk.Now(),
+			expAction: ActionRename,
+		},
+		{
+			name:      "before rename",
+			at:        created,
+			expAction: ActionCreate,
+		},
+		{
+			name:   "before create",
+			at:     created.Add(-time.Hour),
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			event, err := h.At(ctx, user.ID, c.at)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expAction, event.Action)
+		})
+	}
+}
+
+func TestMemory_ListAt(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(start)
+	h := NewMemory(clk)
+	ivan, boris, arnold := modeltest.Ivan(), modeltest.Boris(), modeltest.Arnold()
+
+	for _, user := range []models.User{ivan, boris, arnold} {
+		require.NoError(t, h.Record(ctx, Event{UserID: user.ID, Name: user.Name, Action: ActionCreate, State: user}))
+	}
+	created := clk.Now()
+	clk.Advance(time.Minute)
+	require.NoError(t, h.Record(ctx, Event{UserID: boris.ID, Name: boris.Name, Action: ActionDelete, State: boris}))
+	renamed := modeltest.From(ivan).WithName("Zed").Build()
+	require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: renamed.Name, Action: ActionRename, State: renamed}))
+
+	names := func(events []Event) []string {
+		var list []string
+		for _, event := range events {
+			list = append(list, event.Name)
+		}
+		return list
+	}
+
+	cases := []struct {
+		name     string
+		at       time.Time
+		order    bool
+		after    string
+		limit    uint64
+		expNames []string
+	}{
+		{
+			name:     "latest",
+			at:       clk.Now(),
+			expNames: []string{arnold.Name, "Zed"},
+		},
+		{
+			name:     "before deletion and rename",
+			at:       created,
+			expNames: []string{arnold.Name, boris.Name, ivan.Name},
+		},
+		{
+			name:     "descending page",
+			at:       created,
+			order:    true,
+			after:    ivan.Name,
+			limit:    1,
+			expNames: []string{boris.Name},
+		},
+		{
+			name: "before create",
+			at:   created.Add(-time.Hour),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			events, err := h.ListAt(ctx, c.at, c.order, c.after, c.limit)
+			require.NoError(t, err)
+			assert.Equal(t, c.expNames, names(events))
+		})
+	}
+}
+
+func TestMemory_Prune(t *testing.T) {
+	ctx := context.Background()
+	ivan := modeltest.Ivan()
+	petr := modeltest.NewUser().WithName("petr").Build()
+
+	cases := []struct {
+		name      string
+		byAge     bool
+		maxRows   int64
+		expPruned int64
+		expIvan   bool
+	}{
+		{
+			name:    "nothing to prune",
+			expIvan: true,
+		},
+		{
+			name:      "by age",
+			byAge:     true,
+			expPruned: 2,
+		},
+		{
+			name:      "by rows",
+			maxRows:   2,
+			expPruned: 1,
+			expIvan:   true,
+		},
+		{
+			name:      "by age and rows",
+			byAge:     true,
+			maxRows:   2,
+			expPruned: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clk := clock.NewFake(start)
+			h := NewMemory(clk)
+			require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionCreate, State: ivan}))
+			require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionUpdate, State: ivan}))
+			clk.Advance(time.Minute)
+			before := clk.Now()
+			clk.Advance(time.Second)
+			require.NoError(t, h.Record(ctx, Event{UserID: petr.ID, Name: petr.Name, Action: ActionCreate, State: petr}))
+
+			if !c.byAge {
+				before = time.Time{}
+			}
+			pruned, err := h.Prune(ctx, before, c.maxRows)
+			require.NoError(t, err)
+			assert.Equal(t, c.expPruned, pruned)
+
+			_, err = h.UserID(ctx, ivan.Name)
+			assert.Equal(t, c.expIvan, err == nil)
+			_, err = h.UserID(ctx, petr.Name)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemory_Impersonations(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(start)
+	h := NewMemory(clk)
+	ivan := modeltest.Ivan()
+	petr := modeltest.NewUser().WithName("petr").Build()
+
+	since := clk.Now()
+	require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionCreate, State: ivan, Actor: "admin"}))
+	require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionUpdate, State: ivan,
+		Actor: "ivan", RealActor: "support"}))
+	require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionUpdate, State: ivan,
+		Actor: "ivan", RealActor: "support"}))
+	clk.Advance(time.Minute)
+	require.NoError(t, h.Record(ctx, Event{UserID: petr.ID, Name: petr.Name, Action: ActionUpdate, State: petr,
+		Actor: "petr", RealActor: "support"}))
+
+	sessions, err := h.Impersonations(ctx, since, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "petr", sessions[0].Actor)
+	assert.Equal(t, int64(1), sessions[0].Changes)
+	assert.Equal(t, "ivan", sessions[1].Actor)
+	assert.Equal(t, "support", sessions[1].RealActor)
+	assert.Equal(t, int64(2), sessions[1].Changes)
+
+	sessions, err = h.Impersonations(ctx, since, 1)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	sessions, err = h.Impersonations(ctx, time.Now().Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestPruner_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clk := clock.NewFake(start)
+	h := NewMemory(clk)
+	ivan := modeltest.Ivan()
+	require.NoError(t, h.Record(ctx, Event{UserID: ivan.ID, Name: ivan.Name, Action: ActionCreate, State: ivan}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewPruner(h, Retention{MaxAge: time.Hour, Interval: time.Minute}, clk, loggerPkg.NewFatal()).Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// the event within the retention is kept
+	clk.BlockUntil(1)
+	_, err := h.UserID(ctx, ivan.Name)
+	require.NoError(t, err)
+
+	// the tick after the retention prunes it
+	clk.Advance(2 * time.Hour)
+	assert.Eventually(t, func() bool {
+		_, err = h.UserID(ctx, ivan.Name)
+		return err != nil
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: history.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	history "gitlab.ozon.dev/iTukaev/homework/internal/pkg/history"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// At mocks base method.
+func (m *MockInterface) At(ctx context.Context, userID string, at time.Time) (history.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "At", ctx, userID, at)
+	ret0, _ := ret[0].(history.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// At indicates an expected call of At.
+func (mr *MockInterfaceMockRecorder) At(ctx, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "At", reflect.TypeOf((*MockInterface)(nil).At), ctx, userID, at)
+}
+
+// Close mocks base method.
+func (m *MockInterface) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockInterfaceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockInterface)(nil).Close))
+}
+
+// Impersonations mocks base method.
+func (m *MockInterface) Impersonations(ctx context.Context, since time.Time, limit uint64) ([]history.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonations", ctx, since, limit)
+	ret0, _ := ret[0].([]history.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonations indicates an expected call of Impersonations.
+func (mr *MockInterfaceMockRecorder) Impersonations(ctx, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonations", reflect.TypeOf((*MockInterface)(nil).Impersonations), ctx, since, limit)
+}
+
+// ListAt mocks base method.
+func (m *MockInterface) ListAt(ctx context.Context, at time.Time, order bool, after string, limit uint64) ([]history.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAt", ctx, at, order, after, limit)
+	ret0, _ := ret[0].([]history.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAt indicates an expected call of ListAt.
+func (mr *MockInterfaceMockRecorder) ListAt(ctx, at, order, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAt", reflect.TypeOf((*MockInterface)(nil).ListAt), ctx, at, order, after, limit)
+}
+
+// Prune mocks base method.
+func (m *MockInterface) Prune(ctx context.Context, before time.Time, maxRows int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", ctx, before, maxRows)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockInterfaceMockRecorder) Prune(ctx, before, maxRows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockInterface)(nil).Prune), ctx, before, maxRows)
+}
+
+// Record mocks base method.
+func (m *MockInterface) Record(ctx context.Context, event history.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockInterfaceMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockInterface)(nil).Record), ctx, event)
+}
+
+// UserID mocks base method.
+func (m *MockInterface) UserID(ctx context.Context, name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserID", ctx, name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserID indicates an expected call of UserID.
+func (mr *MockInterfaceMockRecorder) UserID(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserID", reflect.TypeOf((*MockInterface)(nil).UserID), ctx, name)
+}
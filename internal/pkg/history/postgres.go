@@ -0,0 +1,362 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+const (
+	historyTable = "users_history"
+	// partitionPrefix and partitionLayout make names of daily partitions of the table,
+	// e.g. users_history_p20261016. Rows out of them are kept in the default partition.
+	partitionPrefix = historyTable + "_p"
+	partitionLayout = "20060102"
+	// partitionsAhead is a number of daily partitions created ahead by the prune
+	partitionsAhead = 7
+
+	idField        = "id"
+	userIDField    = "user_id"
+	nameField      = "name"
+	actionField    = "action"
+	stateField     = "state"
+	actorField     = "actor"
+	realActorField = "real_actor"
+	createdAtField = "created_at"
+)
+
+var eventColumns = []string{idField, userIDField, nameField, actionField, stateField, actorField, realActorField, createdAtField}
+
+type PgxPool interface {
+	pgxtype.Querier
+	Close()
+}
+
+// NewPostgres returns history kept in the users_history table, the pool is closed with the history.
+func NewPostgres(pool PgxPool, logger *zap.SugaredLogger) Interface {
+	logger.Infoln("With PostgreSQL user history started")
+	return &postgres{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+type postgres struct {
+	pool   PgxPool
+	logger *zap.SugaredLogger
+}
+
+func (p *postgres) Record(ctx context.Context, event Event) error {
+	state, err := json.Marshal(event.State)
+	if err != nil {
+		return errors.Wrapf(err, "history record [%s]", event.Name)
+	}
+	query, args, err := squirrel.Insert(historyTable).
+		Columns(eventColumns[1:]...).
+		Values(event.UserID, event.Name, event.Action, string(state), event.Actor, event.RealActor, time.Now()).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return errors.Wrapf(err, "history record [%s]", event.Name)
+	}
+	p.logger.Debugln("Record", query, event.UserID, event.Action)
+
+	if _, err = p.pool.Exec(ctx, query, args...); err != nil {
+		return errors.Wrapf(err, "history record [%s]", event.Name)
+	}
+	return nil
+}
+
+func (p *postgres) UserID(ctx context.Context, name string) (string, error) {
+	query, args, err := squirrel.Select(userIDField).
+		From(historyTable).
+		Where(squirrel.Eq{
+			nameField: name,
+		}).
+		OrderBy(idField + " DESC").
+		Limit(1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return "", errors.Wrapf(err, "history of [%s]", name)
+	}
+	p.logger.Debugln("UserID", query, args)
+
+	var id string
+	if err = p.pool.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errors.Wrapf(errorsPkg.ErrUserNotFound, "history of [%s]", name)
+		}
+		return "", errors.Wrapf(err, "history of [%s]", name)
+	}
+	return id, nil
+}
+
+func (p *postgres) At(ctx context.Context, userID string, at time.Time) (Event, error) {
+	query, args, err := squirrel.Select(eventColumns...).
+		From(historyTable).
+		Where(squirrel.Eq{
+			userIDField: userID,
+		}).
+		Where(squirrel.LtOrEq{
+			createdAtField: at,
+		}).
+		OrderBy(idField + " DESC").
+		Limit(1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return Event{}, errors.Wrapf(err, "history of [%s]", userID)
+	}
+	p.logger.Debugln("At", query, args)
+
+	event, err := scanEvent(p.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Event{}, errors.Wrapf(errorsPkg.ErrUserNotFound, "history of [%s] at [%s]", userID, at)
+		}
+		return Event{}, errors.Wrapf(err, "history of [%s]", userID)
+	}
+	return event, nil
+}
+
+// ListAt takes the last event of every user by DISTINCT ON, deletions are filtered out after it,
+// so users deleted at the time are not listed by their earlier events.
+func (p *postgres) ListAt(ctx context.Context, at time.Time, order bool, after string, limit uint64) ([]Event, error) {
+	last := squirrel.Select(eventColumns...).
+		Options("DISTINCT ON ("+userIDField+")").
+		From(historyTable).
+		Where(squirrel.LtOrEq{
+			createdAtField: at,
+		}).
+		OrderBy(userIDField, idField+" DESC")
+
+	orderBy := nameField
+	var next squirrel.Sqlizer = squirrel.Gt{nameField: after}
+	if order {
+		orderBy += " DESC"
+		next = squirrel.Lt{nameField: after}
+	}
+	builder := squirrel.Select(eventColumns...).
+		FromSelect(last, "last").
+		Where(squirrel.NotEq{
+			actionField: ActionDelete,
+		}).
+		OrderBy(orderBy).
+		PlaceholderFormat(squirrel.Dollar)
+	if after != "" {
+		builder = builder.Where(next)
+	}
+	if limit > 0 {
+		builder = builder.Limit(limit)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "history list at")
+	}
+	p.logger.Debugln("ListAt", query, args)
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "history list at")
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "history list at")
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "history list at")
+	}
+	return events, nil
+}
+
+// scanEvent reads row of eventColumns.
+func scanEvent(row pgx.Row) (Event, error) {
+	var (
+		event Event
+		state []byte
+	)
+	err := row.Scan(&event.ID, &event.UserID, &event.Name, &event.Action, &state, &event.Actor, &event.RealActor, &event.CreatedAt)
+	if err != nil {
+		return Event{}, err
+	}
+	if err = json.Unmarshal(state, &event.State); err != nil {
+		return Event{}, errors.Wrapf(err, "history of [%s] state", event.UserID)
+	}
+	return event, nil
+}
+
+// Impersonations groups the changes by both actors, each pair is a session.
+func (p *postgres) Impersonations(ctx context.Context, since time.Time, limit uint64) ([]Session, error) {
+	builder := squirrel.Select(realActorField, actorField,
+		"min("+createdAtField+")", "max("+createdAtField+")", "count(*)").
+		From(historyTable).
+		Where(squirrel.NotEq{
+			realActorField: "",
+		}).
+		Where(squirrel.GtOrEq{
+			createdAtField: since,
+		}).
+		GroupBy(realActorField, actorField).
+		OrderBy("max(" + createdAtField + ") DESC").
+		PlaceholderFormat(squirrel.Dollar)
+	if limit > 0 {
+		builder = builder.Limit(limit)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "history impersonations")
+	}
+	p.logger.Debugln("Impersonations", query, args)
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "history impersonations")
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var session Session
+		if err = rows.Scan(&session.RealActor, &session.Actor, &session.FirstAt, &session.LastAt, &session.Changes); err != nil {
+			return nil, errors.Wrap(err, "history impersonations")
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "history impersonations")
+	}
+	return sessions, nil
+}
+
+// Prune creates daily partitions ahead, drops partitions ended before the time
+// and deletes the rest of the events out of the limits.
+func (p *postgres) Prune(ctx context.Context, before time.Time, maxRows int64) (int64, error) {
+	if err := p.createPartitions(ctx, time.Now()); err != nil {
+		return 0, err
+	}
+
+	var pruned int64
+	if !before.IsZero() {
+		dropped, err := p.dropPartitions(ctx, before)
+		pruned += dropped
+		if err != nil {
+			return pruned, err
+		}
+
+		query, args, err := squirrel.Delete(historyTable).
+			Where(squirrel.Lt{
+				createdAtField: before,
+			}).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			return pruned, errors.Wrap(err, "history prune")
+		}
+		p.logger.Debugln("Prune", query, args)
+		tag, err := p.pool.Exec(ctx, query, args...)
+		if err != nil {
+			return pruned, errors.Wrap(err, "history prune")
+		}
+		pruned += tag.RowsAffected()
+	}
+
+	if maxRows > 0 {
+		// ids grow with recording, so events with ids up to the oldest kept one are removed
+		query := "DELETE FROM " + historyTable + " WHERE " + idField + " <= (SELECT " + idField +
+			" FROM " + historyTable + " ORDER BY " + idField + " DESC OFFSET $1 LIMIT 1)"
+		p.logger.Debugln("Prune", query, maxRows)
+		tag, err := p.pool.Exec(ctx, query, maxRows)
+		if err != nil {
+			return pruned, errors.Wrap(err, "history prune rows")
+		}
+		pruned += tag.RowsAffected()
+	}
+	return pruned, nil
+}
+
+// createPartitions creates daily partitions starting from the next day, since rows of
+// the current one may be kept in the default partition already.
+func (p *postgres) createPartitions(ctx context.Context, now time.Time) error {
+	day := now.UTC().Truncate(24 * time.Hour)
+	for i := 1; i <= partitionsAhead; i++ {
+		from := day.AddDate(0, 0, i)
+		query := "CREATE TABLE IF NOT EXISTS " + partitionName(from) + " PARTITION OF " + historyTable +
+			" FOR VALUES FROM ('" + from.Format(time.RFC3339) + "') TO ('" + from.AddDate(0, 0, 1).Format(time.RFC3339) + "')"
+		if _, err := p.pool.Exec(ctx, query); err != nil {
+			return errors.Wrapf(err, "history partition [%s]", from.Format(partitionLayout))
+		}
+	}
+	return nil
+}
+
+// dropPartitions drops daily partitions ended before the time, it is cheaper than deletion of their rows.
+func (p *postgres) dropPartitions(ctx context.Context, before time.Time) (int64, error) {
+	rows, err := p.pool.Query(ctx, "SELECT c.relname FROM pg_inherits i "+
+		"JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class t ON t.oid = i.inhparent "+
+		"WHERE t.relname = $1", historyTable)
+	if err != nil {
+		return 0, errors.Wrap(err, "history partitions")
+	}
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, errors.Wrap(err, "history partitions")
+		}
+		if !strings.HasPrefix(name, partitionPrefix) {
+			continue
+		}
+		day, err := time.Parse(partitionLayout, strings.TrimPrefix(name, partitionPrefix))
+		if err != nil {
+			continue
+		}
+		if !day.AddDate(0, 0, 1).After(before) {
+			expired = append(expired, name)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "history partitions")
+	}
+
+	var dropped int64
+	for _, name := range expired {
+		var count int64
+		table := pgx.Identifier{name}.Sanitize()
+		if err = p.pool.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&count); err != nil {
+			return dropped, errors.Wrapf(err, "history partition [%s]", name)
+		}
+		if _, err = p.pool.Exec(ctx, "DROP TABLE "+table); err != nil {
+			return dropped, errors.Wrapf(err, "history partition [%s]", name)
+		}
+		dropped += count
+		p.logger.Infow("History partition dropped", "partition", name, "events", count)
+	}
+	return dropped, nil
+}
+
+func partitionName(day time.Time) string {
+	return partitionPrefix + day.Format(partitionLayout)
+}
+
+func (p *postgres) Close() {
+	p.pool.Close()
+	p.logger.Infoln("PostgreSQL user history connection closed")
+}
@@ -0,0 +1,173 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+var user = modeltest.NewUser().WithAttribute("team", "core").Build()
+
+func TestPostgres_Record(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	state, err := json.Marshal(user)
+	require.NoError(t, err)
+	mock.ExpectExec("INSERT INTO users_history (user_id,name,action,state,actor,real_actor,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7)").
+		WithArgs(user.ID, user.Name, ActionUpdate, string(state), "meta", "", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	h := &postgres{pool: mock, logger: loggerPkg.NewFatal()}
+	err = h.Record(context.Background(), Event{UserID: user.ID, Name: user.Name, Action: ActionUpdate, State: user, Actor: "meta"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgres_At(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	at := time.Unix(1660000000, 0)
+	state, err := json.Marshal(user)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		err    error
+		expErr error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:   "failed, no event",
+			err:    pgx.ErrNoRows,
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+		{
+			name:   "failed, query crashed",
+			err:    errorsPkg.ErrUnexpected,
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+	query := "SELECT id, user_id, name, action, state, actor, real_actor, created_at FROM users_history " +
+		"WHERE user_id = $1 AND created_at <= $2 ORDER BY id DESC LIMIT 1"
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rows := pgxmock.NewRows(eventColumns).
+				AddRow(int64(7), user.ID, user.Name, ActionUpdate, state, "meta", "", at)
+			mock.ExpectQuery(query).
+				WithArgs(user.ID, at).
+				WillReturnRows(rows).
+				WillReturnError(c.err)
+
+			h := &postgres{pool: mock, logger: loggerPkg.NewFatal()}
+			event, err := h.At(context.Background(), user.ID, at)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, Event{ID: 7, UserID: user.ID, Name: user.Name, Action: ActionUpdate, State: user,
+					Actor: "meta", CreatedAt: at}, event)
+			}
+		})
+	}
+}
+
+func TestPostgres_ListAt(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	at := time.Unix(1660000000, 0)
+	state, err := json.Marshal(user)
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT id, user_id, name, action, state, actor, real_actor, created_at FROM "+
+		"(SELECT DISTINCT ON (user_id) id, user_id, name, action, state, actor, real_actor, created_at FROM users_history "+
+		"WHERE created_at <= $1 ORDER BY user_id, id DESC) AS last "+
+		"WHERE action <> $2 AND name < $3 ORDER BY name DESC LIMIT 10").
+		WithArgs(at, ActionDelete, "petr").
+		WillReturnRows(pgxmock.NewRows(eventColumns).
+			AddRow(int64(7), user.ID, user.Name, ActionUpdate, state, "meta", "", at))
+
+	h := &postgres{pool: mock, logger: loggerPkg.NewFatal()}
+	events, err := h.ListAt(context.Background(), at, true, "petr", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{ID: 7, UserID: user.ID, Name: user.Name, Action: ActionUpdate, State: user,
+		Actor: "meta", CreatedAt: at}}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgres_Impersonations(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	since := time.Unix(1660000000, 0)
+	first, last := since.Add(time.Minute), since.Add(time.Hour)
+	mock.ExpectQuery("SELECT real_actor, actor, min(created_at), max(created_at), count(*) FROM users_history "+
+		"WHERE real_actor <> $1 AND created_at >= $2 GROUP BY real_actor, actor ORDER BY max(created_at) DESC LIMIT 10").
+		WithArgs("", since).
+		WillReturnRows(pgxmock.NewRows([]string{"real_actor", "actor", "min", "max", "count"}).
+			AddRow("support", "alice", first, last, int64(3)))
+
+	h := &postgres{pool: mock, logger: loggerPkg.NewFatal()}
+	sessions, err := h.Impersonations(context.Background(), since, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Session{{RealActor: "support", Actor: "alice", FirstAt: first, LastAt: last, Changes: 3}}, sessions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgres_Prune(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	for i := 1; i <= partitionsAhead; i++ {
+		from := now.AddDate(0, 0, i)
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS " + partitionName(from) + " PARTITION OF users_history " +
+			"FOR VALUES FROM ('" + from.Format(time.RFC3339) + "') TO ('" + from.AddDate(0, 0, 1).Format(time.RFC3339) + "')").
+			WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	}
+
+	before := now.AddDate(0, 0, -2).Add(time.Hour)
+	expired := partitionName(now.AddDate(0, 0, -3))
+	mock.ExpectQuery("SELECT c.relname FROM pg_inherits i " +
+		"JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class t ON t.oid = i.inhparent WHERE t.relname = $1").
+		WithArgs(historyTable).
+		WillReturnRows(pgxmock.NewRows([]string{"relname"}).
+			AddRow("users_history_default").
+			AddRow(expired).
+			AddRow(partitionName(now.AddDate(0, 0, -2))).
+			AddRow(partitionName(now)))
+	mock.ExpectQuery(`SELECT count(*) FROM "` + expired + `"`).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(5)))
+	mock.ExpectExec(`DROP TABLE "` + expired + `"`).
+		WillReturnResult(pgxmock.NewResult("DROP", 0))
+	mock.ExpectExec("DELETE FROM users_history WHERE created_at < $1").
+		WithArgs(before).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec("DELETE FROM users_history WHERE id <= " +
+		"(SELECT id FROM users_history ORDER BY id DESC OFFSET $1 LIMIT 1)").
+		WithArgs(int64(100)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	h := &postgres{pool: mock, logger: loggerPkg.NewFatal()}
+	pruned, err := h.Prune(context.Background(), before, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
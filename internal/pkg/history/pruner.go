@@ -0,0 +1,66 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultPruneInterval = time.Hour
+
+// Pruner removes events out of the retention, so the history doesn't grow unbounded.
+type Pruner struct {
+	history   Interface
+	retention Retention
+	clock     clock.Clock
+	logger    *zap.SugaredLogger
+}
+
+func NewPruner(history Interface, retention Retention, clk clock.Clock, logger *zap.SugaredLogger) *Pruner {
+	if retention.Interval <= 0 {
+		retention.Interval = defaultPruneInterval
+	}
+	return &Pruner{
+		history:   history,
+		retention: retention,
+		clock:     clk,
+		logger:    logger,
+	}
+}
+
+// Run prunes the history every interval until ctx is done. It must be run on the leader only.
+func (p *Pruner) Run(ctx context.Context) {
+	p.logger.Infow("Start history pruning", "max_age", p.retention.MaxAge, "max_rows", p.retention.MaxRows,
+		"interval", p.retention.Interval)
+	ticker := p.clock.NewTicker(p.retention.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Prune(ctx, p.clock.Now()); err != nil && ctx.Err() == nil {
+			p.logger.Errorf("history prune: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			p.logger.Infoln("History pruning stopped")
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Prune removes events out of the retention at the time, pruned events are counted even on failure.
+func (p *Pruner) Prune(ctx context.Context, now time.Time) (int64, error) {
+	var before time.Time
+	if p.retention.MaxAge > 0 {
+		before = now.Add(-p.retention.MaxAge)
+	}
+	pruned, err := p.history.Prune(ctx, before, p.retention.MaxRows)
+	counter.HistoryPruned.Add(uint64(pruned))
+	if pruned != 0 {
+		p.logger.Infow("History pruned", "events", pruned)
+	}
+	return pruned, err
+}
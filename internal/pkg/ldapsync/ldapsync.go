@@ -0,0 +1,328 @@
+package ldapsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	ldapPkg "gitlab.ozon.dev/iTukaev/homework/pkg/ldap"
+)
+
+const (
+	// DNAttribute links the user to its directory entry, users without it are never changed by sync.
+	DNAttribute = models.DNAttribute
+
+	defaultInterval = time.Hour
+	dialTimeout     = 10 * time.Second
+	pageSize        = 1000
+	passwordSize    = 32
+)
+
+// Config of the directory sync. Filter is RFC 4515 search filter, e.g. "(&(objectClass=person)(!(nsAccountLock=true)))".
+type Config struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Addr         string        `mapstructure:"addr"`
+	UseTLS       bool          `mapstructure:"use_tls"`
+	BindDN       string        `mapstructure:"bind_dn"`
+	BindPassword string        `mapstructure:"bind_password"`
+	BaseDN       string        `mapstructure:"base_dn"`
+	Filter       string        `mapstructure:"filter"`
+	Interval     time.Duration `mapstructure:"interval"`
+	// DryRun computes and reports the diff without changing users.
+	DryRun            bool   `mapstructure:"dry_run"`
+	NameAttribute     string `mapstructure:"name_attribute"`
+	EmailAttribute    string `mapstructure:"email_attribute"`
+	FullNameAttribute string `mapstructure:"full_name_attribute"`
+}
+
+// Directory returns entries, which must exist as users.
+type Directory interface {
+	Users(ctx context.Context) ([]ldapPkg.Entry, error)
+}
+
+// Report is a diff summary of the sync, lists contain user names.
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	DryRun    bool          `json:"dry_run"`
+	Directory int           `json:"directory"`
+	Created   []string      `json:"created,omitempty"`
+	Updated   []string      `json:"updated,omitempty"`
+	Disabled  []string      `json:"disabled,omitempty"`
+	Enabled   []string      `json:"enabled,omitempty"`
+	// Conflicts are directory users, whose names are taken by local users.
+	Conflicts []string `json:"conflicts,omitempty"`
+	Failed    []string `json:"failed,omitempty"`
+	Unchanged int      `json:"unchanged"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type Syncer struct {
+	cfg        Config
+	directory  Directory
+	user       userPkg.Interface
+	normalizer normalizePkg.Interface
+	logger     *zap.SugaredLogger
+
+	mu   sync.Mutex
+	last Report
+}
+
+// New returns syncer of the configured LDAP directory.
+func New(cfg Config, user userPkg.Interface, normalizer normalizePkg.Interface, logger *zap.SugaredLogger) *Syncer {
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = "uid"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.FullNameAttribute == "" {
+		cfg.FullNameAttribute = "cn"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	return &Syncer{
+		cfg:        cfg,
+		directory:  &directory{cfg: cfg},
+		user:       user,
+		normalizer: normalizer,
+		logger:     logger,
+	}
+}
+
+// Run syncs users every interval until ctx is done. It must be run on the leader only.
+func (s *Syncer) Run(ctx context.Context) {
+	s.logger.Infow("Start LDAP sync", "interval", s.cfg.Interval, "dry_run", s.cfg.DryRun)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("LDAP sync: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			s.logger.Infoln("LDAP sync stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Last returns report of the last sync.
+func (s *Syncer) Last() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+type entry struct {
+	dn       string
+	email    string
+	fullName string
+}
+
+// Sync reconciles users with the directory: missing users are created, removed ones are
+// disabled, changed ones are updated and returned ones are enabled again.
+func (s *Syncer) Sync(ctx context.Context) (report Report, err error) {
+	report = Report{StartedAt: time.Now(), DryRun: s.cfg.DryRun}
+	defer func() {
+		report.Duration = time.Since(report.StartedAt)
+		if err != nil {
+			report.Error = err.Error()
+		}
+		s.mu.Lock()
+		s.last = report
+		s.mu.Unlock()
+	}()
+
+	// the link to the directory and the status of directory users are written by the sync only
+	ctx = userPkg.WithDirectory(ctx)
+
+	entries, err := s.directory.Users(ctx)
+	if err != nil {
+		return report, errors.Wrap(err, "directory users")
+	}
+	desired := make(map[string]entry, len(entries))
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name, err := s.normalizer.Name(e.Get(s.cfg.NameAttribute))
+		if err != nil || name == "" {
+			s.logger.Warnw("LDAP sync: entry skipped", "dn", e.DN, "name", name)
+			continue
+		}
+		if _, ok := desired[name]; !ok {
+			names = append(names, name)
+		}
+		desired[name] = entry{
+			dn:       e.DN,
+			email:    e.Get(s.cfg.EmailAttribute),
+			fullName: e.Get(s.cfg.FullNameAttribute),
+		}
+	}
+	report.Directory = len(desired)
+
+	sort.Strings(names)
+
+	all, existing, err := s.users(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, name := range names {
+		e := desired[name]
+		user, ok := existing[name]
+		switch {
+		case !ok:
+			s.apply(ctx, &report, &report.Created, name, func() error {
+				return s.create(ctx, name, e)
+			})
+		case user.Attributes[DNAttribute] == "":
+			report.Conflicts = append(report.Conflicts, name)
+		case user.Status == models.StatusDisabled:
+			s.apply(ctx, &report, &report.Enabled, name, func() error {
+				if err := s.user.Enable(ctx, name); err != nil {
+					return err
+				}
+				return s.update(ctx, user, e)
+			})
+		case changed(user, e):
+			s.apply(ctx, &report, &report.Updated, name, func() error {
+				return s.update(ctx, user, e)
+			})
+		default:
+			report.Unchanged++
+		}
+	}
+	for _, user := range all {
+		if _, ok := desired[user.Name]; ok || user.Attributes[DNAttribute] == "" || user.Status == models.StatusDisabled {
+			continue
+		}
+		name := user.Name
+		s.apply(ctx, &report, &report.Disabled, name, func() error {
+			return s.user.Disable(ctx, name)
+		})
+	}
+
+	s.logger.Infow("LDAP sync finished",
+		"dry_run", report.DryRun,
+		"directory", report.Directory,
+		"created", report.Created,
+		"updated", report.Updated,
+		"disabled", report.Disabled,
+		"enabled", report.Enabled,
+		"conflicts", report.Conflicts,
+		"failed", report.Failed,
+		"unchanged", report.Unchanged,
+	)
+	return report, nil
+}
+
+// apply runs the change unless it is a dry run, the name is added to the list on success.
+func (s *Syncer) apply(ctx context.Context, report *Report, list *[]string, name string, change func() error) {
+	if !s.cfg.DryRun {
+		if err := change(); err != nil {
+			if ctx.Err() == nil {
+				s.logger.Errorw("LDAP sync: apply", "name", name, "error", err.Error())
+			}
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+	}
+	*list = append(*list, name)
+}
+
+func (s *Syncer) create(ctx context.Context, name string, e entry) error {
+	// password is unknown to anybody, directory users log in via the directory only
+	password, err := passwordPkg.Generate(passwordSize)
+	if err != nil {
+		return err
+	}
+	fullName := e.fullName
+	if fullName == "" {
+		fullName = name
+	}
+	return s.user.Create(ctx, models.User{
+		Name:       name,
+		Password:   password,
+		Email:      e.email,
+		FullName:   fullName,
+		CreatedAt:  time.Now().Unix(),
+		Attributes: map[string]string{DNAttribute: e.dn},
+	})
+}
+
+// changed reports whether the user differs from its directory entry.
+func changed(user models.User, e entry) bool {
+	return user.Email != e.email || user.FullName != e.fullName || user.Attributes[DNAttribute] != e.dn
+}
+
+// update copies fields of the entry to the user, if they are changed.
+func (s *Syncer) update(ctx context.Context, user models.User, e entry) error {
+	if !changed(user, e) {
+		return nil
+	}
+	return s.user.Update(ctx, models.UserUpdate{
+		User: models.User{
+			Name:       user.Name,
+			Email:      e.email,
+			FullName:   e.fullName,
+			Attributes: map[string]string{DNAttribute: e.dn},
+		},
+		Mask: []string{
+			models.MaskEmail,
+			models.MaskFullName,
+			"attributes." + DNAttribute,
+		},
+	})
+}
+
+// users returns all users ordered by name and indexed by name.
+func (s *Syncer) users(ctx context.Context) ([]models.User, map[string]models.User, error) {
+	var all []models.User
+	users := make(map[string]models.User)
+	ctx = repoPkg.WithoutScanBudget(ctx)
+	for page := uint64(0); ; page++ {
+		list, err := s.user.List(ctx, false, pageSize, page, nil, "")
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "user list")
+		}
+		all = append(all, list...)
+		for _, user := range list {
+			users[user.Name] = user
+		}
+		if len(list) < pageSize {
+			return all, users, nil
+		}
+	}
+}
+
+type directory struct {
+	cfg Config
+}
+
+func (d *directory) Users(ctx context.Context) ([]ldapPkg.Entry, error) {
+	conn, err := ldapPkg.Dial(ctx, d.cfg.Addr, d.cfg.UseTLS, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if d.cfg.BindDN != "" {
+		if err = conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+	return conn.Search(d.cfg.BaseDN, d.cfg.Filter,
+		[]string{d.cfg.NameAttribute, d.cfg.EmailAttribute, d.cfg.FullNameAttribute})
+}
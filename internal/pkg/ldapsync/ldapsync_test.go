@@ -0,0 +1,115 @@
+package ldapsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	ldapPkg "gitlab.ozon.dev/iTukaev/homework/pkg/ldap"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+type directoryFunc func(ctx context.Context) ([]ldapPkg.Entry, error)
+
+func (f directoryFunc) Users(ctx context.Context) ([]ldapPkg.Entry, error) {
+	return f(ctx)
+}
+
+func person(name, email string) ldapPkg.Entry {
+	return ldapPkg.Entry{
+		DN: "uid=" + name + ",ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"uid":  {name},
+			"mail": {email},
+			"cn":   {name},
+		},
+	}
+}
+
+func linked(name, email string, disabled bool) models.User {
+	user := models.User{
+		Name:       name,
+		Email:      email,
+		FullName:   name,
+		Status:     models.StatusActive,
+		Attributes: map[string]string{DNAttribute: person(name, email).DN},
+	}
+	if disabled {
+		user.Status = models.StatusDisabled
+	}
+	return user
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	directory := directoryFunc(func(context.Context) ([]ldapPkg.Entry, error) {
+		return []ldapPkg.Entry{
+			person("ivan", "ivan@example.com"),
+			person("petr", "petr@new.example.com"),
+			person("boris", "boris@example.com"),
+			person("anna", "anna@example.com"),
+			person("new", "new@example.com"),
+			{DN: "cn=service,dc=example,dc=com"},
+		}, nil
+	})
+	existing := []models.User{
+		linked("anna", "anna@example.com", true),
+		{Name: "boris", Email: "boris@example.com"},
+		linked("ivan", "ivan@example.com", false),
+		linked("olga", "olga@example.com", false),
+		linked("petr", "petr@example.com", false),
+	}
+	expReport := Report{
+		Directory: 5,
+		Created:   []string{"new"},
+		Updated:   []string{"petr"},
+		Disabled:  []string{"olga"},
+		Enabled:   []string{"anna"},
+		Conflicts: []string{"boris"},
+		Unchanged: 1,
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		mockUser := userMockPkg.NewMockInterface(ctl)
+		s := New(Config{DryRun: dryRun}, mockUser, normalizePkg.New(normalizePkg.Policy{}), loggerPkg.NewFatal())
+		s.directory = directory
+
+		mockUser.EXPECT().List(gomock.Any(), false, uint64(pageSize), uint64(0), nil, "").
+			Return(existing, nil).Times(1)
+		if !dryRun {
+			mockUser.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, user models.User) error {
+					assert.Equal(t, "new", user.Name)
+					assert.Equal(t, person("new", "").DN, user.Attributes[DNAttribute])
+					assert.NotEmpty(t, user.Password)
+					return nil
+				}).Times(1)
+			updates := make(map[string]models.UserUpdate)
+			mockUser.EXPECT().Update(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, update models.UserUpdate) error {
+					updates[update.Name] = update
+					return nil
+				}).Times(1)
+			defer func() {
+				assert.Equal(t, "petr@new.example.com", updates["petr"].Email)
+			}()
+			mockUser.EXPECT().Disable(gomock.Any(), "olga").Return(nil).Times(1)
+			mockUser.EXPECT().Enable(gomock.Any(), "anna").Return(nil).Times(1)
+		}
+
+		report, err := s.Sync(context.Background())
+		require.NoError(t, err)
+		expReport.DryRun = dryRun
+		report.StartedAt, report.Duration = expReport.StartedAt, expReport.Duration
+		assert.Equal(t, expReport, report)
+		assert.Equal(t, report.Created, s.Last().Created)
+	}
+}
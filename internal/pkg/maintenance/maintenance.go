@@ -0,0 +1,172 @@
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// HealthService is a name of the health check service, which is not serving in maintenance mode.
+const HealthService = "maintenance"
+
+// Config of the maintenance mode.
+type Config struct {
+	// RefreshInterval is an interval of reading the mode changed by other replicas.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// State of the maintenance mode.
+type State struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	// Actor is a request meta of the last change.
+	Actor string `json:"actor,omitempty"`
+	// Since is unix time of the last change.
+	Since int64 `json:"since,omitempty"`
+}
+
+// Store persists the state, so the mode survives restarts.
+type Store interface {
+	Get(ctx context.Context) (State, error)
+	Set(ctx context.Context, state State) error
+}
+
+// Mode keeps the state of the store in memory, so mutations are checked without store calls.
+type Mode struct {
+	store  Store
+	logger *zap.SugaredLogger
+
+	mu       sync.RWMutex
+	state    State
+	watchers []func(State)
+}
+
+func New(store Store, logger *zap.SugaredLogger) *Mode {
+	return &Mode{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Watch calls fn with the current state and on every change of the mode.
+func (m *Mode) Watch(fn func(State)) {
+	m.mu.Lock()
+	m.watchers = append(m.watchers, fn)
+	state := m.state
+	m.mu.Unlock()
+	fn(state)
+}
+
+// State returns the current state.
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Check returns ErrMaintenance with the reason, if the mode is enabled.
+func (m *Mode) Check() error {
+	state := m.State()
+	if !state.Enabled {
+		return nil
+	}
+	return errors.Wrapf(errorsPkg.ErrMaintenance, "reason [%s]", state.Reason)
+}
+
+// Set persists the state and applies it. The reason is required to enable the mode.
+func (m *Mode) Set(ctx context.Context, enabled bool, reason, actor string) (State, error) {
+	if enabled && reason == "" {
+		return State{}, errors.Wrap(errorsPkg.ErrValidation, "field: [reason] cannot be empty")
+	}
+	if !enabled {
+		reason = ""
+	}
+	state := State{
+		Enabled: enabled,
+		Reason:  reason,
+		Actor:   actor,
+		Since:   time.Now().Unix(),
+	}
+	if err := m.store.Set(ctx, state); err != nil {
+		return State{}, errors.Wrap(err, "maintenance set")
+	}
+	m.apply(state)
+	return state, nil
+}
+
+// Refresh reads the state from the store.
+func (m *Mode) Refresh(ctx context.Context) error {
+	state, err := m.store.Get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "maintenance refresh")
+	}
+	m.apply(state)
+	return nil
+}
+
+// Run refreshes the state until ctx is done.
+func (m *Mode) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Refresh(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Errorln(err)
+			}
+		}
+	}
+}
+
+func (m *Mode) apply(state State) {
+	m.mu.Lock()
+	changed := state != m.state
+	m.state = state
+	watchers := m.watchers
+	m.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if state.Enabled {
+		m.logger.Warnw("Maintenance mode enabled", "reason", state.Reason, "actor", state.Actor)
+	} else {
+		m.logger.Infow("Maintenance mode disabled", "actor", state.Actor)
+	}
+	for _, fn := range watchers {
+		fn(state)
+	}
+}
+
+// NewMemory returns the store, which keeps the state until the process is stopped.
+func NewMemory() Store {
+	return &memory{}
+}
+
+type memory struct {
+	mu    sync.Mutex
+	state State
+}
+
+func (m *memory) Get(context.Context) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state, nil
+}
+
+func (m *memory) Set(_ context.Context, state State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = state
+	return nil
+}
@@ -0,0 +1,106 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestMode_Set(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	mode := New(store, loggerPkg.NewFatal())
+	var watched []State
+	mode.Watch(func(state State) {
+		watched = append(watched, state)
+	})
+
+	_, err := mode.Set(ctx, true, "", "meta")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+	assert.NoError(t, mode.Check())
+
+	state, err := mode.Set(ctx, true, "upgrade", "meta")
+	require.NoError(t, err)
+	assert.ErrorIs(t, mode.Check(), errorsPkg.ErrMaintenance)
+	stored, err := store.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, state, stored)
+
+	// the state persisted by another replica is applied on refresh
+	restarted := New(store, loggerPkg.NewFatal())
+	require.NoError(t, restarted.Refresh(ctx))
+	assert.Equal(t, state, restarted.State())
+
+	state, err = mode.Set(ctx, false, "upgrade", "meta")
+	require.NoError(t, err)
+	assert.Empty(t, state.Reason)
+	assert.NoError(t, mode.Check())
+
+	require.Len(t, watched, 3)
+	assert.False(t, watched[0].Enabled)
+	assert.True(t, watched[1].Enabled)
+	assert.False(t, watched[2].Enabled)
+}
+
+func TestPostgres_Get(t *testing.T) {
+	cases := []struct {
+		name string
+		rows *pgxmock.Rows
+		err  error
+		exp  State
+	}{
+		{
+			name: "success, stored state",
+			rows: pgxmock.NewRows([]string{enabledField, reasonField, actorField, sinceField}).
+				AddRow(true, "upgrade", "meta", int64(1660000000)),
+			exp: State{Enabled: true, Reason: "upgrade", Actor: "meta", Since: 1660000000},
+		},
+		{
+			name: "success, never set",
+			err:  pgx.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mock.Close()
+
+			query := mock.ExpectQuery("SELECT enabled, reason, actor, since FROM maintenance WHERE id = $1").WithArgs(stateID)
+			if c.err != nil {
+				query.WillReturnError(c.err)
+			} else {
+				query.WillReturnRows(c.rows)
+			}
+
+			state, err := NewPostgres(mock).Get(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, state)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgres_Set(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	state := State{Enabled: true, Reason: "upgrade", Actor: "meta", Since: 1660000000}
+	mock.ExpectExec("INSERT INTO maintenance (id,enabled,reason,actor,since) VALUES ($1,$2,$3,$4,$5) "+
+		"ON CONFLICT (id) DO UPDATE SET enabled = EXCLUDED.enabled, reason = EXCLUDED.reason, "+
+		"actor = EXCLUDED.actor, since = EXCLUDED.since").
+		WithArgs(stateID, state.Enabled, state.Reason, state.Actor, state.Since).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	assert.NoError(t, NewPostgres(mock).Set(context.Background(), state))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,69 @@
+package maintenance
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	maintenanceTable = "maintenance"
+	// stateID is a key of the only row of the table
+	stateID = 1
+
+	idField      = "id"
+	enabledField = "enabled"
+	reasonField  = "reason"
+	actorField   = "actor"
+	sinceField   = "since"
+)
+
+// NewPostgres returns the store, which keeps the state in the maintenance table.
+func NewPostgres(pool pgxtype.Querier) Store {
+	return &postgres{pool: pool}
+}
+
+type postgres struct {
+	pool pgxtype.Querier
+}
+
+func (p *postgres) Get(ctx context.Context) (State, error) {
+	query, args, err := squirrel.Select(enabledField, reasonField, actorField, sinceField).
+		From(maintenanceTable).
+		Where(squirrel.Eq{idField: stateID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return State{}, errors.Wrap(err, "maintenance get")
+	}
+
+	var state State
+	err = p.pool.QueryRow(ctx, query, args...).Scan(&state.Enabled, &state.Reason, &state.Actor, &state.Since)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, errors.Wrap(err, "maintenance get")
+	}
+	return state, nil
+}
+
+func (p *postgres) Set(ctx context.Context, state State) error {
+	query, args, err := squirrel.Insert(maintenanceTable).
+		Columns(idField, enabledField, reasonField, actorField, sinceField).
+		Values(stateID, state.Enabled, state.Reason, state.Actor, state.Since).
+		Suffix("ON CONFLICT (id) DO UPDATE SET enabled = EXCLUDED.enabled, reason = EXCLUDED.reason, " +
+			"actor = EXCLUDED.actor, since = EXCLUDED.since").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "maintenance set")
+	}
+	if _, err = p.pool.Exec(ctx, query, args...); err != nil {
+		return errors.Wrap(err, "maintenance set")
+	}
+	return nil
+}
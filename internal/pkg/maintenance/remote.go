@@ -0,0 +1,51 @@
+package maintenance
+
+import (
+	"context"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+// NewRemote returns the store of the data service, so services in front of it
+// reject mutations without sending them.
+func NewRemote(client pb.AdminClient) Store {
+	return &remote{client: client}
+}
+
+type remote struct {
+	client pb.AdminClient
+}
+
+func (r *remote) Get(ctx context.Context) (State, error) {
+	resp, err := r.client.MaintenanceGet(ctx, &pb.MaintenanceGetRequest{})
+	if err != nil {
+		return State{}, err
+	}
+	return FromPb(resp.GetState()), nil
+}
+
+func (r *remote) Set(ctx context.Context, state State) error {
+	_, err := r.client.MaintenanceSet(ctx, &pb.MaintenanceSetRequest{
+		Enabled: state.Enabled,
+		Reason:  state.Reason,
+	})
+	return err
+}
+
+func ToPb(state State) *pb.MaintenanceState {
+	return &pb.MaintenanceState{
+		Enabled: state.Enabled,
+		Reason:  state.Reason,
+		Actor:   state.Actor,
+		Since:   state.Since,
+	}
+}
+
+func FromPb(state *pb.MaintenanceState) State {
+	return State{
+		Enabled: state.GetEnabled(),
+		Reason:  state.GetReason(),
+		Actor:   state.GetActor(),
+		Since:   state.GetSince(),
+	}
+}
@@ -0,0 +1,105 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+// Policy describes which normalization steps are applied to user names.
+type Policy struct {
+	Trim        bool `mapstructure:"trim"`
+	NFC         bool `mapstructure:"nfc"`
+	CaseFold    bool `mapstructure:"case_fold"`
+	Confusables bool `mapstructure:"confusables"`
+}
+
+type Interface interface {
+	// Name returns normalized user name or ErrValidation, if the name is rejected by the policy
+	// or has characters other than letters, digits, "_" and ".".
+	Name(name string) (string, error)
+}
+
+func New(policy Policy) Interface {
+	return &normalizer{
+		policy: policy,
+		folder: cases.Fold(),
+	}
+}
+
+type normalizer struct {
+	policy Policy
+	folder cases.Caser
+}
+
+func (n *normalizer) Name(name string) (string, error) {
+	if n.policy.Trim {
+		name = strings.TrimSpace(name)
+	}
+	if n.policy.NFC {
+		name = norm.NFC.String(name)
+	}
+	if n.policy.CaseFold {
+		name = n.folder.String(name)
+	}
+	if !allowed(name) {
+		return "", errors.Wrapf(errorsPkg.ErrValidation,
+			"field: [name] may contain latin, greek and cyrillic letters, digits, \"_\" and \".\" only: [%s]", name)
+	}
+	if n.policy.Confusables && mixedScripts(name) {
+		return "", errors.Wrapf(errorsPkg.ErrValidation, "field: [name] mixes scripts: [%s]", name)
+	}
+	return name, nil
+}
+
+// letters are blocks of latin, greek and cyrillic letters, the users table checks names by the same blocks.
+var letters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00c0, Hi: 0x024f, Stride: 1},
+		{Lo: 0x0370, Hi: 0x03ff, Stride: 1},
+		{Lo: 0x0400, Hi: 0x052f, Stride: 1},
+		{Lo: 0x1e00, Hi: 0x1fff, Stride: 1},
+	},
+}
+
+// allowed reports whether the name consists of letters, ASCII digits, "_" and ".".
+func allowed(name string) bool {
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+		case unicode.Is(letters, r) && unicode.IsLetter(r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var scripts = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Cyrillic,
+	unicode.Greek,
+}
+
+// mixedScripts reports whether the name contains letters from more than one of
+// the scripts with visually confusable letters, e.g. latin "a" and cyrillic "а".
+func mixedScripts(name string) bool {
+	found := -1
+	for _, r := range name {
+		for i, script := range scripts {
+			if !unicode.Is(script, r) {
+				continue
+			}
+			if found != -1 && found != i {
+				return true
+			}
+			found = i
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+func TestNormalizer_Name(t *testing.T) {
+	full := Policy{
+		Trim:        true,
+		NFC:         true,
+		CaseFold:    true,
+		Confusables: true,
+	}
+
+	cases := []struct {
+		name    string
+		policy  Policy
+		input   string
+		expName string
+		expErr  error
+	}{
+		{
+			name:    "success, empty policy",
+			policy:  Policy{},
+			input:   "Ivan_1.Petrov",
+			expName: "Ivan_1.Petrov",
+		},
+		{
+			name:   "failed, spaces are not trimmed by empty policy",
+			policy: Policy{},
+			input:  " Ivan ",
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, letters of other scripts",
+			policy: full,
+			input:  "李娜",
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, symbols",
+			policy: full,
+			input:  "ivan×petr",
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:    "success, cyrillic name folded and trimmed",
+			policy:  full,
+			input:   "Иван  ",
+			expName: "иван",
+		},
+		{
+			name:    "success, decomposed letters composed",
+			policy:  full,
+			input:   "Jose\u0301",
+			expName: "jos\u00e9",
+		},
+		{
+			name:   "failed, latin and cyrillic mixed",
+			policy: full,
+			input:  "Iv\u0430n",
+			expErr: errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			name, err := New(c.policy).Name(c.input)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expName, name)
+		})
+	}
+}
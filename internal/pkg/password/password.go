@@ -0,0 +1,258 @@
+package password
+
+import (
+	"bufio"
+	"crypto/rand"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+)
+
+// TenantAttribute selects the tenant policy of the user, users without it get the default one.
+const TenantAttribute = models.TenantAttribute
+
+const (
+	breachedFalsePositive = 0.001
+	// maxHashedLength is a number of password bytes used by bcrypt
+	maxHashedLength = 72
+)
+
+// Policy describes password requirements, zero policy accepts any password.
+type Policy struct {
+	MinLength int  `mapstructure:"min_length"`
+	Lower     bool `mapstructure:"lower"`
+	Upper     bool `mapstructure:"upper"`
+	Digit     bool `mapstructure:"digit"`
+	Symbol    bool `mapstructure:"symbol"`
+	// Breached rejects passwords from the breached list.
+	Breached bool `mapstructure:"breached"`
+	// DisallowName rejects passwords containing the user name in any case.
+	DisallowName bool `mapstructure:"disallow_name"`
+	// MaxAge is a lifetime of the password, 0 disables expiration.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+// Config of the policies. BreachedList is a file with a password per line,
+// it is loaded into bloom filter, so rare strong passwords may be rejected as well.
+type Config struct {
+	Default      Policy            `mapstructure:"default"`
+	Tenants      map[string]Policy `mapstructure:"tenants"`
+	BreachedList string            `mapstructure:"breached_list"`
+}
+
+type Interface interface {
+	// Check returns ErrValidation listing all rules of the user tenant policy
+	// violated by the user password.
+	Check(user models.User) error
+	// MaxAge returns password lifetime of the user tenant policy, 0 if passwords never expire.
+	MaxAge(user models.User) time.Duration
+}
+
+// Overrides returns policies of tenants set at runtime, they take precedence over the config.
+type Overrides interface {
+	PasswordPolicy(tenant string) (Policy, bool)
+}
+
+// New returns the policies of the config, overrides may be nil.
+func New(cfg Config, overrides Overrides) (Interface, error) {
+	p := &policies{
+		cfg:       cfg,
+		overrides: overrides,
+	}
+	if cfg.BreachedList != "" {
+		breached, err := load(cfg.BreachedList)
+		if err != nil {
+			return nil, err
+		}
+		p.breached = breached
+	}
+	return p, nil
+}
+
+type policies struct {
+	cfg       Config
+	overrides Overrides
+	breached  *bloom.Filter
+}
+
+func (p *policies) Check(user models.User) error {
+	policy := p.policy(user)
+
+	var violations []string
+	if utf8.RuneCountInString(user.Password) < policy.MinLength {
+		violations = append(violations, "must be at least "+strconv.Itoa(policy.MinLength)+" characters long")
+	}
+	var lower, upper, digit, symbol bool
+	for _, r := range user.Password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.Lower && !lower {
+		violations = append(violations, "must contain a lower case letter")
+	}
+	if policy.Upper && !upper {
+		violations = append(violations, "must contain an upper case letter")
+	}
+	if policy.Digit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if policy.Symbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+	if policy.DisallowName && user.Name != "" &&
+		strings.Contains(strings.ToLower(user.Password), strings.ToLower(user.Name)) {
+		violations = append(violations, "must not contain the user name")
+	}
+	if policy.Breached && p.breached != nil && p.breached.Test(user.Password) {
+		violations = append(violations, "is found in the breached passwords list")
+	}
+
+	if len(violations) != 0 {
+		return errors.Wrapf(errorsPkg.ErrValidation, "field: [password] %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (p *policies) MaxAge(user models.User) time.Duration {
+	return p.policy(user).MaxAge
+}
+
+// policy returns policy of the user tenant.
+func (p *policies) policy(user models.User) Policy {
+	if p.overrides != nil {
+		if policy, ok := p.overrides.PasswordPolicy(user.Attributes[TenantAttribute]); ok {
+			return policy
+		}
+	}
+	if tenant, ok := p.cfg.Tenants[user.Attributes[TenantAttribute]]; ok {
+		return tenant
+	}
+	return p.cfg.Default
+}
+
+// load reads the breached passwords list into bloom filter.
+func load(path string) (*bloom.Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read breached passwords list")
+	}
+	var passwords []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read breached passwords list")
+	}
+
+	filter := bloom.New(uint64(len(passwords)), breachedFalsePositive)
+	for _, password := range passwords {
+		filter.Add(password)
+	}
+	return filter, nil
+}
+
+const (
+	lowers  = "abcdefghijklmnopqrstuvwxyz"
+	uppers  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits  = "0123456789"
+	symbols = "!#$%&*+-=?@^_~"
+)
+
+// Generate returns random password of the size, it has characters of every class,
+// so it passes any policy with smaller minimal length.
+func Generate(size int) (string, error) {
+	classes := []string{lowers, uppers, digits, symbols}
+	if size < len(classes) {
+		size = len(classes)
+	}
+	all := strings.Join(classes, "")
+	password := make([]byte, size)
+	for i := range password {
+		chars := all
+		if i < len(classes) {
+			chars = classes[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", errors.Wrap(err, "random")
+		}
+		password[i] = chars[n.Int64()]
+	}
+	// characters of every class are moved from the beginning to random places
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", errors.Wrap(err, "random")
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// Hash returns bcrypt hash of the password, the hash is stored instead of the password.
+// Passwords longer than bcrypt input are rejected, their tail would be ignored.
+func Hash(password string) (string, error) {
+	if len(password) > maxHashedLength {
+		return "", errors.Wrapf(errorsPkg.ErrValidation, "field: [password] is longer than %d bytes", maxHashedLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.Wrap(errorsPkg.ErrValidation, "field: [password] "+err.Error())
+	}
+	return string(hash), nil
+}
+
+var dummy struct {
+	once sync.Once
+	hash string
+}
+
+// Dummy returns bcrypt hash of a random password at the cost of Hash. Passwords of unknown users
+// are compared with it, so their checks take as long as checks of stored users do.
+func Dummy() string {
+	dummy.once.Do(func() {
+		password, err := Generate(maxHashedLength)
+		if err == nil {
+			dummy.hash, err = Hash(password)
+		}
+		if err != nil {
+			panic(errors.Wrap(err, "dummy password hash"))
+		}
+	})
+	return dummy.hash
+}
+
+// Hashed reports whether the stored password is a bcrypt hash.
+func Hashed(stored string) bool {
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+// Matches reports whether the password matches the bcrypt hash.
+func Matches(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,134 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+func TestPolicies_Check(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(list, []byte("Qwerty123!\npassword\n"), 0o600))
+
+	strict := Policy{MinLength: 10, Lower: true, Upper: true, Digit: true, Symbol: true, Breached: true, DisallowName: true}
+	p, err := New(Config{
+		Default:      Policy{MinLength: 4},
+		Tenants:      map[string]Policy{"staff": strict},
+		BreachedList: list,
+	}, nil)
+	require.NoError(t, err)
+
+	staff := map[string]string{TenantAttribute: "staff"}
+	cases := []struct {
+		name       string
+		user       models.User
+		violations []string
+	}{
+		{
+			name: "success, default policy",
+			user: models.User{Name: "ivan", Password: "ivan"},
+		},
+		{
+			name:       "failed, default policy",
+			user:       models.User{Name: "ivan", Password: "123"},
+			violations: []string{"must be at least 4 characters long"},
+		},
+		{
+			name: "success, tenant policy",
+			user: models.User{Name: "ivan", Password: "Correct-Horse-42", Attributes: staff},
+		},
+		{
+			name: "failed, tenant policy",
+			user: models.User{Name: "ivan", Password: "my_IVAN", Attributes: staff},
+			violations: []string{
+				"must be at least 10 characters long",
+				"must contain a digit",
+				"must not contain the user name",
+			},
+		},
+		{
+			name:       "failed, breached password",
+			user:       models.User{Name: "ivan", Password: "Qwerty123!", Attributes: staff},
+			violations: []string{"is found in the breached passwords list"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := p.Check(c.user)
+			if len(c.violations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+			assert.Contains(t, err.Error(), "field: [password] "+strings.Join(c.violations, "; "))
+		})
+	}
+}
+
+type overrides map[string]Policy
+
+func (o overrides) PasswordPolicy(tenant string) (Policy, bool) {
+	policy, ok := o[tenant]
+	return policy, ok
+}
+
+func TestPolicies_MaxAge(t *testing.T) {
+	p, err := New(Config{
+		Default: Policy{MaxAge: 90 * 24 * time.Hour},
+		Tenants: map[string]Policy{"service": {}, "staff": {}},
+	}, overrides{"staff": {MaxAge: 30 * 24 * time.Hour}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 90*24*time.Hour, p.MaxAge(models.User{}))
+	assert.Equal(t, time.Duration(0), p.MaxAge(models.User{Attributes: map[string]string{TenantAttribute: "service"}}))
+	assert.Equal(t, 30*24*time.Hour, p.MaxAge(models.User{Attributes: map[string]string{TenantAttribute: "staff"}}),
+		"the override takes precedence over the config")
+}
+
+func TestGenerate(t *testing.T) {
+	password, err := Generate(16)
+	require.NoError(t, err)
+	assert.Len(t, password, 16)
+	assert.True(t, strings.IndexFunc(password, unicode.IsLower) >= 0)
+	assert.True(t, strings.IndexFunc(password, unicode.IsUpper) >= 0)
+	assert.True(t, strings.IndexFunc(password, unicode.IsDigit) >= 0)
+	assert.True(t, strings.ContainsAny(password, symbols))
+}
+
+func TestHash(t *testing.T) {
+	hash, err := Hash("Secret#1")
+	require.NoError(t, err)
+	assert.True(t, Hashed(hash))
+	assert.True(t, Matches(hash, "Secret#1"))
+	assert.False(t, Matches(hash, "secret#1"))
+	assert.False(t, Hashed("Secret#1"))
+
+	_, err = Hash(strings.Repeat("a", 73))
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+}
+
+func TestDummy(t *testing.T) {
+	hash, err := Hash("Secret#1")
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+
+	// the dummy hash costs as stored ones do and matches no password
+	dummyCost, err := bcrypt.Cost([]byte(Dummy()))
+	require.NoError(t, err)
+	assert.Equal(t, cost, dummyCost)
+	assert.Equal(t, Dummy(), Dummy())
+	assert.False(t, Matches(Dummy(), ""))
+	assert.False(t, Matches(Dummy(), "Secret#1"))
+}
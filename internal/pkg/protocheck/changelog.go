@@ -0,0 +1,87 @@
+package protocheck
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const unreleased = "Unreleased"
+
+var (
+	versionHeading  = regexp.MustCompile(`^## \[([^\]]+)\]`)
+	breakingHeading = regexp.MustCompile(`^### Breaking\s*$`)
+	listedElement   = regexp.MustCompile("^[-*]\\s+`([^`]+)`")
+)
+
+// Changelog is the part of CHANGELOG.md used by the check.
+type Changelog struct {
+	// Released is the last released version, its descriptors are compared with the current ones.
+	Released string
+	// Breaking are elements listed in the "### Breaking" subsection of the unreleased changes,
+	// their breaking changes are accepted for the next release.
+	Breaking map[string]struct{}
+}
+
+// ParseChangelog reads versions headed by "## [v1.2.0]" and elements listed
+// as "- `full.proto.Name`" under "### Breaking" of the "## [Unreleased]" section.
+func ParseChangelog(r io.Reader) (Changelog, error) {
+	changelog := Changelog{Breaking: make(map[string]struct{})}
+	var section string
+	var breaking bool
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := versionHeading.FindStringSubmatch(line); match != nil {
+			section, breaking = match[1], false
+			if section != unreleased {
+				changelog.Released = section
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "### ") {
+			breaking = section == unreleased && breakingHeading.MatchString(line)
+			continue
+		}
+		if match := listedElement.FindStringSubmatch(line); breaking && match != nil {
+			changelog.Breaking[match[1]] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Changelog{}, errors.Wrap(err, "changelog read")
+	}
+	if changelog.Released == "" {
+		return Changelog{}, errors.New("changelog has no released version")
+	}
+	return changelog, nil
+}
+
+// Unaccepted returns changes of elements, which are not listed as breaking. Changes of nested
+// elements are accepted with the parent one, e.g. fields of the removed message.
+func (c Changelog) Unaccepted(changes []Change) []Change {
+	var unaccepted []Change
+	for _, change := range changes {
+		if !c.accepted(change.Element) {
+			unaccepted = append(unaccepted, change)
+		}
+	}
+	return unaccepted
+}
+
+func (c Changelog) accepted(element string) bool {
+	for {
+		if _, ok := c.Breaking[element]; ok {
+			return true
+		}
+		i := strings.LastIndex(element, ".")
+		if i < 0 {
+			return false
+		}
+		element = element[:i]
+	}
+}
@@ -0,0 +1,191 @@
+package protocheck
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Change is a breaking change of the element, which is a full proto name,
+// e.g. "gitlab.ozon.dev.iTukaev.homework.api.UserGetRequest.name".
+type Change struct {
+	Element string
+	Reason  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Element, c.Reason)
+}
+
+// Compare returns breaking changes of the current descriptors against the released ones.
+// Removed messages, fields, enums, enum values, services and methods are breaking, as well
+// as changed field numbers, names, types and labels, and changed method types and streaming.
+// Added elements are compatible.
+func Compare(released, current *descriptorpb.FileDescriptorSet) []Change {
+	was, is := index(released), index(current)
+	var changes []Change
+
+	for name, old := range was.messages {
+		msg, ok := is.messages[name]
+		if !ok {
+			changes = append(changes, Change{Element: name, Reason: "message removed"})
+			continue
+		}
+		changes = append(changes, compareFields(name, old, msg)...)
+	}
+	for name, old := range was.enums {
+		enum, ok := is.enums[name]
+		if !ok {
+			changes = append(changes, Change{Element: name, Reason: "enum removed"})
+			continue
+		}
+		changes = append(changes, compareValues(name, old, enum)...)
+	}
+	for name, old := range was.services {
+		service, ok := is.services[name]
+		if !ok {
+			changes = append(changes, Change{Element: name, Reason: "service removed"})
+			continue
+		}
+		changes = append(changes, compareMethods(name, old, service)...)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Element != changes[j].Element {
+			return changes[i].Element < changes[j].Element
+		}
+		return changes[i].Reason < changes[j].Reason
+	})
+	return changes
+}
+
+func compareFields(message string, old, current *descriptorpb.DescriptorProto) []Change {
+	fields := make(map[int32]*descriptorpb.FieldDescriptorProto, len(current.GetField()))
+	for _, field := range current.GetField() {
+		fields[field.GetNumber()] = field
+	}
+
+	var changes []Change
+	for _, was := range old.GetField() {
+		element := message + "." + was.GetName()
+		is, ok := fields[was.GetNumber()]
+		if !ok {
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("field %d removed", was.GetNumber())})
+			continue
+		}
+		if is.GetName() != was.GetName() {
+			// names are a part of JSON and gateway contracts
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("field %d renamed to [%s]", was.GetNumber(), is.GetName())})
+		}
+		if fieldType(is) != fieldType(was) {
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("type changed from [%s] to [%s]", fieldType(was), fieldType(is))})
+		}
+		if is.GetLabel() != was.GetLabel() {
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("label changed from [%s] to [%s]", was.GetLabel(), is.GetLabel())})
+		}
+	}
+	return changes
+}
+
+func compareValues(enum string, old, current *descriptorpb.EnumDescriptorProto) []Change {
+	values := make(map[int32]string, len(current.GetValue()))
+	for _, value := range current.GetValue() {
+		values[value.GetNumber()] = value.GetName()
+	}
+
+	var changes []Change
+	for _, was := range old.GetValue() {
+		element := enum + "." + was.GetName()
+		name, ok := values[was.GetNumber()]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("value %d removed", was.GetNumber())})
+		case name != was.GetName():
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("value %d renamed to [%s]", was.GetNumber(), name)})
+		}
+	}
+	return changes
+}
+
+func compareMethods(service string, old, current *descriptorpb.ServiceDescriptorProto) []Change {
+	methods := make(map[string]*descriptorpb.MethodDescriptorProto, len(current.GetMethod()))
+	for _, method := range current.GetMethod() {
+		methods[method.GetName()] = method
+	}
+
+	var changes []Change
+	for _, was := range old.GetMethod() {
+		element := service + "." + was.GetName()
+		is, ok := methods[was.GetName()]
+		if !ok {
+			changes = append(changes, Change{Element: element, Reason: "method removed"})
+			continue
+		}
+		if is.GetInputType() != was.GetInputType() {
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("request changed from [%s] to [%s]", was.GetInputType(), is.GetInputType())})
+		}
+		if is.GetOutputType() != was.GetOutputType() {
+			changes = append(changes, Change{Element: element, Reason: fmt.Sprintf("response changed from [%s] to [%s]", was.GetOutputType(), is.GetOutputType())})
+		}
+		if is.GetClientStreaming() != was.GetClientStreaming() || is.GetServerStreaming() != was.GetServerStreaming() {
+			changes = append(changes, Change{Element: element, Reason: "streaming changed"})
+		}
+	}
+	return changes
+}
+
+// fieldType returns the message or enum name for such fields and the scalar type otherwise.
+func fieldType(field *descriptorpb.FieldDescriptorProto) string {
+	if field.GetTypeName() != "" {
+		return field.GetTypeName()
+	}
+	return field.GetType().String()
+}
+
+// elements are descriptors of the set by full names, nested messages and enums are included.
+type elements struct {
+	messages map[string]*descriptorpb.DescriptorProto
+	enums    map[string]*descriptorpb.EnumDescriptorProto
+	services map[string]*descriptorpb.ServiceDescriptorProto
+}
+
+func index(set *descriptorpb.FileDescriptorSet) elements {
+	e := elements{
+		messages: make(map[string]*descriptorpb.DescriptorProto),
+		enums:    make(map[string]*descriptorpb.EnumDescriptorProto),
+		services: make(map[string]*descriptorpb.ServiceDescriptorProto),
+	}
+	for _, file := range set.GetFile() {
+		prefix := file.GetPackage()
+		for _, msg := range file.GetMessageType() {
+			e.addMessage(prefix, msg)
+		}
+		for _, enum := range file.GetEnumType() {
+			e.enums[join(prefix, enum.GetName())] = enum
+		}
+		for _, service := range file.GetService() {
+			e.services[join(prefix, service.GetName())] = service
+		}
+	}
+	return e
+}
+
+func (e elements) addMessage(prefix string, msg *descriptorpb.DescriptorProto) {
+	name := join(prefix, msg.GetName())
+	e.messages[name] = msg
+	// map entries are nested messages too, so changed key and value types are found
+	for _, nested := range msg.GetNestedType() {
+		e.addMessage(name, nested)
+	}
+	for _, enum := range msg.GetEnumType() {
+		e.enums[join(name, enum.GetName())] = enum
+	}
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
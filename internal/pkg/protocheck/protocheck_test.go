@@ -0,0 +1,178 @@
+package protocheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, t descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   t.Enum(),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+}
+
+func apiSet() *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("api.proto"),
+		Package: proto.String("api"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("GetRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("limit", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Order"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("ASC"), Number: proto.Int32(0)},
+					{Name: proto.String("DESC"), Number: proto.Int32(1)},
+				},
+			}},
+		}, {
+			Name: proto.String("GetResponse"),
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("User"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".api.GetRequest"),
+				OutputType: proto.String(".api.GetResponse"),
+			}},
+		}},
+	}}}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(set *descriptorpb.FileDescriptorSet)
+		exp    []string
+	}{
+		{
+			name:   "compatible, nothing changed",
+			change: func(set *descriptorpb.FileDescriptorSet) {},
+		},
+		{
+			name: "compatible, field and method added",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				msg := set.File[0].MessageType[0]
+				msg.Field = append(msg.Field, field("offset", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT32))
+				service := set.File[0].Service[0]
+				service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+					Name:       proto.String("List"),
+					InputType:  proto.String(".api.GetRequest"),
+					OutputType: proto.String(".api.GetResponse"),
+				})
+			},
+		},
+		{
+			name: "breaking, field removed and type changed",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				msg := set.File[0].MessageType[0]
+				msg.Field = []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				}
+			},
+			exp: []string{
+				"api.GetRequest.limit: field 2 removed",
+				"api.GetRequest.name: type changed from [TYPE_STRING] to [TYPE_BYTES]",
+			},
+		},
+		{
+			name: "breaking, field renamed and repeated",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				f := set.File[0].MessageType[0].Field[0]
+				f.Name = proto.String("names")
+				f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			},
+			exp: []string{
+				"api.GetRequest.name: field 1 renamed to [names]",
+				"api.GetRequest.name: label changed from [LABEL_OPTIONAL] to [LABEL_REPEATED]",
+			},
+		},
+		{
+			name: "breaking, nested enum value removed",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				enum := set.File[0].MessageType[0].EnumType[0]
+				enum.Value = enum.Value[:1]
+			},
+			exp: []string{"api.GetRequest.Order.DESC: value 1 removed"},
+		},
+		{
+			name: "breaking, message removed and method changed",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				set.File[0].MessageType = set.File[0].MessageType[:1]
+				method := set.File[0].Service[0].Method[0]
+				method.OutputType = proto.String(".api.GetRequest")
+				method.ServerStreaming = proto.Bool(true)
+			},
+			exp: []string{
+				"api.GetResponse: message removed",
+				"api.User.Get: response changed from [.api.GetResponse] to [.api.GetRequest]",
+				"api.User.Get: streaming changed",
+			},
+		},
+		{
+			name: "breaking, service removed",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				set.File[0].Service = nil
+			},
+			exp: []string{"api.User: service removed"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			current := apiSet()
+			c.change(current)
+
+			var changes []string
+			for _, change := range Compare(apiSet(), current) {
+				changes = append(changes, change.String())
+			}
+			assert.Equal(t, c.exp, changes)
+		})
+	}
+}
+
+func TestParseChangelog(t *testing.T) {
+	changelog, err := ParseChangelog(strings.NewReader(`# Changelog
+
+## [Unreleased]
+
+### Added
+- ` + "`api.User.List`" + ` method.
+
+### Breaking
+- ` + "`api.GetResponse`" + ` is replaced with the user.
+* ` + "`api.GetRequest.limit`" + `
+
+## [v1.1.0] - 2026-10-01
+
+### Breaking
+- ` + "`api.User`" + `
+
+## [v1.0.0] - 2026-09-01
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", changelog.Released)
+
+	changes := []Change{
+		{Element: "api.GetRequest.limit", Reason: "field 2 removed"},
+		{Element: "api.GetRequest.name", Reason: "field 1 renamed to [names]"},
+		{Element: "api.GetResponse.user", Reason: "field 1 removed"},
+		{Element: "api.User.List", Reason: "method removed"},
+	}
+	assert.Equal(t, []Change{changes[1], changes[3]}, changelog.Unaccepted(changes))
+
+	_, err = ParseChangelog(strings.NewReader("# Changelog\n\n## [Unreleased]\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultSample   = 100
+	// attemptsFactor limits random keys read per sampled user, cache keeps lists and sessions too
+	attemptsFactor = 4
+)
+
+// Config of the cache and repository reconciliation.
+type Config struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	// Sample is a number of cached users compared with the repository per scan.
+	Sample int `mapstructure:"sample"`
+	// Repair removes divergent users from the cache, otherwise they are reported only.
+	Repair bool `mapstructure:"repair"`
+}
+
+// Report of the scan, Diverged contains names of divergent cached users.
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Sampled   int           `json:"sampled"`
+	Diverged  []string      `json:"diverged,omitempty"`
+	Repaired  int           `json:"repaired"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type Scanner struct {
+	cfg    Config
+	cache  *redis.Client
+	codec  codecPkg.Interface
+	data   repoPkg.Interface
+	logger *zap.SugaredLogger
+
+	mu   sync.Mutex
+	last Report
+}
+
+// New returns scanner, which compares random cached users with the repository.
+func New(cfg Config, cache *redis.Client, codec codecPkg.Interface, data repoPkg.Interface, logger *zap.SugaredLogger) *Scanner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Sample <= 0 {
+		cfg.Sample = defaultSample
+	}
+	return &Scanner{
+		cfg:    cfg,
+		cache:  cache,
+		codec:  codec,
+		data:   data,
+		logger: logger,
+	}
+}
+
+// Run scans the cache every interval until ctx is done. It must be run on the leader only.
+func (s *Scanner) Run(ctx context.Context) {
+	s.logger.Infow("Start cache reconciliation", "interval", s.cfg.Interval, "sample", s.cfg.Sample, "repair", s.cfg.Repair)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Infoln("Cache reconciliation stopped")
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("cache reconciliation: %v", err)
+		}
+	}
+}
+
+// Last returns report of the last scan.
+func (s *Scanner) Last() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Scan compares random cached users with the repository. Cached user diverges, if it is
+// missing in the repository or differs from it, while the cache entry is not changed during the check.
+func (s *Scanner) Scan(ctx context.Context) (report Report, err error) {
+	report = Report{StartedAt: time.Now()}
+	defer func() {
+		report.Duration = time.Since(report.StartedAt)
+		if err != nil {
+			report.Error = err.Error()
+		}
+		s.mu.Lock()
+		s.last = report
+		s.mu.Unlock()
+		if len(report.Diverged) != 0 {
+			s.logger.Warnw("cache diverged", "sampled", report.Sampled, "diverged", report.Diverged,
+				"repaired", report.Repaired)
+		}
+	}()
+
+	seen := make(map[string]struct{}, s.cfg.Sample)
+	for attempt := 0; attempt < s.cfg.Sample*attemptsFactor && report.Sampled < s.cfg.Sample; attempt++ {
+		key, err := s.cache.RandomKey(ctx).Result()
+		if errors.Is(err, redis.Nil) {
+			return report, nil
+		}
+		if err != nil {
+			return report, errors.Wrap(err, "random key")
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		sampled, diverged, err := s.check(ctx, key)
+		if err != nil {
+			return report, err
+		}
+		if !sampled {
+			continue
+		}
+		report.Sampled++
+		counter.CacheSampled.Inc()
+		if !diverged {
+			continue
+		}
+		report.Diverged = append(report.Diverged, key)
+		counter.CacheDiverged.Inc()
+		if s.cfg.Repair {
+			if err = s.cache.Del(ctx, key).Err(); err != nil {
+				return report, errors.Wrapf(err, "repair [%s]", key)
+			}
+			report.Repaired++
+		}
+	}
+	return report, nil
+}
+
+// check compares the cached user with the repository, keys of other entries are not sampled.
+func (s *Scanner) check(ctx context.Context, key string) (sampled, diverged bool, err error) {
+	cached, ok, err := s.get(ctx, key)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	user, _, err := s.codec.DecodeUser(cached)
+	if err != nil || user.Name != key || user.ID == "" {
+		return false, false, nil
+	}
+
+	var fields []string
+	stored, err := s.data.UserGet(ctx, key)
+	switch {
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		fields = []string{"missing"}
+	case err != nil:
+		return true, false, errors.Wrapf(err, "repository user [%s]", key)
+	default:
+		// avatar URL is filled on read and is not kept by the repository
+		user.AvatarURL = ""
+		if fields = models.Diff(user, stored); len(fields) == 0 {
+			return true, false, nil
+		}
+	}
+
+	// the entry changed during the check is updated by the regular invalidation
+	again, ok, err := s.get(ctx, key)
+	if err != nil || !ok || !bytes.Equal(cached, again) {
+		return true, false, err
+	}
+	s.logger.Warnw("cached user diverged", "name", key, "fields", fields)
+	return true, true, nil
+}
+
+// get returns the cached value, false is returned for missing keys and values, which are not strings.
+func (s *Scanner) get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.cache.Get(ctx, key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, false, nil
+	case err != nil && isWrongType(err):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, errors.Wrapf(err, "cached [%s]", key)
+	}
+	return data, true, nil
+}
+
+func isWrongType(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "WRONGTYPE")
+}
@@ -0,0 +1,101 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	user := modeltest.Ivan()
+	stale := modeltest.From(user).WithFullName("Ivan Stale").Build()
+	encode := func(user models.User) string {
+		data, err := json.Marshal(user)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	cases := []struct {
+		name      string
+		cached    string
+		recached  string
+		stored    models.User
+		storedErr error
+		repair    bool
+		expReport Report
+	}{
+		{
+			name:      "same",
+			cached:    encode(modeltest.From(user).WithAvatarURL("/v1/user/ivan/avatar").Build()),
+			stored:    user,
+			expReport: Report{Sampled: 1},
+		},
+		{
+			name:      "differs, repaired",
+			cached:    encode(stale),
+			recached:  encode(stale),
+			stored:    user,
+			repair:    true,
+			expReport: Report{Sampled: 1, Diverged: []string{user.Name}, Repaired: 1},
+		},
+		{
+			name:      "missing in repository",
+			cached:    encode(user),
+			recached:  encode(user),
+			storedErr: errorsPkg.ErrUserNotFound,
+			expReport: Report{Sampled: 1, Diverged: []string{user.Name}},
+		},
+		{
+			name:      "changed during check",
+			cached:    encode(stale),
+			recached:  encode(user),
+			stored:    user,
+			repair:    true,
+			expReport: Report{Sampled: 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			data := repoMockPkg.NewMockInterface(ctl)
+
+			// entries of other types are not sampled
+			mock.ExpectRandomKey().SetVal("list_generation")
+			mock.ExpectGet("list_generation").SetVal("3")
+			mock.ExpectRandomKey().SetVal(user.Name)
+			mock.ExpectGet(user.Name).SetVal(c.cached)
+			data.EXPECT().UserGet(gomock.Any(), user.Name).Return(c.stored, c.storedErr).Times(1)
+			if c.recached != "" {
+				mock.ExpectGet(user.Name).SetVal(c.recached)
+			}
+			if c.repair && len(c.expReport.Diverged) != 0 {
+				mock.ExpectDel(user.Name).SetVal(1)
+			}
+
+			s := New(Config{Sample: 1, Repair: c.repair}, client, codecPkg.Default(), data, loggerPkg.NewFatal())
+			report, err := s.Scan(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, c.expReport.Sampled, report.Sampled)
+			assert.Equal(t, c.expReport.Diverged, report.Diverged)
+			assert.Equal(t, c.expReport.Repaired, report.Repaired)
+			assert.Equal(t, report, s.Last())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
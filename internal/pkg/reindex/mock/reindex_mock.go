@@ -0,0 +1,142 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reindex.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	reindex "gitlab.ozon.dev/iTukaev/homework/internal/pkg/reindex"
+)
+
+// MockIndex is a mock of Index interface.
+type MockIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockIndexMockRecorder
+}
+
+// MockIndexMockRecorder is the mock recorder for MockIndex.
+type MockIndexMockRecorder struct {
+	mock *MockIndex
+}
+
+// NewMockIndex creates a new mock instance.
+func NewMockIndex(ctrl *gomock.Controller) *MockIndex {
+	mock := &MockIndex{ctrl: ctrl}
+	mock.recorder = &MockIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIndex) EXPECT() *MockIndexMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockIndex) Add(users []models.User) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Add", users)
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockIndexMockRecorder) Add(users interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockIndex)(nil).Add), users)
+}
+
+// Begin mocks base method.
+func (m *MockIndex) Begin(resume bool) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", resume)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIndexMockRecorder) Begin(resume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIndex)(nil).Begin), resume)
+}
+
+// Commit mocks base method.
+func (m *MockIndex) Commit() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Commit")
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockIndexMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockIndex)(nil).Commit))
+}
+
+// IndexName mocks base method.
+func (m *MockIndex) IndexName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// IndexName indicates an expected call of IndexName.
+func (mr *MockIndexMockRecorder) IndexName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexName", reflect.TypeOf((*MockIndex)(nil).IndexName))
+}
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Start mocks base method.
+func (m *MockInterface) Start(ctx context.Context, names []string, resume bool) (reindex.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, names, resume)
+	ret0, _ := ret[0].(reindex.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockInterfaceMockRecorder) Start(ctx, names, resume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockInterface)(nil).Start), ctx, names, resume)
+}
+
+// Status mocks base method.
+func (m *MockInterface) Status(ctx context.Context) (reindex.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx)
+	ret0, _ := ret[0].(reindex.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockInterfaceMockRecorder) Status(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockInterface)(nil).Status), ctx)
+}
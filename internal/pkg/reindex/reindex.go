@@ -0,0 +1,249 @@
+//go:generate mockgen -source=reindex.go -destination=./mock/reindex_mock.go -package=mock
+
+package reindex
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
+)
+
+const (
+	jobKey   = "reindex_job"
+	pageSize = 1000
+)
+
+const (
+	StatusRunning     = "running"
+	StatusDone        = "done"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+var ErrJobRunning = errors.New("reindex job is already running")
+
+// Index is a secondary index, which can be rebuilt from the primary store.
+type Index interface {
+	IndexName() string
+	// Begin prepares new index. If resume is true and index keeps state of
+	// the previous unfinished build, the state is reused and true is returned.
+	Begin(resume bool) bool
+	Add(users []models.User)
+	// Commit replaces current index with the built one.
+	Commit()
+}
+
+// Job is a state of the rebuild, it is persisted after every page.
+type Job struct {
+	ID        string    `json:"id"`
+	Indexes   []string  `json:"indexes"`
+	Status    string    `json:"status"`
+	Page      uint64    `json:"page"`
+	Processed uint64    `json:"processed"`
+	Resumed   bool      `json:"resumed"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Interface interface {
+	// Start runs rebuild of the named indexes in background, all indexes are rebuilt if names are empty.
+	// With resume the last failed or interrupted job is continued from its checkpoint.
+	Start(ctx context.Context, names []string, resume bool) (Job, error)
+	Status(ctx context.Context) (Job, error)
+}
+
+// New returns job runner, jobs are run by the pool workers.
+func New(data repoPkg.Interface, client *redis.Client, jobs *workerpool.Pool, logger *zap.SugaredLogger, indexes ...Index) Interface {
+	r := &runner{
+		data:    data,
+		cache:   client,
+		jobs:    jobs,
+		logger:  logger,
+		indexes: make(map[string]Index, len(indexes)),
+	}
+	for _, index := range indexes {
+		r.indexes[index.IndexName()] = index
+	}
+	return r
+}
+
+type runner struct {
+	data    repoPkg.Interface
+	cache   *redis.Client
+	jobs    *workerpool.Pool
+	logger  *zap.SugaredLogger
+	indexes map[string]Index
+
+	mu      sync.Mutex
+	running bool
+}
+
+func (r *runner) Start(ctx context.Context, names []string, resume bool) (Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return Job{}, ErrJobRunning
+	}
+
+	if len(names) == 0 {
+		for name := range r.indexes {
+			names = append(names, name)
+		}
+	}
+	indexes := make([]Index, 0, len(names))
+	for _, name := range names {
+		index, ok := r.indexes[name]
+		if !ok {
+			return Job{}, errors.Wrapf(errorsPkg.ErrValidation, "field: [indexes] unknown index: [%s]", name)
+		}
+		indexes = append(indexes, index)
+	}
+
+	now := time.Now()
+	job := Job{
+		ID:        uuid.New().String(),
+		Indexes:   names,
+		Status:    StatusRunning,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if resume {
+		prev, err := r.load(ctx)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return Job{}, err
+		}
+		// running job in storage is interrupted one, since no job is running now
+		if prev.Status == StatusFailed || prev.Status == StatusInterrupted || prev.Status == StatusRunning {
+			job.ID, job.Indexes, job.StartedAt = prev.ID, prev.Indexes, prev.StartedAt
+			job.Page, job.Processed = prev.Page, prev.Processed
+			indexes = indexes[:0]
+			for _, name := range prev.Indexes {
+				if index, ok := r.indexes[name]; ok {
+					indexes = append(indexes, index)
+				}
+			}
+		}
+	}
+
+	// all indexes must keep their partial state to continue from the checkpoint
+	resumed := job.Page != 0
+	for _, index := range indexes {
+		if !index.Begin(resumed) {
+			resumed = false
+		}
+	}
+	if !resumed && job.Page != 0 {
+		for _, index := range indexes {
+			index.Begin(false)
+		}
+		job.Page, job.Processed = 0, 0
+	}
+	job.Resumed = resumed
+
+	if err := r.save(ctx, job); err != nil {
+		return Job{}, err
+	}
+	r.running = true
+	if err := r.jobs.Submit(context.Background(), func(ctx context.Context) error {
+		r.run(ctx, job, indexes)
+		return nil
+	}); err != nil {
+		r.running = false
+		return Job{}, errors.Wrap(err, "submit job")
+	}
+
+	return job, nil
+}
+
+// Status returns the last job, redis.Nil is returned if there were no jobs.
+func (r *runner) Status(ctx context.Context) (Job, error) {
+	job, err := r.load(ctx)
+	if err != nil {
+		return Job{}, err
+	}
+
+	r.mu.Lock()
+	running := r.running
+	r.mu.Unlock()
+	// job was interrupted by the service restart
+	if job.Status == StatusRunning && !running {
+		job.Status = StatusInterrupted
+	}
+	return job, nil
+}
+
+func (r *runner) run(ctx context.Context, job Job, indexes []Index) {
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+	r.logger.Infof("reindex [%s] %v started from page %d", job.ID, job.Indexes, job.Page)
+	ctx = repoPkg.WithoutScanBudget(ctx)
+
+	for {
+		users, err := r.data.UserList(ctx, false, pageSize, job.Page, nil)
+		if err != nil {
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			r.logger.Errorf("reindex [%s]: user list: %v", job.ID, err)
+			break
+		}
+		for _, index := range indexes {
+			index.Add(users)
+		}
+		job.Processed += uint64(len(users))
+
+		if len(users) < pageSize {
+			for _, index := range indexes {
+				index.Commit()
+			}
+			job.Status = StatusDone
+			r.logger.Infof("reindex [%s] finished, %d users processed", job.ID, job.Processed)
+			break
+		}
+
+		job.Page++
+		job.UpdatedAt = time.Now()
+		if err = r.save(ctx, job); err != nil {
+			r.logger.Errorf("reindex [%s]: save checkpoint: %v", job.ID, err)
+		}
+	}
+
+	job.UpdatedAt = time.Now()
+	if err := r.save(ctx, job); err != nil {
+		r.logger.Errorf("reindex [%s]: save job: %v", job.ID, err)
+	}
+}
+
+func (r *runner) load(ctx context.Context) (Job, error) {
+	data, err := r.cache.Get(ctx, jobKey).Bytes()
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	if err = json.Unmarshal(data, &job); err != nil {
+		return Job{}, errors.Wrap(err, "unmarshal job")
+	}
+	return job, nil
+}
+
+func (r *runner) save(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "marshal job")
+	}
+	return errors.Wrap(r.cache.Set(ctx, jobKey, data, 0).Err(), "save job")
+}
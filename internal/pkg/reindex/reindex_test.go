@@ -0,0 +1,72 @@
+package reindex
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+type index struct{}
+
+func (i *index) IndexName() string { return "bloom" }
+func (i *index) Begin(bool) bool   { return false }
+func (i *index) Add([]models.User) {}
+func (i *index) Commit()           {}
+
+func Test_Start(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name    string
+		indexes []string
+		running bool
+		expErr  error
+	}{
+		{
+			name:    "failed, unknown index",
+			indexes: []string{"unknown"},
+			expErr:  errorsPkg.ErrValidation,
+		},
+		{
+			name:    "failed, job is running",
+			indexes: []string{"bloom"},
+			running: true,
+			expErr:  ErrJobRunning,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, _ := redismock.NewClientMock()
+			r := New(repoMockPkg.NewMockInterface(ctl), client, nil, loggerPkg.NewFatal(), &index{}).(*runner)
+			r.running = c.running
+
+			_, err := r.Start(context.Background(), c.indexes, false)
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func Test_Status(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	data, err := json.Marshal(Job{ID: "1", Status: StatusRunning, Page: 3})
+	require.NoError(t, err)
+	mock.ExpectGet(jobKey).SetVal(string(data))
+
+	r := New(nil, client, nil, loggerPkg.NewFatal())
+	job, err := r.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, StatusInterrupted, job.Status)
+	assert.Equal(t, uint64(3), job.Page)
+}
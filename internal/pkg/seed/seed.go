@@ -0,0 +1,172 @@
+package seed
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+const (
+	// RoleAttribute keeps the user role, the admin gets RoleAdmin.
+	RoleAttribute = models.RoleAttribute
+	RoleAdmin     = "admin"
+)
+
+// Config of the seeding. Users of the file and the admin are created,
+// if the store has no users yet. Attribute keys are read in lower case.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// File is a YAML file with the users list, see User for fields.
+	File string `mapstructure:"file"`
+	// Admin is seeded with the admin role, if the name is set.
+	Admin User `mapstructure:"admin"`
+}
+
+// User to seed. Password is hashed before it is stored, PasswordHash is a bcrypt hash
+// stored as is, so plain passwords are not kept in the file.
+type User struct {
+	Name         string            `mapstructure:"name"`
+	Password     string            `mapstructure:"password"`
+	PasswordHash string            `mapstructure:"password_hash"`
+	Email        string            `mapstructure:"email"`
+	FullName     string            `mapstructure:"full_name"`
+	Attributes   map[string]string `mapstructure:"attributes"`
+}
+
+// Report of the seeding, lists contain user names.
+type Report struct {
+	// Skipped is true, if the store is not empty.
+	Skipped bool     `json:"skipped"`
+	Created []string `json:"created,omitempty"`
+	// Existing users are kept as they are.
+	Existing []string `json:"existing,omitempty"`
+}
+
+type Seeder struct {
+	cfg        Config
+	user       userPkg.Interface
+	normalizer normalizePkg.Interface
+	logger     *zap.SugaredLogger
+}
+
+func New(cfg Config, user userPkg.Interface, normalizer normalizePkg.Interface, logger *zap.SugaredLogger) *Seeder {
+	return &Seeder{
+		cfg:        cfg,
+		user:       user,
+		normalizer: normalizer,
+		logger:     logger,
+	}
+}
+
+// Run seeds users, if the store is empty. Seeded users are written as restored ones,
+// so existing users are never changed and re-runs are safe.
+func (s *Seeder) Run(ctx context.Context) (Report, error) {
+	var report Report
+	users, err := s.users()
+	if err != nil || len(users) == 0 {
+		return report, err
+	}
+
+	stored, err := s.user.List(ctx, false, 1, 0, nil, "")
+	if err != nil {
+		return report, errors.Wrap(err, "seed: store check")
+	}
+	if len(stored) != 0 {
+		report.Skipped = true
+		s.logger.Infoln("Seeding skipped, store is not empty")
+		return report, nil
+	}
+
+	now := time.Now().Unix()
+	for _, seeded := range users {
+		user, err := s.build(seeded, now)
+		if err != nil {
+			return report, errors.Wrapf(err, "seed: user [%s]", seeded.Name)
+		}
+		status, err := s.user.Restore(ctx, user, false)
+		if err != nil {
+			return report, errors.Wrapf(err, "seed: user [%s]", user.Name)
+		}
+		if status == models.ImportSkipped {
+			report.Existing = append(report.Existing, user.Name)
+			continue
+		}
+		report.Created = append(report.Created, user.Name)
+	}
+	s.logger.Infow("Users seeded", "created", report.Created, "existing", report.Existing)
+	return report, nil
+}
+
+// users returns the admin and users of the file.
+func (s *Seeder) users() ([]User, error) {
+	var users []User
+	if s.cfg.Admin.Name != "" {
+		admin := s.cfg.Admin
+		attributes := make(map[string]string, len(admin.Attributes)+1)
+		for key, value := range admin.Attributes {
+			attributes[key] = value
+		}
+		attributes[RoleAttribute] = RoleAdmin
+		admin.Attributes = attributes
+		users = append(users, admin)
+	}
+	if s.cfg.File == "" {
+		return users, nil
+	}
+
+	file := viper.New()
+	file.SetConfigFile(s.cfg.File)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "seed: read file")
+	}
+	var listed []User
+	if err := file.UnmarshalKey("users", &listed); err != nil {
+		return nil, errors.Wrap(err, "seed: unmarshal file")
+	}
+	return append(users, listed...), nil
+}
+
+func (s *Seeder) build(seeded User, now int64) (models.User, error) {
+	name, err := s.normalizer.Name(seeded.Name)
+	if err != nil {
+		return models.User{}, err
+	}
+	if name == "" {
+		return models.User{}, errors.Wrap(errorsPkg.ErrValidation, "field: [name] cannot be empty")
+	}
+
+	password := seeded.PasswordHash
+	switch {
+	case password != "" && !passwordPkg.Hashed(password):
+		return models.User{}, errors.Wrap(errorsPkg.ErrValidation, "field: [password_hash] is not a bcrypt hash")
+	case password == "" && seeded.Password == "":
+		return models.User{}, errors.Wrap(errorsPkg.ErrValidation, "field: [password] cannot be empty")
+	case password == "":
+		if password, err = passwordPkg.Hash(seeded.Password); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return models.User{
+		ID:                uuid.New().String(),
+		Name:              name,
+		Password:          password,
+		Email:             seeded.Email,
+		FullName:          seeded.FullName,
+		CreatedAt:         now,
+		Status:            models.StatusActive,
+		PasswordChangedAt: now,
+		Attributes:        seeded.Attributes,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package seed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	normalizePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/normalize"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const seedFile = `users:
+  - name: " Ivan "
+    password: Secret#1
+    email: ivan@example.com
+    attributes:
+      team: core
+  - name: petr
+    password_hash: $2a$04$uuT55flt1JqXCXrLYQHgDOVUhK03mZrfileDISlgB5AqdRKTlljc.
+`
+
+func TestSeeder_Run(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(seedFile), 0o600))
+	normalizer := normalizePkg.New(normalizePkg.Policy{Trim: true, CaseFold: true})
+	admin := User{Name: "root", Password: "Admin#123"}
+
+	cases := []struct {
+		name       string
+		cfg        Config
+		stored     []models.User
+		restored   map[string]models.ImportStatus
+		restoreErr error
+		exp        Report
+		expErr     error
+	}{
+		{
+			name: "success, admin and file users seeded",
+			cfg:  Config{File: path, Admin: admin},
+			restored: map[string]models.ImportStatus{
+				"root": models.ImportCreated,
+				"ivan": models.ImportCreated,
+				"petr": models.ImportSkipped,
+			},
+			exp: Report{Created: []string{"root", "ivan"}, Existing: []string{"petr"}},
+		},
+		{
+			name:   "success, store is not empty",
+			cfg:    Config{File: path, Admin: admin},
+			stored: []models.User{{Name: "ivan"}},
+			exp:    Report{Skipped: true},
+		},
+		{
+			name: "success, nothing to seed",
+		},
+		{
+			name:   "failed, not a hash",
+			cfg:    Config{Admin: User{Name: "root", PasswordHash: "Admin#123"}},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, no password",
+			cfg:    Config{Admin: User{Name: "root"}},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:       "failed, Restore unexpected error",
+			cfg:        Config{Admin: admin},
+			restored:   map[string]models.ImportStatus{"root": models.ImportFailed},
+			restoreErr: errorsPkg.ErrUnexpected,
+			expErr:     errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockUser := userMockPkg.NewMockInterface(ctl)
+			seeder := New(c.cfg, mockUser, normalizer, loggerPkg.NewFatal())
+
+			if c.cfg.File != "" || c.cfg.Admin.Name != "" {
+				mockUser.EXPECT().List(gomock.Any(), false, uint64(1), uint64(0), gomock.Any(), "").
+					Return(c.stored, nil).Times(1)
+			}
+			mockUser.EXPECT().Restore(gomock.Any(), gomock.Any(), false).
+				DoAndReturn(func(_ context.Context, user models.User, _ bool) (models.ImportStatus, error) {
+					assert.NotEmpty(t, user.ID)
+					assert.Equal(t, models.StatusActive, user.Status)
+					assert.True(t, passwordPkg.Hashed(user.Password))
+					switch user.Name {
+					case "root":
+						assert.True(t, passwordPkg.Matches(user.Password, admin.Password))
+						assert.Equal(t, RoleAdmin, user.Attributes[RoleAttribute])
+					case "ivan":
+						assert.True(t, passwordPkg.Matches(user.Password, "Secret#1"))
+						assert.Equal(t, map[string]string{"team": "core"}, user.Attributes)
+					case "petr":
+						assert.True(t, passwordPkg.Matches(user.Password, "Secret#2"))
+					}
+					status, ok := c.restored[user.Name]
+					require.True(t, ok, user.Name)
+					return status, c.restoreErr
+				}).
+				Times(len(c.restored))
+
+			report, err := seeder.Run(ctx)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, c.exp, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+
+	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	"gitlab.ozon.dev/iTukaev/homework/migrations"
+	redisPkg "gitlab.ozon.dev/iTukaev/homework/pkg/redis"
+)
+
+// Postgres checks database connection and that all embedded migrations are applied.
+func Postgres(cfg pgModels.Config) []Probe {
+	var pool *pgxpool.Pool
+	return []Probe{
+		{
+			Name: "postgres",
+			Check: func(ctx context.Context) error {
+				conn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+					cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+				p, err := pgxpool.Connect(ctx, conn)
+				if err != nil {
+					return errors.Wrapf(err, "connect [%s:%s/%s]", cfg.Host, cfg.Port, cfg.DBName)
+				}
+				if err = p.Ping(ctx); err != nil {
+					p.Close()
+					return errors.Wrapf(err, "ping [%s:%s/%s]", cfg.Host, cfg.Port, cfg.DBName)
+				}
+				pool = p
+				return nil
+			},
+		},
+		{
+			Name: "migrations",
+			Check: func(ctx context.Context) error {
+				if pool == nil {
+					return errors.New("skipped, no database connection")
+				}
+				defer pool.Close()
+				return checkMigrations(ctx, pool)
+			},
+		},
+	}
+}
+
+func checkMigrations(ctx context.Context, pool *pgxpool.Pool) error {
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	var applied int64
+	if err = pool.QueryRow(ctx,
+		"SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied",
+	).Scan(&applied); err != nil {
+		return errors.Wrap(err, "read goose_db_version")
+	}
+	if applied < latest {
+		return errors.Errorf("database version [%d] is behind migrations [%d], run ./migrate.sh", applied, latest)
+	}
+	return nil
+}
+
+func Redis(cfg redisPkg.Config) Probe {
+	return Probe{
+		Name: "redis",
+		Check: func(ctx context.Context) error {
+			client, err := redisPkg.New(ctx, cfg)
+			if err != nil {
+				return errors.Wrapf(err, "ping [%s]", cfg.Host)
+			}
+			return client.Close()
+		},
+	}
+}
+
+// Kafka checks that brokers are reachable, topics are created by brokers on first use.
+func Kafka(brokers []string) Probe {
+	return Probe{
+		Name: "kafka",
+		Check: func(ctx context.Context) error {
+			cfg := sarama.NewConfig()
+			if deadline, ok := ctx.Deadline(); ok {
+				cfg.Net.DialTimeout = time.Until(deadline)
+			}
+			client, err := sarama.NewClient(brokers, cfg)
+			if err != nil {
+				return errors.Wrapf(err, "connect %v", brokers)
+			}
+			defer client.Close()
+
+			if _, err = client.Controller(); err != nil {
+				return errors.Wrap(err, "cluster controller")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const probeTimeout = 5 * time.Second
+
+// Probe checks a single dependency of the service.
+type Probe struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Run executes probes one by one and logs the readiness report.
+// Error lists reasons of all failed probes.
+func Run(ctx context.Context, logger *zap.SugaredLogger, probes ...Probe) ([]Result, error) {
+	results := make([]Result, 0, len(probes))
+	failed := make([]string, 0)
+	for _, probe := range probes {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		start := time.Now()
+		err := probe.Check(probeCtx)
+		cancel()
+
+		result := Result{
+			Name:     probe.Name,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+		results = append(results, result)
+
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", probe.Name, err))
+			logger.Errorw("self-check", "probe", result.Name, "status", "failed",
+				"duration", result.Duration, "error", err.Error())
+			continue
+		}
+		logger.Infow("self-check", "probe", result.Name, "status", "ok", "duration", result.Duration)
+	}
+
+	if len(failed) != 0 {
+		return results, errors.Errorf("self-check failed: %s", strings.Join(failed, "; "))
+	}
+	return results, nil
+}
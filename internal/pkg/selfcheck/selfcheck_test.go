@@ -0,0 +1,37 @@
+package selfcheck
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRun(t *testing.T) {
+	ok := Probe{
+		Name:  "redis",
+		Check: func(context.Context) error { return nil },
+	}
+	failed := Probe{
+		Name:  "kafka",
+		Check: func(context.Context) error { return errors.New("connection refused") },
+	}
+
+	t.Run("success", func(t *testing.T) {
+		results, err := Run(context.Background(), loggerPkg.NewFatal(), ok)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "redis", results[0].Name)
+	})
+
+	t.Run("failed, reason of every probe is reported", func(t *testing.T) {
+		results, err := Run(context.Background(), loggerPkg.NewFatal(), failed, ok, failed)
+		assert.EqualError(t, err, "self-check failed: kafka: connection refused; kafka: connection refused")
+		assert.Len(t, results, 3)
+		assert.NoError(t, results[1].Err)
+	})
+}
@@ -0,0 +1,143 @@
+package standby
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	bloomPkg "gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// meta identifies calls of the loader in logs of peers
+	meta = "warm-standby"
+)
+
+// ErrChecksum is returned, if the received state differs from the one sent by the peer.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Config of the warm standby: the cache state of a new replica is loaded from a healthy peer
+// instead of the database.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Peers are gRPC addresses of data service replicas, they are tried in order.
+	Peers []string `mapstructure:"peers"`
+	// Timeout of the transfer from one peer, default is 30s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// peer is a connection to the replica, it is closed after the transfer.
+type peer struct {
+	health healthpb.HealthClient
+	admin  pb.AdminClient
+	close  func() error
+}
+
+type Loader struct {
+	cfg    Config
+	token  string
+	dial   func(addr string) (peer, error)
+	logger *zap.SugaredLogger
+}
+
+// New returns loader of the cache state of peers, the admin token is sent to them, if it is set.
+func New(cfg Config, token string, logger *zap.SugaredLogger) *Loader {
+	return newLoader(cfg, func(addr string) (peer, error) {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return peer{}, err
+		}
+		return peer{
+			health: healthpb.NewHealthClient(conn),
+			admin:  pb.NewAdminClient(conn),
+			close:  conn.Close,
+		}, nil
+	}, token, logger)
+}
+
+func newLoader(cfg Config, dial func(addr string) (peer, error), token string, logger *zap.SugaredLogger) *Loader {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Loader{cfg: cfg, token: token, dial: dial, logger: logger}
+}
+
+// Bloom returns the bloom filter state of the first serving peer, which is received completely
+// and matches the checksum. Failed peers are logged, the error of the last one is returned.
+func (l *Loader) Bloom(ctx context.Context) (bloomPkg.State, error) {
+	err := errors.New("no peers")
+	for _, addr := range l.cfg.Peers {
+		var state bloomPkg.State
+		if state, err = l.bloom(ctx, addr); err == nil {
+			l.logger.Infow("bloom filter received", "peer", addr, "words", len(state.Bits))
+			return state, nil
+		}
+		err = errors.Wrapf(err, "peer [%s]", addr)
+		l.logger.Warnw("bloom filter transfer", "error", err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return bloomPkg.State{}, err
+}
+
+func (l *Loader) bloom(ctx context.Context, addr string) (bloomPkg.State, error) {
+	p, err := l.dial(addr)
+	if err != nil {
+		return bloomPkg.State{}, errors.Wrap(err, "dial")
+	}
+	defer func() {
+		_ = p.close()
+	}()
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(ctx, "meta", meta), l.cfg.Timeout)
+	defer cancel()
+	if l.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpcPkg.AdminMetaKey, l.token)
+	}
+
+	health, err := p.health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return bloomPkg.State{}, errors.Wrap(err, "health check")
+	}
+	if health.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return bloomPkg.State{}, errors.Errorf("peer is %s", health.GetStatus())
+	}
+
+	stream, err := p.admin.CacheStateExport(ctx, &pb.CacheStateExportRequest{})
+	if err != nil {
+		return bloomPkg.State{}, errors.Wrap(err, "open stream")
+	}
+	var (
+		state    bloomPkg.State
+		checksum string
+	)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return bloomPkg.State{}, errors.Wrap(err, "receive")
+		}
+		state.Size, state.Hashes = resp.GetSize(), resp.GetHashes()
+		state.Bits = append(state.Bits, resp.GetBits()...)
+		if resp.GetChecksum() != "" {
+			checksum = resp.GetChecksum()
+		}
+	}
+	// the checksum is sent last, so the state without it is incomplete
+	if checksum == "" || state.Checksum() != checksum {
+		return bloomPkg.State{}, ErrChecksum
+	}
+	return state, nil
+}
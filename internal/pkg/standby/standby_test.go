@@ -0,0 +1,126 @@
+package standby
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	bloomPkg "gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+)
+
+type health healthpb.HealthCheckResponse_ServingStatus
+
+func (h health) Check(context.Context, *healthpb.HealthCheckRequest, ...grpc.CallOption) (*healthpb.HealthCheckResponse, error) {
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_ServingStatus(h)}, nil
+}
+
+func (h health) Watch(context.Context, *healthpb.HealthCheckRequest, ...grpc.CallOption) (healthpb.Health_WatchClient, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestLoader_Bloom(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	filter := bloomPkg.New(100, 0.01)
+	filter.Add("ivan")
+	state := filter.State()
+	half := len(state.Bits) / 2
+	chunks := func(checksum string) []*pb.CacheStateExportResponse {
+		return []*pb.CacheStateExportResponse{
+			{Size: state.Size, Hashes: state.Hashes, Bits: state.Bits[:half]},
+			{Size: state.Size, Hashes: state.Hashes, Bits: state.Bits[half:], Checksum: checksum},
+		}
+	}
+
+	type peerState struct {
+		status healthpb.HealthCheckResponse_ServingStatus
+		chunks []*pb.CacheStateExportResponse
+	}
+	cases := []struct {
+		name  string
+		peers []peerState
+		// expPeer is an index of the peer the state is loaded from, -1 if none
+		expPeer int
+	}{
+		{
+			name:    "success, first peer",
+			peers:   []peerState{{status: healthpb.HealthCheckResponse_SERVING, chunks: chunks(state.Checksum())}},
+			expPeer: 0,
+		},
+		{
+			name: "success, not serving peer is skipped",
+			peers: []peerState{
+				{status: healthpb.HealthCheckResponse_NOT_SERVING},
+				{status: healthpb.HealthCheckResponse_SERVING, chunks: chunks(state.Checksum())},
+			},
+			expPeer: 1,
+		},
+		{
+			name: "success, checksum mismatch is skipped",
+			peers: []peerState{
+				{status: healthpb.HealthCheckResponse_SERVING, chunks: chunks("abcd")},
+				{status: healthpb.HealthCheckResponse_SERVING, chunks: chunks(state.Checksum())},
+			},
+			expPeer: 1,
+		},
+		{
+			name:    "failed, truncated stream",
+			peers:   []peerState{{status: healthpb.HealthCheckResponse_SERVING, chunks: chunks(state.Checksum())[:1]}},
+			expPeer: -1,
+		},
+		{
+			name:    "failed, no peers",
+			expPeer: -1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var (
+				addrs  []string
+				peers  = make(map[string]peer)
+				closed int
+			)
+			for i, p := range c.peers {
+				addr := string(rune('a' + i))
+				admin := apiMockPkg.NewMockAdminClient(ctl)
+				if p.status == healthpb.HealthCheckResponse_SERVING {
+					stream := apiMockPkg.NewMockAdmin_CacheStateExportClient(ctl)
+					for _, chunk := range p.chunks {
+						stream.EXPECT().Recv().Return(chunk, nil).Times(1)
+					}
+					stream.EXPECT().Recv().Return(nil, io.EOF).Times(1)
+					admin.EXPECT().CacheStateExport(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1)
+				}
+				addrs = append(addrs, addr)
+				peers[addr] = peer{health: health(p.status), admin: admin, close: func() error {
+					closed++
+					return nil
+				}}
+			}
+
+			loader := newLoader(Config{Enabled: true, Peers: addrs}, func(addr string) (peer, error) {
+				return peers[addr], nil
+			}, "", loggerPkg.NewFatal())
+			got, err := loader.Bloom(context.Background())
+			if c.expPeer < 0 {
+				assert.Error(t, err)
+				assert.Equal(t, len(c.peers), closed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, state.Checksum(), got.Checksum())
+			assert.Equal(t, c.expPeer+1, closed)
+		})
+	}
+}
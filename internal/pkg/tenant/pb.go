@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"time"
+
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+func ToPb(overrides Overrides) *pb.TenantOverrides {
+	result := &pb.TenantOverrides{
+		Tenant:              overrides.Tenant,
+		MaxPasswordAttempts: uint32(overrides.MaxPasswordAttempts),
+		MaxAvatarSize:       overrides.MaxAvatarSize,
+		Features:            overrides.Features,
+		Actor:               overrides.Actor,
+		UpdatedAt:           overrides.UpdatedAt,
+	}
+	if policy := overrides.Password; policy != nil {
+		result.Password = &pb.TenantPasswordPolicy{
+			MinLength:    uint32(policy.MinLength),
+			Lower:        policy.Lower,
+			Upper:        policy.Upper,
+			Digit:        policy.Digit,
+			Symbol:       policy.Symbol,
+			Breached:     policy.Breached,
+			DisallowName: policy.DisallowName,
+			MaxAge:       int64(policy.MaxAge / time.Second),
+		}
+	}
+	return result
+}
+
+// FromPb returns the overrides of the request, the actor and the time of the change are ignored.
+func FromPb(overrides *pb.TenantOverrides) Overrides {
+	result := Overrides{
+		Tenant:              overrides.GetTenant(),
+		MaxPasswordAttempts: int(overrides.GetMaxPasswordAttempts()),
+		MaxAvatarSize:       overrides.GetMaxAvatarSize(),
+		Features:            overrides.GetFeatures(),
+	}
+	if policy := overrides.GetPassword(); policy != nil {
+		result.Password = &passwordPkg.Policy{
+			MinLength:    int(policy.GetMinLength()),
+			Lower:        policy.GetLower(),
+			Upper:        policy.GetUpper(),
+			Digit:        policy.GetDigit(),
+			Symbol:       policy.GetSymbol(),
+			Breached:     policy.GetBreached(),
+			DisallowName: policy.GetDisallowName(),
+			MaxAge:       time.Duration(policy.GetMaxAge()) * time.Second,
+		}
+	}
+	return result
+}
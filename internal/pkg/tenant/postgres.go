@@ -0,0 +1,102 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+)
+
+const (
+	overridesTable = "tenant_overrides"
+
+	tenantField    = "tenant"
+	overridesField = "overrides"
+	actorField     = "actor"
+	updatedAtField = "updated_at"
+)
+
+// NewPostgres returns the store, which keeps the overrides in the tenant_overrides table.
+func NewPostgres(pool pgxtype.Querier) Store {
+	return &postgres{pool: pool}
+}
+
+type postgres struct {
+	pool pgxtype.Querier
+}
+
+func (p *postgres) List(ctx context.Context) ([]Overrides, error) {
+	query, args, err := squirrel.Select(overridesField).
+		From(overridesTable).
+		OrderBy(tenantField).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "tenant list")
+	}
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "tenant list")
+	}
+	defer rows.Close()
+	var list []Overrides
+	for rows.Next() {
+		var data []byte
+		if err = rows.Scan(&data); err != nil {
+			return nil, errors.Wrap(err, "tenant list")
+		}
+		var overrides Overrides
+		if err = json.Unmarshal(data, &overrides); err != nil {
+			return nil, errors.Wrap(err, "tenant list: unmarshal overrides")
+		}
+		list = append(list, overrides)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "tenant list")
+	}
+	return list, nil
+}
+
+func (p *postgres) Set(ctx context.Context, overrides Overrides) error {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return errors.Wrap(err, "tenant set: marshal overrides")
+	}
+	query, args, err := squirrel.Insert(overridesTable).
+		Columns(tenantField, overridesField, actorField, updatedAtField).
+		Values(overrides.Tenant, data, overrides.Actor, overrides.UpdatedAt).
+		Suffix("ON CONFLICT (tenant) DO UPDATE SET overrides = EXCLUDED.overrides, " +
+			"actor = EXCLUDED.actor, updated_at = EXCLUDED.updated_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "tenant set")
+	}
+	if _, err = p.pool.Exec(ctx, query, args...); err != nil {
+		return errors.Wrap(err, "tenant set")
+	}
+	return nil
+}
+
+func (p *postgres) Delete(ctx context.Context, tenant string) error {
+	query, args, err := squirrel.Delete(overridesTable).
+		Where(squirrel.Eq{tenantField: tenant}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "tenant delete")
+	}
+	tag, err := p.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "tenant delete")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.Wrapf(errorsPkg.ErrTenantNotFound, "tenant [%s]", tenant)
+	}
+	return nil
+}
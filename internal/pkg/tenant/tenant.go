@@ -0,0 +1,256 @@
+//go:generate mockgen -source=tenant.go -destination=./mock/tenant_mock.go -package=mock
+
+// Package tenant keeps overrides of the service config for users of a tenant: the password policy,
+// the password checks limit, the avatar quota and feature flags. The tenant is selected by
+// user attribute "tenant", overrides are stored in the repository and cached by every replica.
+package tenant
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+// Features of users switched by the overrides.
+const (
+	// FeatureAvatars allows users of the tenant to upload avatars.
+	FeatureAvatars = "avatars"
+)
+
+const defaultRefreshInterval = 30 * time.Second
+
+// Config of the overrides.
+type Config struct {
+	// RefreshInterval is an interval of reading the overrides changed by other replicas.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// Overrides of the tenant, zero values keep the service config.
+type Overrides struct {
+	Tenant string `json:"tenant"`
+	// Password replaces the password policy of the tenant.
+	Password *passwordPkg.Policy `json:"password,omitempty"`
+	// MaxPasswordAttempts limits failed password checks of the tenant users within the window.
+	MaxPasswordAttempts int `json:"max_password_attempts,omitempty"`
+	// MaxAvatarSize is the quota of avatars of the tenant users in bytes.
+	MaxAvatarSize int64 `json:"max_avatar_size,omitempty"`
+	// Features enable or disable features for users of the tenant, features missing here keep the default.
+	Features map[string]bool `json:"features,omitempty"`
+	// Actor is a request meta of the last change.
+	Actor string `json:"actor,omitempty"`
+	// UpdatedAt is unix time of the last change.
+	UpdatedAt int64 `json:"updated_at,omitempty"`
+}
+
+// Store persists the overrides, so they are shared by replicas.
+type Store interface {
+	List(ctx context.Context) ([]Overrides, error)
+	Set(ctx context.Context, overrides Overrides) error
+	// Delete returns ErrTenantNotFound, if the tenant of the name has no overrides.
+	Delete(ctx context.Context, name string) error
+}
+
+type Interface interface {
+	// Get returns the cached overrides of the tenant of the name, false if it has none.
+	Get(name string) (Overrides, bool)
+	// List returns the cached overrides of all tenants ordered by tenant.
+	List() []Overrides
+	// Set replaces the overrides of the tenant on behalf of the actor.
+	Set(ctx context.Context, overrides Overrides, actor string) (Overrides, error)
+	// Delete removes the overrides of the tenant of the name, its users get the service config.
+	Delete(ctx context.Context, name, actor string) error
+}
+
+// Of returns the tenant of the user, it is empty for users without the attribute.
+func Of(user models.User) string {
+	return user.Attributes[passwordPkg.TenantAttribute]
+}
+
+// Registry caches the overrides of the store, so they are applied without store calls.
+// Writes invalidate the cache of this replica, others read the changes on refresh.
+type Registry struct {
+	store  Store
+	logger *zap.SugaredLogger
+
+	mu        sync.RWMutex
+	overrides map[string]Overrides
+}
+
+func New(store Store, logger *zap.SugaredLogger) *Registry {
+	return &Registry{
+		store:     store,
+		logger:    logger,
+		overrides: make(map[string]Overrides),
+	}
+}
+
+func (r *Registry) Get(tenant string) (Overrides, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	overrides, ok := r.overrides[tenant]
+	return overrides, ok
+}
+
+func (r *Registry) List() []Overrides {
+	r.mu.RLock()
+	list := make([]Overrides, 0, len(r.overrides))
+	for _, overrides := range r.overrides {
+		list = append(list, overrides)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Tenant < list[j].Tenant
+	})
+	return list
+}
+
+func (r *Registry) Set(ctx context.Context, overrides Overrides, actor string) (Overrides, error) {
+	if err := validate(overrides); err != nil {
+		return Overrides{}, errors.Wrap(err, "tenant set")
+	}
+	overrides.Actor = actor
+	overrides.UpdatedAt = time.Now().Unix()
+	if err := r.store.Set(ctx, overrides); err != nil {
+		return Overrides{}, errors.Wrap(err, "tenant set")
+	}
+	r.invalidate(ctx)
+	r.logger.Infow("tenant overrides set", "tenant", overrides.Tenant, "actor", actor)
+	return overrides, nil
+}
+
+func (r *Registry) Delete(ctx context.Context, tenant, actor string) error {
+	if err := r.store.Delete(ctx, tenant); err != nil {
+		return errors.Wrap(err, "tenant delete")
+	}
+	r.invalidate(ctx)
+	r.logger.Infow("tenant overrides deleted", "tenant", tenant, "actor", actor)
+	return nil
+}
+
+// invalidate reads the overrides changed by this replica, the write is applied by the next
+// refresh, if the store cannot be read now.
+func (r *Registry) invalidate(ctx context.Context) {
+	if err := r.Refresh(ctx); err != nil {
+		r.logger.Errorln(err)
+	}
+}
+
+// Refresh reads the overrides from the store.
+func (r *Registry) Refresh(ctx context.Context) error {
+	list, err := r.store.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "tenant refresh")
+	}
+	overrides := make(map[string]Overrides, len(list))
+	for _, o := range list {
+		overrides[o.Tenant] = o
+	}
+	r.mu.Lock()
+	r.overrides = overrides
+	r.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the overrides until ctx is done.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Errorln(err)
+			}
+		}
+	}
+}
+
+// PasswordPolicy returns the password policy of the tenant, it makes the registry
+// the overrides of password policies.
+func (r *Registry) PasswordPolicy(tenant string) (passwordPkg.Policy, bool) {
+	overrides, ok := r.Get(tenant)
+	if !ok || overrides.Password == nil {
+		return passwordPkg.Policy{}, false
+	}
+	return *overrides.Password, true
+}
+
+// Feature reports whether the feature is enabled for users of the tenant, def is returned,
+// if the tenant does not override it.
+func Feature(tenants Interface, tenant, feature string, def bool) bool {
+	if tenants == nil {
+		return def
+	}
+	overrides, ok := tenants.Get(tenant)
+	if !ok {
+		return def
+	}
+	if enabled, ok := overrides.Features[feature]; ok {
+		return enabled
+	}
+	return def
+}
+
+func validate(overrides Overrides) error {
+	switch {
+	case overrides.Tenant == "":
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [tenant] cannot be empty")
+	case overrides.MaxPasswordAttempts < 0:
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [max_password_attempts] cannot be negative")
+	case overrides.MaxAvatarSize < 0:
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [max_avatar_size] cannot be negative")
+	case overrides.Password != nil && (overrides.Password.MinLength < 0 || overrides.Password.MaxAge < 0):
+		return errors.Wrap(errorsPkg.ErrValidation, "field: [password] min_length and max_age cannot be negative")
+	}
+	return nil
+}
+
+// NewMemory returns the store, which keeps the overrides until the process is stopped.
+func NewMemory() Store {
+	return &memory{overrides: make(map[string]Overrides)}
+}
+
+type memory struct {
+	mu        sync.Mutex
+	overrides map[string]Overrides
+}
+
+func (m *memory) List(context.Context) ([]Overrides, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Overrides, 0, len(m.overrides))
+	for _, overrides := range m.overrides {
+		list = append(list, overrides)
+	}
+	return list, nil
+}
+
+func (m *memory) Set(_ context.Context, overrides Overrides) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides[overrides.Tenant] = overrides
+	return nil
+}
+
+func (m *memory) Delete(_ context.Context, tenant string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.overrides[tenant]; !ok {
+		return errors.Wrapf(errorsPkg.ErrTenantNotFound, "tenant [%s]", tenant)
+	}
+	delete(m.overrides, tenant)
+	return nil
+}
@@ -0,0 +1,85 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRegistry_Set(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	registry := New(store, loggerPkg.NewFatal())
+
+	_, err := registry.Set(ctx, Overrides{MaxPasswordAttempts: 3}, "meta")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+	_, err = registry.Set(ctx, Overrides{Tenant: "staff", MaxAvatarSize: -1}, "meta")
+	assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+
+	set, err := registry.Set(ctx, Overrides{
+		Tenant:   "staff",
+		Password: &passwordPkg.Policy{MinLength: 16},
+		Features: map[string]bool{FeatureAvatars: false},
+	}, "meta")
+	require.NoError(t, err)
+	assert.Equal(t, "meta", set.Actor)
+
+	// the write invalidates the cache of the replica
+	cached, ok := registry.Get("staff")
+	require.True(t, ok)
+	assert.Equal(t, set, cached)
+	policy, ok := registry.PasswordPolicy("staff")
+	assert.True(t, ok)
+	assert.Equal(t, 16, policy.MinLength)
+	assert.False(t, Feature(registry, "staff", FeatureAvatars, true))
+	assert.True(t, Feature(registry, "", FeatureAvatars, true))
+
+	// the overrides written by another replica are applied on refresh
+	replica := New(store, loggerPkg.NewFatal())
+	require.NoError(t, replica.Refresh(ctx))
+	assert.Equal(t, []Overrides{set}, replica.List())
+
+	require.NoError(t, registry.Delete(ctx, "staff", "meta"))
+	_, ok = registry.Get("staff")
+	assert.False(t, ok)
+	assert.ErrorIs(t, registry.Delete(ctx, "staff", "meta"), errorsPkg.ErrTenantNotFound)
+}
+
+func TestPostgres_Delete(t *testing.T) {
+	cases := []struct {
+		name     string
+		affected int64
+		expErr   error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+		},
+		{
+			name:   "failed, tenant without overrides",
+			expErr: errorsPkg.ErrTenantNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mock.Close()
+
+			mock.ExpectExec("DELETE FROM tenant_overrides WHERE tenant = $1").WithArgs("staff").
+				WillReturnResult(pgxmock.NewResult("DELETE", c.affected))
+
+			err = NewPostgres(mock).Delete(context.Background(), "staff")
+			assert.ErrorIs(t, err, c.expErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
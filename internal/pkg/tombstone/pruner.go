@@ -0,0 +1,71 @@
+// Package tombstone prunes tombstones of deleted users, which delta exports report
+// to incremental sync consumers.
+package tombstone
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultPruneInterval = time.Hour
+
+// Config of the tombstone retention, zero retention keeps tombstones forever.
+// Consumers must sync more often than the retention, older deltas require a full export.
+type Config struct {
+	Retention time.Duration `mapstructure:"retention"`
+	// Interval between prunes.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Interface removes tombstones out of the retention at the time, it is the user core.
+type Interface interface {
+	PruneTombstones(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Pruner removes tombstones out of the retention, so they don't grow unbounded.
+type Pruner struct {
+	user   Interface
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+}
+
+func NewPruner(user Interface, cfg Config, clk clock.Clock, logger *zap.SugaredLogger) *Pruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultPruneInterval
+	}
+	return &Pruner{
+		user:   user,
+		cfg:    cfg,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Run prunes tombstones every interval until ctx is done. It must be run on the leader only.
+func (p *Pruner) Run(ctx context.Context) {
+	p.logger.Infow("Start tombstones pruning", "retention", p.cfg.Retention, "interval", p.cfg.Interval)
+	ticker := p.clock.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		pruned, err := p.user.PruneTombstones(ctx, p.clock.Now())
+		if err != nil && ctx.Err() == nil {
+			p.logger.Errorf("tombstones prune: %v", err)
+		}
+		counter.TombstonesPruned.Add(uint64(pruned))
+		if pruned != 0 {
+			p.logger.Infow("Tombstones pruned", "tombstones", pruned)
+		}
+		select {
+		case <-ctx.Done():
+			p.logger.Infoln("Tombstones pruning stopped")
+			return
+		case <-ticker.C():
+		}
+	}
+}
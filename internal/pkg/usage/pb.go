@@ -0,0 +1,27 @@
+package usage
+
+import (
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+func ToPb(report []ClientUsage) []*pb.ClientUsage {
+	result := make([]*pb.ClientUsage, 0, len(report))
+	for _, client := range report {
+		methods := make([]*pb.MethodUsage, 0, len(client.Methods))
+		for _, method := range client.Methods {
+			methods = append(methods, &pb.MethodUsage{
+				Method:   method.Method,
+				Requests: method.Requests,
+				Errors:   method.Errors,
+			})
+		}
+		result = append(result, &pb.ClientUsage{
+			Client:    client.Client,
+			Requests:  client.Requests,
+			Errors:    client.Errors,
+			ErrorRate: client.ErrorRate(),
+			Methods:   methods,
+		})
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	usageTable = "api_usage"
+
+	clientField   = "client"
+	methodField   = "method"
+	periodField   = "period"
+	requestsField = "requests"
+	errorsField   = "errors"
+)
+
+// NewPostgres returns the store, which sums usage of replicas in the api_usage table.
+func NewPostgres(pool pgxtype.Querier) Store {
+	return &postgres{pool: pool}
+}
+
+type postgres struct {
+	pool pgxtype.Querier
+}
+
+func (p *postgres) Add(ctx context.Context, usage []Usage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	insert := squirrel.Insert(usageTable).
+		Columns(clientField, methodField, periodField, requestsField, errorsField)
+	for _, u := range usage {
+		insert = insert.Values(u.Client, u.Method, u.Period, u.Requests, u.Errors)
+	}
+	query, args, err := insert.
+		Suffix("ON CONFLICT (client, method, period) DO UPDATE SET " +
+			"requests = api_usage.requests + EXCLUDED.requests, errors = api_usage.errors + EXCLUDED.errors").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "usage add")
+	}
+	if _, err = p.pool.Exec(ctx, query, args...); err != nil {
+		return errors.Wrap(err, "usage add")
+	}
+	return nil
+}
+
+func (p *postgres) List(ctx context.Context, since, until int64, client string) ([]Usage, error) {
+	where := squirrel.And{
+		squirrel.GtOrEq{periodField: since},
+		squirrel.Lt{periodField: until},
+	}
+	if client != "" {
+		where = append(where, squirrel.Eq{clientField: client})
+	}
+	query, args, err := squirrel.Select(clientField, methodField, periodField, requestsField, errorsField).
+		From(usageTable).
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "usage list")
+	}
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "usage list")
+	}
+	defer rows.Close()
+	var list []Usage
+	for rows.Next() {
+		var u Usage
+		if err = rows.Scan(&u.Client, &u.Method, &u.Period, &u.Requests, &u.Errors); err != nil {
+			return nil, errors.Wrap(err, "usage list")
+		}
+		list = append(list, u)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "usage list")
+	}
+	return list, nil
+}
+
+func (p *postgres) Prune(ctx context.Context, before int64) (int64, error) {
+	query, args, err := squirrel.Delete(usageTable).
+		Where(squirrel.Lt{periodField: before}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "usage prune")
+	}
+	tag, err := p.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "usage prune")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,366 @@
+//go:generate mockgen -source=usage.go -destination=./mock/usage_mock.go -package=mock
+
+// Package usage counts API calls and errors of every client, so quotas are negotiated and abusive
+// clients are found by data. Calls are counted in memory and aggregated into the store by hourly
+// periods every interval, so the report covers calls of all replicas.
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const (
+	defaultInterval   = time.Minute
+	defaultRetention  = 30 * 24 * time.Hour
+	defaultMaxClients = 1000
+	// flushTimeout limits the last aggregation on stop
+	flushTimeout = 10 * time.Second
+
+	// Period is the length of aggregated periods.
+	Period = time.Hour
+	// Anonymous is the client of calls without the request meta.
+	Anonymous = "anonymous"
+	// OtherClients counts calls of clients beyond MaxClients of one aggregation.
+	OtherClients = "other"
+)
+
+// Config of the usage reporting.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval between aggregations into the store, default is 1m.
+	Interval time.Duration `mapstructure:"interval"`
+	// Retention of aggregated periods, default is 30 days.
+	Retention time.Duration `mapstructure:"retention"`
+	// MaxClients limits clients counted between aggregations, since the request meta is set by callers.
+	// Calls of others are counted by OtherClients. Default is 1000.
+	MaxClients int `mapstructure:"max_clients"`
+}
+
+// Usage is the number of calls of the client to the method within the period.
+type Usage struct {
+	Client string
+	Method string
+	// Period is unix time of the period start.
+	Period   int64
+	Requests uint64
+	Errors   uint64
+}
+
+// ClientUsage is usage of the client summed over periods, methods are ordered by requests.
+type ClientUsage struct {
+	Client   string
+	Requests uint64
+	Errors   uint64
+	Methods  []Usage
+}
+
+// ErrorRate returns the share of failed calls.
+func (u ClientUsage) ErrorRate() float64 {
+	if u.Requests == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Requests)
+}
+
+// Store aggregates usage by client, method and period.
+type Store interface {
+	// Add adds the counts to the stored ones of the same client, method and period.
+	Add(ctx context.Context, usage []Usage) error
+	// List returns usage of periods started at since or later and before until,
+	// usage of the client only, if it is not empty.
+	List(ctx context.Context, since, until int64, client string) ([]Usage, error)
+	// Prune removes periods started before the time, the number of removed rows is returned.
+	Prune(ctx context.Context, before int64) (int64, error)
+}
+
+type Interface interface {
+	// Record counts the call of the client to the method, it is failed, if err is not nil.
+	Record(client, method string, err error)
+	// Report returns usage of clients within [since, until) including calls not aggregated yet,
+	// the most active clients go first.
+	Report(ctx context.Context, since, until int64, client string) ([]ClientUsage, error)
+}
+
+type key struct {
+	client string
+	method string
+	period int64
+}
+
+// Tracker counts calls and aggregates them into the store.
+type Tracker struct {
+	store  Store
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	pending map[key]*Usage
+	clients map[string]struct{}
+}
+
+func New(store Store, cfg Config, clk clock.Clock, logger *zap.SugaredLogger) *Tracker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	if cfg.MaxClients <= 0 {
+		cfg.MaxClients = defaultMaxClients
+	}
+	return &Tracker{
+		store:   store,
+		cfg:     cfg,
+		clock:   clk,
+		logger:  logger,
+		pending: make(map[key]*Usage),
+		clients: make(map[string]struct{}),
+	}
+}
+
+func (t *Tracker) Record(client, method string, err error) {
+	period := t.clock.Now().Truncate(Period).Unix()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.clients[client]; !ok {
+		if len(t.clients) >= t.cfg.MaxClients {
+			client = OtherClients
+		}
+		t.clients[client] = struct{}{}
+	}
+	k := key{client: client, method: method, period: period}
+	u, ok := t.pending[k]
+	if !ok {
+		u = &Usage{Client: client, Method: method, Period: period}
+		t.pending[k] = u
+	}
+	u.Requests++
+	if err != nil {
+		u.Errors++
+	}
+}
+
+// Flush adds the pending counts to the store. Counts, which failed to be added, are kept
+// for the next aggregation.
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[key]*Usage, len(pending))
+	t.clients = make(map[string]struct{})
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usage := make([]Usage, 0, len(pending))
+	for _, u := range pending {
+		usage = append(usage, *u)
+	}
+	if err := t.store.Add(ctx, usage); err != nil {
+		t.restore(usage)
+		return errors.Wrap(err, "usage flush")
+	}
+	return nil
+}
+
+// restore returns counts not added to the store to the pending ones.
+func (t *Tracker) restore(usage []Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, u := range usage {
+		k := key{client: u.Client, method: u.Method, period: u.Period}
+		t.clients[u.Client] = struct{}{}
+		if pending, ok := t.pending[k]; ok {
+			pending.Requests += u.Requests
+			pending.Errors += u.Errors
+			continue
+		}
+		restored := u
+		t.pending[k] = &restored
+	}
+}
+
+func (t *Tracker) Report(ctx context.Context, since, until int64, client string) ([]ClientUsage, error) {
+	stored, err := t.store.List(ctx, since, until, client)
+	if err != nil {
+		return nil, errors.Wrap(err, "usage report")
+	}
+	t.mu.Lock()
+	for _, u := range t.pending {
+		if u.Period >= since && u.Period < until && (client == "" || u.Client == client) {
+			stored = append(stored, *u)
+		}
+	}
+	t.mu.Unlock()
+	return summarize(stored), nil
+}
+
+// summarize sums usage of periods by client and method.
+func summarize(usage []Usage) []ClientUsage {
+	clients := make(map[string]*ClientUsage)
+	methods := make(map[string]map[string]*Usage)
+	for _, u := range usage {
+		c, ok := clients[u.Client]
+		if !ok {
+			c = &ClientUsage{Client: u.Client}
+			clients[u.Client] = c
+			methods[u.Client] = make(map[string]*Usage)
+		}
+		c.Requests += u.Requests
+		c.Errors += u.Errors
+		m, ok := methods[u.Client][u.Method]
+		if !ok {
+			m = &Usage{Client: u.Client, Method: u.Method}
+			methods[u.Client][u.Method] = m
+		}
+		m.Requests += u.Requests
+		m.Errors += u.Errors
+	}
+
+	report := make([]ClientUsage, 0, len(clients))
+	for name, c := range clients {
+		for _, m := range methods[name] {
+			c.Methods = append(c.Methods, *m)
+		}
+		sort.Slice(c.Methods, func(i, j int) bool {
+			if c.Methods[i].Requests != c.Methods[j].Requests {
+				return c.Methods[i].Requests > c.Methods[j].Requests
+			}
+			return c.Methods[i].Method < c.Methods[j].Method
+		})
+		report = append(report, *c)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Requests != report[j].Requests {
+			return report[i].Requests > report[j].Requests
+		}
+		return report[i].Client < report[j].Client
+	})
+	return report
+}
+
+// Run aggregates the counts every interval and prunes periods beyond the retention once a period,
+// the last counts are aggregated, when ctx is done.
+func (t *Tracker) Run(ctx context.Context) {
+	t.logger.Infow("Start usage reporting", "interval", t.cfg.Interval, "retention", t.cfg.Retention)
+	ticker := t.clock.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+	var nextPrune time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			if err := t.Flush(flushCtx); err != nil {
+				t.logger.Errorf("usage flush on stop: %v", err)
+			}
+			cancel()
+			t.logger.Infoln("Usage reporting stopped")
+			return
+		case <-ticker.C():
+		}
+		if err := t.Flush(ctx); err != nil && ctx.Err() == nil {
+			t.logger.Errorf("usage flush: %v", err)
+		}
+		if now := t.clock.Now(); !now.Before(nextPrune) {
+			nextPrune = now.Add(Period)
+			pruned, err := t.store.Prune(ctx, now.Add(-t.cfg.Retention).Unix())
+			if err != nil && ctx.Err() == nil {
+				t.logger.Errorf("usage prune: %v", err)
+			} else if pruned != 0 {
+				t.logger.Infow("usage pruned", "rows", pruned)
+			}
+		}
+	}
+}
+
+// Client returns the request meta of the caller, calls without it are Anonymous.
+func Client(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if meta := md.Get("meta"); len(meta) != 0 && meta[0] != "" {
+		return meta[0]
+	}
+	return Anonymous
+}
+
+// UnaryInterceptor records calls by the client and the method. It precedes authentication,
+// so rejected calls of clients are counted as errors too.
+func UnaryInterceptor(usage Interface) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		usage.Record(Client(ctx), info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor is UnaryInterceptor of streams, a stream is one call.
+func StreamInterceptor(usage Interface) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		usage.Record(Client(ss.Context()), info.FullMethod, err)
+		return err
+	}
+}
+
+// NewMemory returns the store, which keeps usage until the process is stopped.
+func NewMemory() Store {
+	return &memory{usage: make(map[key]Usage)}
+}
+
+type memory struct {
+	mu    sync.Mutex
+	usage map[key]Usage
+}
+
+func (m *memory) Add(_ context.Context, usage []Usage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range usage {
+		k := key{client: u.Client, method: u.Method, period: u.Period}
+		stored := m.usage[k]
+		u.Requests += stored.Requests
+		u.Errors += stored.Errors
+		m.usage[k] = u
+	}
+	return nil
+}
+
+func (m *memory) List(_ context.Context, since, until int64, client string) ([]Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []Usage
+	for _, u := range m.usage {
+		if u.Period >= since && u.Period < until && (client == "" || u.Client == client) {
+			list = append(list, u)
+		}
+	}
+	return list, nil
+}
+
+func (m *memory) Prune(_ context.Context, before int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var pruned int64
+	for k, u := range m.usage {
+		if u.Period < before {
+			delete(m.usage, k)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,119 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const (
+	userGet    = "/ozon.dev.homework.api.User/UserGet"
+	userCreate = "/ozon.dev.homework.api.User/UserCreate"
+)
+
+func TestTracker_Report(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Unix(7200, 0))
+	store := NewMemory()
+	tracker := New(store, Config{MaxClients: 2}, clk, loggerPkg.NewFatal())
+
+	tracker.Record("shop", userGet, nil)
+	tracker.Record("shop", userGet, errors.New("not found"))
+	tracker.Record("bot", userCreate, nil)
+	// clients beyond the limit are counted together
+	tracker.Record("crawler", userGet, nil)
+	tracker.Record("scraper", userGet, errors.New("denied"))
+	require.NoError(t, tracker.Flush(ctx))
+
+	clk.Advance(Period)
+	tracker.Record("shop", userCreate, nil)
+
+	report, err := tracker.Report(ctx, 0, clk.Now().Add(Period).Unix(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []ClientUsage{
+		{
+			Client:   "shop",
+			Requests: 3,
+			Errors:   1,
+			Methods: []Usage{
+				{Client: "shop", Method: userGet, Requests: 2, Errors: 1},
+				{Client: "shop", Method: userCreate, Requests: 1},
+			},
+		},
+		{
+			Client:   OtherClients,
+			Requests: 2,
+			Errors:   1,
+			Methods:  []Usage{{Client: OtherClients, Method: userGet, Requests: 2, Errors: 1}},
+		},
+		{
+			Client:   "bot",
+			Requests: 1,
+			Methods:  []Usage{{Client: "bot", Method: userCreate, Requests: 1}},
+		},
+	}, report)
+	assert.Equal(t, 1.0/3, report[0].ErrorRate())
+
+	// the pending period is out of the range
+	report, err = tracker.Report(ctx, 0, clk.Now().Unix(), "shop")
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, uint64(2), report[0].Requests)
+}
+
+// failingStore fails to add usage, while err is set.
+type failingStore struct {
+	Store
+	err error
+}
+
+func (s *failingStore) Add(ctx context.Context, usage []Usage) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Store.Add(ctx, usage)
+}
+
+func TestTracker_Flush(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{Store: NewMemory(), err: errors.New("connection refused")}
+	tracker := New(store, Config{}, clock.NewFake(time.Unix(3600, 0)), loggerPkg.NewFatal())
+
+	tracker.Record("shop", userGet, nil)
+	assert.Error(t, tracker.Flush(ctx))
+
+	// the counts failed to be added are kept
+	tracker.Record("shop", userGet, errors.New("not found"))
+	store.err = nil
+	require.NoError(t, tracker.Flush(ctx))
+	require.NoError(t, tracker.Flush(ctx))
+	stored, err := store.List(ctx, 0, 7200, "")
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{{Client: "shop", Method: userGet, Period: 3600, Requests: 2, Errors: 1}}, stored)
+}
+
+func TestPostgres_Add(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectExec("INSERT INTO api_usage (client,method,period,requests,errors) VALUES ($1,$2,$3,$4,$5) "+
+		"ON CONFLICT (client, method, period) DO UPDATE SET "+
+		"requests = api_usage.requests + EXCLUDED.requests, errors = api_usage.errors + EXCLUDED.errors").
+		WithArgs("shop", userGet, int64(3600), uint64(2), uint64(1)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = NewPostgres(mock).Add(context.Background(), []Usage{
+		{Client: "shop", Method: userGet, Period: 3600, Requests: 2, Errors: 1},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
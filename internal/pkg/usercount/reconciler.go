@@ -0,0 +1,69 @@
+// Package usercount reconciles counters of users by tenant, which writes maintain in the same
+// transaction, with the users they count.
+package usercount
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultInterval = time.Hour
+
+// Config of the reconciliation.
+type Config struct {
+	// Interval between recounts, default is 1h.
+	Interval time.Duration `mapstructure:"reconcile_interval"`
+}
+
+// Interface recounts users and corrects drifted counters, it is the user core.
+type Interface interface {
+	ReconcileCounts(ctx context.Context) (int64, error)
+}
+
+// Reconciler corrects counters drifted by writes bypassing the repository, e.g. manual fixes of the database.
+type Reconciler struct {
+	user   Interface
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+}
+
+func NewReconciler(user Interface, cfg Config, clk clock.Clock, logger *zap.SugaredLogger) *Reconciler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	return &Reconciler{
+		user:   user,
+		cfg:    cfg,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Run recounts users every interval until ctx is done. It must be run on the leader only.
+func (r *Reconciler) Run(ctx context.Context) {
+	r.logger.Infow("Start user counts reconciliation", "interval", r.cfg.Interval)
+	ticker := r.clock.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Infoln("User counts reconciliation stopped")
+			return
+		case <-ticker.C():
+		}
+		corrected, err := r.user.ReconcileCounts(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorf("user counts reconcile: %v", err)
+		}
+		counter.UserCountsCorrected.Add(uint64(corrected))
+		if corrected != 0 {
+			r.logger.Warnw("User counts corrected", "tenants", corrected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package userstats
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/pkg/errors"
+
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+)
+
+const usersTable = "users"
+
+// NewPostgres returns the store, which counts users of the users table by one GROUP BY query.
+func NewPostgres(pool pgxtype.Querier) Store {
+	return &postgres{pool: pool}
+}
+
+type postgres struct {
+	pool pgxtype.Querier
+}
+
+func (p *postgres) Count(ctx context.Context) ([]Count, error) {
+	query, args, err := squirrel.Select(
+		"COALESCE(attributes ->> '"+passwordPkg.TenantAttribute+"', '')",
+		"status",
+		"count(*)",
+	).
+		From(usersTable).
+		GroupBy("1", "2").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "user stats count")
+	}
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "user stats count")
+	}
+	defer rows.Close()
+	var counts []Count
+	for rows.Next() {
+		var count Count
+		if err = rows.Scan(&count.Tenant, &count.Status, &count.Users); err != nil {
+			return nil, errors.Wrap(err, "user stats count")
+		}
+		counts = append(counts, count)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "user stats count")
+	}
+	return counts, nil
+}
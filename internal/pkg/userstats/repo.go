@@ -0,0 +1,42 @@
+package userstats
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	tenantPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/tenant"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+// NewRepo returns the store, which counts users of the repository snapshot. It reads all users,
+// so it is used for the local storage and shards, which have no single users table to query.
+func NewRepo(data repoPkg.Interface) Store {
+	return &repo{data: data}
+}
+
+type repo struct {
+	data repoPkg.Interface
+}
+
+type key struct {
+	tenant string
+	status string
+}
+
+func (r *repo) Count(ctx context.Context) ([]Count, error) {
+	users := make(map[key]int64)
+	if err := r.data.UserSnapshot(ctx, func(user models.User) error {
+		users[key{tenant: tenantPkg.Of(user), status: user.Status}]++
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "user stats snapshot")
+	}
+
+	counts := make([]Count, 0, len(users))
+	for k, n := range users {
+		counts = append(counts, Count{Tenant: k.tenant, Status: k.status, Users: n})
+	}
+	return counts, nil
+}
@@ -0,0 +1,156 @@
+// Package userstats refreshes gauges of the number of users in total, by tenant and by status,
+// so dashboards read counts of the last refresh instead of counting users on every scrape.
+package userstats
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const (
+	defaultInterval   = time.Minute
+	defaultMaxTenants = 100
+
+	// NoTenant labels users without the tenant attribute.
+	NoTenant = "none"
+	// OtherTenants labels users of tenants beyond the largest MaxTenants ones.
+	OtherTenants = "other"
+)
+
+// Config of the user stats.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval between refreshes, default is 1m.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxTenants limits the cardinality of the tenant gauge, users of smaller tenants
+	// are counted by OtherTenants. Default is 100.
+	MaxTenants int `mapstructure:"max_tenants"`
+}
+
+// Count is the number of users of the tenant in the status.
+type Count struct {
+	Tenant string
+	Status string
+	Users  int64
+}
+
+// Store counts users grouped by tenant and status by one aggregating pass.
+type Store interface {
+	Count(ctx context.Context) ([]Count, error)
+}
+
+// Stats are the counts of the last refresh.
+type Stats struct {
+	Total    int64
+	Tenants  map[string]int64
+	Statuses map[string]int64
+}
+
+// Collector refreshes the gauges by the counts of the store.
+type Collector struct {
+	store  Store
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+}
+
+func New(store Store, cfg Config, clk clock.Clock, logger *zap.SugaredLogger) *Collector {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.MaxTenants <= 0 {
+		cfg.MaxTenants = defaultMaxTenants
+	}
+	return &Collector{
+		store:  store,
+		cfg:    cfg,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Refresh counts users and sets the gauges. Gauges keep the previous counts on errors.
+func (c *Collector) Refresh(ctx context.Context) (Stats, error) {
+	counts, err := c.store.Count(ctx)
+	if err != nil {
+		return Stats{}, errors.Wrap(err, "user stats count")
+	}
+
+	stats := Stats{
+		Tenants: make(map[string]int64),
+		// known statuses are reported without users too, so their series don't disappear
+		Statuses: map[string]int64{
+			models.StatusActive:   0,
+			models.StatusDisabled: 0,
+			models.StatusPending:  0,
+		},
+	}
+	for _, count := range counts {
+		tenant := count.Tenant
+		if tenant == "" {
+			tenant = NoTenant
+		}
+		stats.Total += count.Users
+		stats.Tenants[tenant] += count.Users
+		stats.Statuses[count.Status] += count.Users
+	}
+	stats.Tenants = fold(stats.Tenants, c.cfg.MaxTenants)
+
+	counter.UsersTotal.Set(stats.Total)
+	counter.UsersByTenant.Replace(stats.Tenants)
+	counter.UsersByStatus.Replace(stats.Statuses)
+	return stats, nil
+}
+
+// Run refreshes the gauges every interval until ctx is done.
+func (c *Collector) Run(ctx context.Context) {
+	c.logger.Infow("Start user stats", "interval", c.cfg.Interval, "max_tenants", c.cfg.MaxTenants)
+	ticker := c.clock.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Errorf("user stats refresh: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			c.logger.Infoln("User stats stopped")
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// fold keeps the largest tenants up to the limit and counts users of others by OtherTenants.
+func fold(tenants map[string]int64, limit int) map[string]int64 {
+	if len(tenants) <= limit {
+		return tenants
+	}
+	names := make([]string, 0, len(tenants))
+	for name := range tenants {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tenants[names[i]] != tenants[names[j]] {
+			return tenants[names[i]] > tenants[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	folded := make(map[string]int64, limit+1)
+	for i, name := range names {
+		if i < limit {
+			folded[name] += tenants[name]
+			continue
+		}
+		folded[OtherTenants] += tenants[name]
+	}
+	return folded
+}
@@ -0,0 +1,110 @@
+package userstats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+var users = []models.User{
+	modeltest.From(modeltest.Arnold()).WithAttribute("tenant", "acme").WithStatus(models.StatusActive).Build(),
+	modeltest.From(modeltest.Boris()).WithAttribute("tenant", "acme").WithStatus(models.StatusDisabled).Build(),
+	modeltest.NewUser().WithAttribute("tenant", "initech").WithStatus(models.StatusActive).Build(),
+	modeltest.NewUser().WithStatus(models.StatusActive).Build(),
+}
+
+func TestCollector_Refresh(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name       string
+		maxTenants int
+		snapErr    error
+		expStats   Stats
+		expErr     error
+	}{
+		{
+			name: "success",
+			expStats: Stats{
+				Total:    4,
+				Tenants:  map[string]int64{"acme": 2, "initech": 1, NoTenant: 1},
+				Statuses: map[string]int64{models.StatusActive: 3, models.StatusDisabled: 1, models.StatusPending: 0},
+			},
+		},
+		{
+			name:       "success, smaller tenants folded",
+			maxTenants: 1,
+			expStats: Stats{
+				Total:    4,
+				Tenants:  map[string]int64{"acme": 2, OtherTenants: 2},
+				Statuses: map[string]int64{models.StatusActive: 3, models.StatusDisabled: 1, models.StatusPending: 0},
+			},
+		},
+		{
+			name:    "failed, snapshot",
+			snapErr: errorsPkg.ErrUnexpected,
+			expErr:  errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := repoMockPkg.NewMockInterface(ctl)
+			data.EXPECT().UserSnapshot(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, fn func(models.User) error) error {
+					if c.snapErr != nil {
+						return c.snapErr
+					}
+					for _, user := range users {
+						if err := fn(user); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+
+			collector := New(NewRepo(data), Config{MaxTenants: c.maxTenants}, clock.Real(), loggerPkg.NewFatal())
+			stats, err := collector.Refresh(context.Background())
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expStats, stats)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, c.expStats.Total, counter.UsersTotal.Value())
+			assert.Equal(t, c.expStats.Tenants["acme"], counter.UsersByTenant.Value("acme"))
+			assert.Equal(t, c.expStats.Statuses[models.StatusDisabled], counter.UsersByStatus.Value(models.StatusDisabled))
+		})
+	}
+}
+
+func TestPostgres_Count(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectQuery("SELECT COALESCE(attributes ->> 'tenant', ''), status, count(*) FROM users GROUP BY 1, 2").
+		WillReturnRows(pgxmock.NewRows([]string{"tenant", "status", "count"}).
+			AddRow("acme", models.StatusActive, int64(2)).
+			AddRow("", models.StatusPending, int64(1)))
+
+	counts, err := NewPostgres(mock).Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Count{
+		{Tenant: "acme", Status: models.StatusActive, Users: 2},
+		{Tenant: "", Status: models.StatusPending, Users: 1},
+	}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
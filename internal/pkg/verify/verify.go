@@ -0,0 +1,180 @@
+//go:generate mockgen -source=verify.go -destination=./mock/verify_mock.go -package=mock
+
+// Package verify checks invariants of stored users, which are not enforced by every backend,
+// and repairs violations of the known classes.
+package verify
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+// Classes of violations.
+const (
+	// ClassMissingField is a user without ID, password, creation time or status.
+	// Missing status is repaired by the default active one.
+	ClassMissingField = "missing_field"
+	// ClassUnknownStatus is a status out of active, disabled and pending.
+	ClassUnknownStatus = "unknown_status"
+	// ClassDuplicateEmail is an email of several users, letter case is ignored.
+	ClassDuplicateEmail = "duplicate_email"
+	// ClassMalformedHash is a password looking like a bcrypt hash, which bcrypt can't parse,
+	// so it never matches. It is repaired by expiring the password.
+	ClassMalformedHash = "malformed_hash"
+)
+
+// bcryptPrefix starts bcrypt hashes of all versions.
+const bcryptPrefix = "$2"
+
+// maxReported limits violations kept by the report, all of them are counted.
+const maxReported = 1000
+
+// Config of the verification on startup.
+type Config struct {
+	OnStartup bool `mapstructure:"on_startup"`
+	// Repair violations of the known classes on startup.
+	Repair bool `mapstructure:"repair"`
+}
+
+// Violation of the user invariant.
+type Violation struct {
+	Class    string
+	Name     string
+	Detail   string
+	Repaired bool
+
+	repairable bool
+}
+
+// Report of the verification, Violations are limited, Found counts all of them.
+type Report struct {
+	Checked    uint64
+	Found      uint64
+	Repaired   uint64
+	Violations []Violation
+}
+
+type Interface interface {
+	// Verify checks all users of a consistent snapshot, violations of the known classes
+	// are repaired, if repair is set.
+	Verify(ctx context.Context, repair bool) (Report, error)
+}
+
+// New returns verifier of users of the repository. Repairs are written to the repository
+// directly, so cached users are updated, when they expire.
+func New(data repoPkg.Interface, logger *zap.SugaredLogger) Interface {
+	return &verifier{
+		data:   data,
+		logger: logger,
+	}
+}
+
+type verifier struct {
+	data   repoPkg.Interface
+	logger *zap.SugaredLogger
+}
+
+func (v *verifier) Verify(ctx context.Context, repair bool) (Report, error) {
+	var (
+		report  Report
+		repairs []models.User
+	)
+	add := func(violation Violation) {
+		report.Found++
+		counter.VerifyViolations.Inc(violation.Class)
+		if len(report.Violations) < maxReported {
+			report.Violations = append(report.Violations, violation)
+		}
+	}
+
+	emails := make(map[string]string)
+	err := v.data.UserSnapshot(ctx, func(user models.User) error {
+		report.Checked++
+		fixed, violations := check(user)
+		if email := strings.ToLower(strings.TrimSpace(user.Email)); email != "" {
+			if first, ok := emails[email]; ok {
+				violations = append(violations, Violation{
+					Class:  ClassDuplicateEmail,
+					Name:   user.Name,
+					Detail: "email of user [" + first + "]",
+				})
+			} else {
+				emails[email] = user.Name
+			}
+		}
+
+		repaired := false
+		for _, violation := range violations {
+			violation.Repaired = violation.repairable && repair
+			repaired = repaired || violation.Repaired
+			add(violation)
+		}
+		if repaired {
+			repairs = append(repairs, fixed)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, errors.Wrap(err, "verify users")
+	}
+
+	// users are written after the snapshot is read, repairs are idempotent
+	now := time.Now().Unix()
+	for _, user := range repairs {
+		user.UpdatedAt = now
+		if err = v.data.UserUpdate(ctx, user); err != nil {
+			return report, errors.Wrapf(err, "repair user [%s]", user.Name)
+		}
+		report.Repaired++
+	}
+
+	v.logger.Infow("users verified", "checked", report.Checked, "violations", report.Found, "repaired", report.Repaired)
+	for _, violation := range report.Violations {
+		v.logger.Warnw("user invariant violated", "class", violation.Class, "name", violation.Name,
+			"detail", violation.Detail, "repaired", violation.Repaired)
+	}
+	return report, nil
+}
+
+// check returns violations of the user and the user with repairable ones fixed.
+func check(user models.User) (models.User, []Violation) {
+	var violations []Violation
+	missing := func(field string) {
+		violations = append(violations, Violation{Class: ClassMissingField, Name: user.Name, Detail: "field: [" + field + "] is empty"})
+	}
+	if user.ID == "" {
+		missing("id")
+	}
+	if user.Password == "" {
+		missing("password")
+	}
+	if user.CreatedAt == 0 {
+		missing("created_at")
+	}
+
+	fixed := user
+	switch {
+	case user.Status == "":
+		fixed.Status = models.StatusActive
+		violations = append(violations, Violation{Class: ClassMissingField, Name: user.Name, Detail: "field: [status] is empty", repairable: true})
+	case models.ValidateStatus(user.Status) != nil:
+		violations = append(violations, Violation{Class: ClassUnknownStatus, Name: user.Name, Detail: "status [" + user.Status + "]"})
+	}
+
+	if strings.HasPrefix(user.Password, bcryptPrefix) && !passwordPkg.Hashed(user.Password) {
+		if fixed.PasswordExpiresAt == 0 || fixed.PasswordExpiresAt > time.Now().Unix() {
+			fixed.PasswordExpiresAt = time.Now().Unix()
+		}
+		violations = append(violations, Violation{Class: ClassMalformedHash, Name: user.Name, Detail: "field: [password] is not a valid bcrypt hash", repairable: true})
+	}
+	return fixed, violations
+}
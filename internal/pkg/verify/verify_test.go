@@ -0,0 +1,108 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const hash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+func TestVerifier_Verify(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	valid := modeltest.NewUser().WithPassword(hash).WithEmail("ivan@email.com").Build()
+	duplicate := modeltest.Boris()
+	duplicate.Email = " Ivan@Email.com"
+	noStatus := modeltest.Arnold()
+	noStatus.Status = ""
+	malformed := modeltest.NewUser().WithName("petr").WithEmail("petr@email.com").WithPassword("$2a$10$broken").Build()
+	unknown := modeltest.NewUser().WithName("olga").WithEmail("olga@email.com").WithPassword("secret").Build()
+	unknown.ID, unknown.Status = "", "deleted"
+	users := []models.User{valid, duplicate, noStatus, malformed, unknown}
+
+	cases := []struct {
+		name          string
+		repair        bool
+		updateErr     error
+		expViolations []Violation
+		expRepaired   uint64
+		expErr        error
+	}{
+		{
+			name: "success, report only",
+			expViolations: []Violation{
+				{Class: ClassDuplicateEmail, Name: duplicate.Name, Detail: "email of user [" + valid.Name + "]"},
+				{Class: ClassMissingField, Name: noStatus.Name, Detail: "field: [status] is empty", repairable: true},
+				{Class: ClassMalformedHash, Name: malformed.Name, Detail: "field: [password] is not a valid bcrypt hash", repairable: true},
+				{Class: ClassMissingField, Name: unknown.Name, Detail: "field: [id] is empty"},
+				{Class: ClassUnknownStatus, Name: unknown.Name, Detail: "status [deleted]"},
+			},
+		},
+		{
+			name:   "success, repair",
+			repair: true,
+			expViolations: []Violation{
+				{Class: ClassDuplicateEmail, Name: duplicate.Name, Detail: "email of user [" + valid.Name + "]"},
+				{Class: ClassMissingField, Name: noStatus.Name, Detail: "field: [status] is empty", Repaired: true, repairable: true},
+				{Class: ClassMalformedHash, Name: malformed.Name, Detail: "field: [password] is not a valid bcrypt hash", Repaired: true, repairable: true},
+				{Class: ClassMissingField, Name: unknown.Name, Detail: "field: [id] is empty"},
+				{Class: ClassUnknownStatus, Name: unknown.Name, Detail: "status [deleted]"},
+			},
+			expRepaired: 2,
+		},
+		{
+			name:      "failed, repair error",
+			repair:    true,
+			updateErr: errorsPkg.ErrUnexpected,
+			expErr:    errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo := repoMockPkg.NewMockInterface(ctl)
+			repo.EXPECT().UserSnapshot(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, fn func(models.User) error) error {
+					for _, user := range users {
+						if err := fn(user); err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Times(1)
+			var repaired []models.User
+			if c.repair {
+				repo.EXPECT().UserUpdate(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user models.User) error {
+						repaired = append(repaired, user)
+						return c.updateErr
+					}).MinTimes(1)
+			}
+
+			report, err := New(repo, loggerPkg.NewFatal()).Verify(context.Background(), c.repair)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr != nil {
+				return
+			}
+			assert.Equal(t, uint64(len(users)), report.Checked)
+			assert.Equal(t, uint64(len(c.expViolations)), report.Found)
+			assert.Equal(t, c.expViolations, report.Violations)
+			assert.Equal(t, c.expRepaired, report.Repaired)
+			if c.repair {
+				assert.Equal(t, models.StatusActive, repaired[0].Status)
+				assert.NotZero(t, repaired[1].PasswordExpiresAt)
+				assert.NotZero(t, repaired[1].UpdatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package bloom
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	bloomModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	bloomPkg "gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+)
+
+const (
+	rebuildPageSize = 1000
+	rebuildTimeout  = 1 * time.Minute
+	defaultRebuild  = 10 * time.Minute
+)
+
+// Loader returns the filter state of a peer, its checksum must be verified.
+type Loader func(ctx context.Context) (bloomPkg.State, error)
+
+// New wraps repository with bloom filter of existing user names.
+// UserGet for a name missing in the filter returns ErrUserNotFound without repository call.
+// Until the first rebuild is finished all calls go to repository. If load is set, the first
+// filter is loaded from a peer instead and it is rebuilt from the repository, if the load fails.
+func New(ctx context.Context, data repoPkg.Interface, cfg bloomModels.Config, load Loader, logger *zap.SugaredLogger) repoPkg.Interface {
+	logger.Infoln("With bloom filter started")
+	if cfg.Rebuild <= 0 {
+		cfg.Rebuild = defaultRebuild
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r := &repo{
+		data:   data,
+		cfg:    cfg,
+		load:   load,
+		cancel: cancel,
+		logger: logger,
+	}
+	go r.run(ctx)
+	return r
+}
+
+// Names is implemented by the repository of New, names of users written bypassing it,
+// e.g. read by CDC, are added to the filter.
+type Names interface {
+	AddName(name string)
+}
+
+// Exporter is implemented by the repository of New, the filter is exported to new replicas.
+type Exporter interface {
+	// FilterState returns the state of the current filter, false if it is not built yet.
+	FilterState() (bloomPkg.State, bool)
+}
+
+type repo struct {
+	data   repoPkg.Interface
+	cfg    bloomModels.Config
+	load   Loader
+	cancel context.CancelFunc
+	logger *zap.SugaredLogger
+
+	mu     sync.RWMutex
+	filter *bloomPkg.Filter
+	// pending is a filter built by reindex job
+	pending *bloomPkg.Filter
+	// rebuilding is a filter built by the periodic rebuild
+	rebuilding *bloomPkg.Filter
+	// loading collects names added while the filter is loaded from a peer
+	loading *bloomPkg.Filter
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	if err := r.data.UserCreate(ctx, user); err != nil {
+		return err
+	}
+	r.AddName(user.Name)
+	return nil
+}
+
+// AddName adds the name to the filter and the ones being rebuilt.
+func (r *repo) AddName(name string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.filter != nil {
+		r.filter.Add(name)
+	}
+	if r.pending != nil {
+		r.pending.Add(name)
+	}
+	if r.rebuilding != nil {
+		r.rebuilding.Add(name)
+	}
+	if r.loading != nil {
+		r.loading.Add(name)
+	}
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	return r.data.UserUpdate(ctx, user)
+}
+
+// UserDelete can't remove name from the filter, stale names are dropped by the next rebuild.
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	return r.data.UserDelete(ctx, name)
+}
+
+// UserRename adds the new name to the filter, the old one is dropped by the next rebuild.
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	if err := r.data.UserRename(ctx, oldName, newName); err != nil {
+		return err
+	}
+	r.AddName(newName)
+	return nil
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	if f := r.current(); f != nil && !f.Test(name) {
+		counter.BloomSkip.Inc()
+		return models.User{}, apperr.WrapKey(errorsPkg.ErrUserNotFound, "repo.UserGet", "name", name)
+	}
+	return r.data.UserGet(ctx, name)
+}
+
+// UserGetByID is not filtered, since the filter keeps names only.
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	return r.data.UserGetByID(ctx, id)
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	return r.data.UserList(ctx, order, limit, offset, where)
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	return r.data.UserSnapshot(ctx, fn)
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	return r.data.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	return r.data.UserTombstonesPrune(ctx, before)
+}
+
+func (r *repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	return r.data.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (int64, error) {
+	return r.data.UserCountsReconcile(ctx)
+}
+
+func (r *repo) Close() {
+	r.cancel()
+	r.data.Close()
+}
+
+func (r *repo) current() *bloomPkg.Filter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.filter
+}
+
+// FilterState returns the state of the current filter, false if it is not built yet.
+func (r *repo) FilterState() (bloomPkg.State, bool) {
+	f := r.current()
+	if f == nil {
+		return bloomPkg.State{}, false
+	}
+	return f.State(), true
+}
+
+func (r *repo) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Rebuild)
+	defer ticker.Stop()
+
+	// the loaded filter is rebuilt by the ticker only
+	loaded := r.load != nil && r.warm(ctx)
+	for {
+		if !loaded {
+			if err := r.rebuild(ctx); err != nil {
+				r.logger.Errorf("bloom filter rebuild: %v", err)
+			}
+		}
+		loaded = false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// warm loads the filter from a peer, names added meanwhile are merged to it.
+// The filter of other size or number of hashes, e.g. of a peer with other config, is rejected.
+func (r *repo) warm(ctx context.Context) bool {
+	r.mu.Lock()
+	r.loading = bloomPkg.New(r.cfg.Expected, r.cfg.FP)
+	r.mu.Unlock()
+
+	var filter *bloomPkg.Filter
+	state, err := r.load(ctx)
+	if err == nil {
+		filter, err = bloomPkg.FromState(state)
+	}
+
+	r.mu.Lock()
+	if err == nil {
+		err = filter.Union(r.loading)
+	}
+	if err == nil {
+		r.filter = filter
+	}
+	r.loading = nil
+	r.mu.Unlock()
+
+	if err != nil {
+		counter.BloomLoadFailed.Inc()
+		r.logger.Warnf("bloom filter load, rebuild from the database: %v", err)
+		return false
+	}
+	counter.BloomLoaded.Inc()
+	r.logger.Infoln("bloom filter loaded from a peer")
+	return true
+}
+
+// rebuild fills new filter with all user names and replaces the current one.
+// Names created during the rebuild are added to both filters, so users created after
+// their page was read are not lost by the swap. Pages are read by name, the way ListAfter
+// does, so users deleted during the rebuild don't shift the following pages.
+func (r *repo) rebuild(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(repoPkg.WithoutScanBudget(ctx), rebuildTimeout)
+	defer cancel()
+
+	next := bloomPkg.New(r.cfg.Expected, r.cfg.FP)
+	r.mu.Lock()
+	r.rebuilding = next
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.rebuilding = nil
+		r.mu.Unlock()
+	}()
+	var where filter.Expr
+	for {
+		users, err := r.data.UserList(ctx, false, rebuildPageSize, 0, where)
+		if err != nil {
+			return errors.Wrap(err, "user list")
+		}
+		for _, user := range users {
+			next.Add(user.Name)
+		}
+		if len(users) < rebuildPageSize {
+			break
+		}
+		where = filter.Cond{Field: filter.FieldName, Op: filter.OpGt, Value: users[len(users)-1].Name}
+	}
+
+	r.mu.Lock()
+	r.filter, r.rebuilding = next, nil
+	r.mu.Unlock()
+	counter.BloomRebuild.Inc()
+	r.logger.Debugln("bloom filter rebuilt")
+	return nil
+}
+
+func (r *repo) IndexName() string {
+	return "bloom"
+}
+
+func (r *repo) Begin(resume bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if resume && r.pending != nil {
+		return true
+	}
+	r.pending = bloomPkg.New(r.cfg.Expected, r.cfg.FP)
+	return false
+}
+
+func (r *repo) Add(users []models.User) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range users {
+		r.pending.Add(user.Name)
+	}
+}
+
+func (r *repo) Commit() {
+	r.mu.Lock()
+	r.filter, r.pending = r.pending, nil
+	r.mu.Unlock()
+	counter.BloomRebuild.Inc()
+}
@@ -0,0 +1,144 @@
+package bloom
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	bloomModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/bloom/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	bloomPkg "gitlab.ozon.dev/iTukaev/homework/pkg/bloom"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRepo_Load(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cfg := bloomModels.Config{Expected: 100, FP: 0.01, Rebuild: time.Hour}
+	peer := bloomPkg.New(cfg.Expected, cfg.FP)
+	peer.Add("ivan")
+
+	cases := []struct {
+		name       string
+		state      bloomPkg.State
+		loadErr    error
+		expRebuild bool
+	}{
+		{
+			name:  "loaded from peer",
+			state: peer.State(),
+		},
+		{
+			name:       "failed load, rebuilt",
+			loadErr:    errors.New("no peers"),
+			expRebuild: true,
+		},
+		{
+			name:       "other config of peer, rebuilt",
+			state:      bloomPkg.New(1000, cfg.FP).State(),
+			expRebuild: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := repoMockPkg.NewMockInterface(ctl)
+			rebuilt := make(chan struct{})
+			if c.expRebuild {
+				data.EXPECT().UserList(gomock.Any(), false, uint64(rebuildPageSize), uint64(0), nil).
+					DoAndReturn(func(context.Context, bool, uint64, uint64, filter.Expr) ([]models.User, error) {
+						close(rebuilt)
+						return []models.User{{Name: "ivan"}}, nil
+					}).Times(1)
+			}
+			data.EXPECT().Close().Times(1)
+
+			r := New(context.Background(), data, cfg, func(context.Context) (bloomPkg.State, error) {
+				return c.state, c.loadErr
+			}, loggerPkg.NewFatal()).(*repo)
+			defer r.Close()
+			if c.expRebuild {
+				<-rebuilt
+			}
+			assert.Eventually(t, func() bool {
+				_, built := r.FilterState()
+				return built
+			}, time.Second, time.Millisecond)
+
+			_, err := r.UserGet(context.Background(), "petr")
+			assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+		})
+	}
+}
+
+func TestRepo_AddNameDuringRebuild(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cfg := bloomModels.Config{Expected: 100, FP: 0.01, Rebuild: time.Hour}
+	data := repoMockPkg.NewMockInterface(ctl)
+	r := &repo{data: data, cfg: cfg, logger: loggerPkg.NewFatal()}
+	// the user is created after its page was read, but before the filters are swapped
+	data.EXPECT().UserList(gomock.Any(), false, uint64(rebuildPageSize), uint64(0), nil).
+		DoAndReturn(func(context.Context, bool, uint64, uint64, filter.Expr) ([]models.User, error) {
+			r.AddName("petr")
+			return []models.User{{Name: "ivan"}}, nil
+		}).Times(1)
+	data.EXPECT().UserGet(gomock.Any(), "petr").Return(models.User{Name: "petr"}, nil).Times(1)
+
+	assert.NoError(t, r.rebuild(context.Background()))
+	user, err := r.UserGet(context.Background(), "petr")
+	assert.NoError(t, err)
+	assert.Equal(t, "petr", user.Name)
+}
+
+func TestRepo_DeleteDuringRebuild(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cfg := bloomModels.Config{Expected: 3 * rebuildPageSize, FP: 0.01, Rebuild: time.Hour}
+	data := repoMockPkg.NewMockInterface(ctl)
+	r := &repo{data: data, cfg: cfg, logger: loggerPkg.NewFatal()}
+
+	names := make([]string, 0, 2*rebuildPageSize)
+	for i := 0; i < cap(names); i++ {
+		names = append(names, fmt.Sprintf("user%05d", i))
+	}
+	stored := append([]string(nil), names...)
+	calls := 0
+	// the first user is deleted after the first page was read, so offsets of all the following users shift
+	data.EXPECT().UserList(gomock.Any(), false, uint64(rebuildPageSize), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ bool, limit, offset uint64, where filter.Expr) ([]models.User, error) {
+			calls++
+			var after string
+			if cond, ok := where.(filter.Cond); ok {
+				assert.Equal(t, filter.FieldName, cond.Field)
+				assert.Equal(t, filter.OpGt, cond.Op)
+				after = cond.Value.(string)
+			}
+			var page []models.User
+			for i, name := range stored {
+				if name > after && uint64(i) >= offset*limit && uint64(len(page)) < limit {
+					page = append(page, models.User{Name: name})
+				}
+			}
+			if calls == 1 {
+				stored = stored[1:]
+			}
+			return page, nil
+		}).MinTimes(2)
+
+	assert.NoError(t, r.rebuild(context.Background()))
+	for _, name := range names[1:] {
+		assert.True(t, r.filter.Test(name), name)
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Config struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Expected uint64        `mapstructure:"expected"`
+	FP       float64       `mapstructure:"fp"`
+	Rebuild  time.Duration `mapstructure:"rebuild"`
+}
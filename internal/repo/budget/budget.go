@@ -0,0 +1,115 @@
+// Package budget guards the repository from lists reading too many rows, e.g. deep pages
+// or filters without an index, which turn into scans of the whole table.
+package budget
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+// Modes of lists over the budget.
+const (
+	// ModeReject fails the list with ErrScanBudget.
+	ModeReject = "reject"
+	// ModeTruncate lowers the limit of the first page to the budget and reports the page
+	// as partial. Deeper pages are rejected, since their offset alone is over the budget
+	// or a shorter page would shift the following ones.
+	ModeTruncate = "truncate"
+)
+
+// Config of the scan budget.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxRows is a maximal number of rows a list may read.
+	MaxRows uint64 `mapstructure:"max_rows"`
+	// Mode is reject or truncate, empty value rejects.
+	Mode string `mapstructure:"mode"`
+	// Explain estimates rows of filtered lists by the query plan of the backend,
+	// otherwise rows of the requested pages are counted only.
+	Explain bool `mapstructure:"explain"`
+}
+
+// Estimator is implemented by backends, which estimate rows read by the list, e.g. by EXPLAIN.
+type Estimator interface {
+	UserListScan(ctx context.Context, limit, offset uint64, where filter.Expr) (uint64, error)
+}
+
+// New wraps repository, lists estimated to read more than cfg.MaxRows rows are rejected
+// or truncated. Lists of contexts made by repo.WithoutScanBudget are not limited.
+func New(data repoPkg.Interface, cfg Config, logger *zap.SugaredLogger) repoPkg.Interface {
+	r := &repo{
+		Interface: data,
+		cfg:       cfg,
+		logger:    logger,
+	}
+	if estimator, ok := data.(Estimator); ok && cfg.Explain {
+		r.estimator = estimator
+	}
+	logger.Infof("With scan budget started, max rows %d, mode %s, explain %t", cfg.MaxRows, cfg.Mode, r.estimator != nil)
+	return r
+}
+
+type repo struct {
+	repoPkg.Interface
+	cfg       Config
+	estimator Estimator
+	logger    *zap.SugaredLogger
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	if repoPkg.ScanBudgetExempt(ctx) {
+		return r.Interface.UserList(ctx, order, limit, offset, where)
+	}
+	rows, err := r.scan(ctx, limit, offset, where)
+	if err != nil {
+		return nil, err
+	}
+	if rows <= r.cfg.MaxRows {
+		return r.Interface.UserList(ctx, order, limit, offset, where)
+	}
+
+	if r.cfg.Mode == ModeTruncate && offset == 0 && limit > r.cfg.MaxRows {
+		truncated, err := r.scan(ctx, r.cfg.MaxRows, 0, where)
+		if err != nil {
+			return nil, err
+		}
+		if truncated <= r.cfg.MaxRows {
+			counter.ScanBudget.Inc("truncated")
+			if partial := repoPkg.PartialFromContext(ctx); partial != nil {
+				partial.Skip("scan budget")
+			}
+			r.logger.Warnw("list truncated by scan budget", "limit", limit, "rows", rows, "max_rows", r.cfg.MaxRows)
+			return r.Interface.UserList(ctx, order, r.cfg.MaxRows, 0, where)
+		}
+	}
+	counter.ScanBudget.Inc("rejected")
+	r.logger.Warnw("list rejected by scan budget", "limit", limit, "offset", offset, "where", where,
+		"rows", rows, "max_rows", r.cfg.MaxRows)
+	return nil, errors.Wrapf(errorsPkg.ErrScanBudget, "list estimated to read %d rows, budget is %d", rows, r.cfg.MaxRows)
+}
+
+// scan returns rows estimated to be read by the list. Offset is a page number, so rows
+// of all pages up to the requested one are read.
+func (r *repo) scan(ctx context.Context, limit, offset uint64, where filter.Expr) (uint64, error) {
+	if r.estimator == nil || where == nil {
+		return (offset + 1) * limit, nil
+	}
+	rows, err := r.estimator.UserListScan(ctx, limit, offset, where)
+	if err != nil {
+		return 0, errors.Wrap(err, "scan budget: estimate")
+	}
+	return rows, nil
+}
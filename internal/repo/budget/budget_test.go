@@ -0,0 +1,119 @@
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+// estimated is a backend, which plans estimate the filtered lists to read rows.
+type estimated struct {
+	*repoMockPkg.MockInterface
+	rows uint64
+}
+
+func (e estimated) UserListScan(context.Context, uint64, uint64, filter.Expr) (uint64, error) {
+	return e.rows, nil
+}
+
+func TestRepo_UserList(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	where := filter.ListParams(nil, models.StatusDisabled)
+
+	cases := []struct {
+		name       string
+		cfg        Config
+		limit      uint64
+		offset     uint64
+		where      filter.Expr
+		estimated  uint64
+		exempt     bool
+		expLimit   uint64
+		expPartial bool
+		expErr     error
+	}{
+		{
+			name:     "success, within the budget",
+			cfg:      Config{MaxRows: 100},
+			limit:    50,
+			offset:   1,
+			expLimit: 50,
+		},
+		{
+			name:   "failed, deep page",
+			cfg:    Config{MaxRows: 100},
+			limit:  50,
+			offset: 2,
+			expErr: errorsPkg.ErrScanBudget,
+		},
+		{
+			name:     "success, exempt context",
+			cfg:      Config{MaxRows: 100},
+			limit:    50,
+			offset:   2,
+			exempt:   true,
+			expLimit: 50,
+		},
+		{
+			name:       "success, first page truncated",
+			cfg:        Config{MaxRows: 100, Mode: ModeTruncate},
+			limit:      500,
+			expLimit:   100,
+			expPartial: true,
+		},
+		{
+			name:   "failed, deep page is not truncated",
+			cfg:    Config{MaxRows: 100, Mode: ModeTruncate},
+			limit:  50,
+			offset: 2,
+			expErr: errorsPkg.ErrScanBudget,
+		},
+		{
+			name:      "failed, filter estimated by the plan",
+			cfg:       Config{MaxRows: 100, Explain: true},
+			limit:     10,
+			where:     where,
+			estimated: 5000,
+			expErr:    errorsPkg.ErrScanBudget,
+		},
+		{
+			name:      "success, filter served by an index",
+			cfg:       Config{MaxRows: 100, Explain: true},
+			limit:     50,
+			where:     where,
+			estimated: 20,
+			expLimit:  50,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := repoMockPkg.NewMockInterface(ctl)
+			if c.expErr == nil {
+				data.EXPECT().UserList(gomock.Any(), false, c.expLimit, c.offset, c.where).Return(nil, nil)
+			}
+			ctx, partial := repoPkg.WithPartial(context.Background())
+			if c.exempt {
+				ctx = repoPkg.WithoutScanBudget(ctx)
+			}
+
+			r := New(estimated{MockInterface: data, rows: c.estimated}, c.cfg, loggerPkg.NewFatal())
+			_, err := r.UserList(ctx, false, c.limit, c.offset, c.where)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr != nil {
+				assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+			}
+			assert.Equal(t, c.expPartial, partial.IsPartial())
+		})
+	}
+}
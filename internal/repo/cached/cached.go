@@ -0,0 +1,213 @@
+// Package cached composes a cache in front of a persistent repository. Users are read through
+// the cache by name, concurrent misses of the same name or gets of the same ID read the repository
+// once, and writes set or invalidate the cached users. Entries expire by TTL with jitter, so users cached together
+// are not read again at once.
+package cached
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+const (
+	// BackendMemory keeps users in the memory of the replica.
+	BackendMemory = "memory"
+	// BackendRedis shares users between replicas.
+	BackendRedis = "redis"
+
+	defaultTTL    = time.Minute
+	defaultJitter = 0.1
+	// flightTimeout limits the read shared by concurrent gets, it doesn't end with the first caller
+	flightTimeout = 5 * time.Second
+)
+
+// Config of the cache in front of the repository.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend is memory or redis, empty value is memory.
+	Backend string `mapstructure:"backend"`
+	// TTL of cached users, default is 1m.
+	TTL time.Duration `mapstructure:"ttl"`
+	// Jitter is the share of TTL randomly added to or subtracted from TTL of every entry, default is 0.1.
+	Jitter float64 `mapstructure:"jitter"`
+	// WriteThrough sets written users to the cache, otherwise they are invalidated and read by the next get.
+	WriteThrough bool `mapstructure:"write_through"`
+	// MaxEntries limits the memory backend, zero means no limit.
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// Cache keeps users by name. Errors of the cache are logged, the repository is read then.
+type Cache interface {
+	// Get returns the cached user and true, false is returned for a miss.
+	Get(ctx context.Context, name string) (models.User, bool, error)
+	Set(ctx context.Context, user models.User, ttl time.Duration) error
+	Delete(ctx context.Context, names ...string) error
+}
+
+// New wraps repository with the cache.
+func New(data repoPkg.Interface, cache Cache, cfg Config, logger *zap.SugaredLogger) repoPkg.Interface {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.Jitter <= 0 || cfg.Jitter >= 1 {
+		cfg.Jitter = defaultJitter
+	}
+	logger.Infow("With repo cache started", "backend", cfg.Backend, "ttl", cfg.TTL, "write_through", cfg.WriteThrough)
+	return &repo{
+		Interface: data,
+		cache:     cache,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+type repo struct {
+	// writes is a number of writes, users read before a write are not cached after it
+	writes uint64
+	repoPkg.Interface
+	cache  Cache
+	cfg    Config
+	logger *zap.SugaredLogger
+	// gets coalesces concurrent misses of the same name, getsByID gets of the same ID
+	gets     singleflight.Group
+	getsByID singleflight.Group
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	user, ok, err := r.cache.Get(ctx, name)
+	if err != nil {
+		r.logger.Errorf("repo cache get: %v", err)
+	} else if ok {
+		counter.RepoCacheHit.Inc()
+		return user, nil
+	}
+	counter.RepoCacheMiss.Inc()
+
+	return collapse(ctx, &r.gets, name, func(ctx context.Context) (models.User, error) {
+		writes := atomic.LoadUint64(&r.writes)
+		user, err := r.Interface.UserGet(ctx, name)
+		if err != nil || atomic.LoadUint64(&r.writes) != writes {
+			return user, err
+		}
+		if err = r.cache.Set(ctx, user, r.ttl()); err != nil {
+			r.logger.Errorf("repo cache set: %v", err)
+		} else if atomic.LoadUint64(&r.writes) != writes {
+			// the write came between the check and the set, the stale user may replace its entry
+			if err = r.cache.Delete(ctx, name); err != nil {
+				r.logger.Errorf("repo cache delete: %v", err)
+			}
+		}
+		return user, nil
+	})
+}
+
+// UserGetByID is not cached, since writes don't know IDs of renamed and deleted users,
+// concurrent gets of the ID read the repository once.
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	return collapse(ctx, &r.getsByID, id, func(ctx context.Context) (models.User, error) {
+		return r.Interface.UserGetByID(ctx, id)
+	})
+}
+
+// collapse calls get once for concurrent calls of the key. The read keeps values of the first
+// caller's context, but is not canceled with it, so other callers still get the user.
+func collapse(
+	ctx context.Context,
+	group *singleflight.Group,
+	key string,
+	get func(ctx context.Context) (models.User, error),
+) (models.User, error) {
+	ch := group.DoChan(key, func() (interface{}, error) {
+		flightCtx, cancel := context.WithTimeout(detached{ctx}, flightTimeout)
+		defer cancel()
+		return get(flightCtx)
+	})
+	select {
+	case <-ctx.Done():
+		return models.User{}, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			counter.RepoGetsCollapsed.Inc()
+		}
+		return res.Val.(models.User), res.Err
+	}
+}
+
+// detached keeps values of the context without its deadline and cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	err := r.Interface.UserCreate(ctx, user)
+	r.written(ctx, user, err)
+	return err
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	err := r.Interface.UserUpdate(ctx, user)
+	r.written(ctx, user, err)
+	return err
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	err := r.Interface.UserDelete(ctx, name)
+	r.invalidate(ctx, name)
+	return err
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	err := r.Interface.UserRename(ctx, oldName, newName)
+	r.invalidate(ctx, oldName, newName)
+	return err
+}
+
+// written sets the user written successfully with write-through, otherwise the user is invalidated,
+// since a failed write may be applied by the backend still.
+func (r *repo) written(ctx context.Context, user models.User, err error) {
+	if err != nil || !r.cfg.WriteThrough {
+		r.invalidate(ctx, user.Name)
+		return
+	}
+	atomic.AddUint64(&r.writes, 1)
+	r.gets.Forget(user.Name)
+	if err = r.cache.Set(ctx, user, r.ttl()); err != nil {
+		r.logger.Errorf("repo cache set: %v", err)
+		r.invalidate(ctx, user.Name)
+	}
+}
+
+// invalidate removes users from the cache. Reads in flight are forgotten, so following gets
+// don't share users read before the write.
+func (r *repo) invalidate(ctx context.Context, names ...string) {
+	atomic.AddUint64(&r.writes, 1)
+	for _, name := range names {
+		r.gets.Forget(name)
+	}
+	if err := r.cache.Delete(ctx, names...); err != nil {
+		r.logger.Errorf("repo cache delete: %v", err)
+	}
+}
+
+// ttl returns TTL with random jitter.
+func (r *repo) ttl() time.Duration {
+	jitter := (rand.Float64()*2 - 1) * r.cfg.Jitter
+	return r.cfg.TTL + time.Duration(jitter*float64(r.cfg.TTL))
+}
@@ -0,0 +1,194 @@
+package cached
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRepo_UserGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	data := repoMockPkg.NewMockInterface(ctl)
+	data.EXPECT().UserGet(gomock.Any(), "alice").
+		DoAndReturn(func(context.Context, string) (models.User, error) {
+			<-release
+			return models.User{Name: "alice", Email: "alice@example.com"}, nil
+		}).Times(1)
+	data.EXPECT().UserGet(gomock.Any(), "bob").Return(models.User{}, errorsPkg.ErrUserNotFound).Times(2)
+	repo := New(data, NewMemory(Config{}), Config{}, loggerPkg.NewFatal())
+
+	// concurrent misses read the repository once
+	var wg sync.WaitGroup
+	users := make([]models.User, 5)
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			users[i], err = repo.UserGet(ctx, "alice")
+			assert.NoError(t, err)
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, user := range users {
+		assert.Equal(t, "alice@example.com", user.Email)
+	}
+
+	// hits don't read the repository
+	user, err := repo.UserGet(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err = repo.UserGet(ctx, "bob")
+		assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+	}
+}
+
+func TestRepo_UserUpdate(t *testing.T) {
+	cases := []struct {
+		name         string
+		writeThrough bool
+		updateErr    error
+		expReads     int
+	}{
+		{
+			name:     "invalidated",
+			expReads: 2,
+		},
+		{
+			name:         "write-through",
+			writeThrough: true,
+			expReads:     1,
+		},
+		{
+			name:         "failed write is invalidated",
+			writeThrough: true,
+			updateErr:    errorsPkg.ErrUnexpected,
+			expReads:     2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			ctx := context.Background()
+			updated := models.User{Name: "alice", Email: "new@example.com"}
+
+			data := repoMockPkg.NewMockInterface(ctl)
+			data.EXPECT().UserGet(gomock.Any(), "alice").Return(models.User{Name: "alice"}, nil).Times(c.expReads)
+			data.EXPECT().UserUpdate(gomock.Any(), updated).Return(c.updateErr).Times(1)
+			repo := New(data, NewMemory(Config{}), Config{WriteThrough: c.writeThrough}, loggerPkg.NewFatal())
+
+			_, err := repo.UserGet(ctx, "alice")
+			require.NoError(t, err)
+			assert.Equal(t, c.updateErr, repo.UserUpdate(ctx, updated))
+			_, err = repo.UserGet(ctx, "alice")
+			require.NoError(t, err)
+		})
+	}
+}
+
+// setHook runs before the set of the cache.
+type setHook struct {
+	Cache
+	before func()
+}
+
+func (c *setHook) Set(ctx context.Context, user models.User, ttl time.Duration) error {
+	if c.before != nil {
+		c.before()
+		c.before = nil
+	}
+	return c.Cache.Set(ctx, user, ttl)
+}
+
+func TestRepo_UserGetRacingUpdate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+	updated := models.User{Name: "alice", Email: "new@example.com"}
+
+	data := repoMockPkg.NewMockInterface(ctl)
+	gomock.InOrder(
+		data.EXPECT().UserGet(gomock.Any(), "alice").Return(models.User{Name: "alice"}, nil).Times(1),
+		data.EXPECT().UserGet(gomock.Any(), "alice").Return(updated, nil).Times(1),
+	)
+	data.EXPECT().UserUpdate(gomock.Any(), updated).Return(nil).Times(1)
+	cache := &setHook{Cache: NewMemory(Config{})}
+	repo := New(data, cache, Config{}, loggerPkg.NewFatal())
+	// the update is done after the user is read, but before it is set to the cache
+	cache.before = func() {
+		require.NoError(t, repo.UserUpdate(ctx, updated))
+	}
+
+	user, err := repo.UserGet(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, user.Email)
+	user, err = repo.UserGet(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, updated.Email, user.Email, "the stale user is not cached")
+}
+
+func TestRepo_TTL(t *testing.T) {
+	r := New(nil, NewMemory(Config{}), Config{TTL: time.Minute, Jitter: 0.2}, loggerPkg.NewFatal()).(*repo)
+	for i := 0; i < 100; i++ {
+		ttl := r.ttl()
+		assert.GreaterOrEqual(t, ttl, 48*time.Second)
+		assert.LessOrEqual(t, ttl, 72*time.Second)
+	}
+}
+
+func TestRepo_UserGetByID(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	data := repoMockPkg.NewMockInterface(ctl)
+	data.EXPECT().UserGetByID(gomock.Any(), "id-1").
+		DoAndReturn(func(ctx context.Context, _ string) (models.User, error) {
+			close(started)
+			<-release
+			// the read is not canceled with the first caller
+			return models.User{ID: "id-1", Name: "alice"}, ctx.Err()
+		}).Times(1)
+	repo := New(data, NewMemory(Config{}), Config{}, loggerPkg.NewFatal())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := repo.UserGetByID(ctx, "id-1")
+		first <- err
+	}()
+	<-started
+	second := make(chan models.User)
+	go func() {
+		user, err := repo.UserGetByID(context.Background(), "id-1")
+		assert.NoError(t, err)
+		second <- user
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.Equal(t, "alice", (<-second).Name)
+}
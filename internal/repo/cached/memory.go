@@ -0,0 +1,40 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	cachePkg "gitlab.ozon.dev/iTukaev/homework/pkg/cache"
+)
+
+// NewMemory returns the cache of users in the memory of the replica, the least recently used users
+// are evicted over cfg.MaxEntries.
+func NewMemory(cfg Config) Cache {
+	return &memory{
+		users: cachePkg.New(cachePkg.Options[string, models.User]{
+			MaxEntries: cfg.MaxEntries,
+		}),
+	}
+}
+
+type memory struct {
+	users *cachePkg.Cache[string, models.User]
+}
+
+func (m *memory) Get(_ context.Context, name string) (models.User, bool, error) {
+	user, ok := m.users.Get(name)
+	return user, ok, nil
+}
+
+func (m *memory) Set(_ context.Context, user models.User, ttl time.Duration) error {
+	m.users.SetTTL(user.Name, user, ttl)
+	return nil
+}
+
+func (m *memory) Delete(_ context.Context, names ...string) error {
+	for _, name := range names {
+		m.users.Delete(name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// redisPrefix keeps keys apart from the users cached by the service layer
+const redisPrefix = "repo_user_"
+
+// NewRedis returns the cache of users shared by replicas, users are encoded by the codec.
+func NewRedis(client redis.Cmdable, codec codecPkg.Interface) Cache {
+	return &redisCache{
+		client: client,
+		codec:  codec,
+	}
+}
+
+type redisCache struct {
+	client redis.Cmdable
+	codec  codecPkg.Interface
+}
+
+func (c *redisCache) Get(ctx context.Context, name string) (models.User, bool, error) {
+	data, err := c.client.Get(ctx, redisPrefix+name).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.User{}, false, nil
+	}
+	if err != nil {
+		return models.User{}, false, errors.Wrap(err, "redis get")
+	}
+	// payloads of unknown formats are misses, the user is read and set again
+	user, _, err := c.codec.DecodeUser(data)
+	if err != nil {
+		return models.User{}, false, nil
+	}
+	return user, true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, user models.User, ttl time.Duration) error {
+	data, err := c.codec.EncodeUser(user)
+	if err != nil {
+		return errors.Wrap(err, "encode user")
+	}
+	if err = c.client.Set(ctx, redisPrefix+user.Name, data, ttl).Err(); err != nil {
+		return errors.Wrap(err, "redis set")
+	}
+	return nil
+}
+
+func (c *redisCache) Delete(ctx context.Context, names ...string) error {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, redisPrefix+name)
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "redis delete")
+	}
+	return nil
+}
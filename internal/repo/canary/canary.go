@@ -0,0 +1,277 @@
+package canary
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+const (
+	BackendPostgres = "postgres"
+	BackendLocal    = "local"
+
+	defaultTimeout = 2 * time.Second
+)
+
+// Config of the candidate repository rollout.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Candidate is a backend of the new repository, "postgres" or "local".
+	Candidate string `mapstructure:"candidate"`
+	// Percent of reads, which are repeated on the candidate and compared, from 0 to 100.
+	Percent float64 `mapstructure:"percent"`
+	// MirrorWrites applies successful writes to the candidate too, so both hold the same users.
+	MirrorWrites bool `mapstructure:"mirror_writes"`
+	// Timeout of the candidate call.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// New wraps repository, sampled reads are repeated on the candidate in background and
+// results are compared, mismatches are logged and counted in counter.CanaryMismatch by method.
+// Callers always get results of the primary repository.
+func New(primary, candidate repoPkg.Interface, cfg Config, logger *zap.SugaredLogger) repoPkg.Interface {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	logger.Infof("With canary %s repository started, reads %.2f%%, mirror writes %t",
+		cfg.Candidate, cfg.Percent, cfg.MirrorWrites)
+	return &repo{
+		primary:   primary,
+		candidate: candidate,
+		cfg:       cfg,
+		logger:    logger,
+		sample: func() bool {
+			return rand.Float64()*100 < cfg.Percent
+		},
+	}
+}
+
+type repo struct {
+	primary   repoPkg.Interface
+	candidate repoPkg.Interface
+	cfg       Config
+	logger    *zap.SugaredLogger
+	sample    func() bool
+	// compared is called after comparison, it is used by tests only
+	compared func()
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	if err := r.primary.UserCreate(ctx, user); err != nil {
+		return err
+	}
+	r.mirror(ctx, "UserCreate", func(ctx context.Context) error {
+		return r.candidate.UserCreate(ctx, user)
+	})
+	return nil
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	if err := r.primary.UserUpdate(ctx, user); err != nil {
+		return err
+	}
+	r.mirror(ctx, "UserUpdate", func(ctx context.Context) error {
+		return r.candidate.UserUpdate(ctx, user)
+	})
+	return nil
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	if err := r.primary.UserDelete(ctx, name); err != nil {
+		return err
+	}
+	r.mirror(ctx, "UserDelete", func(ctx context.Context) error {
+		return r.candidate.UserDelete(ctx, name)
+	})
+	return nil
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	if err := r.primary.UserRename(ctx, oldName, newName); err != nil {
+		return err
+	}
+	r.mirror(ctx, "UserRename", func(ctx context.Context) error {
+		return r.candidate.UserRename(ctx, oldName, newName)
+	})
+	return nil
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	user, err := r.primary.UserGet(ctx, name)
+	if r.sample() {
+		go func() {
+			got, gotErr := r.run(func(ctx context.Context) (interface{}, error) {
+				return r.candidate.UserGet(ctx, name)
+			})
+			r.compare("UserGet", []models.User{user}, err, got, gotErr, "name", name)
+		}()
+	}
+	return user, err
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	user, err := r.primary.UserGetByID(ctx, id)
+	if r.sample() {
+		go func() {
+			got, gotErr := r.run(func(ctx context.Context) (interface{}, error) {
+				return r.candidate.UserGetByID(ctx, id)
+			})
+			r.compare("UserGetByID", []models.User{user}, err, got, gotErr, "id", id)
+		}()
+	}
+	return user, err
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	users, err := r.primary.UserList(ctx, order, limit, offset, where)
+	if r.sample() {
+		go func() {
+			got, gotErr := r.run(func(ctx context.Context) (interface{}, error) {
+				return r.candidate.UserList(ctx, order, limit, offset, where)
+			})
+			r.compare("UserList", users, err, got, gotErr,
+				"order", order, "limit", limit, "offset", offset, "where", where)
+		}()
+	}
+	return users, err
+}
+
+// UserSnapshot reads the primary repository only.
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	return r.primary.UserSnapshot(ctx, fn)
+}
+
+// UserTombstones reads the primary repository only.
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	return r.primary.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	pruned, err := r.primary.UserTombstonesPrune(ctx, before)
+	if err != nil {
+		return pruned, err
+	}
+	r.mirror(ctx, "UserTombstonesPrune", func(ctx context.Context) error {
+		_, err := r.candidate.UserTombstonesPrune(ctx, before)
+		return err
+	})
+	return pruned, nil
+}
+
+// UserCounts is read from the primary, counts of the candidate are not compared,
+// since they differ by writes not mirrored yet.
+func (r *repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	return r.primary.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (int64, error) {
+	corrected, err := r.primary.UserCountsReconcile(ctx)
+	if err != nil {
+		return corrected, err
+	}
+	r.mirror(ctx, "UserCountsReconcile", func(ctx context.Context) error {
+		_, err := r.candidate.UserCountsReconcile(ctx)
+		return err
+	})
+	return corrected, nil
+}
+
+func (r *repo) Close() {
+	r.primary.Close()
+	r.candidate.Close()
+}
+
+// run calls the candidate with own timeout, so it does not depend on the finished request.
+func (r *repo) run(call func(ctx context.Context) (interface{}, error)) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	result, err := call(ctx)
+	switch result := result.(type) {
+	case models.User:
+		return []models.User{result}, err
+	case []models.User:
+		return result, err
+	}
+	return nil, err
+}
+
+// mirror applies the write to the candidate, errors are logged only.
+func (r *repo) mirror(ctx context.Context, method string, call func(ctx context.Context) error) {
+	if !r.cfg.MirrorWrites {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	if err := call(ctx); err != nil {
+		counter.CanaryMismatch.Inc(method)
+		r.logger.Warnw("canary write failed", "op", "repo."+method, "error", err.Error())
+	}
+}
+
+// compare logs mismatch of the results, field names are logged instead of values,
+// so passwords and personal data do not get to the log.
+func (r *repo) compare(method string, exp []models.User, expErr error, got []models.User, gotErr error, args ...interface{}) {
+	defer func() {
+		if r.compared != nil {
+			r.compared()
+		}
+	}()
+	counter.CanaryCompared.Inc(method)
+
+	var mismatch []interface{}
+	switch {
+	case !sameError(expErr, gotErr):
+		mismatch = []interface{}{"error", errorString(expErr), "candidate_error", errorString(gotErr)}
+	case expErr != nil:
+	case len(exp) != len(got):
+		mismatch = []interface{}{"count", len(exp), "candidate_count", len(got)}
+	default:
+		for i := range exp {
+			if fields := models.Diff(exp[i], got[i]); len(fields) != 0 {
+				mismatch = []interface{}{"index", i, "name", exp[i].Name, "fields", fields}
+				break
+			}
+		}
+	}
+	if mismatch == nil {
+		return
+	}
+	counter.CanaryMismatch.Inc(method)
+	r.logger.Warnw("canary mismatch", append(append([]interface{}{"op", "repo." + method}, args...), mismatch...)...)
+}
+
+// sameError reports whether both errors are nil, or both are the same known error.
+func sameError(exp, got error) bool {
+	if exp == nil || got == nil {
+		return exp == got
+	}
+	for _, known := range []error{errorsPkg.ErrUserNotFound, errorsPkg.ErrUserAlreadyExists} {
+		if errors.Is(exp, known) {
+			return errors.Is(got, known)
+		}
+	}
+	return !errors.Is(got, errorsPkg.ErrUserNotFound) && !errors.Is(got, errorsPkg.ErrUserAlreadyExists)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,114 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRepo_UserGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	alice := models.User{Name: "alice", Email: "alice@example.com"}
+
+	cases := []struct {
+		name        string
+		sampled     bool
+		candidate   models.User
+		candErr     error
+		expMismatch bool
+	}{
+		{
+			name:      "same result",
+			sampled:   true,
+			candidate: alice,
+		},
+		{
+			name:        "different field",
+			sampled:     true,
+			candidate:   models.User{Name: "alice", Email: "old@example.com"},
+			expMismatch: true,
+		},
+		{
+			name:        "missing in candidate",
+			sampled:     true,
+			candErr:     errorsPkg.ErrUserNotFound,
+			expMismatch: true,
+		},
+		{
+			name: "not sampled",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			primary := repoMockPkg.NewMockInterface(ctl)
+			candidate := repoMockPkg.NewMockInterface(ctl)
+			primary.EXPECT().UserGet(gomock.Any(), "alice").Return(alice, nil).Times(1)
+
+			done := make(chan struct{})
+			r := New(primary, candidate, Config{Candidate: BackendPostgres}, loggerPkg.NewFatal()).(*repo)
+			r.sample = func() bool { return c.sampled }
+			r.compared = func() { close(done) }
+			if c.sampled {
+				candidate.EXPECT().UserGet(gomock.Any(), "alice").Return(c.candidate, c.candErr).Times(1)
+			} else {
+				close(done)
+			}
+
+			before := counter.CanaryMismatch.String()
+			user, err := r.UserGet(context.Background(), "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, alice, user)
+			<-done
+			assert.Equal(t, c.expMismatch, before != counter.CanaryMismatch.String())
+		})
+	}
+}
+
+func TestRepo_UserCreate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	alice := models.User{Name: "alice"}
+
+	cases := []struct {
+		name       string
+		mirror     bool
+		primaryErr error
+		expMirror  int
+	}{
+		{
+			name:      "mirrored",
+			mirror:    true,
+			expMirror: 1,
+		},
+		{
+			name: "mirror disabled",
+		},
+		{
+			name:       "primary failed",
+			mirror:     true,
+			primaryErr: errorsPkg.ErrUserAlreadyExists,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			primary := repoMockPkg.NewMockInterface(ctl)
+			candidate := repoMockPkg.NewMockInterface(ctl)
+			primary.EXPECT().UserCreate(gomock.Any(), alice).Return(c.primaryErr).Times(1)
+			candidate.EXPECT().UserCreate(gomock.Any(), alice).Return(nil).Times(c.expMirror)
+
+			r := New(primary, candidate, Config{MirrorWrites: c.mirror}, loggerPkg.NewFatal())
+			assert.ErrorIs(t, r.UserCreate(context.Background(), alice), c.primaryErr)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package filter describes conditions on users, which repositories compile to their queries,
+// so a new filterable field does not need a new repository method.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+// Field of the user. Attribute keys are fields made by Attribute.
+type Field string
+
+const (
+	FieldID                Field = "id"
+	FieldName              Field = "name"
+	FieldEmail             Field = "email"
+	FieldFullName          Field = "full_name"
+	FieldStatus            Field = "status"
+	FieldCreatedAt         Field = "created_at"
+	FieldPasswordChangedAt Field = "password_changed_at"
+	FieldPasswordExpiresAt Field = "password_expires_at"
+	FieldUpdatedAt         Field = "updated_at"
+
+	attributePrefix = "attributes."
+)
+
+// Attribute returns the field of the attribute key, its values are strings.
+func Attribute(key string) Field {
+	return Field(attributePrefix + key)
+}
+
+// AttributeKey returns the key of the attribute field, ok is false for other fields.
+func (f Field) AttributeKey() (string, bool) {
+	if !strings.HasPrefix(string(f), attributePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(string(f), attributePrefix), true
+}
+
+// Numeric reports whether values of the field are int64, other fields are strings.
+func (f Field) Numeric() bool {
+	switch f {
+	case FieldCreatedAt, FieldPasswordChangedAt, FieldPasswordExpiresAt, FieldUpdatedAt:
+		return true
+	}
+	return false
+}
+
+func (f Field) known() bool {
+	switch f {
+	case FieldID, FieldName, FieldEmail, FieldFullName, FieldStatus,
+		FieldCreatedAt, FieldPasswordChangedAt, FieldPasswordExpiresAt, FieldUpdatedAt:
+		return true
+	}
+	key, ok := f.AttributeKey()
+	return ok && key != ""
+}
+
+// Op compares the field with the value.
+type Op string
+
+const (
+	OpEq Op = "="
+	OpNe Op = "!="
+	OpLt Op = "<"
+	OpLe Op = "<="
+	OpGt Op = ">"
+	OpGe Op = ">="
+	// OpPrefix matches strings starting with the value.
+	OpPrefix Op = "prefix"
+)
+
+// Expr is one of Cond, And and Or. Nil expression matches all users.
+type Expr interface {
+	fmt.Stringer
+	expr()
+}
+
+// Cond compares the field of the user with the value.
+type Cond struct {
+	Field Field
+	Op    Op
+	// Value is int64 for numeric fields and string for others.
+	Value interface{}
+}
+
+// And matches users matched by all expressions, empty And matches all users.
+type And []Expr
+
+// Or matches users matched by any expression, empty Or matches no users.
+type Or []Expr
+
+func (Cond) expr() {}
+func (And) expr()  {}
+func (Or) expr()   {}
+
+func (c Cond) String() string {
+	return fmt.Sprintf("%s %s %#v", c.Field, c.Op, c.Value)
+}
+
+func (a And) String() string {
+	return join(a, " AND ")
+}
+
+func (o Or) String() string {
+	return join(o, " OR ")
+}
+
+func join(exprs []Expr, sep string) string {
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		parts = append(parts, "("+e.String()+")")
+	}
+	return strings.Join(parts, sep)
+}
+
+func Eq(field Field, value interface{}) Cond {
+	return Cond{Field: field, Op: OpEq, Value: value}
+}
+
+// ListParams returns the filter of the users having all the attributes and the status,
+// if it is not empty. Nil is returned, if there are no conditions.
+func ListParams(attributes map[string]string, status string) Expr {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var and And
+	for _, key := range keys {
+		and = append(and, Eq(Attribute(key), attributes[key]))
+	}
+	if status != "" {
+		and = append(and, Eq(FieldStatus, status))
+	}
+	if len(and) == 0 {
+		return nil
+	}
+	return and
+}
+
+// ListKey returns the canonical form of the ListParams filter, it is equal for equal filters,
+// so page tokens of a list are bound to its filter by the key.
+func ListKey(attributes map[string]string, status string) string {
+	return fmt.Sprint(ListParams(attributes, status))
+}
+
+// Validate checks fields, operators and types of values, so repositories compile valid expressions only.
+func Validate(expr Expr) error {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case Cond:
+		return validateCond(e)
+	case And:
+		return validateAll(e)
+	case Or:
+		return validateAll(e)
+	default:
+		return errors.Wrapf(errorsPkg.ErrValidation, "filter: unknown expression [%T]", expr)
+	}
+}
+
+func validateAll(exprs []Expr) error {
+	for _, e := range exprs {
+		if err := Validate(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCond(c Cond) error {
+	if !c.Field.known() {
+		return errors.Wrapf(errorsPkg.ErrValidation, "filter: unknown field [%s]", c.Field)
+	}
+	switch c.Op {
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+	case OpPrefix:
+		if c.Field.Numeric() {
+			return errors.Wrapf(errorsPkg.ErrValidation, "filter: [%s] of numeric field [%s]", c.Op, c.Field)
+		}
+	default:
+		return errors.Wrapf(errorsPkg.ErrValidation, "filter: unknown operator [%s]", c.Op)
+	}
+
+	if c.Field.Numeric() {
+		if _, ok := c.Value.(int64); !ok {
+			return errors.Wrapf(errorsPkg.ErrValidation, "filter: field [%s] value [%v] is not int64", c.Field, c.Value)
+		}
+		return nil
+	}
+	if _, ok := c.Value.(string); !ok {
+		return errors.Wrapf(errorsPkg.ErrValidation, "filter: field [%s] value [%v] is not a string", c.Field, c.Value)
+	}
+	return nil
+}
+
+// Value returns the field of the user, ok is false for an absent attribute.
+func Value(user models.User, field Field) (value interface{}, ok bool) {
+	switch field {
+	case FieldID:
+		return user.ID, true
+	case FieldName:
+		return user.Name, true
+	case FieldEmail:
+		return user.Email, true
+	case FieldFullName:
+		return user.FullName, true
+	case FieldStatus:
+		return user.Status, true
+	case FieldCreatedAt:
+		return user.CreatedAt, true
+	case FieldPasswordChangedAt:
+		return user.PasswordChangedAt, true
+	case FieldPasswordExpiresAt:
+		return user.PasswordExpiresAt, true
+	case FieldUpdatedAt:
+		return user.UpdatedAt, true
+	}
+	key, _ := field.AttributeKey()
+	value, ok = user.Attributes[key]
+	return value, ok
+}
@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+)
+
+func TestListParams(t *testing.T) {
+	assert.Nil(t, ListParams(nil, ""))
+	assert.Equal(t, And{
+		Eq(Attribute("region"), "eu"),
+		Eq(Attribute("team"), "core"),
+		Eq(FieldStatus, models.StatusActive),
+	}, ListParams(map[string]string{"team": "core", "region": "eu"}, models.StatusActive))
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		expr   Expr
+		expErr error
+	}{
+		{
+			name: "success, nil",
+		},
+		{
+			name: "success, nested",
+			expr: Or{
+				Cond{Field: FieldName, Op: OpPrefix, Value: "iv"},
+				And{
+					Cond{Field: FieldCreatedAt, Op: OpGt, Value: int64(1)},
+					Eq(Attribute("team"), "core"),
+				},
+			},
+		},
+		{
+			name:   "failed, unknown field",
+			expr:   And{Eq("password", "123")},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, empty attribute key",
+			expr:   Eq(Attribute(""), "core"),
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, unknown operator",
+			expr:   Cond{Field: FieldName, Op: "~", Value: "iv"},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, prefix of numeric field",
+			expr:   Cond{Field: FieldCreatedAt, Op: OpPrefix, Value: "1"},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name:   "failed, numeric value of string field",
+			expr:   Or{Eq(FieldEmail, 1)},
+			expErr: errorsPkg.ErrValidation,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.ErrorIs(t, Validate(c.expr), c.expErr)
+		})
+	}
+}
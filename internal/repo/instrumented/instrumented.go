@@ -0,0 +1,180 @@
+// Package instrumented measures repository calls by backend, table and method:
+// latency histograms, error classes and traced spans.
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+)
+
+const (
+	tableUsers      = "users"
+	tableTombstones = "users_tombstones"
+	tableCounters   = "user_counters"
+)
+
+// Error classes of counter.RepoErrors.
+const (
+	ClassNotFound      = "not_found"
+	ClassAlreadyExists = "already_exists"
+	ClassValidation    = "validation"
+	ClassTimeout       = "timeout"
+	ClassCanceled      = "canceled"
+	ClassUnexpected    = "unexpected"
+)
+
+// Config of the repository instrumentation.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Tracing starts a span of every call of a traced request.
+	Tracing bool `mapstructure:"tracing"`
+}
+
+// New wraps repository of the backend, e.g. "postgres" or "local". Calls are observed
+// in counter.RepoLatency and failed ones in counter.RepoErrors by "backend/table/method".
+func New(data repoPkg.Interface, backend string, cfg Config) repoPkg.Interface {
+	return &repo{
+		data:    data,
+		backend: backend,
+		tracing: cfg.Tracing,
+	}
+}
+
+type repo struct {
+	data    repoPkg.Interface
+	backend string
+	tracing bool
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) (err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserCreate")
+	defer func() { done(err) }()
+	return r.data.UserCreate(ctx, user)
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) (err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserUpdate")
+	defer func() { done(err) }()
+	return r.data.UserUpdate(ctx, user)
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) (err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserDelete")
+	defer func() { done(err) }()
+	return r.data.UserDelete(ctx, name)
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) (err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserRename")
+	defer func() { done(err) }()
+	return r.data.UserRename(ctx, oldName, newName)
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (_ models.User, err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserGet")
+	defer func() { done(err) }()
+	return r.data.UserGet(ctx, name)
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (_ models.User, err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserGetByID")
+	defer func() { done(err) }()
+	return r.data.UserGetByID(ctx, id)
+}
+
+func (r *repo) UserList(ctx context.Context, order bool, limit, offset uint64, where filter.Expr) (_ []models.User, err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserList")
+	defer func() { done(err) }()
+	return r.data.UserList(ctx, order, limit, offset, where)
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) (err error) {
+	ctx, done := r.start(ctx, tableUsers, "UserSnapshot")
+	defer func() { done(err) }()
+	return r.data.UserSnapshot(ctx, fn)
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) (_ []models.Tombstone, err error) {
+	ctx, done := r.start(ctx, tableTombstones, "UserTombstones")
+	defer func() { done(err) }()
+	return r.data.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (_ int64, err error) {
+	ctx, done := r.start(ctx, tableTombstones, "UserTombstonesPrune")
+	defer func() { done(err) }()
+	return r.data.UserTombstonesPrune(ctx, before)
+}
+
+func (r *repo) UserCounts(ctx context.Context) (_ map[string]int64, err error) {
+	ctx, done := r.start(ctx, tableCounters, "UserCounts")
+	defer func() { done(err) }()
+	return r.data.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (_ int64, err error) {
+	ctx, done := r.start(ctx, tableCounters, "UserCountsReconcile")
+	defer func() { done(err) }()
+	return r.data.UserCountsReconcile(ctx)
+}
+
+func (r *repo) Close() {
+	r.data.Close()
+}
+
+// start begins the call, the returned function observes its result.
+// The span is a child of the request span, untraced calls have no span.
+func (r *repo) start(ctx context.Context, table, method string) (context.Context, func(err error)) {
+	key := r.backend + "/" + table + "/" + method
+	var span opentracing.Span
+	if r.tracing && opentracing.SpanFromContext(ctx) != nil {
+		span, ctx = opentracing.StartSpanFromContext(ctx, "repo."+method)
+		span.SetTag("repo.backend", r.backend)
+		span.SetTag("db.table", table)
+	}
+	start, traceID := time.Now(), jaegerPkg.SampledTraceID(ctx)
+	return ctx, func(err error) {
+		// sampled trace of the call is the exemplar of its bucket
+		counter.RepoLatency.ObserveExemplar(key, time.Since(start), traceID)
+		if err != nil {
+			class := Class(err)
+			counter.RepoErrors.Inc(key + "/" + class)
+			if span != nil {
+				ext.Error.Set(span, true)
+				span.SetTag("error.class", class)
+			}
+		}
+		if span != nil {
+			span.Finish()
+		}
+	}
+}
+
+// Class returns the class of the repository error.
+func Class(err error) string {
+	switch {
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		return ClassNotFound
+	case errors.Is(err, errorsPkg.ErrUserAlreadyExists):
+		return ClassAlreadyExists
+	case errors.Is(err, errorsPkg.ErrValidation):
+		return ClassValidation
+	case errors.Is(err, errorsPkg.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	default:
+		return ClassUnexpected
+	}
+}
@@ -0,0 +1,74 @@
+package instrumented
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+)
+
+func TestRepo_UserGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name     string
+		backend  string
+		err      error
+		expClass string
+	}{
+		{
+			name:    "success",
+			backend: "local",
+		},
+		{
+			name:     "not found",
+			backend:  "postgres",
+			err:      errors.Wrap(errorsPkg.ErrUserNotFound, "alice"),
+			expClass: ClassNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := repoMockPkg.NewMockInterface(ctl)
+			data.EXPECT().UserGet(gomock.Any(), "alice").Return(models.User{}, c.err).Times(1)
+
+			key := c.backend + "/users/UserGet"
+			before := counter.RepoLatency.Count(key)
+			_, err := New(data, c.backend, Config{Enabled: true}).UserGet(context.Background(), "alice")
+			assert.ErrorIs(t, err, c.err)
+			assert.Equal(t, before+1, counter.RepoLatency.Count(key))
+			if c.expClass != "" {
+				assert.Contains(t, counter.RepoErrors.String(), "["+key+"/"+c.expClass+"]")
+			}
+		})
+	}
+}
+
+func TestClass(t *testing.T) {
+	cases := []struct {
+		err      error
+		expClass string
+	}{
+		{err: errorsPkg.ErrUserNotFound, expClass: ClassNotFound},
+		{err: errorsPkg.ErrUserAlreadyExists, expClass: ClassAlreadyExists},
+		{err: errorsPkg.ErrNameReserved, expClass: ClassValidation},
+		{err: errors.Wrap(context.DeadlineExceeded, "query"), expClass: ClassTimeout},
+		{err: context.Canceled, expClass: ClassCanceled},
+		{err: errors.New("connection reset"), expClass: ClassUnexpected},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expClass, func(t *testing.T) {
+			assert.Equal(t, c.expClass, Class(c.err))
+		})
+	}
+}
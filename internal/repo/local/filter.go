@@ -0,0 +1,84 @@
+package local
+
+import (
+	"strings"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+// predicate compiles the valid filter to the function matching users.
+func predicate(expr filter.Expr) func(user models.User) bool {
+	switch e := expr.(type) {
+	case filter.Cond:
+		return func(user models.User) bool {
+			value, ok := filter.Value(user, e.Field)
+			return ok && compare(value, e.Op, e.Value)
+		}
+	case filter.And:
+		all := predicates(e)
+		return func(user models.User) bool {
+			for _, match := range all {
+				if !match(user) {
+					return false
+				}
+			}
+			return true
+		}
+	case filter.Or:
+		some := predicates(e)
+		return func(user models.User) bool {
+			for _, match := range some {
+				if match(user) {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		return func(models.User) bool { return true }
+	}
+}
+
+func predicates(exprs []filter.Expr) []func(user models.User) bool {
+	list := make([]func(user models.User) bool, 0, len(exprs))
+	for _, e := range exprs {
+		list = append(list, predicate(e))
+	}
+	return list
+}
+
+// compare applies the operator to values of the same type, they are checked by filter.Validate.
+func compare(value interface{}, op filter.Op, with interface{}) bool {
+	var cmp int
+	switch v := value.(type) {
+	case string:
+		if op == filter.OpPrefix {
+			return strings.HasPrefix(v, with.(string))
+		}
+		cmp = strings.Compare(v, with.(string))
+	case int64:
+		switch w := with.(int64); {
+		case v < w:
+			cmp = -1
+		case v > w:
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case filter.OpEq:
+		return cmp == 0
+	case filter.OpNe:
+		return cmp != 0
+	case filter.OpLt:
+		return cmp < 0
+	case filter.OpLe:
+		return cmp <= 0
+	case filter.OpGt:
+		return cmp > 0
+	case filter.OpGe:
+		return cmp >= 0
+	}
+	return false
+}
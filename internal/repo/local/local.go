@@ -3,157 +3,496 @@ package local
 import (
 	"context"
 	"sort"
-	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
 	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	cachePkg "gitlab.ozon.dev/iTukaev/homework/pkg/cache"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
 )
 
-func New(workersCount int, logger *zap.SugaredLogger) repoPkg.Interface {
+// New returns in-memory repository, operations are run by the pool workers.
+func New(pool *workerpool.Pool, logger *zap.SugaredLogger) repoPkg.Interface {
 	logger.Infoln("With local storage started")
+	return newCache(pool, logger)
+}
+
+const (
+	// entryOverhead is an estimated size of the map entry and user struct without string data.
+	entryOverhead = 128
+	// attributeOverhead is an estimated size of the attributes map entry without string data.
+	attributeOverhead = 48
+)
+
+// cache keeps users by the typed cache, its transactions guard the index and tombstones too.
+type cache struct {
+	users  *cachePkg.Cache[string, models.User]
+	pool   *workerpool.Pool
+	logger *zap.SugaredLogger
+
+	// tombstones are left by deletions in order
+	tombstones []models.Tombstone
+	// counts are numbers of users by tenant, they are changed by the transactions of writes
+	counts map[string]int64
+	// names are keys of users in ascending order, pages are read by the index without sorting
+	names []string
+	// version is a number of writes
+	version uint64
+	// snap shares users and names with the cache, they are copied by the next write
+	snap *snapshot
+}
+
+func newCache(pool *workerpool.Pool, logger *zap.SugaredLogger) *cache {
 	return &cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, workersCount),
+		users: cachePkg.New(cachePkg.Options[string, models.User]{
+			Sizer:   entrySize,
+			Metrics: metrics{},
+		}),
+		pool:   pool,
 		logger: logger,
 	}
 }
 
-type cache struct {
-	mu     sync.RWMutex
-	data   map[string]models.User
-	poolCh chan struct{}
-	logger *zap.SugaredLogger
+// metrics publishes the typed cache metrics by the local storage counters.
+type metrics struct {
+	cachePkg.NopMetrics
+}
+
+// Removed counts evictions by capacity and expiration, users deleted by requests are not evicted.
+func (metrics) Removed(reason cachePkg.Reason) {
+	if reason != cachePkg.ReasonDeleted {
+		counter.LocalEvictions.Inc()
+	}
+}
+
+func (metrics) Compacted() {
+	counter.LocalResize.Inc()
+}
+
+func (metrics) Changed(entries int, size int64) {
+	counter.LocalEntries.Set(int64(entries))
+	counter.LocalMemory.Set(size)
+}
+
+func (metrics) LockWait(d time.Duration) {
+	counter.LocalLockWait.Observe(d)
+}
+
+type tx = cachePkg.Tx[string, models.User]
+
+// snapshot is an immutable version of users, lists of a request read pages of the same one.
+type snapshot struct {
+	version uint64
+	data    cachePkg.Snapshot[string, models.User]
+	names   []string
 }
 
 func (c *cache) UserCreate(ctx context.Context, user models.User) error {
 	c.logger.Debugln("UserCreate, cached func", user.String())
-	select {
-	case <-ctx.Done():
-		return errorsPkg.ErrTimeout
-	case c.poolCh <- struct{}{}:
-		c.mu.Lock()
-		defer func() {
-			c.mu.Unlock()
-			<-c.poolCh
-		}()
-
-		c.data[user.Name] = user
-		return nil
-	}
+	err := c.do(ctx, func() {
+		c.users.Update(func(tx *tx) {
+			c.set(tx, user)
+		})
+	})
+	return apperr.WrapKey(err, "repo.UserCreate", "name", user.Name)
 }
 
 func (c *cache) UserUpdate(ctx context.Context, user models.User) error {
 	c.logger.Debugln("UserUpdate, cached func", user.String())
-	select {
-	case <-ctx.Done():
-		return errorsPkg.ErrTimeout
-	case c.poolCh <- struct{}{}:
-		c.mu.Lock()
-		defer func() {
-			c.mu.Unlock()
-			<-c.poolCh
-		}()
-
-		u := c.data[user.Name]
-		if user.Email != "" {
-			u.Email = user.Email
-		}
-		if user.Password != "" {
-			u.Password = user.Password
-		}
-		if user.FullName != "" {
-			u.FullName = user.FullName
-		}
+	err := c.do(ctx, func() {
+		c.users.Update(func(tx *tx) {
+			c.update(tx, user)
+		})
+	})
+	return apperr.WrapKey(err, "repo.UserUpdate", "name", user.Name)
+}
 
-		c.data[user.Name] = u
-		return nil
+// update merges the non-empty fields to the stored user.
+func (c *cache) update(tx *tx, user models.User) {
+	u, _ := tx.Get(user.Name)
+	if user.Email != "" {
+		u.Email = user.Email
+	}
+	if user.Password != "" {
+		u.Password = user.Password
+	}
+	if user.FullName != "" {
+		u.FullName = user.FullName
 	}
+	if user.Attributes != nil {
+		u.Attributes = user.Attributes
+	}
+	if user.UpdatedAt != 0 {
+		u.UpdatedAt = user.UpdatedAt
+	}
+
+	c.set(tx, u)
 }
 
 func (c *cache) UserDelete(ctx context.Context, name string) error {
 	c.logger.Debugln("UserDelete, cached func", name)
-	select {
-	case <-ctx.Done():
-		return errorsPkg.ErrTimeout
-	case c.poolCh <- struct{}{}:
-		c.mu.Lock()
-		defer func() {
-			c.mu.Unlock()
-			<-c.poolCh
-		}()
-
-		delete(c.data, name)
-		return nil
+	err := c.do(ctx, func() {
+		c.users.Update(func(tx *tx) {
+			if user, ok := tx.Get(name); ok {
+				c.tombstones = append(c.tombstones, models.Tombstone{ID: user.ID, Name: name, DeletedAt: time.Now().Unix()})
+			}
+			c.remove(tx, name)
+		})
+	})
+	return apperr.WrapKey(err, "repo.UserDelete", "name", name)
+}
+
+// UserRename checks both names and then moves the entry, the cache is locked for both phases.
+func (c *cache) UserRename(ctx context.Context, oldName, newName string) error {
+	c.logger.Debugln("UserRename, cached func", oldName, newName)
+	var errRename error
+	err := c.do(ctx, func() {
+		c.users.Update(func(tx *tx) {
+			// prepare
+			user, ok := tx.Get(oldName)
+			if !ok {
+				errRename = errorsPkg.ErrUserNotFound
+				return
+			}
+			if _, ok = tx.Get(newName); ok {
+				errRename = errorsPkg.ErrUserAlreadyExists
+				return
+			}
+
+			// commit
+			user.Name = newName
+			user.UpdatedAt = time.Now().Unix()
+			c.set(tx, user)
+			c.remove(tx, oldName)
+		})
+	})
+	if err == nil {
+		err = errRename
 	}
+	return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
 }
 
 func (c *cache) UserGet(ctx context.Context, name string) (models.User, error) {
 	c.logger.Debugln("UserGet, cached func", name)
-	select {
-	case <-ctx.Done():
-		return models.User{}, errorsPkg.ErrTimeout
-	case c.poolCh <- struct{}{}:
-		c.mu.RLock()
-		defer func() {
-			c.mu.RUnlock()
-			<-c.poolCh
-		}()
-
-		if user, ok := c.data[name]; !ok {
-			return user, errors.Wrapf(errorsPkg.ErrUserNotFound, "user-name: [%s]", name)
-		} else {
-			return user, nil
+	var (
+		user  models.User
+		found bool
+	)
+	err := c.do(ctx, func() {
+		user, found = c.users.Get(name)
+	})
+	if err == nil && !found {
+		err = errorsPkg.ErrUserNotFound
+	}
+	return user, apperr.WrapKey(err, "repo.UserGet", "name", name)
+}
+
+// UserGetByID scans all entries, local storage is not intended for large data sets.
+func (c *cache) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	c.logger.Debugln("UserGetByID, cached func", id)
+	var (
+		user  models.User
+		found bool
+	)
+	err := c.do(ctx, func() {
+		c.users.View(func(view cachePkg.Snapshot[string, models.User]) {
+			view.Range(func(_ string, u models.User) bool {
+				if u.ID == id {
+					user, found = u, true
+				}
+				return !found
+			})
+		})
+	})
+	if err == nil && !found {
+		err = errorsPkg.ErrUserNotFound
+	}
+	return user, apperr.WrapKey(err, "repo.UserGetByID", "id", id)
+}
+
+func (c *cache) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	c.logger.Debugln("UserList, cached func", order, limit, offset, where)
+	if err := filter.Validate(where); err != nil {
+		return nil, apperr.Wrap(err, "repo.UserList")
+	}
+	if ls := repoPkg.ListSnapshotFromContext(ctx); ls != nil {
+		state, err := ls.Load(c, func() (interface{}, func(), error) {
+			var snap *snapshot
+			err := c.do(ctx, func() {
+				snap = c.snapshot()
+			})
+			return snap, nil, err
+		})
+		if err != nil {
+			return nil, apperr.Wrap(err, "repo.UserList")
 		}
+		return state.(*snapshot).page(order, limit, offset, where), nil
+	}
+
+	var list []models.User
+	err := c.do(ctx, func() {
+		c.users.View(func(view cachePkg.Snapshot[string, models.User]) {
+			list = (&snapshot{data: view, names: c.names}).page(order, limit, offset, where)
+		})
+	})
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserList")
 	}
+	return list, nil
 }
 
-func (c *cache) UserList(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error) {
-	c.logger.Debugln("UserList, cached func", order, limit, offset)
-	select {
-	case <-ctx.Done():
-		return nil, errorsPkg.ErrTimeout
-	case c.poolCh <- struct{}{}:
-		c.mu.RLock()
-		defer func() {
-			c.mu.RUnlock()
-			<-c.poolCh
-		}()
+// page returns users of the page in order of the index, the snapshot must not be written.
+// Only users of the page are copied: without a filter the page is sliced out of the index,
+// with a filter users are visited until the page is filled.
+func (s *snapshot) page(order bool, limit, offset uint64, where filter.Expr) []models.User {
+	match := predicate(where)
+	skip, start, total := limit*offset, uint64(0), uint64(len(s.names))
+	if where == nil {
+		// every user matches, so the page starts right after the skipped ones
+		start, skip = skip, 0
+	}
+	if start >= total {
+		return make([]models.User, 0)
+	}
+	size := limit
+	if rest := total - start; size > rest {
+		size = rest
+	}
 
-		if len(c.data) < int(limit*offset) {
-			return make([]models.User, 0), nil
+	list := make([]models.User, 0, size)
+	for i := start; i < total && uint64(len(list)) < limit; i++ {
+		name := s.names[i]
+		if order {
+			name = s.names[total-1-i]
+		}
+		user, _ := s.data.Get(name)
+		if !match(user) {
+			continue
 		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		list = append(list, user)
+	}
+	return list
+}
 
-		list := make([]models.User, 0, len(c.data))
-		for _, user := range c.data {
-			list = append(list, user)
+// UserSnapshot iterates the immutable snapshot in order of the index, so fn is called
+// without the lock held.
+func (c *cache) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	c.logger.Debugln("UserSnapshot, cached func")
+	var snap *snapshot
+	err := c.do(ctx, func() {
+		snap = c.snapshot()
+	})
+	if err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+
+	for _, name := range snap.names {
+		user, _ := snap.data.Get(name)
+		if err = fn(user); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// snapshot returns the immutable version of users. It shares data and names with the cache,
+// so it is taken without copying and the next write copies them instead.
+func (c *cache) snapshot() *snapshot {
+	var snap *snapshot
+	c.users.Update(func(tx *tx) {
+		if c.snap == nil {
+			c.snap = &snapshot{version: c.version, data: tx.Snapshot(), names: c.names}
+			c.logger.Debugln("snapshot taken, version", c.version)
+		}
+		snap = c.snap
+	})
+	return snap
+}
+
+// write detaches names from the snapshot and counts the version, users are detached by the typed cache.
+func (c *cache) write() {
+	c.version++
+	if c.snap == nil {
+		return
+	}
+	c.names = append(make([]string, 0, len(c.names)), c.names...)
+	c.snap = nil
+}
+
+// do runs fn by the pool worker, ErrTimeout is returned if ctx is done before fn is started.
+func (c *cache) do(ctx context.Context, fn func()) error {
+	err := c.pool.Do(ctx, func(context.Context) error {
+		fn()
+		return nil
+	})
+	if err != nil && errors.Is(err, ctx.Err()) {
+		return errorsPkg.ErrTimeout
+	}
+	return err
+}
+
+// set stores the user and indexes its name.
+func (c *cache) set(tx *tx, user models.User) {
+	c.write()
+	if old, ok := tx.Get(user.Name); ok {
+		c.count(old, -1)
+	} else {
+		c.index(user.Name)
+	}
+	c.count(user, 1)
+	tx.Set(user.Name, user)
+}
 
-		sort.Slice(list, func(i, j int) bool {
-			if order {
-				return list[i].Name > list[j].Name
+// count adds delta to the counter of the user tenant in the update transaction.
+func (c *cache) count(user models.User, delta int64) {
+	if c.counts == nil {
+		c.counts = make(map[string]int64)
+	}
+	tenant := user.Attributes[passwordPkg.TenantAttribute]
+	if c.counts[tenant] += delta; c.counts[tenant] == 0 {
+		delete(c.counts, tenant)
+	}
+}
+
+// index inserts the name to the ordered index in the update transaction.
+func (c *cache) index(name string) {
+	i := sort.SearchStrings(c.names, name)
+	if i < len(c.names) && c.names[i] == name {
+		return
+	}
+	c.names = append(c.names, "")
+	copy(c.names[i+1:], c.names[i:])
+	c.names[i] = name
+}
+
+// unindex removes the name from the ordered index in the update transaction.
+func (c *cache) unindex(name string) {
+	i := sort.SearchStrings(c.names, name)
+	if i < len(c.names) && c.names[i] == name {
+		c.names = append(c.names[:i], c.names[i+1:]...)
+	}
+}
+
+func (c *cache) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	c.logger.Debugln("UserTombstones, cached func", since)
+	var tombstones []models.Tombstone
+	err := c.do(ctx, func() {
+		c.users.View(func(cachePkg.Snapshot[string, models.User]) {
+			// tombstones are in order of deletion, so the first one since the time is found by search
+			i := sort.Search(len(c.tombstones), func(i int) bool {
+				return c.tombstones[i].DeletedAt >= since
+			})
+			tombstones = append(tombstones, c.tombstones[i:]...)
+		})
+	})
+	return tombstones, apperr.Wrap(err, "repo.UserTombstones")
+}
+
+func (c *cache) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	c.logger.Debugln("UserTombstonesPrune, cached func", before)
+	var pruned int64
+	err := c.do(ctx, func() {
+		c.users.Update(func(*tx) {
+			i := sort.Search(len(c.tombstones), func(i int) bool {
+				return c.tombstones[i].DeletedAt >= before
+			})
+			c.tombstones = append([]models.Tombstone(nil), c.tombstones[i:]...)
+			pruned = int64(i)
+		})
+	})
+	return pruned, apperr.Wrap(err, "repo.UserTombstonesPrune")
+}
+
+// remove deletes the user and unindexes its name.
+func (c *cache) remove(tx *tx, name string) {
+	user, ok := tx.Get(name)
+	if !ok {
+		return
+	}
+	c.write()
+	c.count(user, -1)
+	tx.Delete(name)
+	c.unindex(name)
+}
+
+func (c *cache) UserCounts(ctx context.Context) (map[string]int64, error) {
+	c.logger.Debugln("UserCounts, cached func")
+	counts := make(map[string]int64)
+	err := c.do(ctx, func() {
+		c.users.View(func(cachePkg.Snapshot[string, models.User]) {
+			for tenant, users := range c.counts {
+				counts[tenant] = users
+			}
+		})
+	})
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserCounts")
+	}
+	return counts, nil
+}
+
+// UserCountsReconcile recounts users of the index in the update transaction.
+func (c *cache) UserCountsReconcile(ctx context.Context) (int64, error) {
+	c.logger.Debugln("UserCountsReconcile, cached func")
+	var corrected int64
+	err := c.do(ctx, func() {
+		c.users.Update(func(tx *tx) {
+			counts := make(map[string]int64)
+			for _, name := range c.names {
+				user, _ := tx.Get(name)
+				counts[user.Attributes[passwordPkg.TenantAttribute]]++
+			}
+			for tenant, users := range counts {
+				if c.counts[tenant] != users {
+					corrected++
+				}
 			}
-			return list[i].Name < list[j].Name
+			for tenant := range c.counts {
+				if _, ok := counts[tenant]; !ok {
+					corrected++
+				}
+			}
+			c.counts = counts
 		})
+	})
+	return corrected, apperr.Wrap(err, "repo.UserCountsReconcile")
+}
 
-		min := limit * offset
-		if len(list) < int(limit*(offset+1)) {
-			return list[min:], nil
-		} else {
-			max := limit * (offset + 1)
-			return list[min:max], nil
-		}
+// entrySize returns estimated memory of the entry, name is stored twice: as a key and in the user.
+func entrySize(_ string, user models.User) int64 {
+	size := entryOverhead + len(user.ID) + len(user.Name)*2 + len(user.Password) + len(user.Email) + len(user.FullName) +
+		len(user.Status)
+	for key, value := range user.Attributes {
+		size += attributeOverhead + len(key) + len(value)
 	}
+	return int64(size)
 }
 
+// Close waits for the queued operations and clears the cache.
 func (c *cache) Close() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.data = nil
-	close(c.poolCh)
+	c.pool.Close()
+	c.users.Update(func(*tx) {
+		c.names = nil
+		c.counts = nil
+		c.snap = nil
+	})
+	c.users.Clear()
 	c.logger.Infoln("Cache cleaned")
 }
@@ -0,0 +1,148 @@
+//go:build sim
+// +build sim
+
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/sim"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
+)
+
+const seeds = 50
+
+// newSim returns the cache of a worker per actor, so a task waiting for its turn
+// does not block tasks of other actors.
+func newSim(t *testing.T, actors int) repoPkg.Interface {
+	t.Helper()
+	pool := workerpool.New("sim", workerpool.Config{Workers: actors}, loggerPkg.NewFatal())
+	t.Cleanup(pool.Close)
+	return New(pool, loggerPkg.NewFatal())
+}
+
+// lastRun returns the actor, which task was run last.
+func lastRun(steps []sim.Step) string {
+	var last string
+	for _, step := range steps {
+		if step.Point == workerpool.PointRun {
+			last = step.Actor
+		}
+	}
+	return last
+}
+
+// firstRun returns the step, which runs the first task of the actor.
+func firstRun(steps []sim.Step, actor string) int {
+	for i, step := range steps {
+		if step.Actor == actor && step.Point == workerpool.PointRun {
+			return i
+		}
+	}
+	return len(steps)
+}
+
+func TestSim_CreateCreate(t *testing.T) {
+	create := func(data repoPkg.Interface, email string) sim.Actor {
+		return sim.Actor{Name: email, Fn: func(ctx context.Context) {
+			assert.NoError(t, data.UserCreate(ctx, models.User{Name: "ivan", Email: email}))
+		}}
+	}
+
+	// creates of the same name collide, the user of the task run last is kept whatever the order of calls is
+	for seed := int64(0); seed < seeds; seed++ {
+		data := newSim(t, 2)
+		steps, err := sim.Run(sim.Schedule{Seed: seed}, create(data, "a@mail"), create(data, "b@mail"))
+		require.NoError(t, err)
+
+		user, err := data.UserGet(context.Background(), "ivan")
+		require.NoError(t, err)
+		assert.Equal(t, lastRun(steps), user.Email, "seed %d", seed)
+	}
+
+	// the call queued first and run last wins
+	data := newSim(t, 2)
+	_, err := sim.Run(sim.Schedule{Script: []string{"a@mail", "a@mail", "b@mail", "b@mail", "b@mail", "a@mail"}},
+		create(data, "a@mail"), create(data, "b@mail"))
+	require.NoError(t, err)
+	user, err := data.UserGet(context.Background(), "ivan")
+	require.NoError(t, err)
+	assert.Equal(t, "a@mail", user.Email)
+}
+
+func TestSim_DeleteDuringList(t *testing.T) {
+	names := []string{"a", "b", "c", "d"}
+	// pages of the lister are read before and after the delete
+	script := []string{"list", "list", "list", "delete", "delete", "delete", "list", "list"}
+
+	cases := []struct {
+		name     string
+		snapshot bool
+		script   []string
+		expNames []string
+	}{
+		{
+			name:     "pages shift without the list snapshot",
+			script:   script,
+			expNames: []string{"a", "b", "d"},
+		},
+		{
+			name:     "pages of the list snapshot",
+			snapshot: true,
+			script:   script,
+			expNames: names,
+		},
+	}
+
+	run := func(t *testing.T, schedule sim.Schedule, snapshot bool) ([]string, []sim.Step) {
+		data := newSim(t, 2)
+		for _, name := range names {
+			require.NoError(t, data.UserCreate(context.Background(), models.User{Name: name}))
+		}
+		var listed []string
+		lister := sim.Actor{Name: "list", Fn: func(ctx context.Context) {
+			if snapshot {
+				var release func()
+				ctx, release = repoPkg.WithListSnapshot(ctx)
+				defer release()
+			}
+			for page := uint64(0); page < 2; page++ {
+				users, err := data.UserList(ctx, false, 2, page, nil)
+				assert.NoError(t, err)
+				for _, user := range users {
+					listed = append(listed, user.Name)
+				}
+			}
+		}}
+		deleter := sim.Actor{Name: "delete", Fn: func(ctx context.Context) {
+			assert.NoError(t, data.UserDelete(ctx, "a"))
+		}}
+		steps, err := sim.Run(schedule, lister, deleter)
+		require.NoError(t, err)
+		return listed, steps
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			listed, _ := run(t, sim.Schedule{Script: c.script}, c.snapshot)
+			assert.Equal(t, c.expNames, listed)
+		})
+	}
+
+	// the snapshot lists the users as of the first page, whatever the interleaving is
+	for seed := int64(0); seed < seeds; seed++ {
+		listed, steps := run(t, sim.Schedule{Seed: seed}, true)
+		if firstRun(steps, "list") < firstRun(steps, "delete") {
+			assert.Equal(t, names, listed, "seed %d", seed)
+			continue
+		}
+		assert.Len(t, listed, len(names)-1, "seed %d", seed)
+	}
+}
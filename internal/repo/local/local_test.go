@@ -2,55 +2,81 @@ package local
 
 import (
 	"context"
-	"sync"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	cachePkg "gitlab.ozon.dev/iTukaev/homework/pkg/cache"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
 )
 
 var (
-	user1 = models.User{
-		Name:      "Ivan",
-		Password:  "123",
-		Email:     "ivan@email.com",
-		FullName:  "Ivan the Dummy",
-		CreatedAt: 1660412940,
-	}
-	user2 = models.User{
-		Name:      "Ivan",
-		Password:  "123456",
-		Email:     "ivanivan@email.com",
-		FullName:  "Ivan the Smart guy",
-		CreatedAt: 1660412940,
-	}
-	user3 = models.User{
-		Name:      "Boris",
-		Password:  "321",
-		Email:     "boris@email.com",
-		FullName:  "Boris The Blade",
-		CreatedAt: 1660412960,
-	}
-	user4 = models.User{
-		Name:      "Arnold",
-		Password:  "321",
-		Email:     "arnold@email.com",
-		FullName:  "Arnold Schwarzenegger",
-		CreatedAt: 1660412960,
-	}
+	user1 = modeltest.Ivan()
+	user2 = modeltest.NewUser().
+		WithPassword("123456").
+		WithEmail("ivanivan@email.com").
+		WithFullName("Ivan the Smart guy").
+		Build()
+	user3 = modeltest.Boris()
+	user4 = modeltest.Arnold()
 )
 
-func TestCache_UserCreate(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
+// put stores the user bypassing the pool.
+func put(c *cache, user models.User) {
+	c.users.Update(func(tx *tx) {
+		c.set(tx, user)
+	})
+}
+
+// drop removes the user bypassing the pool, no tombstone is left.
+func drop(c *cache, name string) {
+	c.users.Update(func(tx *tx) {
+		c.remove(tx, name)
+	})
+}
+
+// get returns the stored user or the zero one.
+func get(c *cache, name string) models.User {
+	user, _ := c.users.Get(name)
+	return user
+}
+
+// all returns the stored users by names.
+func all(c *cache) map[string]models.User {
+	data := make(map[string]models.User)
+	c.users.View(func(view cachePkg.Snapshot[string, models.User]) {
+		view.Range(func(name string, user models.User) bool {
+			data[name] = user
+			return true
+		})
+	})
+	return data
+}
+
+// saturate occupies the only worker and the queue of the pool until the test is finished.
+func saturate(t *testing.T, pool *workerpool.Pool) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	for i := 0; i < 2; i++ {
+		_ = pool.Submit(context.Background(), func(context.Context) error {
+			<-release
+			return nil
+		})
 	}
+}
+
+func TestCache_UserCreate(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
@@ -59,32 +85,30 @@ func TestCache_UserCreate(t *testing.T) {
 		user    models.User
 		expErr  error
 		expUser models.User
-		poolCh  func(chan struct{})
+		busy    func(*testing.T, *workerpool.Pool)
 	}{
 		{
 			name:    "success",
 			user:    user1,
 			expErr:  nil,
 			expUser: user1,
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 		},
 		{
 			name:    "failed, deadline exceeded",
 			user:    user1,
 			expErr:  errorsPkg.ErrTimeout,
 			expUser: models.User{},
-			poolCh: func(ch chan struct{}) {
-				ch <- struct{}{}
-			},
+			busy:    saturate,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			c.poolCh(testCache.poolCh)
+			c.busy(t, testCache.pool)
 			err := testCache.UserCreate(ctx, c.user)
-			actualUser := testCache.data[c.user.Name]
-			delete(testCache.data, c.user.Name)
+			actualUser := get(testCache, c.user.Name)
+			drop(testCache, c.user.Name)
 
 			assert.ErrorIs(t, err, c.expErr)
 			assert.Equal(t, c.expUser, actualUser)
@@ -93,12 +117,7 @@ func TestCache_UserCreate(t *testing.T) {
 }
 
 func TestCache_UserUpdate(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
-	}
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
@@ -108,7 +127,7 @@ func TestCache_UserUpdate(t *testing.T) {
 		newUser models.User
 		expErr  error
 		expUser models.User
-		poolCh  func(chan struct{})
+		busy    func(*testing.T, *workerpool.Pool)
 	}{
 		{
 			name:    "success",
@@ -116,7 +135,7 @@ func TestCache_UserUpdate(t *testing.T) {
 			newUser: user2,
 			expErr:  nil,
 			expUser: user2,
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 		},
 		{
 			name:    "failed, deadline exceeded",
@@ -124,19 +143,17 @@ func TestCache_UserUpdate(t *testing.T) {
 			newUser: user2,
 			expErr:  errorsPkg.ErrTimeout,
 			expUser: user1,
-			poolCh: func(ch chan struct{}) {
-				ch <- struct{}{}
-			},
+			busy:    saturate,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			testCache.data[c.user.Name] = c.user
-			c.poolCh(testCache.poolCh)
+			put(testCache, c.user)
+			c.busy(t, testCache.pool)
 			err := testCache.UserUpdate(ctx, c.newUser)
-			actualUser := testCache.data[c.newUser.Name]
-			delete(testCache.data, c.newUser.Name)
+			actualUser := get(testCache, c.newUser.Name)
+			drop(testCache, c.newUser.Name)
 
 			assert.ErrorIs(t, err, c.expErr)
 			assert.Equal(t, c.expUser, actualUser)
@@ -145,12 +162,7 @@ func TestCache_UserUpdate(t *testing.T) {
 }
 
 func TestCache_UserDelete(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
-	}
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
@@ -159,33 +171,31 @@ func TestCache_UserDelete(t *testing.T) {
 		user    models.User
 		expErr  error
 		expUser models.User
-		poolCh  func(chan struct{})
+		busy    func(*testing.T, *workerpool.Pool)
 	}{
 		{
 			name:    "success",
 			user:    user1,
 			expErr:  nil,
 			expUser: models.User{},
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 		},
 		{
 			name:    "failed, deadline exceeded",
 			user:    user1,
 			expErr:  errorsPkg.ErrTimeout,
 			expUser: user1,
-			poolCh: func(ch chan struct{}) {
-				ch <- struct{}{}
-			},
+			busy:    saturate,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			testCache.data[c.user.Name] = c.user
-			c.poolCh(testCache.poolCh)
+			put(testCache, c.user)
+			c.busy(t, testCache.pool)
 			err := testCache.UserDelete(ctx, c.user.Name)
-			actualUser := testCache.data[c.user.Name]
-			delete(testCache.data, c.user.Name)
+			actualUser := get(testCache, c.user.Name)
+			drop(testCache, c.user.Name)
 
 			assert.ErrorIs(t, err, c.expErr)
 			assert.Equal(t, c.expUser, actualUser)
@@ -193,13 +203,85 @@ func TestCache_UserDelete(t *testing.T) {
 	}
 }
 
-func TestCache_UserGet(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
+func TestCache_UserRename(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	cases := []struct {
+		name    string
+		users   []models.User
+		oldName string
+		newName string
+		expErr  error
+		expData map[string]models.User
+		busy    func(*testing.T, *workerpool.Pool)
+	}{
+		{
+			name:    "success",
+			users:   []models.User{user1},
+			oldName: user1.Name,
+			newName: "ivan_renamed",
+			expData: map[string]models.User{
+				"ivan_renamed": modeltest.From(user1).WithName("ivan_renamed").Build(),
+			},
+			busy: func(*testing.T, *workerpool.Pool) {},
+		},
+		{
+			name:    "failed, user not found",
+			users:   []models.User{user3},
+			oldName: user1.Name,
+			newName: "ivan_renamed",
+			expErr:  errorsPkg.ErrUserNotFound,
+			expData: map[string]models.User{user3.Name: user3},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+		},
+		{
+			name:    "failed, new name is taken",
+			users:   []models.User{user1, user3},
+			oldName: user1.Name,
+			newName: user3.Name,
+			expErr:  errorsPkg.ErrUserAlreadyExists,
+			expData: map[string]models.User{user1.Name: user1, user3.Name: user3},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+		},
+		{
+			name:    "failed, deadline exceeded",
+			users:   []models.User{user1},
+			oldName: user1.Name,
+			newName: "ivan_renamed",
+			expErr:  errorsPkg.ErrTimeout,
+			expData: map[string]models.User{user1.Name: user1},
+			busy:    saturate,
+		},
 	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testCache.users.Clear()
+			testCache.names = nil
+			for _, u := range c.users {
+				put(testCache, u)
+			}
+			c.busy(t, testCache.pool)
+			since := time.Now().Unix()
+			err := testCache.UserRename(ctx, c.oldName, c.newName)
+
+			assert.ErrorIs(t, err, c.expErr)
+			// the renamed user is stamped by the rename
+			data := all(testCache)
+			if renamed, ok := data[c.newName]; ok && err == nil {
+				assert.GreaterOrEqual(t, renamed.UpdatedAt, since)
+				renamed.UpdatedAt = c.expData[c.newName].UpdatedAt
+				data[c.newName] = renamed
+			}
+			assert.Equal(t, c.expData, data)
+		})
+	}
+}
+
+func TestCache_UserGet(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
@@ -208,32 +290,30 @@ func TestCache_UserGet(t *testing.T) {
 		user    models.User
 		expErr  error
 		expUser models.User
-		poolCh  func(chan struct{})
+		busy    func(*testing.T, *workerpool.Pool)
 	}{
 		{
 			name:    "success",
 			user:    user1,
 			expErr:  nil,
 			expUser: user1,
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 		},
 		{
 			name:    "failed, deadline exceeded",
 			user:    user1,
 			expErr:  errorsPkg.ErrTimeout,
 			expUser: models.User{},
-			poolCh: func(ch chan struct{}) {
-				ch <- struct{}{}
-			},
+			busy:    saturate,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			testCache.data[c.user.Name] = c.user
-			c.poolCh(testCache.poolCh)
+			put(testCache, c.user)
+			c.busy(t, testCache.pool)
 			actualUser, err := testCache.UserGet(ctx, c.user.Name)
-			delete(testCache.data, c.user.Name)
+			drop(testCache, c.user.Name)
 
 			assert.ErrorIs(t, err, c.expErr)
 			assert.Equal(t, c.expUser, actualUser)
@@ -241,35 +321,67 @@ func TestCache_UserGet(t *testing.T) {
 	}
 }
 
-func TestCache_UserList(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
+func TestCache_UserGetByID(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	put(testCache, user1)
+	put(testCache, user3)
+
+	cases := []struct {
+		name    string
+		id      string
+		expErr  error
+		expUser models.User
+	}{
+		{
+			name:    "success",
+			id:      user3.ID,
+			expUser: user3,
+		},
+		{
+			name:    "failed, user not found",
+			id:      user4.ID,
+			expErr:  errorsPkg.ErrUserNotFound,
+			expUser: models.User{},
+		},
 	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actualUser, err := testCache.UserGetByID(ctx, c.id)
+
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expUser, actualUser)
+		})
+	}
+}
+
+func TestCache_UserList(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
-	testCache.data[user1.Name] = user1
-	testCache.data[user3.Name] = user3
-	testCache.data[user4.Name] = user4
+	put(testCache, user1)
+	put(testCache, user3)
+	put(testCache, user4)
 
 	cases := []struct {
 		name    string
 		list    []models.User
 		expErr  error
 		expList []models.User
-		poolCh  func(chan struct{})
+		busy    func(*testing.T, *workerpool.Pool)
 		order   bool
 		limit   uint64
 		offset  uint64
+		where   filter.Expr
 	}{
 		{
 			name:    "success, asc, all users",
 			list:    []models.User{user1, user3, user4},
 			expErr:  nil,
 			expList: []models.User{user4, user3, user1},
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 			order:   false,
 			limit:   3,
 			offset:  0,
@@ -279,7 +391,7 @@ func TestCache_UserList(t *testing.T) {
 			list:    []models.User{user1, user3, user4},
 			expErr:  nil,
 			expList: []models.User{user1, user3, user4},
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 			order:   true,
 			limit:   3,
 			offset:  0,
@@ -289,7 +401,7 @@ func TestCache_UserList(t *testing.T) {
 			list:    []models.User{user1, user3, user4},
 			expErr:  nil,
 			expList: make([]models.User, 0),
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 			order:   true,
 			limit:   2,
 			offset:  2,
@@ -299,29 +411,89 @@ func TestCache_UserList(t *testing.T) {
 			list:    []models.User{user1, user3, user4},
 			expErr:  nil,
 			expList: []models.User{user4},
-			poolCh:  func(_ chan struct{}) {},
+			busy:    func(*testing.T, *workerpool.Pool) {},
 			order:   true,
 			limit:   2,
 			offset:  1,
 		},
+		{
+			name:    "success, attributes filter",
+			list:    []models.User{user1, user3, user4},
+			expErr:  nil,
+			expList: []models.User{user3},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+			order:   false,
+			limit:   3,
+			offset:  0,
+			where:   filter.ListParams(map[string]string{"team": "core"}, ""),
+		},
+		{
+			name:    "success, status filter",
+			list:    []models.User{user1, user3, user4},
+			expErr:  nil,
+			expList: make([]models.User, 0),
+			busy:    func(*testing.T, *workerpool.Pool) {},
+			order:   false,
+			limit:   3,
+			offset:  0,
+			where:   filter.ListParams(nil, models.StatusDisabled),
+		},
+		{
+			name:    "success, or filter",
+			list:    []models.User{user1, user3, user4},
+			expList: []models.User{user3, user1},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+			limit:   3,
+			where: filter.Or{
+				filter.Cond{Field: filter.FieldName, Op: filter.OpPrefix, Value: "Iv"},
+				filter.And{
+					filter.Cond{Field: filter.FieldCreatedAt, Op: filter.OpGe, Value: int64(1660412960)},
+					filter.Cond{Field: filter.Attribute("team"), Op: filter.OpNe, Value: "ops"},
+				},
+			},
+		},
+		{
+			name:    "success, asc, second page",
+			list:    []models.User{user1, user3, user4},
+			expList: []models.User{user3},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+			limit:   1,
+			offset:  1,
+		},
+		{
+			name:    "success, filtered second page",
+			list:    []models.User{user1, user3, user4},
+			expList: []models.User{user3},
+			busy:    func(*testing.T, *workerpool.Pool) {},
+			order:   true,
+			limit:   1,
+			offset:  1,
+			where:   filter.Cond{Field: filter.FieldName, Op: filter.OpNe, Value: user4.Name},
+		},
+		{
+			name:   "failed, invalid filter",
+			list:   []models.User{user1, user3, user4},
+			expErr: errorsPkg.ErrValidation,
+			busy:   func(*testing.T, *workerpool.Pool) {},
+			limit:  3,
+			where:  filter.Cond{Field: filter.FieldCreatedAt, Op: filter.OpPrefix, Value: "166"},
+		},
 		{
 			name:    "failed, deadline exceeded",
 			list:    []models.User{user1, user3, user4},
 			expErr:  errorsPkg.ErrTimeout,
 			expList: nil,
-			poolCh: func(ch chan struct{}) {
-				ch <- struct{}{}
-			},
-			order:  false,
-			limit:  2,
-			offset: 1,
+			busy:    saturate,
+			order:   false,
+			limit:   2,
+			offset:  1,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			c.poolCh(testCache.poolCh)
-			actuaList, err := testCache.UserList(ctx, c.order, c.limit, c.offset)
+			c.busy(t, testCache.pool)
+			actuaList, err := testCache.UserList(ctx, c.order, c.limit, c.offset, c.where)
 
 			assert.ErrorIs(t, err, c.expErr)
 			assert.Equal(t, c.expList, actuaList)
@@ -329,19 +501,143 @@ func TestCache_UserList(t *testing.T) {
 	}
 }
 
+func TestCache_UserSnapshot(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	put(testCache, user1)
+	put(testCache, user3)
+	put(testCache, user4)
+
+	t.Run("success, ordered by name", func(t *testing.T) {
+		var users []models.User
+		err := testCache.UserSnapshot(context.Background(), func(user models.User) error {
+			users = append(users, user)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{user4, user3, user1}, users)
+	})
+
+	t.Run("failed, fn error stops iteration", func(t *testing.T) {
+		var count int
+		err := testCache.UserSnapshot(context.Background(), func(models.User) error {
+			count++
+			return errorsPkg.ErrUnexpected
+		})
+		assert.ErrorIs(t, err, errorsPkg.ErrUnexpected)
+		assert.Equal(t, 1, count)
+	})
+}
+
 func TestCache_Close(t *testing.T) {
-	testCache := cache{
-		mu:     sync.RWMutex{},
-		data:   make(map[string]models.User),
-		poolCh: make(chan struct{}, 1),
-		logger: loggerPkg.NewFatal(),
-	}
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
 
 	t.Run("success memory clear", func(t *testing.T) {
 		testCache.Close()
-		_, ok := <-testCache.poolCh
+		_, err := testCache.UserGet(context.Background(), user1.Name)
+
+		assert.Zero(t, testCache.users.Len())
+		assert.ErrorIs(t, err, workerpool.ErrClosed)
+	})
+}
+
+func TestCache_Index(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	ctx := context.Background()
+	for _, user := range []models.User{user1, user3, user4} {
+		assert.NoError(t, testCache.UserCreate(ctx, user))
+	}
+	assert.NoError(t, testCache.UserUpdate(ctx, user3))
+	assert.Equal(t, []string{user4.Name, user3.Name, user1.Name}, testCache.names)
+
+	assert.NoError(t, testCache.UserRename(ctx, user4.Name, "Zed"))
+	assert.NoError(t, testCache.UserDelete(ctx, user3.Name))
+	assert.NoError(t, testCache.UserDelete(ctx, "unknown"))
+	assert.Equal(t, []string{user1.Name, "Zed"}, testCache.names)
+}
 
-		assert.Nil(t, testCache.data)
-		assert.False(t, ok)
+func TestCache_UserCounts(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	ctx := context.Background()
+	acme := modeltest.NewUser().WithName("acme").WithAttribute("tenant", "acme").Build()
+	for _, user := range []models.User{user1, user3, acme} {
+		assert.NoError(t, testCache.UserCreate(ctx, user))
+	}
+
+	moved := user3
+	moved.Attributes = map[string]string{"tenant": "acme"}
+	assert.NoError(t, testCache.UserUpdate(ctx, moved))
+	assert.NoError(t, testCache.UserRename(ctx, acme.Name, "acme2"))
+	assert.NoError(t, testCache.UserDelete(ctx, user1.Name))
+
+	counts, err := testCache.UserCounts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"acme": 2}, counts)
+
+	t.Run("success, reconcile corrects drift", func(t *testing.T) {
+		testCache.users.Update(func(*tx) {
+			testCache.counts = map[string]int64{"acme": 5, "lost": 1}
+		})
+		corrected, err := testCache.UserCountsReconcile(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), corrected)
+
+		counts, err := testCache.UserCounts(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"acme": 2}, counts)
+
+		corrected, err = testCache.UserCountsReconcile(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, corrected)
 	})
 }
+
+func TestCache_Compact(t *testing.T) {
+	// deletions of the typed cache compacting the map
+	const deletions = 1024
+	testCache := newCache(nil, loggerPkg.NewFatal())
+	for i := 0; i < deletions+1; i++ {
+		put(testCache, models.User{Name: fmt.Sprintf("user_%d", i)})
+	}
+	resize, evictions := counter.LocalResize.Value(), counter.LocalEvictions.Value()
+
+	for i := 0; i < deletions; i++ {
+		drop(testCache, fmt.Sprintf("user_%d", i))
+	}
+
+	assert.Equal(t, resize+1, counter.LocalResize.Value())
+	// deleted users are not evictions
+	assert.Equal(t, evictions, counter.LocalEvictions.Value())
+	assert.Equal(t, 1, testCache.users.Len())
+	assert.Equal(t, []string{"user_1024"}, testCache.names)
+	assert.Equal(t, entrySize("user_1024", models.User{Name: "user_1024"}), testCache.users.Size())
+	assert.Equal(t, int64(1), counter.LocalEntries.Value())
+}
+
+func TestCache_ListSnapshot(t *testing.T) {
+	testCache := newCache(workerpool.New("test", workerpool.Config{Workers: 1}, zap.NewNop().Sugar()), loggerPkg.NewFatal())
+	for _, user := range []models.User{user1, user3, user4} {
+		put(testCache, user)
+	}
+	ctx, release := repoPkg.WithListSnapshot(context.Background())
+	defer release()
+
+	page, err := testCache.UserList(ctx, false, 1, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{user4}, page)
+
+	// writes after the first page are not seen by the next pages of the request
+	assert.NoError(t, testCache.UserCreate(ctx, models.User{Name: "Aaron"}))
+	assert.NoError(t, testCache.UserDelete(ctx, user1.Name))
+	page, err = testCache.UserList(ctx, false, 1, 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{user3}, page)
+	page, err = testCache.UserList(ctx, false, 1, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{user1}, page)
+
+	// other requests read the current version
+	page, err = testCache.UserList(context.Background(), false, 10, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{{Name: "Aaron"}, user4, user3}, page)
+	assert.Equal(t, uint64(5), testCache.version)
+}
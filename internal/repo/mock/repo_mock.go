@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	models "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	filter "gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 )
 
 // MockInterface is a mock of Interface interface.
@@ -47,6 +48,36 @@ func (mr *MockInterfaceMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockInterface)(nil).Close))
 }
 
+// UserCounts mocks base method.
+func (m *MockInterface) UserCounts(ctx context.Context) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCounts", ctx)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCounts indicates an expected call of UserCounts.
+func (mr *MockInterfaceMockRecorder) UserCounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCounts", reflect.TypeOf((*MockInterface)(nil).UserCounts), ctx)
+}
+
+// UserCountsReconcile mocks base method.
+func (m *MockInterface) UserCountsReconcile(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCountsReconcile", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCountsReconcile indicates an expected call of UserCountsReconcile.
+func (mr *MockInterfaceMockRecorder) UserCountsReconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCountsReconcile", reflect.TypeOf((*MockInterface)(nil).UserCountsReconcile), ctx)
+}
+
 // UserCreate mocks base method.
 func (m *MockInterface) UserCreate(ctx context.Context, user models.User) error {
 	m.ctrl.T.Helper()
@@ -90,19 +121,92 @@ func (mr *MockInterfaceMockRecorder) UserGet(ctx, name interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGet", reflect.TypeOf((*MockInterface)(nil).UserGet), ctx, name)
 }
 
+// UserGetByID mocks base method.
+func (m *MockInterface) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGetByID", ctx, id)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGetByID indicates an expected call of UserGetByID.
+func (mr *MockInterfaceMockRecorder) UserGetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGetByID", reflect.TypeOf((*MockInterface)(nil).UserGetByID), ctx, id)
+}
+
 // UserList mocks base method.
-func (m *MockInterface) UserList(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error) {
+func (m *MockInterface) UserList(ctx context.Context, order bool, limit, offset uint64, where filter.Expr) ([]models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserList", ctx, order, limit, offset)
+	ret := m.ctrl.Call(m, "UserList", ctx, order, limit, offset, where)
 	ret0, _ := ret[0].([]models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UserList indicates an expected call of UserList.
-func (mr *MockInterfaceMockRecorder) UserList(ctx, order, limit, offset interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) UserList(ctx, order, limit, offset, where interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserList", reflect.TypeOf((*MockInterface)(nil).UserList), ctx, order, limit, offset, where)
+}
+
+// UserRename mocks base method.
+func (m *MockInterface) UserRename(ctx context.Context, oldName, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRename", ctx, oldName, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserRename indicates an expected call of UserRename.
+func (mr *MockInterfaceMockRecorder) UserRename(ctx, oldName, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRename", reflect.TypeOf((*MockInterface)(nil).UserRename), ctx, oldName, newName)
+}
+
+// UserSnapshot mocks base method.
+func (m *MockInterface) UserSnapshot(ctx context.Context, fn func(models.User) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserSnapshot", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserSnapshot indicates an expected call of UserSnapshot.
+func (mr *MockInterfaceMockRecorder) UserSnapshot(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSnapshot", reflect.TypeOf((*MockInterface)(nil).UserSnapshot), ctx, fn)
+}
+
+// UserTombstones mocks base method.
+func (m *MockInterface) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserTombstones", ctx, since)
+	ret0, _ := ret[0].([]models.Tombstone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserTombstones indicates an expected call of UserTombstones.
+func (mr *MockInterfaceMockRecorder) UserTombstones(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserTombstones", reflect.TypeOf((*MockInterface)(nil).UserTombstones), ctx, since)
+}
+
+// UserTombstonesPrune mocks base method.
+func (m *MockInterface) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserTombstonesPrune", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserTombstonesPrune indicates an expected call of UserTombstonesPrune.
+func (mr *MockInterfaceMockRecorder) UserTombstonesPrune(ctx, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserList", reflect.TypeOf((*MockInterface)(nil).UserList), ctx, order, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserTombstonesPrune", reflect.TypeOf((*MockInterface)(nil).UserTombstonesPrune), ctx, before)
 }
 
 // UserUpdate mocks base method.
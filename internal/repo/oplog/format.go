@@ -0,0 +1,279 @@
+package oplog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	codecPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/codec"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+// header starts files of the log, its last byte is a version of the format.
+const header = "OPLOG\x01"
+
+const redacted = "***"
+
+// maxRecordSize protects readers from lengths of corrupted records.
+const maxRecordSize = 16 << 20
+
+// Tags of filter expressions.
+const (
+	exprNil byte = iota
+	exprCond
+	exprAnd
+	exprOr
+)
+
+// Tags of condition values, values of other types are written as strings.
+const (
+	valueString byte = iota
+	valueInt
+)
+
+// users are encoded by the cache codec, so the log follows new fields of the user.
+var users, _ = codecPkg.New(codecPkg.FormatMsgpack)
+
+// appendRecord appends the record prefixed by its length. Fields of the record are written
+// in order of the struct, the user is empty for operations without it.
+func appendRecord(dst []byte, rec Record) ([]byte, error) {
+	var body []byte
+	body = appendString(body, rec.Op)
+	body = appendVarint(body, rec.TS)
+	body = appendVarint(body, int64(rec.Duration))
+	body = append(body, byte(rec.Code))
+
+	var user []byte
+	if rec.Op == OpUserCreate || rec.Op == OpUserUpdate {
+		var err error
+		if user, err = users.EncodeUser(rec.User); err != nil {
+			return nil, errors.Wrap(err, "oplog")
+		}
+	}
+	body = appendBytes(body, user)
+	body = appendString(body, rec.Name)
+	body = appendString(body, rec.NewName)
+	body = appendString(body, rec.ID)
+	order := byte(0)
+	if rec.Order {
+		order = 1
+	}
+	body = append(body, order)
+	body = appendUvarint(body, rec.Limit)
+	body = appendUvarint(body, rec.Offset)
+	body = appendExpr(body, rec.Where)
+	body = appendVarint(body, rec.Since)
+
+	return appendBytes(dst, body), nil
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(dst, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(dst []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(dst, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = appendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendBytes(dst, b []byte) []byte {
+	dst = appendUvarint(dst, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendExpr(dst []byte, expr filter.Expr) []byte {
+	switch e := expr.(type) {
+	case filter.Cond:
+		dst = append(dst, exprCond)
+		dst = appendString(dst, string(e.Field))
+		dst = appendString(dst, string(e.Op))
+		if v, ok := e.Value.(int64); ok {
+			return appendVarint(append(dst, valueInt), v)
+		}
+		return appendString(append(dst, valueString), fmt.Sprint(e.Value))
+	case filter.And:
+		return appendExprs(append(dst, exprAnd), e)
+	case filter.Or:
+		return appendExprs(append(dst, exprOr), e)
+	}
+	return append(dst, exprNil)
+}
+
+func appendExprs(dst []byte, exprs []filter.Expr) []byte {
+	dst = appendUvarint(dst, uint64(len(exprs)))
+	for _, e := range exprs {
+		dst = appendExpr(dst, e)
+	}
+	return dst
+}
+
+// Reader reads records of a file of the log.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns reader of the log, the header is checked.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(header))
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, errors.Wrap(err, "oplog: read header")
+	}
+	if string(head) != header {
+		return nil, errors.New("oplog: unknown header")
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next record, io.EOF is returned at the end of the log. The record
+// cut by the crash of the writer is io.ErrUnexpectedEOF.
+func (r *Reader) Next() (Record, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Record{}, err
+	}
+	if size > maxRecordSize {
+		return Record{}, errors.Errorf("oplog: record of %d bytes", size)
+	}
+	body := make([]byte, size)
+	if _, err = io.ReadFull(r.r, body); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+	return decodeRecord(body)
+}
+
+// decoder reads fields of the record, the first error stops reading.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func decodeRecord(body []byte) (Record, error) {
+	d := &decoder{data: body}
+	rec := Record{
+		Op:       d.string(),
+		TS:       d.varint(),
+		Duration: time.Duration(d.varint()),
+		Code:     Code(d.byte()),
+	}
+	if user := d.bytes(); len(user) != 0 && d.err == nil {
+		decoded, _, err := users.DecodeUser(user)
+		if err != nil {
+			return Record{}, errors.Wrap(err, "oplog: decode user")
+		}
+		rec.User = decoded
+	}
+	rec.Name = d.string()
+	rec.NewName = d.string()
+	rec.ID = d.string()
+	rec.Order = d.byte() == 1
+	rec.Limit = d.uvarint()
+	rec.Offset = d.uvarint()
+	rec.Where = d.expr()
+	rec.Since = d.varint()
+	if d.err != nil {
+		return Record{}, errors.Wrapf(d.err, "oplog: decode record [%s]", rec.Op)
+	}
+	return rec, nil
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = io.ErrUnexpectedEOF
+	}
+	d.data = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail()
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	size := d.uvarint()
+	if size > uint64(len(d.data)) {
+		d.fail()
+		return nil
+	}
+	b := d.data[:size]
+	d.data = d.data[size:]
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) expr() filter.Expr {
+	switch tag := d.byte(); tag {
+	case exprCond:
+		c := filter.Cond{Field: filter.Field(d.string()), Op: filter.Op(d.string())}
+		if d.byte() == valueInt {
+			c.Value = d.varint()
+		} else {
+			c.Value = d.string()
+		}
+		return c
+	case exprAnd:
+		return filter.And(d.exprs())
+	case exprOr:
+		return filter.Or(d.exprs())
+	case exprNil:
+	default:
+		if d.err == nil {
+			d.err = errors.Errorf("unknown expression [%d]", tag)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) exprs() []filter.Expr {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return nil
+	}
+	exprs := make([]filter.Expr, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		exprs = append(exprs, d.expr())
+	}
+	return exprs
+}
@@ -0,0 +1,278 @@
+// Package oplog records calls of the repository to a binary log: the operation, its arguments,
+// the result code and the duration. The log is replayed against a fresh repository by Replay,
+// so bugs seen in production only are reproduced deterministically by a test.
+package oplog
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+const defaultMaxSize = 64 << 20
+
+// Operations of records, they are names of the repository methods.
+const (
+	OpUserCreate          = "UserCreate"
+	OpUserUpdate          = "UserUpdate"
+	OpUserDelete          = "UserDelete"
+	OpUserRename          = "UserRename"
+	OpUserGet             = "UserGet"
+	OpUserGetByID         = "UserGetByID"
+	OpUserList            = "UserList"
+	OpUserSnapshot        = "UserSnapshot"
+	OpUserTombstones      = "UserTombstones"
+	OpUserTombstonesPrune = "UserTombstonesPrune"
+	OpUserCounts          = "UserCounts"
+	OpUserCountsReconcile = "UserCountsReconcile"
+)
+
+// Code is a result of the call, errors are reduced to their class, so results of replays
+// compare with the recorded ones.
+type Code byte
+
+const (
+	CodeOK Code = iota
+	CodeNotFound
+	CodeAlreadyExists
+	CodeValidation
+	CodeTimeout
+	CodeError
+)
+
+var codeNames = map[Code]string{
+	CodeOK:            "ok",
+	CodeNotFound:      "not_found",
+	CodeAlreadyExists: "already_exists",
+	CodeValidation:    "validation",
+	CodeTimeout:       "timeout",
+	CodeError:         "error",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// CodeOf returns the result code of the error.
+func CodeOf(err error) Code {
+	switch {
+	case err == nil:
+		return CodeOK
+	case errors.Is(err, errorsPkg.ErrUserNotFound):
+		return CodeNotFound
+	case errors.Is(err, errorsPkg.ErrUserAlreadyExists):
+		return CodeAlreadyExists
+	case errors.Is(err, errorsPkg.ErrValidation):
+		return CodeValidation
+	case errors.Is(err, errorsPkg.ErrTimeout), errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return CodeTimeout
+	}
+	return CodeError
+}
+
+// Config of the log.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// File of the log, it is created with the header, if it does not exist, and appended.
+	File string `mapstructure:"file"`
+	// MaxSize of the file in bytes. The full file is moved to File.1, which replaces the previous one,
+	// so the log takes up to twice the size. Default is 64 MiB.
+	MaxSize int64 `mapstructure:"max_size"`
+}
+
+// Record of the call. Arguments of other operations are zero, the password of the user is redacted.
+type Record struct {
+	Op string
+	// TS is a time of the call in UNIX nanoseconds.
+	TS       int64
+	Duration time.Duration
+	Code     Code
+
+	User    models.User
+	Name    string
+	NewName string
+	ID      string
+	Order   bool
+	Limit   uint64
+	Offset  uint64
+	Where   filter.Expr
+	// Since is the time of UserTombstones and the time before of UserTombstonesPrune.
+	Since int64
+}
+
+// New wraps repository, calls are recorded to the log of the config.
+func New(data repoPkg.Interface, cfg Config, logger *zap.SugaredLogger) (repoPkg.Interface, error) {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+	r := &repo{
+		data:   data,
+		cfg:    cfg,
+		logger: logger,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	logger.Infof("With operation log started, file %s, max size %d", cfg.File, cfg.MaxSize)
+	return r, nil
+}
+
+type repo struct {
+	data   repoPkg.Interface
+	cfg    Config
+	logger *zap.SugaredLogger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) (err error) {
+	defer r.record(time.Now(), Record{Op: OpUserCreate, User: user}, &err)
+	return r.data.UserCreate(ctx, user)
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) (err error) {
+	defer r.record(time.Now(), Record{Op: OpUserUpdate, User: user}, &err)
+	return r.data.UserUpdate(ctx, user)
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) (err error) {
+	defer r.record(time.Now(), Record{Op: OpUserDelete, Name: name}, &err)
+	return r.data.UserDelete(ctx, name)
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) (err error) {
+	defer r.record(time.Now(), Record{Op: OpUserRename, Name: oldName, NewName: newName}, &err)
+	return r.data.UserRename(ctx, oldName, newName)
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (_ models.User, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserGet, Name: name}, &err)
+	return r.data.UserGet(ctx, name)
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (_ models.User, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserGetByID, ID: id}, &err)
+	return r.data.UserGetByID(ctx, id)
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) (_ []models.User, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserList, Order: order, Limit: limit, Offset: offset, Where: where}, &err)
+	return r.data.UserList(ctx, order, limit, offset, where)
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) (err error) {
+	defer r.record(time.Now(), Record{Op: OpUserSnapshot}, &err)
+	return r.data.UserSnapshot(ctx, fn)
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) (_ []models.Tombstone, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserTombstones, Since: since}, &err)
+	return r.data.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (_ int64, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserTombstonesPrune, Since: before}, &err)
+	return r.data.UserTombstonesPrune(ctx, before)
+}
+
+func (r *repo) UserCounts(ctx context.Context) (_ map[string]int64, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserCounts}, &err)
+	return r.data.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (_ int64, err error) {
+	defer r.record(time.Now(), Record{Op: OpUserCountsReconcile}, &err)
+	return r.data.UserCountsReconcile(ctx)
+}
+
+func (r *repo) Close() {
+	r.data.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.file.Close(); err != nil {
+		r.logger.Errorf("operation log close: %v", err)
+	}
+}
+
+// record writes the call to the log, errors of the log are logged and do not fail the call.
+func (r *repo) record(start time.Time, rec Record, err *error) {
+	rec.TS = start.UnixNano()
+	rec.Duration = time.Since(start)
+	rec.Code = CodeOf(*err)
+	if rec.User.Password != "" {
+		rec.User.Password = redacted
+	}
+	data, encodeErr := appendRecord(nil, rec)
+	if encodeErr != nil {
+		r.logger.Errorw("operation log encode", "op", rec.Op, "error", encodeErr.Error())
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size+int64(len(data)) > r.cfg.MaxSize && r.size > int64(len(header)) {
+		if rotateErr := r.rotate(); rotateErr != nil {
+			r.logger.Errorw("operation log rotate", "file", r.cfg.File, "error", rotateErr.Error())
+		}
+	}
+	n, writeErr := r.file.Write(data)
+	r.size += int64(n)
+	if writeErr != nil {
+		r.logger.Errorw("operation log write", "op", rec.Op, "error", writeErr.Error())
+	}
+}
+
+// open opens the log file, the header is written to the new one.
+func (r *repo) open() error {
+	file, err := os.OpenFile(r.cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "oplog: open")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "oplog: stat")
+	}
+	r.file, r.size = file, info.Size()
+	if r.size == 0 {
+		n, err := file.Write([]byte(header))
+		r.size = int64(n)
+		if err != nil {
+			_ = file.Close()
+			return errors.Wrap(err, "oplog: write header")
+		}
+	}
+	return nil
+}
+
+// rotate moves the full file to File.1, the file is reopened, even if it is not moved.
+func (r *repo) rotate() error {
+	closeErr := r.file.Close()
+	renameErr := os.Rename(r.cfg.File, r.cfg.File+".1")
+	if err := r.open(); err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return errors.Wrap(closeErr, "oplog: close")
+	}
+	return errors.Wrap(renameErr, "oplog: rename")
+}
@@ -0,0 +1,114 @@
+package oplog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	localPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/local"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
+)
+
+func newLocal(t *testing.T) repoPkg.Interface {
+	t.Helper()
+	return localPkg.New(workerpool.New("test", workerpool.Config{Workers: 1}, loggerPkg.NewFatal()), loggerPkg.NewFatal())
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "repo.oplog")
+	data, err := New(newLocal(t), Config{File: file}, loggerPkg.NewFatal())
+	require.NoError(t, err)
+
+	user := models.User{ID: "1", Name: "ivan", Password: "hash", Attributes: map[string]string{"team": "core"}}
+	require.NoError(t, data.UserCreate(ctx, user))
+	require.NoError(t, data.UserRename(ctx, "ivan", "petr"))
+	assert.ErrorIs(t, data.UserRename(ctx, "ivan", "petr"), errorsPkg.ErrUserNotFound)
+	_, err = data.UserGet(ctx, "ivan")
+	assert.Error(t, err)
+	_, err = data.UserList(ctx, true, 10, 0, filter.Or{
+		filter.ListParams(map[string]string{"team": "core"}, ""),
+		filter.Cond{Field: filter.FieldCreatedAt, Op: filter.OpGe, Value: int64(1660412960)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, data.UserDelete(ctx, "petr"))
+	data.Close()
+
+	// the log read back keeps arguments and results, the password is not written
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	reader, err := NewReader(f)
+	require.NoError(t, err)
+	var records []Record
+	for {
+		rec, err := reader.Next()
+		if err != nil {
+			break
+		}
+		records = append(records, rec)
+	}
+	require.NoError(t, f.Close())
+	require.Len(t, records, 6)
+	assert.Equal(t, redacted, records[0].User.Password)
+	assert.Equal(t, "petr", records[1].NewName)
+	assert.Equal(t, CodeNotFound, records[2].Code)
+	assert.Equal(t, CodeNotFound, records[3].Code)
+	assert.Equal(t, filter.Or{
+		filter.And{filter.Cond{Field: filter.Attribute("team"), Op: filter.OpEq, Value: "core"}},
+		filter.Cond{Field: filter.FieldCreatedAt, Op: filter.OpGe, Value: int64(1660412960)},
+	}, records[4].Where)
+
+	// the fresh repository reproduces all results
+	f, err = os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	report, err := Replay(ctx, f, newLocal(t))
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Replayed)
+	assert.Empty(t, report.Mismatches)
+
+	// the repository of other state differs from the first rename
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	other := newLocal(t)
+	require.NoError(t, other.UserCreate(ctx, models.User{ID: "2", Name: "petr"}))
+	report, err = Replay(ctx, f, other)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Mismatches)
+	assert.Equal(t, 1, report.Mismatches[0].Index)
+	assert.Equal(t, CodeAlreadyExists, report.Mismatches[0].Code)
+}
+
+func TestRepo_Rotate(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "repo.oplog")
+	data, err := New(newLocal(t), Config{File: file, MaxSize: 64}, loggerPkg.NewFatal())
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, _ = data.UserGet(ctx, "ivan")
+	}
+	data.Close()
+
+	for _, name := range []string{file + ".1", file} {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(64))
+
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		report, err := Replay(ctx, f, newLocal(t))
+		require.NoError(t, f.Close())
+		require.NoError(t, err)
+		assert.NotZero(t, report.Replayed)
+		assert.Empty(t, report.Mismatches)
+	}
+}
@@ -0,0 +1,85 @@
+package oplog
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+// Mismatch is the replayed call, which result code differs from the recorded one.
+type Mismatch struct {
+	// Index of the record in the log.
+	Index  int
+	Record Record
+	Code   Code
+	Err    error
+}
+
+// Report of the replay.
+type Report struct {
+	Replayed   int
+	Mismatches []Mismatch
+}
+
+// Replay calls the recorded operations in order on the repository, e.g. a fresh local one
+// of a test, and reports calls, which result codes differ from the recorded ones. Files of
+// the log are replayed one by one, the rotated File.1 first. The log is bounded, so the
+// state changed before its first record is missing in the repository.
+func Replay(ctx context.Context, r io.Reader, data repoPkg.Interface) (Report, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Report{}, err
+	}
+	var report Report
+	for {
+		rec, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, errors.Wrapf(err, "oplog: record %d", report.Replayed)
+		}
+		callErr := call(ctx, data, rec)
+		if code := CodeOf(callErr); code != rec.Code {
+			report.Mismatches = append(report.Mismatches, Mismatch{Index: report.Replayed, Record: rec, Code: code, Err: callErr})
+		}
+		report.Replayed++
+	}
+}
+
+func call(ctx context.Context, data repoPkg.Interface, rec Record) error {
+	var err error
+	switch rec.Op {
+	case OpUserCreate:
+		err = data.UserCreate(ctx, rec.User)
+	case OpUserUpdate:
+		err = data.UserUpdate(ctx, rec.User)
+	case OpUserDelete:
+		err = data.UserDelete(ctx, rec.Name)
+	case OpUserRename:
+		err = data.UserRename(ctx, rec.Name, rec.NewName)
+	case OpUserGet:
+		_, err = data.UserGet(ctx, rec.Name)
+	case OpUserGetByID:
+		_, err = data.UserGetByID(ctx, rec.ID)
+	case OpUserList:
+		_, err = data.UserList(ctx, rec.Order, rec.Limit, rec.Offset, rec.Where)
+	case OpUserSnapshot:
+		err = data.UserSnapshot(ctx, func(models.User) error { return nil })
+	case OpUserTombstones:
+		_, err = data.UserTombstones(ctx, rec.Since)
+	case OpUserTombstonesPrune:
+		_, err = data.UserTombstonesPrune(ctx, rec.Since)
+	case OpUserCounts:
+		_, err = data.UserCounts(ctx)
+	case OpUserCountsReconcile:
+		_, err = data.UserCountsReconcile(ctx)
+	default:
+		err = errors.Errorf("oplog: unknown operation [%s]", rec.Op)
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const (
+	defaultCheckInterval = 5 * time.Second
+	defaultMinBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff    = 10 * time.Second
+	// checkTimeout limits a single check of the primary
+	checkTimeout = 2 * time.Second
+)
+
+// ErrStandby is returned by the check of a pool connected to a standby, e.g. the demoted primary.
+var ErrStandby = errors.New("connected to a standby")
+
+// FailoverPool is the pool checked by the failover.
+type FailoverPool interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	// CloseIdle closes idle connections, so new ones are made to the current primary.
+	CloseIdle(ctx context.Context)
+}
+
+// idlePool closes idle connections of pgxpool.
+type idlePool struct {
+	*pgxpool.Pool
+}
+
+func (p idlePool) CloseIdle(ctx context.Context) {
+	for _, conn := range p.AcquireAllIdle(ctx) {
+		// closed connections are destroyed by the pool on release
+		_ = conn.Conn().Close(ctx)
+		conn.Release()
+	}
+}
+
+// Failover keeps the pool connected to the primary. Connections to a lost primary fail or,
+// if it is demoted, reject writes, so they are closed and new ones are made by the DSN:
+// host names are resolved again and a multi-host DSN picks the writable host.
+type Failover struct {
+	pool   FailoverPool
+	name   string
+	cfg    pgModels.Failover
+	clock  clock.Clock
+	logger *zap.SugaredLogger
+}
+
+// NewFailover returns the failover of the pool, name tells apart pools in metrics and logs.
+func NewFailover(pool *pgxpool.Pool, name string, cfg pgModels.Failover, clk clock.Clock, logger *zap.SugaredLogger) *Failover {
+	return newFailover(idlePool{Pool: pool}, name, cfg, clk, logger)
+}
+
+func newFailover(pool FailoverPool, name string, cfg pgModels.Failover, clk clock.Clock, logger *zap.SugaredLogger) *Failover {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = defaultMaxBackoff
+		if cfg.MaxBackoff < cfg.MinBackoff {
+			cfg.MaxBackoff = cfg.MinBackoff
+		}
+	}
+	return &Failover{
+		pool:   pool,
+		name:   name,
+		cfg:    cfg,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Check returns an error, if the pool is not connected to the primary.
+func (f *Failover) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	var standby bool
+	if err := f.pool.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&standby); err != nil {
+		return errors.Wrap(err, "primary check")
+	}
+	if standby {
+		return ErrStandby
+	}
+	return nil
+}
+
+// Run checks the primary with the interval and reconnects the pool, when it is lost.
+func (f *Failover) Run(ctx context.Context) {
+	f.logger.Infow("Start postgres failover", "pool", f.name, "check_interval", f.cfg.CheckInterval)
+	ticker := f.clock.NewTicker(f.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			f.logger.Infow("Postgres failover stopped", "pool", f.name)
+			return
+		case <-ticker.C():
+		}
+		if err := f.Check(ctx); err != nil && ctx.Err() == nil {
+			f.reconnect(ctx, err)
+		}
+	}
+}
+
+// reconnect closes idle connections and checks the primary with exponential backoff, until
+// the pool is connected to it again or ctx is done.
+func (f *Failover) reconnect(ctx context.Context, cause error) {
+	counter.RepoFailovers.Inc(f.name)
+	f.logger.Warnw("postgres primary lost", "pool", f.name, "error", cause)
+	started := f.clock.Now()
+	backoff := f.cfg.MinBackoff
+	for attempt := 1; ; attempt++ {
+		f.pool.CloseIdle(ctx)
+		err := f.Check(ctx)
+		if err == nil {
+			f.logger.Infow("postgres primary reconnected", "pool", f.name, "attempts", attempt,
+				"downtime", f.clock.Since(started))
+			return
+		}
+		counter.RepoReconnectsFailed.Inc(f.name)
+		f.logger.Warnw("postgres reconnect failed", "pool", f.name, "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.clock.After(backoff):
+		}
+		if backoff *= 2; backoff > f.cfg.MaxBackoff {
+			backoff = f.cfg.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const primaryCheck = "SELECT pg_is_in_recovery()"
+
+// mockPool counts closes of idle connections.
+type mockPool struct {
+	pgxmock.PgxPoolIface
+	closed int
+}
+
+func (p *mockPool) CloseIdle(context.Context) {
+	p.closed++
+}
+
+func TestFailover_Check(t *testing.T) {
+	cases := []struct {
+		name    string
+		standby bool
+		err     error
+		expErr  bool
+	}{
+		{
+			name: "primary",
+		},
+		{
+			name:    "demoted primary",
+			standby: true,
+			expErr:  true,
+		},
+		{
+			name:   "connection lost",
+			err:    errors.New("connection refused"),
+			expErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mock.Close()
+			query := mock.ExpectQuery(primaryCheck)
+			if c.err != nil {
+				query.WillReturnError(c.err)
+			} else {
+				query.WillReturnRows(pgxmock.NewRows([]string{"pg_is_in_recovery"}).AddRow(c.standby))
+			}
+
+			failover := newFailover(&mockPool{PgxPoolIface: mock}, "pg", pgModels.Failover{},
+				clock.NewFake(time.Unix(0, 0)), loggerPkg.NewFatal())
+			assert.Equal(t, c.expErr, failover.Check(context.Background()) != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFailover_Reconnect(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mock.Close()
+	mock.ExpectQuery(primaryCheck).WillReturnError(errors.New("connection refused"))
+	mock.ExpectQuery(primaryCheck).WillReturnRows(pgxmock.NewRows([]string{"pg_is_in_recovery"}).AddRow(true))
+	mock.ExpectQuery(primaryCheck).WillReturnRows(pgxmock.NewRows([]string{"pg_is_in_recovery"}).AddRow(false))
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	pool := &mockPool{PgxPoolIface: mock}
+	failover := newFailover(pool, "pg", pgModels.Failover{MinBackoff: time.Second, MaxBackoff: 3 * time.Second},
+		clk, loggerPkg.NewFatal())
+
+	done := make(chan struct{})
+	go func() {
+		failover.reconnect(context.Background(), ErrStandby)
+		close(done)
+	}()
+	// backoff doubles after every failed reconnect
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		clk.BlockUntil(1)
+		clk.Advance(backoff - time.Millisecond)
+		select {
+		case <-done:
+			t.Fatal("reconnected before the backoff")
+		default:
+		}
+		clk.Advance(time.Millisecond)
+	}
+	<-done
+	assert.Equal(t, 3, pool.closed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+// filterColumns maps filter fields to columns, attributes are compiled separately.
+var filterColumns = map[filter.Field]string{
+	filter.FieldID:                idField,
+	filter.FieldName:              nameField,
+	filter.FieldEmail:             emailField,
+	filter.FieldFullName:          fullNameField,
+	filter.FieldStatus:            statusField,
+	filter.FieldCreatedAt:         createdAtField,
+	filter.FieldPasswordChangedAt: passwordChangedAtField,
+	filter.FieldPasswordExpiresAt: passwordExpiresAtField,
+	filter.FieldUpdatedAt:         updatedAtField,
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// compileFilter compiles the valid filter to the WHERE condition, nil is returned for nil filter.
+// Absent attributes are NULL, so their conditions are false as in the local repository.
+func compileFilter(expr filter.Expr) (squirrel.Sqlizer, error) {
+	switch e := expr.(type) {
+	case filter.Cond:
+		if key, ok := e.Field.AttributeKey(); ok {
+			return compileAttribute(key, e)
+		}
+		return compileColumn(filterColumns[e.Field], e), nil
+	case filter.And:
+		list, err := compileAll(e)
+		return squirrel.And(list), err
+	case filter.Or:
+		list, err := compileAll(e)
+		return squirrel.Or(list), err
+	default:
+		return nil, nil
+	}
+}
+
+func compileAll(exprs []filter.Expr) ([]squirrel.Sqlizer, error) {
+	list := make([]squirrel.Sqlizer, 0, len(exprs))
+	for _, e := range exprs {
+		sql, err := compileFilter(e)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sql)
+	}
+	return list, nil
+}
+
+func compileColumn(column string, c filter.Cond) squirrel.Sqlizer {
+	switch c.Op {
+	case filter.OpNe:
+		return squirrel.NotEq{column: c.Value}
+	case filter.OpLt:
+		return squirrel.Lt{column: c.Value}
+	case filter.OpLe:
+		return squirrel.LtOrEq{column: c.Value}
+	case filter.OpGt:
+		return squirrel.Gt{column: c.Value}
+	case filter.OpGe:
+		return squirrel.GtOrEq{column: c.Value}
+	case filter.OpPrefix:
+		return squirrel.Expr(column+` LIKE ? ESCAPE '\'`, likeEscaper.Replace(c.Value.(string))+"%")
+	default:
+		return squirrel.Eq{column: c.Value}
+	}
+}
+
+// compileAttribute uses containment for equality, so the attributes index is used.
+func compileAttribute(key string, c filter.Cond) (squirrel.Sqlizer, error) {
+	value := c.Value.(string)
+	switch c.Op {
+	case filter.OpEq:
+		contained, err := json.Marshal(map[string]string{key: value})
+		if err != nil {
+			return nil, err
+		}
+		return squirrel.Expr(attributesField+" @> ?", string(contained)), nil
+	case filter.OpPrefix:
+		return squirrel.Expr(attributesField+`->>? LIKE ? ESCAPE '\'`, key, likeEscaper.Replace(value)+"%"), nil
+	case filter.OpNe:
+		return squirrel.Expr(attributesField+"->>? <> ?", key, value), nil
+	default:
+		return squirrel.Expr(attributesField+"->>? "+string(c.Op)+" ?", key, value), nil
+	}
+}
@@ -1,9 +1,26 @@
 package models
 
+import "time"
+
 type Config struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	DBName   string `mapstructure:"db_name"`
+	// Host may list hosts of the primary and standbys separated by commas, connections are made
+	// to the first writable one then.
+	Host string `mapstructure:"host"`
+	// Port may list ports of the hosts separated by commas, a single port is used for all hosts.
+	Port     string   `mapstructure:"port"`
+	User     string   `mapstructure:"user"`
+	Password string   `mapstructure:"password"`
+	DBName   string   `mapstructure:"db_name"`
+	Failover Failover `mapstructure:"failover"`
+}
+
+// Failover of the primary: the pool is checked with the interval, when the primary is lost,
+// idle connections are closed and the pool reconnects with exponential backoff.
+type Failover struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckInterval of the primary, default is 5s.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// MinBackoff and MaxBackoff bound delays between reconnects, defaults are 100ms and 10s.
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
 }
@@ -2,7 +2,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgtype/pgxtype"
@@ -11,28 +14,66 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	passwordPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/password"
 	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 	"gitlab.ozon.dev/iTukaev/homework/pkg/helper"
 )
 
 const (
-	usersTable = "users"
-
-	nameField      = "name"
-	passwordField  = "password"
-	emailField     = "email"
-	fullNameField  = "full_name"
-	createdAtField = "created_at"
+	usersTable      = "users"
+	tombstonesTable = "users_tombstones"
+	countersTable   = "user_counters"
+
+	idField         = "id"
+	nameField       = "name"
+	passwordField   = "password"
+	emailField      = "email"
+	fullNameField   = "full_name"
+	createdAtField  = "created_at"
+	statusField     = "status"
+	attributesField = "attributes"
+
+	passwordChangedAtField = "password_changed_at"
+	passwordExpiresAtField = "password_expires_at"
+	hlcField               = "hlc"
+	updatedAtField         = "updated_at"
+	deletedAtField         = "deleted_at"
+	tenantField            = "tenant"
+	usersField             = "users"
 
 	desc = " DESC"
 
+	snapshotIsolation = "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"
+
 	repoService = "repo"
+
+	// countersLock blocks counter updates of writes, while counters are recounted,
+	// reads of counters are not blocked
+	countersLock = "LOCK TABLE " + countersTable + " IN EXCLUSIVE MODE"
 )
 
+// tenantExpr is the counter key of the user row, users without the tenant have empty one.
+var tenantExpr = "COALESCE(" + attributesField + " ->> '" + passwordPkg.TenantAttribute + "', '')"
+
+// countUsers adds users of the rows selected by the query to the counters.
+func countUsers(selected string) string {
+	return "INSERT INTO " + countersTable + " (" + tenantField + ", " + usersField + ") " + selected +
+		" ON CONFLICT (" + tenantField + ") DO UPDATE SET " + usersField + " = " + countersTable + "." + usersField +
+		" + EXCLUDED." + usersField
+}
+
+var userColumns = []string{
+	idField, nameField, passwordField, emailField, fullNameField, createdAtField, statusField, attributesField,
+	passwordChangedAtField, passwordExpiresAtField, hlcField, updatedAtField,
+}
+
 type PgxPool interface {
 	pgxtype.Querier
+	Begin(ctx context.Context) (pgx.Tx, error)
 	Close()
 }
 
@@ -47,6 +88,10 @@ func New(pool *pgxpool.Pool, logger *zap.SugaredLogger) repoPkg.Interface {
 func NewPostgres(ctx context.Context, host, port, user, password, dbname string, logger *zap.SugaredLogger) (*pgxpool.Pool, error) {
 	psqlConn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
+	// hosts are tried in order, so the pool connects to the primary after failovers too
+	if strings.Contains(host, ",") {
+		psqlConn += " target_session_attrs=read-write"
+	}
 	logger.Debugln("PostgreSQL connection", psqlConn)
 
 	pool, err := pgxpool.Connect(ctx, psqlConn)
@@ -72,18 +117,26 @@ func (r *repo) UserCreate(ctx context.Context, user models.User) error {
 	}()
 	go helper.StartNewSpan(ctx, repoService, stop)
 
-	query, args, err := squirrel.Insert(usersTable).
-		Columns(nameField, passwordField, emailField, fullNameField, createdAtField).
-		Values(user.Name, user.Password, user.Email, user.FullName, user.CreatedAt).
+	attributes, err := encodeAttributes(user.Attributes)
+	if err != nil {
+		return apperr.WrapKey(err, "repo.UserCreate", "name", user.Name)
+	}
+	insert, args, err := squirrel.Insert(usersTable).
+		Columns(userColumns...).
+		Values(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, attributes,
+			user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt).
+		Suffix("RETURNING " + tenantExpr + " AS " + tenantField).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return errors.Wrap(err, "postgres UserCreate: to sql")
+		return apperr.WrapKey(err, "repo.UserCreate", "name", user.Name)
 	}
+	// the counter is updated by the same statement, so it is never out of the transaction of the write
+	query := "WITH inserted AS (" + insert + ") " + countUsers("SELECT "+tenantField+", 1 FROM inserted")
 	r.logger.Debugln("UserCreate", query, args)
 
 	if _, err = r.pool.Exec(ctx, query, args...); err != nil {
-		return errors.Wrap(err, "postgres UserCreate: insert")
+		return apperr.WrapKey(err, "repo.UserCreate", "name", user.Name)
 	}
 
 	return nil
@@ -96,22 +149,42 @@ func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
 	}()
 	go helper.StartNewSpan(ctx, repoService, stop)
 
-	query, args, err := squirrel.Update(usersTable).
+	attributes, err := encodeAttributes(user.Attributes)
+	if err != nil {
+		return apperr.WrapKey(err, "repo.UserUpdate", "name", user.Name)
+	}
+	update, args, err := squirrel.Update(usersTable).
 		Set(passwordField, user.Password).
 		Set(emailField, user.Email).
 		Set(fullNameField, user.FullName).
+		Set(statusField, user.Status).
+		Set(attributesField, attributes).
+		Set(passwordChangedAtField, user.PasswordChangedAt).
+		Set(passwordExpiresAtField, user.PasswordExpiresAt).
+		Set(hlcField, user.HLC).
+		Set(updatedAtField, user.UpdatedAt).
 		Where(squirrel.Eq{
 			nameField: user.Name,
 		}).
+		Suffix("RETURNING " + tenantExpr + " AS " + tenantField).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return errors.Wrap(err, "postgres UserUpdate: to sql")
+		return apperr.WrapKey(err, "repo.UserUpdate", "name", user.Name)
 	}
+	// the old row is locked, so the tenant it is counted by is not changed by concurrent writes;
+	// users moved to another tenant are counted by both tenants in the same statement
+	name := fmt.Sprintf("$%d", len(args)+1)
+	args = append(args, user.Name)
+	query := "WITH old AS (SELECT " + tenantExpr + " AS " + tenantField + " FROM " + usersTable +
+		" WHERE " + nameField + " = " + name + " FOR UPDATE), updated AS (" + update + "), " +
+		"moved AS (SELECT old." + tenantField + " AS old, updated." + tenantField + " AS new FROM old, updated " +
+		"WHERE old." + tenantField + " <> updated." + tenantField + ") " +
+		countUsers("SELECT old, -1 FROM moved UNION ALL SELECT new, 1 FROM moved")
 	r.logger.Debugln("UserUpdate", query, args)
 
 	if _, err = r.pool.Exec(ctx, query, args...); err != nil {
-		return errors.Wrap(err, "postgres UserUpdate: update")
+		return apperr.WrapKey(err, "repo.UserUpdate", "name", user.Name)
 	}
 
 	return nil
@@ -124,19 +197,94 @@ func (r *repo) UserDelete(ctx context.Context, name string) error {
 	}()
 	go helper.StartNewSpan(ctx, repoService, stop)
 
-	query, args, err := squirrel.Delete(usersTable).
+	// the tombstone is inserted and the counter is updated by the same statement, so both are left by every deletion
+	query := "WITH deleted AS (DELETE FROM " + usersTable + " WHERE " + nameField + " = $1 RETURNING " +
+		idField + ", " + nameField + ", " + tenantExpr + " AS " + tenantField + "), tombstones AS (INSERT INTO " +
+		tombstonesTable + " (" + idField + ", " + nameField + ", " + deletedAtField + ") SELECT " + idField + ", " +
+		nameField + ", $2 FROM deleted) " + countUsers("SELECT "+tenantField+", -1 FROM deleted")
+	args := []interface{}{name, time.Now().Unix()}
+	r.logger.Debugln("UserDelete", query, args)
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return apperr.WrapKey(err, "repo.UserDelete", "name", name)
+	}
+
+	return nil
+}
+
+// UserRename changes the name in a transaction, the renamed row is locked until commit.
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) (retErr error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
+	}
+	defer func() {
+		if retErr != nil {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				r.logger.Errorf("rename rollback: %v", err)
+			}
+		}
+	}()
+
+	exists := func(name, suffix string) (bool, error) {
+		query, args, err := squirrel.Select(nameField).
+			From(usersTable).
+			Where(squirrel.Eq{
+				nameField: name,
+			}).
+			Suffix(suffix).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			return false, err
+		}
+		r.logger.Debugln("UserRename", query, args)
+
+		var found string
+		if err = tx.QueryRow(ctx, query, args...).Scan(&found); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	if ok, err := exists(oldName, "FOR UPDATE"); err != nil {
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
+	} else if !ok {
+		return apperr.WrapKey(errorsPkg.ErrUserNotFound, "repo.UserRename", "name", oldName)
+	}
+	if ok, err := exists(newName, ""); err != nil {
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
+	} else if ok {
+		return apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "repo.UserRename", "name", newName)
+	}
+
+	query, args, err := squirrel.Update(usersTable).
+		Set(nameField, newName).
+		Set(updatedAtField, time.Now().Unix()).
 		Where(squirrel.Eq{
-			nameField: name,
+			nameField: oldName,
 		}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return errors.Wrap(err, "postgres UserDelete: to sql")
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
 	}
-	r.logger.Debugln("UserDelete", query, args)
+	r.logger.Debugln("UserRename", query, args)
 
-	if _, err = r.pool.Exec(ctx, query, args...); err != nil {
-		return errors.Wrap(err, "postgres UserDelete: delete")
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return apperr.WrapKey(err, "repo.UserRename", "name", oldName)
 	}
 
 	return nil
@@ -149,7 +297,7 @@ func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
 	}()
 	go helper.StartNewSpan(ctx, repoService, stop)
 
-	query, args, err := squirrel.Select(nameField, passwordField, emailField, fullNameField, createdAtField).
+	query, args, err := squirrel.Select(userColumns...).
 		From(usersTable).
 		Where(squirrel.Eq{
 			nameField: name,
@@ -157,24 +305,58 @@ func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return models.User{}, errors.Wrap(err, "postgres UserGet: to sql")
+		return models.User{}, apperr.WrapKey(err, "repo.UserGet", "name", name)
 	}
 	r.logger.Debugln("UserGet", query, args)
 
-	row := r.pool.QueryRow(ctx, query, args...)
-	var user models.User
-	if err = row.Scan(&user.Name, &user.Password, &user.Email, &user.FullName, &user.CreatedAt); err != nil {
+	user, err := scanUser(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.User{}, errorsPkg.ErrUserNotFound
+			return models.User{}, apperr.WrapKey(errorsPkg.ErrUserNotFound, "repo.UserGet", "name", name)
 		}
-		return models.User{}, errors.Wrap(err, "postgres UserGet: get")
+		return models.User{}, apperr.WrapKey(err, "repo.UserGet", "name", name)
 	}
 	r.logger.Debugln("UserGet", user.String())
 
 	return user, nil
 }
 
-func (r *repo) UserList(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error) {
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	query, args, err := squirrel.Select(userColumns...).
+		From(usersTable).
+		Where(squirrel.Eq{
+			idField: id,
+		}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return models.User{}, apperr.WrapKey(err, "repo.UserGetByID", "id", id)
+	}
+	r.logger.Debugln("UserGetByID", query, args)
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, apperr.WrapKey(errorsPkg.ErrUserNotFound, "repo.UserGetByID", "id", id)
+		}
+		return models.User{}, apperr.WrapKey(err, "repo.UserGetByID", "id", id)
+	}
+
+	return user, nil
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
 	stop := make(chan struct{})
 	defer func() {
 		stop <- struct{}{}
@@ -185,28 +367,44 @@ func (r *repo) UserList(ctx context.Context, order bool, limit, offset uint64) (
 	if order {
 		sort = desc
 	}
-	query, args, err := squirrel.Select(nameField, passwordField, emailField, fullNameField, createdAtField).
-		From(usersTable).
+	if err := filter.Validate(where); err != nil {
+		return nil, apperr.Wrap(err, "repo.UserList")
+	}
+	cond, err := compileFilter(where)
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserList")
+	}
+	builder := squirrel.Select(userColumns...).
+		From(usersTable)
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+	query, args, err := builder.
 		Limit(limit).
 		Offset(offset * limit).
 		OrderBy(nameField + sort).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return nil, errors.Wrap(err, "postgres UserList: to sql")
+		return nil, apperr.Wrap(err, "repo.UserList")
 	}
 	r.logger.Debugln("UserList", query, args)
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	querier, err := r.listQuerier(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserList")
+	}
+	rows, err := querier.Query(ctx, query, args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "postgres UserList: query")
+		return nil, apperr.Wrap(err, "repo.UserList")
 	}
+	defer rows.Close()
 
 	users := make([]models.User, 0)
 	for rows.Next() {
-		var user models.User
-		if err = rows.Scan(&user.Name, &user.Password, &user.Email, &user.FullName, &user.CreatedAt); err != nil {
-			return nil, errors.Wrap(err, "postgres UserList: row scan")
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, apperr.Wrap(err, "repo.UserList")
 		}
 		users = append(users, user)
 	}
@@ -215,6 +413,339 @@ func (r *repo) UserList(ctx context.Context, order bool, limit, offset uint64) (
 	return users, nil
 }
 
+// UserListScan estimates rows read by the list by plans of the filter, so the scan budget rejects
+// the list before it runs. Filters served by an index read the matched rows, which are sorted then.
+// Others scan the table in order of names, until the pages are filled by the matched fraction of rows.
+func (r *repo) UserListScan(ctx context.Context, limit, offset uint64, where filter.Expr) (uint64, error) {
+	rows := (offset + 1) * limit
+	if err := filter.Validate(where); err != nil {
+		return 0, apperr.Wrap(err, "repo.UserListScan")
+	}
+	cond, err := compileFilter(where)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserListScan")
+	}
+	if cond == nil {
+		return rows, nil
+	}
+	query, args, err := squirrel.Select(idField).
+		From(usersTable).
+		Where(cond).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserListScan")
+	}
+	matched, err := r.explain(ctx, query, args...)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserListScan")
+	}
+	if !matched.seqScan() {
+		return uint64(matched.Rows), nil
+	}
+	total, err := r.explain(ctx, "SELECT "+idField+" FROM "+usersTable)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserListScan")
+	}
+	if matched.Rows < 1 {
+		matched.Rows = 1
+	}
+	scanned := float64(rows) * total.Rows / matched.Rows
+	if scanned > total.Rows {
+		scanned = total.Rows
+	}
+	return uint64(scanned), nil
+}
+
+// plan is a node of the EXPLAIN output in JSON format.
+type plan struct {
+	NodeType string  `json:"Node Type"`
+	Rows     float64 `json:"Plan Rows"`
+	Plans    []plan  `json:"Plans"`
+}
+
+func (p plan) seqScan() bool {
+	if p.NodeType == "Seq Scan" {
+		return true
+	}
+	for _, child := range p.Plans {
+		if child.seqScan() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repo) explain(ctx context.Context, query string, args ...interface{}) (plan, error) {
+	var data []byte
+	if err := r.pool.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&data); err != nil {
+		return plan{}, errors.Wrap(err, "explain")
+	}
+	var plans []struct {
+		Plan plan `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return plan{}, errors.Wrap(err, "explain: decode plan")
+	}
+	if len(plans) == 0 {
+		return plan{}, errors.New("explain: no plan")
+	}
+	return plans[0].Plan, nil
+}
+
+// listQuerier returns the pool or the transaction of the list snapshot of the request.
+// Pages of the request are read by a single REPEATABLE READ transaction, so they are
+// consistent with each other. It holds the connection until the request is done.
+func (r *repo) listQuerier(ctx context.Context) (pgxtype.Querier, error) {
+	ls := repoPkg.ListSnapshotFromContext(ctx)
+	if ls == nil {
+		return r.pool, nil
+	}
+	state, err := ls.Load(r, func() (interface{}, func(), error) {
+		tx, err := r.pool.Begin(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = tx.Exec(ctx, snapshotIsolation); err != nil {
+			r.rollback(tx)
+			return nil, nil, err
+		}
+		return tx, func() { r.rollback(tx) }, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state.(pgx.Tx), nil
+}
+
+func (r *repo) rollback(tx pgx.Tx) {
+	if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		r.logger.Errorf("list snapshot rollback: %v", err)
+	}
+}
+
+// UserSnapshot reads users in a single read only transaction, so fn gets a consistent snapshot.
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			r.logger.Errorf("snapshot rollback: %v", err)
+		}
+	}()
+	if _, err = tx.Exec(ctx, snapshotIsolation); err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+
+	query, args, err := squirrel.Select(userColumns...).
+		From(usersTable).
+		OrderBy(nameField).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+	r.logger.Debugln("UserSnapshot", query, args)
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return apperr.Wrap(err, "repo.UserSnapshot")
+		}
+		if err = fn(user); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return apperr.Wrap(err, "repo.UserSnapshot")
+	}
+	return nil
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	query, args, err := squirrel.Select(idField, nameField, deletedAtField).
+		From(tombstonesTable).
+		Where(squirrel.GtOrEq{
+			deletedAtField: since,
+		}).
+		OrderBy(deletedAtField).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserTombstones")
+	}
+	r.logger.Debugln("UserTombstones", query, args)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserTombstones")
+	}
+	defer rows.Close()
+
+	var tombstones []models.Tombstone
+	for rows.Next() {
+		var tombstone models.Tombstone
+		if err = rows.Scan(&tombstone.ID, &tombstone.Name, &tombstone.DeletedAt); err != nil {
+			return nil, apperr.Wrap(err, "repo.UserTombstones")
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperr.Wrap(err, "repo.UserTombstones")
+	}
+	return tombstones, nil
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	query, args, err := squirrel.Delete(tombstonesTable).
+		Where(squirrel.Lt{
+			deletedAtField: before,
+		}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserTombstonesPrune")
+	}
+	r.logger.Debugln("UserTombstonesPrune", query, args)
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserTombstonesPrune")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UserCounts reads the counters instead of users, tenants without users are left out.
+func (r *repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	query, args, err := squirrel.Select(tenantField, usersField).
+		From(countersTable).
+		Where(squirrel.NotEq{
+			usersField: 0,
+		}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserCounts")
+	}
+	r.logger.Debugln("UserCounts", query, args)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperr.Wrap(err, "repo.UserCounts")
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var (
+			tenant string
+			users  int64
+		)
+		if err = rows.Scan(&tenant, &users); err != nil {
+			return nil, apperr.Wrap(err, "repo.UserCounts")
+		}
+		counts[tenant] = users
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperr.Wrap(err, "repo.UserCounts")
+	}
+	return counts, nil
+}
+
+// UserCountsReconcile recounts users under the lock of the counters. Writes committed before
+// the lock are counted by the recount, others update the counters after it.
+func (r *repo) UserCountsReconcile(ctx context.Context) (_ int64, retErr error) {
+	stop := make(chan struct{})
+	defer func() {
+		stop <- struct{}{}
+	}()
+	go helper.StartNewSpan(ctx, repoService, stop)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserCountsReconcile")
+	}
+	defer func() {
+		if retErr != nil {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				r.logger.Errorf("counters reconcile rollback: %v", err)
+			}
+		}
+	}()
+	if _, err = tx.Exec(ctx, countersLock); err != nil {
+		return 0, apperr.Wrap(err, "repo.UserCountsReconcile")
+	}
+
+	// counters are replaced by the recount, counters of tenants without users are zeroed
+	query := "WITH actual AS (SELECT " + tenantExpr + " AS " + tenantField + ", count(*) AS " + usersField +
+		" FROM " + usersTable + " GROUP BY 1), keys AS (SELECT " + tenantField + " FROM actual UNION SELECT " +
+		tenantField + " FROM " + countersTable + ") INSERT INTO " + countersTable + " (" + tenantField + ", " +
+		usersField + ") SELECT keys." + tenantField + ", COALESCE(actual." + usersField + ", 0) FROM keys LEFT JOIN actual USING (" +
+		tenantField + ") ON CONFLICT (" + tenantField + ") DO UPDATE SET " + usersField + " = EXCLUDED." + usersField +
+		" WHERE " + countersTable + "." + usersField + " <> EXCLUDED." + usersField
+	r.logger.Debugln("UserCountsReconcile", query)
+
+	tag, err := tx.Exec(ctx, query)
+	if err != nil {
+		return 0, apperr.Wrap(err, "repo.UserCountsReconcile")
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, apperr.Wrap(err, "repo.UserCountsReconcile")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// scanUser reads row of userColumns.
+func scanUser(row pgx.Row) (models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Name, &user.Password, &user.Email, &user.FullName, &user.CreatedAt, &user.Status,
+		&user.Attributes, &user.PasswordChangedAt, &user.PasswordExpiresAt, &user.HLC, &user.UpdatedAt)
+	return user, err
+}
+
+// encodeAttributes returns attributes as JSONB text, nil map is stored as empty object.
+func encodeAttributes(attributes map[string]string) (string, error) {
+	if attributes == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func (r *repo) Close() {
 	r.pool.Close()
 	r.logger.Infoln("PostgreSQL connection closed")
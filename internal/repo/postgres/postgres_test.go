@@ -8,20 +8,18 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
 )
 
 var (
-	user = models.User{
-		Name:      "Ivan",
-		Password:  "123",
-		Email:     "ivan@email.com",
-		FullName:  "Ivan the Dummy",
-		CreatedAt: 1660412940,
-	}
+	user = modeltest.NewUser().WithAttribute("team", "core").Build()
 )
 
 func TestRepo_UserCreate(t *testing.T) {
@@ -44,8 +42,13 @@ func TestRepo_UserCreate(t *testing.T) {
 			expErr: errorsPkg.ErrUnexpected,
 		},
 	}
-	query := "INSERT INTO users (name,password,email,full_name,created_at) VALUES ($1,$2,$3,$4,$5)"
-	args := []interface{}{user.Name, user.Password, user.Email, user.FullName, user.CreatedAt}
+	query := "INSERT INTO users (id,name,password,email,full_name,created_at,status,attributes,password_changed_at,password_expires_at,hlc,updated_at) " +
+		"VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)"
+	query = "WITH inserted AS (" + query + " RETURNING COALESCE(attributes ->> 'tenant', '') AS tenant) " +
+		"INSERT INTO user_counters (tenant, users) SELECT tenant, 1 FROM inserted " +
+		"ON CONFLICT (tenant) DO UPDATE SET users = user_counters.users + EXCLUDED.users"
+	args := []interface{}{user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, `{"team":"core"}`,
+		user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -84,8 +87,15 @@ func TestRepo_UserUpdate(t *testing.T) {
 			expErr: errorsPkg.ErrUnexpected,
 		},
 	}
-	query := "UPDATE users SET password = $1, email = $2, full_name = $3 WHERE name = $4"
-	args := []interface{}{user.Password, user.Email, user.FullName, user.Name}
+	query := "UPDATE users SET password = $1, email = $2, full_name = $3, status = $4, attributes = $5, " +
+		"password_changed_at = $6, password_expires_at = $7, hlc = $8, updated_at = $9 WHERE name = $10"
+	query = "WITH old AS (SELECT COALESCE(attributes ->> 'tenant', '') AS tenant FROM users WHERE name = $11 FOR UPDATE), " +
+		"updated AS (" + query + " RETURNING COALESCE(attributes ->> 'tenant', '') AS tenant), " +
+		"moved AS (SELECT old.tenant AS old, updated.tenant AS new FROM old, updated WHERE old.tenant <> updated.tenant) " +
+		"INSERT INTO user_counters (tenant, users) SELECT old, -1 FROM moved UNION ALL SELECT new, 1 FROM moved " +
+		"ON CONFLICT (tenant) DO UPDATE SET users = user_counters.users + EXCLUDED.users"
+	args := []interface{}{user.Password, user.Email, user.FullName, user.Status, `{"team":"core"}`,
+		user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt, user.Name, user.Name}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -124,8 +134,11 @@ func TestRepo_UserDelete(t *testing.T) {
 			expErr: errorsPkg.ErrUnexpected,
 		},
 	}
-	query := "DELETE FROM users WHERE name = $1"
-	args := []interface{}{user.Name}
+	query := "WITH deleted AS (DELETE FROM users WHERE name = $1 RETURNING id, name, COALESCE(attributes ->> 'tenant', '') AS tenant), " +
+		"tombstones AS (INSERT INTO users_tombstones (id, name, deleted_at) SELECT id, name, $2 FROM deleted) " +
+		"INSERT INTO user_counters (tenant, users) SELECT tenant, -1 FROM deleted " +
+		"ON CONFLICT (tenant) DO UPDATE SET users = user_counters.users + EXCLUDED.users"
+	args := []interface{}{user.Name, pgxmock.AnyArg()}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -144,6 +157,264 @@ func TestRepo_UserDelete(t *testing.T) {
 	}
 }
 
+func TestRepo_UserTombstones(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const query = "SELECT id, name, deleted_at FROM users_tombstones WHERE deleted_at >= $1 ORDER BY deleted_at"
+	cases := []struct {
+		name   string
+		err    error
+		expLen int
+		expErr error
+	}{
+		{
+			name:   "success",
+			expLen: 1,
+		},
+		{
+			name:   "failed, query crashed",
+			err:    errorsPkg.ErrUnexpected,
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectQuery(query).
+				WithArgs(int64(1660412940)).
+				WillReturnRows(pgxmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow(user.ID, user.Name, int64(1660412941))).
+				WillReturnError(c.err)
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			tombstones, err := r.UserTombstones(context.Background(), 1660412940)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Len(t, tombstones, c.expLen)
+		})
+	}
+}
+
+func TestRepo_UserTombstonesPrune(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec("DELETE FROM users_tombstones WHERE deleted_at < $1").
+		WithArgs(int64(1660412940)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	r := &repo{
+		pool:   mock,
+		logger: loggerPkg.NewFatal(),
+	}
+	pruned, err := r.UserTombstonesPrune(context.Background(), 1660412940)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pruned)
+}
+
+func TestRepo_UserCounts(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const query = "SELECT tenant, users FROM user_counters WHERE users <> $1"
+	cases := []struct {
+		name      string
+		err       error
+		expCounts map[string]int64
+		expErr    error
+	}{
+		{
+			name:      "success",
+			expCounts: map[string]int64{"": 2, "acme": 3},
+		},
+		{
+			name:   "failed, query crashed",
+			err:    errorsPkg.ErrUnexpected,
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectQuery(query).
+				WithArgs(0).
+				WillReturnRows(pgxmock.NewRows([]string{"tenant", "users"}).AddRow("", int64(2)).AddRow("acme", int64(3))).
+				WillReturnError(c.err)
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			counts, err := r.UserCounts(context.Background())
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expCounts, counts)
+		})
+	}
+}
+
+func TestRepo_UserCountsReconcile(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const query = "WITH actual AS (SELECT COALESCE(attributes ->> 'tenant', '') AS tenant, count(*) AS users FROM users GROUP BY 1), " +
+		"keys AS (SELECT tenant FROM actual UNION SELECT tenant FROM user_counters) " +
+		"INSERT INTO user_counters (tenant, users) SELECT keys.tenant, COALESCE(actual.users, 0) FROM keys LEFT JOIN actual USING (tenant) " +
+		"ON CONFLICT (tenant) DO UPDATE SET users = EXCLUDED.users WHERE user_counters.users <> EXCLUDED.users"
+	cases := []struct {
+		name         string
+		prepare      func()
+		expCorrected int64
+		expErr       error
+	}{
+		{
+			name: "success",
+			prepare: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(countersLock).WillReturnResult(pgxmock.NewResult("LOCK", 0))
+				mock.ExpectExec(query).WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				mock.ExpectCommit()
+			},
+			expCorrected: 2,
+		},
+		{
+			name: "failed, lock crashed",
+			prepare: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(countersLock).WillReturnError(errorsPkg.ErrUnexpected)
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUnexpected,
+		},
+		{
+			name: "failed, recount crashed",
+			prepare: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(countersLock).WillReturnResult(pgxmock.NewResult("LOCK", 0))
+				mock.ExpectExec(query).WillReturnError(errorsPkg.ErrUnexpected)
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.prepare()
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			corrected, err := r.UserCountsReconcile(context.Background())
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expCorrected, corrected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepo_UserRename(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const newName = "ivan_renamed"
+	cases := []struct {
+		name   string
+		expect func()
+		expErr error
+	}{
+		{
+			name: "success",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1 FOR UPDATE").
+					WithArgs(user.Name).
+					WillReturnRows(pgxmock.NewRows([]string{"name"}).AddRow(user.Name))
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1").
+					WithArgs(newName).
+					WillReturnError(pgx.ErrNoRows)
+				mock.ExpectExec("UPDATE users SET name = $1, updated_at = $2 WHERE name = $3").
+					WithArgs(newName, pgxmock.AnyArg(), user.Name).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "failed, user not found",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1 FOR UPDATE").
+					WithArgs(user.Name).
+					WillReturnError(pgx.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+		{
+			name: "failed, new name is taken",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1 FOR UPDATE").
+					WithArgs(user.Name).
+					WillReturnRows(pgxmock.NewRows([]string{"name"}).AddRow(user.Name))
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1").
+					WithArgs(newName).
+					WillReturnRows(pgxmock.NewRows([]string{"name"}).AddRow(newName))
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUserAlreadyExists,
+		},
+		{
+			name: "failed, exec crashed",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1 FOR UPDATE").
+					WithArgs(user.Name).
+					WillReturnRows(pgxmock.NewRows([]string{"name"}).AddRow(user.Name))
+				mock.ExpectQuery("SELECT name FROM users WHERE name = $1").
+					WithArgs(newName).
+					WillReturnError(pgx.ErrNoRows)
+				mock.ExpectExec("UPDATE users SET name = $1, updated_at = $2 WHERE name = $3").
+					WithArgs(newName, pgxmock.AnyArg(), user.Name).
+					WillReturnError(errorsPkg.ErrUnexpected)
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.expect()
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			err = r.UserRename(context.Background(), user.Name, newName)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestRepo_UserGet(t *testing.T) {
 	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
 	if err != nil {
@@ -172,12 +443,13 @@ func TestRepo_UserGet(t *testing.T) {
 			expErr: errorsPkg.ErrUserNotFound,
 		},
 	}
-	query := "SELECT name, password, email, full_name, created_at FROM users WHERE name = $1"
+	query := "SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at FROM users WHERE name = $1"
 	args := []interface{}{user.Name}
 
 	for _, c := range cases {
-		rows := pgxmock.NewRows([]string{nameField, passwordField, emailField, fullNameField, createdAtField}).
-			AddRow(user.Name, user.Password, user.Email, user.FullName, user.CreatedAt)
+		rows := pgxmock.NewRows(userColumns).
+			AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, user.Attributes,
+				user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt)
 		t.Run(c.name, func(t *testing.T) {
 			mock.ExpectQuery(query).
 				WithArgs(args...).
@@ -195,7 +467,7 @@ func TestRepo_UserGet(t *testing.T) {
 	}
 }
 
-func TestRepo_UserList(t *testing.T) {
+func TestRepo_UserGetByID(t *testing.T) {
 	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
 	if err != nil {
 		t.Fatal(err)
@@ -217,28 +489,296 @@ func TestRepo_UserList(t *testing.T) {
 			err:    errorsPkg.ErrUnexpected,
 			expErr: errorsPkg.ErrUnexpected,
 		},
+		{
+			name:   "failed, no data",
+			err:    pgx.ErrNoRows,
+			expErr: errorsPkg.ErrUserNotFound,
+		},
+	}
+	query := "SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at FROM users WHERE id = $1"
+	args := []interface{}{user.ID}
+
+	for _, c := range cases {
+		rows := pgxmock.NewRows(userColumns).
+			AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, user.Attributes,
+				user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt)
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectQuery(query).
+				WithArgs(args...).
+				WillReturnRows(rows).
+				WillReturnError(c.err).
+				RowsWillBeClosed()
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			_, err = r.UserGetByID(context.Background(), user.ID)
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func TestRepo_UserList(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
 	}
+	defer mock.Close()
+
 	order := true
 	limit := uint64(2)
 	offset := uint64(0)
-	query := fmt.Sprintf("SELECT name, password, email, full_name, created_at "+
-		"FROM users ORDER BY name DESC LIMIT %d OFFSET %d", limit, offset)
+	cases := []struct {
+		name   string
+		where  filter.Expr
+		query  string
+		args   []interface{}
+		err    error
+		expErr error
+	}{
+		{
+			name: "success",
+			query: fmt.Sprintf("SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at "+
+				"FROM users ORDER BY name DESC LIMIT %d OFFSET %d", limit, offset),
+			err:    nil,
+			expErr: nil,
+		},
+		{
+			name:  "success, attributes filter",
+			where: filter.ListParams(map[string]string{"team": "core"}, ""),
+			query: fmt.Sprintf("SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at "+
+				"FROM users WHERE (attributes @> $1) ORDER BY name DESC LIMIT %d OFFSET %d", limit, offset),
+			args:   []interface{}{`{"team":"core"}`},
+			err:    nil,
+			expErr: nil,
+		},
+		{
+			name:  "success, status filter",
+			where: filter.ListParams(nil, models.StatusDisabled),
+			query: fmt.Sprintf("SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at "+
+				"FROM users WHERE (status = $1) ORDER BY name DESC LIMIT %d OFFSET %d", limit, offset),
+			args:   []interface{}{models.StatusDisabled},
+			err:    nil,
+			expErr: nil,
+		},
+		{
+			name: "success, or filter",
+			where: filter.Or{
+				filter.Cond{Field: filter.FieldName, Op: filter.OpPrefix, Value: "iv_"},
+				filter.And{
+					filter.Cond{Field: filter.FieldCreatedAt, Op: filter.OpGe, Value: int64(1660412960)},
+					filter.Cond{Field: filter.Attribute("team"), Op: filter.OpNe, Value: "ops"},
+				},
+			},
+			query: fmt.Sprintf("SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at "+
+				`FROM users WHERE (name LIKE $1 ESCAPE '\' OR (created_at >= $2 AND attributes->>$3 <> $4)) ORDER BY name DESC LIMIT %d OFFSET %d`, limit, offset),
+			args:   []interface{}{`iv\_%`, int64(1660412960), "team", "ops"},
+			err:    nil,
+			expErr: nil,
+		},
+		{
+			name:   "failed, invalid filter",
+			where:  filter.Cond{Field: "password", Op: filter.OpEq, Value: "123"},
+			expErr: errorsPkg.ErrValidation,
+		},
+		{
+			name: "failed, query crashed",
+			query: fmt.Sprintf("SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at "+
+				"FROM users ORDER BY name DESC LIMIT %d OFFSET %d", limit, offset),
+			err:    errorsPkg.ErrUnexpected,
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
 
 	for _, c := range cases {
-		rows := pgxmock.NewRows([]string{nameField, passwordField, emailField, fullNameField, createdAtField}).
-			AddRow(user.Name, user.Password, user.Email, user.FullName, user.CreatedAt).
-			AddRow(user.Name, user.Password, user.Email, user.FullName, user.CreatedAt)
+		rows := pgxmock.NewRows(userColumns).
+			AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, user.Attributes,
+				user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt).
+			AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status, user.Attributes,
+				user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt)
 		t.Run(c.name, func(t *testing.T) {
-			mock.ExpectQuery(query).
-				WillReturnRows(rows).
-				WillReturnError(c.err)
+			if c.query != "" {
+				mock.ExpectQuery(c.query).
+					WithArgs(c.args...).
+					WillReturnRows(rows).
+					WillReturnError(c.err)
+			}
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			_, err = r.UserList(context.Background(), order, limit, offset, c.where)
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func TestRepo_UserListScan(t *testing.T) {
+	where := filter.ListParams(map[string]string{"team": "core"}, "")
+	query := "EXPLAIN (FORMAT JSON) SELECT id FROM users WHERE (attributes @> $1)"
+	cases := []struct {
+		name    string
+		plan    string
+		seqScan bool
+		expRows uint64
+	}{
+		{
+			name:    "index scan reads matched rows",
+			plan:    `[{"Plan": {"Node Type": "Bitmap Heap Scan", "Plan Rows": 40, "Plans": [{"Node Type": "Bitmap Index Scan", "Plan Rows": 40}]}}]`,
+			expRows: 40,
+		},
+		{
+			name:    "seq scan reads pages by the matched fraction",
+			plan:    `[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1000}}]`,
+			seqScan: true,
+			expRows: 2000,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mock.Close()
+
+			mock.ExpectQuery(query).WithArgs(`{"team":"core"}`).
+				WillReturnRows(pgxmock.NewRows([]string{"QUERY PLAN"}).AddRow([]byte(c.plan)))
+			if c.seqScan {
+				mock.ExpectQuery("EXPLAIN (FORMAT JSON) SELECT id FROM users").
+					WillReturnRows(pgxmock.NewRows([]string{"QUERY PLAN"}).AddRow([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 100000}}]`)))
+			}
+
+			r := &repo{
+				pool:   mock,
+				logger: loggerPkg.NewFatal(),
+			}
+			rows, err := r.UserListScan(context.Background(), 10, 1, where)
+			require.NoError(t, err)
+			assert.Equal(t, c.expRows, rows)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepo_UserListSnapshot(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const query = "SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at " +
+		"FROM users ORDER BY name LIMIT 1 OFFSET %d"
+	r := &repo{
+		pool:   mock,
+		logger: loggerPkg.NewFatal(),
+	}
+
+	t.Run("success, pages are read by a single transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(snapshotIsolation).WillReturnResult(pgxmock.NewResult("SET", 0))
+		for offset := 0; offset < 2; offset++ {
+			mock.ExpectQuery(fmt.Sprintf(query, offset)).WillReturnRows(pgxmock.NewRows(userColumns).
+				AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status,
+					user.Attributes, user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt))
+		}
+		mock.ExpectRollback()
+
+		ctx, release := repoPkg.WithListSnapshot(context.Background())
+		for offset := uint64(0); offset < 2; offset++ {
+			users, err := r.UserList(ctx, false, 1, offset, nil)
+			assert.NoError(t, err)
+			assert.Len(t, users, 1)
+		}
+		release()
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed, isolation is not set", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(snapshotIsolation).WillReturnError(errorsPkg.ErrUnexpected)
+		mock.ExpectRollback()
+
+		ctx, release := repoPkg.WithListSnapshot(context.Background())
+		defer release()
+		_, err := r.UserList(ctx, false, 1, 0, nil)
+		assert.ErrorIs(t, err, errorsPkg.ErrUnexpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepo_UserSnapshot(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const query = "SELECT id, name, password, email, full_name, created_at, status, attributes, password_changed_at, password_expires_at, hlc, updated_at " +
+		"FROM users ORDER BY name"
+	cases := []struct {
+		name   string
+		fnErr  error
+		expect func()
+		expLen int
+		expErr error
+	}{
+		{
+			name: "success",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(snapshotIsolation).WillReturnResult(pgxmock.NewResult("SET", 0))
+				mock.ExpectQuery(query).WillReturnRows(pgxmock.NewRows(userColumns).
+					AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status,
+						user.Attributes, user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt))
+				mock.ExpectRollback()
+			},
+			expLen: 1,
+		},
+		{
+			name:  "failed, fn error",
+			fnErr: errorsPkg.ErrTimeout,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(snapshotIsolation).WillReturnResult(pgxmock.NewResult("SET", 0))
+				mock.ExpectQuery(query).WillReturnRows(pgxmock.NewRows(userColumns).
+					AddRow(user.ID, user.Name, user.Password, user.Email, user.FullName, user.CreatedAt, user.Status,
+						user.Attributes, user.PasswordChangedAt, user.PasswordExpiresAt, user.HLC, user.UpdatedAt))
+				mock.ExpectRollback()
+			},
+			expLen: 1,
+			expErr: errorsPkg.ErrTimeout,
+		},
+		{
+			name: "failed, query crashed",
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(snapshotIsolation).WillReturnResult(pgxmock.NewResult("SET", 0))
+				mock.ExpectQuery(query).WillReturnError(errorsPkg.ErrUnexpected)
+				mock.ExpectRollback()
+			},
+			expErr: errorsPkg.ErrUnexpected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.expect()
 
 			r := &repo{
 				pool:   mock,
 				logger: loggerPkg.NewFatal(),
 			}
-			_, err = r.UserList(context.Background(), order, limit, offset)
+			var users []models.User
+			err := r.UserSnapshot(context.Background(), func(user models.User) error {
+				users = append(users, user)
+				return c.fnErr
+			})
 			assert.ErrorIs(t, err, c.expErr)
+			assert.Len(t, users, c.expLen)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -0,0 +1,54 @@
+package readonly
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+)
+
+// Checker returns error, while writes are not allowed, e.g. in maintenance mode.
+type Checker interface {
+	Check() error
+}
+
+// New wraps repository, writes are rejected with error of the checker and reads pass through.
+func New(data repoPkg.Interface, checker Checker) repoPkg.Interface {
+	return &repo{
+		Interface: data,
+		checker:   checker,
+	}
+}
+
+type repo struct {
+	repoPkg.Interface
+	checker Checker
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	if err := r.checker.Check(); err != nil {
+		return err
+	}
+	return r.Interface.UserCreate(ctx, user)
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	if err := r.checker.Check(); err != nil {
+		return err
+	}
+	return r.Interface.UserUpdate(ctx, user)
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	if err := r.checker.Check(); err != nil {
+		return err
+	}
+	return r.Interface.UserDelete(ctx, name)
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	if err := r.checker.Check(); err != nil {
+		return err
+	}
+	return r.Interface.UserRename(ctx, oldName, newName)
+}
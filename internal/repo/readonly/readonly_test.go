@@ -0,0 +1,66 @@
+package readonly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	maintenancePkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/maintenance"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRepo(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	cases := []struct {
+		name        string
+		maintenance bool
+		expErr      error
+	}{
+		{
+			name: "success, writes pass through",
+		},
+		{
+			name:        "failed, writes rejected in maintenance",
+			maintenance: true,
+			expErr:      errorsPkg.ErrMaintenance,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mode := maintenancePkg.New(maintenancePkg.NewMemory(), loggerPkg.NewFatal())
+			_, err := mode.Set(ctx, c.maintenance, "upgrade", "meta")
+			require.NoError(t, err)
+
+			data := repoMockPkg.NewMockInterface(ctl)
+			writes := 1
+			if c.maintenance {
+				writes = 0
+			}
+			data.EXPECT().UserCreate(gomock.Any(), gomock.Any()).Return(nil).Times(writes)
+			data.EXPECT().UserUpdate(gomock.Any(), gomock.Any()).Return(nil).Times(writes)
+			data.EXPECT().UserDelete(gomock.Any(), "ivan").Return(nil).Times(writes)
+			data.EXPECT().UserRename(gomock.Any(), "ivan", "petr").Return(nil).Times(writes)
+			data.EXPECT().UserGet(gomock.Any(), "ivan").Return(models.User{Name: "ivan"}, nil).Times(1)
+
+			r := New(data, mode)
+			assert.ErrorIs(t, r.UserCreate(ctx, models.User{Name: "ivan"}), c.expErr)
+			assert.ErrorIs(t, r.UserUpdate(ctx, models.User{Name: "ivan"}), c.expErr)
+			assert.ErrorIs(t, r.UserDelete(ctx, "ivan"), c.expErr)
+			assert.ErrorIs(t, r.UserRename(ctx, "ivan", "petr"), c.expErr)
+
+			user, err := r.UserGet(ctx, "ivan")
+			assert.NoError(t, err)
+			assert.Equal(t, "ivan", user.Name)
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/hlc"
+)
+
+const (
+	defaultQueueSize     = 10000
+	defaultTimeout       = 5 * time.Second
+	defaultRetryInterval = time.Second
+	// maxConflicts is a number of the latest conflicts kept for the report
+	maxConflicts = 100
+)
+
+// ErrStale is returned for the replicated write, which is older than the stored user.
+var ErrStale = errors.New("replicated write is older than the stored one")
+
+type replicatedKey struct{}
+
+// Replicated marks writes of the mutation received from another region. They are not forwarded
+// and the user is written, only if the write is later than the stored one by HLC.
+func Replicated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicatedKey{}, true)
+}
+
+func isReplicated(ctx context.Context) bool {
+	replicated, _ := ctx.Value(replicatedKey{}).(bool)
+	return replicated
+}
+
+// Conflict is a replicated write rejected, since the stored user was written later.
+type Conflict struct {
+	Name        string
+	ID          string
+	StoredHLC   uint64
+	RejectedHLC uint64
+	DetectedAt  time.Time
+}
+
+// Config of the replication to other regions. Passive regions enable it without endpoints,
+// so written users are stamped by HLC and replicated writes are resolved by the last write.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoints are gRPC addresses of data services of passive regions.
+	Endpoints []string `mapstructure:"endpoints"`
+	// QueueSize is a number of mutations waiting for the endpoint. Mutations above it are dropped
+	// and the endpoint catches up with the snapshot.
+	QueueSize int `mapstructure:"queue_size"`
+	// Timeout of the mutation call.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RetryInterval between failed calls, failed mutation is repeated until it is applied.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	// CatchUpOnStart sends the snapshot to endpoints, when the leader is elected.
+	CatchUpOnStart bool `mapstructure:"catch_up_on_start"`
+}
+
+// Stats of the endpoint replication.
+type Stats struct {
+	Pending int `json:"pending"`
+	// Lag is an age of the oldest mutation, which is not applied yet.
+	Lag        time.Duration `json:"lag"`
+	Sent       uint64        `json:"sent"`
+	Dropped    uint64        `json:"dropped"`
+	CatchUps   uint64        `json:"catch_ups"`
+	CatchingUp bool          `json:"catching_up"`
+}
+
+// Repo forwards successful mutations of the wrapped repository to the endpoints in background.
+// Every endpoint has a single sender, so mutations are applied in order they are made.
+// Written users are stamped by HLC, concurrent writes of regions are resolved by the last one.
+// Deletions and renames are applied as is. Avatars are not replicated.
+type Repo struct {
+	repoPkg.Interface
+	cfg       Config
+	clock     *hlc.Clock
+	endpoints []*endpoint
+	conns     []*grpc.ClientConn
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	logger    *zap.SugaredLogger
+
+	mu        sync.Mutex
+	conflicts []Conflict
+	total     uint64
+}
+
+// New wraps repository and starts senders to the endpoints until ctx is done or the repository is closed.
+func New(ctx context.Context, data repoPkg.Interface, cfg Config, logger *zap.SugaredLogger) (*Repo, error) {
+	clients := make(map[string]pb.ReplicaClient, len(cfg.Endpoints))
+	conns := make([]*grpc.ClientConn, 0, len(cfg.Endpoints))
+	for _, addr := range cfg.Endpoints {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+			return nil, errors.Wrapf(err, "replica [%s] dial", addr)
+		}
+		conns = append(conns, conn)
+		clients[addr] = pb.NewReplicaClient(conn)
+	}
+	r := newRepo(ctx, data, cfg, clients, logger)
+	r.conns = conns
+	logger.Infow("With replication started", "endpoints", cfg.Endpoints)
+	return r, nil
+}
+
+func newRepo(ctx context.Context, data repoPkg.Interface, cfg Config, clients map[string]pb.ReplicaClient, logger *zap.SugaredLogger) *Repo {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r := &Repo{
+		Interface: data,
+		cfg:       cfg,
+		clock:     hlc.New(),
+		cancel:    cancel,
+		logger:    logger,
+	}
+	for _, addr := range cfg.Endpoints {
+		e := &endpoint{
+			addr:    addr,
+			client:  clients[addr],
+			data:    data,
+			cfg:     cfg,
+			queue:   make(chan mutation, cfg.QueueSize),
+			catchUp: make(chan struct{}, 1),
+			logger:  logger,
+		}
+		r.endpoints = append(r.endpoints, e)
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			e.run(ctx)
+		}()
+	}
+	return r
+}
+
+func (r *Repo) UserCreate(ctx context.Context, user models.User) error {
+	if isReplicated(ctx) {
+		r.clock.Update(hlc.Timestamp(user.HLC))
+		return r.Interface.UserCreate(ctx, user)
+	}
+	user.HLC = uint64(r.clock.Now())
+	if err := r.Interface.UserCreate(ctx, user); err != nil {
+		return err
+	}
+	r.forward(&pb.ReplicaApplyRequest{
+		Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(user)},
+	})
+	return nil
+}
+
+func (r *Repo) UserUpdate(ctx context.Context, user models.User) error {
+	if isReplicated(ctx) {
+		return r.resolve(ctx, user)
+	}
+	user.HLC = uint64(r.clock.Now())
+	if err := r.Interface.UserUpdate(ctx, user); err != nil {
+		return err
+	}
+	r.forward(&pb.ReplicaApplyRequest{
+		Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(user)},
+	})
+	return nil
+}
+
+func (r *Repo) UserDelete(ctx context.Context, name string) error {
+	if err := r.Interface.UserDelete(ctx, name); err != nil || isReplicated(ctx) {
+		return err
+	}
+	r.forward(&pb.ReplicaApplyRequest{
+		Mutation: &pb.ReplicaApplyRequest_Delete{Delete: name},
+	})
+	return nil
+}
+
+func (r *Repo) UserRename(ctx context.Context, oldName, newName string) error {
+	if err := r.Interface.UserRename(ctx, oldName, newName); err != nil || isReplicated(ctx) {
+		return err
+	}
+	r.forward(&pb.ReplicaApplyRequest{
+		Mutation: &pb.ReplicaApplyRequest_Rename{Rename: &pb.ReplicaRename{OldName: oldName, NewName: newName}},
+	})
+	return nil
+}
+
+// resolve writes the replicated user, if it is later than the stored one, otherwise ErrStale is returned.
+// The caller holds the user lock, so the stored user doesn't change until it is written.
+func (r *Repo) resolve(ctx context.Context, user models.User) error {
+	r.clock.Update(hlc.Timestamp(user.HLC))
+	stored, err := r.Interface.UserGet(ctx, user.Name)
+	if err != nil {
+		return err
+	}
+	if later(user, stored) {
+		return r.Interface.UserUpdate(ctx, user)
+	}
+	// the repeated write is not a conflict
+	if user.HLC != stored.HLC || len(models.Diff(user, stored)) != 0 {
+		r.conflict(Conflict{
+			Name:        user.Name,
+			ID:          stored.ID,
+			StoredHLC:   stored.HLC,
+			RejectedHLC: user.HLC,
+			DetectedAt:  time.Now(),
+		})
+	}
+	return errors.Wrapf(ErrStale, "user [%s]", user.Name)
+}
+
+// later reports whether the write is later than the stored one. Writes of the same time are ordered
+// by their states, so all regions keep the same one.
+func later(user, stored models.User) bool {
+	if user.HLC != stored.HLC {
+		return user.HLC > stored.HLC
+	}
+	a, _ := json.Marshal(user)
+	b, _ := json.Marshal(stored)
+	return string(a) > string(b)
+}
+
+func (r *Repo) conflict(conflict Conflict) {
+	counter.ReplicaConflicts.Inc()
+	r.logger.Warnw("replication conflict", "name", conflict.Name, "stored_hlc", conflict.StoredHLC,
+		"rejected_hlc", conflict.RejectedHLC)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total++
+	r.conflicts = append(r.conflicts, conflict)
+	if len(r.conflicts) > maxConflicts {
+		r.conflicts = r.conflicts[len(r.conflicts)-maxConflicts:]
+	}
+}
+
+// Conflicts returns the number of conflicts and the latest ones, the newest first.
+func (r *Repo) Conflicts() (uint64, []Conflict) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conflicts := make([]Conflict, 0, len(r.conflicts))
+	for i := len(r.conflicts) - 1; i >= 0; i-- {
+		conflicts = append(conflicts, r.conflicts[i])
+	}
+	return r.total, conflicts
+}
+
+// CatchUp makes all endpoints catch up with the snapshot, e.g. the passive region is new.
+func (r *Repo) CatchUp() {
+	for _, e := range r.endpoints {
+		e.signal()
+	}
+}
+
+// Stats returns replication stats by endpoint.
+func (r *Repo) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(r.endpoints))
+	for _, e := range r.endpoints {
+		stats[e.addr] = e.stats()
+	}
+	return stats
+}
+
+// Close stops senders, pending mutations are lost, and closes the wrapped repository.
+func (r *Repo) Close() {
+	r.cancel()
+	r.wg.Wait()
+	for _, conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.Interface.Close()
+}
+
+func (r *Repo) forward(request *pb.ReplicaApplyRequest) {
+	m := mutation{request: request, at: time.Now()}
+	for _, e := range r.endpoints {
+		e.enqueue(m)
+	}
+}
+
+type mutation struct {
+	request *pb.ReplicaApplyRequest
+	at      time.Time
+}
+
+type endpoint struct {
+	addr   string
+	client pb.ReplicaClient
+	data   repoPkg.Interface
+	cfg    Config
+	queue  chan mutation
+	// catchUp is signalled, when mutations are lost
+	catchUp chan struct{}
+	logger  *zap.SugaredLogger
+
+	mu sync.Mutex
+	// oldest is a time of the mutation or the snapshot being sent
+	oldest     time.Time
+	sent       uint64
+	dropped    uint64
+	catchUps   uint64
+	catchingUp bool
+}
+
+func (e *endpoint) enqueue(m mutation) {
+	select {
+	case e.queue <- m:
+	default:
+		e.mu.Lock()
+		e.dropped++
+		e.mu.Unlock()
+		counter.ReplicaDropped.Inc()
+		e.signal()
+	}
+}
+
+func (e *endpoint) signal() {
+	select {
+	case e.catchUp <- struct{}{}:
+	default:
+	}
+}
+
+func (e *endpoint) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.catchUp:
+			e.sync(ctx)
+		case m := <-e.queue:
+			e.send(ctx, m)
+		}
+	}
+}
+
+// send repeats the mutation until it is applied, or the endpoint has to catch up anyway.
+func (e *endpoint) send(ctx context.Context, m mutation) {
+	e.setOldest(m.at)
+	defer e.setOldest(time.Time{})
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+		_, err := e.client.ReplicaApply(callCtx, m.request)
+		cancel()
+		if err == nil {
+			e.mu.Lock()
+			e.sent++
+			e.mu.Unlock()
+			counter.ReplicaSent.Inc()
+			return
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			// the mutation is never applied, the snapshot brings the endpoint up to date
+			e.logger.Errorw("replicate", "endpoint", e.addr, "error", err.Error())
+			e.signal()
+			return
+		}
+		e.logger.Warnw("replicate, retry", "endpoint", e.addr, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.cfg.RetryInterval):
+		}
+		if len(e.catchUp) != 0 {
+			return
+		}
+	}
+}
+
+// sync sends the snapshot to the endpoint. Queued mutations are made before the snapshot is read,
+// so they are dropped. Mutations made later are sent after it and applied again.
+func (e *endpoint) sync(ctx context.Context) {
+	for pending := len(e.queue); pending > 0; pending-- {
+		<-e.queue
+	}
+	e.mu.Lock()
+	e.catchingUp = true
+	e.catchUps++
+	e.mu.Unlock()
+	e.setOldest(time.Now())
+	defer func() {
+		e.mu.Lock()
+		e.catchingUp = false
+		e.mu.Unlock()
+		e.setOldest(time.Time{})
+	}()
+
+	e.logger.Infow("Replica catch up started", "endpoint", e.addr)
+	resp, err := e.sendSnapshot(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		e.logger.Errorw("replica catch up", "endpoint", e.addr, "error", err.Error())
+		select {
+		case <-ctx.Done():
+		case <-time.After(e.cfg.RetryInterval):
+			e.signal()
+		}
+		return
+	}
+	e.logger.Infow("Replica caught up", "endpoint", e.addr, "upserted", resp.GetUpserted(), "deleted", resp.GetDeleted())
+}
+
+func (e *endpoint) sendSnapshot(ctx context.Context) (*pb.ReplicaCatchUpResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := e.client.ReplicaCatchUp(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "open stream")
+	}
+	err = e.data.UserSnapshot(ctx, func(user models.User) error {
+		return stream.Send(&pb.ReplicaCatchUpRequest{User: adaptor.ToUserPbModel(user)})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "send snapshot")
+	}
+	return stream.CloseAndRecv()
+}
+
+func (e *endpoint) setOldest(at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.oldest = at
+}
+
+func (e *endpoint) stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := Stats{
+		Pending:    len(e.queue),
+		Sent:       e.sent,
+		Dropped:    e.dropped,
+		CatchUps:   e.catchUps,
+		CatchingUp: e.catchingUp,
+	}
+	if !e.oldest.IsZero() {
+		stats.Lag = time.Since(e.oldest)
+	}
+	return stats
+}
@@ -0,0 +1,189 @@
+package replicate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models/modeltest"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/adaptor"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	apiMockPkg "gitlab.ozon.dev/iTukaev/homework/pkg/mock"
+)
+
+const endpointAddr = "passive:8081"
+
+var user = modeltest.Ivan()
+
+func TestRepo_Forward(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	var stamped models.User
+	mockRepo.EXPECT().UserCreate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, got models.User) error {
+			stamped = got
+			return nil
+		}).Times(1)
+	mockRepo.EXPECT().UserUpdate(gomock.Any(), gomock.Any()).Return(errorsPkg.ErrUserNotFound).Times(1)
+	mockRepo.EXPECT().UserRename(gomock.Any(), user.Name, "petr").Return(nil).Times(1)
+	mockRepo.EXPECT().UserDelete(gomock.Any(), "petr").Return(nil).Times(1)
+	mockRepo.EXPECT().Close().Times(1)
+
+	applied := make(chan *pb.ReplicaApplyRequest, 3)
+	client := apiMockPkg.NewMockReplicaClient(ctl)
+	calls := 0
+	client.EXPECT().ReplicaApply(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.ReplicaApplyRequest, _ ...grpc.CallOption) (*pb.ReplicaApplyResponse, error) {
+			calls++
+			// the first call fails and is repeated
+			if calls == 1 {
+				return nil, status.Error(codes.Unavailable, "unavailable")
+			}
+			applied <- in
+			return &pb.ReplicaApplyResponse{}, nil
+		}).Times(4)
+
+	r := newRepo(ctx, mockRepo, Config{Endpoints: []string{endpointAddr}, RetryInterval: time.Millisecond},
+		map[string]pb.ReplicaClient{endpointAddr: client}, loggerPkg.NewFatal())
+	require.NoError(t, r.UserCreate(ctx, user))
+	// failed mutation is not replicated
+	require.ErrorIs(t, r.UserUpdate(ctx, user), errorsPkg.ErrUserNotFound)
+	require.NoError(t, r.UserRename(ctx, user.Name, "petr"))
+	require.NoError(t, r.UserDelete(ctx, "petr"))
+	require.NotZero(t, stamped.HLC)
+	assert.Equal(t, modeltest.From(user).WithHLC(stamped.HLC).Build(), stamped)
+
+	expected := []*pb.ReplicaApplyRequest{
+		{Mutation: &pb.ReplicaApplyRequest_Upsert{Upsert: adaptor.ToUserPbModel(stamped)}},
+		{Mutation: &pb.ReplicaApplyRequest_Rename{Rename: &pb.ReplicaRename{OldName: user.Name, NewName: "petr"}}},
+		{Mutation: &pb.ReplicaApplyRequest_Delete{Delete: "petr"}},
+	}
+	for _, exp := range expected {
+		select {
+		case got := <-applied:
+			assert.Equal(t, exp.String(), got.String())
+		case <-time.After(time.Second):
+			t.Fatal("mutation is not applied")
+		}
+	}
+	r.Close()
+	assert.Equal(t, uint64(3), r.Stats()[endpointAddr].Sent)
+}
+
+func TestRepo_Resolve(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := Replicated(context.Background())
+
+	replicated := modeltest.From(user).WithHLC(100).Build()
+	cases := []struct {
+		name         string
+		stored       models.User
+		updated      int
+		expConflicts uint64
+		expErr       error
+	}{
+		{
+			name:    "success, replicated write is later",
+			stored:  modeltest.From(user).WithHLC(99).WithEmail("old@email.com").Build(),
+			updated: 1,
+		},
+		{
+			name:         "failed, stored user is written later",
+			stored:       modeltest.From(user).WithHLC(101).WithEmail("new@email.com").Build(),
+			expConflicts: 1,
+			expErr:       ErrStale,
+		},
+		{
+			name:   "failed, repeated write",
+			stored: replicated,
+			expErr: ErrStale,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockRepo := repoMockPkg.NewMockInterface(ctl)
+			mockRepo.EXPECT().UserGet(gomock.Any(), user.Name).Return(c.stored, nil).Times(1)
+			mockRepo.EXPECT().UserUpdate(gomock.Any(), replicated).Return(nil).Times(c.updated)
+			mockRepo.EXPECT().Close().Times(1)
+			// replicated writes are never forwarded
+			client := apiMockPkg.NewMockReplicaClient(ctl)
+
+			r := newRepo(context.Background(), mockRepo, Config{Endpoints: []string{endpointAddr}},
+				map[string]pb.ReplicaClient{endpointAddr: client}, loggerPkg.NewFatal())
+			err := r.UserUpdate(ctx, replicated)
+			assert.ErrorIs(t, err, c.expErr)
+			r.Close()
+
+			total, conflicts := r.Conflicts()
+			assert.Equal(t, c.expConflicts, total)
+			assert.Len(t, conflicts, int(c.expConflicts))
+			// the clock is ahead of the observed write
+			assert.Greater(t, uint64(r.clock.Now()), replicated.HLC)
+		})
+	}
+}
+
+func TestRepo_CatchUp(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	ctx := context.Background()
+
+	mockRepo := repoMockPkg.NewMockInterface(ctl)
+	mockRepo.EXPECT().UserSnapshot(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(models.User) error) error {
+			return fn(user)
+		}).Times(1)
+	mockRepo.EXPECT().Close().Times(1)
+
+	done := make(chan struct{})
+	stream := apiMockPkg.NewMockReplica_ReplicaCatchUpClient(ctl)
+	stream.EXPECT().Send(&pb.ReplicaCatchUpRequest{User: adaptor.ToUserPbModel(user)}).Return(nil).Times(1)
+	stream.EXPECT().CloseAndRecv().
+		DoAndReturn(func() (*pb.ReplicaCatchUpResponse, error) {
+			close(done)
+			return &pb.ReplicaCatchUpResponse{Upserted: 1}, nil
+		}).Times(1)
+	client := apiMockPkg.NewMockReplicaClient(ctl)
+	client.EXPECT().ReplicaCatchUp(gomock.Any()).Return(stream, nil).Times(1)
+
+	r := newRepo(ctx, mockRepo, Config{Endpoints: []string{endpointAddr}},
+		map[string]pb.ReplicaClient{endpointAddr: client}, loggerPkg.NewFatal())
+	r.CatchUp()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("endpoint is not caught up")
+	}
+	r.Close()
+	assert.Equal(t, uint64(1), r.Stats()[endpointAddr].CatchUps)
+}
+
+func TestEndpoint_Enqueue(t *testing.T) {
+	e := &endpoint{
+		queue:   make(chan mutation, 1),
+		catchUp: make(chan struct{}, 1),
+	}
+	e.enqueue(mutation{at: time.Now()})
+	assert.Len(t, e.catchUp, 0)
+
+	// overflowed endpoint drops the mutation and has to catch up
+	e.enqueue(mutation{at: time.Now()})
+	assert.Len(t, e.catchUp, 1)
+	assert.Equal(t, Stats{Pending: 1, Dropped: 1}, e.stats())
+}
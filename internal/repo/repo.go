@@ -4,15 +4,150 @@ package repo
 
 import (
 	"context"
+	"sync"
+
+	"github.com/pkg/errors"
 
 	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
 )
 
 type Interface interface {
 	UserCreate(ctx context.Context, user models.User) error
 	UserUpdate(ctx context.Context, user models.User) error
 	UserDelete(ctx context.Context, name string) error
+	// UserRename atomically changes the user name, which is the primary key.
+	UserRename(ctx context.Context, oldName, newName string) error
 	UserGet(ctx context.Context, name string) (models.User, error)
-	UserList(ctx context.Context, order bool, limit, offset uint64) ([]models.User, error)
+	UserGetByID(ctx context.Context, id string) (models.User, error)
+	// UserList returns page of users matched by the filter, nil filter matches all users.
+	UserList(ctx context.Context, order bool, limit, offset uint64, where filter.Expr) ([]models.User, error)
+	// UserSnapshot calls fn for every user of a consistent snapshot ordered by name,
+	// error of fn stops the iteration and is returned.
+	UserSnapshot(ctx context.Context, fn func(user models.User) error) error
+	// UserTombstones returns users deleted at the time in UNIX format or later, in order of deletion.
+	UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error)
+	// UserTombstonesPrune removes tombstones of users deleted before the time in UNIX format.
+	UserTombstonesPrune(ctx context.Context, before int64) (int64, error)
+	// UserCounts returns numbers of users by tenant, users without the tenant are counted by the empty one.
+	// Counters are changed with writes, so users are not read.
+	UserCounts(ctx context.Context) (map[string]int64, error)
+	// UserCountsReconcile recounts users and fixes counters, the number of fixed tenants is returned.
+	UserCountsReconcile(ctx context.Context) (int64, error)
 	Close()
 }
+
+type partialKey struct{}
+
+// Partial collects names of shards skipped by a best-effort list and of backends, which page
+// was truncated by the scan budget. It is safe for concurrent use.
+type Partial struct {
+	mu      sync.Mutex
+	skipped []string
+	parent  *Partial
+}
+
+// WithPartial returns context, which collects shards skipped by lists of the request.
+// Skipped shards are reported to the collector of ctx as well, if there is one.
+func WithPartial(ctx context.Context) (context.Context, *Partial) {
+	p := &Partial{parent: PartialFromContext(ctx)}
+	return context.WithValue(ctx, partialKey{}, p), p
+}
+
+// PartialFromContext returns the collector of skipped shards, nil is returned, if they are not collected.
+func PartialFromContext(ctx context.Context) *Partial {
+	p, _ := ctx.Value(partialKey{}).(*Partial)
+	return p
+}
+
+// Skip records the shard or the backend, which users are missing in the result.
+func (p *Partial) Skip(shard string) {
+	p.mu.Lock()
+	p.skipped = append(p.skipped, shard)
+	p.mu.Unlock()
+	if p.parent != nil {
+		p.parent.Skip(shard)
+	}
+}
+
+// Skipped returns names of the skipped shards.
+func (p *Partial) Skipped() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.skipped...)
+}
+
+// IsPartial reports if users of some shards are missing in the result.
+func (p *Partial) IsPartial() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.skipped) != 0
+}
+
+type listSnapshotKey struct{}
+
+// ListSnapshot keeps snapshots of repositories read by pages of a single request,
+// so the pages do not skip or repeat users written meanwhile. It is safe for concurrent use.
+type ListSnapshot struct {
+	mu       sync.Mutex
+	states   map[interface{}]interface{}
+	releases []func()
+}
+
+// WithListSnapshot returns context, which lists of a repository read from the same snapshot.
+// Release must be called, when the request is done.
+func WithListSnapshot(ctx context.Context) (context.Context, func()) {
+	s := &ListSnapshot{states: make(map[interface{}]interface{})}
+	return context.WithValue(ctx, listSnapshotKey{}, s), s.release
+}
+
+// ListSnapshotFromContext returns snapshots of the request, nil is returned, if lists are not isolated.
+func ListSnapshotFromContext(ctx context.Context) *ListSnapshot {
+	s, _ := ctx.Value(listSnapshotKey{}).(*ListSnapshot)
+	return s
+}
+
+// Load returns the state of the repository snapshot by key, it is opened by the first call.
+// The release of the state is called with the release of the request.
+func (s *ListSnapshot) Load(key interface{}, open func() (interface{}, func(), error)) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states == nil {
+		return nil, errors.New("repo: list snapshot is released")
+	}
+	if state, ok := s.states[key]; ok {
+		return state, nil
+	}
+	state, release, err := open()
+	if err != nil {
+		return nil, err
+	}
+	s.states[key] = state
+	if release != nil {
+		s.releases = append(s.releases, release)
+	}
+	return state, nil
+}
+
+func (s *ListSnapshot) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, release := range s.releases {
+		release()
+	}
+	s.states, s.releases = nil, nil
+}
+
+type scanBudgetKey struct{}
+
+// WithoutScanBudget returns context, which lists are not limited by the scan budget,
+// e.g. of jobs paging through all users by design.
+func WithoutScanBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scanBudgetKey{}, true)
+}
+
+// ScanBudgetExempt reports if lists of ctx are not limited by the scan budget.
+func ScanBudgetExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(scanBudgetKey{}).(bool)
+	return exempt
+}
@@ -0,0 +1,68 @@
+// Package scanned adds rows read by repository calls to the cost of the request.
+package scanned
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	costPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cost"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+)
+
+// New wraps repository, rows read by gets, lists, snapshots and tombstones are added
+// to the meter of the request.
+func New(data repoPkg.Interface) repoPkg.Interface {
+	return &repo{
+		Interface: data,
+	}
+}
+
+type repo struct {
+	repoPkg.Interface
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	user, err := r.Interface.UserGet(ctx, name)
+	if err == nil {
+		costPkg.AddRows(ctx, 1)
+	}
+	return user, err
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	user, err := r.Interface.UserGetByID(ctx, id)
+	if err == nil {
+		costPkg.AddRows(ctx, 1)
+	}
+	return user, err
+}
+
+// UserList counts rows of the skipped pages too, since the backend reads them. Offset is a page number.
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	users, err := r.Interface.UserList(ctx, order, limit, offset, where)
+	if err == nil {
+		costPkg.AddRows(ctx, offset*limit+uint64(len(users)))
+	}
+	return users, err
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	return r.Interface.UserSnapshot(ctx, func(user models.User) error {
+		costPkg.AddRows(ctx, 1)
+		return fn(user)
+	})
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	tombstones, err := r.Interface.UserTombstones(ctx, since)
+	if err == nil {
+		costPkg.AddRows(ctx, uint64(len(tombstones)))
+	}
+	return tombstones, err
+}
@@ -0,0 +1,33 @@
+package scanned
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	costPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/cost"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+)
+
+func TestRepo_UserList(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	data := repoMockPkg.NewMockInterface(ctl)
+	data.EXPECT().UserList(gomock.Any(), false, uint64(10), uint64(2), nil).
+		Return([]models.User{{Name: "alice"}, {Name: "bob"}}, nil).Times(2)
+	repo := New(data)
+
+	_, err := repo.UserList(context.Background(), false, 10, 2, nil)
+	assert.NoError(t, err)
+
+	// rows of the skipped pages are read too
+	ctx, meter := costPkg.WithMeter(context.Background())
+	_, err = repo.UserList(ctx, false, 10, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(22), meter.Rows())
+}
@@ -0,0 +1,66 @@
+package shard
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+const defaultVirtualNodes = 160
+
+// ring routes keys to shards by consistent hashing. Every shard owns virtual nodes spread
+// over the ring, so adding or removing a shard moves about 1/N of keys only.
+type ring struct {
+	points []uint64
+	owners []string
+}
+
+func newRing(shards []string, virtualNodes int) *ring {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+	r := &ring{
+		points: make([]uint64, 0, len(shards)*virtualNodes),
+		owners: make([]string, 0, len(shards)*virtualNodes),
+	}
+	type node struct {
+		point uint64
+		owner string
+	}
+	nodes := make([]node, 0, len(shards)*virtualNodes)
+	for _, shard := range shards {
+		for i := 0; i < virtualNodes; i++ {
+			nodes = append(nodes, node{point: hash(shard + "#" + strconv.Itoa(i)), owner: shard})
+		}
+	}
+	// equal points are ordered by owner, so rings of the same shards are equal whatever the config order is
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].point == nodes[j].point {
+			return nodes[i].owner < nodes[j].owner
+		}
+		return nodes[i].point < nodes[j].point
+	})
+	for _, n := range nodes {
+		r.points = append(r.points, n.point)
+		r.owners = append(r.owners, n.owner)
+	}
+	return r
+}
+
+// owner returns the shard of the first virtual node clockwise from the key.
+func (r *ring) owner(key string) string {
+	point := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= point
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+func hash(key string) uint64 {
+	sum := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
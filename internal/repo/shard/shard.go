@@ -0,0 +1,568 @@
+// Package shard spreads users over several repositories by consistent hashing of names.
+// Calls of a user are routed to its owner shard, lists and snapshots are merged from all shards.
+package shard
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	pgModels "gitlab.ozon.dev/iTukaev/homework/internal/repo/postgres/models"
+)
+
+const defaultMigrateInterval = time.Minute
+
+// Policies of lists, which shards failed.
+const (
+	// ListFail fails the list, if any shard fails.
+	ListFail = "fail"
+	// ListBestEffort returns users of the other shards and reports the failed ones
+	// to repo.Partial of the context. The list fails, if all shards fail.
+	ListBestEffort = "best_effort"
+)
+
+// Config of the sharded repository.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Shards are repositories by name, users are routed by the ring of the names
+	// of shards, which are not draining.
+	Shards []Shard `mapstructure:"shards"`
+	// Previous are names of the shards of the ring before resharding. Users missing on the owner
+	// are read from the previous owner, until the migration moves them. Empty, if resharding is done.
+	Previous []string `mapstructure:"previous"`
+	// VirtualNodes of a shard on the ring, the ring must not be changed by resharding.
+	VirtualNodes int `mapstructure:"virtual_nodes"`
+	// MigrateInterval between migrations of users to their owners while resharding.
+	MigrateInterval time.Duration `mapstructure:"migrate_interval"`
+	// ListPolicy is fail or best_effort, fail is the default.
+	ListPolicy string `mapstructure:"list_policy"`
+}
+
+// Shard is a repository of the part of users, Postgres database or the local storage.
+type Shard struct {
+	Name  string          `mapstructure:"name"`
+	Local bool            `mapstructure:"local"`
+	PG    pgModels.Config `mapstructure:"pg"`
+	// Draining shard is removed from the ring, it is read until its users are moved.
+	Draining bool `mapstructure:"draining"`
+}
+
+// Repo routes calls of a user to its shard. It is not a distributed transaction manager:
+// a rename between shards and a move of the migration create the user on the target first
+// and delete it from the source then, so a failure leaves a copy rather than loses the user.
+type Repo struct {
+	shards map[string]repoPkg.Interface
+	// names are shards of the ring, all are the ones keeping users, including previous shards
+	names    []string
+	all      []string
+	ring     *ring
+	previous *ring
+	cfg      Config
+	logger   *zap.SugaredLogger
+}
+
+// New returns the repository of shards by name, every shard of the config must be given.
+// Draining shards are not in the ring, so they must be previous ones.
+func New(shards map[string]repoPkg.Interface, cfg Config, logger *zap.SugaredLogger) (*Repo, error) {
+	if len(cfg.Shards) == 0 {
+		return nil, errors.Wrap(errorsPkg.ErrValidation, "shard: no shards")
+	}
+	if cfg.MigrateInterval <= 0 {
+		cfg.MigrateInterval = defaultMigrateInterval
+	}
+	switch cfg.ListPolicy {
+	case "":
+		cfg.ListPolicy = ListFail
+	case ListFail, ListBestEffort:
+	default:
+		return nil, errors.Wrapf(errorsPkg.ErrValidation, "shard: unknown list policy [%s]", cfg.ListPolicy)
+	}
+	names := make([]string, 0, len(cfg.Shards))
+	for _, shard := range cfg.Shards {
+		if _, ok := shards[shard.Name]; !ok || shard.Name == "" {
+			return nil, errors.Wrapf(errorsPkg.ErrValidation, "shard: no repository of shard [%s]", shard.Name)
+		}
+		if !shard.Draining {
+			names = append(names, shard.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Wrap(errorsPkg.ErrValidation, "shard: all shards are draining")
+	}
+	for _, name := range cfg.Previous {
+		if _, ok := shards[name]; !ok {
+			return nil, errors.Wrapf(errorsPkg.ErrValidation, "shard: no repository of previous shard [%s]", name)
+		}
+	}
+	all := append([]string(nil), names...)
+	for _, name := range cfg.Previous {
+		if !contains(all, name) {
+			all = append(all, name)
+		}
+	}
+	r := &Repo{
+		shards: shards,
+		names:  names,
+		all:    all,
+		ring:   newRing(names, cfg.VirtualNodes),
+		cfg:    cfg,
+		logger: logger,
+	}
+	if len(cfg.Previous) != 0 {
+		r.previous = newRing(cfg.Previous, cfg.VirtualNodes)
+	}
+	logger.Infow("With sharded repository started", "shards", names, "previous", cfg.Previous)
+	return r, nil
+}
+
+// owner returns the shard of the user.
+func (r *Repo) owner(name string) repoPkg.Interface {
+	return r.shards[r.ring.owner(name)]
+}
+
+// previousOwner returns the shard of the user before resharding, nil is returned,
+// if it is the owner or there is no resharding.
+func (r *Repo) previousOwner(name string) repoPkg.Interface {
+	if r.previous == nil {
+		return nil
+	}
+	if previous := r.previous.owner(name); previous != r.ring.owner(name) {
+		return r.shards[previous]
+	}
+	return nil
+}
+
+// locate returns the shard keeping the user, it is the previous owner for users not moved yet.
+func (r *Repo) locate(ctx context.Context, name string) (repoPkg.Interface, models.User, error) {
+	owner := r.owner(name)
+	user, err := owner.UserGet(ctx, name)
+	previous := r.previousOwner(name)
+	if previous == nil || !errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return owner, user, err
+	}
+	user, err = previous.UserGet(ctx, name)
+	return previous, user, err
+}
+
+// UserCreate checks the previous owner first, so the user not moved yet is not created twice.
+func (r *Repo) UserCreate(ctx context.Context, user models.User) error {
+	if previous := r.previousOwner(user.Name); previous != nil {
+		if _, err := previous.UserGet(ctx, user.Name); err == nil {
+			return apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "shard.UserCreate", "name", user.Name)
+		} else if !errors.Is(err, errorsPkg.ErrUserNotFound) {
+			return err
+		}
+	}
+	return r.owner(user.Name).UserCreate(ctx, user)
+}
+
+// UserUpdate moves the user not moved yet to its owner first, since the update is partial.
+func (r *Repo) UserUpdate(ctx context.Context, user models.User) error {
+	if previous := r.previousOwner(user.Name); previous != nil {
+		if err := r.move(ctx, previous, user.Name); err != nil {
+			return apperr.WrapKey(err, "shard.UserUpdate", "name", user.Name)
+		}
+	}
+	return r.owner(user.Name).UserUpdate(ctx, user)
+}
+
+func (r *Repo) UserDelete(ctx context.Context, name string) error {
+	if previous := r.previousOwner(name); previous != nil {
+		if err := previous.UserDelete(ctx, name); err != nil {
+			return err
+		}
+	}
+	return r.owner(name).UserDelete(ctx, name)
+}
+
+// UserRename is atomic, if both names are owned by the same shard. Otherwise the user
+// is created with the new name on its owner and deleted from the old one then.
+func (r *Repo) UserRename(ctx context.Context, oldName, newName string) error {
+	source, user, err := r.locate(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	target := r.owner(newName)
+	if source == target && r.previousOwner(newName) == nil {
+		return source.UserRename(ctx, oldName, newName)
+	}
+
+	if _, _, err = r.locate(ctx, newName); err == nil {
+		return apperr.WrapKey(errorsPkg.ErrUserAlreadyExists, "shard.UserRename", "name", newName)
+	} else if !errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return err
+	}
+	user.Name, user.UpdatedAt = newName, time.Now().Unix()
+	if err = target.UserCreate(ctx, user); err != nil {
+		return err
+	}
+	return source.UserDelete(ctx, oldName)
+}
+
+func (r *Repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	_, user, err := r.locate(ctx, name)
+	return user, err
+}
+
+// UserGetByID asks all shards, since the ID does not tell the shard.
+func (r *Repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	users := make([]models.User, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		users[i], err = shard.UserGetByID(ctx, id)
+		return err
+	})
+	for i, err := range errs {
+		if err == nil {
+			return users[i], nil
+		}
+	}
+	for _, err := range errs {
+		if !errors.Is(err, errorsPkg.ErrUserNotFound) {
+			return models.User{}, err
+		}
+	}
+	return models.User{}, errs[0]
+}
+
+// UserList queries all shards concurrently and merges their pages in the requested order.
+// Every shard returns all users up to the end of the page, so deep pages are expensive.
+// Failed shards are skipped by the best-effort policy and reported to repo.Partial of ctx.
+func (r *Repo) UserList(ctx context.Context, order bool, limit, offset uint64, where filter.Expr) ([]models.User, error) {
+	pages := make([][]models.User, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		pages[i], err = shard.UserList(ctx, order, limit*(offset+1), 0, where)
+		return err
+	})
+
+	var failed []int
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	if len(failed) != 0 {
+		if r.cfg.ListPolicy != ListBestEffort || len(failed) == len(r.all) || ctx.Err() != nil {
+			return nil, errs[failed[0]]
+		}
+		partial := repoPkg.PartialFromContext(ctx)
+		for _, i := range failed {
+			r.logger.Warnw("shard skipped by list", "shard", r.all[i], "error", errs[i])
+			counter.ShardSkipped.Inc()
+			if partial != nil {
+				partial.Skip(r.all[i])
+			}
+		}
+	}
+	return mergePages(pages, order, limit*offset, limit*(offset+1)), nil
+}
+
+// mergePages merges ordered pages of shards and returns users from start up to end,
+// a user kept by two shards during a move is counted once.
+func mergePages(pages [][]models.User, order bool, start, end uint64) []models.User {
+	before := func(a, b models.User) bool {
+		if order {
+			return a.Name > b.Name
+		}
+		return a.Name < b.Name
+	}
+
+	result := make([]models.User, 0)
+	heads := make([]int, len(pages))
+	var last *models.User
+	for n := uint64(0); n < end; {
+		first := -1
+		for i, page := range pages {
+			if heads[i] < len(page) && (first < 0 || before(page[heads[i]], pages[first][heads[first]])) {
+				first = i
+			}
+		}
+		if first < 0 {
+			break
+		}
+		user := pages[first][heads[first]]
+		heads[first]++
+		if last != nil && last.Name == user.Name {
+			continue
+		}
+		if n >= start {
+			result = append(result, user)
+		}
+		last = &user
+		n++
+	}
+	return result
+}
+
+// UserSnapshot merges snapshots of shards read concurrently, so users are streamed in order
+// by name without keeping all of them.
+func (r *Repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streams := make([]chan models.User, len(r.all))
+	errs := make([]error, len(r.all))
+	var wg sync.WaitGroup
+	for i, name := range r.all {
+		streams[i] = make(chan models.User)
+		wg.Add(1)
+		go func(i int, shard repoPkg.Interface) {
+			defer wg.Done()
+			defer close(streams[i])
+			errs[i] = shard.UserSnapshot(ctx, func(user models.User) error {
+				select {
+				case streams[i] <- user:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}(i, r.shards[name])
+	}
+
+	err := merge(streams, fn)
+	cancel()
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	for _, err = range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge calls fn for users of the ordered streams in order, a user kept by two shards
+// during a move is passed once.
+func merge(streams []chan models.User, fn func(user models.User) error) error {
+	heads := make([]*models.User, len(streams))
+	next := func(i int) {
+		if user, ok := <-streams[i]; ok {
+			heads[i] = &user
+		} else {
+			heads[i] = nil
+		}
+	}
+	for i := range streams {
+		next(i)
+	}
+	var last *models.User
+	for {
+		first := -1
+		for i, head := range heads {
+			if head != nil && (first < 0 || head.Name < heads[first].Name) {
+				first = i
+			}
+		}
+		if first < 0 {
+			return nil
+		}
+		user := *heads[first]
+		next(first)
+		if last != nil && last.Name == user.Name {
+			continue
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+		last = &user
+	}
+}
+
+// UserTombstones merges tombstones of all shards in order of deletion.
+func (r *Repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	lists := make([][]models.Tombstone, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		lists[i], err = shard.UserTombstones(ctx, since)
+		return err
+	})
+	var tombstones []models.Tombstone
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, lists[i]...)
+	}
+	sort.SliceStable(tombstones, func(i, j int) bool {
+		return tombstones[i].DeletedAt < tombstones[j].DeletedAt
+	})
+	return tombstones, nil
+}
+
+func (r *Repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	pruned := make([]int64, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		pruned[i], err = shard.UserTombstonesPrune(ctx, before)
+		return err
+	})
+	var total int64
+	for i, err := range errs {
+		if err != nil {
+			return total, err
+		}
+		total += pruned[i]
+	}
+	return total, nil
+}
+
+// UserCounts sums counters of the shards, every user is counted by its shard only.
+func (r *Repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	lists := make([]map[string]int64, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		lists[i], err = shard.UserCounts(ctx)
+		return err
+	})
+	counts := make(map[string]int64)
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		for tenant, users := range lists[i] {
+			counts[tenant] += users
+		}
+	}
+	return counts, nil
+}
+
+func (r *Repo) UserCountsReconcile(ctx context.Context) (int64, error) {
+	corrected := make([]int64, len(r.all))
+	errs := r.each(func(i int, shard repoPkg.Interface) error {
+		var err error
+		corrected[i], err = shard.UserCountsReconcile(ctx)
+		return err
+	})
+	var total int64
+	for i, err := range errs {
+		if err != nil {
+			return total, err
+		}
+		total += corrected[i]
+	}
+	return total, nil
+}
+
+// Close closes all shards, including previous ones removed from the ring.
+func (r *Repo) Close() {
+	for _, shard := range r.shards {
+		shard.Close()
+	}
+}
+
+// each calls fn for every shard keeping users concurrently, errors are returned by shard index.
+func (r *Repo) each(fn func(i int, shard repoPkg.Interface) error) []error {
+	errs := make([]error, len(r.all))
+	var wg sync.WaitGroup
+	for i, name := range r.all {
+		wg.Add(1)
+		go func(i int, shard repoPkg.Interface) {
+			defer wg.Done()
+			errs[i] = fn(i, shard)
+		}(i, r.shards[name])
+	}
+	wg.Wait()
+	return errs
+}
+
+// Run migrates users to their owners every interval until ctx is done, it does nothing,
+// if there is no resharding. It must be run on the leader only.
+func (r *Repo) Run(ctx context.Context) {
+	if r.previous == nil {
+		return
+	}
+	r.logger.Infow("Start shard migration", "shards", r.names, "previous", r.cfg.Previous,
+		"interval", r.cfg.MigrateInterval)
+	ticker := time.NewTicker(r.cfg.MigrateInterval)
+	defer ticker.Stop()
+	for {
+		moved, err := r.Migrate(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorf("shard migration: %v", err)
+		}
+		if moved != 0 {
+			r.logger.Infow("Users moved to their shards", "users", moved)
+		}
+		if err == nil && moved == 0 {
+			r.logger.Infoln("Shard migration is done, previous shards can be removed from the config")
+		}
+		select {
+		case <-ctx.Done():
+			r.logger.Infoln("Shard migration stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Migrate moves users of the previous shards, which are owned by other shards now.
+func (r *Repo) Migrate(ctx context.Context) (int, error) {
+	var moved int
+	for _, name := range r.cfg.Previous {
+		// users are moved after the snapshot, so it is not read while its shard is written
+		var misplaced []string
+		err := r.shards[name].UserSnapshot(ctx, func(user models.User) error {
+			if r.ring.owner(user.Name) != name {
+				misplaced = append(misplaced, user.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, errors.Wrapf(err, "shard [%s] snapshot", name)
+		}
+		for _, user := range misplaced {
+			if err = r.move(ctx, r.shards[name], user); err != nil {
+				return moved, errors.Wrapf(err, "shard [%s] move [%s]", name, user)
+			}
+			moved++
+			counter.ShardMoved.Inc()
+		}
+	}
+	return moved, nil
+}
+
+// move creates the user of the source on its owner and deletes it from the source.
+// The user written to the owner after resharding is kept. The moved user is stamped,
+// so delta exports send it again after the tombstone left by the deletion.
+func (r *Repo) move(ctx context.Context, source repoPkg.Interface, name string) error {
+	user, err := source.UserGet(ctx, name)
+	if errors.Is(err, errorsPkg.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	owner := r.owner(name)
+	if _, err = owner.UserGet(ctx, name); errors.Is(err, errorsPkg.ErrUserNotFound) {
+		user.UpdatedAt = time.Now().Unix()
+		if err = owner.UserCreate(ctx, user); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return source.UserDelete(ctx, name)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
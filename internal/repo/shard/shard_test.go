@@ -0,0 +1,274 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	errorsPkg "gitlab.ozon.dev/iTukaev/homework/internal/customerrors"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/local"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/workerpool"
+)
+
+func newShards(t *testing.T, names ...string) map[string]repoPkg.Interface {
+	shards := make(map[string]repoPkg.Interface, len(names))
+	for _, name := range names {
+		pool := workerpool.New(name, workerpool.Config{Workers: 1}, zap.NewNop().Sugar())
+		shards[name] = local.New(pool, zap.NewNop().Sugar())
+		t.Cleanup(shards[name].Close)
+	}
+	return shards
+}
+
+func user(i int) models.User {
+	return models.User{ID: fmt.Sprintf("id-%03d", i), Name: fmt.Sprintf("user_%03d", i)}
+}
+
+func names(users []models.User) []string {
+	result := make([]string, 0, len(users))
+	for _, u := range users {
+		result = append(result, u.Name)
+	}
+	return result
+}
+
+func TestRing_Owner(t *testing.T) {
+	before := newRing([]string{"a", "b", "c"}, 0)
+	after := newRing([]string{"c", "b", "a", "d"}, 0)
+
+	owned := make(map[string]int)
+	var moved int
+	const keys = 10000
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("user_%d", i)
+		owned[before.owner(key)]++
+		if before.owner(key) != after.owner(key) {
+			assert.Equal(t, "d", after.owner(key), "keys are moved to the added shard only")
+			moved++
+		}
+	}
+	for _, shard := range []string{"a", "b", "c"} {
+		assert.InDelta(t, keys/3, owned[shard], keys/10, "shard [%s] owns about a third of keys", shard)
+	}
+	assert.InDelta(t, keys/4, moved, keys/10)
+}
+
+func TestRepo_Routing(t *testing.T) {
+	ctx := context.Background()
+	shards := newShards(t, "a", "b", "c")
+	r, err := New(shards, Config{Shards: []Shard{{Name: "a"}, {Name: "b"}, {Name: "c"}}}, loggerPkg.NewFatal())
+	require.NoError(t, err)
+
+	var all []string
+	for i := 0; i < 30; i++ {
+		require.NoError(t, r.UserCreate(ctx, user(i)))
+		all = append(all, user(i).Name)
+	}
+	for i := 0; i < 30; i++ {
+		for name, shard := range shards {
+			_, err = shard.UserGet(ctx, user(i).Name)
+			assert.Equal(t, name == r.ring.owner(user(i).Name), err == nil, "user is kept by its owner only")
+		}
+	}
+
+	got, err := r.UserGet(ctx, "user_007")
+	require.NoError(t, err)
+	assert.Equal(t, user(7), got)
+	got, err = r.UserGetByID(ctx, "id-011")
+	require.NoError(t, err)
+	assert.Equal(t, "user_011", got.Name)
+	_, err = r.UserGetByID(ctx, "unknown")
+	assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+
+	list, err := r.UserList(ctx, false, 4, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, all[8:12], names(list))
+	list, err = r.UserList(ctx, true, 4, 7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_001", "user_000"}, names(list))
+	list, err = r.UserList(ctx, false, 4, 8, nil)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	var snapshot []string
+	require.NoError(t, r.UserSnapshot(ctx, func(u models.User) error {
+		snapshot = append(snapshot, u.Name)
+		return nil
+	}))
+	assert.Equal(t, all, snapshot)
+	err = r.UserSnapshot(ctx, func(models.User) error {
+		return errorsPkg.ErrUnexpected
+	})
+	assert.ErrorIs(t, err, errorsPkg.ErrUnexpected)
+}
+
+func TestRepo_UserList(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name       string
+		policy     string
+		failed     []string
+		expUsers   int
+		expSkipped []string
+		expErr     error
+	}{
+		{name: "all shards", policy: ListBestEffort, expUsers: 30},
+		{name: "fail", failed: []string{"b"}, expErr: errorsPkg.ErrUnexpected},
+		{name: "best effort", policy: ListBestEffort, failed: []string{"b"}, expSkipped: []string{"b"}},
+		{name: "best effort all failed", policy: ListBestEffort, failed: []string{"a", "b", "c"}, expErr: errorsPkg.ErrUnexpected},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			shards := newShards(t, "a", "b", "c")
+			cfg := Config{Shards: []Shard{{Name: "a"}, {Name: "b"}, {Name: "c"}}, ListPolicy: c.policy}
+			r, err := New(shards, cfg, loggerPkg.NewFatal())
+			require.NoError(t, err)
+			var expected []string
+			for i := 0; i < 30; i++ {
+				require.NoError(t, r.UserCreate(ctx, user(i)))
+				if !contains(c.failed, r.ring.owner(user(i).Name)) {
+					expected = append(expected, user(i).Name)
+				}
+			}
+			for _, name := range c.failed {
+				shards[name] = failing{shards[name]}
+			}
+
+			ctx, partial := repoPkg.WithPartial(ctx)
+			list, err := r.UserList(ctx, false, 100, 0, nil)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, expected, names(list))
+			}
+			assert.Equal(t, c.expSkipped, partial.Skipped())
+		})
+	}
+}
+
+// failing is a shard failing lists.
+type failing struct {
+	repoPkg.Interface
+}
+
+func (failing) UserList(context.Context, bool, uint64, uint64, filter.Expr) ([]models.User, error) {
+	return nil, errorsPkg.ErrUnexpected
+}
+
+func TestMergePages(t *testing.T) {
+	pages := [][]models.User{
+		{user(1), user(4), user(5)},
+		{user(2), user(4)},
+		{},
+		{user(3), user(6)},
+	}
+	assert.Equal(t, []string{"user_001", "user_002", "user_003", "user_004"}, names(mergePages(pages, false, 0, 4)))
+	assert.Equal(t, []string{"user_005", "user_006"}, names(mergePages(pages, false, 4, 8)))
+	assert.Empty(t, mergePages(pages, false, 8, 12))
+}
+
+func TestRepo_UserRename(t *testing.T) {
+	ctx := context.Background()
+	r, err := New(newShards(t, "a", "b"), Config{Shards: []Shard{{Name: "a"}, {Name: "b"}}}, loggerPkg.NewFatal())
+	require.NoError(t, err)
+	// names of the other shard than the renamed one
+	source := user(1)
+	var target string
+	for i := 0; target == ""; i++ {
+		if name := fmt.Sprintf("renamed_%d", i); r.ring.owner(name) != r.ring.owner(source.Name) {
+			target = name
+		}
+	}
+	require.NoError(t, r.UserCreate(ctx, source))
+	require.NoError(t, r.UserCreate(ctx, user(2)))
+
+	assert.ErrorIs(t, r.UserRename(ctx, "unknown", target), errorsPkg.ErrUserNotFound)
+	require.NoError(t, r.UserRename(ctx, source.Name, target))
+	renamed, err := r.owner(target).UserGet(ctx, target)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, renamed.ID)
+	assert.NotZero(t, renamed.UpdatedAt)
+	_, err = r.UserGet(ctx, source.Name)
+	assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+
+	assert.ErrorIs(t, r.UserRename(ctx, user(2).Name, target), errorsPkg.ErrUserAlreadyExists)
+}
+
+func TestRepo_Migrate(t *testing.T) {
+	ctx := context.Background()
+	shards := newShards(t, "a", "b")
+	for i := 0; i < 20; i++ {
+		require.NoError(t, shards["a"].UserCreate(ctx, user(i)))
+	}
+	// b is added to the ring of a
+	r, err := New(shards, Config{Shards: []Shard{{Name: "a"}, {Name: "b"}}, Previous: []string{"a"}}, loggerPkg.NewFatal())
+	require.NoError(t, err)
+
+	var misplaced []models.User
+	for i := 0; i < 20; i++ {
+		if r.ring.owner(user(i).Name) == "b" {
+			misplaced = append(misplaced, user(i))
+		}
+	}
+	require.NotEmpty(t, misplaced)
+	got, err := r.UserGet(ctx, misplaced[0].Name)
+	require.NoError(t, err, "the user not moved yet is read from the previous owner")
+	assert.Equal(t, misplaced[0], got)
+	list, err := r.UserList(ctx, false, 100, 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, list, 20)
+
+	// the update moves the user first
+	require.NoError(t, r.UserUpdate(ctx, models.User{Name: misplaced[0].Name, Email: "moved@example.com"}))
+	got, err = shards["b"].UserGet(ctx, misplaced[0].Name)
+	require.NoError(t, err)
+	assert.Equal(t, "moved@example.com", got.Email)
+	// the user not moved yet is not created on its owner again
+	last := misplaced[len(misplaced)-1]
+	assert.ErrorIs(t, r.UserCreate(ctx, last), errorsPkg.ErrUserAlreadyExists)
+	_, err = shards["b"].UserGet(ctx, last.Name)
+	assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+	require.NoError(t, r.UserCreate(ctx, user(20)))
+
+	moved, err := r.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(misplaced)-1, moved)
+	for _, u := range misplaced {
+		_, err = shards["a"].UserGet(ctx, u.Name)
+		assert.ErrorIs(t, err, errorsPkg.ErrUserNotFound)
+		_, err = shards["b"].UserGet(ctx, u.Name)
+		assert.NoError(t, err)
+	}
+	moved, err = r.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+}
+
+func TestNew(t *testing.T) {
+	shards := newShards(t, "a")
+	cases := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "no shards"},
+		{name: "unknown shard", cfg: Config{Shards: []Shard{{Name: "b"}}}},
+		{name: "unknown previous shard", cfg: Config{Shards: []Shard{{Name: "a"}}, Previous: []string{"b"}}},
+		{name: "all shards draining", cfg: Config{Shards: []Shard{{Name: "a", Draining: true}}}},
+		{name: "unknown list policy", cfg: Config{Shards: []Shard{{Name: "a"}}, ListPolicy: "any"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(shards, c.cfg, loggerPkg.NewFatal())
+			assert.ErrorIs(t, err, errorsPkg.ErrValidation)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package slowlog
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	jaegerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/jaeger"
+)
+
+const redacted = "***"
+
+// New wraps repository, calls longer than threshold are logged
+// and counted in counter.SlowOps by method name.
+func New(data repoPkg.Interface, threshold time.Duration, logger *zap.SugaredLogger) repoPkg.Interface {
+	logger.Infof("With slow operations log started, threshold %s", threshold)
+	return &repo{
+		data:      data,
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+type repo struct {
+	data      repoPkg.Interface
+	threshold time.Duration
+	logger    *zap.SugaredLogger
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	defer r.observe(ctx, "UserCreate", time.Now(), "user", redact(user))
+	return r.data.UserCreate(ctx, user)
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	defer r.observe(ctx, "UserUpdate", time.Now(), "user", redact(user))
+	return r.data.UserUpdate(ctx, user)
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	defer r.observe(ctx, "UserDelete", time.Now(), "name", name)
+	return r.data.UserDelete(ctx, name)
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	defer r.observe(ctx, "UserRename", time.Now(), "name", oldName, "new_name", newName)
+	return r.data.UserRename(ctx, oldName, newName)
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	defer r.observe(ctx, "UserGet", time.Now(), "name", name)
+	return r.data.UserGet(ctx, name)
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	defer r.observe(ctx, "UserGetByID", time.Now(), "id", id)
+	return r.data.UserGetByID(ctx, id)
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	defer r.observe(ctx, "UserList", time.Now(),
+		"order", order, "limit", limit, "offset", offset, "where", where)
+	return r.data.UserList(ctx, order, limit, offset, where)
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	defer r.observe(ctx, "UserSnapshot", time.Now())
+	return r.data.UserSnapshot(ctx, fn)
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	defer r.observe(ctx, "UserTombstones", time.Now(), "since", since)
+	return r.data.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	defer r.observe(ctx, "UserTombstonesPrune", time.Now(), "before", before)
+	return r.data.UserTombstonesPrune(ctx, before)
+}
+
+func (r *repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	defer r.observe(ctx, "UserCounts", time.Now())
+	return r.data.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (int64, error) {
+	defer r.observe(ctx, "UserCountsReconcile", time.Now())
+	return r.data.UserCountsReconcile(ctx)
+}
+
+func (r *repo) Close() {
+	r.data.Close()
+}
+
+// observe logs the call, if it took longer than threshold.
+// Trace ID is logged for traced calls, stack is logged otherwise.
+func (r *repo) observe(ctx context.Context, method string, start time.Time, args ...interface{}) {
+	duration := time.Since(start)
+	if duration < r.threshold {
+		return
+	}
+	counter.SlowOps.Inc(method)
+
+	fields := append([]interface{}{"op", "repo." + method, "duration", duration}, args...)
+	if traceID, ok := jaegerPkg.TraceID(ctx); ok {
+		fields = append(fields, "trace_id", traceID)
+	} else {
+		fields = append(fields, "stack", string(debug.Stack()))
+	}
+	r.logger.Warnw("slow repo operation", fields...)
+}
+
+// redact hides credentials of the user.
+func redact(user models.User) models.User {
+	if user.Password != "" {
+		user.Password = redacted
+	}
+	return user
+}
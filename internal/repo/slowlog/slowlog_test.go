@@ -0,0 +1,60 @@
+package slowlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestRepo_UserGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	cases := []struct {
+		name    string
+		delay   time.Duration
+		expSlow bool
+	}{
+		{
+			name:    "fast call is not counted",
+			delay:   0,
+			expSlow: false,
+		},
+		{
+			name:    "slow call is counted",
+			delay:   20 * time.Millisecond,
+			expSlow: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := repoMockPkg.NewMockInterface(ctl)
+			data.EXPECT().UserGet(gomock.Any(), "alice").
+				DoAndReturn(func(context.Context, string) (models.User, error) {
+					time.Sleep(c.delay)
+					return models.User{Name: "alice"}, nil
+				}).Times(1)
+
+			before := counter.SlowOps.String()
+			user, err := New(data, 10*time.Millisecond, loggerPkg.NewFatal()).UserGet(context.Background(), "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", user.Name)
+			assert.Equal(t, c.expSlow, before != counter.SlowOps.String())
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	user := redact(models.User{Name: "alice", Password: "secret"})
+	assert.Equal(t, redacted, user.Password)
+	assert.Equal(t, "alice", user.Name)
+}
@@ -0,0 +1,91 @@
+package timed
+
+import (
+	"context"
+	"time"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo"
+	"gitlab.ozon.dev/iTukaev/homework/internal/repo/filter"
+	timingPkg "gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+// New wraps repository, duration of calls is added to the repo layer of the request timings.
+func New(data repoPkg.Interface) repoPkg.Interface {
+	return &repo{
+		data: data,
+	}
+}
+
+type repo struct {
+	data repoPkg.Interface
+}
+
+func (r *repo) UserCreate(ctx context.Context, user models.User) error {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserCreate(ctx, user)
+}
+
+func (r *repo) UserUpdate(ctx context.Context, user models.User) error {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserUpdate(ctx, user)
+}
+
+func (r *repo) UserDelete(ctx context.Context, name string) error {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserDelete(ctx, name)
+}
+
+func (r *repo) UserRename(ctx context.Context, oldName, newName string) error {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserRename(ctx, oldName, newName)
+}
+
+func (r *repo) UserGet(ctx context.Context, name string) (models.User, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserGet(ctx, name)
+}
+
+func (r *repo) UserGetByID(ctx context.Context, id string) (models.User, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserGetByID(ctx, id)
+}
+
+func (r *repo) UserList(
+	ctx context.Context,
+	order bool,
+	limit, offset uint64,
+	where filter.Expr,
+) ([]models.User, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserList(ctx, order, limit, offset, where)
+}
+
+func (r *repo) UserSnapshot(ctx context.Context, fn func(user models.User) error) error {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserSnapshot(ctx, fn)
+}
+
+func (r *repo) UserTombstones(ctx context.Context, since int64) ([]models.Tombstone, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserTombstones(ctx, since)
+}
+
+func (r *repo) UserTombstonesPrune(ctx context.Context, before int64) (int64, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserTombstonesPrune(ctx, before)
+}
+
+func (r *repo) UserCounts(ctx context.Context) (map[string]int64, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserCounts(ctx)
+}
+
+func (r *repo) UserCountsReconcile(ctx context.Context) (int64, error) {
+	defer timingPkg.Track(ctx, timingPkg.Repo, time.Now())
+	return r.data.UserCountsReconcile(ctx)
+}
+
+func (r *repo) Close() {
+	r.data.Close()
+}
@@ -0,0 +1,36 @@
+package timed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	repoMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/repo/mock"
+	timingPkg "gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+func TestRepo_UserGet(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	data := repoMockPkg.NewMockInterface(ctl)
+	data.EXPECT().UserGet(gomock.Any(), "alice").
+		DoAndReturn(func(context.Context, string) (models.User, error) {
+			time.Sleep(10 * time.Millisecond)
+			return models.User{Name: "alice"}, nil
+		}).Times(2)
+	repo := New(data)
+
+	_, err := repo.UserGet(context.Background(), "alice")
+	assert.NoError(t, err)
+
+	ctx, timings := timingPkg.WithTimings(context.Background())
+	_, err = repo.UserGet(ctx, "alice")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, timings.Get(timingPkg.Repo), 10*time.Millisecond)
+	assert.Zero(t, timings.Get(timingPkg.Cache))
+}
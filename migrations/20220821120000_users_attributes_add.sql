@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS attributes jsonb NOT NULL DEFAULT '{}'::jsonb;
+CREATE INDEX IF NOT EXISTS users_attributes_idx ON public.users USING gin (attributes jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_attributes_idx;
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS attributes;
+-- +goose StatementEnd
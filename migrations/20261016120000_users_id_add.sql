@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS id uuid;
+UPDATE public.users
+SET id = gen_random_uuid()
+WHERE id IS NULL;
+ALTER TABLE public.users
+    ALTER COLUMN id SET NOT NULL,
+    ALTER COLUMN id SET DEFAULT gen_random_uuid(),
+    DROP CONSTRAINT users_pkey,
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT users_name_key UNIQUE (name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.users
+    DROP CONSTRAINT users_name_key,
+    DROP CONSTRAINT users_pkey,
+    ADD CONSTRAINT users_pkey PRIMARY KEY (name),
+    DROP COLUMN IF EXISTS id;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bcrypt hashes and random passwords of provisioned users exceed 30 characters
+ALTER TABLE public.users
+    ALTER COLUMN password TYPE varchar(100);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- longer passwords are not truncated, truncated bcrypt hashes would never match
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM public.users WHERE length(password) > 30) THEN
+        RAISE EXCEPTION 'users have passwords longer than 30 characters, the column can not be narrowed';
+    END IF;
+END $$;
+ALTER TABLE public.users
+    ALTER COLUMN password TYPE varchar(30);
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'active',
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'disabled', 'pending'));
+UPDATE public.users
+SET status = 'disabled'
+WHERE attributes ->> 'disabled' = 'true';
+UPDATE public.users
+SET attributes = attributes - 'disabled'
+WHERE attributes ? 'disabled';
+CREATE INDEX IF NOT EXISTS users_status_idx ON public.users (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_status_idx;
+UPDATE public.users
+SET attributes = attributes || '{"disabled": "true"}'::jsonb
+WHERE status = 'disabled';
+ALTER TABLE public.users
+    DROP CONSTRAINT users_status_check,
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS password_changed_at bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS password_expires_at bigint NOT NULL DEFAULT 0;
+UPDATE public.users
+SET password_changed_at = created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS password_expires_at,
+    DROP COLUMN IF EXISTS password_changed_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS public.users_history (
+  id            bigserial PRIMARY KEY,
+  user_id       uuid NOT NULL,
+  name          varchar(30) NOT NULL,
+  action        varchar(16) NOT NULL,
+  state         jsonb NOT NULL,
+  actor         varchar(255) NOT NULL DEFAULT '',
+  created_at    timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS users_history_user_id_idx ON public.users_history (user_id, id);
+CREATE INDEX IF NOT EXISTS users_history_name_idx ON public.users_history (name, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.users_history;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- daily partitions are created ahead and dropped by the history retention,
+-- rows out of them are kept in the default partition
+ALTER TABLE public.users_history RENAME TO users_history_unpartitioned;
+ALTER INDEX public.users_history_user_id_idx RENAME TO users_history_unpartitioned_user_id_idx;
+ALTER INDEX public.users_history_name_idx RENAME TO users_history_unpartitioned_name_idx;
+ALTER TABLE public.users_history_unpartitioned RENAME CONSTRAINT users_history_pkey TO users_history_unpartitioned_pkey;
+ALTER SEQUENCE public.users_history_id_seq RENAME TO users_history_unpartitioned_id_seq;
+
+CREATE TABLE public.users_history (
+  id            bigserial,
+  user_id       uuid NOT NULL,
+  name          varchar(30) NOT NULL,
+  action        varchar(16) NOT NULL,
+  state         jsonb NOT NULL,
+  actor         varchar(255) NOT NULL DEFAULT '',
+  created_at    timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+CREATE TABLE public.users_history_default PARTITION OF public.users_history DEFAULT;
+CREATE INDEX users_history_user_id_idx ON public.users_history (user_id, id);
+CREATE INDEX users_history_name_idx ON public.users_history (name, id);
+
+INSERT INTO public.users_history SELECT * FROM public.users_history_unpartitioned;
+SELECT setval(pg_get_serial_sequence('public.users_history', 'id'),
+              (SELECT coalesce(max(id), 0) + 1 FROM public.users_history), false);
+DROP TABLE public.users_history_unpartitioned;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.users_history RENAME TO users_history_partitioned;
+ALTER INDEX public.users_history_user_id_idx RENAME TO users_history_partitioned_user_id_idx;
+ALTER INDEX public.users_history_name_idx RENAME TO users_history_partitioned_name_idx;
+ALTER TABLE public.users_history_partitioned RENAME CONSTRAINT users_history_pkey TO users_history_partitioned_pkey;
+ALTER SEQUENCE public.users_history_id_seq RENAME TO users_history_partitioned_id_seq;
+
+CREATE TABLE public.users_history (
+  id            bigserial PRIMARY KEY,
+  user_id       uuid NOT NULL,
+  name          varchar(30) NOT NULL,
+  action        varchar(16) NOT NULL,
+  state         jsonb NOT NULL,
+  actor         varchar(255) NOT NULL DEFAULT '',
+  created_at    timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX users_history_user_id_idx ON public.users_history (user_id, id);
+CREATE INDEX users_history_name_idx ON public.users_history (name, id);
+
+INSERT INTO public.users_history SELECT * FROM public.users_history_partitioned;
+SELECT setval(pg_get_serial_sequence('public.users_history', 'id'),
+              (SELECT coalesce(max(id), 0) + 1 FROM public.users_history), false);
+DROP TABLE public.users_history_partitioned;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- hybrid logical clock timestamp of the last write, replicated writes are resolved by it
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS hlc bigint NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS hlc;
+-- +goose StatementEnd
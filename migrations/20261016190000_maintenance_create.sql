@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the only row keeps maintenance mode, so it survives restarts of the data service
+CREATE TABLE IF NOT EXISTS public.maintenance
+(
+    id      smallint PRIMARY KEY CHECK (id = 1),
+    enabled boolean  NOT NULL,
+    reason  text     NOT NULL DEFAULT '',
+    actor   text     NOT NULL DEFAULT '',
+    since   bigint   NOT NULL DEFAULT 0
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.maintenance;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- request meta of the caller acting as the actor, empty if the caller is the actor
+ALTER TABLE public.users_history
+    ADD COLUMN IF NOT EXISTS real_actor varchar(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.users_history
+    DROP COLUMN IF EXISTS real_actor;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- time of the last write in UNIX format, incremental exports select users by it
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS updated_at bigint NOT NULL DEFAULT 0;
+UPDATE public.users
+SET updated_at = COALESCE(created_at, 0)
+WHERE updated_at = 0;
+CREATE INDEX IF NOT EXISTS users_updated_at_idx ON public.users (updated_at);
+
+-- users left by deletions, so incremental exports see them
+CREATE TABLE IF NOT EXISTS public.users_tombstones
+(
+    id         uuid         NOT NULL,
+    name       varchar(255) NOT NULL,
+    deleted_at bigint       NOT NULL
+);
+CREATE INDEX IF NOT EXISTS users_tombstones_deleted_at_idx ON public.users_tombstones (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.users_tombstones;
+DROP INDEX IF EXISTS public.users_updated_at_idx;
+ALTER TABLE public.users
+    DROP COLUMN IF EXISTS updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- old rows of updates and deletions carry all columns, so CDC finds cached users by their names
+ALTER TABLE public.users
+    REPLICA IDENTITY FULL;
+CREATE PUBLICATION users_cdc FOR TABLE public.users;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP PUBLICATION IF EXISTS users_cdc;
+ALTER TABLE public.users
+    REPLICA IDENTITY DEFAULT;
+-- +goose StatementEnd
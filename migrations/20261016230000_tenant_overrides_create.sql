@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- config overrides of tenants managed by admin TenantOverridesSet, the tenant is the user attribute
+CREATE TABLE IF NOT EXISTS public.tenant_overrides
+(
+    tenant     text   PRIMARY KEY,
+    overrides  jsonb  NOT NULL,
+    actor      text   NOT NULL DEFAULT '',
+    updated_at bigint NOT NULL DEFAULT 0
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.tenant_overrides;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users by tenant maintained by the statements changing users, the tenant is the user attribute,
+-- users without it are counted by the empty tenant
+CREATE TABLE IF NOT EXISTS public.user_counters
+(
+    tenant text   PRIMARY KEY,
+    users  bigint NOT NULL DEFAULT 0
+);
+INSERT INTO public.user_counters (tenant, users)
+SELECT COALESCE(attributes ->> 'tenant', ''), count(*)
+FROM public.users
+GROUP BY 1
+ON CONFLICT (tenant) DO UPDATE SET users = EXCLUDED.users;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.user_counters;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- calls and errors of API clients by hourly periods, replicas add their counts, reported by admin UsageReport
+CREATE TABLE IF NOT EXISTS public.api_usage
+(
+    client   text   NOT NULL,
+    method   text   NOT NULL,
+    period   bigint NOT NULL,
+    requests bigint NOT NULL DEFAULT 0,
+    errors   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (client, method, period)
+);
+CREATE INDEX IF NOT EXISTS api_usage_period_idx ON public.api_usage (period);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.api_usage;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- normalized names keep latin, greek and cyrillic letters, the same blocks are checked by the name normalizer
+ALTER TABLE public.users
+    DROP CONSTRAINT name_right,
+    ADD CONSTRAINT name_right CHECK ( name ~ '^[A-Za-z0-9_\.\u00C0-\u024F\u0370-\u03FF\u0400-\u052F\u1E00-\u1FFF]+$' );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- fails, if names with other letters are stored
+ALTER TABLE public.users
+    DROP CONSTRAINT name_right,
+    ADD CONSTRAINT name_right CHECK ( name ~ '^[A-Za-z0-9_\.]+$' );
+-- +goose StatementEnd
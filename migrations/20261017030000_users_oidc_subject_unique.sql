@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an external identity is linked to a single user, otherwise its login is rejected
+CREATE UNIQUE INDEX IF NOT EXISTS users_oidc_subject_idx
+    ON public.users ((attributes ->> 'oidc_subject'))
+    WHERE attributes ? 'oidc_subject';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_oidc_subject_idx;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+-- recorded states keep no passwords, states recorded before are scrubbed
+UPDATE public.users_history SET state = state - 'password' WHERE state ? 'password';
+-- +goose StatementEnd
+
+-- +goose Down
+-- scrubbed passwords can't be recorded back, states are kept without them
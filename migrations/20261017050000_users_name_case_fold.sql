@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the name policy folds case of names, stored names are folded the same way, so users created before
+-- the policy stay reachable. name_fold is the Unicode case folding of letters of the allowed blocks:
+-- lower() and letters, which lower() keeps, e.g. "ß", the final sigma and greek symbol variants.
+CREATE FUNCTION pg_temp.name_fold(name text) RETURNS text
+    LANGUAGE sql
+    IMMUTABLE AS
+$fold$
+SELECT translate(replace(lower(name), U&'\00DF', 'ss'),
+                 U&'\017F\03C2\03D0\03D1\03D5\03D6\03F0\03F1\03F5\1E9B\1FBE',
+                 U&'\0073\03C3\03B2\03B8\03C6\03C0\03BA\03C1\03B5\1E61\03B9')
+$fold$;
+
+-- letters folded to combining marks or to several letters, e.g. greek ones with iota subscript,
+-- names with them are rejected by the normalizer, their users have to be renamed before the migration,
+-- the same is for users, whose folded names collide
+DO $$
+DECLARE
+    unsupported text;
+    collided    text;
+BEGIN
+    SELECT string_agg(name, ', ' ORDER BY name) INTO unsupported
+    FROM public.users
+    WHERE name || lower(name) ~ '[\u0130\u0149\u01F0\u0390\u03B0\u1E96-\u1E9A\u1F50\u1F52\u1F54\u1F56\u1F80-\u1F87\u1F90-\u1F97\u1FA0-\u1FA7\u1FB2-\u1FB4\u1FB6\u1FB7\u1FC2-\u1FC4\u1FC6\u1FC7\u1FD2\u1FD3\u1FD6\u1FD7\u1FE2-\u1FE4\u1FE6\u1FE7\u1FF2-\u1FF4\u1FF6\u1FF7]';
+    IF unsupported IS NOT NULL THEN
+        RAISE EXCEPTION 'user names can not be case folded, rename them first: %', unsupported;
+    END IF;
+
+    SELECT string_agg(names, '; ') INTO collided
+    FROM (SELECT string_agg(name, ', ' ORDER BY name) AS names
+          FROM public.users
+          GROUP BY pg_temp.name_fold(name)
+          HAVING count(*) > 1) AS collisions;
+    IF collided IS NOT NULL THEN
+        RAISE EXCEPTION 'user names collide after case folding, rename them first: %', collided;
+    END IF;
+END $$;
+
+UPDATE public.users
+SET name       = pg_temp.name_fold(name),
+    updated_at = extract(EPOCH FROM now())::bigint
+WHERE name <> pg_temp.name_fold(name);
+
+-- history is looked up by names
+UPDATE public.users_history
+SET name = pg_temp.name_fold(name)
+WHERE name <> pg_temp.name_fold(name);
+
+DROP FUNCTION pg_temp.name_fold(text);
+-- +goose StatementEnd
+
+-- +goose Down
+-- original case of names is not kept, names stay folded
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FS contains goose migrations of the users database.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns version of the last migration, it is a prefix of the file name.
+func Latest() (int64, error) {
+	files, err := fs.Glob(FS, "*.sql")
+	if err != nil {
+		return 0, errors.Wrap(err, "list migrations")
+	}
+
+	var latest int64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(file, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "migration [%s] version", file)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
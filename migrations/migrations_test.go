@@ -0,0 +1,14 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	latest, err := Latest()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, latest, int64(20220821120000))
+}
@@ -1,35 +1,119 @@
 package adaptor
 
 import (
+	"sync"
+
 	coreModels "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
 	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
 )
 
 func ToUserPbModel(u coreModels.User) *pbModels.User {
-	return &pbModels.User{
-		Name:      u.Name,
-		Password:  u.Password,
-		Email:     u.Email,
-		FullName:  u.FullName,
-		CreatedAt: u.CreatedAt,
+	user := new(pbModels.User)
+	fillUserPbModel(user, u)
+	return user
+}
+
+// fillUserPbModel overwrites the pb user, so it is reused without allocation.
+func fillUserPbModel(dst *pbModels.User, u coreModels.User) {
+	*dst = pbModels.User{
+		Id:         u.ID,
+		Name:       u.Name,
+		Password:   u.Password,
+		Email:      u.Email,
+		FullName:   u.FullName,
+		CreatedAt:  u.CreatedAt,
+		Status:     u.Status,
+		Attributes: u.Attributes,
+		AvatarUrl:  u.AvatarURL,
+
+		PasswordChangedAt: u.PasswordChangedAt,
+		PasswordExpiresAt: u.PasswordExpiresAt,
+		Hlc:               u.HLC,
+		UpdatedAt:         u.UpdatedAt,
 	}
 }
 
 func ToUserCoreModel(u *pbModels.User) *coreModels.User {
 	return &coreModels.User{
-		Name:      u.Name,
-		Password:  u.Password,
-		Email:     u.Email,
-		FullName:  u.FullName,
-		CreatedAt: u.CreatedAt,
+		Name:       u.Name,
+		Password:   u.Password,
+		Email:      u.Email,
+		FullName:   u.FullName,
+		CreatedAt:  u.CreatedAt,
+		Attributes: u.Attributes,
 	}
 }
 
+// ToUserCoreState keeps stored fields of the user: ID, password, status and timestamps,
+// it is used, when the user is written as is.
+func ToUserCoreState(u *pbModels.User) coreModels.User {
+	return coreModels.User{
+		ID:         u.GetId(),
+		Name:       u.GetName(),
+		Password:   u.GetPassword(),
+		Email:      u.GetEmail(),
+		FullName:   u.GetFullName(),
+		CreatedAt:  u.GetCreatedAt(),
+		Status:     u.GetStatus(),
+		Attributes: u.GetAttributes(),
+
+		PasswordChangedAt: u.GetPasswordChangedAt(),
+		PasswordExpiresAt: u.GetPasswordExpiresAt(),
+		HLC:               u.GetHlc(),
+		UpdatedAt:         u.GetUpdatedAt(),
+	}
+}
+
+// ToUserListPbModel converts the page of users, pb users are allocated by one slice.
 func ToUserListPbModel(users []coreModels.User) []*pbModels.User {
-	list := make([]*pbModels.User, 0, len(users))
-	for _, user := range users {
-		list = append(list, ToUserPbModel(user))
+	return new(UserBatch).Fill(users)
+}
+
+// maxPooledBatch limits users of a batch returned to the pool, so a rare huge page
+// does not keep its memory.
+const maxPooledBatch = 10000
+
+var userBatches = sync.Pool{
+	New: func() interface{} {
+		return new(UserBatch)
+	},
+}
+
+// UserBatch converts pages of users to pb users allocated by one slice. Pages of a stream
+// are converted by the same batch, since messages are encoded by Send and not kept after it.
+type UserBatch struct {
+	users []pbModels.User
+	list  []*pbModels.User
+}
+
+// GetUserBatch returns a batch of the pool, Release must be called, when its users are sent.
+func GetUserBatch() *UserBatch {
+	return userBatches.Get().(*UserBatch)
+}
+
+// Release returns the batch to the pool, users of the batch must not be used after it.
+func (b *UserBatch) Release() {
+	if cap(b.users) > maxPooledBatch {
+		return
+	}
+	// references of the last page are dropped, so pooled batch does not keep users alive
+	for i := range b.users {
+		b.users[i] = pbModels.User{}
 	}
+	b.users, b.list = b.users[:0], b.list[:0]
+	userBatches.Put(b)
+}
 
-	return list
+// Fill converts the page, pb users of the previous page are overwritten.
+func (b *UserBatch) Fill(users []coreModels.User) []*pbModels.User {
+	if cap(b.users) < len(users) {
+		b.users = make([]pbModels.User, len(users))
+		b.list = make([]*pbModels.User, len(users))
+	}
+	b.users, b.list = b.users[:len(users)], b.list[:len(users)]
+	for i, user := range users {
+		fillUserPbModel(&b.users[i], user)
+		b.list[i] = &b.users[i]
+	}
+	return b.list
 }
@@ -0,0 +1,89 @@
+package adaptor
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreModels "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+const benchPage = 10000
+
+func page(size int) []coreModels.User {
+	users := make([]coreModels.User, 0, size)
+	for i := 0; i < size; i++ {
+		name := "user" + strconv.Itoa(i)
+		users = append(users, coreModels.User{
+			ID:         strconv.Itoa(i),
+			Name:       name,
+			Email:      name + "@example.com",
+			FullName:   "User " + name,
+			CreatedAt:  1660000000,
+			Status:     coreModels.StatusActive,
+			Attributes: map[string]string{"team": "core"},
+		})
+	}
+	return users
+}
+
+func TestUserBatch_Fill(t *testing.T) {
+	batch := GetUserBatch()
+	defer batch.Release()
+
+	first := batch.Fill(page(3))
+	require.Len(t, first, 3)
+	assert.Equal(t, "user2", first[2].GetName())
+	assert.Equal(t, ToUserPbModel(page(3)[2]).GetEmail(), first[2].GetEmail())
+
+	// the next page overwrites users of the previous one
+	second := batch.Fill(page(2))
+	require.Len(t, second, 2)
+	assert.Same(t, first[0], second[0])
+	assert.Equal(t, "user1", second[1].GetName())
+
+	next := page(2)
+	assert.Zero(t, testing.AllocsPerRun(10, func() {
+		batch.Fill(next)
+	}), "page within capacity of the batch allocates nothing")
+}
+
+func TestUserBatch_Release(t *testing.T) {
+	batch := new(UserBatch)
+	users := batch.Fill(page(1))
+	batch.Release()
+	assert.Equal(t, "", users[0].GetName(), "released users are dropped")
+}
+
+// BenchmarkToUserList compares allocations of a 10k users page converted user by user,
+// by one slice and by the pooled batch reused by pages of a stream.
+func BenchmarkToUserList(b *testing.B) {
+	users := page(benchPage)
+
+	b.Run("per user", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			list := make([]*pbModels.User, 0, len(users))
+			for _, user := range users {
+				list = append(list, ToUserPbModel(user))
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ToUserListPbModel(users)
+		}
+	})
+	b.Run("pooled batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			batch := GetUserBatch()
+			batch.Fill(users)
+			batch.Release()
+		}
+	})
+}
@@ -34,10 +34,26 @@ type UserClient interface {
 	//
 	// Delete user from DB and cache
 	UserDelete(ctx context.Context, in *UserDeleteRequest, opts ...grpc.CallOption) (*UserDeleteResponse, error)
+	// Rename user
+	//
+	// Change user name in DB, move cached data and avatar to the new name
+	UserRename(ctx context.Context, in *UserRenameRequest, opts ...grpc.CallOption) (*UserRenameResponse, error)
 	// Get user information
 	//
 	// Returns user information by user name
 	UserGet(ctx context.Context, in *UserGetRequest, opts ...grpc.CallOption) (*UserGetResponse, error)
+	// Disable user
+	//
+	// Move active or pending user to disabled status, disabled user can't log in
+	UserDisable(ctx context.Context, in *UserDisableRequest, opts ...grpc.CallOption) (*UserDisableResponse, error)
+	// Enable user
+	//
+	// Move disabled or pending user to active status
+	UserEnable(ctx context.Context, in *UserEnableRequest, opts ...grpc.CallOption) (*UserEnableResponse, error)
+	// Get user information by ID
+	//
+	// Returns user information by immutable user ID
+	UserGetById(ctx context.Context, in *UserGetByIdRequest, opts ...grpc.CallOption) (*UserGetByIdResponse, error)
 	// Get users list
 	//
 	// Returns all users from DB
@@ -50,6 +66,68 @@ type UserClient interface {
 	//
 	// Returns all users from DB
 	UserAllList(ctx context.Context, in *UserAllListRequest, opts ...grpc.CallOption) (User_UserAllListClient, error)
+	// Import users
+	//
+	// Users are imported one by one, conflict strategy is taken from the first message.
+	// Result of every row is streamed back in the order of rows. Atomic imports are applied
+	// after the last row is received, if every row passes the checks, and none is written otherwise
+	UserImport(ctx context.Context, opts ...grpc.CallOption) (User_UserImportClient, error)
+	// Upload user avatar
+	//
+	// Client streaming upload, user name is taken from the first chunk.
+	// PNG, JPEG, GIF and WebP images are accepted
+	UserAvatarUpload(ctx context.Context, opts ...grpc.CallOption) (User_UserAvatarUploadClient, error)
+	// Get user avatar
+	//
+	// Returns avatar image by user name
+	UserAvatarGet(ctx context.Context, in *UserAvatarGetRequest, opts ...grpc.CallOption) (*UserAvatarGetResponse, error)
+	// Check user password
+	//
+	// Verifies password of the user for login integrations, failed attempts
+	// are limited per user. Unknown user is reported as invalid password
+	UserCheckPassword(ctx context.Context, in *UserCheckPasswordRequest, opts ...grpc.CallOption) (*UserCheckPasswordResponse, error)
+	// Login via external provider
+	//
+	// Maps verified identity of OpenID Connect provider to the local user and starts its session.
+	// Called by OIDC callback of the receiver, it is not exposed by the receiver itself
+	UserLoginExternal(ctx context.Context, in *UserLoginExternalRequest, opts ...grpc.CallOption) (*UserLoginExternalResponse, error)
+	// Get service info
+	//
+	// Returns full names of methods enabled and disabled by the service config
+	ServiceInfo(ctx context.Context, in *ServiceInfoRequest, opts ...grpc.CallOption) (*ServiceInfoResponse, error)
+	// Wait for user change
+	//
+	// Returns the user, if its version differs from the known one. Otherwise blocks until the user
+	// is changed or the deadline capped by the server, and returns not_modified
+	UserGetIfChanged(ctx context.Context, in *UserGetIfChangedRequest, opts ...grpc.CallOption) (*UserGetIfChangedResponse, error)
+	// Get changed users
+	//
+	// Streams users created or updated since the time for incremental syncs, deletions are
+	// streamed with include_deleted and go first. The first message carries next_since of the next sync
+	UserChanges(ctx context.Context, in *UserChangesRequest, opts ...grpc.CallOption) (User_UserChangesClient, error)
+	// Count users
+	//
+	// Returns the number of users of the tenant and of all users by counters maintained with writes,
+	// users are not scanned. Counters are recounted periodically, so drifted ones are corrected
+	UserCount(ctx context.Context, in *UserCountRequest, opts ...grpc.CallOption) (*UserCountResponse, error)
+	// Get own user
+	//
+	// Returns the user of the session token passed in "authorization" metadata, "Bearer " prefix
+	// is optional. Me methods address the session user only and never take a name
+	MeGet(ctx context.Context, in *MeGetRequest, opts ...grpc.CallOption) (*MeGetResponse, error)
+	// Update own profile
+	//
+	// Updates email and full name of the session user, password is changed by MeChangePassword
+	MeUpdate(ctx context.Context, in *MeUpdateRequest, opts ...grpc.CallOption) (*MeUpdateResponse, error)
+	// Delete own user
+	//
+	// Deletes the session user and ends the session
+	MeDelete(ctx context.Context, in *MeDeleteRequest, opts ...grpc.CallOption) (*MeDeleteResponse, error)
+	// Change own password
+	//
+	// Replaces the password of the session user, the old one must be passed even if it is expired.
+	// Failed attempts are limited as the ones of UserCheckPassword
+	MeChangePassword(ctx context.Context, in *MeChangePasswordRequest, opts ...grpc.CallOption) (*MeChangePasswordResponse, error)
 }
 
 type userClient struct {
@@ -87,6 +165,15 @@ func (c *userClient) UserDelete(ctx context.Context, in *UserDeleteRequest, opts
 	return out, nil
 }
 
+func (c *userClient) UserRename(ctx context.Context, in *UserRenameRequest, opts ...grpc.CallOption) (*UserRenameResponse, error) {
+	out := new(UserRenameResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserRename", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userClient) UserGet(ctx context.Context, in *UserGetRequest, opts ...grpc.CallOption) (*UserGetResponse, error) {
 	out := new(UserGetResponse)
 	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet", in, out, opts...)
@@ -96,6 +183,33 @@ func (c *userClient) UserGet(ctx context.Context, in *UserGetRequest, opts ...gr
 	return out, nil
 }
 
+func (c *userClient) UserDisable(ctx context.Context, in *UserDisableRequest, opts ...grpc.CallOption) (*UserDisableResponse, error) {
+	out := new(UserDisableResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserDisable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserEnable(ctx context.Context, in *UserEnableRequest, opts ...grpc.CallOption) (*UserEnableResponse, error) {
+	out := new(UserEnableResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserEnable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserGetById(ctx context.Context, in *UserGetByIdRequest, opts ...grpc.CallOption) (*UserGetByIdResponse, error) {
+	out := new(UserGetByIdResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGetById", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userClient) UserList(ctx context.Context, in *UserListRequest, opts ...grpc.CallOption) (*UserListResponse, error) {
 	out := new(UserListResponse)
 	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserList", in, out, opts...)
@@ -146,6 +260,193 @@ func (x *userUserAllListClient) Recv() (*UserAllListResponse, error) {
 	return m, nil
 }
 
+func (c *userClient) UserImport(ctx context.Context, opts ...grpc.CallOption) (User_UserImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &User_ServiceDesc.Streams[1], "/gitlab.ozon.dev.iTukaev.homework.api.User/UserImport", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userUserImportClient{stream}
+	return x, nil
+}
+
+type User_UserImportClient interface {
+	Send(*UserImportRequest) error
+	Recv() (*UserImportResponse, error)
+	grpc.ClientStream
+}
+
+type userUserImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *userUserImportClient) Send(m *UserImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userUserImportClient) Recv() (*UserImportResponse, error) {
+	m := new(UserImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userClient) UserAvatarUpload(ctx context.Context, opts ...grpc.CallOption) (User_UserAvatarUploadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &User_ServiceDesc.Streams[2], "/gitlab.ozon.dev.iTukaev.homework.api.User/UserAvatarUpload", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userUserAvatarUploadClient{stream}
+	return x, nil
+}
+
+type User_UserAvatarUploadClient interface {
+	Send(*UserAvatarUploadRequest) error
+	CloseAndRecv() (*UserAvatarUploadResponse, error)
+	grpc.ClientStream
+}
+
+type userUserAvatarUploadClient struct {
+	grpc.ClientStream
+}
+
+func (x *userUserAvatarUploadClient) Send(m *UserAvatarUploadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userUserAvatarUploadClient) CloseAndRecv() (*UserAvatarUploadResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UserAvatarUploadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userClient) UserAvatarGet(ctx context.Context, in *UserAvatarGetRequest, opts ...grpc.CallOption) (*UserAvatarGetResponse, error) {
+	out := new(UserAvatarGetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserAvatarGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserCheckPassword(ctx context.Context, in *UserCheckPasswordRequest, opts ...grpc.CallOption) (*UserCheckPasswordResponse, error) {
+	out := new(UserCheckPasswordResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserCheckPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserLoginExternal(ctx context.Context, in *UserLoginExternalRequest, opts ...grpc.CallOption) (*UserLoginExternalResponse, error) {
+	out := new(UserLoginExternalResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserLoginExternal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ServiceInfo(ctx context.Context, in *ServiceInfoRequest, opts ...grpc.CallOption) (*ServiceInfoResponse, error) {
+	out := new(ServiceInfoResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/ServiceInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserGetIfChanged(ctx context.Context, in *UserGetIfChangedRequest, opts ...grpc.CallOption) (*UserGetIfChangedResponse, error) {
+	out := new(UserGetIfChangedResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGetIfChanged", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UserChanges(ctx context.Context, in *UserChangesRequest, opts ...grpc.CallOption) (User_UserChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &User_ServiceDesc.Streams[3], "/gitlab.ozon.dev.iTukaev.homework.api.User/UserChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userUserChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type User_UserChangesClient interface {
+	Recv() (*UserChangesResponse, error)
+	grpc.ClientStream
+}
+
+type userUserChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *userUserChangesClient) Recv() (*UserChangesResponse, error) {
+	m := new(UserChangesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userClient) UserCount(ctx context.Context, in *UserCountRequest, opts ...grpc.CallOption) (*UserCountResponse, error) {
+	out := new(UserCountResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/UserCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) MeGet(ctx context.Context, in *MeGetRequest, opts ...grpc.CallOption) (*MeGetResponse, error) {
+	out := new(MeGetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/MeGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) MeUpdate(ctx context.Context, in *MeUpdateRequest, opts ...grpc.CallOption) (*MeUpdateResponse, error) {
+	out := new(MeUpdateResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/MeUpdate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) MeDelete(ctx context.Context, in *MeDeleteRequest, opts ...grpc.CallOption) (*MeDeleteResponse, error) {
+	out := new(MeDeleteResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/MeDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) MeChangePassword(ctx context.Context, in *MeChangePasswordRequest, opts ...grpc.CallOption) (*MeChangePasswordResponse, error) {
+	out := new(MeChangePasswordResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.User/MeChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServer is the server API for User service.
 // All implementations must embed UnimplementedUserServer
 // for forward compatibility
@@ -162,10 +463,26 @@ type UserServer interface {
 	//
 	// Delete user from DB and cache
 	UserDelete(context.Context, *UserDeleteRequest) (*UserDeleteResponse, error)
+	// Rename user
+	//
+	// Change user name in DB, move cached data and avatar to the new name
+	UserRename(context.Context, *UserRenameRequest) (*UserRenameResponse, error)
 	// Get user information
 	//
 	// Returns user information by user name
 	UserGet(context.Context, *UserGetRequest) (*UserGetResponse, error)
+	// Disable user
+	//
+	// Move active or pending user to disabled status, disabled user can't log in
+	UserDisable(context.Context, *UserDisableRequest) (*UserDisableResponse, error)
+	// Enable user
+	//
+	// Move disabled or pending user to active status
+	UserEnable(context.Context, *UserEnableRequest) (*UserEnableResponse, error)
+	// Get user information by ID
+	//
+	// Returns user information by immutable user ID
+	UserGetById(context.Context, *UserGetByIdRequest) (*UserGetByIdResponse, error)
 	// Get users list
 	//
 	// Returns all users from DB
@@ -178,6 +495,68 @@ type UserServer interface {
 	//
 	// Returns all users from DB
 	UserAllList(*UserAllListRequest, User_UserAllListServer) error
+	// Import users
+	//
+	// Users are imported one by one, conflict strategy is taken from the first message.
+	// Result of every row is streamed back in the order of rows. Atomic imports are applied
+	// after the last row is received, if every row passes the checks, and none is written otherwise
+	UserImport(User_UserImportServer) error
+	// Upload user avatar
+	//
+	// Client streaming upload, user name is taken from the first chunk.
+	// PNG, JPEG, GIF and WebP images are accepted
+	UserAvatarUpload(User_UserAvatarUploadServer) error
+	// Get user avatar
+	//
+	// Returns avatar image by user name
+	UserAvatarGet(context.Context, *UserAvatarGetRequest) (*UserAvatarGetResponse, error)
+	// Check user password
+	//
+	// Verifies password of the user for login integrations, failed attempts
+	// are limited per user. Unknown user is reported as invalid password
+	UserCheckPassword(context.Context, *UserCheckPasswordRequest) (*UserCheckPasswordResponse, error)
+	// Login via external provider
+	//
+	// Maps verified identity of OpenID Connect provider to the local user and starts its session.
+	// Called by OIDC callback of the receiver, it is not exposed by the receiver itself
+	UserLoginExternal(context.Context, *UserLoginExternalRequest) (*UserLoginExternalResponse, error)
+	// Get service info
+	//
+	// Returns full names of methods enabled and disabled by the service config
+	ServiceInfo(context.Context, *ServiceInfoRequest) (*ServiceInfoResponse, error)
+	// Wait for user change
+	//
+	// Returns the user, if its version differs from the known one. Otherwise blocks until the user
+	// is changed or the deadline capped by the server, and returns not_modified
+	UserGetIfChanged(context.Context, *UserGetIfChangedRequest) (*UserGetIfChangedResponse, error)
+	// Get changed users
+	//
+	// Streams users created or updated since the time for incremental syncs, deletions are
+	// streamed with include_deleted and go first. The first message carries next_since of the next sync
+	UserChanges(*UserChangesRequest, User_UserChangesServer) error
+	// Count users
+	//
+	// Returns the number of users of the tenant and of all users by counters maintained with writes,
+	// users are not scanned. Counters are recounted periodically, so drifted ones are corrected
+	UserCount(context.Context, *UserCountRequest) (*UserCountResponse, error)
+	// Get own user
+	//
+	// Returns the user of the session token passed in "authorization" metadata, "Bearer " prefix
+	// is optional. Me methods address the session user only and never take a name
+	MeGet(context.Context, *MeGetRequest) (*MeGetResponse, error)
+	// Update own profile
+	//
+	// Updates email and full name of the session user, password is changed by MeChangePassword
+	MeUpdate(context.Context, *MeUpdateRequest) (*MeUpdateResponse, error)
+	// Delete own user
+	//
+	// Deletes the session user and ends the session
+	MeDelete(context.Context, *MeDeleteRequest) (*MeDeleteResponse, error)
+	// Change own password
+	//
+	// Replaces the password of the session user, the old one must be passed even if it is expired.
+	// Failed attempts are limited as the ones of UserCheckPassword
+	MeChangePassword(context.Context, *MeChangePasswordRequest) (*MeChangePasswordResponse, error)
 	mustEmbedUnimplementedUserServer()
 }
 
@@ -194,9 +573,21 @@ func (UnimplementedUserServer) UserUpdate(context.Context, *UserUpdateRequest) (
 func (UnimplementedUserServer) UserDelete(context.Context, *UserDeleteRequest) (*UserDeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UserDelete not implemented")
 }
+func (UnimplementedUserServer) UserRename(context.Context, *UserRenameRequest) (*UserRenameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserRename not implemented")
+}
 func (UnimplementedUserServer) UserGet(context.Context, *UserGetRequest) (*UserGetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UserGet not implemented")
 }
+func (UnimplementedUserServer) UserDisable(context.Context, *UserDisableRequest) (*UserDisableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserDisable not implemented")
+}
+func (UnimplementedUserServer) UserEnable(context.Context, *UserEnableRequest) (*UserEnableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserEnable not implemented")
+}
+func (UnimplementedUserServer) UserGetById(context.Context, *UserGetByIdRequest) (*UserGetByIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserGetById not implemented")
+}
 func (UnimplementedUserServer) UserList(context.Context, *UserListRequest) (*UserListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UserList not implemented")
 }
@@ -206,6 +597,45 @@ func (UnimplementedUserServer) Data(context.Context, *DataRequest) (*DataRespons
 func (UnimplementedUserServer) UserAllList(*UserAllListRequest, User_UserAllListServer) error {
 	return status.Errorf(codes.Unimplemented, "method UserAllList not implemented")
 }
+func (UnimplementedUserServer) UserImport(User_UserImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method UserImport not implemented")
+}
+func (UnimplementedUserServer) UserAvatarUpload(User_UserAvatarUploadServer) error {
+	return status.Errorf(codes.Unimplemented, "method UserAvatarUpload not implemented")
+}
+func (UnimplementedUserServer) UserAvatarGet(context.Context, *UserAvatarGetRequest) (*UserAvatarGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserAvatarGet not implemented")
+}
+func (UnimplementedUserServer) UserCheckPassword(context.Context, *UserCheckPasswordRequest) (*UserCheckPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserCheckPassword not implemented")
+}
+func (UnimplementedUserServer) UserLoginExternal(context.Context, *UserLoginExternalRequest) (*UserLoginExternalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserLoginExternal not implemented")
+}
+func (UnimplementedUserServer) ServiceInfo(context.Context, *ServiceInfoRequest) (*ServiceInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ServiceInfo not implemented")
+}
+func (UnimplementedUserServer) UserGetIfChanged(context.Context, *UserGetIfChangedRequest) (*UserGetIfChangedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserGetIfChanged not implemented")
+}
+func (UnimplementedUserServer) UserChanges(*UserChangesRequest, User_UserChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method UserChanges not implemented")
+}
+func (UnimplementedUserServer) UserCount(context.Context, *UserCountRequest) (*UserCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserCount not implemented")
+}
+func (UnimplementedUserServer) MeGet(context.Context, *MeGetRequest) (*MeGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MeGet not implemented")
+}
+func (UnimplementedUserServer) MeUpdate(context.Context, *MeUpdateRequest) (*MeUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MeUpdate not implemented")
+}
+func (UnimplementedUserServer) MeDelete(context.Context, *MeDeleteRequest) (*MeDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MeDelete not implemented")
+}
+func (UnimplementedUserServer) MeChangePassword(context.Context, *MeChangePasswordRequest) (*MeChangePasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MeChangePassword not implemented")
+}
 func (UnimplementedUserServer) mustEmbedUnimplementedUserServer() {}
 
 // UnsafeUserServer may be embedded to opt out of forward compatibility for this service.
@@ -273,6 +703,24 @@ func _User_UserDelete_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _User_UserRename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserRename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserRename",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserRename(ctx, req.(*UserRenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _User_UserGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UserGetRequest)
 	if err := dec(in); err != nil {
@@ -291,6 +739,60 @@ func _User_UserGet_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _User_UserDisable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserDisableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserDisable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserDisable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserDisable(ctx, req.(*UserDisableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserEnable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserEnableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserEnable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserEnable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserEnable(ctx, req.(*UserEnableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserGetById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserGetByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserGetById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGetById",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserGetById(ctx, req.(*UserGetByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _User_UserList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UserListRequest)
 	if err := dec(in); err != nil {
@@ -348,8 +850,261 @@ func (x *userUserAllListServer) Send(m *UserAllListResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-// User_ServiceDesc is the grpc.ServiceDesc for User service.
-// It's only intended for direct use with grpc.RegisterService,
+func _User_UserImport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserServer).UserImport(&userUserImportServer{stream})
+}
+
+type User_UserImportServer interface {
+	Send(*UserImportResponse) error
+	Recv() (*UserImportRequest, error)
+	grpc.ServerStream
+}
+
+type userUserImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *userUserImportServer) Send(m *UserImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userUserImportServer) Recv() (*UserImportRequest, error) {
+	m := new(UserImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _User_UserAvatarUpload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserServer).UserAvatarUpload(&userUserAvatarUploadServer{stream})
+}
+
+type User_UserAvatarUploadServer interface {
+	SendAndClose(*UserAvatarUploadResponse) error
+	Recv() (*UserAvatarUploadRequest, error)
+	grpc.ServerStream
+}
+
+type userUserAvatarUploadServer struct {
+	grpc.ServerStream
+}
+
+func (x *userUserAvatarUploadServer) SendAndClose(m *UserAvatarUploadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userUserAvatarUploadServer) Recv() (*UserAvatarUploadRequest, error) {
+	m := new(UserAvatarUploadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _User_UserAvatarGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserAvatarGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserAvatarGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserAvatarGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserAvatarGet(ctx, req.(*UserAvatarGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserCheckPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserCheckPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserCheckPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserCheckPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserCheckPassword(ctx, req.(*UserCheckPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserLoginExternal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserLoginExternalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserLoginExternal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserLoginExternal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserLoginExternal(ctx, req.(*UserLoginExternalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ServiceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ServiceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/ServiceInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ServiceInfo(ctx, req.(*ServiceInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserGetIfChanged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserGetIfChangedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserGetIfChanged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGetIfChanged",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserGetIfChanged(ctx, req.(*UserGetIfChangedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_UserChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UserChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServer).UserChanges(m, &userUserChangesServer{stream})
+}
+
+type User_UserChangesServer interface {
+	Send(*UserChangesResponse) error
+	grpc.ServerStream
+}
+
+type userUserChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *userUserChangesServer) Send(m *UserChangesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _User_UserCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).UserCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/UserCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).UserCount(ctx, req.(*UserCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_MeGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MeGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).MeGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/MeGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).MeGet(ctx, req.(*MeGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_MeUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MeUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).MeUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/MeUpdate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).MeUpdate(ctx, req.(*MeUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_MeDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MeDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).MeDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/MeDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).MeDelete(ctx, req.(*MeDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_MeChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MeChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).MeChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.User/MeChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).MeChangePassword(ctx, req.(*MeChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// User_ServiceDesc is the grpc.ServiceDesc for User service.
+// It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var User_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "gitlab.ozon.dev.iTukaev.homework.api.User",
@@ -367,10 +1122,26 @@ var User_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UserDelete",
 			Handler:    _User_UserDelete_Handler,
 		},
+		{
+			MethodName: "UserRename",
+			Handler:    _User_UserRename_Handler,
+		},
 		{
 			MethodName: "UserGet",
 			Handler:    _User_UserGet_Handler,
 		},
+		{
+			MethodName: "UserDisable",
+			Handler:    _User_UserDisable_Handler,
+		},
+		{
+			MethodName: "UserEnable",
+			Handler:    _User_UserEnable_Handler,
+		},
+		{
+			MethodName: "UserGetById",
+			Handler:    _User_UserGetById_Handler,
+		},
 		{
 			MethodName: "UserList",
 			Handler:    _User_UserList_Handler,
@@ -379,6 +1150,46 @@ var User_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Data",
 			Handler:    _User_Data_Handler,
 		},
+		{
+			MethodName: "UserAvatarGet",
+			Handler:    _User_UserAvatarGet_Handler,
+		},
+		{
+			MethodName: "UserCheckPassword",
+			Handler:    _User_UserCheckPassword_Handler,
+		},
+		{
+			MethodName: "UserLoginExternal",
+			Handler:    _User_UserLoginExternal_Handler,
+		},
+		{
+			MethodName: "ServiceInfo",
+			Handler:    _User_ServiceInfo_Handler,
+		},
+		{
+			MethodName: "UserGetIfChanged",
+			Handler:    _User_UserGetIfChanged_Handler,
+		},
+		{
+			MethodName: "UserCount",
+			Handler:    _User_UserCount_Handler,
+		},
+		{
+			MethodName: "MeGet",
+			Handler:    _User_MeGet_Handler,
+		},
+		{
+			MethodName: "MeUpdate",
+			Handler:    _User_MeUpdate_Handler,
+		},
+		{
+			MethodName: "MeDelete",
+			Handler:    _User_MeDelete_Handler,
+		},
+		{
+			MethodName: "MeChangePassword",
+			Handler:    _User_MeChangePassword_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -386,6 +1197,1586 @@ var User_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _User_UserAllList_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "UserImport",
+			Handler:       _User_UserImport_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UserAvatarUpload",
+			Handler:       _User_UserAvatarUpload_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UserChanges",
+			Handler:       _User_UserChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	// Add denylist entry
+	//
+	// Adds reserved user name or pattern, entry is persisted in cache
+	DenylistAdd(ctx context.Context, in *DenylistAddRequest, opts ...grpc.CallOption) (*DenylistAddResponse, error)
+	// Remove denylist entry
+	//
+	// Removes runtime entry, entries from config cannot be removed
+	DenylistRemove(ctx context.Context, in *DenylistRemoveRequest, opts ...grpc.CallOption) (*DenylistRemoveResponse, error)
+	// Get denylist
+	//
+	// Returns static and runtime denylist entries
+	DenylistList(ctx context.Context, in *DenylistListRequest, opts ...grpc.CallOption) (*DenylistListResponse, error)
+	// Rebuild secondary indexes
+	//
+	// Starts background rebuild of the indexes from the primary store.
+	// With resume the last failed or interrupted job continues from its checkpoint
+	ReindexStart(ctx context.Context, in *ReindexStartRequest, opts ...grpc.CallOption) (*ReindexStartResponse, error)
+	// Get reindex progress
+	//
+	// Returns state of the last reindex job
+	ReindexStatus(ctx context.Context, in *ReindexStatusRequest, opts ...grpc.CallOption) (*ReindexStatusResponse, error)
+	// Force password rotation
+	//
+	// Expires passwords of the cohort: named users, users having all the attributes or all users.
+	// Their logins are rejected with reason PASSWORD_EXPIRED until the password is changed
+	PasswordExpire(ctx context.Context, in *PasswordExpireRequest, opts ...grpc.CallOption) (*PasswordExpireResponse, error)
+	// Create backup
+	//
+	// Writes gzip compressed consistent snapshot of all users. The backup is streamed to the client
+	// in chunks, or stored by the key, if store is set. The last message carries the summary
+	BackupCreate(ctx context.Context, in *BackupCreateRequest, opts ...grpc.CallOption) (Admin_BackupCreateClient, error)
+	// Restore backup
+	//
+	// Restores users from the backup streamed in chunks, or from the stored one, if the key is set.
+	// Options are taken from the first message. The whole backup is verified before users are written
+	BackupRestore(ctx context.Context, opts ...grpc.CallOption) (Admin_BackupRestoreClient, error)
+	// Get user state at the time
+	//
+	// Reconstructs the user from its history as it was at the time. With restore the state
+	// replaces the current user, deleted user is recreated. Password is never returned
+	UserStateAt(ctx context.Context, in *UserStateAtRequest, opts ...grpc.CallOption) (*UserStateAtResponse, error)
+	// List users at the time
+	//
+	// Returns a page of users as they were at the time by their history, users deleted at the time
+	// are not listed. Read only, requires the history enabled. Passwords are never returned
+	UserListAt(ctx context.Context, in *UserListAtRequest, opts ...grpc.CallOption) (*UserListAtResponse, error)
+	// Set maintenance mode
+	//
+	// Enables or disables read-only maintenance mode. Mutations are rejected with Unavailable
+	// and the reason while it is enabled, reads continue. The mode is persisted and survives restarts
+	MaintenanceSet(ctx context.Context, in *MaintenanceSetRequest, opts ...grpc.CallOption) (*MaintenanceSetResponse, error)
+	// Get maintenance mode
+	//
+	// Returns the current maintenance state
+	MaintenanceGet(ctx context.Context, in *MaintenanceGetRequest, opts ...grpc.CallOption) (*MaintenanceGetResponse, error)
+	// List impersonation sessions
+	//
+	// Returns callers, which acted as other identities with the act-as metadata, by the changes
+	// recorded in the user history. The latest sessions go first. Requires the history enabled
+	ImpersonationList(ctx context.Context, in *ImpersonationListRequest, opts ...grpc.CallOption) (*ImpersonationListResponse, error)
+	// Verify stored users
+	//
+	// Checks invariants of all users: required fields, known status, unique emails and bcrypt format
+	// of hashed passwords. With repair missing status is set to active and malformed passwords are expired
+	VerifyData(ctx context.Context, in *VerifyDataRequest, opts ...grpc.CallOption) (*VerifyDataResponse, error)
+	// Get cached user
+	//
+	// Returns the user as it is cached with the remaining time to live, the password is omitted
+	CacheGet(ctx context.Context, in *CacheGetRequest, opts ...grpc.CallOption) (*CacheGetResponse, error)
+	// Evict cached user
+	//
+	// Drops the cached user and cached lists, so the next reads go to the database
+	CacheEvict(ctx context.Context, in *CacheEvictRequest, opts ...grpc.CallOption) (*CacheEvictResponse, error)
+	// Cache statistics
+	//
+	// Returns hits and misses of this instance since its start and the size of the cache
+	CacheStats(ctx context.Context, in *CacheStatsRequest, opts ...grpc.CallOption) (*CacheStatsResponse, error)
+	// Export cache state
+	//
+	// Streams the bloom filter of user names of this instance in chunks, the last message carries
+	// the checksum. New replicas load it from a peer instead of scanning the database
+	CacheStateExport(ctx context.Context, in *CacheStateExportRequest, opts ...grpc.CallOption) (Admin_CacheStateExportClient, error)
+	// List anomaly alerts
+	//
+	// Returns unconfirmed alerts of suspicious mutation patterns detected by this instance:
+	// mass deletes, privileged role grants and creation bursts of one caller
+	AnomalyList(ctx context.Context, in *AnomalyListRequest, opts ...grpc.CallOption) (*AnomalyListResponse, error)
+	// Confirm anomaly alerts
+	//
+	// Clears the alerts of this instance. If the approval mode enabled maintenance on an alert,
+	// it is disabled, so mutations are accepted again
+	AnomalyConfirm(ctx context.Context, in *AnomalyConfirmRequest, opts ...grpc.CallOption) (*AnomalyConfirmResponse, error)
+	// List pending operations
+	//
+	// Returns deletes and mass updates beyond the thresholds of the approval config, which wait
+	// for a second admin. Operations are dropped, if nobody approves them within the TTL
+	OperationList(ctx context.Context, in *OperationListRequest, opts ...grpc.CallOption) (*OperationListResponse, error)
+	// Approve pending operation
+	//
+	// Runs the pending operation. The approver must differ from the caller, who requested it
+	OperationApprove(ctx context.Context, in *OperationApproveRequest, opts ...grpc.CallOption) (*OperationApproveResponse, error)
+	// List tenant overrides
+	//
+	// Returns config overrides of all tenants, the tenant of the user is its attribute "tenant"
+	TenantOverridesList(ctx context.Context, in *TenantOverridesListRequest, opts ...grpc.CallOption) (*TenantOverridesListResponse, error)
+	// Get tenant overrides
+	TenantOverridesGet(ctx context.Context, in *TenantOverridesGetRequest, opts ...grpc.CallOption) (*TenantOverridesGetResponse, error)
+	// Set tenant overrides
+	//
+	// Replaces the overrides of the tenant, unset fields keep the service config. Replicas apply
+	// the overrides within the refresh interval
+	TenantOverridesSet(ctx context.Context, in *TenantOverridesSetRequest, opts ...grpc.CallOption) (*TenantOverridesSetResponse, error)
+	// Delete tenant overrides
+	//
+	// Users of the tenant get the service config again
+	TenantOverridesDelete(ctx context.Context, in *TenantOverridesDeleteRequest, opts ...grpc.CallOption) (*TenantOverridesDeleteResponse, error)
+	// Report API usage of clients
+	//
+	// Returns calls and errors of every client within the period by method, the most active clients go first.
+	// Clients are told by the request meta. Counts are aggregated into the repository every usage interval,
+	// calls of this instance not aggregated yet are reported too
+	UsageReport(ctx context.Context, in *UsageReportRequest, opts ...grpc.CallOption) (*UsageReportResponse, error)
+	// Generate fake users
+	//
+	// Creates realistic fake users named with the prefix in batches, so demo environments and load tests
+	// are seeded. Users are validated as created ones and get the "generated" attribute. Requires
+	// the generator enabled
+	UserGenerate(ctx context.Context, in *UserGenerateRequest, opts ...grpc.CallOption) (*UserGenerateResponse, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) DenylistAdd(ctx context.Context, in *DenylistAddRequest, opts ...grpc.CallOption) (*DenylistAddResponse, error) {
+	out := new(DenylistAddResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistAdd", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DenylistRemove(ctx context.Context, in *DenylistRemoveRequest, opts ...grpc.CallOption) (*DenylistRemoveResponse, error) {
+	out := new(DenylistRemoveResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistRemove", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DenylistList(ctx context.Context, in *DenylistListRequest, opts ...grpc.CallOption) (*DenylistListResponse, error) {
+	out := new(DenylistListResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReindexStart(ctx context.Context, in *ReindexStartRequest, opts ...grpc.CallOption) (*ReindexStartResponse, error) {
+	out := new(ReindexStartResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ReindexStart", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReindexStatus(ctx context.Context, in *ReindexStatusRequest, opts ...grpc.CallOption) (*ReindexStatusResponse, error) {
+	out := new(ReindexStatusResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ReindexStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) PasswordExpire(ctx context.Context, in *PasswordExpireRequest, opts ...grpc.CallOption) (*PasswordExpireResponse, error) {
+	out := new(PasswordExpireResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/PasswordExpire", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) BackupCreate(ctx context.Context, in *BackupCreateRequest, opts ...grpc.CallOption) (Admin_BackupCreateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], "/gitlab.ozon.dev.iTukaev.homework.api.Admin/BackupCreate", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminBackupCreateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_BackupCreateClient interface {
+	Recv() (*BackupCreateResponse, error)
+	grpc.ClientStream
+}
+
+type adminBackupCreateClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminBackupCreateClient) Recv() (*BackupCreateResponse, error) {
+	m := new(BackupCreateResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) BackupRestore(ctx context.Context, opts ...grpc.CallOption) (Admin_BackupRestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[1], "/gitlab.ozon.dev.iTukaev.homework.api.Admin/BackupRestore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminBackupRestoreClient{stream}
+	return x, nil
+}
+
+type Admin_BackupRestoreClient interface {
+	Send(*BackupRestoreRequest) error
+	CloseAndRecv() (*BackupRestoreResponse, error)
+	grpc.ClientStream
+}
+
+type adminBackupRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminBackupRestoreClient) Send(m *BackupRestoreRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *adminBackupRestoreClient) CloseAndRecv() (*BackupRestoreResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BackupRestoreResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) UserStateAt(ctx context.Context, in *UserStateAtRequest, opts ...grpc.CallOption) (*UserStateAtResponse, error) {
+	out := new(UserStateAtResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserStateAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) UserListAt(ctx context.Context, in *UserListAtRequest, opts ...grpc.CallOption) (*UserListAtResponse, error) {
+	out := new(UserListAtResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserListAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) MaintenanceSet(ctx context.Context, in *MaintenanceSetRequest, opts ...grpc.CallOption) (*MaintenanceSetResponse, error) {
+	out := new(MaintenanceSetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/MaintenanceSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) MaintenanceGet(ctx context.Context, in *MaintenanceGetRequest, opts ...grpc.CallOption) (*MaintenanceGetResponse, error) {
+	out := new(MaintenanceGetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/MaintenanceGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ImpersonationList(ctx context.Context, in *ImpersonationListRequest, opts ...grpc.CallOption) (*ImpersonationListResponse, error) {
+	out := new(ImpersonationListResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ImpersonationList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) VerifyData(ctx context.Context, in *VerifyDataRequest, opts ...grpc.CallOption) (*VerifyDataResponse, error) {
+	out := new(VerifyDataResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/VerifyData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CacheGet(ctx context.Context, in *CacheGetRequest, opts ...grpc.CallOption) (*CacheGetResponse, error) {
+	out := new(CacheGetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CacheEvict(ctx context.Context, in *CacheEvictRequest, opts ...grpc.CallOption) (*CacheEvictResponse, error) {
+	out := new(CacheEvictResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheEvict", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CacheStats(ctx context.Context, in *CacheStatsRequest, opts ...grpc.CallOption) (*CacheStatsResponse, error) {
+	out := new(CacheStatsResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CacheStateExport(ctx context.Context, in *CacheStateExportRequest, opts ...grpc.CallOption) (Admin_CacheStateExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[2], "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheStateExport", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminCacheStateExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_CacheStateExportClient interface {
+	Recv() (*CacheStateExportResponse, error)
+	grpc.ClientStream
+}
+
+type adminCacheStateExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminCacheStateExportClient) Recv() (*CacheStateExportResponse, error) {
+	m := new(CacheStateExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) AnomalyList(ctx context.Context, in *AnomalyListRequest, opts ...grpc.CallOption) (*AnomalyListResponse, error) {
+	out := new(AnomalyListResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/AnomalyList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) AnomalyConfirm(ctx context.Context, in *AnomalyConfirmRequest, opts ...grpc.CallOption) (*AnomalyConfirmResponse, error) {
+	out := new(AnomalyConfirmResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/AnomalyConfirm", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) OperationList(ctx context.Context, in *OperationListRequest, opts ...grpc.CallOption) (*OperationListResponse, error) {
+	out := new(OperationListResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/OperationList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) OperationApprove(ctx context.Context, in *OperationApproveRequest, opts ...grpc.CallOption) (*OperationApproveResponse, error) {
+	out := new(OperationApproveResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/OperationApprove", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TenantOverridesList(ctx context.Context, in *TenantOverridesListRequest, opts ...grpc.CallOption) (*TenantOverridesListResponse, error) {
+	out := new(TenantOverridesListResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TenantOverridesGet(ctx context.Context, in *TenantOverridesGetRequest, opts ...grpc.CallOption) (*TenantOverridesGetResponse, error) {
+	out := new(TenantOverridesGetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TenantOverridesSet(ctx context.Context, in *TenantOverridesSetRequest, opts ...grpc.CallOption) (*TenantOverridesSetResponse, error) {
+	out := new(TenantOverridesSetResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TenantOverridesDelete(ctx context.Context, in *TenantOverridesDeleteRequest, opts ...grpc.CallOption) (*TenantOverridesDeleteResponse, error) {
+	out := new(TenantOverridesDeleteResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) UsageReport(ctx context.Context, in *UsageReportRequest, opts ...grpc.CallOption) (*UsageReportResponse, error) {
+	out := new(UsageReportResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UsageReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) UserGenerate(ctx context.Context, in *UserGenerateRequest, opts ...grpc.CallOption) (*UserGenerateResponse, error) {
+	out := new(UserGenerateResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserGenerate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+type AdminServer interface {
+	// Add denylist entry
+	//
+	// Adds reserved user name or pattern, entry is persisted in cache
+	DenylistAdd(context.Context, *DenylistAddRequest) (*DenylistAddResponse, error)
+	// Remove denylist entry
+	//
+	// Removes runtime entry, entries from config cannot be removed
+	DenylistRemove(context.Context, *DenylistRemoveRequest) (*DenylistRemoveResponse, error)
+	// Get denylist
+	//
+	// Returns static and runtime denylist entries
+	DenylistList(context.Context, *DenylistListRequest) (*DenylistListResponse, error)
+	// Rebuild secondary indexes
+	//
+	// Starts background rebuild of the indexes from the primary store.
+	// With resume the last failed or interrupted job continues from its checkpoint
+	ReindexStart(context.Context, *ReindexStartRequest) (*ReindexStartResponse, error)
+	// Get reindex progress
+	//
+	// Returns state of the last reindex job
+	ReindexStatus(context.Context, *ReindexStatusRequest) (*ReindexStatusResponse, error)
+	// Force password rotation
+	//
+	// Expires passwords of the cohort: named users, users having all the attributes or all users.
+	// Their logins are rejected with reason PASSWORD_EXPIRED until the password is changed
+	PasswordExpire(context.Context, *PasswordExpireRequest) (*PasswordExpireResponse, error)
+	// Create backup
+	//
+	// Writes gzip compressed consistent snapshot of all users. The backup is streamed to the client
+	// in chunks, or stored by the key, if store is set. The last message carries the summary
+	BackupCreate(*BackupCreateRequest, Admin_BackupCreateServer) error
+	// Restore backup
+	//
+	// Restores users from the backup streamed in chunks, or from the stored one, if the key is set.
+	// Options are taken from the first message. The whole backup is verified before users are written
+	BackupRestore(Admin_BackupRestoreServer) error
+	// Get user state at the time
+	//
+	// Reconstructs the user from its history as it was at the time. With restore the state
+	// replaces the current user, deleted user is recreated. Password is never returned
+	UserStateAt(context.Context, *UserStateAtRequest) (*UserStateAtResponse, error)
+	// List users at the time
+	//
+	// Returns a page of users as they were at the time by their history, users deleted at the time
+	// are not listed. Read only, requires the history enabled. Passwords are never returned
+	UserListAt(context.Context, *UserListAtRequest) (*UserListAtResponse, error)
+	// Set maintenance mode
+	//
+	// Enables or disables read-only maintenance mode. Mutations are rejected with Unavailable
+	// and the reason while it is enabled, reads continue. The mode is persisted and survives restarts
+	MaintenanceSet(context.Context, *MaintenanceSetRequest) (*MaintenanceSetResponse, error)
+	// Get maintenance mode
+	//
+	// Returns the current maintenance state
+	MaintenanceGet(context.Context, *MaintenanceGetRequest) (*MaintenanceGetResponse, error)
+	// List impersonation sessions
+	//
+	// Returns callers, which acted as other identities with the act-as metadata, by the changes
+	// recorded in the user history. The latest sessions go first. Requires the history enabled
+	ImpersonationList(context.Context, *ImpersonationListRequest) (*ImpersonationListResponse, error)
+	// Verify stored users
+	//
+	// Checks invariants of all users: required fields, known status, unique emails and bcrypt format
+	// of hashed passwords. With repair missing status is set to active and malformed passwords are expired
+	VerifyData(context.Context, *VerifyDataRequest) (*VerifyDataResponse, error)
+	// Get cached user
+	//
+	// Returns the user as it is cached with the remaining time to live, the password is omitted
+	CacheGet(context.Context, *CacheGetRequest) (*CacheGetResponse, error)
+	// Evict cached user
+	//
+	// Drops the cached user and cached lists, so the next reads go to the database
+	CacheEvict(context.Context, *CacheEvictRequest) (*CacheEvictResponse, error)
+	// Cache statistics
+	//
+	// Returns hits and misses of this instance since its start and the size of the cache
+	CacheStats(context.Context, *CacheStatsRequest) (*CacheStatsResponse, error)
+	// Export cache state
+	//
+	// Streams the bloom filter of user names of this instance in chunks, the last message carries
+	// the checksum. New replicas load it from a peer instead of scanning the database
+	CacheStateExport(*CacheStateExportRequest, Admin_CacheStateExportServer) error
+	// List anomaly alerts
+	//
+	// Returns unconfirmed alerts of suspicious mutation patterns detected by this instance:
+	// mass deletes, privileged role grants and creation bursts of one caller
+	AnomalyList(context.Context, *AnomalyListRequest) (*AnomalyListResponse, error)
+	// Confirm anomaly alerts
+	//
+	// Clears the alerts of this instance. If the approval mode enabled maintenance on an alert,
+	// it is disabled, so mutations are accepted again
+	AnomalyConfirm(context.Context, *AnomalyConfirmRequest) (*AnomalyConfirmResponse, error)
+	// List pending operations
+	//
+	// Returns deletes and mass updates beyond the thresholds of the approval config, which wait
+	// for a second admin. Operations are dropped, if nobody approves them within the TTL
+	OperationList(context.Context, *OperationListRequest) (*OperationListResponse, error)
+	// Approve pending operation
+	//
+	// Runs the pending operation. The approver must differ from the caller, who requested it
+	OperationApprove(context.Context, *OperationApproveRequest) (*OperationApproveResponse, error)
+	// List tenant overrides
+	//
+	// Returns config overrides of all tenants, the tenant of the user is its attribute "tenant"
+	TenantOverridesList(context.Context, *TenantOverridesListRequest) (*TenantOverridesListResponse, error)
+	// Get tenant overrides
+	TenantOverridesGet(context.Context, *TenantOverridesGetRequest) (*TenantOverridesGetResponse, error)
+	// Set tenant overrides
+	//
+	// Replaces the overrides of the tenant, unset fields keep the service config. Replicas apply
+	// the overrides within the refresh interval
+	TenantOverridesSet(context.Context, *TenantOverridesSetRequest) (*TenantOverridesSetResponse, error)
+	// Delete tenant overrides
+	//
+	// Users of the tenant get the service config again
+	TenantOverridesDelete(context.Context, *TenantOverridesDeleteRequest) (*TenantOverridesDeleteResponse, error)
+	// Report API usage of clients
+	//
+	// Returns calls and errors of every client within the period by method, the most active clients go first.
+	// Clients are told by the request meta. Counts are aggregated into the repository every usage interval,
+	// calls of this instance not aggregated yet are reported too
+	UsageReport(context.Context, *UsageReportRequest) (*UsageReportResponse, error)
+	// Generate fake users
+	//
+	// Creates realistic fake users named with the prefix in batches, so demo environments and load tests
+	// are seeded. Users are validated as created ones and get the "generated" attribute. Requires
+	// the generator enabled
+	UserGenerate(context.Context, *UserGenerateRequest) (*UserGenerateResponse, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) DenylistAdd(context.Context, *DenylistAddRequest) (*DenylistAddResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DenylistAdd not implemented")
+}
+func (UnimplementedAdminServer) DenylistRemove(context.Context, *DenylistRemoveRequest) (*DenylistRemoveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DenylistRemove not implemented")
+}
+func (UnimplementedAdminServer) DenylistList(context.Context, *DenylistListRequest) (*DenylistListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DenylistList not implemented")
+}
+func (UnimplementedAdminServer) ReindexStart(context.Context, *ReindexStartRequest) (*ReindexStartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReindexStart not implemented")
+}
+func (UnimplementedAdminServer) ReindexStatus(context.Context, *ReindexStatusRequest) (*ReindexStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReindexStatus not implemented")
+}
+func (UnimplementedAdminServer) PasswordExpire(context.Context, *PasswordExpireRequest) (*PasswordExpireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PasswordExpire not implemented")
+}
+func (UnimplementedAdminServer) BackupCreate(*BackupCreateRequest, Admin_BackupCreateServer) error {
+	return status.Errorf(codes.Unimplemented, "method BackupCreate not implemented")
+}
+func (UnimplementedAdminServer) BackupRestore(Admin_BackupRestoreServer) error {
+	return status.Errorf(codes.Unimplemented, "method BackupRestore not implemented")
+}
+func (UnimplementedAdminServer) UserStateAt(context.Context, *UserStateAtRequest) (*UserStateAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserStateAt not implemented")
+}
+func (UnimplementedAdminServer) UserListAt(context.Context, *UserListAtRequest) (*UserListAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserListAt not implemented")
+}
+func (UnimplementedAdminServer) MaintenanceSet(context.Context, *MaintenanceSetRequest) (*MaintenanceSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MaintenanceSet not implemented")
+}
+func (UnimplementedAdminServer) MaintenanceGet(context.Context, *MaintenanceGetRequest) (*MaintenanceGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MaintenanceGet not implemented")
+}
+func (UnimplementedAdminServer) ImpersonationList(context.Context, *ImpersonationListRequest) (*ImpersonationListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImpersonationList not implemented")
+}
+func (UnimplementedAdminServer) VerifyData(context.Context, *VerifyDataRequest) (*VerifyDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyData not implemented")
+}
+func (UnimplementedAdminServer) CacheGet(context.Context, *CacheGetRequest) (*CacheGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheGet not implemented")
+}
+func (UnimplementedAdminServer) CacheEvict(context.Context, *CacheEvictRequest) (*CacheEvictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheEvict not implemented")
+}
+func (UnimplementedAdminServer) CacheStats(context.Context, *CacheStatsRequest) (*CacheStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheStats not implemented")
+}
+func (UnimplementedAdminServer) CacheStateExport(*CacheStateExportRequest, Admin_CacheStateExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method CacheStateExport not implemented")
+}
+func (UnimplementedAdminServer) AnomalyList(context.Context, *AnomalyListRequest) (*AnomalyListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnomalyList not implemented")
+}
+func (UnimplementedAdminServer) AnomalyConfirm(context.Context, *AnomalyConfirmRequest) (*AnomalyConfirmResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnomalyConfirm not implemented")
+}
+func (UnimplementedAdminServer) OperationList(context.Context, *OperationListRequest) (*OperationListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OperationList not implemented")
+}
+func (UnimplementedAdminServer) OperationApprove(context.Context, *OperationApproveRequest) (*OperationApproveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OperationApprove not implemented")
+}
+func (UnimplementedAdminServer) TenantOverridesList(context.Context, *TenantOverridesListRequest) (*TenantOverridesListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TenantOverridesList not implemented")
+}
+func (UnimplementedAdminServer) TenantOverridesGet(context.Context, *TenantOverridesGetRequest) (*TenantOverridesGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TenantOverridesGet not implemented")
+}
+func (UnimplementedAdminServer) TenantOverridesSet(context.Context, *TenantOverridesSetRequest) (*TenantOverridesSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TenantOverridesSet not implemented")
+}
+func (UnimplementedAdminServer) TenantOverridesDelete(context.Context, *TenantOverridesDeleteRequest) (*TenantOverridesDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TenantOverridesDelete not implemented")
+}
+func (UnimplementedAdminServer) UsageReport(context.Context, *UsageReportRequest) (*UsageReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UsageReport not implemented")
+}
+func (UnimplementedAdminServer) UserGenerate(context.Context, *UserGenerateRequest) (*UserGenerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserGenerate not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_DenylistAdd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DenylistAddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DenylistAdd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistAdd",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DenylistAdd(ctx, req.(*DenylistAddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DenylistRemove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DenylistRemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DenylistRemove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistRemove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DenylistRemove(ctx, req.(*DenylistRemoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DenylistList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DenylistListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DenylistList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/DenylistList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DenylistList(ctx, req.(*DenylistListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReindexStart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexStartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReindexStart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ReindexStart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReindexStart(ctx, req.(*ReindexStartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReindexStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReindexStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ReindexStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReindexStatus(ctx, req.(*ReindexStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_PasswordExpire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordExpireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).PasswordExpire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/PasswordExpire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).PasswordExpire(ctx, req.(*PasswordExpireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_BackupCreate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupCreateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).BackupCreate(m, &adminBackupCreateServer{stream})
+}
+
+type Admin_BackupCreateServer interface {
+	Send(*BackupCreateResponse) error
+	grpc.ServerStream
+}
+
+type adminBackupCreateServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminBackupCreateServer) Send(m *BackupCreateResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Admin_BackupRestore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServer).BackupRestore(&adminBackupRestoreServer{stream})
+}
+
+type Admin_BackupRestoreServer interface {
+	SendAndClose(*BackupRestoreResponse) error
+	Recv() (*BackupRestoreRequest, error)
+	grpc.ServerStream
+}
+
+type adminBackupRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminBackupRestoreServer) SendAndClose(m *BackupRestoreResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *adminBackupRestoreServer) Recv() (*BackupRestoreRequest, error) {
+	m := new(BackupRestoreRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Admin_UserStateAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserStateAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UserStateAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserStateAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UserStateAt(ctx, req.(*UserStateAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_UserListAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserListAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UserListAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserListAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UserListAt(ctx, req.(*UserListAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_MaintenanceSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).MaintenanceSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/MaintenanceSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).MaintenanceSet(ctx, req.(*MaintenanceSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_MaintenanceGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).MaintenanceGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/MaintenanceGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).MaintenanceGet(ctx, req.(*MaintenanceGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ImpersonationList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImpersonationListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ImpersonationList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/ImpersonationList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ImpersonationList(ctx, req.(*ImpersonationListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_VerifyData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).VerifyData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/VerifyData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).VerifyData(ctx, req.(*VerifyDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CacheGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CacheGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CacheGet(ctx, req.(*CacheGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CacheEvict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheEvictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CacheEvict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheEvict",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CacheEvict(ctx, req.(*CacheEvictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/CacheStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CacheStats(ctx, req.(*CacheStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CacheStateExport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CacheStateExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).CacheStateExport(m, &adminCacheStateExportServer{stream})
+}
+
+type Admin_CacheStateExportServer interface {
+	Send(*CacheStateExportResponse) error
+	grpc.ServerStream
+}
+
+type adminCacheStateExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminCacheStateExportServer) Send(m *CacheStateExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Admin_AnomalyList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnomalyListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AnomalyList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/AnomalyList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AnomalyList(ctx, req.(*AnomalyListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_AnomalyConfirm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnomalyConfirmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AnomalyConfirm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/AnomalyConfirm",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AnomalyConfirm(ctx, req.(*AnomalyConfirmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_OperationList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).OperationList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/OperationList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).OperationList(ctx, req.(*OperationListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_OperationApprove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationApproveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).OperationApprove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/OperationApprove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).OperationApprove(ctx, req.(*OperationApproveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TenantOverridesList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TenantOverridesListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TenantOverridesList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TenantOverridesList(ctx, req.(*TenantOverridesListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TenantOverridesGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TenantOverridesGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TenantOverridesGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TenantOverridesGet(ctx, req.(*TenantOverridesGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TenantOverridesSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TenantOverridesSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TenantOverridesSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TenantOverridesSet(ctx, req.(*TenantOverridesSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TenantOverridesDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TenantOverridesDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TenantOverridesDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/TenantOverridesDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TenantOverridesDelete(ctx, req.(*TenantOverridesDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_UsageReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UsageReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UsageReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UsageReport(ctx, req.(*UsageReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_UserGenerate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserGenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UserGenerate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Admin/UserGenerate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UserGenerate(ctx, req.(*UserGenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitlab.ozon.dev.iTukaev.homework.api.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DenylistAdd",
+			Handler:    _Admin_DenylistAdd_Handler,
+		},
+		{
+			MethodName: "DenylistRemove",
+			Handler:    _Admin_DenylistRemove_Handler,
+		},
+		{
+			MethodName: "DenylistList",
+			Handler:    _Admin_DenylistList_Handler,
+		},
+		{
+			MethodName: "ReindexStart",
+			Handler:    _Admin_ReindexStart_Handler,
+		},
+		{
+			MethodName: "ReindexStatus",
+			Handler:    _Admin_ReindexStatus_Handler,
+		},
+		{
+			MethodName: "PasswordExpire",
+			Handler:    _Admin_PasswordExpire_Handler,
+		},
+		{
+			MethodName: "UserStateAt",
+			Handler:    _Admin_UserStateAt_Handler,
+		},
+		{
+			MethodName: "UserListAt",
+			Handler:    _Admin_UserListAt_Handler,
+		},
+		{
+			MethodName: "MaintenanceSet",
+			Handler:    _Admin_MaintenanceSet_Handler,
+		},
+		{
+			MethodName: "MaintenanceGet",
+			Handler:    _Admin_MaintenanceGet_Handler,
+		},
+		{
+			MethodName: "ImpersonationList",
+			Handler:    _Admin_ImpersonationList_Handler,
+		},
+		{
+			MethodName: "VerifyData",
+			Handler:    _Admin_VerifyData_Handler,
+		},
+		{
+			MethodName: "CacheGet",
+			Handler:    _Admin_CacheGet_Handler,
+		},
+		{
+			MethodName: "CacheEvict",
+			Handler:    _Admin_CacheEvict_Handler,
+		},
+		{
+			MethodName: "CacheStats",
+			Handler:    _Admin_CacheStats_Handler,
+		},
+		{
+			MethodName: "AnomalyList",
+			Handler:    _Admin_AnomalyList_Handler,
+		},
+		{
+			MethodName: "AnomalyConfirm",
+			Handler:    _Admin_AnomalyConfirm_Handler,
+		},
+		{
+			MethodName: "OperationList",
+			Handler:    _Admin_OperationList_Handler,
+		},
+		{
+			MethodName: "OperationApprove",
+			Handler:    _Admin_OperationApprove_Handler,
+		},
+		{
+			MethodName: "TenantOverridesList",
+			Handler:    _Admin_TenantOverridesList_Handler,
+		},
+		{
+			MethodName: "TenantOverridesGet",
+			Handler:    _Admin_TenantOverridesGet_Handler,
+		},
+		{
+			MethodName: "TenantOverridesSet",
+			Handler:    _Admin_TenantOverridesSet_Handler,
+		},
+		{
+			MethodName: "TenantOverridesDelete",
+			Handler:    _Admin_TenantOverridesDelete_Handler,
+		},
+		{
+			MethodName: "UsageReport",
+			Handler:    _Admin_UsageReport_Handler,
+		},
+		{
+			MethodName: "UserGenerate",
+			Handler:    _Admin_UserGenerate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BackupCreate",
+			Handler:       _Admin_BackupCreate_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BackupRestore",
+			Handler:       _Admin_BackupRestore_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CacheStateExport",
+			Handler:       _Admin_CacheStateExport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+// ReplicaClient is the client API for Replica service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReplicaClient interface {
+	// Apply replicated mutation
+	//
+	// Applies the mutation made in the active region. Mutations are idempotent, so they can be
+	// repeated after a failure. Served by data services of passive regions
+	ReplicaApply(ctx context.Context, in *ReplicaApplyRequest, opts ...grpc.CallOption) (*ReplicaApplyResponse, error)
+	// Catch up with the active region
+	//
+	// Writes users of the streamed snapshot of the active region as is and deletes users
+	// missing in it. Used, when mutations were lost, e.g. the region was unavailable too long
+	ReplicaCatchUp(ctx context.Context, opts ...grpc.CallOption) (Replica_ReplicaCatchUpClient, error)
+	// Get replication conflicts
+	//
+	// Returns the latest replicated writes, which lost to the stored ones written later by
+	// the hybrid logical clock. The rejected states are not kept
+	ReplicaConflicts(ctx context.Context, in *ReplicaConflictsRequest, opts ...grpc.CallOption) (*ReplicaConflictsResponse, error)
+}
+
+type replicaClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReplicaClient(cc grpc.ClientConnInterface) ReplicaClient {
+	return &replicaClient{cc}
+}
+
+func (c *replicaClient) ReplicaApply(ctx context.Context, in *ReplicaApplyRequest, opts ...grpc.CallOption) (*ReplicaApplyResponse, error) {
+	out := new(ReplicaApplyResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Replica/ReplicaApply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicaClient) ReplicaCatchUp(ctx context.Context, opts ...grpc.CallOption) (Replica_ReplicaCatchUpClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Replica_ServiceDesc.Streams[0], "/gitlab.ozon.dev.iTukaev.homework.api.Replica/ReplicaCatchUp", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &replicaReplicaCatchUpClient{stream}
+	return x, nil
+}
+
+type Replica_ReplicaCatchUpClient interface {
+	Send(*ReplicaCatchUpRequest) error
+	CloseAndRecv() (*ReplicaCatchUpResponse, error)
+	grpc.ClientStream
+}
+
+type replicaReplicaCatchUpClient struct {
+	grpc.ClientStream
+}
+
+func (x *replicaReplicaCatchUpClient) Send(m *ReplicaCatchUpRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *replicaReplicaCatchUpClient) CloseAndRecv() (*ReplicaCatchUpResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ReplicaCatchUpResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *replicaClient) ReplicaConflicts(ctx context.Context, in *ReplicaConflictsRequest, opts ...grpc.CallOption) (*ReplicaConflictsResponse, error) {
+	out := new(ReplicaConflictsResponse)
+	err := c.cc.Invoke(ctx, "/gitlab.ozon.dev.iTukaev.homework.api.Replica/ReplicaConflicts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplicaServer is the server API for Replica service.
+// All implementations must embed UnimplementedReplicaServer
+// for forward compatibility
+type ReplicaServer interface {
+	// Apply replicated mutation
+	//
+	// Applies the mutation made in the active region. Mutations are idempotent, so they can be
+	// repeated after a failure. Served by data services of passive regions
+	ReplicaApply(context.Context, *ReplicaApplyRequest) (*ReplicaApplyResponse, error)
+	// Catch up with the active region
+	//
+	// Writes users of the streamed snapshot of the active region as is and deletes users
+	// missing in it. Used, when mutations were lost, e.g. the region was unavailable too long
+	ReplicaCatchUp(Replica_ReplicaCatchUpServer) error
+	// Get replication conflicts
+	//
+	// Returns the latest replicated writes, which lost to the stored ones written later by
+	// the hybrid logical clock. The rejected states are not kept
+	ReplicaConflicts(context.Context, *ReplicaConflictsRequest) (*ReplicaConflictsResponse, error)
+	mustEmbedUnimplementedReplicaServer()
+}
+
+// UnimplementedReplicaServer must be embedded to have forward compatible implementations.
+type UnimplementedReplicaServer struct {
+}
+
+func (UnimplementedReplicaServer) ReplicaApply(context.Context, *ReplicaApplyRequest) (*ReplicaApplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicaApply not implemented")
+}
+func (UnimplementedReplicaServer) ReplicaCatchUp(Replica_ReplicaCatchUpServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReplicaCatchUp not implemented")
+}
+func (UnimplementedReplicaServer) ReplicaConflicts(context.Context, *ReplicaConflictsRequest) (*ReplicaConflictsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicaConflicts not implemented")
+}
+func (UnimplementedReplicaServer) mustEmbedUnimplementedReplicaServer() {}
+
+// UnsafeReplicaServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReplicaServer will
+// result in compilation errors.
+type UnsafeReplicaServer interface {
+	mustEmbedUnimplementedReplicaServer()
+}
+
+func RegisterReplicaServer(s grpc.ServiceRegistrar, srv ReplicaServer) {
+	s.RegisterService(&Replica_ServiceDesc, srv)
+}
+
+func _Replica_ReplicaApply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicaApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicaServer).ReplicaApply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Replica/ReplicaApply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicaServer).ReplicaApply(ctx, req.(*ReplicaApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Replica_ReplicaCatchUp_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ReplicaServer).ReplicaCatchUp(&replicaReplicaCatchUpServer{stream})
+}
+
+type Replica_ReplicaCatchUpServer interface {
+	SendAndClose(*ReplicaCatchUpResponse) error
+	Recv() (*ReplicaCatchUpRequest, error)
+	grpc.ServerStream
+}
+
+type replicaReplicaCatchUpServer struct {
+	grpc.ServerStream
+}
+
+func (x *replicaReplicaCatchUpServer) SendAndClose(m *ReplicaCatchUpResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *replicaReplicaCatchUpServer) Recv() (*ReplicaCatchUpRequest, error) {
+	m := new(ReplicaCatchUpRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Replica_ReplicaConflicts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicaConflictsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicaServer).ReplicaConflicts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitlab.ozon.dev.iTukaev.homework.api.Replica/ReplicaConflicts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicaServer).ReplicaConflicts(ctx, req.(*ReplicaConflictsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Replica_ServiceDesc is the grpc.ServiceDesc for Replica service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Replica_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitlab.ozon.dev.iTukaev.homework.api.Replica",
+	HandlerType: (*ReplicaServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReplicaApply",
+			Handler:    _Replica_ReplicaApply_Handler,
+		},
+		{
+			MethodName: "ReplicaConflicts",
+			Handler:    _Replica_ReplicaConflicts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReplicaCatchUp",
+			Handler:       _Replica_ReplicaCatchUp_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "api.proto",
 }
@@ -0,0 +1,54 @@
+package validate
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is a violated rule of the field, the field is a path of names from the request, e.g. user.name.
+type Error struct {
+	Field  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "field: [" + e.Field + "] " + e.Reason
+}
+
+// Validator is a message with the generated Validate method.
+type Validator interface {
+	Validate() error
+}
+
+// Nested validates the message of the field and prefixes fields of its errors with the field name.
+func Nested(field string, message Validator) error {
+	err := message.Validate()
+	var violation *Error
+	if errors.As(err, &violation) {
+		return &Error{Field: field + "." + violation.Field, Reason: violation.Reason}
+	}
+	return err
+}
+
+// Status returns InvalidArgument status of the validation error with the field violation
+// in BadRequest details.
+func Status(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	var violation *Error
+	if !errors.As(err, &violation) {
+		return st.Err()
+	}
+	detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       violation.Field,
+			Description: violation.Reason,
+		}},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
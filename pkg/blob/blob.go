@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// Storage keeps small binary objects by key.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (Object, []byte, error)
+	// Stat returns object info or ErrNotFound.
+	Stat(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Streamer is implemented by storages, which put and get objects as streams,
+// so large objects, e.g. backups, are not held in memory.
+type Streamer interface {
+	// PutStream writes size bytes of r as the object.
+	PutStream(ctx context.Context, key, contentType string, r io.Reader, size int64) error
+	// GetStream returns the object reader or ErrNotFound, the reader must be closed.
+	GetStream(ctx context.Context, key string) (Object, io.ReadCloser, error)
+}
@@ -0,0 +1,116 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+)
+
+type Config struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// New returns storage, which keeps objects as files in the directory.
+func New(cfg Config) (blob.Storage, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "create storage dir")
+	}
+	return &storage{
+		dir: cfg.Dir,
+	}, nil
+}
+
+type storage struct {
+	dir string
+}
+
+func (s *storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	return s.PutStream(ctx, key, contentType, bytes.NewReader(data), int64(len(data)))
+}
+
+func (s *storage) PutStream(_ context.Context, key, _ string, r io.Reader, size int64) error {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = io.CopyN(tmp, r, size); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "write temp file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "close temp file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), s.path(key)), "rename temp file")
+}
+
+func (s *storage) Get(_ context.Context, key string) (blob.Object, []byte, error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return blob.Object{}, nil, errors.Wrapf(blob.ErrNotFound, "key: [%s]", key)
+		}
+		return blob.Object{}, nil, errors.Wrap(err, "read file")
+	}
+	return blob.Object{
+		Key:         key,
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+	}, data, nil
+}
+
+func (s *storage) GetStream(_ context.Context, key string) (blob.Object, io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return blob.Object{}, nil, errors.Wrapf(blob.ErrNotFound, "key: [%s]", key)
+		}
+		return blob.Object{}, nil, errors.Wrap(err, "open file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return blob.Object{}, nil, errors.Wrap(err, "stat file")
+	}
+	return blob.Object{
+		Key:  key,
+		Size: info.Size(),
+	}, file, nil
+}
+
+func (s *storage) Stat(_ context.Context, key string) (blob.Object, error) {
+	info, err := os.Stat(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return blob.Object{}, errors.Wrapf(blob.ErrNotFound, "key: [%s]", key)
+		}
+		return blob.Object{}, errors.Wrap(err, "stat file")
+	}
+	return blob.Object{
+		Key:  key,
+		Size: info.Size(),
+	}, nil
+}
+
+func (s *storage) Delete(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "remove file")
+	}
+	return nil
+}
+
+// path encodes the key, so it can't escape the storage directory.
+func (s *storage) path(key string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(key)))
+}
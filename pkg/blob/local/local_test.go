@@ -0,0 +1,86 @@
+package local
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	storage, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	cases := []struct {
+		name    string
+		key     string
+		put     bool
+		expType string
+		expErr  error
+	}{
+		{
+			name:    "success",
+			key:     "Ivan",
+			put:     true,
+			expType: "image/png",
+			expErr:  nil,
+		},
+		{
+			name:    "success, key with path separators",
+			key:     "../../etc/passwd",
+			put:     true,
+			expType: "image/png",
+			expErr:  nil,
+		},
+		{
+			name:   "failed, not found",
+			key:    "Boris",
+			put:    false,
+			expErr: blob.ErrNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.put {
+				require.NoError(t, storage.Put(ctx, c.key, c.expType, png))
+			}
+
+			obj, data, err := storage.Get(ctx, c.key)
+			assert.ErrorIs(t, err, c.expErr)
+			_, err = storage.Stat(ctx, c.key)
+			assert.ErrorIs(t, err, c.expErr)
+			if c.expErr == nil {
+				assert.Equal(t, png, data)
+				assert.Equal(t, c.expType, obj.ContentType)
+			}
+		})
+	}
+}
+
+func TestStorage_Stream(t *testing.T) {
+	ctx := context.Background()
+	storage, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	streamer := storage.(blob.Streamer)
+
+	require.NoError(t, streamer.PutStream(ctx, "backup", "application/gzip", strings.NewReader("gzip"), 4))
+	obj, body, err := streamer.GetStream(ctx, "backup")
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("gzip"), data)
+	assert.Equal(t, int64(4), obj.Size)
+
+	assert.Error(t, streamer.PutStream(ctx, "short", "application/gzip", strings.NewReader("gz"), 4))
+	_, _, err = streamer.GetStream(ctx, "short")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
@@ -0,0 +1,256 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+)
+
+const (
+	algorithm   = "AWS4-HMAC-SHA256"
+	service     = "s3"
+	timeFormat  = "20060102T150405Z"
+	dateFormat  = "20060102"
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// unsignedPayload is signed instead of the hash of streamed bodies, which are read once
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// Config of S3 compatible storage, e.g. MinIO. Path-style addressing is used.
+type Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+}
+
+func New(cfg Config) blob.Storage {
+	scheme := "http"
+	if cfg.UseSSL {
+		scheme = "https"
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &storage{
+		cfg:    cfg,
+		scheme: scheme,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+}
+
+type storage struct {
+	cfg    Config
+	scheme string
+	client *http.Client
+	now    func() time.Time
+}
+
+func (s *storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	req, err := s.request(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	return s.put(req, contentType)
+}
+
+func (s *storage) PutStream(ctx context.Context, key, contentType string, r io.Reader, size int64) error {
+	req, err := s.signedRequest(ctx, http.MethodPut, key, io.LimitReader(r, size), size, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	return s.put(req, contentType)
+}
+
+func (s *storage) put(req *http.Request, contentType string) error {
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "s3 put")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (s *storage) Get(ctx context.Context, key string) (blob.Object, []byte, error) {
+	obj, body, err := s.GetStream(ctx, key)
+	if err != nil {
+		return blob.Object{}, nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return blob.Object{}, nil, errors.Wrap(err, "s3 get: read body")
+	}
+	obj.Size = int64(len(data))
+	return obj, data, nil
+}
+
+func (s *storage) GetStream(ctx context.Context, key string) (blob.Object, io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return blob.Object{}, nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return blob.Object{}, nil, errors.Wrap(err, "s3 get")
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return blob.Object{}, nil, errors.Wrapf(blob.ErrNotFound, "key: [%s]", key)
+		}
+		return blob.Object{}, nil, responseError(resp)
+	}
+	return blob.Object{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}, resp.Body, nil
+}
+
+func (s *storage) Stat(ctx context.Context, key string) (blob.Object, error) {
+	req, err := s.request(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return blob.Object{}, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return blob.Object{}, errors.Wrap(err, "s3 head")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return blob.Object{}, errors.Wrapf(blob.ErrNotFound, "key: [%s]", key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return blob.Object{}, responseError(resp)
+	}
+	return blob.Object{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}, nil
+}
+
+func (s *storage) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "s3 delete")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// request returns object request signed with AWS Signature Version 4.
+func (s *storage) request(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	payloadHash := emptySHA256
+	if len(body) != 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	return s.signedRequest(ctx, method, key, bytes.NewReader(body), int64(len(body)), payloadHash)
+}
+
+// signedRequest returns object request of the body with the payload hash signed.
+func (s *storage) signedRequest(ctx context.Context, method, key string, body io.Reader, size int64, payloadHash string) (*http.Request, error) {
+	u := url.URL{
+		Scheme: s.scheme,
+		Host:   s.cfg.Endpoint,
+		Path:   "/" + s.cfg.Bucket + "/" + key,
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	req.ContentLength = size
+
+	now := s.now().UTC()
+	amzDate := now.Format(timeFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), s.cfg.Region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format(dateFormat))
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.cfg.AccessKey, scope, signedHeaders, signature))
+	return req, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return errors.Errorf("s3 response: [%s] %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,78 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/blob"
+)
+
+func TestStorage(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), algorithm+" Credential=key/20220820/us-east-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+		case http.MethodGet, http.MethodHead:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	storage := New(Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:    "avatars",
+		AccessKey: "key",
+		SecretKey: "secret",
+	}).(*storage)
+	storage.now = func() time.Time {
+		return time.Date(2022, 8, 20, 12, 0, 0, 0, time.UTC)
+	}
+	ctx := context.Background()
+
+	require.NoError(t, storage.Put(ctx, "Ivan", "image/png", []byte("png")))
+
+	obj, data, err := storage.Get(ctx, "Ivan")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("png"), data)
+	assert.Equal(t, "image/png", obj.ContentType)
+
+	_, err = storage.Stat(ctx, "Boris")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+
+	require.NoError(t, storage.PutStream(ctx, "backup", "application/gzip", strings.NewReader("gzip"), 4))
+	obj, body, err := storage.GetStream(ctx, "backup")
+	require.NoError(t, err)
+	data, err = io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, []byte("gzip"), data)
+	assert.Equal(t, int64(4), obj.Size)
+
+	_, _, err = storage.GetStream(ctx, "Boris")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
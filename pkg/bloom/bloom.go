@@ -0,0 +1,126 @@
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// ErrMismatch is returned, if filters of different size or number of hashes are merged.
+var ErrMismatch = errors.New("filters mismatch")
+
+// Filter is a thread safe bloom filter for strings.
+type Filter struct {
+	mu     sync.RWMutex
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// New creates filter sized for expected number of items with false positive probability fp.
+func New(expected uint64, fp float64) *Filter {
+	if expected == 0 {
+		expected = 1
+	}
+	if fp <= 0 || fp >= 1 {
+		fp = 0.01
+	}
+	size := uint64(math.Ceil(-float64(expected) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Round(float64(size) / float64(expected) * math.Ln2))
+	if hashes == 0 {
+		hashes = 1
+	}
+	return &Filter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+func (f *Filter) Add(key string) {
+	h1, h2 := hash(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := uint64(0); i < f.hashes; i++ {
+		pos := (h1 + i*h2) % f.size
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// Test returns false if key was definitely never added.
+func (f *Filter) Test(key string) bool {
+	h1, h2 := hash(key)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for i := uint64(0); i < f.hashes; i++ {
+		pos := (h1 + i*h2) % f.size
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// State is a copy of the filter, filters are transferred by their states.
+type State struct {
+	Size   uint64
+	Hashes uint64
+	Bits   []uint64
+}
+
+// State returns a copy of the filter.
+func (f *Filter) State() State {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return State{
+		Size:   f.size,
+		Hashes: f.hashes,
+		Bits:   append([]uint64(nil), f.bits...),
+	}
+}
+
+// Checksum returns SHA-256 of the state in hex.
+func (s State) Checksum() string {
+	h := sha256.New()
+	buf := make([]byte, 8)
+	for _, word := range append([]uint64{s.Size, s.Hashes}, s.Bits...) {
+		binary.LittleEndian.PutUint64(buf, word)
+		_, _ = h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FromState returns the filter of the state, the state must not be changed then.
+func FromState(s State) (*Filter, error) {
+	if s.Size == 0 || s.Hashes == 0 || uint64(len(s.Bits)) != (s.Size+63)/64 {
+		return nil, errors.New("malformed filter state")
+	}
+	return &Filter{bits: s.Bits, size: s.Size, hashes: s.Hashes}, nil
+}
+
+// Union adds keys of the other filter, it must have the same size and number of hashes.
+func (f *Filter) Union(other *Filter) error {
+	state := other.State()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if state.Size != f.size || state.Hashes != f.hashes {
+		return ErrMismatch
+	}
+	for i, word := range state.Bits {
+		f.bits[i] |= word
+	}
+	return nil
+}
+
+func hash(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum & math.MaxUint32, sum>>32 | 1
+}
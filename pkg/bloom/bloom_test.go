@@ -0,0 +1,58 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("user_%d", i))
+	}
+
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Test(fmt.Sprintf("user_%d", i)))
+	}
+
+	falsePositive := 0
+	for i := 1000; i < 11000; i++ {
+		if f.Test(fmt.Sprintf("user_%d", i)) {
+			falsePositive++
+		}
+	}
+	assert.Less(t, falsePositive, 300)
+}
+
+func TestFilter_State(t *testing.T) {
+	f := New(100, 0.01)
+	f.Add("ivan")
+
+	state := f.State()
+	restored, err := FromState(state)
+	assert.NoError(t, err)
+	assert.True(t, restored.Test("ivan"))
+	assert.Equal(t, state.Checksum(), restored.State().Checksum())
+
+	// the state is a copy
+	f.Add("petr")
+	assert.NotEqual(t, state.Checksum(), f.State().Checksum())
+	assert.False(t, restored.Test("petr"))
+
+	_, err = FromState(State{Size: state.Size, Hashes: state.Hashes, Bits: state.Bits[1:]})
+	assert.Error(t, err)
+}
+
+func TestFilter_Union(t *testing.T) {
+	f, other := New(100, 0.01), New(100, 0.01)
+	f.Add("ivan")
+	other.Add("petr")
+
+	assert.NoError(t, f.Union(other))
+	assert.True(t, f.Test("ivan"))
+	assert.True(t, f.Test("petr"))
+
+	assert.ErrorIs(t, f.Union(New(1000, 0.01)), ErrMismatch)
+}
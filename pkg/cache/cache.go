@@ -0,0 +1,353 @@
+// Package cache is an in-memory typed cache of entries with optional TTL, limits of entries
+// and memory, pluggable eviction policy and metrics. The user repository of the local storage
+// is built on it, caches of sessions, tokens and tenant configs may reuse it.
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+// compactThreshold is a minimal number of deletions, after which the map can be compacted.
+const compactThreshold = 1024
+
+// Reason why the entry is removed.
+type Reason string
+
+const (
+	ReasonDeleted  Reason = "deleted"
+	ReasonExpired  Reason = "expired"
+	ReasonCapacity Reason = "capacity"
+)
+
+// Options of the cache, zero values mean no limits and no expiration.
+type Options[K comparable, V any] struct {
+	// TTL of the entries set by Set, zero means entries never expire.
+	TTL time.Duration
+	// MaxEntries and MaxSize limit the cache, victims of the policy are evicted by writes over them.
+	MaxEntries int
+	MaxSize    int64
+	// Policy picks victims of the limits, LRU is used by default.
+	Policy Policy[K]
+	// Sizer estimates memory of the entry, entries have zero size by default.
+	Sizer func(key K, value V) int64
+	// OnEvict is called for entries removed by expiration or limits under the write lock,
+	// so it must not call the cache. Deleted entries are not passed.
+	OnEvict func(key K, value V, reason Reason)
+	Metrics Metrics
+	// Clock of expiration, the system one by default.
+	Clock clock.Clock
+}
+
+type entry[V any] struct {
+	value V
+	size  int64
+	// expires is unix time in nanoseconds, zero means never
+	expires int64
+}
+
+// Cache is safe for concurrent use. Writes of Update are atomic with each other.
+type Cache[K comparable, V any] struct {
+	mu   sync.RWMutex
+	opts Options[K, V]
+	data map[K]entry[V]
+	// size is an estimated memory used by entries
+	size int64
+	// deleted is a number of deletions since the map was allocated, go maps never shrink
+	deleted int
+	// shared data is read by snapshots, it is copied by the next write
+	shared bool
+}
+
+// New returns the empty cache.
+func New[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	if opts.Policy == nil && (opts.MaxEntries > 0 || opts.MaxSize > 0) {
+		opts.Policy = NewLRU[K]()
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = NopMetrics{}
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real()
+	}
+	return &Cache[K, V]{
+		opts: opts,
+		data: make(map[K]entry[V]),
+	}
+}
+
+// Get returns the value of the key, expired entries are missing.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	value, ok := c.get(key)
+	c.opts.Metrics.Lookup(ok)
+	return value, ok
+}
+
+// Set stores the value with the default TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.Update(func(tx *Tx[K, V]) { tx.Set(key, value) })
+}
+
+// SetTTL stores the value, which expires after ttl, zero ttl means never.
+func (c *Cache[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	c.Update(func(tx *Tx[K, V]) { tx.SetTTL(key, value, ttl) })
+}
+
+// Delete removes the key and reports whether it was stored.
+func (c *Cache[K, V]) Delete(key K) bool {
+	var ok bool
+	c.Update(func(tx *Tx[K, V]) { ok = tx.Delete(key) })
+	return ok
+}
+
+// Len returns the number of entries including expired ones not removed yet.
+func (c *Cache[K, V]) Len() int {
+	c.rlock()
+	defer c.mu.RUnlock()
+	return len(c.data)
+}
+
+// Size returns estimated memory of the entries.
+func (c *Cache[K, V]) Size() int64 {
+	c.rlock()
+	defer c.mu.RUnlock()
+	return c.size
+}
+
+// View calls fn with the view of the current entries under the read lock,
+// the view must not be used after fn returns.
+func (c *Cache[K, V]) View(fn func(view Snapshot[K, V])) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	fn(Snapshot[K, V]{data: c.data, now: c.opts.Clock.Now().UnixNano()})
+}
+
+// Update calls fn with the transaction under the write lock.
+func (c *Cache[K, V]) Update(fn func(tx *Tx[K, V])) {
+	c.lock()
+	defer c.mu.Unlock()
+	fn(&Tx[K, V]{c: c})
+	c.opts.Metrics.Changed(len(c.data), c.size)
+}
+
+// Snapshot returns the immutable version of the entries. It shares the map with the cache,
+// so it is taken without copying and the next write copies the map instead.
+func (c *Cache[K, V]) Snapshot() Snapshot[K, V] {
+	c.lock()
+	defer c.mu.Unlock()
+	return c.snapshot()
+}
+
+// Expire removes expired entries and returns their number.
+func (c *Cache[K, V]) Expire() int {
+	var n int
+	c.Update(func(tx *Tx[K, V]) {
+		now := c.opts.Clock.Now().UnixNano()
+		for key, e := range c.data {
+			if e.expired(now) {
+				c.remove(key, ReasonExpired)
+				n++
+			}
+		}
+	})
+	return n
+}
+
+// Clear removes all entries without counting them removed.
+func (c *Cache[K, V]) Clear() {
+	c.Update(func(tx *Tx[K, V]) {
+		for key := range c.data {
+			if c.opts.Policy != nil {
+				c.opts.Policy.Removed(key)
+			}
+		}
+		c.data = make(map[K]entry[V])
+		c.size, c.deleted, c.shared = 0, 0, false
+	})
+}
+
+// Tx is the atomic change of the cache, it must not be used after the Update callback returns.
+type Tx[K comparable, V any] struct {
+	c *Cache[K, V]
+}
+
+// Get returns the value of the key, expired entries are missing.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	return tx.c.get(key)
+}
+
+// Set stores the value with the default TTL.
+func (tx *Tx[K, V]) Set(key K, value V) {
+	tx.SetTTL(key, value, tx.c.opts.TTL)
+}
+
+// SetTTL stores the value, which expires after ttl, zero ttl means never.
+func (tx *Tx[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	tx.c.set(key, value, ttl)
+}
+
+// Delete removes the key and reports whether it was stored.
+func (tx *Tx[K, V]) Delete(key K) bool {
+	return tx.c.remove(key, ReasonDeleted)
+}
+
+// Len returns the number of entries including expired ones not removed yet.
+func (tx *Tx[K, V]) Len() int {
+	return len(tx.c.data)
+}
+
+// Snapshot returns the immutable version of the entries, see Cache.Snapshot.
+func (tx *Tx[K, V]) Snapshot() Snapshot[K, V] {
+	return tx.c.snapshot()
+}
+
+// Snapshot is a read-only version of the entries.
+type Snapshot[K comparable, V any] struct {
+	data map[K]entry[V]
+	// now is the time of expiration of the snapshot entries
+	now int64
+}
+
+// Get returns the value of the key, entries expired by the time of the snapshot are missing.
+func (s Snapshot[K, V]) Get(key K) (V, bool) {
+	e, ok := s.data[key]
+	if !ok || e.expired(s.now) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Len returns the number of entries including expired ones.
+func (s Snapshot[K, V]) Len() int {
+	return len(s.data)
+}
+
+// Range calls fn for the entries in random order until it returns false.
+func (s Snapshot[K, V]) Range(fn func(key K, value V) bool) {
+	for key, e := range s.data {
+		if e.expired(s.now) {
+			continue
+		}
+		if !fn(key, e.value) {
+			return
+		}
+	}
+}
+
+func (e entry[V]) expired(now int64) bool {
+	return e.expires != 0 && e.expires <= now
+}
+
+// lock takes write lock and observes time spent waiting for it.
+func (c *Cache[K, V]) lock() {
+	start := time.Now()
+	c.mu.Lock()
+	c.opts.Metrics.LockWait(time.Since(start))
+}
+
+// rlock takes read lock and observes time spent waiting for it.
+func (c *Cache[K, V]) rlock() {
+	start := time.Now()
+	c.mu.RLock()
+	c.opts.Metrics.LockWait(time.Since(start))
+}
+
+// get returns the live entry, read lock must be held.
+func (c *Cache[K, V]) get(key K) (V, bool) {
+	e, ok := c.data[key]
+	if !ok || e.expired(c.opts.Clock.Now().UnixNano()) {
+		var zero V
+		return zero, false
+	}
+	if c.opts.Policy != nil {
+		c.opts.Policy.Accessed(key)
+	}
+	return e.value, true
+}
+
+// snapshot marks the map shared, write lock must be held.
+func (c *Cache[K, V]) snapshot() Snapshot[K, V] {
+	c.shared = true
+	return Snapshot[K, V]{data: c.data, now: c.opts.Clock.Now().UnixNano()}
+}
+
+// write copies the map read by snapshots, write lock must be held.
+func (c *Cache[K, V]) write() {
+	if !c.shared {
+		return
+	}
+	c.copy()
+	c.shared = false
+}
+
+func (c *Cache[K, V]) copy() {
+	data := make(map[K]entry[V], len(c.data))
+	for key, e := range c.data {
+		data[key] = e
+	}
+	c.data = data
+}
+
+// set stores the entry and evicts victims of the limits, write lock must be held.
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) {
+	c.write()
+	e := entry[V]{value: value}
+	if c.opts.Sizer != nil {
+		e.size = c.opts.Sizer(key, value)
+	}
+	if ttl > 0 {
+		e.expires = c.opts.Clock.Now().Add(ttl).UnixNano()
+	}
+	if old, ok := c.data[key]; ok {
+		c.size -= old.size
+	}
+	c.data[key] = e
+	c.size += e.size
+	if c.opts.Policy != nil {
+		c.opts.Policy.Added(key)
+	}
+	c.evict()
+}
+
+// evict removes victims of the policy, until the cache is within the limits.
+func (c *Cache[K, V]) evict() {
+	for (c.opts.MaxEntries > 0 && len(c.data) > c.opts.MaxEntries) || (c.opts.MaxSize > 0 && c.size > c.opts.MaxSize) {
+		key, ok := c.opts.Policy.Victim()
+		if !ok {
+			return
+		}
+		c.remove(key, ReasonCapacity)
+	}
+}
+
+// remove deletes the entry and compacts the map, when most of its buckets are empty.
+// Write lock must be held.
+func (c *Cache[K, V]) remove(key K, reason Reason) bool {
+	old, ok := c.data[key]
+	if !ok {
+		return false
+	}
+	c.write()
+	delete(c.data, key)
+	c.size -= old.size
+	c.deleted++
+	if c.opts.Policy != nil {
+		c.opts.Policy.Removed(key)
+	}
+	if reason != ReasonDeleted && c.opts.OnEvict != nil {
+		c.opts.OnEvict(key, old.value, reason)
+	}
+	c.opts.Metrics.Removed(reason)
+
+	if c.deleted >= compactThreshold && c.deleted > len(c.data) {
+		c.copy()
+		c.deleted = 0
+		c.opts.Metrics.Compacted()
+	}
+	return true
+}
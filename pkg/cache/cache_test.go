@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+type counting struct {
+	NopMetrics
+	hits, misses, compacted int
+	removed                 map[Reason]int
+	entries                 int
+	size                    int64
+}
+
+func (m *counting) Lookup(hit bool) {
+	if hit {
+		m.hits++
+		return
+	}
+	m.misses++
+}
+
+func (m *counting) Removed(reason Reason) {
+	m.removed[reason]++
+}
+
+func (m *counting) Compacted() {
+	m.compacted++
+}
+
+func (m *counting) Changed(entries int, size int64) {
+	m.entries, m.size = entries, size
+}
+
+func TestCache_TTL(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1660412960, 0))
+	var evicted []string
+	c := New(Options[string, int]{
+		TTL:   time.Minute,
+		Clock: clk,
+		OnEvict: func(key string, _ int, reason Reason) {
+			evicted = append(evicted, key+":"+string(reason))
+		},
+	})
+	c.Set("token", 1)
+	c.SetTTL("session", 2, time.Hour)
+	c.SetTTL("config", 3, 0)
+
+	clk.Advance(2 * time.Minute)
+	_, ok := c.Get("token")
+	assert.False(t, ok)
+	value, ok := c.Get("session")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	// expired entries are kept until they are removed
+	assert.Equal(t, 3, c.Len())
+
+	assert.Equal(t, 1, c.Expire())
+	assert.Equal(t, []string{"token:expired"}, evicted)
+
+	clk.Advance(24 * time.Hour)
+	_, ok = c.Get("session")
+	assert.False(t, ok)
+	value, ok = c.Get("config")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
+
+func TestCache_Evict(t *testing.T) {
+	cases := []struct {
+		name       string
+		maxEntries int
+		maxSize    int64
+		expKeys    []string
+	}{
+		{
+			name:       "entries",
+			maxEntries: 2,
+			expKeys:    []string{"a", "c"},
+		},
+		{
+			name:    "size",
+			maxSize: 4,
+			expKeys: []string{"c"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &counting{removed: make(map[Reason]int)}
+			var evicted []string
+			c := New(Options[string, string]{
+				MaxEntries: tc.maxEntries,
+				MaxSize:    tc.maxSize,
+				Sizer:      func(_ string, value string) int64 { return int64(len(value)) },
+				OnEvict:    func(key string, _ string, _ Reason) { evicted = append(evicted, key) },
+				Metrics:    m,
+			})
+			c.Set("a", "aa")
+			c.Set("b", "bb")
+			// a is used recently, so b is the victim
+			_, _ = c.Get("a")
+			c.Set("c", "ccc")
+
+			var keys []string
+			for _, key := range []string{"a", "b", "c"} {
+				if _, ok := c.Get(key); ok {
+					keys = append(keys, key)
+				}
+			}
+			assert.Equal(t, tc.expKeys, keys)
+			assert.Equal(t, len(evicted), m.removed[ReasonCapacity])
+			assert.Equal(t, "b", evicted[0])
+			assert.Equal(t, len(tc.expKeys), m.entries)
+			assert.LessOrEqual(t, c.Size(), int64(5))
+		})
+	}
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	c := New(Options[string, int]{})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	snap := c.Snapshot()
+
+	// writes after the snapshot are not seen by it
+	c.Set("a", 10)
+	c.Delete("b")
+	c.Update(func(tx *Tx[string, int]) {
+		tx.Set("c", 3)
+	})
+
+	value, ok := snap.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = snap.Get("c")
+	assert.False(t, ok)
+	assert.Equal(t, 2, snap.Len())
+
+	current := make(map[string]int)
+	c.View(func(view Snapshot[string, int]) {
+		view.Range(func(key string, value int) bool {
+			current[key] = value
+			return true
+		})
+	})
+	assert.Equal(t, map[string]int{"a": 10, "c": 3}, current)
+}
+
+func TestCache_Compact(t *testing.T) {
+	m := &counting{removed: make(map[Reason]int)}
+	c := New(Options[string, int]{
+		Sizer:   func(string, int) int64 { return 10 },
+		Metrics: m,
+	})
+	for i := 0; i < compactThreshold+1; i++ {
+		c.Set(fmt.Sprintf("key_%d", i), i)
+	}
+	for i := 0; i < compactThreshold; i++ {
+		assert.True(t, c.Delete(fmt.Sprintf("key_%d", i)))
+	}
+	assert.False(t, c.Delete("key_0"))
+
+	assert.Equal(t, 1, m.compacted)
+	assert.Equal(t, 0, c.deleted)
+	assert.Equal(t, compactThreshold, m.removed[ReasonDeleted])
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, int64(10), c.Size())
+	assert.Equal(t, 1, m.entries)
+	assert.Equal(t, int64(10), m.size)
+
+	_, _ = c.Get("key_0")
+	_, _ = c.Get("key_1024")
+	assert.Equal(t, 1, m.hits)
+	assert.Equal(t, 1, m.misses)
+
+	c.Clear()
+	assert.Zero(t, c.Len())
+	assert.Zero(t, m.size)
+}
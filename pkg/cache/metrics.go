@@ -0,0 +1,24 @@
+package cache
+
+import "time"
+
+// Metrics observes the cache, NopMetrics can be embedded to implement a part of it.
+type Metrics interface {
+	// Lookup counts Get calls of the cache.
+	Lookup(hit bool)
+	Removed(reason Reason)
+	// Compacted counts reallocations of the map shrinking it after deletions.
+	Compacted()
+	// Changed reports the number of entries and their size after every Update.
+	Changed(entries int, size int64)
+	LockWait(d time.Duration)
+}
+
+// NopMetrics observes nothing.
+type NopMetrics struct{}
+
+func (NopMetrics) Lookup(bool)            {}
+func (NopMetrics) Removed(Reason)         {}
+func (NopMetrics) Compacted()             {}
+func (NopMetrics) Changed(int, int64)     {}
+func (NopMetrics) LockWait(time.Duration) {}
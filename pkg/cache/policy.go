@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Policy picks entries evicted by the limits of the cache. Accessed is called under
+// the read lock of the cache, so implementations must be safe for concurrent use.
+type Policy[K comparable] interface {
+	// Added is called for the stored key, both new and overwritten.
+	Added(key K)
+	Accessed(key K)
+	Removed(key K)
+	// Victim returns the key to evict, false means nothing to evict.
+	Victim() (K, bool)
+}
+
+// LRU evicts the least recently used entry.
+type LRU[K comparable] struct {
+	mu    sync.Mutex
+	order *list.List
+	items map[K]*list.Element
+}
+
+// NewLRU returns the empty LRU policy.
+func NewLRU[K comparable]() *LRU[K] {
+	return &LRU[K]{
+		order: list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+func (p *LRU[K]) Added(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.items[key]; ok {
+		p.order.MoveToFront(el)
+		return
+	}
+	p.items[key] = p.order.PushFront(key)
+}
+
+func (p *LRU[K]) Accessed(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.items[key]; ok {
+		p.order.MoveToFront(el)
+	}
+}
+
+func (p *LRU[K]) Removed(key K) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.items[key]; ok {
+		p.order.Remove(el)
+		delete(p.items, key)
+	}
+}
+
+func (p *LRU[K]) Victim() (K, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	el := p.order.Back()
+	if el == nil {
+		var zero K
+		return zero, false
+	}
+	return el.Value.(K), true
+}
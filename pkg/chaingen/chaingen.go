@@ -92,6 +92,8 @@ func genChain(allStructs []*StructInfo, imports map[string]string, packageName s
 				case *ast.Ident:
 					fType = fmt.Sprintf("*%s", val)
 				}
+			case *ast.MapType:
+				fType = fmt.Sprintf("map[%s]%s", val.Key, val.Value)
 			default:
 				fType = fmt.Sprintf("%s", val)
 			}
@@ -0,0 +1,173 @@
+// Package clock abstracts time, so TTLs, expirations, periodic jobs and timestamps are tested
+// by advancing the fake clock instead of sleeping.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes tickers and timers of it.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks of the clock to C.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real returns the system clock.
+func Real() Clock {
+	return system{}
+}
+
+type system struct{}
+
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+func (system) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (system) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (system) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Fake is the clock moved by Advance and Set only. Like the real ones, its tickers drop ticks
+// not received in time.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	// added wakes BlockUntil, when a waiter is added
+	added chan struct{}
+}
+
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewFake returns the fake clock stopped at now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, added: make(chan struct{})}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{f: f, w: f.add(d, d)}
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.add(d, 0).c
+}
+
+// Advance moves the clock forward and fires tickers and timers due by the new time in order.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to the time, tickers and timers are not fired by moves backward.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+		if len(f.waiters) == 0 || f.waiters[0].at.After(now) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.at
+		select {
+		case w.c <- w.at:
+		default:
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = now
+}
+
+// BlockUntil waits until n tickers and timers wait for the clock, so a goroutine starting
+// a ticker is not raced by Advance.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		waiters, added := len(f.waiters), f.added
+		f.mu.Unlock()
+		if waiters >= n {
+			return
+		}
+		<-added
+	}
+}
+
+func (f *Fake) add(d, period time.Duration) *waiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &waiter{at: f.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	close(f.added)
+	f.added = make(chan struct{})
+	return w
+}
+
+func (f *Fake) remove(w *waiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.waiters {
+		if f.waiters[i] == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.f.remove(t.w)
+}
@@ -0,0 +1,69 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Unix(1660412960, 0)
+
+// received returns the times delivered to c without waiting.
+func received(c <-chan time.Time) []time.Time {
+	var times []time.Time
+	for {
+		select {
+		case at := <-c:
+			times = append(times, at)
+		default:
+			return times
+		}
+	}
+}
+
+func TestFake_Ticker(t *testing.T) {
+	clk := NewFake(start)
+	ticker := clk.NewTicker(time.Minute)
+
+	clk.Advance(30 * time.Second)
+	assert.Empty(t, received(ticker.C()))
+
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []time.Time{start.Add(time.Minute)}, received(ticker.C()))
+
+	// ticks not received in time are dropped
+	clk.Advance(3 * time.Minute)
+	assert.Equal(t, []time.Time{start.Add(2 * time.Minute)}, received(ticker.C()))
+	assert.Equal(t, start.Add(4*time.Minute), clk.Now())
+
+	ticker.Stop()
+	clk.Advance(time.Hour)
+	assert.Empty(t, received(ticker.C()))
+}
+
+func TestFake_After(t *testing.T) {
+	clk := NewFake(start)
+	c := clk.After(time.Second)
+
+	clk.Set(start.Add(-time.Hour))
+	assert.Empty(t, received(c))
+
+	clk.Set(start.Add(time.Hour))
+	assert.Equal(t, []time.Time{start.Add(time.Second)}, received(c))
+	assert.Equal(t, 2*time.Hour, clk.Since(start.Add(-time.Hour)))
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	clk := NewFake(start)
+	ticks := make(chan time.Time)
+	go func() {
+		ticker := clk.NewTicker(time.Minute)
+		defer ticker.Stop()
+		ticks <- <-ticker.C()
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), <-ticks)
+}
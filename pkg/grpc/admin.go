@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminService is a prefix of full method names of the Admin service.
+const adminService = "/gitlab.ozon.dev.iTukaev.homework.api.Admin/"
+
+// AdminAuthUnaryInterceptor rejects calls of the Admin service without one of the tokens
+// in "admin-token" metadata with Unauthenticated. Several tokens allow their rotation,
+// no tokens disable the check.
+func AdminAuthUnaryInterceptor(tokens []string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := adminAuth(ctx, info.FullMethod, tokens); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AdminAuthStreamInterceptor is AdminAuthUnaryInterceptor of streams.
+func AdminAuthStreamInterceptor(tokens []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := adminAuth(ss.Context(), info.FullMethod, tokens); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func adminAuth(ctx context.Context, method string, tokens []string) error {
+	if len(tokens) == 0 || !strings.HasPrefix(method, adminService) {
+		return nil
+	}
+	for _, token := range tokens {
+		if trusted(ctx, AdminMetaKey, token) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "admin token is required")
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminAuthUnaryInterceptor(t *testing.T) {
+	tokens := []string{"old", "new"}
+	cases := []struct {
+		name     string
+		tokens   []string
+		method   string
+		reqToken string
+		expCode  codes.Code
+	}{
+		{
+			name:     "valid token",
+			tokens:   tokens,
+			method:   adminService + "CacheEvict",
+			reqToken: "old",
+			expCode:  codes.OK,
+		},
+		{
+			name:     "rotated token",
+			tokens:   tokens,
+			method:   adminService + "CacheEvict",
+			reqToken: "new",
+			expCode:  codes.OK,
+		},
+		{
+			name:     "wrong token",
+			tokens:   tokens,
+			method:   adminService + "CacheEvict",
+			reqToken: "guess",
+			expCode:  codes.Unauthenticated,
+		},
+		{
+			name:    "no token",
+			tokens:  tokens,
+			method:  adminService + "CacheEvict",
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name:    "other service",
+			tokens:  tokens,
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet",
+			expCode: codes.OK,
+		},
+		{
+			name:    "check disabled",
+			method:  adminService + "CacheEvict",
+			expCode: codes.OK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.reqToken != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(AdminMetaKey, c.reqToken))
+			}
+			called := false
+			_, err := AdminAuthUnaryInterceptor(c.tokens)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: c.method},
+				func(context.Context, interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expCode == codes.OK, called)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Authorizer decides calls by the caller, the method and the request, e.g. by declarative policies.
+// Denied calls get the returned status error.
+type Authorizer interface {
+	// Authorize is called with nil request for streams, messages are not received before the handler.
+	Authorize(ctx context.Context, method string, req interface{}) error
+}
+
+// AuthzUnaryInterceptor rejects calls denied by the authorizer, it follows the impersonation
+// interceptor, so both identities of the caller are known.
+func AuthzUnaryInterceptor(authz Authorizer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := authz.Authorize(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthzStreamInterceptor rejects streaming calls denied by the authorizer.
+func AuthzStreamInterceptor(authz Authorizer) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := authz.Authorize(stream.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	// client side health checking is registered by the import
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	PolicyPickFirst  = "pick_first"
+	PolicyRoundRobin = "round_robin"
+
+	dnsScheme    = "dns"
+	subsetScheme = "subset"
+)
+
+// BalancerConfig of the client connection to replicas of the service.
+type BalancerConfig struct {
+	// Policy is "pick_first" or "round_robin", empty is pick_first.
+	Policy string `mapstructure:"policy"`
+	// HealthCheck stops using backends, which report NOT_SERVING by the gRPC health service.
+	HealthCheck bool `mapstructure:"health_check"`
+	// Subset limits the number of backends used by the client, 0 uses all resolved backends.
+	// Subset is kept stable, when backends are added or removed.
+	Subset int `mapstructure:"subset"`
+}
+
+// Dial connects to the target balancing calls by the config. Target "dns:///data:9002" resolves
+// all pod addresses of the headless service and follows their changes.
+func Dial(target string, cfg BalancerConfig, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	serviceConfig, err := balancerServiceConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithDefaultServiceConfig(serviceConfig))
+	if cfg.Subset > 0 {
+		id, _ := os.Hostname()
+		opts = append(opts, grpc.WithResolvers(&subsetBuilder{size: cfg.Subset, id: id}))
+		target = subsetScheme + ":///" + endpoint(target)
+	}
+	return grpc.Dial(target, opts...)
+}
+
+func balancerServiceConfig(cfg BalancerConfig) (string, error) {
+	policy := cfg.Policy
+	switch policy {
+	case "":
+		policy = PolicyPickFirst
+	case PolicyPickFirst, PolicyRoundRobin:
+	default:
+		return "", errors.Errorf("unknown balancing policy: [%s]", cfg.Policy)
+	}
+
+	serviceConfig := map[string]interface{}{
+		"loadBalancingConfig": []map[string]interface{}{{policy: struct{}{}}},
+	}
+	if cfg.HealthCheck {
+		// empty service name checks the overall server health
+		serviceConfig["healthCheckConfig"] = map[string]string{"serviceName": ""}
+	}
+	data, err := json.Marshal(serviceConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "service config")
+	}
+	return string(data), nil
+}
+
+// endpoint returns host and port of the target, scheme of the DNS target is removed.
+func endpoint(target string) string {
+	if strings.HasPrefix(target, dnsScheme+":") {
+		target = strings.TrimPrefix(target, dnsScheme+":")
+		// authority of the DNS server is not supported by the subset
+		if strings.HasPrefix(target, "//") {
+			target = target[strings.Index(target[2:], "/")+3:]
+		}
+	}
+	return target
+}
+
+// subsetBuilder resolves the target by DNS and keeps the subset of the resolved addresses.
+type subsetBuilder struct {
+	size int
+	id   string
+}
+
+func (b *subsetBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	dns := resolver.Get(dnsScheme)
+	if dns == nil {
+		return nil, errors.New("dns resolver is not registered")
+	}
+	return dns.Build(target, &subsetConn{ClientConn: cc, size: b.size, id: b.id}, opts)
+}
+
+func (b *subsetBuilder) Scheme() string {
+	return subsetScheme
+}
+
+type subsetConn struct {
+	resolver.ClientConn
+	size int
+	id   string
+}
+
+func (c *subsetConn) UpdateState(state resolver.State) error {
+	state.Addresses = subset(state.Addresses, c.size, c.id)
+	return c.ClientConn.UpdateState(state)
+}
+
+// subset returns size addresses chosen by rendezvous hashing with the client id, so clients
+// are spread over backends and a backend change moves only the clients, which used it.
+func subset(addresses []resolver.Address, size int, id string) []resolver.Address {
+	if len(addresses) <= size {
+		return addresses
+	}
+	scores := make(map[string]uint64, len(addresses))
+	for _, address := range addresses {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id + "/" + address.Addr))
+		scores[address.Addr] = h.Sum64()
+	}
+	chosen := append([]resolver.Address(nil), addresses...)
+	sort.Slice(chosen, func(i, j int) bool {
+		return scores[chosen[i].Addr] > scores[chosen[j].Addr]
+	})
+	return chosen[:size]
+}
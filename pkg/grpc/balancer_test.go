@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestBalancerServiceConfig(t *testing.T) {
+	cases := []struct {
+		name      string
+		cfg       BalancerConfig
+		expConfig string
+		expErr    bool
+	}{
+		{
+			name:      "default",
+			expConfig: `{"loadBalancingConfig":[{"pick_first":{}}]}`,
+		},
+		{
+			name:      "round robin with health check",
+			cfg:       BalancerConfig{Policy: PolicyRoundRobin, HealthCheck: true},
+			expConfig: `{"healthCheckConfig":{"serviceName":""},"loadBalancingConfig":[{"round_robin":{}}]}`,
+		},
+		{
+			name:   "unknown policy",
+			cfg:    BalancerConfig{Policy: "random"},
+			expErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			serviceConfig, err := balancerServiceConfig(c.cfg)
+			assert.Equal(t, c.expErr, err != nil)
+			assert.Equal(t, c.expConfig, serviceConfig)
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	assert.Equal(t, "data:9002", endpoint("dns:///data:9002"))
+	assert.Equal(t, "data:9002", endpoint("dns://8.8.8.8/data:9002"))
+	assert.Equal(t, "data:9002", endpoint("data:9002"))
+}
+
+func TestSubset(t *testing.T) {
+	addresses := make([]resolver.Address, 10)
+	for i := range addresses {
+		addresses[i] = resolver.Address{Addr: fmt.Sprintf("10.0.0.%d:9002", i)}
+	}
+
+	chosen := subset(addresses, 3, "receiver-1")
+	require.Len(t, chosen, 3)
+	assert.Equal(t, chosen, subset(addresses, 3, "receiver-1"))
+	assert.Len(t, subset(addresses[:2], 3, "receiver-1"), 2)
+
+	// removal of the unused backend keeps the subset
+	var unused []resolver.Address
+	for _, address := range addresses {
+		if address != chosen[0] && address != chosen[1] && address != chosen[2] {
+			unused = append(unused, address)
+		}
+	}
+	assert.Equal(t, chosen, subset(append(append([]resolver.Address(nil), chosen...), unused[1:]...), 3, "receiver-1"))
+}
+
+func TestDial(t *testing.T) {
+	conn, err := Dial("dns:///localhost:9002", BalancerConfig{Policy: PolicyRoundRobin, HealthCheck: true, Subset: 1},
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	assert.Equal(t, "subset:///localhost:9002", conn.Target())
+	assert.NoError(t, conn.Close())
+
+	_, err = Dial("localhost:9002", BalancerConfig{Policy: "random"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+const (
+	// DebugMetaKey carries the debug token, requests with the valid one get timings in trailing metadata.
+	DebugMetaKey = "debug-token"
+	// TimingMetaPrefix is a prefix of the trailing metadata keys with layer timings, e.g. "timing-repo".
+	TimingMetaPrefix = "timing-"
+)
+
+// DebugUnaryInterceptor collects timings of requests with the debug token and returns them
+// in trailing metadata. Empty token disables debug mode.
+func DebugUnaryInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !trusted(ctx, DebugMetaKey, token) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		// timings collected by outer interceptors, e.g. of the cost budget, are shared
+		timings := timing.FromContext(ctx)
+		if timings == nil {
+			ctx, timings = timing.WithTimings(ctx)
+		}
+		resp, err := handler(ctx, req)
+		timings.Add(timing.Total, time.Since(start))
+
+		_ = grpc.SetTrailer(ctx, timingsMeta(timings))
+		return resp, err
+	}
+}
+
+// DebugForwardUnaryInterceptor passes the debug token of the incoming request to the upstream
+// and copies upstream timings to trailing metadata of the incoming request.
+func DebugForwardUnaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(DebugMetaKey)
+	if len(tokens) == 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	var trailer metadata.MD
+	ctx = metadata.AppendToOutgoingContext(ctx, DebugMetaKey, tokens[0])
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+
+	timings := metadata.MD{}
+	for key, values := range trailer {
+		if strings.HasPrefix(key, TimingMetaPrefix) {
+			timings[key] = values
+		}
+	}
+	if len(timings) != 0 {
+		_ = grpc.SetTrailer(ctx, timings)
+	}
+	return err
+}
+
+// trusted reports whether the request carries the token in the metadata key, empty token is never trusted.
+func trusted(ctx context.Context, key, token string) bool {
+	if token == "" {
+		return false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(key)
+	return len(tokens) != 0 && subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(token)) == 1
+}
+
+func timingsMeta(timings *timing.Timings) metadata.MD {
+	md := metadata.MD{}
+	for _, layer := range []string{timing.Cache, timing.Repo} {
+		md.Set(TimingMetaPrefix+layer, timings.Get(layer).String())
+	}
+	for _, layer := range timings.Layers() {
+		md.Set(TimingMetaPrefix+layer, timings.Get(layer).String())
+	}
+	return md
+}
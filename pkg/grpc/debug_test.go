@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+// stream records header and trailing metadata set by the handler.
+type stream struct {
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *stream) Method() string               { return "/api.User/UserGet" }
+func (s *stream) SendHeader(metadata.MD) error { return nil }
+func (s *stream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+func (s *stream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestDebugUnaryInterceptor(t *testing.T) {
+	cases := []struct {
+		name       string
+		token      string
+		reqToken   string
+		expTimings bool
+	}{
+		{
+			name:       "trusted client",
+			token:      "secret",
+			reqToken:   "secret",
+			expTimings: true,
+		},
+		{
+			name:     "wrong token",
+			token:    "secret",
+			reqToken: "guess",
+		},
+		{
+			name: "no token",
+		},
+		{
+			name:     "debug mode disabled",
+			reqToken: "secret",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &stream{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), s)
+			if c.reqToken != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(DebugMetaKey, c.reqToken))
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				timing.Track(ctx, timing.Repo, time.Now().Add(-time.Millisecond))
+				return req, nil
+			}
+			resp, err := DebugUnaryInterceptor(c.token)(ctx, "req", &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			assert.Equal(t, "req", resp)
+
+			if !c.expTimings {
+				assert.Empty(t, s.trailer)
+				return
+			}
+			assert.Equal(t, []string{"0s"}, s.trailer.Get(TimingMetaPrefix+timing.Cache))
+			repo, err := time.ParseDuration(s.trailer.Get(TimingMetaPrefix + timing.Repo)[0])
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, repo, time.Millisecond)
+			assert.Len(t, s.trailer.Get(TimingMetaPrefix+timing.Total), 1)
+		})
+	}
+}
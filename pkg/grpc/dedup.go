@@ -0,0 +1,164 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultDedupWindow = 5 * time.Second
+
+// DedupConfig of duplicate mutations suppression.
+type DedupConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Window, within which the same request of the same caller is a duplicate, default is 5s.
+	Window time.Duration `mapstructure:"window"`
+	// Methods are full names of deduplicated methods, default are mutations of users by name.
+	Methods []string `mapstructure:"methods"`
+}
+
+const userService = "/gitlab.ozon.dev.iTukaev.homework.api.User/"
+
+var defaultDedupMethods = []string{
+	userService + "UserCreate",
+	userService + "UserUpdate",
+	userService + "UserDelete",
+	userService + "UserRename",
+	userService + "UserDisable",
+	userService + "UserEnable",
+}
+
+// Dedup suppresses duplicates of mutations, e.g. double submits of GUIs: a request of the caller
+// equal to the one made within the window is not handled again, it gets the result of the first one.
+// Requests are keyed by the hash of the method, the caller identity and the payload. Callers without
+// the meta identity are not deduplicated, since requests of different callers can't be told apart.
+// Results are kept in memory, so duplicates are suppressed by the instance, which handled the request.
+type Dedup struct {
+	cfg     DedupConfig
+	clock   clock.Clock
+	methods map[string]struct{}
+
+	mu        sync.Mutex
+	calls     map[[sha256.Size]byte]*dedupCall
+	nextPrune time.Time
+}
+
+type dedupCall struct {
+	done    chan struct{}
+	expires time.Time
+	resp    interface{}
+	err     error
+}
+
+func NewDedup(cfg DedupConfig, clk clock.Clock) *Dedup {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultDedupWindow
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = defaultDedupMethods
+	}
+	methods := make(map[string]struct{}, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		methods[method] = struct{}{}
+	}
+	return &Dedup{
+		cfg:     cfg,
+		clock:   clk,
+		methods: methods,
+		calls:   make(map[[sha256.Size]byte]*dedupCall),
+	}
+}
+
+// Do calls the handler, unless the request is a duplicate. Duplicates of a request in flight
+// wait for its result.
+func (d *Dedup) Do(ctx context.Context, method string, req interface{}, handler func() (interface{}, error)) (interface{}, error) {
+	key, ok := d.key(ctx, method, req)
+	if !ok {
+		return handler()
+	}
+
+	d.mu.Lock()
+	now := d.clock.Now()
+	d.prune(now)
+	if call, ok := d.calls[key]; ok && now.Before(call.expires) {
+		d.mu.Unlock()
+		counter.DuplicatesSuppressed.Inc(method)
+		select {
+		case <-call.done:
+			return call.resp, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &dedupCall{done: make(chan struct{}), expires: now.Add(d.cfg.Window)}
+	d.calls[key] = call
+	d.mu.Unlock()
+
+	call.resp, call.err = handler()
+	close(call.done)
+	return call.resp, call.err
+}
+
+// key returns the hash of the request, if the method is deduplicated and the caller is known.
+func (d *Dedup) key(ctx context.Context, method string, req interface{}) ([sha256.Size]byte, bool) {
+	var key [sha256.Size]byte
+	if _, ok := d.methods[method]; !ok {
+		return key, false
+	}
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return key, false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("meta")) == 0 {
+		return key, false
+	}
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return key, false
+	}
+	identity := IdentityFromContext(ctx)
+	h := sha256.New()
+	for _, part := range []string{method, identity.Real, identity.Effective} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(payload)
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+// prune drops expired calls once a window, it is called under the lock.
+func (d *Dedup) prune(now time.Time) {
+	if now.Before(d.nextPrune) {
+		return
+	}
+	d.nextPrune = now.Add(d.cfg.Window)
+	for key, call := range d.calls {
+		if !now.Before(call.expires) {
+			delete(d.calls, key)
+		}
+	}
+}
+
+// DedupUnaryInterceptor suppresses duplicates of unary mutations.
+func DedupUnaryInterceptor(d *Dedup) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return d.Do(ctx, info.FullMethod, req, func() (interface{}, error) {
+			return handler(ctx, req)
+		})
+	}
+}
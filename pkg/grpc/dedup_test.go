@@ -0,0 +1,162 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+func TestDedup_Do(t *testing.T) {
+	create := userService + "UserCreate"
+	withMeta := func(meta string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("meta", meta))
+	}
+	req := func(name string) *pb.UserCreateRequest {
+		return &pb.UserCreateRequest{User: &pbModels.User{Name: name}}
+	}
+
+	type call struct {
+		ctx     context.Context
+		method  string
+		req     interface{}
+		advance time.Duration
+	}
+	cases := []struct {
+		name     string
+		calls    []call
+		expCalls int
+	}{
+		{
+			name: "duplicate within the window",
+			calls: []call{
+				{ctx: withMeta("ivan"), method: create, req: req("petr")},
+				{ctx: withMeta("ivan"), method: create, req: req("petr"), advance: time.Second},
+			},
+			expCalls: 1,
+		},
+		{
+			name: "duplicate after the window",
+			calls: []call{
+				{ctx: withMeta("ivan"), method: create, req: req("petr")},
+				{ctx: withMeta("ivan"), method: create, req: req("petr"), advance: 5 * time.Second},
+			},
+			expCalls: 2,
+		},
+		{
+			name: "other payload",
+			calls: []call{
+				{ctx: withMeta("ivan"), method: create, req: req("petr")},
+				{ctx: withMeta("ivan"), method: create, req: req("oleg")},
+			},
+			expCalls: 2,
+		},
+		{
+			name: "other caller",
+			calls: []call{
+				{ctx: withMeta("ivan"), method: create, req: req("petr")},
+				{ctx: withMeta("oleg"), method: create, req: req("petr")},
+			},
+			expCalls: 2,
+		},
+		{
+			name: "caller without meta",
+			calls: []call{
+				{ctx: context.Background(), method: create, req: req("petr")},
+				{ctx: context.Background(), method: create, req: req("petr")},
+			},
+			expCalls: 2,
+		},
+		{
+			name: "other method",
+			calls: []call{
+				{ctx: withMeta("ivan"), method: userService + "UserGet", req: &pb.UserGetRequest{Name: "petr"}},
+				{ctx: withMeta("ivan"), method: userService + "UserGet", req: &pb.UserGetRequest{Name: "petr"}},
+			},
+			expCalls: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Unix(1700000000, 0))
+			dedup := NewDedup(DedupConfig{Enabled: true}, clk)
+			calls := 0
+			for i, cl := range c.calls {
+				clk.Advance(cl.advance)
+				resp, err := dedup.Do(cl.ctx, cl.method, cl.req, func() (interface{}, error) {
+					calls++
+					return calls, errors.New("already exists")
+				})
+				assert.EqualError(t, err, "already exists")
+				if c.expCalls == 1 {
+					assert.Equal(t, 1, resp, "duplicates get the result of the first call")
+				} else {
+					assert.Equal(t, i+1, resp)
+				}
+			}
+			assert.Equal(t, c.expCalls, calls)
+		})
+	}
+}
+
+func TestDedup_DoConcurrent(t *testing.T) {
+	dedup := NewDedup(DedupConfig{Enabled: true}, clock.Real())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("meta", "ivan"))
+	req := &pb.UserDeleteRequest{Name: "petr"}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	calls := 0
+	var wg sync.WaitGroup
+	results := make([]interface{}, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i != 0 {
+				<-started
+			}
+			results[i], _ = dedup.Do(ctx, userService+"UserDelete", req, func() (interface{}, error) {
+				calls++
+				close(started)
+				<-release
+				return &pb.UserDeleteResponse{}, nil
+			})
+		}(i)
+	}
+	<-started
+	// duplicates wait for the call in flight
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+	assert.Same(t, results[0], results[1])
+	assert.Same(t, results[0], results[2])
+}
+
+func TestDedupUnaryInterceptor(t *testing.T) {
+	interceptor := DedupUnaryInterceptor(NewDedup(DedupConfig{Methods: []string{"/svc/Method"}}, clock.Real()))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("meta", "ivan"))
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, err := interceptor(ctx, &pb.UserDeleteRequest{Name: "petr"}, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"},
+			func(context.Context, interface{}) (interface{}, error) {
+				calls++
+				return nil, nil
+			})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+}
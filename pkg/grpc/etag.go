@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+// ETag returns a strong entity tag of the record. The record keeps the whole user
+// including its HLC version, so any change of the user changes the tag.
+func ETag(record []byte) string {
+	sum := sha256.Sum256(record)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagForwardResponse is a gateway response option, which sets ETag of user records.
+// GET /v1/user/{name} is answered asynchronously by a request UID, so the record is
+// the one polled by GET /v1/data.
+func ETagForwardResponse(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	if out, ok := resp.(*pb.DataResponse); ok && len(out.GetBody().GetValue()) != 0 {
+		w.Header().Set("ETag", ETag(out.GetBody().GetValue()))
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	return nil
+}
+
+// ETagHandler answers GET requests by 304 Not Modified, if If-None-Match contains ETag of the response,
+// so polling clients do not download an unchanged user. The response is still made by next,
+// the handler saves only the body.
+func ETagHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match := r.Header.Get("If-None-Match")
+		if r.Method != http.MethodGet || match == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&etagWriter{ResponseWriter: w, match: match}, r)
+	})
+}
+
+type etagWriter struct {
+	http.ResponseWriter
+	match       string
+	wroteHeader bool
+	notModified bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code != http.StatusOK {
+		w.Header().Del("ETag")
+	} else if etagMatches(w.match, w.Header().Get("ETag")) {
+		w.notModified = true
+		code = http.StatusNotModified
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Length")
+		counter.NotModified.Inc()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.notModified {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// etagMatches compares tags of If-None-Match weakly, as RFC 7232 requires for GET.
+func etagMatches(match, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
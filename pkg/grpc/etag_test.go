@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+type dataServer struct {
+	pb.UnimplementedUserServer
+	records map[string]*anypb.Any
+}
+
+func (s *dataServer) Data(_ context.Context, in *pb.DataRequest) (*pb.DataResponse, error) {
+	record, ok := s.records[in.GetUid()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "key is incorrect or data in not ready yet")
+	}
+	return &pb.DataResponse{Body: record}, nil
+}
+
+func TestETag(t *testing.T) {
+	tag := ETag([]byte(`{"name":"ivan","hlc":1}`))
+	assert.Equal(t, tag, ETag([]byte(`{"name":"ivan","hlc":1}`)))
+	assert.NotEqual(t, tag, ETag([]byte(`{"name":"ivan","hlc":2}`)))
+}
+
+func TestETagHandler(t *testing.T) {
+	// the gateway marshals only typed bodies to JSON
+	record, err := anypb.New(wrapperspb.Bytes([]byte(`{"name":"ivan","hlc":1}`)))
+	require.NoError(t, err)
+	tag := ETag(record.GetValue())
+	gwMux := runtime.NewServeMux(runtime.WithForwardResponseOption(ETagForwardResponse))
+	require.NoError(t, pb.RegisterUserHandlerServer(context.Background(), gwMux,
+		&dataServer{records: map[string]*anypb.Any{"uid-1": record}}))
+	handler := ETagHandler(gwMux)
+
+	cases := []struct {
+		name    string
+		uid     string
+		match   string
+		expCode int
+		expTag  string
+	}{
+		{
+			name:    "no If-None-Match",
+			uid:     "uid-1",
+			expCode: http.StatusOK,
+			expTag:  tag,
+		},
+		{
+			name:    "not modified",
+			uid:     "uid-1",
+			match:   tag,
+			expCode: http.StatusNotModified,
+			expTag:  tag,
+		},
+		{
+			name:    "not modified, weak tag in a list",
+			uid:     "uid-1",
+			match:   `"stale", W/` + tag,
+			expCode: http.StatusNotModified,
+			expTag:  tag,
+		},
+		{
+			name:    "modified",
+			uid:     "uid-1",
+			match:   `"stale"`,
+			expCode: http.StatusOK,
+			expTag:  tag,
+		},
+		{
+			name:    "not found is not cached",
+			uid:     "uid-2",
+			match:   "*",
+			expCode: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/data?uid="+c.uid, nil)
+			if c.match != "" {
+				req.Header.Set("If-None-Match", c.match)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.expCode, rec.Code)
+			assert.Equal(t, c.expTag, rec.Header().Get("ETag"))
+			if c.expCode == http.StatusNotModified {
+				assert.Empty(t, rec.Body.Bytes())
+			} else {
+				assert.NotEmpty(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+// RoleTokenMetaKey carries the role token, HTTP callers send it in Grpc-Metadata-Role-Token header.
+const RoleTokenMetaKey = "role-token"
+
+// FieldsConfig is a declarative policy of user fields hidden from callers by their role.
+type FieldsConfig struct {
+	// DefaultRole is a role of callers without a known token.
+	DefaultRole string `mapstructure:"default_role"`
+	// Tokens map roles to tokens of their callers.
+	Tokens map[string]string `mapstructure:"tokens"`
+	// Hidden maps roles to user fields cleared in responses, e.g. email and attributes.
+	// Roles without hidden fields see the whole user.
+	Hidden map[string][]string `mapstructure:"hidden"`
+}
+
+// Fields strips hidden fields of users from responses by the caller role.
+type Fields struct {
+	cfg    FieldsConfig
+	hidden map[string][]protoreflect.FieldDescriptor
+}
+
+// NewFields checks field names of the policy, they are names of the user model fields.
+func NewFields(cfg FieldsConfig) (*Fields, error) {
+	fields := (&pbModels.User{}).ProtoReflect().Descriptor().Fields()
+	f := &Fields{cfg: cfg, hidden: make(map[string][]protoreflect.FieldDescriptor, len(cfg.Hidden))}
+	for role, names := range cfg.Hidden {
+		for _, name := range names {
+			fd := fields.ByName(protoreflect.Name(name))
+			if fd == nil {
+				return nil, errors.Errorf("fields: role [%s]: unknown user field [%s]", role, name)
+			}
+			f.hidden[role] = append(f.hidden[role], fd)
+		}
+	}
+	return f, nil
+}
+
+// Role returns the role of the caller by its token or the default role.
+func (f *Fields) Role(ctx context.Context) string {
+	for role, token := range f.cfg.Tokens {
+		if trusted(ctx, RoleTokenMetaKey, token) {
+			return role
+		}
+	}
+	return f.cfg.DefaultRole
+}
+
+// Filter clears hidden fields of all users in the response. Users of Data are kept
+// as JSON, so their keys are removed from it.
+func (f *Fields) Filter(ctx context.Context, resp interface{}) {
+	hidden := f.hidden[f.Role(ctx)]
+	if len(hidden) == 0 {
+		return
+	}
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return
+	}
+	if data, ok := msg.(*pb.DataResponse); ok {
+		if body := data.GetBody(); body != nil {
+			body.Value = stripJSON(body.GetValue(), hidden)
+		}
+		return
+	}
+	stripMessage(msg.ProtoReflect(), hidden)
+}
+
+var userFullName = (&pbModels.User{}).ProtoReflect().Descriptor().FullName()
+
+func stripMessage(m protoreflect.Message, hidden []protoreflect.FieldDescriptor) {
+	if m.Descriptor().FullName() == userFullName {
+		for _, fd := range hidden {
+			m.Clear(fd)
+		}
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				stripMessage(list.Get(i).Message(), hidden)
+			}
+		case !fd.IsMap() && fd.Message() != nil:
+			stripMessage(v.Message(), hidden)
+		}
+		return true
+	})
+}
+
+// stripJSON removes hidden keys of the user or users list, other data is returned as is.
+func stripJSON(data []byte, hidden []protoreflect.FieldDescriptor) []byte {
+	var users []map[string]json.RawMessage
+	list := true
+	if err := json.Unmarshal(data, &users); err != nil {
+		var user map[string]json.RawMessage
+		if err = json.Unmarshal(data, &user); err != nil {
+			return data
+		}
+		users, list = []map[string]json.RawMessage{user}, false
+	}
+	for _, user := range users {
+		for _, fd := range hidden {
+			delete(user, string(fd.Name()))
+		}
+	}
+
+	var stripped []byte
+	var err error
+	if list {
+		stripped, err = json.Marshal(users)
+	} else {
+		stripped, err = json.Marshal(users[0])
+	}
+	if err != nil {
+		return data
+	}
+	return stripped
+}
+
+// FieldsUnaryInterceptor strips hidden fields of users from responses.
+func FieldsUnaryInterceptor(f *Fields) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			f.Filter(ctx, resp)
+		}
+		return resp, err
+	}
+}
+
+// FieldsStreamInterceptor strips hidden fields of users from streamed responses.
+func FieldsStreamInterceptor(f *Fields) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &fieldsStream{ServerStream: ss, fields: f})
+	}
+}
+
+type fieldsStream struct {
+	grpc.ServerStream
+	fields *Fields
+}
+
+func (s *fieldsStream) SendMsg(m interface{}) error {
+	s.fields.Filter(s.Context(), m)
+	return s.ServerStream.SendMsg(m)
+}
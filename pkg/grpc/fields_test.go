@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+func TestNewFields(t *testing.T) {
+	_, err := NewFields(FieldsConfig{Hidden: map[string][]string{"public": {"email", "last_login"}}})
+	assert.Error(t, err)
+}
+
+func TestFields_Filter(t *testing.T) {
+	fields, err := NewFields(FieldsConfig{
+		DefaultRole: "public",
+		Tokens:      map[string]string{"support": "secret"},
+		Hidden: map[string][]string{
+			"public":  {"email", "attributes"},
+			"support": {"attributes"},
+		},
+	})
+	require.NoError(t, err)
+	user := func() *pbModels.User {
+		return &pbModels.User{Name: "ivan", Email: "ivan@example.com", Attributes: map[string]string{"team": "core"}}
+	}
+
+	cases := []struct {
+		name    string
+		token   string
+		resp    proto.Message
+		expResp proto.Message
+	}{
+		{
+			name:    "default role",
+			resp:    &pb.UserAllListResponse{Users: []*pbModels.User{user(), user()}},
+			expResp: &pb.UserAllListResponse{Users: []*pbModels.User{{Name: "ivan"}, {Name: "ivan"}}},
+		},
+		{
+			name:    "role by token",
+			token:   "secret",
+			resp:    &pb.UserGetIfChangedResponse{User: user(), Version: "v1"},
+			expResp: &pb.UserGetIfChangedResponse{User: &pbModels.User{Name: "ivan", Email: "ivan@example.com"}, Version: "v1"},
+		},
+		{
+			name:    "default role, unknown token",
+			token:   "guess",
+			resp:    &pb.UserGetIfChangedResponse{User: user()},
+			expResp: &pb.UserGetIfChangedResponse{User: &pbModels.User{Name: "ivan"}},
+		},
+		{
+			name: "data users",
+			resp: &pb.DataResponse{Body: &anypb.Any{
+				Value: []byte(`[{"name":"ivan","email":"ivan@example.com","attributes":{"team":"core"}}]`),
+			}},
+			expResp: &pb.DataResponse{Body: &anypb.Any{Value: []byte(`[{"name":"ivan"}]`)}},
+		},
+		{
+			name:    "data not a user",
+			resp:    &pb.DataResponse{Body: &anypb.Any{Value: []byte(`"ok"`)}},
+			expResp: &pb.DataResponse{Body: &anypb.Any{Value: []byte(`"ok"`)}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RoleTokenMetaKey, c.token))
+			}
+			fields.Filter(ctx, c.resp)
+			assert.True(t, proto.Equal(c.expResp, c.resp), c.resp)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+// GatewayServer runs the interceptors of the gRPC server for calls of the server made directly
+// by the HTTP gateway, which does not run interceptors. So authorization, limits, metrics,
+// request IDs and recovery of both protocols are the same. The gateway serves unary methods only.
+func GatewayServer(server pb.UserServer, interceptors ...grpc.UnaryServerInterceptor) pb.UserServer {
+	return &gatewayServer{UserServer: server, interceptor: grpcMiddleware.ChainUnaryServer(interceptors...)}
+}
+
+type gatewayServer struct {
+	pb.UserServer
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// call runs the interceptors of the method of the User service.
+func (s *gatewayServer) call(ctx context.Context, method string, req interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+	return s.interceptor(ctx, req, &grpc.UnaryServerInfo{Server: s.UserServer, FullMethod: userService + method}, handler)
+}
+
+func (s *gatewayServer) UserCreate(ctx context.Context, in *pb.UserCreateRequest) (*pb.UserCreateResponse, error) {
+	resp, err := s.call(ctx, "UserCreate", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserCreate(ctx, req.(*pb.UserCreateRequest))
+	})
+	out, _ := resp.(*pb.UserCreateResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserUpdate(ctx context.Context, in *pb.UserUpdateRequest) (*pb.UserUpdateResponse, error) {
+	resp, err := s.call(ctx, "UserUpdate", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserUpdate(ctx, req.(*pb.UserUpdateRequest))
+	})
+	out, _ := resp.(*pb.UserUpdateResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserDelete(ctx context.Context, in *pb.UserDeleteRequest) (*pb.UserDeleteResponse, error) {
+	resp, err := s.call(ctx, "UserDelete", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserDelete(ctx, req.(*pb.UserDeleteRequest))
+	})
+	out, _ := resp.(*pb.UserDeleteResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserRename(ctx context.Context, in *pb.UserRenameRequest) (*pb.UserRenameResponse, error) {
+	resp, err := s.call(ctx, "UserRename", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserRename(ctx, req.(*pb.UserRenameRequest))
+	})
+	out, _ := resp.(*pb.UserRenameResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserGet(ctx context.Context, in *pb.UserGetRequest) (*pb.UserGetResponse, error) {
+	resp, err := s.call(ctx, "UserGet", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserGet(ctx, req.(*pb.UserGetRequest))
+	})
+	out, _ := resp.(*pb.UserGetResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserDisable(ctx context.Context, in *pb.UserDisableRequest) (*pb.UserDisableResponse, error) {
+	resp, err := s.call(ctx, "UserDisable", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserDisable(ctx, req.(*pb.UserDisableRequest))
+	})
+	out, _ := resp.(*pb.UserDisableResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserEnable(ctx context.Context, in *pb.UserEnableRequest) (*pb.UserEnableResponse, error) {
+	resp, err := s.call(ctx, "UserEnable", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserEnable(ctx, req.(*pb.UserEnableRequest))
+	})
+	out, _ := resp.(*pb.UserEnableResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserGetById(ctx context.Context, in *pb.UserGetByIdRequest) (*pb.UserGetByIdResponse, error) {
+	resp, err := s.call(ctx, "UserGetById", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserGetById(ctx, req.(*pb.UserGetByIdRequest))
+	})
+	out, _ := resp.(*pb.UserGetByIdResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserList(ctx context.Context, in *pb.UserListRequest) (*pb.UserListResponse, error) {
+	resp, err := s.call(ctx, "UserList", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserList(ctx, req.(*pb.UserListRequest))
+	})
+	out, _ := resp.(*pb.UserListResponse)
+	return out, err
+}
+
+func (s *gatewayServer) Data(ctx context.Context, in *pb.DataRequest) (*pb.DataResponse, error) {
+	resp, err := s.call(ctx, "Data", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.Data(ctx, req.(*pb.DataRequest))
+	})
+	out, _ := resp.(*pb.DataResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserAvatarGet(ctx context.Context, in *pb.UserAvatarGetRequest) (*pb.UserAvatarGetResponse, error) {
+	resp, err := s.call(ctx, "UserAvatarGet", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserAvatarGet(ctx, req.(*pb.UserAvatarGetRequest))
+	})
+	out, _ := resp.(*pb.UserAvatarGetResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserCheckPassword(ctx context.Context, in *pb.UserCheckPasswordRequest) (*pb.UserCheckPasswordResponse, error) {
+	resp, err := s.call(ctx, "UserCheckPassword", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserCheckPassword(ctx, req.(*pb.UserCheckPasswordRequest))
+	})
+	out, _ := resp.(*pb.UserCheckPasswordResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserLoginExternal(ctx context.Context, in *pb.UserLoginExternalRequest) (*pb.UserLoginExternalResponse, error) {
+	resp, err := s.call(ctx, "UserLoginExternal", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserLoginExternal(ctx, req.(*pb.UserLoginExternalRequest))
+	})
+	out, _ := resp.(*pb.UserLoginExternalResponse)
+	return out, err
+}
+
+func (s *gatewayServer) ServiceInfo(ctx context.Context, in *pb.ServiceInfoRequest) (*pb.ServiceInfoResponse, error) {
+	resp, err := s.call(ctx, "ServiceInfo", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.ServiceInfo(ctx, req.(*pb.ServiceInfoRequest))
+	})
+	out, _ := resp.(*pb.ServiceInfoResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserGetIfChanged(ctx context.Context, in *pb.UserGetIfChangedRequest) (*pb.UserGetIfChangedResponse, error) {
+	resp, err := s.call(ctx, "UserGetIfChanged", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserGetIfChanged(ctx, req.(*pb.UserGetIfChangedRequest))
+	})
+	out, _ := resp.(*pb.UserGetIfChangedResponse)
+	return out, err
+}
+
+func (s *gatewayServer) UserCount(ctx context.Context, in *pb.UserCountRequest) (*pb.UserCountResponse, error) {
+	resp, err := s.call(ctx, "UserCount", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.UserCount(ctx, req.(*pb.UserCountRequest))
+	})
+	out, _ := resp.(*pb.UserCountResponse)
+	return out, err
+}
+
+func (s *gatewayServer) MeGet(ctx context.Context, in *pb.MeGetRequest) (*pb.MeGetResponse, error) {
+	resp, err := s.call(ctx, "MeGet", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.MeGet(ctx, req.(*pb.MeGetRequest))
+	})
+	out, _ := resp.(*pb.MeGetResponse)
+	return out, err
+}
+
+func (s *gatewayServer) MeUpdate(ctx context.Context, in *pb.MeUpdateRequest) (*pb.MeUpdateResponse, error) {
+	resp, err := s.call(ctx, "MeUpdate", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.MeUpdate(ctx, req.(*pb.MeUpdateRequest))
+	})
+	out, _ := resp.(*pb.MeUpdateResponse)
+	return out, err
+}
+
+func (s *gatewayServer) MeDelete(ctx context.Context, in *pb.MeDeleteRequest) (*pb.MeDeleteResponse, error) {
+	resp, err := s.call(ctx, "MeDelete", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.MeDelete(ctx, req.(*pb.MeDeleteRequest))
+	})
+	out, _ := resp.(*pb.MeDeleteResponse)
+	return out, err
+}
+
+func (s *gatewayServer) MeChangePassword(ctx context.Context, in *pb.MeChangePasswordRequest) (*pb.MeChangePasswordResponse, error) {
+	resp, err := s.call(ctx, "MeChangePassword", in, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.UserServer.MeChangePassword(ctx, req.(*pb.MeChangePasswordRequest))
+	})
+	out, _ := resp.(*pb.MeChangePasswordResponse)
+	return out, err
+}
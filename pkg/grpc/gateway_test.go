@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+type panicServer struct {
+	pb.UnimplementedUserServer
+}
+
+func (s *panicServer) UserGet(_ context.Context, in *pb.UserGetRequest) (*pb.UserGetResponse, error) {
+	if in.GetName() == "panic" {
+		panic("user get")
+	}
+	return &pb.UserGetResponse{}, nil
+}
+
+func TestGatewayServer(t *testing.T) {
+	type call struct {
+		method    string
+		requestID string
+	}
+	var calls []call
+	record := func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		calls = append(calls, call{method: info.FullMethod, requestID: RequestIDFromContext(ctx)})
+		return handler(ctx, req)
+	}
+	limiter := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 2}, clock.NewFake(time.Unix(100, 0)))
+
+	gwMux := runtime.NewServeMux(
+		runtime.WithErrorHandler(RetryAfterErrorHandler),
+		runtime.WithOutgoingHeaderMatcher(OutgoingHeaderMatcher),
+	)
+	require.NoError(t, pb.RegisterUserHandlerServer(context.Background(), gwMux, GatewayServer(&panicServer{},
+		RequestIDUnaryInterceptor,
+		RecoveryUnaryInterceptor(zap.NewNop().Sugar()),
+		RateLimitUnaryInterceptor(limiter),
+		record,
+	)))
+	handler := RequestIDHandler(gwMux)
+
+	cases := []struct {
+		name          string
+		target        string
+		requestID     string
+		expCode       int
+		expRequestID  string
+		expRetryAfter string
+		expCalls      int
+	}{
+		{
+			name:         "request ID of the caller",
+			target:       "/v1/user/ivan",
+			requestID:    "req-1",
+			expCode:      http.StatusOK,
+			expRequestID: "req-1",
+			expCalls:     1,
+		},
+		{
+			name:     "panic is recovered",
+			target:   "/v1/user/panic",
+			expCode:  http.StatusInternalServerError,
+			expCalls: 1,
+		},
+		{
+			name:          "rate limited",
+			target:        "/v1/user/ivan",
+			expCode:       http.StatusTooManyRequests,
+			expRetryAfter: "1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls = nil
+			req := httptest.NewRequest(http.MethodGet, c.target, nil)
+			if c.requestID != "" {
+				req.Header.Set(RequestIDHeader, c.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.expCode, rec.Code)
+			assert.Equal(t, c.expRetryAfter, rec.Header().Get("Retry-After"))
+			requestID := rec.Header().Get(RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if c.expRequestID != "" {
+				assert.Equal(t, c.expRequestID, requestID)
+			}
+			assert.Empty(t, rec.Header().Get(runtime.MetadataHeaderPrefix+RequestIDMetaKey),
+				"the request ID is returned once")
+			require.Len(t, calls, c.expCalls)
+			for _, call := range calls {
+				assert.Equal(t, userService+"UserGet", call.method)
+				assert.Equal(t, requestID, call.requestID, "interceptors see the ID returned to the caller")
+			}
+		})
+	}
+}
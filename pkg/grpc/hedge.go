@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+// hedgeBurst limits hedges made in a row, when the budget is saved by fast calls.
+const hedgeBurst = 10
+
+// HedgeConfig of hedged unary calls, methods must be idempotent reads.
+type HedgeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Delay after which the second attempt is sent, if the first one is not completed.
+	Delay time.Duration `mapstructure:"delay"`
+	// Budget is a maximal share of hedged calls in percents.
+	Budget float64 `mapstructure:"budget"`
+	// Methods are full method names, e.g. "/gitlab.ozon.dev.iTukaev.homework.api.User/Data".
+	Methods []string `mapstructure:"methods"`
+}
+
+// HedgeUnaryInterceptor sends the second attempt of the configured methods, if the first one
+// is not completed within the delay. The first successful result is returned, the other attempt
+// is cancelled, the error is returned, if both attempts failed. Every call earns budget percents
+// of a hedge, so hedges never exceed the budget. Attempts fill their own header, trailer and peer,
+// they are copied to call options of the returned attempt only, so the cancelled one doesn't race on them.
+func HedgeUnaryInterceptor(cfg HedgeConfig) grpc.UnaryClientInterceptor {
+	methods := make(map[string]struct{}, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		methods[method] = struct{}{}
+	}
+	budget := &hedgeBudget{ratio: cfg.Budget / 100}
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		msg, ok := reply.(proto.Message)
+		if _, hedged := methods[method]; !hedged || !ok || cfg.Delay <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		budget.deposit()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make(chan *hedgeAttempt, 2)
+		call := func(hedge bool) {
+			a := &hedgeAttempt{reply: msg.ProtoReflect().New().Interface(), hedge: hedge}
+			a.err = invoker(ctx, method, req, a.reply, cc, a.options(opts)...)
+			results <- a
+		}
+
+		go call(false)
+		timer := time.NewTimer(cfg.Delay)
+		defer timer.Stop()
+
+		var (
+			r       *hedgeAttempt
+			started = 1
+		)
+		select {
+		case r = <-results:
+		case <-timer.C:
+			if budget.withdraw() {
+				counter.HedgeCalls.Inc()
+				started++
+				go call(true)
+			}
+			r = <-results
+		}
+		// the failed attempt waits for the other one, its result may still succeed
+		for received := 1; r.err != nil && received < started; received++ {
+			r = <-results
+		}
+		r.fill(opts)
+		if r.err != nil {
+			return r.err
+		}
+		if r.hedge {
+			counter.HedgeWins.Inc()
+		}
+		proto.Reset(msg)
+		proto.Merge(msg, r.reply)
+		return nil
+	}
+}
+
+// hedgeAttempt is a result of the attempt with outputs written by the call.
+type hedgeAttempt struct {
+	reply   proto.Message
+	err     error
+	hedge   bool
+	header  metadata.MD
+	trailer metadata.MD
+	peer    peer.Peer
+}
+
+// options replaces output call options with the ones of the attempt.
+func (a *hedgeAttempt) options(opts []grpc.CallOption) []grpc.CallOption {
+	replaced := make([]grpc.CallOption, 0, len(opts))
+	for _, opt := range opts {
+		switch opt.(type) {
+		case grpc.HeaderCallOption:
+			opt = grpc.Header(&a.header)
+		case grpc.TrailerCallOption:
+			opt = grpc.Trailer(&a.trailer)
+		case grpc.PeerCallOption:
+			opt = grpc.Peer(&a.peer)
+		}
+		replaced = append(replaced, opt)
+	}
+	return replaced
+}
+
+// fill copies outputs of the attempt to the call options of the caller.
+func (a *hedgeAttempt) fill(opts []grpc.CallOption) {
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = a.header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = a.trailer
+		case grpc.PeerCallOption:
+			*o.PeerAddr = a.peer
+		}
+	}
+}
+
+// hedgeBudget is a token bucket, every call deposits ratio of a token, every hedge withdraws a token.
+type hedgeBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func (b *hedgeBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.ratio
+	if b.tokens > hedgeBurst {
+		b.tokens = hedgeBurst
+	}
+}
+
+func (b *hedgeBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
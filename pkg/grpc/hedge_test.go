@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var errAttempt = errors.New("attempt failed")
+
+func TestHedgeUnaryInterceptor(t *testing.T) {
+	const method = "/api.User/Data"
+
+	cases := []struct {
+		name      string
+		method    string
+		budget    float64
+		slowFirst bool
+		failFirst bool
+		failHedge bool
+		expValue  string
+		expCalls  int32
+		expErr    error
+	}{
+		{
+			name:     "first attempt in time",
+			method:   method,
+			budget:   100,
+			expValue: "first",
+			expCalls: 1,
+		},
+		{
+			name:      "hedge wins",
+			method:    method,
+			budget:    100,
+			slowFirst: true,
+			expValue:  "hedge",
+			expCalls:  2,
+		},
+		{
+			name:      "failed first attempt loses to the hedge",
+			method:    method,
+			budget:    100,
+			slowFirst: true,
+			failFirst: true,
+			expValue:  "hedge",
+			expCalls:  2,
+		},
+		{
+			name:      "failed hedge waits for the first attempt",
+			method:    method,
+			budget:    100,
+			slowFirst: true,
+			failHedge: true,
+			expValue:  "first",
+			expCalls:  2,
+		},
+		{
+			name:      "both attempts failed",
+			method:    method,
+			budget:    100,
+			slowFirst: true,
+			failFirst: true,
+			failHedge: true,
+			expCalls:  2,
+			expErr:    errAttempt,
+		},
+		{
+			name:      "budget exhausted",
+			method:    method,
+			slowFirst: true,
+			expValue:  "first",
+			expCalls:  1,
+		},
+		{
+			name:      "method is not hedged",
+			method:    "/api.User/UserCheckPassword",
+			budget:    100,
+			slowFirst: true,
+			expValue:  "first",
+			expCalls:  1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int32
+			invoker := func(ctx context.Context, _ string, _, reply interface{}, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+				value := reply.(*wrapperspb.StringValue)
+				attempt, fail := "first", c.failFirst
+				if atomic.AddInt32(&calls, 1) > 1 {
+					attempt, fail = "hedge", c.failHedge
+				} else if c.slowFirst {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(50 * time.Millisecond):
+					}
+				}
+				for _, opt := range opts {
+					if header, ok := opt.(grpc.HeaderCallOption); ok {
+						*header.HeaderAddr = metadata.Pairs("attempt", attempt)
+					}
+				}
+				if fail {
+					return errAttempt
+				}
+				value.Value = attempt
+				return nil
+			}
+
+			interceptor := HedgeUnaryInterceptor(HedgeConfig{
+				Delay:   5 * time.Millisecond,
+				Budget:  c.budget,
+				Methods: []string{method},
+			})
+			reply := &wrapperspb.StringValue{}
+			var header metadata.MD
+			err := interceptor(context.Background(), c.method, &wrapperspb.StringValue{}, reply, nil, invoker, grpc.Header(&header))
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expValue, reply.GetValue())
+			assert.Equal(t, c.expCalls, atomic.LoadInt32(&calls))
+			// the header is filled by the returned attempt
+			if c.expValue != "" {
+				assert.Equal(t, []string{c.expValue}, header.Get("attempt"))
+			}
+		})
+	}
+}
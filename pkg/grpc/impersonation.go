@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+// ActAsMetaKey carries the identity, which the caller acts as.
+const ActAsMetaKey = "act-as"
+
+// AnyRole of the impersonation config allows act-as to callers of any role, so the authorization
+// policies decide, who acts as others.
+const AnyRole = "*"
+
+// ImpersonationConfig allows callers of the role to act as another identity, empty role disables it.
+type ImpersonationConfig struct {
+	Role string `mapstructure:"role"`
+}
+
+// Identity of the caller. Real is the request meta of the caller, Effective is set,
+// if the caller acts as another identity.
+type Identity struct {
+	Real      string
+	Effective string
+}
+
+// Impersonated reports whether the caller acts as another identity.
+func (i Identity) Impersonated() bool {
+	return i.Effective != "" && i.Effective != i.Real
+}
+
+// Actor returns the identity, which the change is made by.
+func (i Identity) Actor() string {
+	if i.Impersonated() {
+		return i.Effective
+	}
+	return i.Real
+}
+
+type identityKey struct{}
+
+// WithIdentity returns the context of the identity, e.g. received with a broker message.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity set by the interceptors or WithIdentity,
+// otherwise the request meta is the real identity.
+func IdentityFromContext(ctx context.Context) Identity {
+	if identity, ok := ctx.Value(identityKey{}).(Identity); ok {
+		return identity
+	}
+	return Identity{Real: GetMetaFromContext(ctx)}
+}
+
+// ImpersonationUnaryInterceptor accepts act-as metadata of callers of the configured role only,
+// others get PermissionDenied. Both identities are kept in the context of the request.
+func ImpersonationUnaryInterceptor(cfg ImpersonationConfig, roles *Fields) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, err := impersonate(ctx, cfg, roles)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ImpersonationStreamInterceptor is ImpersonationUnaryInterceptor of streams.
+func ImpersonationStreamInterceptor(cfg ImpersonationConfig, roles *Fields) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := impersonate(ss.Context(), cfg, roles)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream is the stream of the context changed by an interceptor.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// ImpersonationHandler is ImpersonationUnaryInterceptor of HTTP handlers out of the gateway, e.g. the events push.
+// Metadata is taken from Grpc-Metadata-* headers, denied requests get 403.
+func ImpersonationHandler(cfg ImpersonationConfig, roles *Fields, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := impersonate(HTTPIncomingContext(r), cfg, roles)
+		if err != nil {
+			http.Error(w, status.Convert(err).Message(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func impersonate(ctx context.Context, cfg ImpersonationConfig, roles *Fields) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	actAs := md.Get(ActAsMetaKey)
+	if len(actAs) == 0 || actAs[0] == "" {
+		return ctx, nil
+	}
+	if cfg.Role == "" || (cfg.Role != AnyRole && roles.Role(ctx) != cfg.Role) {
+		counter.ImpersonationDenied.Inc()
+		return ctx, status.Error(codes.PermissionDenied, "act-as is not allowed for the caller role")
+	}
+	// the real identity is recorded with every change, so it must be known
+	if len(md.Get("meta")) == 0 {
+		counter.ImpersonationDenied.Inc()
+		return ctx, status.Error(codes.PermissionDenied, "act-as requires meta of the caller")
+	}
+	counter.Impersonations.Inc()
+	return WithIdentity(ctx, Identity{Real: GetMetaFromContext(ctx), Effective: actAs[0]}), nil
+}
@@ -0,0 +1,104 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestImpersonationUnaryInterceptor(t *testing.T) {
+	roles, err := NewFields(FieldsConfig{Tokens: map[string]string{"support": "secret"}})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		cfg         ImpersonationConfig
+		md          metadata.MD
+		expCode     codes.Code
+		expIdentity Identity
+	}{
+		{
+			name:        "no act-as",
+			cfg:         ImpersonationConfig{Role: "support"},
+			md:          metadata.Pairs("meta", "ivan"),
+			expIdentity: Identity{Real: "ivan"},
+		},
+		{
+			name:        "act-as by the role",
+			cfg:         ImpersonationConfig{Role: "support"},
+			md:          metadata.Pairs("meta", "agent", RoleTokenMetaKey, "secret", ActAsMetaKey, "ivan"),
+			expIdentity: Identity{Real: "agent", Effective: "ivan"},
+		},
+		{
+			name:    "failed, other role",
+			cfg:     ImpersonationConfig{Role: "support"},
+			md:      metadata.Pairs("meta", "agent", RoleTokenMetaKey, "guess", ActAsMetaKey, "ivan"),
+			expCode: codes.PermissionDenied,
+		},
+		{
+			name:        "act-as by any role",
+			cfg:         ImpersonationConfig{Role: AnyRole},
+			md:          metadata.Pairs("meta", "agent", ActAsMetaKey, "ivan"),
+			expIdentity: Identity{Real: "agent", Effective: "ivan"},
+		},
+		{
+			name:    "failed, no meta",
+			cfg:     ImpersonationConfig{Role: "support"},
+			md:      metadata.Pairs(RoleTokenMetaKey, "secret", ActAsMetaKey, "ivan"),
+			expCode: codes.PermissionDenied,
+		},
+		{
+			name:    "failed, impersonation disabled",
+			md:      metadata.Pairs("meta", "agent", RoleTokenMetaKey, "secret", ActAsMetaKey, "ivan"),
+			expCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var identity Identity
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				identity = IdentityFromContext(ctx)
+				return req, nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), c.md)
+			_, err := ImpersonationUnaryInterceptor(c.cfg, roles)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expIdentity, identity)
+		})
+	}
+}
+
+func TestImpersonationHandler(t *testing.T) {
+	roles, err := NewFields(FieldsConfig{Tokens: map[string]string{"support": "secret"}})
+	require.NoError(t, err)
+
+	var identity Identity
+	handler := ImpersonationHandler(ImpersonationConfig{Role: "support"}, roles,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity = IdentityFromContext(r.Context())
+		}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/data/uid", nil)
+	req.Header.Set("Grpc-Metadata-Meta", "agent")
+	req.Header.Set("Grpc-Metadata-Act-As", "ivan")
+	req.Header.Set("Grpc-Metadata-Role-Token", "guess")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, Identity{}, identity)
+
+	req.Header.Set("Grpc-Metadata-Role-Token", "secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, Identity{Real: "agent", Effective: "ivan"}, identity)
+}
@@ -3,8 +3,11 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -27,3 +30,15 @@ func GetMetaFromContext(ctx context.Context) string {
 
 	return meta
 }
+
+// HTTPIncomingContext returns the request context with incoming metadata of Grpc-Metadata-* headers,
+// as the gateway makes it, so HTTP handlers out of the gateway see the same metadata.
+func HTTPIncomingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for header, values := range r.Header {
+		if strings.HasPrefix(header, runtime.MetadataHeaderPrefix) {
+			md.Append(strings.TrimPrefix(header, runtime.MetadataHeaderPrefix), values...)
+		}
+	}
+	return metadata.NewIncomingContext(r.Context(), md)
+}
@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+// MethodsConfig disables methods of the served API, e.g. UserDelete in production.
+type MethodsConfig struct {
+	// Disabled are full method names, e.g. "/gitlab.ozon.dev.iTukaev.homework.api.User/UserDelete".
+	Disabled []string `mapstructure:"disabled"`
+}
+
+// Methods tells enabled methods of the services from disabled ones.
+type Methods struct {
+	enabled  []string
+	disabled map[string]struct{}
+}
+
+// NewMethods returns methods of the services, which are not disabled by the config.
+func NewMethods(cfg MethodsConfig, services ...grpc.ServiceDesc) *Methods {
+	m := &Methods{disabled: make(map[string]struct{}, len(cfg.Disabled))}
+	for _, method := range cfg.Disabled {
+		m.disabled[method] = struct{}{}
+	}
+	for _, service := range services {
+		var names []string
+		for _, method := range service.Methods {
+			names = append(names, method.MethodName)
+		}
+		for _, stream := range service.Streams {
+			names = append(names, stream.StreamName)
+		}
+		for _, name := range names {
+			method := "/" + service.ServiceName + "/" + name
+			if _, ok := m.disabled[method]; !ok {
+				m.enabled = append(m.enabled, method)
+			}
+		}
+	}
+	sort.Strings(m.enabled)
+	return m
+}
+
+// Enabled returns full names of enabled methods in order.
+func (m *Methods) Enabled() []string {
+	return m.enabled
+}
+
+// Disabled returns full names of disabled methods in order.
+func (m *Methods) Disabled() []string {
+	disabled := make([]string, 0, len(m.disabled))
+	for method := range m.disabled {
+		disabled = append(disabled, method)
+	}
+	sort.Strings(disabled)
+	return disabled
+}
+
+// Info returns the methods reported by ServiceInfo.
+func (m *Methods) Info() *pb.ServiceInfoResponse {
+	return &pb.ServiceInfoResponse{
+		EnabledMethods:  m.Enabled(),
+		DisabledMethods: m.Disabled(),
+	}
+}
+
+// Check returns Unimplemented with reason METHOD_DISABLED, if the method is disabled.
+func (m *Methods) Check(method string) error {
+	if _, ok := m.disabled[method]; !ok {
+		return nil
+	}
+	return apperr.StatusReason(codes.Unimplemented, apperr.ReasonMethodDisabled,
+		errors.Errorf("method [%s] is disabled by config", method))
+}
+
+// MethodsUnaryInterceptor rejects calls of disabled methods.
+func MethodsUnaryInterceptor(m *Methods) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := m.Check(info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MethodsStreamInterceptor rejects calls of disabled streaming methods.
+func MethodsStreamInterceptor(m *Methods) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := m.Check(info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+const userDelete = "/gitlab.ozon.dev.iTukaev.homework.api.User/UserDelete"
+
+func TestNewMethods(t *testing.T) {
+	methods := NewMethods(MethodsConfig{Disabled: []string{userDelete}}, pb.User_ServiceDesc)
+
+	info := methods.Info()
+	assert.Equal(t, []string{userDelete}, info.GetDisabledMethods())
+	assert.NotContains(t, info.GetEnabledMethods(), userDelete)
+	assert.Contains(t, info.GetEnabledMethods(), "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet")
+	assert.Contains(t, info.GetEnabledMethods(), "/gitlab.ozon.dev.iTukaev.homework.api.User/UserImport")
+	assert.Len(t, info.GetEnabledMethods(), len(pb.User_ServiceDesc.Methods)+len(pb.User_ServiceDesc.Streams)-1)
+}
+
+func TestMethodsUnaryInterceptor(t *testing.T) {
+	interceptor := MethodsUnaryInterceptor(NewMethods(MethodsConfig{Disabled: []string{userDelete}}, pb.User_ServiceDesc))
+
+	cases := []struct {
+		name    string
+		method  string
+		expCode codes.Code
+	}{
+		{
+			name:    "enabled method is called",
+			method:  "/gitlab.ozon.dev.iTukaev.homework.api.User/UserGet",
+			expCode: codes.OK,
+		},
+		{
+			name:    "disabled method is rejected",
+			method:  userDelete,
+			expCode: codes.Unimplemented,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			called := false
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: c.method},
+				func(context.Context, interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+			assert.Equal(t, c.expCode, status.Code(err))
+			assert.Equal(t, c.expCode == codes.OK, called)
+			if c.expCode == codes.OK {
+				return
+			}
+			st, _ := status.FromError(err)
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, apperr.ReasonMethodDisabled, info.GetReason())
+		})
+	}
+}
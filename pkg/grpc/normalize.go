@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultPageSize = 100
+
+// NormalizeConfig of requests normalization.
+type NormalizeConfig struct {
+	// DefaultLimit is a page size of list requests without the limit, default is 100.
+	DefaultLimit uint64 `mapstructure:"default_limit"`
+}
+
+// Normalizer brings requests of the User service to the canonical form: names and other
+// identifiers are trimmed, emails are trimmed and lowercased, list requests get the default
+// page size and users to create get the server creation time instead of the client one.
+// Passwords are kept as is.
+type Normalizer struct {
+	cfg   NormalizeConfig
+	clock clock.Clock
+}
+
+func NewNormalizer(cfg NormalizeConfig, clk clock.Clock) *Normalizer {
+	if cfg.DefaultLimit == 0 {
+		cfg.DefaultLimit = defaultPageSize
+	}
+	return &Normalizer{cfg: cfg, clock: clk}
+}
+
+// Normalize changes the request in place, requests of other types are kept.
+func (n *Normalizer) Normalize(req interface{}) {
+	switch in := req.(type) {
+	case *pb.UserCreateRequest:
+		n.user(in.GetUser(), false)
+	case *pb.UserImportRequest:
+		// imported users keep the creation time of their source
+		n.user(in.GetUser(), true)
+	case *pb.UserUpdateRequest:
+		in.Name = strings.TrimSpace(in.Name)
+		profile(in.GetProfile())
+	case *pb.MeUpdateRequest:
+		profile(in.GetProfile())
+	case *pb.UserDeleteRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserRenameRequest:
+		in.Name = strings.TrimSpace(in.Name)
+		in.NewName = strings.TrimSpace(in.NewName)
+	case *pb.UserGetRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserDisableRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserEnableRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserGetByIdRequest:
+		in.Id = strings.TrimSpace(in.Id)
+	case *pb.UserAvatarGetRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserCheckPasswordRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserGetIfChangedRequest:
+		in.Name = strings.TrimSpace(in.Name)
+	case *pb.UserLoginExternalRequest:
+		in.Email = email(in.Email)
+	case *pb.UserCountRequest:
+		in.Tenant = strings.TrimSpace(in.Tenant)
+	case *pb.UserListRequest:
+		in.Status = strings.ToLower(strings.TrimSpace(in.Status))
+		if in.Limit == 0 {
+			in.Limit = n.cfg.DefaultLimit
+		}
+	case *pb.UserAllListRequest:
+		in.Status = strings.ToLower(strings.TrimSpace(in.Status))
+		if in.Limit == 0 {
+			in.Limit = n.cfg.DefaultLimit
+		}
+	}
+}
+
+// user clears fields set by the server and fills the creation time.
+func (n *Normalizer) user(u *pbModels.User, keepCreatedAt bool) {
+	if u == nil {
+		return
+	}
+	u.Name = strings.TrimSpace(u.Name)
+	u.Email = email(u.Email)
+	u.FullName = strings.TrimSpace(u.FullName)
+
+	u.Id, u.Status, u.AvatarUrl = "", "", ""
+	u.PasswordChangedAt, u.PasswordExpiresAt, u.UpdatedAt, u.Hlc = 0, 0, 0, 0
+	if !keepCreatedAt || u.CreatedAt <= 0 {
+		u.CreatedAt = n.clock.Now().Unix()
+	}
+}
+
+func profile(p *pbModels.Profile) {
+	if p == nil {
+		return
+	}
+	if p.Email != nil {
+		normalized := email(*p.Email)
+		p.Email = &normalized
+	}
+	if p.FullName != nil {
+		trimmed := strings.TrimSpace(*p.FullName)
+		p.FullName = &trimmed
+	}
+}
+
+func email(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// NormalizeUnaryInterceptor normalizes requests before they are validated and handled.
+func NormalizeUnaryInterceptor(n *Normalizer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		n.Normalize(req)
+		return handler(ctx, req)
+	}
+}
+
+// NormalizeStreamInterceptor normalizes every received message of the stream.
+func NormalizeStreamInterceptor(n *Normalizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &normalizeStream{ServerStream: ss, normalizer: n})
+	}
+}
+
+type normalizeStream struct {
+	grpc.ServerStream
+	normalizer *Normalizer
+}
+
+func (s *normalizeStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.normalizer.Normalize(m)
+	return nil
+}
@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	normalizer := NewNormalizer(NormalizeConfig{}, clock.NewFake(now))
+	email := " Ivan@Example.COM "
+	fullName := " Ivan Ivanov "
+
+	cases := []struct {
+		name   string
+		req    proto.Message
+		expReq proto.Message
+	}{
+		{
+			name: "user to create",
+			req: &pb.UserCreateRequest{User: &pbModels.User{
+				Name:      " ivan ",
+				Password:  " secret ",
+				Email:     email,
+				FullName:  fullName,
+				Id:        "id",
+				Status:    "disabled",
+				CreatedAt: 1,
+				UpdatedAt: 2,
+				Hlc:       3,
+			}},
+			expReq: &pb.UserCreateRequest{User: &pbModels.User{
+				Name:      "ivan",
+				Password:  " secret ",
+				Email:     "ivan@example.com",
+				FullName:  "Ivan Ivanov",
+				CreatedAt: now.Unix(),
+			}},
+		},
+		{
+			name:   "imported user keeps creation time",
+			req:    &pb.UserImportRequest{User: &pbModels.User{Name: "ivan ", CreatedAt: 1, PasswordChangedAt: 2}},
+			expReq: &pb.UserImportRequest{User: &pbModels.User{Name: "ivan", CreatedAt: 1}},
+		},
+		{
+			name:   "imported user without creation time",
+			req:    &pb.UserImportRequest{User: &pbModels.User{Name: "ivan"}},
+			expReq: &pb.UserImportRequest{User: &pbModels.User{Name: "ivan", CreatedAt: now.Unix()}},
+		},
+		{
+			name: "profile",
+			req: &pb.UserUpdateRequest{Name: "\tivan\n", Profile: &pbModels.Profile{
+				Password: proto.String(" secret "),
+				Email:    proto.String(email),
+				FullName: proto.String(fullName),
+			}},
+			expReq: &pb.UserUpdateRequest{Name: "ivan", Profile: &pbModels.Profile{
+				Password: proto.String(" secret "),
+				Email:    proto.String("ivan@example.com"),
+				FullName: proto.String("Ivan Ivanov"),
+			}},
+		},
+		{
+			name:   "unset profile fields",
+			req:    &pb.MeUpdateRequest{Profile: &pbModels.Profile{}},
+			expReq: &pb.MeUpdateRequest{Profile: &pbModels.Profile{}},
+		},
+		{
+			name:   "rename",
+			req:    &pb.UserRenameRequest{Name: " ivan", NewName: "petr "},
+			expReq: &pb.UserRenameRequest{Name: "ivan", NewName: "petr"},
+		},
+		{
+			name:   "default page size",
+			req:    &pb.UserListRequest{Status: " Active "},
+			expReq: &pb.UserListRequest{Status: "active", Limit: defaultPageSize},
+		},
+		{
+			name:   "page size is kept",
+			req:    &pb.UserAllListRequest{Limit: 5},
+			expReq: &pb.UserAllListRequest{Limit: 5},
+		},
+		{
+			name:   "password check keeps password",
+			req:    &pb.UserCheckPasswordRequest{Name: " ivan ", Password: " secret "},
+			expReq: &pb.UserCheckPasswordRequest{Name: "ivan", Password: " secret "},
+		},
+		{
+			name:   "other requests are kept",
+			req:    &pb.DataRequest{Uid: " uid "},
+			expReq: &pb.DataRequest{Uid: " uid "},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			normalizer.Normalize(c.req)
+			assert.True(t, proto.Equal(c.expReq, c.req), "%v", c.req)
+		})
+	}
+}
+
+func TestNormalizeUnaryInterceptor(t *testing.T) {
+	interceptor := NormalizeUnaryInterceptor(NewNormalizer(NormalizeConfig{DefaultLimit: 10}, clock.Real()))
+
+	var handled interface{}
+	_, err := interceptor(context.Background(), &pb.UserAllListRequest{}, &grpc.UnaryServerInfo{},
+		func(_ context.Context, req interface{}) (interface{}, error) {
+			handled = req
+			return nil, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), handled.(*pb.UserAllListRequest).GetLimit())
+}
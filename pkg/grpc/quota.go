@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+const (
+	// AdminMetaKey carries the admin token, requests with the valid one are not limited by quotas.
+	AdminMetaKey = "admin-token"
+	// ListMaxRowsMetaKey is set in header metadata of UserList requests, which limit was lowered.
+	ListMaxRowsMetaKey = "list-max-rows"
+)
+
+// ListQuotaConfig limits rows of a UserList page requested by non-admin callers.
+type ListQuotaConfig struct {
+	// MaxRows is a maximal page size, 0 disables the quota.
+	MaxRows uint64 `mapstructure:"max_rows"`
+	// AdminToken overrides the quota, empty value disables the override.
+	AdminToken string `mapstructure:"admin_token"`
+}
+
+// ListQuotaUnaryInterceptor lowers limit of UserList requests exceeding the quota,
+// so non-admin callers have to paginate.
+func ListQuotaUnaryInterceptor(cfg ListQuotaConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if in, ok := req.(*pb.UserListRequest); ok {
+			limitList(ctx, cfg, in)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func limitList(ctx context.Context, cfg ListQuotaConfig, in *pb.UserListRequest) {
+	if cfg.MaxRows == 0 || in.GetLimit() <= cfg.MaxRows || trusted(ctx, AdminMetaKey, cfg.AdminToken) {
+		return
+	}
+	in.Limit = cfg.MaxRows
+	counter.ListTruncated.Inc()
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ListMaxRowsMetaKey, strconv.FormatUint(cfg.MaxRows, 10)))
+}
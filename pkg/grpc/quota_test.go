@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+func TestListQuotaUnaryInterceptor(t *testing.T) {
+	quota := ListQuotaConfig{MaxRows: 100, AdminToken: "secret"}
+
+	cases := []struct {
+		name       string
+		cfg        ListQuotaConfig
+		limit      uint64
+		reqToken   string
+		expLimit   uint64
+		expLowered bool
+	}{
+		{
+			name:     "limit within the quota",
+			cfg:      quota,
+			limit:    100,
+			expLimit: 100,
+		},
+		{
+			name:       "limit lowered",
+			cfg:        quota,
+			limit:      100000,
+			expLimit:   100,
+			expLowered: true,
+		},
+		{
+			name:       "limit lowered, wrong admin token",
+			cfg:        quota,
+			limit:      100000,
+			reqToken:   "guess",
+			expLimit:   100,
+			expLowered: true,
+		},
+		{
+			name:     "admin override",
+			cfg:      quota,
+			limit:    100000,
+			reqToken: "secret",
+			expLimit: 100000,
+		},
+		{
+			name:     "quota disabled",
+			limit:    100000,
+			expLimit: 100000,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &stream{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), s)
+			if c.reqToken != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(AdminMetaKey, c.reqToken))
+			}
+			truncated := counter.ListTruncated.Value()
+
+			req := &pb.UserListRequest{Limit: c.limit}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			}
+			resp, err := ListQuotaUnaryInterceptor(c.cfg)(ctx, req, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			assert.Equal(t, c.expLimit, resp.(*pb.UserListRequest).GetLimit())
+
+			if !c.expLowered {
+				assert.Empty(t, s.header)
+				assert.Equal(t, truncated, counter.ListTruncated.Value())
+				return
+			}
+			assert.Equal(t, []string{"100"}, s.header.Get(ListMaxRowsMetaKey))
+			assert.Equal(t, truncated+1, counter.ListTruncated.Value())
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/apperr"
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const defaultRateLimitRate = 50
+
+// RateLimitConfig limits calls of every client address by the token bucket.
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Rate is calls per second of a client, default is 50.
+	Rate float64 `mapstructure:"rate"`
+	// Burst is calls allowed at once, default is twice the rate.
+	Burst int `mapstructure:"burst"`
+	// AdminToken exempts callers with the admin metadata of the token, empty value disables the override.
+	AdminToken string `mapstructure:"admin_token"`
+}
+
+// RateLimiter keeps token buckets of clients. Clients are told by the address: the peer of gRPC calls,
+// the last address of x-forwarded-for set by the HTTP gateway, since the gateway is the peer of its calls.
+// Buckets are kept in memory, so every instance limits its own calls.
+type RateLimiter struct {
+	cfg   RateLimitConfig
+	clock clock.Clock
+	// refill is the time, in which an empty bucket gets full
+	refill time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	nextPrune time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// NewRateLimiter returns the limiter of the config, defaults are set to zero values.
+func NewRateLimiter(cfg RateLimitConfig, clk clock.Clock) *RateLimiter {
+	if cfg.Rate <= 0 {
+		cfg.Rate = defaultRateLimitRate
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = int(2 * cfg.Rate)
+		if cfg.Burst == 0 {
+			cfg.Burst = 1
+		}
+	}
+	return &RateLimiter{
+		cfg:     cfg,
+		clock:   clk,
+		refill:  time.Duration(float64(cfg.Burst) / cfg.Rate * float64(time.Second)),
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// Allow takes a token of the client, if there is none, the delay until the next one is returned.
+func (l *RateLimiter) Allow(client string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.prune(now)
+
+	burst := float64(l.cfg.Burst)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &rateBucket{tokens: burst, at: now}
+		l.buckets[client] = b
+	}
+	b.tokens += now.Sub(b.at).Seconds() * l.cfg.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.at = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / l.cfg.Rate * float64(time.Second)), false
+}
+
+// prune drops buckets full again once a refill time, it is called under the lock.
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Before(l.nextPrune) {
+		return
+	}
+	l.nextPrune = now.Add(l.refill)
+	for client, b := range l.buckets {
+		if now.Sub(b.at) >= l.refill {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// check returns ResourceExhausted with reason RATE_LIMITED and RetryInfo of the delay,
+// if the client has run out of calls.
+func (l *RateLimiter) check(ctx context.Context, method string) error {
+	if trusted(ctx, AdminMetaKey, l.cfg.AdminToken) {
+		return nil
+	}
+	delay, ok := l.Allow(clientAddr(ctx))
+	if ok {
+		return nil
+	}
+	counter.RateLimited.Inc(method)
+	return apperr.StatusReason(codes.ResourceExhausted, apperr.ReasonRateLimited,
+		apperr.WithRetryAfter(errors.New("rate limit of the client is exceeded"), delay))
+}
+
+// RateLimitUnaryInterceptor rejects calls of clients over the rate limit.
+func RateLimitUnaryInterceptor(l *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := l.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor rejects streaming calls of clients over the rate limit,
+// a stream takes one token, whatever the number of its messages is.
+func RateLimitStreamInterceptor(l *RateLimiter) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := l.check(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// clientAddr returns the host of the client, calls of the gateway have no peer, but x-forwarded-for.
+func clientAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if forwarded := md.Get("x-forwarded-for"); len(forwarded) != 0 {
+		// the gateway appends the remote address to addresses sent by the client
+		addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+		return strings.TrimSpace(addrs[len(addrs)-1])
+	}
+	return ""
+}
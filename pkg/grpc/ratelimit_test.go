@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	clk := clock.NewFake(time.Unix(100, 0))
+	limiter := NewRateLimiter(RateLimitConfig{Rate: 2, Burst: 2}, clk)
+
+	type call struct {
+		client   string
+		advance  time.Duration
+		expDelay time.Duration
+		expOk    bool
+	}
+	calls := []call{
+		{client: "10.0.0.1", expOk: true},
+		{client: "10.0.0.1", expOk: true},
+		{client: "10.0.0.1", expDelay: 500 * time.Millisecond},
+		{client: "10.0.0.2", expOk: true},
+		{client: "10.0.0.1", advance: 250 * time.Millisecond, expDelay: 250 * time.Millisecond},
+		{client: "10.0.0.1", advance: 250 * time.Millisecond, expOk: true},
+		{client: "10.0.0.1", expDelay: 500 * time.Millisecond},
+	}
+	for i, c := range calls {
+		clk.Advance(c.advance)
+		delay, ok := limiter.Allow(c.client)
+		assert.Equal(t, c.expOk, ok, "call %d", i)
+		assert.Equal(t, c.expDelay, delay, "call %d", i)
+	}
+
+	// buckets full again are forgotten
+	clk.Advance(time.Second)
+	limiter.Allow("10.0.0.3")
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestClientAddr(t *testing.T) {
+	cases := []struct {
+		name string
+		ctx  context.Context
+		exp  string
+	}{
+		{
+			name: "peer",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", "10.0.0.9")),
+				&peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}}),
+			exp: "10.0.0.1",
+		},
+		{
+			name: "the gateway appends the remote address",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", "10.0.0.9, 10.0.0.2")),
+			exp:  "10.0.0.2",
+		},
+		{
+			name: "unknown",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, clientAddr(c.ctx))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"path"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+)
+
+// RecoveryUnaryInterceptor turns panics of handlers into Internal errors, so a failed call
+// never takes the service down. The panic is logged with the stack and the request ID.
+func RecoveryUnaryInterceptor(logger *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor is RecoveryUnaryInterceptor of streams.
+func RecoveryStreamInterceptor(logger *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, logger *zap.SugaredLogger, method string, r interface{}) error {
+	counter.Panics.Inc(path.Base(method))
+	logger.Errorw("handler panicked", "method", method, "request_id", RequestIDFromContext(ctx),
+		"panic", r, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
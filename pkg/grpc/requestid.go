@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIDMetaKey carries the ID of the request, it is returned in header metadata of the response.
+	RequestIDMetaKey = "x-request-id"
+	// RequestIDHeader is the HTTP header of the request ID.
+	RequestIDHeader = "X-Request-Id"
+
+	// maxRequestID limits IDs of callers, since they are logged
+	maxRequestID = 128
+)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID set by the interceptors, it is empty out of them.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDUnaryInterceptor keeps the request ID of the caller in the context of the request,
+// requests without a valid one get a new ID. The ID is returned in header metadata.
+func RequestIDUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	id := incomingRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetaKey, id))
+	return handler(context.WithValue(ctx, requestIDKey{}, id), req)
+}
+
+// RequestIDStreamInterceptor is RequestIDUnaryInterceptor of streams.
+func RequestIDStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	id := incomingRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(RequestIDMetaKey, id))
+	return handler(srv, &contextStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), requestIDKey{}, id)})
+}
+
+// RequestIDHandler passes X-Request-Id of HTTP requests as the metadata, requests without a valid one
+// get a new ID. The ID is returned in X-Request-Id of the response, so the gateway and handlers
+// out of it answer alike.
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		r.Header.Set(runtime.MetadataHeaderPrefix+RequestIDMetaKey, id)
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OutgoingHeaderMatcher is the gateway matcher of header metadata, the request ID is already
+// returned by RequestIDHandler, other metadata gets Grpc-Metadata- prefix as by default.
+func OutgoingHeaderMatcher(key string) (string, bool) {
+	if key == RequestIDMetaKey {
+		return "", false
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(RequestIDMetaKey); len(ids) != 0 && validRequestID(ids[0]) {
+		return ids[0]
+	}
+	return uuid.New().String()
+}
+
+// validRequestID accepts printable ASCII IDs of limited length.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
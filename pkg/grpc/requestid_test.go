@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	cases := []struct {
+		name   string
+		ids    []string
+		expNew bool
+	}{
+		{
+			name: "ID of the caller",
+			ids:  []string{"req-1"},
+		},
+		{
+			name:   "no ID",
+			expNew: true,
+		},
+		{
+			name:   "not printable",
+			ids:    []string{"req 1\n"},
+			expNew: true,
+		},
+		{
+			name:   "too long",
+			ids:    []string{strings.Repeat("a", maxRequestID+1)},
+			expNew: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			md := metadata.MD{}
+			md.Set(RequestIDMetaKey, c.ids...)
+			var id string
+			_, err := RequestIDUnaryInterceptor(metadata.NewIncomingContext(context.Background(), md), nil,
+				&grpc.UnaryServerInfo{FullMethod: userService + "UserGet"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					id = RequestIDFromContext(ctx)
+					return nil, nil
+				})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, id)
+			if !c.expNew {
+				assert.Equal(t, c.ids[0], id)
+			} else if len(c.ids) != 0 {
+				assert.NotEqual(t, c.ids[0], id)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+)
+
+type throttledServer struct {
+	pb.UnimplementedUserServer
+	err error
+}
+
+func (s *throttledServer) Data(context.Context, *pb.DataRequest) (*pb.DataResponse, error) {
+	return nil, s.err
+}
+
+func throttled(t *testing.T, delay time.Duration) error {
+	st, err := status.New(codes.ResourceExhausted, "too many attempts").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	require.NoError(t, err)
+	return st.Err()
+}
+
+func TestRetryAfterErrorHandler(t *testing.T) {
+	cases := []struct {
+		name          string
+		err           error
+		expCode       int
+		expRetryAfter string
+	}{
+		{
+			name:          "throttled, rounded up",
+			err:           throttled(t, 1500*time.Millisecond),
+			expCode:       http.StatusTooManyRequests,
+			expRetryAfter: "2",
+		},
+		{
+			name:    "without retry info",
+			err:     status.Error(codes.NotFound, "key is incorrect or data in not ready yet"),
+			expCode: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gwMux := runtime.NewServeMux(runtime.WithErrorHandler(RetryAfterErrorHandler))
+			require.NoError(t, pb.RegisterUserHandlerServer(context.Background(), gwMux, &throttledServer{err: c.err}))
+
+			w := httptest.NewRecorder()
+			gwMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/data?uid=uid-1", nil))
+			assert.Equal(t, c.expCode, w.Code)
+			assert.Equal(t, c.expRetryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	delay, ok := RetryDelay(throttled(t, time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, delay)
+
+	_, ok = RetryDelay(status.Error(codes.ResourceExhausted, "too many attempts"))
+	assert.False(t, ok)
+}
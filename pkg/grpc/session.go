@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// SessionMetaKey carries the session token issued by UserLoginExternal. The HTTP gateway passes
+// the Authorization header under the same key.
+const SessionMetaKey = "authorization"
+
+const bearerPrefix = "bearer "
+
+// SessionFromContext returns the session token of the incoming request without "Bearer " prefix,
+// it is empty, if the token is not passed.
+func SessionFromContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(SessionMetaKey)
+	if len(tokens) == 0 {
+		return ""
+	}
+	token := strings.TrimSpace(tokens[0])
+	if len(token) >= len(bearerPrefix) && strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+		token = strings.TrimSpace(token[len(bearerPrefix):])
+	}
+	return token
+}
+
+// SessionForwardUnaryInterceptor passes the session token of the incoming request to the upstream,
+// which authenticates the caller.
+func SessionForwardUnaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if token := SessionFromContext(ctx); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, SessionMetaKey, token)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
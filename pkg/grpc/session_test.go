@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestSessionForwardUnaryInterceptor(t *testing.T) {
+	cases := []struct {
+		name     string
+		md       metadata.MD
+		expToken string
+	}{
+		{name: "no token"},
+		{name: "token", md: metadata.Pairs(SessionMetaKey, "abc"), expToken: "abc"},
+		{name: "bearer token", md: metadata.Pairs(SessionMetaKey, "Bearer abc"), expToken: "abc"},
+		{name: "lowercase bearer token", md: metadata.Pairs(SessionMetaKey, "bearer  abc "), expToken: "abc"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), c.md)
+			assert.Equal(t, c.expToken, SessionFromContext(ctx))
+
+			var forwarded []string
+			err := SessionForwardUnaryInterceptor(ctx, "/api.User/MeGet", nil, nil, nil,
+				func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					md, _ := metadata.FromOutgoingContext(ctx)
+					forwarded = md.Get(SessionMetaKey)
+					return nil
+				})
+			require.NoError(t, err)
+			if c.expToken == "" {
+				assert.Empty(t, forwarded)
+			} else {
+				assert.Equal(t, []string{c.expToken}, forwarded)
+			}
+		})
+	}
+}
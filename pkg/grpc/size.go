@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"gitlab.ozon.dev/iTukaev/homework/internal/counter"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+// defaultMaxResponseBytes leaves room for other fields under the 4MiB default message limit of gRPC.
+const defaultMaxResponseBytes = 1 << 20
+
+// ResponseSizeConfig caps list responses by the serialized size of their users,
+// so a few users with huge attributes don't produce oversized messages.
+type ResponseSizeConfig struct {
+	// MaxBytes of users in one response, default is 1MiB.
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+// FitUsers returns the number of leading users, which serialized size fits the cap.
+// The first user is always kept, so a list with a user larger than the cap goes on.
+func FitUsers(users []*pbModels.User, cfg ResponseSizeConfig) int {
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxResponseBytes
+	}
+	size := 0
+	for i, user := range users {
+		// users are a repeated field, every one is prefixed by the tag and its length
+		size += protowire.SizeTag(1) + protowire.SizeBytes(proto.Size(user))
+		if size > maxBytes && i != 0 {
+			counter.ResponsesCapped.Inc()
+			return i
+		}
+	}
+	return len(users)
+}
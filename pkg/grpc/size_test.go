@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+func TestFitUsers(t *testing.T) {
+	user := func(attribute int) *pbModels.User {
+		return &pbModels.User{Name: "ivan", Attributes: map[string]string{"bio": strings.Repeat("a", attribute)}}
+	}
+	small := proto.Size(&pb.UserListAtResponse{Users: []*pbModels.User{user(10)}})
+
+	cases := []struct {
+		name  string
+		users []*pbModels.User
+		cfg   ResponseSizeConfig
+		exp   int
+	}{
+		{
+			name: "empty",
+			exp:  0,
+		},
+		{
+			name:  "default cap",
+			users: []*pbModels.User{user(10), user(10)},
+			exp:   2,
+		},
+		{
+			name:  "exact cap",
+			users: []*pbModels.User{user(10), user(10)},
+			cfg:   ResponseSizeConfig{MaxBytes: 2 * small},
+			exp:   2,
+		},
+		{
+			name:  "cut after huge user",
+			users: []*pbModels.User{user(10), user(1000), user(10)},
+			cfg:   ResponseSizeConfig{MaxBytes: 500},
+			exp:   1,
+		},
+		{
+			name:  "first user over cap is kept",
+			users: []*pbModels.User{user(1000), user(10)},
+			cfg:   ResponseSizeConfig{MaxBytes: 500},
+			exp:   1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, FitUsers(c.users, c.cfg))
+		})
+	}
+}
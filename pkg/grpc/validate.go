@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/api/validate"
+)
+
+// ValidateUnaryInterceptor rejects requests violating validate.rules of their fields
+// with InvalidArgument before the handler is called.
+func ValidateUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := validateMessage(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidateStreamInterceptor validates every received message of the stream,
+// the handler gets the InvalidArgument error from RecvMsg.
+func ValidateStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validateStream{ServerStream: ss})
+	}
+}
+
+type validateStream struct {
+	grpc.ServerStream
+}
+
+func (s *validateStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateMessage(m)
+}
+
+func validateMessage(m interface{}) error {
+	v, ok := m.(validate.Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return validate.Status(err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	pbModels "gitlab.ozon.dev/iTukaev/homework/pkg/api/models"
+)
+
+func TestValidateUnaryInterceptor(t *testing.T) {
+	interceptor := ValidateUnaryInterceptor()
+	user := func() *pbModels.User {
+		return &pbModels.User{Name: "ivan", Password: "secret", Email: "ivan@example.com", FullName: "Ivan Ivanov"}
+	}
+	long := strings.Repeat("я", 257)
+
+	cases := []struct {
+		name     string
+		req      interface{}
+		expField string
+	}{
+		{
+			name: "valid request",
+			req:  &pb.UserCreateRequest{User: user()},
+		},
+		{
+			name: "request without rules",
+			req:  &pb.UserAllListRequest{},
+		},
+		{
+			name: "not a message",
+			req:  nil,
+		},
+		{
+			name:     "required message",
+			req:      &pb.UserCreateRequest{},
+			expField: "user",
+		},
+		{
+			name: "nested field",
+			req: &pb.UserCreateRequest{User: func() *pbModels.User {
+				u := user()
+				u.Email = ""
+				return u
+			}()},
+			expField: "user.email",
+		},
+		{
+			name:     "required string",
+			req:      &pb.UserGetRequest{},
+			expField: "name",
+		},
+		{
+			name:     "max length in characters",
+			req:      &pb.UserRenameRequest{Name: "ivan", NewName: long},
+			expField: "new_name",
+		},
+		{
+			name: "max length of unset optional",
+			req:  &pb.UserUpdateRequest{Name: "ivan", Profile: &pbModels.Profile{}},
+		},
+		{
+			name:     "max length of set optional",
+			req:      &pb.UserUpdateRequest{Name: "ivan", Profile: &pbModels.Profile{FullName: &long}},
+			expField: "profile.full_name",
+		},
+		{
+			name:     "pattern",
+			req:      &pb.UserListRequest{Status: "deleted"},
+			expField: "status",
+		},
+		{
+			name: "empty string skips pattern",
+			req:  &pb.UserListRequest{},
+		},
+		{
+			name:     "max items",
+			req:      &pb.UserListRequest{Attributes: attributes(33)},
+			expField: "attributes",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			called := false
+			_, err := interceptor(context.Background(), c.req, &grpc.UnaryServerInfo{},
+				func(context.Context, interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+			if c.expField == "" {
+				assert.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+			assert.False(t, called)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			st, _ := status.FromError(err)
+			require.Len(t, st.Details(), 1)
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Len(t, badRequest.GetFieldViolations(), 1)
+			assert.Equal(t, c.expField, badRequest.GetFieldViolations()[0].GetField())
+		})
+	}
+}
+
+func TestValidateUserServer(t *testing.T) {
+	server := pb.ValidateUserServer(&pb.UnimplementedUserServer{})
+
+	_, err := server.UserGetById(context.Background(), &pb.UserGetByIdRequest{Id: "42"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.UserGetById(context.Background(), &pb.UserGetByIdRequest{Id: "0b5e7c1e-6d4f-4c0a-9d2b-3f8a1e2c4b6d"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func attributes(n int) map[string]string {
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		m[strings.Repeat("a", i+1)] = "v"
+	}
+	return m
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 
 	"github.com/Shopify/sarama"
+
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
 )
 
 const (
 	uidKey = "uid"
 	pubKey = "pub"
+
+	// metaKey and actAsKey carry the identity of the caller, so changes made by consumers are
+	// recorded with it. actAsKey is set only, if the caller acts as another identity.
+	metaKey  = "meta"
+	actAsKey = grpcPkg.ActAsMetaKey
 )
 
 func InjectUidPubToCtx(ctx context.Context, uid, pub string) context.Context {
@@ -23,6 +30,24 @@ func ExtractUidPubFromCtx(ctx context.Context) (uid, pub string) {
 	return uid, pub
 }
 
+// InjectIdentityFromMessage returns the context of the identity received with the message,
+// the context is returned as is, if the message has no identity.
+func InjectIdentityFromMessage(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	var identity grpcPkg.Identity
+	for _, header := range msg.Headers {
+		switch string(header.Key) {
+		case metaKey:
+			identity.Real = string(header.Value)
+		case actAsKey:
+			identity.Effective = string(header.Value)
+		}
+	}
+	if identity.Real == "" {
+		return ctx
+	}
+	return grpcPkg.WithIdentity(ctx, identity)
+}
+
 func ExtractUidPubFromMessage(msg *sarama.ConsumerMessage) (uid, pub string) {
 	for _, header := range msg.Headers {
 		switch string(header.Key) {
@@ -6,6 +6,8 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
+
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
 )
 
 func StartNewSpan(ctx context.Context, name string, stop chan struct{}) {
@@ -24,9 +26,14 @@ func StartNewSpan(ctx context.Context, name string, stop chan struct{}) {
 func InjectHeaders(ctx context.Context, msg *sarama.ProducerMessage) error {
 	span := opentracing.SpanFromContext(ctx)
 	uid, pub := ExtractUidPubFromCtx(ctx)
+	identity := grpcPkg.IdentityFromContext(ctx)
 	headers := map[string]string{
-		uidKey: uid,
-		pubKey: pub,
+		uidKey:  uid,
+		pubKey:  pub,
+		metaKey: identity.Real,
+	}
+	if identity.Impersonated() {
+		headers[actAsKey] = identity.Effective
 	}
 
 	if err := opentracing.GlobalTracer().Inject(
@@ -0,0 +1,69 @@
+// Package hlc implements hybrid logical clocks. Timestamps are close to the physical time,
+// grow monotonically and stay ahead of timestamps observed from other nodes, so they order
+// causally related events of different nodes, even if their clocks are skewed.
+package hlc
+
+import (
+	"sync"
+	"time"
+)
+
+// logicalBits are low bits of the timestamp keeping the logical counter,
+// high bits keep the physical time in milliseconds.
+const (
+	logicalBits = 16
+	logicalMask = 1<<logicalBits - 1
+)
+
+// Timestamp packs the physical time in milliseconds and the logical counter.
+// Zero timestamp is older than any other.
+type Timestamp uint64
+
+// Physical returns the physical part of the timestamp.
+func (t Timestamp) Physical() time.Time {
+	return time.UnixMilli(int64(t >> logicalBits))
+}
+
+// Logical returns the counter of timestamps within the same millisecond.
+func (t Timestamp) Logical() uint16 {
+	return uint16(t & logicalMask)
+}
+
+type Clock struct {
+	mu   sync.Mutex
+	last Timestamp
+	now  func() time.Time
+}
+
+func New() *Clock {
+	return &Clock{now: time.Now}
+}
+
+// Now returns a timestamp greater than all returned and observed ones.
+func (c *Clock) Now() Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.advance(0)
+}
+
+// Update observes the timestamp of another node and returns a timestamp greater than it.
+func (c *Clock) Update(remote Timestamp) Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.advance(remote)
+}
+
+func (c *Clock) advance(remote Timestamp) Timestamp {
+	last := c.last
+	if remote > last {
+		last = remote
+	}
+	physical := Timestamp(c.now().UnixMilli()) << logicalBits
+	if physical > last {
+		c.last = physical
+	} else {
+		// the logical counter overflows to the next millisecond, it is ahead of the physical time a bit then
+		c.last = last + 1
+	}
+	return c.last
+}
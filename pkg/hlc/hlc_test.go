@@ -0,0 +1,33 @@
+package hlc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	now := time.UnixMilli(1660000000000)
+	clock := &Clock{now: func() time.Time { return now }}
+
+	first := clock.Now()
+	assert.Equal(t, now, first.Physical())
+	assert.Equal(t, uint16(0), first.Logical())
+
+	second := clock.Now()
+	assert.Equal(t, now, second.Physical())
+	assert.Equal(t, uint16(1), second.Logical())
+
+	// the remote clock is ahead
+	remote := Timestamp(now.Add(time.Second).UnixMilli())<<logicalBits + 5
+	updated := clock.Update(remote)
+	assert.Equal(t, remote+1, updated)
+	assert.Greater(t, clock.Now(), updated)
+
+	// the remote clock is behind
+	assert.Greater(t, clock.Update(first), updated)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, Timestamp(now.UnixMilli())<<logicalBits, clock.Now())
+}
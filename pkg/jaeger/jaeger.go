@@ -1,6 +1,7 @@
 package jaeger
 
 import (
+	"context"
 	"io"
 
 	"github.com/opentracing/opentracing-go"
@@ -8,9 +9,12 @@ import (
 	"github.com/uber/jaeger-client-go/config"
 )
 
+// TraceContextHeader carries the span context in text maps, e.g. Kafka message headers.
+const TraceContextHeader = "uber-trace-id"
+
 func New(service, host string) (opentracing.Tracer, io.Closer, error) {
 	headerCfg := jaeger.HeadersConfig{
-		TraceContextHeaderName: "uber-trace-id",
+		TraceContextHeaderName: TraceContextHeader,
 	}
 	cfg := &config.Configuration{
 		ServiceName: service,
@@ -32,3 +36,43 @@ func New(service, host string) (opentracing.Tracer, io.Closer, error) {
 		config.Extractor(opentracing.TextMap, textPropagator),
 	)
 }
+
+// TraceID returns ID of the trace of the span in ctx, ok is false for untraced calls.
+func TraceID(ctx context.Context) (string, bool) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return "", false
+	}
+	spanCtx, ok := span.Context().(jaeger.SpanContext)
+	if !ok {
+		return "", false
+	}
+	return spanCtx.TraceID().String(), true
+}
+
+// SampledTraceID returns ID of the trace of the span in ctx, if the trace is reported,
+// so it is found in Jaeger, e.g. by an exemplar of a latency histogram.
+func SampledTraceID(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return sampled(span.Context())
+}
+
+// SampledTraceIDFromHeader returns ID of the sampled trace of TraceContextHeader value.
+func SampledTraceIDFromHeader(value string) string {
+	spanCtx, err := jaeger.ContextFromString(value)
+	if err != nil {
+		return ""
+	}
+	return sampled(spanCtx)
+}
+
+func sampled(ctx opentracing.SpanContext) string {
+	spanCtx, ok := ctx.(jaeger.SpanContext)
+	if !ok || !spanCtx.IsSampled() {
+		return ""
+	}
+	return spanCtx.TraceID().String()
+}
@@ -0,0 +1,77 @@
+package keymutex
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+)
+
+const defaultStripes = 256
+
+// Striped serializes operations with the same key, operations with different keys
+// proceed in parallel unless their keys share a stripe.
+type Striped struct {
+	stripes []chan struct{}
+}
+
+// New returns mutex of n stripes, non-positive n means default count.
+func New(n int) *Striped {
+	if n <= 0 {
+		n = defaultStripes
+	}
+	s := &Striped{
+		stripes: make([]chan struct{}, n),
+	}
+	for i := range s.stripes {
+		s.stripes[i] = make(chan struct{}, 1)
+	}
+	return s
+}
+
+// Lock blocks until the key is locked or ctx is done. Returned function unlocks the key.
+func (s *Striped) Lock(ctx context.Context, key string) (func(), error) {
+	stripe := s.stripes[s.index(key)]
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case stripe <- struct{}{}:
+		return func() { <-stripe }, nil
+	}
+}
+
+// LockAll locks all the keys. Stripes are locked in ascending order,
+// so concurrent calls with overlapping keys don't deadlock.
+func (s *Striped) LockAll(ctx context.Context, keys ...string) (func(), error) {
+	indexes := make([]int, 0, len(keys))
+	seen := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		if i := s.index(key); !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	locked := make([]chan struct{}, 0, len(indexes))
+	unlock := func() {
+		for _, stripe := range locked {
+			<-stripe
+		}
+	}
+	for _, i := range indexes {
+		select {
+		case <-ctx.Done():
+			unlock()
+			return nil, ctx.Err()
+		case s.stripes[i] <- struct{}{}:
+			locked = append(locked, s.stripes[i])
+		}
+	}
+	return unlock, nil
+}
+
+func (s *Striped) index(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.stripes)))
+}
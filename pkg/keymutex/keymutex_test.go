@@ -0,0 +1,64 @@
+package keymutex
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStriped_Lock(t *testing.T) {
+	m := New(0)
+
+	var (
+		wg      sync.WaitGroup
+		counter int
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := m.Lock(context.Background(), "alice")
+			require.NoError(t, err)
+			defer unlock()
+			value := counter
+			time.Sleep(time.Microsecond)
+			counter = value + 1
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, counter)
+}
+
+func TestStriped_LockAll(t *testing.T) {
+	m := New(1)
+
+	// both keys share the only stripe, it is locked once
+	unlock, err := m.LockAll(context.Background(), "alice", "bob")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = m.LockAll(ctx, "bob", "alice")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	unlock, err = m.LockAll(context.Background(), "bob")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestStriped_LockTimeout(t *testing.T) {
+	m := New(1)
+	unlock, err := m.Lock(context.Background(), "alice")
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = m.Lock(ctx, "bob")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
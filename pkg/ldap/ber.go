@@ -0,0 +1,192 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// BER tags used by the protocol, see RFC 4511.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagBoolean     = 0x01
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	classApplication = 0x40
+	classContext     = 0x80
+	constructed      = 0x20
+
+	// maxLength limits size of the single message, entries of requested attributes are far smaller
+	maxLength = 8 << 20
+)
+
+var errMalformed = errors.New("malformed BER")
+
+// tlv encodes tag-length-value, content parts are concatenated.
+func tlv(tag byte, content ...[]byte) []byte {
+	size := 0
+	for _, c := range content {
+		size += len(c)
+	}
+	out := make([]byte, 0, size+6)
+	out = append(out, tag)
+	out = appendLength(out, size)
+	for _, c := range content {
+		out = append(out, c...)
+	}
+	return out
+}
+
+func appendLength(out []byte, size int) []byte {
+	if size < 0x80 {
+		return append(out, byte(size))
+	}
+	var buf []byte
+	for n := size; n > 0; n >>= 8 {
+		buf = append([]byte{byte(n)}, buf...)
+	}
+	out = append(out, 0x80|byte(len(buf)))
+	return append(out, buf...)
+}
+
+func integer(tag byte, v int64) []byte {
+	var buf []byte
+	for {
+		buf = append([]byte{byte(v)}, buf...)
+		v >>= 8
+		// minimal two's complement form
+		if (v == 0 && buf[0]&0x80 == 0) || (v == -1 && buf[0]&0x80 != 0) {
+			break
+		}
+	}
+	return tlv(tag, buf)
+}
+
+func octets(tag byte, s string) []byte {
+	return tlv(tag, []byte(s))
+}
+
+func boolean(v bool) []byte {
+	if v {
+		return tlv(tagBoolean, []byte{0xff})
+	}
+	return tlv(tagBoolean, []byte{0x00})
+}
+
+// element is a decoded TLV, content of constructed elements is parsed by children.
+type element struct {
+	tag     byte
+	content []byte
+}
+
+func (e element) children() ([]element, error) {
+	var (
+		out  []element
+		rest = e.content
+	)
+	for len(rest) != 0 {
+		var (
+			el  element
+			err error
+		)
+		if el, rest, err = parse(rest); err != nil {
+			return nil, err
+		}
+		out = append(out, el)
+	}
+	return out, nil
+}
+
+func (e element) int() int64 {
+	var v int64
+	for i, b := range e.content {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+func (e element) string() string {
+	return string(e.content)
+}
+
+func parse(data []byte) (element, []byte, error) {
+	if len(data) < 2 {
+		return element{}, nil, errMalformed
+	}
+	tag := data[0]
+	size, n, err := parseLength(data[1:])
+	if err != nil {
+		return element{}, nil, err
+	}
+	data = data[1+n:]
+	if size > len(data) {
+		return element{}, nil, errMalformed
+	}
+	return element{tag: tag, content: data[:size]}, data[size:], nil
+}
+
+func parseLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, errMalformed
+	}
+	if data[0] < 0x80 {
+		return int(data[0]), 1, nil
+	}
+	n := int(data[0] & 0x7f)
+	if n == 0 || n > 4 || len(data) < 1+n {
+		return 0, 0, errMalformed
+	}
+	size := 0
+	for _, b := range data[1 : 1+n] {
+		size = size<<8 | int(b)
+	}
+	if size > maxLength {
+		return 0, 0, errors.Wrapf(errMalformed, "length %d is too big", size)
+	}
+	return size, 1 + n, nil
+}
+
+// read reads the whole top level element from the stream.
+func read(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+	header := []byte{first}
+	if first >= 0x80 {
+		n := int(first & 0x7f)
+		if n == 0 || n > 4 {
+			return element{}, errMalformed
+		}
+		rest := make([]byte, n)
+		if _, err = io.ReadFull(r, rest); err != nil {
+			return element{}, err
+		}
+		header = append(header, rest...)
+	}
+	size, _, err := parseLength(header)
+	if err != nil {
+		return element{}, err
+	}
+	// the buffer grows with data actually received, so a forged length doesn't allocate it up front
+	var content bytes.Buffer
+	if _, err = io.CopyN(&content, r, int64(size)); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return element{}, err
+	}
+	return element{tag: tag, content: content.Bytes()}, nil
+}
@@ -0,0 +1,161 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Filter choices, see RFC 4511 4.5.1.
+const (
+	filterAnd       = classContext | constructed | 0
+	filterOr        = classContext | constructed | 1
+	filterNot       = classContext | constructed | 2
+	filterEquality  = classContext | constructed | 3
+	filterSubstring = classContext | constructed | 4
+	filterPresent   = classContext | 7
+
+	substringInitial = classContext | 0
+	substringAny     = classContext | 1
+	substringFinal   = classContext | 2
+)
+
+var ErrFilter = errors.New("invalid filter")
+
+// compileFilter encodes string filter of RFC 4515. And, or, not, equality,
+// presence and substrings are supported, approximate and ordering matches are not.
+func compileFilter(filter string) ([]byte, error) {
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	encoded, rest, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errors.Wrapf(ErrFilter, "unexpected [%s]", rest)
+	}
+	return encoded, nil
+}
+
+func parseFilter(s string) ([]byte, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", errors.Wrapf(ErrFilter, "[%s] must start with (", s)
+	}
+	s = s[1:]
+	if s == "" {
+		return nil, "", errors.Wrap(ErrFilter, "unexpected end")
+	}
+
+	var (
+		encoded []byte
+		err     error
+	)
+	switch s[0] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if s[0] == '|' {
+			tag = filterOr
+		}
+		s = s[1:]
+		var set [][]byte
+		for strings.HasPrefix(s, "(") {
+			var item []byte
+			if item, s, err = parseFilter(s); err != nil {
+				return nil, "", err
+			}
+			set = append(set, item)
+		}
+		if len(set) == 0 {
+			return nil, "", errors.Wrap(ErrFilter, "empty set")
+		}
+		encoded = tlv(tag, set...)
+	case '!':
+		var item []byte
+		if item, s, err = parseFilter(s[1:]); err != nil {
+			return nil, "", err
+		}
+		encoded = tlv(filterNot, item)
+	default:
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, "", errors.Wrap(ErrFilter, "missing )")
+		}
+		if encoded, err = parseItem(s[:end]); err != nil {
+			return nil, "", err
+		}
+		s = s[end:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", errors.Wrap(ErrFilter, "missing )")
+	}
+	return encoded, s[1:], nil
+}
+
+func parseItem(item string) ([]byte, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, errors.Wrapf(ErrFilter, "[%s] is not an assertion", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+	if strings.ContainsAny(attr[len(attr)-1:], "~<>:") {
+		return nil, errors.Wrapf(ErrFilter, "[%s] match is not supported", item)
+	}
+	if value == "*" {
+		return octets(filterPresent, attr), nil
+	}
+	if !strings.Contains(value, "*") {
+		v, err := unescape(value)
+		if err != nil {
+			return nil, err
+		}
+		return tlv(filterEquality, octets(tagOctetString, attr), octets(tagOctetString, v)), nil
+	}
+
+	parts := strings.Split(value, "*")
+	var subs [][]byte
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		v, err := unescape(part)
+		if err != nil {
+			return nil, err
+		}
+		tag := byte(substringAny)
+		switch i {
+		case 0:
+			tag = substringInitial
+		case len(parts) - 1:
+			tag = substringFinal
+		}
+		subs = append(subs, octets(tag, v))
+	}
+	return tlv(filterSubstring, octets(tagOctetString, attr), tlv(tagSequence, subs...)), nil
+}
+
+// unescape decodes \XX escapes of the assertion value.
+func unescape(value string) (string, error) {
+	if !strings.Contains(value, `\`) {
+		return value, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+3 > len(value) {
+			return "", errors.Wrapf(ErrFilter, "bad escape in [%s]", value)
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", errors.Wrapf(ErrFilter, "bad escape in [%s]", value)
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,362 @@
+// Package ldap is a minimal LDAPv3 client: simple bind and search only.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Protocol operations, see RFC 4511.
+const (
+	opBindRequest     = classApplication | constructed | 0
+	opBindResponse    = classApplication | constructed | 1
+	opUnbindRequest   = classApplication | 2
+	opSearchRequest   = classApplication | constructed | 3
+	opSearchEntry     = classApplication | constructed | 4
+	opSearchDone      = classApplication | constructed | 5
+	opSearchReference = classApplication | constructed | 19
+
+	authSimple = classContext | 0
+
+	scopeWholeSubtree = 2
+	derefNever        = 0
+
+	resultSuccess            = 0
+	resultInvalidCredentials = 49
+
+	// controls of LDAPMessage, see RFC 4511 section 4.1.11
+	tagControls = classContext | constructed | 0
+	// oidPagedResults is the simple paged results control, see RFC 2696
+	oidPagedResults = "1.2.840.113556.1.4.319"
+
+	// defaultPageSize keeps pages below size limits of directories, e.g. 1000 of Active Directory
+	defaultPageSize = 500
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Error is a non-success result of the operation.
+type Error struct {
+	Code    int64
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ldap result %d: %s", e.Code, e.Message)
+}
+
+// Entry is a found directory entry, attribute names are returned as the server sends them.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get returns the first value of the attribute, names are case-insensitive, e.g. "mail" gets "Mail".
+func (e Entry) Get(name string) string {
+	values, ok := e.Attributes[name]
+	if !ok {
+		for attr, v := range e.Attributes {
+			if strings.EqualFold(attr, name) {
+				values = v
+				break
+			}
+		}
+	}
+	if len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Conn is a connection to the directory, operations are run one by one.
+type Conn struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	id      int64
+	timeout time.Duration
+	// pageSize is a number of entries requested by one search request
+	pageSize int64
+}
+
+// Dial connects to addr, LDAPS is used with useTLS.
+func Dial(ctx context.Context, addr string, useTLS bool, timeout time.Duration) (*Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap dial")
+	}
+	return NewConn(conn, timeout), nil
+}
+
+// NewConn wraps established connection, zero timeout disables operation deadlines.
+func NewConn(conn net.Conn, timeout time.Duration) *Conn {
+	return &Conn{
+		conn:     conn,
+		reader:   bufio.NewReader(conn),
+		timeout:  timeout,
+		pageSize: defaultPageSize,
+	}
+}
+
+// Bind authenticates the connection by simple bind.
+func (c *Conn) Bind(dn, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, err := c.send(tlv(opBindRequest,
+		integer(tagInteger, 3),
+		octets(tagOctetString, dn),
+		octets(authSimple, password),
+	))
+	if err != nil {
+		return errors.Wrap(err, "ldap bind")
+	}
+	op, _, err := c.receive(id)
+	if err != nil {
+		return errors.Wrap(err, "ldap bind")
+	}
+	if op.tag != opBindResponse {
+		return errors.Wrapf(errMalformed, "ldap bind: unexpected response 0x%x", op.tag)
+	}
+	if err = result(op); err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Code == resultInvalidCredentials {
+			return errors.Wrap(ErrInvalidCredentials, e.Message)
+		}
+		return errors.Wrap(err, "ldap bind")
+	}
+	return nil
+}
+
+// Search returns entries of the base DN subtree matched by RFC 4515 filter. Entries are requested
+// by pages, so directories return more entries than their size limit.
+func (c *Conn) Search(baseDN, filter string, attributes []string) ([]Entry, error) {
+	encoded, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([][]byte, 0, len(attributes))
+	for _, attr := range attributes {
+		attrs = append(attrs, octets(tagOctetString, attr))
+	}
+	request := tlv(opSearchRequest,
+		octets(tagOctetString, baseDN),
+		integer(tagEnumerated, scopeWholeSubtree),
+		integer(tagEnumerated, derefNever),
+		integer(tagInteger, 0),
+		integer(tagInteger, 0),
+		boolean(false),
+		encoded,
+		tlv(tagSequence, attrs...),
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		entries []Entry
+		cookie  string
+	)
+	for {
+		var page []Entry
+		if page, cookie, err = c.searchPage(request, cookie); err != nil {
+			return nil, errors.Wrap(err, "ldap search")
+		}
+		entries = append(entries, page...)
+		// the empty cookie ends the search, servers without paging return everything at once
+		if cookie == "" {
+			return entries, nil
+		}
+	}
+}
+
+// searchPage sends the request with the paged results control and returns entries of the page
+// and the cookie of the next one.
+func (c *Conn) searchPage(request []byte, cookie string) ([]Entry, string, error) {
+	id, err := c.send(request, pagedResults(c.pageSize, cookie))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entries []Entry
+	for {
+		op, controls, err := c.receive(id)
+		if err != nil {
+			return nil, "", err
+		}
+		switch op.tag {
+		case opSearchEntry:
+			entry, err := parseEntry(op)
+			if err != nil {
+				return nil, "", err
+			}
+			entries = append(entries, entry)
+		case opSearchReference:
+			// referrals to other servers are not followed
+		case opSearchDone:
+			if err = result(op); err != nil {
+				return nil, "", err
+			}
+			next, err := pagedCookie(controls)
+			return entries, next, err
+		default:
+			return nil, "", errors.Wrapf(errMalformed, "unexpected response 0x%x", op.tag)
+		}
+	}
+}
+
+// Close sends unbind request and closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.send(tlv(opUnbindRequest))
+	return c.conn.Close()
+}
+
+func (c *Conn) send(op []byte, controls ...[]byte) (int64, error) {
+	c.id++
+	if c.timeout > 0 {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	msg := [][]byte{integer(tagInteger, c.id), op}
+	if len(controls) != 0 {
+		msg = append(msg, tlv(tagControls, controls...))
+	}
+	_, err := c.conn.Write(tlv(tagSequence, msg...))
+	return c.id, err
+}
+
+// receive reads the next message and returns its protocol operation and response controls.
+func (c *Conn) receive(id int64) (element, []element, error) {
+	msg, err := read(c.reader)
+	if err != nil {
+		return element{}, nil, err
+	}
+	if msg.tag != tagSequence {
+		return element{}, nil, errMalformed
+	}
+	parts, err := msg.children()
+	if err != nil {
+		return element{}, nil, err
+	}
+	if len(parts) < 2 || parts[0].tag != tagInteger {
+		return element{}, nil, errMalformed
+	}
+	if got := parts[0].int(); got != id {
+		return element{}, nil, errors.Wrapf(errMalformed, "message id %d, expected %d", got, id)
+	}
+	var controls []element
+	if len(parts) > 2 && parts[2].tag == tagControls {
+		if controls, err = parts[2].children(); err != nil {
+			return element{}, nil, err
+		}
+	}
+	return parts[1], controls, nil
+}
+
+// pagedResults encodes the paged results control, the empty cookie requests the first page.
+func pagedResults(size int64, cookie string) []byte {
+	value := tlv(tagSequence, integer(tagInteger, size), octets(tagOctetString, cookie))
+	return tlv(tagSequence, octets(tagOctetString, oidPagedResults), tlv(tagOctetString, value))
+}
+
+// pagedCookie returns the cookie of the paged results control, it is empty after the last page.
+func pagedCookie(controls []element) (string, error) {
+	for _, control := range controls {
+		fields, err := control.children()
+		if err != nil {
+			return "", err
+		}
+		if len(fields) == 0 || fields[0].string() != oidPagedResults {
+			continue
+		}
+		if len(fields) < 2 {
+			return "", errMalformed
+		}
+		// the value is the last field, criticality may precede it
+		value, _, err := parse(fields[len(fields)-1].content)
+		if err != nil {
+			return "", err
+		}
+		parts, err := value.children()
+		if err != nil {
+			return "", err
+		}
+		if len(parts) != 2 {
+			return "", errMalformed
+		}
+		return parts[1].string(), nil
+	}
+	return "", nil
+}
+
+func result(op element) error {
+	parts, err := op.children()
+	if err != nil {
+		return err
+	}
+	if len(parts) < 3 || parts[0].tag != tagEnumerated {
+		return errMalformed
+	}
+	if code := parts[0].int(); code != resultSuccess {
+		return &Error{Code: code, Message: parts[2].string()}
+	}
+	return nil
+}
+
+func parseEntry(op element) (Entry, error) {
+	parts, err := op.children()
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(parts) != 2 {
+		return Entry{}, errMalformed
+	}
+	entry := Entry{
+		DN:         parts[0].string(),
+		Attributes: make(map[string][]string),
+	}
+	attrs, err := parts[1].children()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, attr := range attrs {
+		pair, err := attr.children()
+		if err != nil {
+			return Entry{}, err
+		}
+		if len(pair) != 2 {
+			return Entry{}, errMalformed
+		}
+		values, err := pair[1].children()
+		if err != nil {
+			return Entry{}, err
+		}
+		name := pair[0].string()
+		for _, value := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], value.string())
+		}
+	}
+	return entry, nil
+}
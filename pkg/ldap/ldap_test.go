@@ -0,0 +1,178 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_compileFilter(t *testing.T) {
+	cases := []struct {
+		filter string
+		exp    string
+		expErr error
+	}{
+		{filter: "(cn=a)", exp: "a3070402636e040161"},
+		{filter: "(cn=*)", exp: "8702636e"},
+		{filter: "(&(a=b)(!(c=*)))", exp: "a00da306040161040162a203870163"},
+		{filter: "(cn=a*b)", exp: "a40c0402636e3006800161820162"},
+		{filter: `(cn=\28x\29)`, exp: "a3090402636e0403287829"},
+		{filter: "(cn>=a)", expErr: ErrFilter},
+		{filter: "(&(cn=a)", expErr: ErrFilter},
+		{filter: "cn=a", expErr: ErrFilter},
+	}
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			encoded, err := compileFilter(c.filter)
+			if c.expErr != nil {
+				assert.ErrorIs(t, err, c.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.exp, hex.EncodeToString(encoded))
+		})
+	}
+}
+
+func Test_read(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []byte
+		expErr error
+	}{
+		{name: "length above the limit", data: []byte{tagSequence, 0x84, 0x7f, 0xff, 0xff, 0xff}, expErr: errMalformed},
+		{name: "truncated content", data: []byte{tagSequence, 0x84, 0x00, 0x10, 0x00, 0x00, 0x02, 0x01}, expErr: io.ErrUnexpectedEOF},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := read(bufio.NewReader(bytes.NewReader(c.data)))
+			assert.ErrorIs(t, err, c.expErr)
+		})
+	}
+}
+
+func person(uid, mail string) []byte {
+	return tlv(opSearchEntry,
+		octets(tagOctetString, "uid="+uid+",ou=people,dc=example,dc=com"),
+		tlv(tagSequence,
+			tlv(tagSequence, octets(tagOctetString, "uid"), tlv(tagSet, octets(tagOctetString, uid))),
+			tlv(tagSequence, octets(tagOctetString, "Mail"),
+				tlv(tagSet, octets(tagOctetString, mail), octets(tagOctetString, uid[:1]+"@example.com"))),
+		),
+	)
+}
+
+// page returns entries of the page requested by the paged results control and the control of the next page.
+func page(t *testing.T, parts []element, entries [][]byte) ([][]byte, []byte) {
+	if len(parts) < 3 {
+		return entries, nil
+	}
+	controls, err := parts[2].children()
+	require.NoError(t, err)
+	fields, err := controls[0].children()
+	require.NoError(t, err)
+	require.Equal(t, oidPagedResults, fields[0].string())
+	value, _, err := parse(fields[1].content)
+	require.NoError(t, err)
+	request, err := value.children()
+	require.NoError(t, err)
+
+	offset := 0
+	if cookie := request[1].string(); cookie != "" {
+		offset, err = strconv.Atoi(cookie)
+		require.NoError(t, err)
+	}
+	end, next := offset+int(request[0].int()), ""
+	if end < len(entries) {
+		next = strconv.Itoa(end)
+	} else {
+		end = len(entries)
+	}
+	control := tlv(tagSequence, octets(tagOctetString, oidPagedResults),
+		tlv(tagOctetString, tlv(tagSequence, integer(tagInteger, 0), octets(tagOctetString, next))))
+	return entries[offset:end], tlv(tagControls, control)
+}
+
+// serve answers bind and search requests like a directory with two users, searches are paged.
+func serve(t *testing.T, conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	reply := func(id int64, ops ...[]byte) {
+		for _, op := range ops {
+			_, err := conn.Write(tlv(tagSequence, integer(tagInteger, id), op))
+			require.NoError(t, err)
+		}
+	}
+	replyDone := func(id int64, done, controls []byte) {
+		msg := [][]byte{integer(tagInteger, id), done}
+		if controls != nil {
+			msg = append(msg, controls)
+		}
+		_, err := conn.Write(tlv(tagSequence, msg...))
+		require.NoError(t, err)
+	}
+	success := func(tag byte) []byte {
+		return tlv(tag, integer(tagEnumerated, resultSuccess), octets(tagOctetString, ""), octets(tagOctetString, ""))
+	}
+
+	for {
+		msg, err := read(reader)
+		if err != nil {
+			return
+		}
+		parts, err := msg.children()
+		require.NoError(t, err)
+		id, op := parts[0].int(), parts[1]
+
+		switch op.tag {
+		case opBindRequest:
+			fields, err := op.children()
+			require.NoError(t, err)
+			if fields[2].string() != "secret" {
+				reply(id, tlv(opBindResponse, integer(tagEnumerated, resultInvalidCredentials),
+					octets(tagOctetString, ""), octets(tagOctetString, "bad password")))
+				continue
+			}
+			reply(id, success(opBindResponse))
+		case opSearchRequest:
+			entries, controls := page(t, parts, [][]byte{
+				person("ivan", "ivan@example.com"),
+				person("petr", "petr@example.com"),
+			})
+			reply(id, entries...)
+			replyDone(id, success(opSearchDone), controls)
+		case opUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestConn(t *testing.T) {
+	client, server := net.Pipe()
+	go serve(t, server)
+	conn := NewConn(client, 0)
+	defer conn.Close()
+
+	assert.ErrorIs(t, conn.Bind("cn=admin,dc=example,dc=com", "wrong"), ErrInvalidCredentials)
+	require.NoError(t, conn.Bind("cn=admin,dc=example,dc=com", "secret"))
+
+	for _, size := range []int64{1, 2, defaultPageSize} {
+		conn.pageSize = size
+		entries, err := conn.Search("dc=example,dc=com", "(objectClass=person)", []string{"uid", "mail"})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "uid=ivan,ou=people,dc=example,dc=com", entries[0].DN)
+		assert.Equal(t, "ivan", entries[0].Get("uid"))
+		assert.Equal(t, "ivan", entries[0].Get("UID"))
+		assert.Equal(t, "ivan@example.com", entries[0].Get("mail"))
+		assert.Equal(t, []string{"ivan@example.com", "i@example.com"}, entries[0].Attributes["Mail"])
+		assert.Equal(t, "", entries[0].Get("cn"))
+		assert.Equal(t, "petr", entries[1].Get("uid"))
+	}
+}
@@ -0,0 +1,164 @@
+package leader
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultKey = "leader"
+	defaultTTL = 15 * time.Second
+)
+
+// renewScript prolongs the lease, if it is still held by the instance.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript removes the lease, if it is still held by the instance.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Config of the leader election. If election is disabled, the instance is always a leader.
+type Config struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Key     string        `mapstructure:"key"`
+	TTL     time.Duration `mapstructure:"ttl"`
+}
+
+type Elector interface {
+	// Run blocks until ctx is done. fn is called every time the leadership is acquired,
+	// its context is cancelled, when the leadership is lost.
+	Run(ctx context.Context, fn func(ctx context.Context))
+	IsLeader() bool
+}
+
+// New returns elector based on redis lease. The lease is renewed every third of TTL,
+// so another instance takes the leadership in TTL after the leader has gone.
+func New(client *redis.Client, cfg Config, logger *zap.SugaredLogger) Elector {
+	if !cfg.Enabled {
+		return &single{}
+	}
+	if cfg.Key == "" {
+		cfg.Key = defaultKey
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	return &elector{
+		client: client,
+		cfg:    cfg,
+		id:     uuid.New().String(),
+		logger: logger,
+	}
+}
+
+type elector struct {
+	client *redis.Client
+	cfg    Config
+	id     string
+	logger *zap.SugaredLogger
+	leader int32
+}
+
+func (e *elector) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+func (e *elector) Run(ctx context.Context, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(e.cfg.TTL / 3)
+	defer ticker.Stop()
+
+	for {
+		ok, err := e.acquire(ctx)
+		if err != nil {
+			e.logger.Errorf("leader [%s]: acquire: %v", e.cfg.Key, err)
+		}
+		if ok {
+			e.lead(ctx, ticker, fn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs fn and renews the lease until it is lost or ctx is done.
+func (e *elector) lead(ctx context.Context, ticker *time.Ticker, fn func(ctx context.Context)) {
+	e.logger.Infof("leader [%s]: leadership acquired by [%s]", e.cfg.Key, e.id)
+	atomic.StoreInt32(&e.leader, 1)
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leadCtx)
+	}()
+	defer func() {
+		atomic.StoreInt32(&e.leader, 0)
+		cancel()
+		<-done
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.release(); err != nil {
+				e.logger.Errorf("leader [%s]: release: %v", e.cfg.Key, err)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := e.renew(ctx)
+		if err != nil {
+			e.logger.Errorf("leader [%s]: renew: %v", e.cfg.Key, err)
+		}
+		if !ok {
+			e.logger.Warnf("leader [%s]: leadership lost by [%s]", e.cfg.Key, e.id)
+			return
+		}
+	}
+}
+
+func (e *elector) acquire(ctx context.Context) (bool, error) {
+	ok, err := e.client.SetNX(ctx, e.cfg.Key, e.id, e.cfg.TTL).Result()
+	return ok, errors.Wrap(err, "set lease")
+}
+
+func (e *elector) renew(ctx context.Context) (bool, error) {
+	n, err := renewScript.Run(ctx, e.client, []string{e.cfg.Key}, e.id, e.cfg.TTL.Milliseconds()).Int()
+	return n == 1, errors.Wrap(err, "renew lease")
+}
+
+// release lets other instances take the leadership without waiting for the lease expiration.
+func (e *elector) release() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return errors.Wrap(releaseScript.Run(ctx, e.client, []string{e.cfg.Key}, e.id).Err(), "release lease")
+}
+
+// single is an elector of the only instance.
+type single struct{}
+
+func (single) IsLeader() bool {
+	return true
+}
+
+func (single) Run(ctx context.Context, fn func(ctx context.Context)) {
+	fn(ctx)
+}
@@ -0,0 +1,73 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestElector_Renew(t *testing.T) {
+	cases := []struct {
+		name   string
+		result int64
+		expOk  bool
+	}{
+		{
+			name:   "lease is held",
+			result: 1,
+			expOk:  true,
+		},
+		{
+			name:   "lease is lost",
+			result: 0,
+			expOk:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			e := New(client, Config{Enabled: true, TTL: 3 * time.Second}, loggerPkg.NewFatal()).(*elector)
+			mock.ExpectEvalSha(renewScript.Hash(), []string{defaultKey}, e.id, int64(3000)).SetVal(c.result)
+
+			ok, err := e.renew(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, c.expOk, ok)
+		})
+	}
+}
+
+func TestElector_Run(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	e := New(client, Config{Enabled: true, Key: "test", TTL: 30 * time.Millisecond}, loggerPkg.NewFatal()).(*elector)
+	mock.ExpectSetNX("test", e.id, 30*time.Millisecond).SetVal(true)
+	mock.ExpectEvalSha(renewScript.Hash(), []string{"test"}, e.id, int64(30)).SetVal(0)
+	mock.ExpectSetNX("test", e.id, 30*time.Millisecond).SetVal(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	called := make(chan bool, 1)
+	go e.Run(ctx, func(ctx context.Context) {
+		called <- true
+		<-ctx.Done()
+		cancel()
+	})
+
+	assert.True(t, <-called)
+	<-ctx.Done()
+	assert.False(t, e.IsLeader())
+}
+
+func TestSingle(t *testing.T) {
+	e := New(nil, Config{}, loggerPkg.NewFatal())
+	assert.True(t, e.IsLeader())
+
+	called := false
+	e.Run(context.Background(), func(context.Context) { called = true })
+	assert.True(t, called)
+}
@@ -0,0 +1,202 @@
+package lifecycle
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Component is a part of the service. Run blocks until its context is cancelled,
+// Stop releases resources. Both are optional.
+type Component struct {
+	Name string
+	// DependsOn lists components, which must be started before
+	// and stopped after this one.
+	DependsOn []string
+	Run       func(ctx context.Context) error
+	Stop      func(ctx context.Context) error
+	// Timeout limits the component shutdown, default is 10s.
+	Timeout time.Duration
+}
+
+// Result is a shutdown summary of the component.
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+type Manager struct {
+	logger     *zap.SugaredLogger
+	components map[string]Component
+}
+
+func New(logger *zap.SugaredLogger) *Manager {
+	return &Manager{
+		logger:     logger,
+		components: make(map[string]Component),
+	}
+}
+
+func (m *Manager) Add(components ...Component) {
+	for _, c := range components {
+		m.components[c.Name] = c
+	}
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Run starts components in topological order and waits until ctx is done or any component stops.
+// Then components are stopped in reverse order, every one is cancelled after all its dependents.
+func (m *Manager) Run(ctx context.Context) error {
+	order, err := m.order()
+	if err != nil {
+		return err
+	}
+
+	stopped := make(chan string, len(order))
+	started := make([]*running, 0, len(order))
+	for _, c := range order {
+		r := &running{
+			Component: c,
+			done:      make(chan struct{}),
+		}
+		var runCtx context.Context
+		runCtx, r.cancel = context.WithCancel(context.Background())
+		if c.Run == nil {
+			close(r.done)
+		} else {
+			go func() {
+				defer close(r.done)
+				r.err = r.Run(runCtx)
+				stopped <- r.Name
+			}()
+		}
+		started = append(started, r)
+		m.logger.Infow("component started", "component", c.Name)
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		m.logger.Infoln("Shutting down...")
+	case name := <-stopped:
+		for _, r := range started {
+			if r.Name == name && r.err != nil {
+				runErr = errors.Wrapf(r.err, "component [%s]", name)
+			}
+		}
+		m.logger.Warnw("component stopped, shutting down", "component", name)
+	}
+
+	results := m.shutdown(started)
+	for _, result := range results {
+		if result.Err != nil && runErr == nil {
+			runErr = errors.Wrapf(result.Err, "component [%s] shutdown", result.Name)
+		}
+	}
+	return runErr
+}
+
+// shutdown stops components in reverse order and logs the summary.
+func (m *Manager) shutdown(started []*running) []Result {
+	results := make([]Result, 0, len(started))
+	for i := len(started) - 1; i >= 0; i-- {
+		results = append(results, m.stop(started[i]))
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			m.logger.Errorw("component stop", "component", result.Name,
+				"duration", result.Duration, "error", result.Err.Error())
+			continue
+		}
+		m.logger.Infow("component stop", "component", result.Name, "duration", result.Duration)
+	}
+	m.logger.Infow("shutdown summary", "components", len(results), "failed", failed)
+	return results
+}
+
+func (m *Manager) stop(r *running) Result {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return Result{Name: r.Name, Duration: time.Since(start), Err: errors.Errorf("run is not finished in %s", timeout)}
+	}
+
+	var err error
+	if r.Stop != nil {
+		err = r.Stop(ctx)
+	}
+	return Result{Name: r.Name, Duration: time.Since(start), Err: err}
+}
+
+// order returns components sorted topologically, names are sorted within a level for stable order.
+func (m *Manager) order() ([]Component, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(m.components))
+	order := make([]Component, 0, len(m.components))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		c, ok := m.components[name]
+		if !ok {
+			return errors.Errorf("unknown component [%s] required by [%s]", name, path[len(path)-1])
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("dependency cycle: %v", append(path, name))
+		}
+		state[name] = visiting
+
+		deps := append([]string(nil), c.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, c)
+		return nil
+	}
+
+	names := make([]string, 0, len(m.components))
+	for name := range m.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
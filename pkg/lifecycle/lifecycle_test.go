@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+func TestManager_Run(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		stopped []string
+	)
+	component := func(name string, deps ...string) Component {
+		return Component{
+			Name:      name,
+			DependsOn: deps,
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				stopped = append(stopped, name)
+				return nil
+			},
+		}
+	}
+
+	m := New(loggerPkg.NewFatal())
+	m.Add(
+		component("http", "grpc"),
+		component("grpc", "repo", "cache"),
+		component("cache"),
+		component("repo"),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.NoError(t, m.Run(ctx))
+	assert.Equal(t, []string{"http", "grpc", "repo", "cache"}, stopped)
+}
+
+func TestManager_RunFailed(t *testing.T) {
+	cases := []struct {
+		name       string
+		components []Component
+		expErr     string
+	}{
+		{
+			name: "dependency cycle",
+			components: []Component{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+			},
+			expErr: "dependency cycle: [a b a]",
+		},
+		{
+			name: "unknown dependency",
+			components: []Component{
+				{Name: "a", DependsOn: []string{"b"}},
+			},
+			expErr: "unknown component [b] required by [a]",
+		},
+		{
+			name: "component failed",
+			components: []Component{
+				{Name: "a", Run: func(context.Context) error { return errors.New("listen") }},
+			},
+			expErr: "component [a]: listen",
+		},
+		{
+			name: "component error is returned before shutdown timeout",
+			components: []Component{
+				{Name: "a", DependsOn: []string{"b"}, Run: func(context.Context) error { return errors.New("listen") }},
+				{
+					Name:    "b",
+					Timeout: 10 * time.Millisecond,
+					Run: func(context.Context) error {
+						time.Sleep(time.Second)
+						return nil
+					},
+				},
+			},
+			expErr: "component [a]: listen",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := New(loggerPkg.NewFatal())
+			m.Add(c.components...)
+			assert.EqualError(t, m.Run(context.Background()), c.expErr)
+		})
+	}
+}
@@ -37,6 +37,126 @@ func (m *MockUserClient) EXPECT() *MockUserClientMockRecorder {
 	return m.recorder
 }
 
+// Data mocks base method.
+func (m *MockUserClient) Data(ctx context.Context, in *api.DataRequest, opts ...grpc.CallOption) (*api.DataResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Data", varargs...)
+	ret0, _ := ret[0].(*api.DataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Data indicates an expected call of Data.
+func (mr *MockUserClientMockRecorder) Data(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Data", reflect.TypeOf((*MockUserClient)(nil).Data), varargs...)
+}
+
+// MeChangePassword mocks base method.
+func (m *MockUserClient) MeChangePassword(ctx context.Context, in *api.MeChangePasswordRequest, opts ...grpc.CallOption) (*api.MeChangePasswordResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MeChangePassword", varargs...)
+	ret0, _ := ret[0].(*api.MeChangePasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeChangePassword indicates an expected call of MeChangePassword.
+func (mr *MockUserClientMockRecorder) MeChangePassword(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeChangePassword", reflect.TypeOf((*MockUserClient)(nil).MeChangePassword), varargs...)
+}
+
+// MeDelete mocks base method.
+func (m *MockUserClient) MeDelete(ctx context.Context, in *api.MeDeleteRequest, opts ...grpc.CallOption) (*api.MeDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MeDelete", varargs...)
+	ret0, _ := ret[0].(*api.MeDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeDelete indicates an expected call of MeDelete.
+func (mr *MockUserClientMockRecorder) MeDelete(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeDelete", reflect.TypeOf((*MockUserClient)(nil).MeDelete), varargs...)
+}
+
+// MeGet mocks base method.
+func (m *MockUserClient) MeGet(ctx context.Context, in *api.MeGetRequest, opts ...grpc.CallOption) (*api.MeGetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MeGet", varargs...)
+	ret0, _ := ret[0].(*api.MeGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeGet indicates an expected call of MeGet.
+func (mr *MockUserClientMockRecorder) MeGet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeGet", reflect.TypeOf((*MockUserClient)(nil).MeGet), varargs...)
+}
+
+// MeUpdate mocks base method.
+func (m *MockUserClient) MeUpdate(ctx context.Context, in *api.MeUpdateRequest, opts ...grpc.CallOption) (*api.MeUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MeUpdate", varargs...)
+	ret0, _ := ret[0].(*api.MeUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeUpdate indicates an expected call of MeUpdate.
+func (mr *MockUserClientMockRecorder) MeUpdate(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeUpdate", reflect.TypeOf((*MockUserClient)(nil).MeUpdate), varargs...)
+}
+
+// ServiceInfo mocks base method.
+func (m *MockUserClient) ServiceInfo(ctx context.Context, in *api.ServiceInfoRequest, opts ...grpc.CallOption) (*api.ServiceInfoResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ServiceInfo", varargs...)
+	ret0, _ := ret[0].(*api.ServiceInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServiceInfo indicates an expected call of ServiceInfo.
+func (mr *MockUserClientMockRecorder) ServiceInfo(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceInfo", reflect.TypeOf((*MockUserClient)(nil).ServiceInfo), varargs...)
+}
+
 // UserAllList mocks base method.
 func (m *MockUserClient) UserAllList(ctx context.Context, in *api.UserAllListRequest, opts ...grpc.CallOption) (api.User_UserAllListClient, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +177,106 @@ func (mr *MockUserClientMockRecorder) UserAllList(ctx, in interface{}, opts ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAllList", reflect.TypeOf((*MockUserClient)(nil).UserAllList), varargs...)
 }
 
+// UserAvatarGet mocks base method.
+func (m *MockUserClient) UserAvatarGet(ctx context.Context, in *api.UserAvatarGetRequest, opts ...grpc.CallOption) (*api.UserAvatarGetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserAvatarGet", varargs...)
+	ret0, _ := ret[0].(*api.UserAvatarGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserAvatarGet indicates an expected call of UserAvatarGet.
+func (mr *MockUserClientMockRecorder) UserAvatarGet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAvatarGet", reflect.TypeOf((*MockUserClient)(nil).UserAvatarGet), varargs...)
+}
+
+// UserAvatarUpload mocks base method.
+func (m *MockUserClient) UserAvatarUpload(ctx context.Context, opts ...grpc.CallOption) (api.User_UserAvatarUploadClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserAvatarUpload", varargs...)
+	ret0, _ := ret[0].(api.User_UserAvatarUploadClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserAvatarUpload indicates an expected call of UserAvatarUpload.
+func (mr *MockUserClientMockRecorder) UserAvatarUpload(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAvatarUpload", reflect.TypeOf((*MockUserClient)(nil).UserAvatarUpload), varargs...)
+}
+
+// UserChanges mocks base method.
+func (m *MockUserClient) UserChanges(ctx context.Context, in *api.UserChangesRequest, opts ...grpc.CallOption) (api.User_UserChangesClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserChanges", varargs...)
+	ret0, _ := ret[0].(api.User_UserChangesClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserChanges indicates an expected call of UserChanges.
+func (mr *MockUserClientMockRecorder) UserChanges(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserChanges", reflect.TypeOf((*MockUserClient)(nil).UserChanges), varargs...)
+}
+
+// UserCheckPassword mocks base method.
+func (m *MockUserClient) UserCheckPassword(ctx context.Context, in *api.UserCheckPasswordRequest, opts ...grpc.CallOption) (*api.UserCheckPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserCheckPassword", varargs...)
+	ret0, _ := ret[0].(*api.UserCheckPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCheckPassword indicates an expected call of UserCheckPassword.
+func (mr *MockUserClientMockRecorder) UserCheckPassword(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCheckPassword", reflect.TypeOf((*MockUserClient)(nil).UserCheckPassword), varargs...)
+}
+
+// UserCount mocks base method.
+func (m *MockUserClient) UserCount(ctx context.Context, in *api.UserCountRequest, opts ...grpc.CallOption) (*api.UserCountResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserCount", varargs...)
+	ret0, _ := ret[0].(*api.UserCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCount indicates an expected call of UserCount.
+func (mr *MockUserClientMockRecorder) UserCount(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCount", reflect.TypeOf((*MockUserClient)(nil).UserCount), varargs...)
+}
+
 // UserCreate mocks base method.
 func (m *MockUserClient) UserCreate(ctx context.Context, in *api.UserCreateRequest, opts ...grpc.CallOption) (*api.UserCreateResponse, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +317,46 @@ func (mr *MockUserClientMockRecorder) UserDelete(ctx, in interface{}, opts ...in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDelete", reflect.TypeOf((*MockUserClient)(nil).UserDelete), varargs...)
 }
 
+// UserDisable mocks base method.
+func (m *MockUserClient) UserDisable(ctx context.Context, in *api.UserDisableRequest, opts ...grpc.CallOption) (*api.UserDisableResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserDisable", varargs...)
+	ret0, _ := ret[0].(*api.UserDisableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserDisable indicates an expected call of UserDisable.
+func (mr *MockUserClientMockRecorder) UserDisable(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDisable", reflect.TypeOf((*MockUserClient)(nil).UserDisable), varargs...)
+}
+
+// UserEnable mocks base method.
+func (m *MockUserClient) UserEnable(ctx context.Context, in *api.UserEnableRequest, opts ...grpc.CallOption) (*api.UserEnableResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserEnable", varargs...)
+	ret0, _ := ret[0].(*api.UserEnableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserEnable indicates an expected call of UserEnable.
+func (mr *MockUserClientMockRecorder) UserEnable(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserEnable", reflect.TypeOf((*MockUserClient)(nil).UserEnable), varargs...)
+}
+
 // UserGet mocks base method.
 func (m *MockUserClient) UserGet(ctx context.Context, in *api.UserGetRequest, opts ...grpc.CallOption) (*api.UserGetResponse, error) {
 	m.ctrl.T.Helper()
@@ -117,6 +377,66 @@ func (mr *MockUserClientMockRecorder) UserGet(ctx, in interface{}, opts ...inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGet", reflect.TypeOf((*MockUserClient)(nil).UserGet), varargs...)
 }
 
+// UserGetById mocks base method.
+func (m *MockUserClient) UserGetById(ctx context.Context, in *api.UserGetByIdRequest, opts ...grpc.CallOption) (*api.UserGetByIdResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserGetById", varargs...)
+	ret0, _ := ret[0].(*api.UserGetByIdResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGetById indicates an expected call of UserGetById.
+func (mr *MockUserClientMockRecorder) UserGetById(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGetById", reflect.TypeOf((*MockUserClient)(nil).UserGetById), varargs...)
+}
+
+// UserGetIfChanged mocks base method.
+func (m *MockUserClient) UserGetIfChanged(ctx context.Context, in *api.UserGetIfChangedRequest, opts ...grpc.CallOption) (*api.UserGetIfChangedResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserGetIfChanged", varargs...)
+	ret0, _ := ret[0].(*api.UserGetIfChangedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGetIfChanged indicates an expected call of UserGetIfChanged.
+func (mr *MockUserClientMockRecorder) UserGetIfChanged(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGetIfChanged", reflect.TypeOf((*MockUserClient)(nil).UserGetIfChanged), varargs...)
+}
+
+// UserImport mocks base method.
+func (m *MockUserClient) UserImport(ctx context.Context, opts ...grpc.CallOption) (api.User_UserImportClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserImport", varargs...)
+	ret0, _ := ret[0].(api.User_UserImportClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserImport indicates an expected call of UserImport.
+func (mr *MockUserClientMockRecorder) UserImport(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserImport", reflect.TypeOf((*MockUserClient)(nil).UserImport), varargs...)
+}
+
 // UserList mocks base method.
 func (m *MockUserClient) UserList(ctx context.Context, in *api.UserListRequest, opts ...grpc.CallOption) (*api.UserListResponse, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +457,46 @@ func (mr *MockUserClientMockRecorder) UserList(ctx, in interface{}, opts ...inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserList", reflect.TypeOf((*MockUserClient)(nil).UserList), varargs...)
 }
 
+// UserLoginExternal mocks base method.
+func (m *MockUserClient) UserLoginExternal(ctx context.Context, in *api.UserLoginExternalRequest, opts ...grpc.CallOption) (*api.UserLoginExternalResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserLoginExternal", varargs...)
+	ret0, _ := ret[0].(*api.UserLoginExternalResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserLoginExternal indicates an expected call of UserLoginExternal.
+func (mr *MockUserClientMockRecorder) UserLoginExternal(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserLoginExternal", reflect.TypeOf((*MockUserClient)(nil).UserLoginExternal), varargs...)
+}
+
+// UserRename mocks base method.
+func (m *MockUserClient) UserRename(ctx context.Context, in *api.UserRenameRequest, opts ...grpc.CallOption) (*api.UserRenameResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserRename", varargs...)
+	ret0, _ := ret[0].(*api.UserRenameResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRename indicates an expected call of UserRename.
+func (mr *MockUserClientMockRecorder) UserRename(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRename", reflect.TypeOf((*MockUserClient)(nil).UserRename), varargs...)
+}
+
 // UserUpdate mocks base method.
 func (m *MockUserClient) UserUpdate(ctx context.Context, in *api.UserUpdateRequest, opts ...grpc.CallOption) (*api.UserUpdateResponse, error) {
 	m.ctrl.T.Helper()
@@ -280,190 +640,3519 @@ func (mr *MockUser_UserAllListClientMockRecorder) Trailer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockUser_UserAllListClient)(nil).Trailer))
 }
 
-// MockUserServer is a mock of UserServer interface.
-type MockUserServer struct {
+// MockUser_UserImportClient is a mock of User_UserImportClient interface.
+type MockUser_UserImportClient struct {
 	ctrl     *gomock.Controller
-	recorder *MockUserServerMockRecorder
+	recorder *MockUser_UserImportClientMockRecorder
 }
 
-// MockUserServerMockRecorder is the mock recorder for MockUserServer.
-type MockUserServerMockRecorder struct {
-	mock *MockUserServer
+// MockUser_UserImportClientMockRecorder is the mock recorder for MockUser_UserImportClient.
+type MockUser_UserImportClientMockRecorder struct {
+	mock *MockUser_UserImportClient
 }
 
-// NewMockUserServer creates a new mock instance.
-func NewMockUserServer(ctrl *gomock.Controller) *MockUserServer {
-	mock := &MockUserServer{ctrl: ctrl}
-	mock.recorder = &MockUserServerMockRecorder{mock}
+// NewMockUser_UserImportClient creates a new mock instance.
+func NewMockUser_UserImportClient(ctrl *gomock.Controller) *MockUser_UserImportClient {
+	mock := &MockUser_UserImportClient{ctrl: ctrl}
+	mock.recorder = &MockUser_UserImportClientMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockUserServer) EXPECT() *MockUserServerMockRecorder {
+func (m *MockUser_UserImportClient) EXPECT() *MockUser_UserImportClientMockRecorder {
 	return m.recorder
 }
 
-// UserAllList mocks base method.
-func (m *MockUserServer) UserAllList(arg0 *api.UserAllListRequest, arg1 api.User_UserAllListServer) error {
+// CloseSend mocks base method.
+func (m *MockUser_UserImportClient) CloseSend() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserAllList", arg0, arg1)
+	ret := m.ctrl.Call(m, "CloseSend")
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UserAllList indicates an expected call of UserAllList.
-func (mr *MockUserServerMockRecorder) UserAllList(arg0, arg1 interface{}) *gomock.Call {
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockUser_UserImportClientMockRecorder) CloseSend() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAllList", reflect.TypeOf((*MockUserServer)(nil).UserAllList), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockUser_UserImportClient)(nil).CloseSend))
 }
 
-// UserCreate mocks base method.
-func (m *MockUserServer) UserCreate(arg0 context.Context, arg1 *api.UserCreateRequest) (*api.UserCreateResponse, error) {
+// Context mocks base method.
+func (m *MockUser_UserImportClient) Context() context.Context {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserCreate", arg0, arg1)
-	ret0, _ := ret[0].(*api.UserCreateResponse)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserImportClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserImportClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockUser_UserImportClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockUser_UserImportClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockUser_UserImportClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockUser_UserImportClient) Recv() (*api.UserImportResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.UserImportResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockUser_UserImportClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockUser_UserImportClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserImportClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserImportClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserImportClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockUser_UserImportClient) Send(arg0 *api.UserImportRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockUser_UserImportClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserImportClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserImportClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserImportClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserImportClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockUser_UserImportClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockUser_UserImportClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockUser_UserImportClient)(nil).Trailer))
+}
+
+// MockUser_UserAvatarUploadClient is a mock of User_UserAvatarUploadClient interface.
+type MockUser_UserAvatarUploadClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserAvatarUploadClientMockRecorder
+}
+
+// MockUser_UserAvatarUploadClientMockRecorder is the mock recorder for MockUser_UserAvatarUploadClient.
+type MockUser_UserAvatarUploadClientMockRecorder struct {
+	mock *MockUser_UserAvatarUploadClient
+}
+
+// NewMockUser_UserAvatarUploadClient creates a new mock instance.
+func NewMockUser_UserAvatarUploadClient(ctrl *gomock.Controller) *MockUser_UserAvatarUploadClient {
+	mock := &MockUser_UserAvatarUploadClient{ctrl: ctrl}
+	mock.recorder = &MockUser_UserAvatarUploadClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserAvatarUploadClient) EXPECT() *MockUser_UserAvatarUploadClientMockRecorder {
+	return m.recorder
+}
+
+// CloseAndRecv mocks base method.
+func (m *MockUser_UserAvatarUploadClient) CloseAndRecv() (*api.UserAvatarUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*api.UserAvatarUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).CloseAndRecv))
+}
+
+// CloseSend mocks base method.
+func (m *MockUser_UserAvatarUploadClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockUser_UserAvatarUploadClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockUser_UserAvatarUploadClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).Header))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserAvatarUploadClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockUser_UserAvatarUploadClient) Send(arg0 *api.UserAvatarUploadRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserAvatarUploadClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockUser_UserAvatarUploadClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockUser_UserAvatarUploadClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockUser_UserAvatarUploadClient)(nil).Trailer))
+}
+
+// MockUser_UserChangesClient is a mock of User_UserChangesClient interface.
+type MockUser_UserChangesClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserChangesClientMockRecorder
+}
+
+// MockUser_UserChangesClientMockRecorder is the mock recorder for MockUser_UserChangesClient.
+type MockUser_UserChangesClientMockRecorder struct {
+	mock *MockUser_UserChangesClient
+}
+
+// NewMockUser_UserChangesClient creates a new mock instance.
+func NewMockUser_UserChangesClient(ctrl *gomock.Controller) *MockUser_UserChangesClient {
+	mock := &MockUser_UserChangesClient{ctrl: ctrl}
+	mock.recorder = &MockUser_UserChangesClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserChangesClient) EXPECT() *MockUser_UserChangesClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockUser_UserChangesClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockUser_UserChangesClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockUser_UserChangesClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockUser_UserChangesClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserChangesClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserChangesClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockUser_UserChangesClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockUser_UserChangesClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockUser_UserChangesClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockUser_UserChangesClient) Recv() (*api.UserChangesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.UserChangesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockUser_UserChangesClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockUser_UserChangesClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserChangesClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserChangesClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserChangesClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserChangesClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserChangesClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserChangesClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockUser_UserChangesClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockUser_UserChangesClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockUser_UserChangesClient)(nil).Trailer))
+}
+
+// MockUserServer is a mock of UserServer interface.
+type MockUserServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserServerMockRecorder
+}
+
+// MockUserServerMockRecorder is the mock recorder for MockUserServer.
+type MockUserServerMockRecorder struct {
+	mock *MockUserServer
+}
+
+// NewMockUserServer creates a new mock instance.
+func NewMockUserServer(ctrl *gomock.Controller) *MockUserServer {
+	mock := &MockUserServer{ctrl: ctrl}
+	mock.recorder = &MockUserServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserServer) EXPECT() *MockUserServerMockRecorder {
+	return m.recorder
+}
+
+// Data mocks base method.
+func (m *MockUserServer) Data(arg0 context.Context, arg1 *api.DataRequest) (*api.DataResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Data", arg0, arg1)
+	ret0, _ := ret[0].(*api.DataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Data indicates an expected call of Data.
+func (mr *MockUserServerMockRecorder) Data(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Data", reflect.TypeOf((*MockUserServer)(nil).Data), arg0, arg1)
+}
+
+// MeChangePassword mocks base method.
+func (m *MockUserServer) MeChangePassword(arg0 context.Context, arg1 *api.MeChangePasswordRequest) (*api.MeChangePasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(*api.MeChangePasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeChangePassword indicates an expected call of MeChangePassword.
+func (mr *MockUserServerMockRecorder) MeChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeChangePassword", reflect.TypeOf((*MockUserServer)(nil).MeChangePassword), arg0, arg1)
+}
+
+// MeDelete mocks base method.
+func (m *MockUserServer) MeDelete(arg0 context.Context, arg1 *api.MeDeleteRequest) (*api.MeDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeDelete", arg0, arg1)
+	ret0, _ := ret[0].(*api.MeDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeDelete indicates an expected call of MeDelete.
+func (mr *MockUserServerMockRecorder) MeDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeDelete", reflect.TypeOf((*MockUserServer)(nil).MeDelete), arg0, arg1)
+}
+
+// MeGet mocks base method.
+func (m *MockUserServer) MeGet(arg0 context.Context, arg1 *api.MeGetRequest) (*api.MeGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.MeGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeGet indicates an expected call of MeGet.
+func (mr *MockUserServerMockRecorder) MeGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeGet", reflect.TypeOf((*MockUserServer)(nil).MeGet), arg0, arg1)
+}
+
+// MeUpdate mocks base method.
+func (m *MockUserServer) MeUpdate(arg0 context.Context, arg1 *api.MeUpdateRequest) (*api.MeUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeUpdate", arg0, arg1)
+	ret0, _ := ret[0].(*api.MeUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeUpdate indicates an expected call of MeUpdate.
+func (mr *MockUserServerMockRecorder) MeUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeUpdate", reflect.TypeOf((*MockUserServer)(nil).MeUpdate), arg0, arg1)
+}
+
+// ServiceInfo mocks base method.
+func (m *MockUserServer) ServiceInfo(arg0 context.Context, arg1 *api.ServiceInfoRequest) (*api.ServiceInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServiceInfo", arg0, arg1)
+	ret0, _ := ret[0].(*api.ServiceInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServiceInfo indicates an expected call of ServiceInfo.
+func (mr *MockUserServerMockRecorder) ServiceInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceInfo", reflect.TypeOf((*MockUserServer)(nil).ServiceInfo), arg0, arg1)
+}
+
+// UserAllList mocks base method.
+func (m *MockUserServer) UserAllList(arg0 *api.UserAllListRequest, arg1 api.User_UserAllListServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserAllList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserAllList indicates an expected call of UserAllList.
+func (mr *MockUserServerMockRecorder) UserAllList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAllList", reflect.TypeOf((*MockUserServer)(nil).UserAllList), arg0, arg1)
+}
+
+// UserAvatarGet mocks base method.
+func (m *MockUserServer) UserAvatarGet(arg0 context.Context, arg1 *api.UserAvatarGetRequest) (*api.UserAvatarGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserAvatarGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserAvatarGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserAvatarGet indicates an expected call of UserAvatarGet.
+func (mr *MockUserServerMockRecorder) UserAvatarGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAvatarGet", reflect.TypeOf((*MockUserServer)(nil).UserAvatarGet), arg0, arg1)
+}
+
+// UserAvatarUpload mocks base method.
+func (m *MockUserServer) UserAvatarUpload(arg0 api.User_UserAvatarUploadServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserAvatarUpload", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserAvatarUpload indicates an expected call of UserAvatarUpload.
+func (mr *MockUserServerMockRecorder) UserAvatarUpload(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserAvatarUpload", reflect.TypeOf((*MockUserServer)(nil).UserAvatarUpload), arg0)
+}
+
+// UserChanges mocks base method.
+func (m *MockUserServer) UserChanges(arg0 *api.UserChangesRequest, arg1 api.User_UserChangesServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserChanges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserChanges indicates an expected call of UserChanges.
+func (mr *MockUserServerMockRecorder) UserChanges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserChanges", reflect.TypeOf((*MockUserServer)(nil).UserChanges), arg0, arg1)
+}
+
+// UserCheckPassword mocks base method.
+func (m *MockUserServer) UserCheckPassword(arg0 context.Context, arg1 *api.UserCheckPasswordRequest) (*api.UserCheckPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCheckPassword", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserCheckPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCheckPassword indicates an expected call of UserCheckPassword.
+func (mr *MockUserServerMockRecorder) UserCheckPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCheckPassword", reflect.TypeOf((*MockUserServer)(nil).UserCheckPassword), arg0, arg1)
+}
+
+// UserCount mocks base method.
+func (m *MockUserServer) UserCount(arg0 context.Context, arg1 *api.UserCountRequest) (*api.UserCountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCount", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserCountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCount indicates an expected call of UserCount.
+func (mr *MockUserServerMockRecorder) UserCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCount", reflect.TypeOf((*MockUserServer)(nil).UserCount), arg0, arg1)
+}
+
+// UserCreate mocks base method.
+func (m *MockUserServer) UserCreate(arg0 context.Context, arg1 *api.UserCreateRequest) (*api.UserCreateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserCreate", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserCreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserCreate indicates an expected call of UserCreate.
+func (mr *MockUserServerMockRecorder) UserCreate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCreate", reflect.TypeOf((*MockUserServer)(nil).UserCreate), arg0, arg1)
+}
+
+// UserDelete mocks base method.
+func (m *MockUserServer) UserDelete(arg0 context.Context, arg1 *api.UserDeleteRequest) (*api.UserDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserDelete", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserDelete indicates an expected call of UserDelete.
+func (mr *MockUserServerMockRecorder) UserDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDelete", reflect.TypeOf((*MockUserServer)(nil).UserDelete), arg0, arg1)
+}
+
+// UserDisable mocks base method.
+func (m *MockUserServer) UserDisable(arg0 context.Context, arg1 *api.UserDisableRequest) (*api.UserDisableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserDisable", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserDisableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserDisable indicates an expected call of UserDisable.
+func (mr *MockUserServerMockRecorder) UserDisable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDisable", reflect.TypeOf((*MockUserServer)(nil).UserDisable), arg0, arg1)
+}
+
+// UserEnable mocks base method.
+func (m *MockUserServer) UserEnable(arg0 context.Context, arg1 *api.UserEnableRequest) (*api.UserEnableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserEnable", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserEnableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserEnable indicates an expected call of UserEnable.
+func (mr *MockUserServerMockRecorder) UserEnable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserEnable", reflect.TypeOf((*MockUserServer)(nil).UserEnable), arg0, arg1)
+}
+
+// UserGet mocks base method.
+func (m *MockUserServer) UserGet(arg0 context.Context, arg1 *api.UserGetRequest) (*api.UserGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGet indicates an expected call of UserGet.
+func (mr *MockUserServerMockRecorder) UserGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGet", reflect.TypeOf((*MockUserServer)(nil).UserGet), arg0, arg1)
+}
+
+// UserGetById mocks base method.
+func (m *MockUserServer) UserGetById(arg0 context.Context, arg1 *api.UserGetByIdRequest) (*api.UserGetByIdResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGetById", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserGetByIdResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGetById indicates an expected call of UserGetById.
+func (mr *MockUserServerMockRecorder) UserGetById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGetById", reflect.TypeOf((*MockUserServer)(nil).UserGetById), arg0, arg1)
+}
+
+// UserGetIfChanged mocks base method.
+func (m *MockUserServer) UserGetIfChanged(arg0 context.Context, arg1 *api.UserGetIfChangedRequest) (*api.UserGetIfChangedResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGetIfChanged", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserGetIfChangedResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGetIfChanged indicates an expected call of UserGetIfChanged.
+func (mr *MockUserServerMockRecorder) UserGetIfChanged(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGetIfChanged", reflect.TypeOf((*MockUserServer)(nil).UserGetIfChanged), arg0, arg1)
+}
+
+// UserImport mocks base method.
+func (m *MockUserServer) UserImport(arg0 api.User_UserImportServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserImport", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserImport indicates an expected call of UserImport.
+func (mr *MockUserServerMockRecorder) UserImport(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserImport", reflect.TypeOf((*MockUserServer)(nil).UserImport), arg0)
+}
+
+// UserList mocks base method.
+func (m *MockUserServer) UserList(arg0 context.Context, arg1 *api.UserListRequest) (*api.UserListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserList", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserList indicates an expected call of UserList.
+func (mr *MockUserServerMockRecorder) UserList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserList", reflect.TypeOf((*MockUserServer)(nil).UserList), arg0, arg1)
+}
+
+// UserLoginExternal mocks base method.
+func (m *MockUserServer) UserLoginExternal(arg0 context.Context, arg1 *api.UserLoginExternalRequest) (*api.UserLoginExternalResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserLoginExternal", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserLoginExternalResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserLoginExternal indicates an expected call of UserLoginExternal.
+func (mr *MockUserServerMockRecorder) UserLoginExternal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserLoginExternal", reflect.TypeOf((*MockUserServer)(nil).UserLoginExternal), arg0, arg1)
+}
+
+// UserRename mocks base method.
+func (m *MockUserServer) UserRename(arg0 context.Context, arg1 *api.UserRenameRequest) (*api.UserRenameResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRename", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserRenameResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRename indicates an expected call of UserRename.
+func (mr *MockUserServerMockRecorder) UserRename(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRename", reflect.TypeOf((*MockUserServer)(nil).UserRename), arg0, arg1)
+}
+
+// UserUpdate mocks base method.
+func (m *MockUserServer) UserUpdate(arg0 context.Context, arg1 *api.UserUpdateRequest) (*api.UserUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserUpdate", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserUpdate indicates an expected call of UserUpdate.
+func (mr *MockUserServerMockRecorder) UserUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserUpdate", reflect.TypeOf((*MockUserServer)(nil).UserUpdate), arg0, arg1)
+}
+
+// mustEmbedUnimplementedUserServer mocks base method.
+func (m *MockUserServer) mustEmbedUnimplementedUserServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedUserServer")
+}
+
+// mustEmbedUnimplementedUserServer indicates an expected call of mustEmbedUnimplementedUserServer.
+func (mr *MockUserServerMockRecorder) mustEmbedUnimplementedUserServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedUserServer", reflect.TypeOf((*MockUserServer)(nil).mustEmbedUnimplementedUserServer))
+}
+
+// MockUnsafeUserServer is a mock of UnsafeUserServer interface.
+type MockUnsafeUserServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnsafeUserServerMockRecorder
+}
+
+// MockUnsafeUserServerMockRecorder is the mock recorder for MockUnsafeUserServer.
+type MockUnsafeUserServerMockRecorder struct {
+	mock *MockUnsafeUserServer
+}
+
+// NewMockUnsafeUserServer creates a new mock instance.
+func NewMockUnsafeUserServer(ctrl *gomock.Controller) *MockUnsafeUserServer {
+	mock := &MockUnsafeUserServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeUserServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnsafeUserServer) EXPECT() *MockUnsafeUserServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedUserServer mocks base method.
+func (m *MockUnsafeUserServer) mustEmbedUnimplementedUserServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedUserServer")
+}
+
+// mustEmbedUnimplementedUserServer indicates an expected call of mustEmbedUnimplementedUserServer.
+func (mr *MockUnsafeUserServerMockRecorder) mustEmbedUnimplementedUserServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedUserServer", reflect.TypeOf((*MockUnsafeUserServer)(nil).mustEmbedUnimplementedUserServer))
+}
+
+// MockUser_UserAllListServer is a mock of User_UserAllListServer interface.
+type MockUser_UserAllListServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserAllListServerMockRecorder
+}
+
+// MockUser_UserAllListServerMockRecorder is the mock recorder for MockUser_UserAllListServer.
+type MockUser_UserAllListServerMockRecorder struct {
+	mock *MockUser_UserAllListServer
+}
+
+// NewMockUser_UserAllListServer creates a new mock instance.
+func NewMockUser_UserAllListServer(ctrl *gomock.Controller) *MockUser_UserAllListServer {
+	mock := &MockUser_UserAllListServer{ctrl: ctrl}
+	mock.recorder = &MockUser_UserAllListServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserAllListServer) EXPECT() *MockUser_UserAllListServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockUser_UserAllListServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserAllListServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserAllListServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserAllListServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserAllListServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserAllListServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockUser_UserAllListServer) Send(arg0 *api.UserAllListResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockUser_UserAllListServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserAllListServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockUser_UserAllListServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockUser_UserAllListServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserAllListServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserAllListServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockUser_UserAllListServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockUser_UserAllListServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockUser_UserAllListServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockUser_UserAllListServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SetTrailer), arg0)
+}
+
+// MockUser_UserImportServer is a mock of User_UserImportServer interface.
+type MockUser_UserImportServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserImportServerMockRecorder
+}
+
+// MockUser_UserImportServerMockRecorder is the mock recorder for MockUser_UserImportServer.
+type MockUser_UserImportServerMockRecorder struct {
+	mock *MockUser_UserImportServer
+}
+
+// NewMockUser_UserImportServer creates a new mock instance.
+func NewMockUser_UserImportServer(ctrl *gomock.Controller) *MockUser_UserImportServer {
+	mock := &MockUser_UserImportServer{ctrl: ctrl}
+	mock.recorder = &MockUser_UserImportServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserImportServer) EXPECT() *MockUser_UserImportServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockUser_UserImportServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserImportServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserImportServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockUser_UserImportServer) Recv() (*api.UserImportRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.UserImportRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockUser_UserImportServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockUser_UserImportServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserImportServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserImportServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserImportServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockUser_UserImportServer) Send(arg0 *api.UserImportResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockUser_UserImportServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserImportServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockUser_UserImportServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockUser_UserImportServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockUser_UserImportServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserImportServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserImportServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserImportServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockUser_UserImportServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockUser_UserImportServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockUser_UserImportServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockUser_UserImportServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockUser_UserImportServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockUser_UserImportServer)(nil).SetTrailer), arg0)
+}
+
+// MockUser_UserAvatarUploadServer is a mock of User_UserAvatarUploadServer interface.
+type MockUser_UserAvatarUploadServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserAvatarUploadServerMockRecorder
+}
+
+// MockUser_UserAvatarUploadServerMockRecorder is the mock recorder for MockUser_UserAvatarUploadServer.
+type MockUser_UserAvatarUploadServerMockRecorder struct {
+	mock *MockUser_UserAvatarUploadServer
+}
+
+// NewMockUser_UserAvatarUploadServer creates a new mock instance.
+func NewMockUser_UserAvatarUploadServer(ctrl *gomock.Controller) *MockUser_UserAvatarUploadServer {
+	mock := &MockUser_UserAvatarUploadServer{ctrl: ctrl}
+	mock.recorder = &MockUser_UserAvatarUploadServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserAvatarUploadServer) EXPECT() *MockUser_UserAvatarUploadServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockUser_UserAvatarUploadServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockUser_UserAvatarUploadServer) Recv() (*api.UserAvatarUploadRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.UserAvatarUploadRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserAvatarUploadServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).RecvMsg), m)
+}
+
+// SendAndClose mocks base method.
+func (m *MockUser_UserAvatarUploadServer) SendAndClose(arg0 *api.UserAvatarUploadResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).SendAndClose), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockUser_UserAvatarUploadServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserAvatarUploadServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockUser_UserAvatarUploadServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockUser_UserAvatarUploadServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockUser_UserAvatarUploadServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockUser_UserAvatarUploadServer)(nil).SetTrailer), arg0)
+}
+
+// MockUser_UserChangesServer is a mock of User_UserChangesServer interface.
+type MockUser_UserChangesServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUser_UserChangesServerMockRecorder
+}
+
+// MockUser_UserChangesServerMockRecorder is the mock recorder for MockUser_UserChangesServer.
+type MockUser_UserChangesServerMockRecorder struct {
+	mock *MockUser_UserChangesServer
+}
+
+// NewMockUser_UserChangesServer creates a new mock instance.
+func NewMockUser_UserChangesServer(ctrl *gomock.Controller) *MockUser_UserChangesServer {
+	mock := &MockUser_UserChangesServer{ctrl: ctrl}
+	mock.recorder = &MockUser_UserChangesServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser_UserChangesServer) EXPECT() *MockUser_UserChangesServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockUser_UserChangesServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockUser_UserChangesServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserChangesServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockUser_UserChangesServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockUser_UserChangesServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserChangesServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockUser_UserChangesServer) Send(arg0 *api.UserChangesResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockUser_UserChangesServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserChangesServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockUser_UserChangesServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockUser_UserChangesServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockUser_UserChangesServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockUser_UserChangesServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockUser_UserChangesServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserChangesServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockUser_UserChangesServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockUser_UserChangesServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockUser_UserChangesServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockUser_UserChangesServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockUser_UserChangesServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockUser_UserChangesServer)(nil).SetTrailer), arg0)
+}
+
+// MockAdminClient is a mock of AdminClient interface.
+type MockAdminClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminClientMockRecorder
+}
+
+// MockAdminClientMockRecorder is the mock recorder for MockAdminClient.
+type MockAdminClientMockRecorder struct {
+	mock *MockAdminClient
+}
+
+// NewMockAdminClient creates a new mock instance.
+func NewMockAdminClient(ctrl *gomock.Controller) *MockAdminClient {
+	mock := &MockAdminClient{ctrl: ctrl}
+	mock.recorder = &MockAdminClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminClient) EXPECT() *MockAdminClientMockRecorder {
+	return m.recorder
+}
+
+// AnomalyConfirm mocks base method.
+func (m *MockAdminClient) AnomalyConfirm(ctx context.Context, in *api.AnomalyConfirmRequest, opts ...grpc.CallOption) (*api.AnomalyConfirmResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AnomalyConfirm", varargs...)
+	ret0, _ := ret[0].(*api.AnomalyConfirmResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnomalyConfirm indicates an expected call of AnomalyConfirm.
+func (mr *MockAdminClientMockRecorder) AnomalyConfirm(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnomalyConfirm", reflect.TypeOf((*MockAdminClient)(nil).AnomalyConfirm), varargs...)
+}
+
+// AnomalyList mocks base method.
+func (m *MockAdminClient) AnomalyList(ctx context.Context, in *api.AnomalyListRequest, opts ...grpc.CallOption) (*api.AnomalyListResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AnomalyList", varargs...)
+	ret0, _ := ret[0].(*api.AnomalyListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnomalyList indicates an expected call of AnomalyList.
+func (mr *MockAdminClientMockRecorder) AnomalyList(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnomalyList", reflect.TypeOf((*MockAdminClient)(nil).AnomalyList), varargs...)
+}
+
+// BackupCreate mocks base method.
+func (m *MockAdminClient) BackupCreate(ctx context.Context, in *api.BackupCreateRequest, opts ...grpc.CallOption) (api.Admin_BackupCreateClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BackupCreate", varargs...)
+	ret0, _ := ret[0].(api.Admin_BackupCreateClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupCreate indicates an expected call of BackupCreate.
+func (mr *MockAdminClientMockRecorder) BackupCreate(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupCreate", reflect.TypeOf((*MockAdminClient)(nil).BackupCreate), varargs...)
+}
+
+// BackupRestore mocks base method.
+func (m *MockAdminClient) BackupRestore(ctx context.Context, opts ...grpc.CallOption) (api.Admin_BackupRestoreClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BackupRestore", varargs...)
+	ret0, _ := ret[0].(api.Admin_BackupRestoreClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupRestore indicates an expected call of BackupRestore.
+func (mr *MockAdminClientMockRecorder) BackupRestore(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupRestore", reflect.TypeOf((*MockAdminClient)(nil).BackupRestore), varargs...)
+}
+
+// CacheEvict mocks base method.
+func (m *MockAdminClient) CacheEvict(ctx context.Context, in *api.CacheEvictRequest, opts ...grpc.CallOption) (*api.CacheEvictResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CacheEvict", varargs...)
+	ret0, _ := ret[0].(*api.CacheEvictResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheEvict indicates an expected call of CacheEvict.
+func (mr *MockAdminClientMockRecorder) CacheEvict(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheEvict", reflect.TypeOf((*MockAdminClient)(nil).CacheEvict), varargs...)
+}
+
+// CacheGet mocks base method.
+func (m *MockAdminClient) CacheGet(ctx context.Context, in *api.CacheGetRequest, opts ...grpc.CallOption) (*api.CacheGetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CacheGet", varargs...)
+	ret0, _ := ret[0].(*api.CacheGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheGet indicates an expected call of CacheGet.
+func (mr *MockAdminClientMockRecorder) CacheGet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheGet", reflect.TypeOf((*MockAdminClient)(nil).CacheGet), varargs...)
+}
+
+// CacheStateExport mocks base method.
+func (m *MockAdminClient) CacheStateExport(ctx context.Context, in *api.CacheStateExportRequest, opts ...grpc.CallOption) (api.Admin_CacheStateExportClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CacheStateExport", varargs...)
+	ret0, _ := ret[0].(api.Admin_CacheStateExportClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheStateExport indicates an expected call of CacheStateExport.
+func (mr *MockAdminClientMockRecorder) CacheStateExport(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStateExport", reflect.TypeOf((*MockAdminClient)(nil).CacheStateExport), varargs...)
+}
+
+// CacheStats mocks base method.
+func (m *MockAdminClient) CacheStats(ctx context.Context, in *api.CacheStatsRequest, opts ...grpc.CallOption) (*api.CacheStatsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CacheStats", varargs...)
+	ret0, _ := ret[0].(*api.CacheStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockAdminClientMockRecorder) CacheStats(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockAdminClient)(nil).CacheStats), varargs...)
+}
+
+// DenylistAdd mocks base method.
+func (m *MockAdminClient) DenylistAdd(ctx context.Context, in *api.DenylistAddRequest, opts ...grpc.CallOption) (*api.DenylistAddResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DenylistAdd", varargs...)
+	ret0, _ := ret[0].(*api.DenylistAddResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistAdd indicates an expected call of DenylistAdd.
+func (mr *MockAdminClientMockRecorder) DenylistAdd(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistAdd", reflect.TypeOf((*MockAdminClient)(nil).DenylistAdd), varargs...)
+}
+
+// DenylistList mocks base method.
+func (m *MockAdminClient) DenylistList(ctx context.Context, in *api.DenylistListRequest, opts ...grpc.CallOption) (*api.DenylistListResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DenylistList", varargs...)
+	ret0, _ := ret[0].(*api.DenylistListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistList indicates an expected call of DenylistList.
+func (mr *MockAdminClientMockRecorder) DenylistList(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistList", reflect.TypeOf((*MockAdminClient)(nil).DenylistList), varargs...)
+}
+
+// DenylistRemove mocks base method.
+func (m *MockAdminClient) DenylistRemove(ctx context.Context, in *api.DenylistRemoveRequest, opts ...grpc.CallOption) (*api.DenylistRemoveResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DenylistRemove", varargs...)
+	ret0, _ := ret[0].(*api.DenylistRemoveResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistRemove indicates an expected call of DenylistRemove.
+func (mr *MockAdminClientMockRecorder) DenylistRemove(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistRemove", reflect.TypeOf((*MockAdminClient)(nil).DenylistRemove), varargs...)
+}
+
+// ImpersonationList mocks base method.
+func (m *MockAdminClient) ImpersonationList(ctx context.Context, in *api.ImpersonationListRequest, opts ...grpc.CallOption) (*api.ImpersonationListResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ImpersonationList", varargs...)
+	ret0, _ := ret[0].(*api.ImpersonationListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImpersonationList indicates an expected call of ImpersonationList.
+func (mr *MockAdminClientMockRecorder) ImpersonationList(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImpersonationList", reflect.TypeOf((*MockAdminClient)(nil).ImpersonationList), varargs...)
+}
+
+// MaintenanceGet mocks base method.
+func (m *MockAdminClient) MaintenanceGet(ctx context.Context, in *api.MaintenanceGetRequest, opts ...grpc.CallOption) (*api.MaintenanceGetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MaintenanceGet", varargs...)
+	ret0, _ := ret[0].(*api.MaintenanceGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaintenanceGet indicates an expected call of MaintenanceGet.
+func (mr *MockAdminClientMockRecorder) MaintenanceGet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaintenanceGet", reflect.TypeOf((*MockAdminClient)(nil).MaintenanceGet), varargs...)
+}
+
+// MaintenanceSet mocks base method.
+func (m *MockAdminClient) MaintenanceSet(ctx context.Context, in *api.MaintenanceSetRequest, opts ...grpc.CallOption) (*api.MaintenanceSetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MaintenanceSet", varargs...)
+	ret0, _ := ret[0].(*api.MaintenanceSetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaintenanceSet indicates an expected call of MaintenanceSet.
+func (mr *MockAdminClientMockRecorder) MaintenanceSet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaintenanceSet", reflect.TypeOf((*MockAdminClient)(nil).MaintenanceSet), varargs...)
+}
+
+// OperationApprove mocks base method.
+func (m *MockAdminClient) OperationApprove(ctx context.Context, in *api.OperationApproveRequest, opts ...grpc.CallOption) (*api.OperationApproveResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OperationApprove", varargs...)
+	ret0, _ := ret[0].(*api.OperationApproveResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OperationApprove indicates an expected call of OperationApprove.
+func (mr *MockAdminClientMockRecorder) OperationApprove(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OperationApprove", reflect.TypeOf((*MockAdminClient)(nil).OperationApprove), varargs...)
+}
+
+// OperationList mocks base method.
+func (m *MockAdminClient) OperationList(ctx context.Context, in *api.OperationListRequest, opts ...grpc.CallOption) (*api.OperationListResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OperationList", varargs...)
+	ret0, _ := ret[0].(*api.OperationListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OperationList indicates an expected call of OperationList.
+func (mr *MockAdminClientMockRecorder) OperationList(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OperationList", reflect.TypeOf((*MockAdminClient)(nil).OperationList), varargs...)
+}
+
+// PasswordExpire mocks base method.
+func (m *MockAdminClient) PasswordExpire(ctx context.Context, in *api.PasswordExpireRequest, opts ...grpc.CallOption) (*api.PasswordExpireResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PasswordExpire", varargs...)
+	ret0, _ := ret[0].(*api.PasswordExpireResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PasswordExpire indicates an expected call of PasswordExpire.
+func (mr *MockAdminClientMockRecorder) PasswordExpire(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordExpire", reflect.TypeOf((*MockAdminClient)(nil).PasswordExpire), varargs...)
+}
+
+// ReindexStart mocks base method.
+func (m *MockAdminClient) ReindexStart(ctx context.Context, in *api.ReindexStartRequest, opts ...grpc.CallOption) (*api.ReindexStartResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReindexStart", varargs...)
+	ret0, _ := ret[0].(*api.ReindexStartResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexStart indicates an expected call of ReindexStart.
+func (mr *MockAdminClientMockRecorder) ReindexStart(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexStart", reflect.TypeOf((*MockAdminClient)(nil).ReindexStart), varargs...)
+}
+
+// ReindexStatus mocks base method.
+func (m *MockAdminClient) ReindexStatus(ctx context.Context, in *api.ReindexStatusRequest, opts ...grpc.CallOption) (*api.ReindexStatusResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReindexStatus", varargs...)
+	ret0, _ := ret[0].(*api.ReindexStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexStatus indicates an expected call of ReindexStatus.
+func (mr *MockAdminClientMockRecorder) ReindexStatus(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexStatus", reflect.TypeOf((*MockAdminClient)(nil).ReindexStatus), varargs...)
+}
+
+// TenantOverridesDelete mocks base method.
+func (m *MockAdminClient) TenantOverridesDelete(ctx context.Context, in *api.TenantOverridesDeleteRequest, opts ...grpc.CallOption) (*api.TenantOverridesDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TenantOverridesDelete", varargs...)
+	ret0, _ := ret[0].(*api.TenantOverridesDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesDelete indicates an expected call of TenantOverridesDelete.
+func (mr *MockAdminClientMockRecorder) TenantOverridesDelete(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesDelete", reflect.TypeOf((*MockAdminClient)(nil).TenantOverridesDelete), varargs...)
+}
+
+// TenantOverridesGet mocks base method.
+func (m *MockAdminClient) TenantOverridesGet(ctx context.Context, in *api.TenantOverridesGetRequest, opts ...grpc.CallOption) (*api.TenantOverridesGetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TenantOverridesGet", varargs...)
+	ret0, _ := ret[0].(*api.TenantOverridesGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesGet indicates an expected call of TenantOverridesGet.
+func (mr *MockAdminClientMockRecorder) TenantOverridesGet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesGet", reflect.TypeOf((*MockAdminClient)(nil).TenantOverridesGet), varargs...)
+}
+
+// TenantOverridesList mocks base method.
+func (m *MockAdminClient) TenantOverridesList(ctx context.Context, in *api.TenantOverridesListRequest, opts ...grpc.CallOption) (*api.TenantOverridesListResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TenantOverridesList", varargs...)
+	ret0, _ := ret[0].(*api.TenantOverridesListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesList indicates an expected call of TenantOverridesList.
+func (mr *MockAdminClientMockRecorder) TenantOverridesList(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesList", reflect.TypeOf((*MockAdminClient)(nil).TenantOverridesList), varargs...)
+}
+
+// TenantOverridesSet mocks base method.
+func (m *MockAdminClient) TenantOverridesSet(ctx context.Context, in *api.TenantOverridesSetRequest, opts ...grpc.CallOption) (*api.TenantOverridesSetResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TenantOverridesSet", varargs...)
+	ret0, _ := ret[0].(*api.TenantOverridesSetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesSet indicates an expected call of TenantOverridesSet.
+func (mr *MockAdminClientMockRecorder) TenantOverridesSet(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesSet", reflect.TypeOf((*MockAdminClient)(nil).TenantOverridesSet), varargs...)
+}
+
+// UsageReport mocks base method.
+func (m *MockAdminClient) UsageReport(ctx context.Context, in *api.UsageReportRequest, opts ...grpc.CallOption) (*api.UsageReportResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UsageReport", varargs...)
+	ret0, _ := ret[0].(*api.UsageReportResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsageReport indicates an expected call of UsageReport.
+func (mr *MockAdminClientMockRecorder) UsageReport(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsageReport", reflect.TypeOf((*MockAdminClient)(nil).UsageReport), varargs...)
+}
+
+// UserGenerate mocks base method.
+func (m *MockAdminClient) UserGenerate(ctx context.Context, in *api.UserGenerateRequest, opts ...grpc.CallOption) (*api.UserGenerateResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserGenerate", varargs...)
+	ret0, _ := ret[0].(*api.UserGenerateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGenerate indicates an expected call of UserGenerate.
+func (mr *MockAdminClientMockRecorder) UserGenerate(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGenerate", reflect.TypeOf((*MockAdminClient)(nil).UserGenerate), varargs...)
+}
+
+// UserListAt mocks base method.
+func (m *MockAdminClient) UserListAt(ctx context.Context, in *api.UserListAtRequest, opts ...grpc.CallOption) (*api.UserListAtResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserListAt", varargs...)
+	ret0, _ := ret[0].(*api.UserListAtResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserListAt indicates an expected call of UserListAt.
+func (mr *MockAdminClientMockRecorder) UserListAt(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserListAt", reflect.TypeOf((*MockAdminClient)(nil).UserListAt), varargs...)
+}
+
+// UserStateAt mocks base method.
+func (m *MockAdminClient) UserStateAt(ctx context.Context, in *api.UserStateAtRequest, opts ...grpc.CallOption) (*api.UserStateAtResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserStateAt", varargs...)
+	ret0, _ := ret[0].(*api.UserStateAtResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserStateAt indicates an expected call of UserStateAt.
+func (mr *MockAdminClientMockRecorder) UserStateAt(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserStateAt", reflect.TypeOf((*MockAdminClient)(nil).UserStateAt), varargs...)
+}
+
+// VerifyData mocks base method.
+func (m *MockAdminClient) VerifyData(ctx context.Context, in *api.VerifyDataRequest, opts ...grpc.CallOption) (*api.VerifyDataResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyData", varargs...)
+	ret0, _ := ret[0].(*api.VerifyDataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyData indicates an expected call of VerifyData.
+func (mr *MockAdminClientMockRecorder) VerifyData(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyData", reflect.TypeOf((*MockAdminClient)(nil).VerifyData), varargs...)
+}
+
+// MockAdmin_BackupCreateClient is a mock of Admin_BackupCreateClient interface.
+type MockAdmin_BackupCreateClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_BackupCreateClientMockRecorder
+}
+
+// MockAdmin_BackupCreateClientMockRecorder is the mock recorder for MockAdmin_BackupCreateClient.
+type MockAdmin_BackupCreateClientMockRecorder struct {
+	mock *MockAdmin_BackupCreateClient
+}
+
+// NewMockAdmin_BackupCreateClient creates a new mock instance.
+func NewMockAdmin_BackupCreateClient(ctrl *gomock.Controller) *MockAdmin_BackupCreateClient {
+	mock := &MockAdmin_BackupCreateClient{ctrl: ctrl}
+	mock.recorder = &MockAdmin_BackupCreateClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_BackupCreateClient) EXPECT() *MockAdmin_BackupCreateClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockAdmin_BackupCreateClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAdmin_BackupCreateClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAdmin_BackupCreateClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockAdmin_BackupCreateClient) Recv() (*api.BackupCreateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.BackupCreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_BackupCreateClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_BackupCreateClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAdmin_BackupCreateClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAdmin_BackupCreateClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAdmin_BackupCreateClient)(nil).Trailer))
+}
+
+// MockAdmin_BackupRestoreClient is a mock of Admin_BackupRestoreClient interface.
+type MockAdmin_BackupRestoreClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_BackupRestoreClientMockRecorder
+}
+
+// MockAdmin_BackupRestoreClientMockRecorder is the mock recorder for MockAdmin_BackupRestoreClient.
+type MockAdmin_BackupRestoreClientMockRecorder struct {
+	mock *MockAdmin_BackupRestoreClient
+}
+
+// NewMockAdmin_BackupRestoreClient creates a new mock instance.
+func NewMockAdmin_BackupRestoreClient(ctrl *gomock.Controller) *MockAdmin_BackupRestoreClient {
+	mock := &MockAdmin_BackupRestoreClient{ctrl: ctrl}
+	mock.recorder = &MockAdmin_BackupRestoreClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_BackupRestoreClient) EXPECT() *MockAdmin_BackupRestoreClientMockRecorder {
+	return m.recorder
+}
+
+// CloseAndRecv mocks base method.
+func (m *MockAdmin_BackupRestoreClient) CloseAndRecv() (*api.BackupRestoreResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*api.BackupRestoreResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).CloseAndRecv))
+}
+
+// CloseSend mocks base method.
+func (m *MockAdmin_BackupRestoreClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAdmin_BackupRestoreClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAdmin_BackupRestoreClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).Header))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_BackupRestoreClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAdmin_BackupRestoreClient) Send(arg0 *api.BackupRestoreRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_BackupRestoreClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAdmin_BackupRestoreClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAdmin_BackupRestoreClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAdmin_BackupRestoreClient)(nil).Trailer))
+}
+
+// MockAdmin_CacheStateExportClient is a mock of Admin_CacheStateExportClient interface.
+type MockAdmin_CacheStateExportClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_CacheStateExportClientMockRecorder
+}
+
+// MockAdmin_CacheStateExportClientMockRecorder is the mock recorder for MockAdmin_CacheStateExportClient.
+type MockAdmin_CacheStateExportClientMockRecorder struct {
+	mock *MockAdmin_CacheStateExportClient
+}
+
+// NewMockAdmin_CacheStateExportClient creates a new mock instance.
+func NewMockAdmin_CacheStateExportClient(ctrl *gomock.Controller) *MockAdmin_CacheStateExportClient {
+	mock := &MockAdmin_CacheStateExportClient{ctrl: ctrl}
+	mock.recorder = &MockAdmin_CacheStateExportClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_CacheStateExportClient) EXPECT() *MockAdmin_CacheStateExportClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockAdmin_CacheStateExportClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAdmin_CacheStateExportClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAdmin_CacheStateExportClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockAdmin_CacheStateExportClient) Recv() (*api.CacheStateExportResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.CacheStateExportResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_CacheStateExportClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_CacheStateExportClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAdmin_CacheStateExportClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAdmin_CacheStateExportClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAdmin_CacheStateExportClient)(nil).Trailer))
+}
+
+// MockAdminServer is a mock of AdminServer interface.
+type MockAdminServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServerMockRecorder
+}
+
+// MockAdminServerMockRecorder is the mock recorder for MockAdminServer.
+type MockAdminServerMockRecorder struct {
+	mock *MockAdminServer
+}
+
+// NewMockAdminServer creates a new mock instance.
+func NewMockAdminServer(ctrl *gomock.Controller) *MockAdminServer {
+	mock := &MockAdminServer{ctrl: ctrl}
+	mock.recorder = &MockAdminServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminServer) EXPECT() *MockAdminServerMockRecorder {
+	return m.recorder
+}
+
+// AnomalyConfirm mocks base method.
+func (m *MockAdminServer) AnomalyConfirm(arg0 context.Context, arg1 *api.AnomalyConfirmRequest) (*api.AnomalyConfirmResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnomalyConfirm", arg0, arg1)
+	ret0, _ := ret[0].(*api.AnomalyConfirmResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnomalyConfirm indicates an expected call of AnomalyConfirm.
+func (mr *MockAdminServerMockRecorder) AnomalyConfirm(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnomalyConfirm", reflect.TypeOf((*MockAdminServer)(nil).AnomalyConfirm), arg0, arg1)
+}
+
+// AnomalyList mocks base method.
+func (m *MockAdminServer) AnomalyList(arg0 context.Context, arg1 *api.AnomalyListRequest) (*api.AnomalyListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnomalyList", arg0, arg1)
+	ret0, _ := ret[0].(*api.AnomalyListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnomalyList indicates an expected call of AnomalyList.
+func (mr *MockAdminServerMockRecorder) AnomalyList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnomalyList", reflect.TypeOf((*MockAdminServer)(nil).AnomalyList), arg0, arg1)
+}
+
+// BackupCreate mocks base method.
+func (m *MockAdminServer) BackupCreate(arg0 *api.BackupCreateRequest, arg1 api.Admin_BackupCreateServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupCreate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BackupCreate indicates an expected call of BackupCreate.
+func (mr *MockAdminServerMockRecorder) BackupCreate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupCreate", reflect.TypeOf((*MockAdminServer)(nil).BackupCreate), arg0, arg1)
+}
+
+// BackupRestore mocks base method.
+func (m *MockAdminServer) BackupRestore(arg0 api.Admin_BackupRestoreServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupRestore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BackupRestore indicates an expected call of BackupRestore.
+func (mr *MockAdminServerMockRecorder) BackupRestore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupRestore", reflect.TypeOf((*MockAdminServer)(nil).BackupRestore), arg0)
+}
+
+// CacheEvict mocks base method.
+func (m *MockAdminServer) CacheEvict(arg0 context.Context, arg1 *api.CacheEvictRequest) (*api.CacheEvictResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheEvict", arg0, arg1)
+	ret0, _ := ret[0].(*api.CacheEvictResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheEvict indicates an expected call of CacheEvict.
+func (mr *MockAdminServerMockRecorder) CacheEvict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheEvict", reflect.TypeOf((*MockAdminServer)(nil).CacheEvict), arg0, arg1)
+}
+
+// CacheGet mocks base method.
+func (m *MockAdminServer) CacheGet(arg0 context.Context, arg1 *api.CacheGetRequest) (*api.CacheGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.CacheGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheGet indicates an expected call of CacheGet.
+func (mr *MockAdminServerMockRecorder) CacheGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheGet", reflect.TypeOf((*MockAdminServer)(nil).CacheGet), arg0, arg1)
+}
+
+// CacheStateExport mocks base method.
+func (m *MockAdminServer) CacheStateExport(arg0 *api.CacheStateExportRequest, arg1 api.Admin_CacheStateExportServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStateExport", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CacheStateExport indicates an expected call of CacheStateExport.
+func (mr *MockAdminServerMockRecorder) CacheStateExport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStateExport", reflect.TypeOf((*MockAdminServer)(nil).CacheStateExport), arg0, arg1)
+}
+
+// CacheStats mocks base method.
+func (m *MockAdminServer) CacheStats(arg0 context.Context, arg1 *api.CacheStatsRequest) (*api.CacheStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStats", arg0, arg1)
+	ret0, _ := ret[0].(*api.CacheStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CacheStats indicates an expected call of CacheStats.
+func (mr *MockAdminServerMockRecorder) CacheStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockAdminServer)(nil).CacheStats), arg0, arg1)
+}
+
+// DenylistAdd mocks base method.
+func (m *MockAdminServer) DenylistAdd(arg0 context.Context, arg1 *api.DenylistAddRequest) (*api.DenylistAddResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenylistAdd", arg0, arg1)
+	ret0, _ := ret[0].(*api.DenylistAddResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistAdd indicates an expected call of DenylistAdd.
+func (mr *MockAdminServerMockRecorder) DenylistAdd(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistAdd", reflect.TypeOf((*MockAdminServer)(nil).DenylistAdd), arg0, arg1)
+}
+
+// DenylistList mocks base method.
+func (m *MockAdminServer) DenylistList(arg0 context.Context, arg1 *api.DenylistListRequest) (*api.DenylistListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenylistList", arg0, arg1)
+	ret0, _ := ret[0].(*api.DenylistListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistList indicates an expected call of DenylistList.
+func (mr *MockAdminServerMockRecorder) DenylistList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistList", reflect.TypeOf((*MockAdminServer)(nil).DenylistList), arg0, arg1)
+}
+
+// DenylistRemove mocks base method.
+func (m *MockAdminServer) DenylistRemove(arg0 context.Context, arg1 *api.DenylistRemoveRequest) (*api.DenylistRemoveResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenylistRemove", arg0, arg1)
+	ret0, _ := ret[0].(*api.DenylistRemoveResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenylistRemove indicates an expected call of DenylistRemove.
+func (mr *MockAdminServerMockRecorder) DenylistRemove(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenylistRemove", reflect.TypeOf((*MockAdminServer)(nil).DenylistRemove), arg0, arg1)
+}
+
+// ImpersonationList mocks base method.
+func (m *MockAdminServer) ImpersonationList(arg0 context.Context, arg1 *api.ImpersonationListRequest) (*api.ImpersonationListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImpersonationList", arg0, arg1)
+	ret0, _ := ret[0].(*api.ImpersonationListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImpersonationList indicates an expected call of ImpersonationList.
+func (mr *MockAdminServerMockRecorder) ImpersonationList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImpersonationList", reflect.TypeOf((*MockAdminServer)(nil).ImpersonationList), arg0, arg1)
+}
+
+// MaintenanceGet mocks base method.
+func (m *MockAdminServer) MaintenanceGet(arg0 context.Context, arg1 *api.MaintenanceGetRequest) (*api.MaintenanceGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaintenanceGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.MaintenanceGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaintenanceGet indicates an expected call of MaintenanceGet.
+func (mr *MockAdminServerMockRecorder) MaintenanceGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaintenanceGet", reflect.TypeOf((*MockAdminServer)(nil).MaintenanceGet), arg0, arg1)
+}
+
+// MaintenanceSet mocks base method.
+func (m *MockAdminServer) MaintenanceSet(arg0 context.Context, arg1 *api.MaintenanceSetRequest) (*api.MaintenanceSetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaintenanceSet", arg0, arg1)
+	ret0, _ := ret[0].(*api.MaintenanceSetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaintenanceSet indicates an expected call of MaintenanceSet.
+func (mr *MockAdminServerMockRecorder) MaintenanceSet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaintenanceSet", reflect.TypeOf((*MockAdminServer)(nil).MaintenanceSet), arg0, arg1)
+}
+
+// OperationApprove mocks base method.
+func (m *MockAdminServer) OperationApprove(arg0 context.Context, arg1 *api.OperationApproveRequest) (*api.OperationApproveResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OperationApprove", arg0, arg1)
+	ret0, _ := ret[0].(*api.OperationApproveResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OperationApprove indicates an expected call of OperationApprove.
+func (mr *MockAdminServerMockRecorder) OperationApprove(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OperationApprove", reflect.TypeOf((*MockAdminServer)(nil).OperationApprove), arg0, arg1)
+}
+
+// OperationList mocks base method.
+func (m *MockAdminServer) OperationList(arg0 context.Context, arg1 *api.OperationListRequest) (*api.OperationListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OperationList", arg0, arg1)
+	ret0, _ := ret[0].(*api.OperationListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OperationList indicates an expected call of OperationList.
+func (mr *MockAdminServerMockRecorder) OperationList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OperationList", reflect.TypeOf((*MockAdminServer)(nil).OperationList), arg0, arg1)
+}
+
+// PasswordExpire mocks base method.
+func (m *MockAdminServer) PasswordExpire(arg0 context.Context, arg1 *api.PasswordExpireRequest) (*api.PasswordExpireResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordExpire", arg0, arg1)
+	ret0, _ := ret[0].(*api.PasswordExpireResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PasswordExpire indicates an expected call of PasswordExpire.
+func (mr *MockAdminServerMockRecorder) PasswordExpire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordExpire", reflect.TypeOf((*MockAdminServer)(nil).PasswordExpire), arg0, arg1)
+}
+
+// ReindexStart mocks base method.
+func (m *MockAdminServer) ReindexStart(arg0 context.Context, arg1 *api.ReindexStartRequest) (*api.ReindexStartResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReindexStart", arg0, arg1)
+	ret0, _ := ret[0].(*api.ReindexStartResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexStart indicates an expected call of ReindexStart.
+func (mr *MockAdminServerMockRecorder) ReindexStart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexStart", reflect.TypeOf((*MockAdminServer)(nil).ReindexStart), arg0, arg1)
+}
+
+// ReindexStatus mocks base method.
+func (m *MockAdminServer) ReindexStatus(arg0 context.Context, arg1 *api.ReindexStatusRequest) (*api.ReindexStatusResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReindexStatus", arg0, arg1)
+	ret0, _ := ret[0].(*api.ReindexStatusResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexStatus indicates an expected call of ReindexStatus.
+func (mr *MockAdminServerMockRecorder) ReindexStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexStatus", reflect.TypeOf((*MockAdminServer)(nil).ReindexStatus), arg0, arg1)
+}
+
+// TenantOverridesDelete mocks base method.
+func (m *MockAdminServer) TenantOverridesDelete(arg0 context.Context, arg1 *api.TenantOverridesDeleteRequest) (*api.TenantOverridesDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantOverridesDelete", arg0, arg1)
+	ret0, _ := ret[0].(*api.TenantOverridesDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesDelete indicates an expected call of TenantOverridesDelete.
+func (mr *MockAdminServerMockRecorder) TenantOverridesDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesDelete", reflect.TypeOf((*MockAdminServer)(nil).TenantOverridesDelete), arg0, arg1)
+}
+
+// TenantOverridesGet mocks base method.
+func (m *MockAdminServer) TenantOverridesGet(arg0 context.Context, arg1 *api.TenantOverridesGetRequest) (*api.TenantOverridesGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantOverridesGet", arg0, arg1)
+	ret0, _ := ret[0].(*api.TenantOverridesGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesGet indicates an expected call of TenantOverridesGet.
+func (mr *MockAdminServerMockRecorder) TenantOverridesGet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesGet", reflect.TypeOf((*MockAdminServer)(nil).TenantOverridesGet), arg0, arg1)
+}
+
+// TenantOverridesList mocks base method.
+func (m *MockAdminServer) TenantOverridesList(arg0 context.Context, arg1 *api.TenantOverridesListRequest) (*api.TenantOverridesListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantOverridesList", arg0, arg1)
+	ret0, _ := ret[0].(*api.TenantOverridesListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesList indicates an expected call of TenantOverridesList.
+func (mr *MockAdminServerMockRecorder) TenantOverridesList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesList", reflect.TypeOf((*MockAdminServer)(nil).TenantOverridesList), arg0, arg1)
+}
+
+// TenantOverridesSet mocks base method.
+func (m *MockAdminServer) TenantOverridesSet(arg0 context.Context, arg1 *api.TenantOverridesSetRequest) (*api.TenantOverridesSetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantOverridesSet", arg0, arg1)
+	ret0, _ := ret[0].(*api.TenantOverridesSetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantOverridesSet indicates an expected call of TenantOverridesSet.
+func (mr *MockAdminServerMockRecorder) TenantOverridesSet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantOverridesSet", reflect.TypeOf((*MockAdminServer)(nil).TenantOverridesSet), arg0, arg1)
+}
+
+// UsageReport mocks base method.
+func (m *MockAdminServer) UsageReport(arg0 context.Context, arg1 *api.UsageReportRequest) (*api.UsageReportResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsageReport", arg0, arg1)
+	ret0, _ := ret[0].(*api.UsageReportResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsageReport indicates an expected call of UsageReport.
+func (mr *MockAdminServerMockRecorder) UsageReport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsageReport", reflect.TypeOf((*MockAdminServer)(nil).UsageReport), arg0, arg1)
+}
+
+// UserGenerate mocks base method.
+func (m *MockAdminServer) UserGenerate(arg0 context.Context, arg1 *api.UserGenerateRequest) (*api.UserGenerateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGenerate", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserGenerateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserGenerate indicates an expected call of UserGenerate.
+func (mr *MockAdminServerMockRecorder) UserGenerate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGenerate", reflect.TypeOf((*MockAdminServer)(nil).UserGenerate), arg0, arg1)
+}
+
+// UserListAt mocks base method.
+func (m *MockAdminServer) UserListAt(arg0 context.Context, arg1 *api.UserListAtRequest) (*api.UserListAtResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserListAt", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserListAtResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserListAt indicates an expected call of UserListAt.
+func (mr *MockAdminServerMockRecorder) UserListAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserListAt", reflect.TypeOf((*MockAdminServer)(nil).UserListAt), arg0, arg1)
+}
+
+// UserStateAt mocks base method.
+func (m *MockAdminServer) UserStateAt(arg0 context.Context, arg1 *api.UserStateAtRequest) (*api.UserStateAtResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserStateAt", arg0, arg1)
+	ret0, _ := ret[0].(*api.UserStateAtResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserStateAt indicates an expected call of UserStateAt.
+func (mr *MockAdminServerMockRecorder) UserStateAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserStateAt", reflect.TypeOf((*MockAdminServer)(nil).UserStateAt), arg0, arg1)
+}
+
+// VerifyData mocks base method.
+func (m *MockAdminServer) VerifyData(arg0 context.Context, arg1 *api.VerifyDataRequest) (*api.VerifyDataResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyData", arg0, arg1)
+	ret0, _ := ret[0].(*api.VerifyDataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyData indicates an expected call of VerifyData.
+func (mr *MockAdminServerMockRecorder) VerifyData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyData", reflect.TypeOf((*MockAdminServer)(nil).VerifyData), arg0, arg1)
+}
+
+// mustEmbedUnimplementedAdminServer mocks base method.
+func (m *MockAdminServer) mustEmbedUnimplementedAdminServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedAdminServer")
+}
+
+// mustEmbedUnimplementedAdminServer indicates an expected call of mustEmbedUnimplementedAdminServer.
+func (mr *MockAdminServerMockRecorder) mustEmbedUnimplementedAdminServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedAdminServer", reflect.TypeOf((*MockAdminServer)(nil).mustEmbedUnimplementedAdminServer))
+}
+
+// MockUnsafeAdminServer is a mock of UnsafeAdminServer interface.
+type MockUnsafeAdminServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnsafeAdminServerMockRecorder
+}
+
+// MockUnsafeAdminServerMockRecorder is the mock recorder for MockUnsafeAdminServer.
+type MockUnsafeAdminServerMockRecorder struct {
+	mock *MockUnsafeAdminServer
+}
+
+// NewMockUnsafeAdminServer creates a new mock instance.
+func NewMockUnsafeAdminServer(ctrl *gomock.Controller) *MockUnsafeAdminServer {
+	mock := &MockUnsafeAdminServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeAdminServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnsafeAdminServer) EXPECT() *MockUnsafeAdminServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedAdminServer mocks base method.
+func (m *MockUnsafeAdminServer) mustEmbedUnimplementedAdminServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedAdminServer")
+}
+
+// mustEmbedUnimplementedAdminServer indicates an expected call of mustEmbedUnimplementedAdminServer.
+func (mr *MockUnsafeAdminServerMockRecorder) mustEmbedUnimplementedAdminServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedAdminServer", reflect.TypeOf((*MockUnsafeAdminServer)(nil).mustEmbedUnimplementedAdminServer))
+}
+
+// MockAdmin_BackupCreateServer is a mock of Admin_BackupCreateServer interface.
+type MockAdmin_BackupCreateServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_BackupCreateServerMockRecorder
+}
+
+// MockAdmin_BackupCreateServerMockRecorder is the mock recorder for MockAdmin_BackupCreateServer.
+type MockAdmin_BackupCreateServerMockRecorder struct {
+	mock *MockAdmin_BackupCreateServer
+}
+
+// NewMockAdmin_BackupCreateServer creates a new mock instance.
+func NewMockAdmin_BackupCreateServer(ctrl *gomock.Controller) *MockAdmin_BackupCreateServer {
+	mock := &MockAdmin_BackupCreateServer{ctrl: ctrl}
+	mock.recorder = &MockAdmin_BackupCreateServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_BackupCreateServer) EXPECT() *MockAdmin_BackupCreateServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAdmin_BackupCreateServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_BackupCreateServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAdmin_BackupCreateServer) Send(arg0 *api.BackupCreateResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAdmin_BackupCreateServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_BackupCreateServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAdmin_BackupCreateServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAdmin_BackupCreateServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAdmin_BackupCreateServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAdmin_BackupCreateServer)(nil).SetTrailer), arg0)
+}
+
+// MockAdmin_BackupRestoreServer is a mock of Admin_BackupRestoreServer interface.
+type MockAdmin_BackupRestoreServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_BackupRestoreServerMockRecorder
+}
+
+// MockAdmin_BackupRestoreServerMockRecorder is the mock recorder for MockAdmin_BackupRestoreServer.
+type MockAdmin_BackupRestoreServerMockRecorder struct {
+	mock *MockAdmin_BackupRestoreServer
+}
+
+// NewMockAdmin_BackupRestoreServer creates a new mock instance.
+func NewMockAdmin_BackupRestoreServer(ctrl *gomock.Controller) *MockAdmin_BackupRestoreServer {
+	mock := &MockAdmin_BackupRestoreServer{ctrl: ctrl}
+	mock.recorder = &MockAdmin_BackupRestoreServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_BackupRestoreServer) EXPECT() *MockAdmin_BackupRestoreServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAdmin_BackupRestoreServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockAdmin_BackupRestoreServer) Recv() (*api.BackupRestoreRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.BackupRestoreRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_BackupRestoreServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).RecvMsg), m)
+}
+
+// SendAndClose mocks base method.
+func (m *MockAdmin_BackupRestoreServer) SendAndClose(arg0 *api.BackupRestoreResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).SendAndClose), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAdmin_BackupRestoreServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_BackupRestoreServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAdmin_BackupRestoreServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAdmin_BackupRestoreServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAdmin_BackupRestoreServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAdmin_BackupRestoreServer)(nil).SetTrailer), arg0)
+}
+
+// MockAdmin_CacheStateExportServer is a mock of Admin_CacheStateExportServer interface.
+type MockAdmin_CacheStateExportServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdmin_CacheStateExportServerMockRecorder
+}
+
+// MockAdmin_CacheStateExportServerMockRecorder is the mock recorder for MockAdmin_CacheStateExportServer.
+type MockAdmin_CacheStateExportServerMockRecorder struct {
+	mock *MockAdmin_CacheStateExportServer
+}
+
+// NewMockAdmin_CacheStateExportServer creates a new mock instance.
+func NewMockAdmin_CacheStateExportServer(ctrl *gomock.Controller) *MockAdmin_CacheStateExportServer {
+	mock := &MockAdmin_CacheStateExportServer{ctrl: ctrl}
+	mock.recorder = &MockAdmin_CacheStateExportServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin_CacheStateExportServer) EXPECT() *MockAdmin_CacheStateExportServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAdmin_CacheStateExportServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAdmin_CacheStateExportServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAdmin_CacheStateExportServer) Send(arg0 *api.CacheStateExportResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAdmin_CacheStateExportServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAdmin_CacheStateExportServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAdmin_CacheStateExportServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAdmin_CacheStateExportServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAdmin_CacheStateExportServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAdmin_CacheStateExportServer)(nil).SetTrailer), arg0)
+}
+
+// MockReplicaClient is a mock of ReplicaClient interface.
+type MockReplicaClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplicaClientMockRecorder
+}
+
+// MockReplicaClientMockRecorder is the mock recorder for MockReplicaClient.
+type MockReplicaClientMockRecorder struct {
+	mock *MockReplicaClient
+}
+
+// NewMockReplicaClient creates a new mock instance.
+func NewMockReplicaClient(ctrl *gomock.Controller) *MockReplicaClient {
+	mock := &MockReplicaClient{ctrl: ctrl}
+	mock.recorder = &MockReplicaClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplicaClient) EXPECT() *MockReplicaClientMockRecorder {
+	return m.recorder
+}
+
+// ReplicaApply mocks base method.
+func (m *MockReplicaClient) ReplicaApply(ctx context.Context, in *api.ReplicaApplyRequest, opts ...grpc.CallOption) (*api.ReplicaApplyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReplicaApply", varargs...)
+	ret0, _ := ret[0].(*api.ReplicaApplyResponse)
+	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UserCreate indicates an expected call of UserCreate.
-func (mr *MockUserServerMockRecorder) UserCreate(arg0, arg1 interface{}) *gomock.Call {
+// ReplicaApply indicates an expected call of ReplicaApply.
+func (mr *MockReplicaClientMockRecorder) ReplicaApply(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserCreate", reflect.TypeOf((*MockUserServer)(nil).UserCreate), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaApply", reflect.TypeOf((*MockReplicaClient)(nil).ReplicaApply), varargs...)
 }
 
-// UserDelete mocks base method.
-func (m *MockUserServer) UserDelete(arg0 context.Context, arg1 *api.UserDeleteRequest) (*api.UserDeleteResponse, error) {
+// ReplicaCatchUp mocks base method.
+func (m *MockReplicaClient) ReplicaCatchUp(ctx context.Context, opts ...grpc.CallOption) (api.Replica_ReplicaCatchUpClient, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserDelete", arg0, arg1)
-	ret0, _ := ret[0].(*api.UserDeleteResponse)
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReplicaCatchUp", varargs...)
+	ret0, _ := ret[0].(api.Replica_ReplicaCatchUpClient)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UserDelete indicates an expected call of UserDelete.
-func (mr *MockUserServerMockRecorder) UserDelete(arg0, arg1 interface{}) *gomock.Call {
+// ReplicaCatchUp indicates an expected call of ReplicaCatchUp.
+func (mr *MockReplicaClientMockRecorder) ReplicaCatchUp(ctx interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserDelete", reflect.TypeOf((*MockUserServer)(nil).UserDelete), arg0, arg1)
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaCatchUp", reflect.TypeOf((*MockReplicaClient)(nil).ReplicaCatchUp), varargs...)
 }
 
-// UserGet mocks base method.
-func (m *MockUserServer) UserGet(arg0 context.Context, arg1 *api.UserGetRequest) (*api.UserGetResponse, error) {
+// ReplicaConflicts mocks base method.
+func (m *MockReplicaClient) ReplicaConflicts(ctx context.Context, in *api.ReplicaConflictsRequest, opts ...grpc.CallOption) (*api.ReplicaConflictsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserGet", arg0, arg1)
-	ret0, _ := ret[0].(*api.UserGetResponse)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReplicaConflicts", varargs...)
+	ret0, _ := ret[0].(*api.ReplicaConflictsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UserGet indicates an expected call of UserGet.
-func (mr *MockUserServerMockRecorder) UserGet(arg0, arg1 interface{}) *gomock.Call {
+// ReplicaConflicts indicates an expected call of ReplicaConflicts.
+func (mr *MockReplicaClientMockRecorder) ReplicaConflicts(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGet", reflect.TypeOf((*MockUserServer)(nil).UserGet), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaConflicts", reflect.TypeOf((*MockReplicaClient)(nil).ReplicaConflicts), varargs...)
 }
 
-// UserList mocks base method.
-func (m *MockUserServer) UserList(arg0 context.Context, arg1 *api.UserListRequest) (*api.UserListResponse, error) {
+// MockReplica_ReplicaCatchUpClient is a mock of Replica_ReplicaCatchUpClient interface.
+type MockReplica_ReplicaCatchUpClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplica_ReplicaCatchUpClientMockRecorder
+}
+
+// MockReplica_ReplicaCatchUpClientMockRecorder is the mock recorder for MockReplica_ReplicaCatchUpClient.
+type MockReplica_ReplicaCatchUpClientMockRecorder struct {
+	mock *MockReplica_ReplicaCatchUpClient
+}
+
+// NewMockReplica_ReplicaCatchUpClient creates a new mock instance.
+func NewMockReplica_ReplicaCatchUpClient(ctrl *gomock.Controller) *MockReplica_ReplicaCatchUpClient {
+	mock := &MockReplica_ReplicaCatchUpClient{ctrl: ctrl}
+	mock.recorder = &MockReplica_ReplicaCatchUpClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplica_ReplicaCatchUpClient) EXPECT() *MockReplica_ReplicaCatchUpClientMockRecorder {
+	return m.recorder
+}
+
+// CloseAndRecv mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) CloseAndRecv() (*api.ReplicaCatchUpResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserList", arg0, arg1)
-	ret0, _ := ret[0].(*api.UserListResponse)
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*api.ReplicaCatchUpResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UserList indicates an expected call of UserList.
-func (mr *MockUserServerMockRecorder) UserList(arg0, arg1 interface{}) *gomock.Call {
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) CloseAndRecv() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserList", reflect.TypeOf((*MockUserServer)(nil).UserList), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).CloseAndRecv))
 }
 
-// UserUpdate mocks base method.
-func (m *MockUserServer) UserUpdate(arg0 context.Context, arg1 *api.UserUpdateRequest) (*api.UserUpdateResponse, error) {
+// CloseSend mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) CloseSend() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserUpdate", arg0, arg1)
-	ret0, _ := ret[0].(*api.UserUpdateResponse)
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UserUpdate indicates an expected call of UserUpdate.
-func (mr *MockUserServerMockRecorder) UserUpdate(arg0, arg1 interface{}) *gomock.Call {
+// Header indicates an expected call of Header.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) Header() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserUpdate", reflect.TypeOf((*MockUserServer)(nil).UserUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).Header))
 }
 
-// mustEmbedUnimplementedUserServer mocks base method.
-func (m *MockUserServer) mustEmbedUnimplementedUserServer() {
+// RecvMsg mocks base method.
+func (m_2 *MockReplica_ReplicaCatchUpClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) Send(arg0 *api.ReplicaCatchUpRequest) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "mustEmbedUnimplementedUserServer")
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// mustEmbedUnimplementedUserServer indicates an expected call of mustEmbedUnimplementedUserServer.
-func (mr *MockUserServerMockRecorder) mustEmbedUnimplementedUserServer() *gomock.Call {
+// Send indicates an expected call of Send.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedUserServer", reflect.TypeOf((*MockUserServer)(nil).mustEmbedUnimplementedUserServer))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).Send), arg0)
 }
 
-// MockUnsafeUserServer is a mock of UnsafeUserServer interface.
-type MockUnsafeUserServer struct {
+// SendMsg mocks base method.
+func (m_2 *MockReplica_ReplicaCatchUpClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockReplica_ReplicaCatchUpClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockReplica_ReplicaCatchUpClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockReplica_ReplicaCatchUpClient)(nil).Trailer))
+}
+
+// MockReplicaServer is a mock of ReplicaServer interface.
+type MockReplicaServer struct {
 	ctrl     *gomock.Controller
-	recorder *MockUnsafeUserServerMockRecorder
+	recorder *MockReplicaServerMockRecorder
 }
 
-// MockUnsafeUserServerMockRecorder is the mock recorder for MockUnsafeUserServer.
-type MockUnsafeUserServerMockRecorder struct {
-	mock *MockUnsafeUserServer
+// MockReplicaServerMockRecorder is the mock recorder for MockReplicaServer.
+type MockReplicaServerMockRecorder struct {
+	mock *MockReplicaServer
 }
 
-// NewMockUnsafeUserServer creates a new mock instance.
-func NewMockUnsafeUserServer(ctrl *gomock.Controller) *MockUnsafeUserServer {
-	mock := &MockUnsafeUserServer{ctrl: ctrl}
-	mock.recorder = &MockUnsafeUserServerMockRecorder{mock}
+// NewMockReplicaServer creates a new mock instance.
+func NewMockReplicaServer(ctrl *gomock.Controller) *MockReplicaServer {
+	mock := &MockReplicaServer{ctrl: ctrl}
+	mock.recorder = &MockReplicaServerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockUnsafeUserServer) EXPECT() *MockUnsafeUserServerMockRecorder {
+func (m *MockReplicaServer) EXPECT() *MockReplicaServerMockRecorder {
 	return m.recorder
 }
 
-// mustEmbedUnimplementedUserServer mocks base method.
-func (m *MockUnsafeUserServer) mustEmbedUnimplementedUserServer() {
+// ReplicaApply mocks base method.
+func (m *MockReplicaServer) ReplicaApply(arg0 context.Context, arg1 *api.ReplicaApplyRequest) (*api.ReplicaApplyResponse, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "mustEmbedUnimplementedUserServer")
+	ret := m.ctrl.Call(m, "ReplicaApply", arg0, arg1)
+	ret0, _ := ret[0].(*api.ReplicaApplyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// mustEmbedUnimplementedUserServer indicates an expected call of mustEmbedUnimplementedUserServer.
-func (mr *MockUnsafeUserServerMockRecorder) mustEmbedUnimplementedUserServer() *gomock.Call {
+// ReplicaApply indicates an expected call of ReplicaApply.
+func (mr *MockReplicaServerMockRecorder) ReplicaApply(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedUserServer", reflect.TypeOf((*MockUnsafeUserServer)(nil).mustEmbedUnimplementedUserServer))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaApply", reflect.TypeOf((*MockReplicaServer)(nil).ReplicaApply), arg0, arg1)
 }
 
-// MockUser_UserAllListServer is a mock of User_UserAllListServer interface.
-type MockUser_UserAllListServer struct {
+// ReplicaCatchUp mocks base method.
+func (m *MockReplicaServer) ReplicaCatchUp(arg0 api.Replica_ReplicaCatchUpServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicaCatchUp", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplicaCatchUp indicates an expected call of ReplicaCatchUp.
+func (mr *MockReplicaServerMockRecorder) ReplicaCatchUp(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaCatchUp", reflect.TypeOf((*MockReplicaServer)(nil).ReplicaCatchUp), arg0)
+}
+
+// ReplicaConflicts mocks base method.
+func (m *MockReplicaServer) ReplicaConflicts(arg0 context.Context, arg1 *api.ReplicaConflictsRequest) (*api.ReplicaConflictsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicaConflicts", arg0, arg1)
+	ret0, _ := ret[0].(*api.ReplicaConflictsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplicaConflicts indicates an expected call of ReplicaConflicts.
+func (mr *MockReplicaServerMockRecorder) ReplicaConflicts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaConflicts", reflect.TypeOf((*MockReplicaServer)(nil).ReplicaConflicts), arg0, arg1)
+}
+
+// mustEmbedUnimplementedReplicaServer mocks base method.
+func (m *MockReplicaServer) mustEmbedUnimplementedReplicaServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedReplicaServer")
+}
+
+// mustEmbedUnimplementedReplicaServer indicates an expected call of mustEmbedUnimplementedReplicaServer.
+func (mr *MockReplicaServerMockRecorder) mustEmbedUnimplementedReplicaServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedReplicaServer", reflect.TypeOf((*MockReplicaServer)(nil).mustEmbedUnimplementedReplicaServer))
+}
+
+// MockUnsafeReplicaServer is a mock of UnsafeReplicaServer interface.
+type MockUnsafeReplicaServer struct {
 	ctrl     *gomock.Controller
-	recorder *MockUser_UserAllListServerMockRecorder
+	recorder *MockUnsafeReplicaServerMockRecorder
 }
 
-// MockUser_UserAllListServerMockRecorder is the mock recorder for MockUser_UserAllListServer.
-type MockUser_UserAllListServerMockRecorder struct {
-	mock *MockUser_UserAllListServer
+// MockUnsafeReplicaServerMockRecorder is the mock recorder for MockUnsafeReplicaServer.
+type MockUnsafeReplicaServerMockRecorder struct {
+	mock *MockUnsafeReplicaServer
 }
 
-// NewMockUser_UserAllListServer creates a new mock instance.
-func NewMockUser_UserAllListServer(ctrl *gomock.Controller) *MockUser_UserAllListServer {
-	mock := &MockUser_UserAllListServer{ctrl: ctrl}
-	mock.recorder = &MockUser_UserAllListServerMockRecorder{mock}
+// NewMockUnsafeReplicaServer creates a new mock instance.
+func NewMockUnsafeReplicaServer(ctrl *gomock.Controller) *MockUnsafeReplicaServer {
+	mock := &MockUnsafeReplicaServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeReplicaServerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockUser_UserAllListServer) EXPECT() *MockUser_UserAllListServerMockRecorder {
+func (m *MockUnsafeReplicaServer) EXPECT() *MockUnsafeReplicaServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedReplicaServer mocks base method.
+func (m *MockUnsafeReplicaServer) mustEmbedUnimplementedReplicaServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedReplicaServer")
+}
+
+// mustEmbedUnimplementedReplicaServer indicates an expected call of mustEmbedUnimplementedReplicaServer.
+func (mr *MockUnsafeReplicaServerMockRecorder) mustEmbedUnimplementedReplicaServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedReplicaServer", reflect.TypeOf((*MockUnsafeReplicaServer)(nil).mustEmbedUnimplementedReplicaServer))
+}
+
+// MockReplica_ReplicaCatchUpServer is a mock of Replica_ReplicaCatchUpServer interface.
+type MockReplica_ReplicaCatchUpServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplica_ReplicaCatchUpServerMockRecorder
+}
+
+// MockReplica_ReplicaCatchUpServerMockRecorder is the mock recorder for MockReplica_ReplicaCatchUpServer.
+type MockReplica_ReplicaCatchUpServerMockRecorder struct {
+	mock *MockReplica_ReplicaCatchUpServer
+}
+
+// NewMockReplica_ReplicaCatchUpServer creates a new mock instance.
+func NewMockReplica_ReplicaCatchUpServer(ctrl *gomock.Controller) *MockReplica_ReplicaCatchUpServer {
+	mock := &MockReplica_ReplicaCatchUpServer{ctrl: ctrl}
+	mock.recorder = &MockReplica_ReplicaCatchUpServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplica_ReplicaCatchUpServer) EXPECT() *MockReplica_ReplicaCatchUpServerMockRecorder {
 	return m.recorder
 }
 
 // Context mocks base method.
-func (m *MockUser_UserAllListServer) Context() context.Context {
+func (m *MockReplica_ReplicaCatchUpServer) Context() context.Context {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Context")
 	ret0, _ := ret[0].(context.Context)
@@ -471,13 +4160,28 @@ func (m *MockUser_UserAllListServer) Context() context.Context {
 }
 
 // Context indicates an expected call of Context.
-func (mr *MockUser_UserAllListServerMockRecorder) Context() *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) Context() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockUser_UserAllListServer)(nil).Context))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockReplica_ReplicaCatchUpServer) Recv() (*api.ReplicaCatchUpRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*api.ReplicaCatchUpRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).Recv))
 }
 
 // RecvMsg mocks base method.
-func (m_2 *MockUser_UserAllListServer) RecvMsg(m interface{}) error {
+func (m_2 *MockReplica_ReplicaCatchUpServer) RecvMsg(m interface{}) error {
 	m_2.ctrl.T.Helper()
 	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
 	ret0, _ := ret[0].(error)
@@ -485,27 +4189,27 @@ func (m_2 *MockUser_UserAllListServer) RecvMsg(m interface{}) error {
 }
 
 // RecvMsg indicates an expected call of RecvMsg.
-func (mr *MockUser_UserAllListServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockUser_UserAllListServer)(nil).RecvMsg), m)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).RecvMsg), m)
 }
 
-// Send mocks base method.
-func (m *MockUser_UserAllListServer) Send(arg0 *api.UserAllListResponse) error {
+// SendAndClose mocks base method.
+func (m *MockReplica_ReplicaCatchUpServer) SendAndClose(arg0 *api.ReplicaCatchUpResponse) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", arg0)
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Send indicates an expected call of Send.
-func (mr *MockUser_UserAllListServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockUser_UserAllListServer)(nil).Send), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).SendAndClose), arg0)
 }
 
 // SendHeader mocks base method.
-func (m *MockUser_UserAllListServer) SendHeader(arg0 metadata.MD) error {
+func (m *MockReplica_ReplicaCatchUpServer) SendHeader(arg0 metadata.MD) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendHeader", arg0)
 	ret0, _ := ret[0].(error)
@@ -513,13 +4217,13 @@ func (m *MockUser_UserAllListServer) SendHeader(arg0 metadata.MD) error {
 }
 
 // SendHeader indicates an expected call of SendHeader.
-func (mr *MockUser_UserAllListServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SendHeader), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).SendHeader), arg0)
 }
 
 // SendMsg mocks base method.
-func (m_2 *MockUser_UserAllListServer) SendMsg(m interface{}) error {
+func (m_2 *MockReplica_ReplicaCatchUpServer) SendMsg(m interface{}) error {
 	m_2.ctrl.T.Helper()
 	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
 	ret0, _ := ret[0].(error)
@@ -527,13 +4231,13 @@ func (m_2 *MockUser_UserAllListServer) SendMsg(m interface{}) error {
 }
 
 // SendMsg indicates an expected call of SendMsg.
-func (mr *MockUser_UserAllListServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SendMsg), m)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).SendMsg), m)
 }
 
 // SetHeader mocks base method.
-func (m *MockUser_UserAllListServer) SetHeader(arg0 metadata.MD) error {
+func (m *MockReplica_ReplicaCatchUpServer) SetHeader(arg0 metadata.MD) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetHeader", arg0)
 	ret0, _ := ret[0].(error)
@@ -541,19 +4245,19 @@ func (m *MockUser_UserAllListServer) SetHeader(arg0 metadata.MD) error {
 }
 
 // SetHeader indicates an expected call of SetHeader.
-func (mr *MockUser_UserAllListServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SetHeader), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).SetHeader), arg0)
 }
 
 // SetTrailer mocks base method.
-func (m *MockUser_UserAllListServer) SetTrailer(arg0 metadata.MD) {
+func (m *MockReplica_ReplicaCatchUpServer) SetTrailer(arg0 metadata.MD) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetTrailer", arg0)
 }
 
 // SetTrailer indicates an expected call of SetTrailer.
-func (mr *MockUser_UserAllListServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+func (mr *MockReplica_ReplicaCatchUpServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockUser_UserAllListServer)(nil).SetTrailer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockReplica_ReplicaCatchUpServer)(nil).SetTrailer), arg0)
 }
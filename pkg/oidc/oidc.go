@@ -0,0 +1,303 @@
+// Package oidc implements authorization code flow of OpenID Connect relying party.
+// ID tokens signed by RS256 are accepted only.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var ErrInvalidToken = errors.New("invalid id token")
+
+// Config of the identity provider. AutoProvision and SessionTTL are used
+// by the data service, which maps identities to local users.
+type Config struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+	// AutoProvision creates local user on the first login of unknown subject.
+	AutoProvision bool          `mapstructure:"auto_provision"`
+	SessionTTL    time.Duration `mapstructure:"session_ttl"`
+}
+
+// Claims of the verified ID token.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	Expiry            int64    `json:"exp"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// audience is a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]*rsa.PublicKey
+}
+
+func New(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		clock:  clock.Real(),
+	}
+}
+
+// AuthCodeURL returns URL of the provider login page.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.cfg.ClientID},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"scope":         {strings.Join(p.cfg.Scopes, " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	return d.AuthorizationEndpoint + "?" + query.Encode(), nil
+}
+
+// Exchange redeems authorization code and returns claims of the verified ID token.
+func (p *Provider) Exchange(ctx context.Context, code, nonce string) (Claims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, errors.Wrap(err, "oidc token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err = p.do(req, &token); err != nil {
+		return Claims{}, errors.Wrap(err, "oidc token")
+	}
+	if token.IDToken == "" {
+		return Claims{}, errors.Wrap(ErrInvalidToken, "no id_token in response")
+	}
+	return p.Verify(ctx, token.IDToken, nonce)
+}
+
+// Verify checks signature, issuer, audience, expiry and nonce of the ID token.
+func (p *Provider) Verify(ctx context.Context, idToken, nonce string) (Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return Claims{}, errors.Wrap(ErrInvalidToken, "malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, err
+	}
+	if header.Alg != "RS256" {
+		return Claims{}, errors.Wrapf(ErrInvalidToken, "unsupported alg [%s]", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, errors.Wrap(ErrInvalidToken, "malformed signature")
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return Claims{}, errors.Wrap(ErrInvalidToken, "signature mismatch")
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, err
+	}
+	switch {
+	case claims.Issuer != p.cfg.Issuer:
+		return Claims{}, errors.Wrapf(ErrInvalidToken, "unexpected issuer [%s]", claims.Issuer)
+	case !claims.Audience.contains(p.cfg.ClientID):
+		return Claims{}, errors.Wrap(ErrInvalidToken, "client is not in audience")
+	case p.clock.Now().Unix() >= claims.Expiry:
+		return Claims{}, errors.Wrap(ErrInvalidToken, "token is expired")
+	case claims.Nonce != nonce:
+		return Claims{}, errors.Wrap(ErrInvalidToken, "nonce mismatch")
+	case claims.Subject == "":
+		return Claims{}, errors.Wrap(ErrInvalidToken, "empty subject")
+	}
+	return claims, nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc discovery request")
+	}
+	var d discovery
+	if err = p.do(req, &d); err != nil {
+		return nil, errors.Wrap(err, "oidc discovery")
+	}
+	if d.Issuer != p.cfg.Issuer {
+		return nil, errors.Errorf("oidc discovery: issuer [%s] does not match [%s]", d.Issuer, p.cfg.Issuer)
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// key returns signing key by its id, keys are refetched once for unknown id to follow key rotation.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc jwks request")
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err = p.do(req, &set); err != nil {
+		return nil, errors.Wrap(err, "oidc jwks")
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	p.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidToken, "unknown key [%s]", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) do(req *http.Request, dst interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(dst), "decode response")
+}
+
+func decodeSegment(segment string, dst interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.Wrap(ErrInvalidToken, "malformed segment")
+	}
+	if err = json.Unmarshal(data, dst); err != nil {
+		return errors.Wrap(ErrInvalidToken, "malformed segment")
+	}
+	return nil
+}
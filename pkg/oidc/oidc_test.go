@@ -0,0 +1,169 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	now := time.Date(2022, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	var (
+		issuer string
+		token  string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discovery{
+			Issuer:                issuer,
+			AuthorizationEndpoint: issuer + "/authorize",
+			TokenEndpoint:         issuer + "/token",
+			JWKSURI:               issuer + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": token})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	issuer = srv.URL
+
+	provider := New(Config{
+		Issuer:       issuer,
+		ClientID:     "homework",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:9000/v1/oidc/callback",
+	})
+	provider.clock = clock.NewFake(now)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, "state", parsed.Query().Get("state"))
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":                issuer,
+			"sub":                "42",
+			"aud":                []string{"homework", "other"},
+			"exp":                now.Add(time.Minute).Unix(),
+			"nonce":              "nonce",
+			"email":              "ivan@example.com",
+			"preferred_username": "ivan",
+		}
+	}
+	cases := []struct {
+		name   string
+		kid    string
+		modify func(claims map[string]interface{})
+		expErr error
+	}{
+		{
+			name:   "success",
+			kid:    "k1",
+			modify: func(map[string]interface{}) {},
+		},
+		{
+			name:   "failed, unknown key",
+			kid:    "k2",
+			modify: func(map[string]interface{}) {},
+			expErr: ErrInvalidToken,
+		},
+		{
+			name:   "failed, expired",
+			kid:    "k1",
+			modify: func(claims map[string]interface{}) { claims["exp"] = now.Unix() },
+			expErr: ErrInvalidToken,
+		},
+		{
+			name:   "failed, other audience",
+			kid:    "k1",
+			modify: func(claims map[string]interface{}) { claims["aud"] = "other" },
+			expErr: ErrInvalidToken,
+		},
+		{
+			name:   "failed, nonce mismatch",
+			kid:    "k1",
+			modify: func(claims map[string]interface{}) { claims["nonce"] = "replayed" },
+			expErr: ErrInvalidToken,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			claims := valid()
+			c.modify(claims)
+			token = sign(t, key, c.kid, claims)
+
+			got, err := provider.Exchange(ctx, "code", "nonce")
+			if c.expErr != nil {
+				assert.ErrorIs(t, err, c.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "42", got.Subject)
+			assert.Equal(t, "ivan", got.PreferredUsername)
+		})
+	}
+
+	t.Run("failed, tampered payload", func(t *testing.T) {
+		signed := sign(t, key, "k1", valid())
+		claims := valid()
+		claims["sub"] = "1"
+		payload, _ := json.Marshal(claims)
+		parts := strings.Split(signed, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+		_, err := provider.Verify(ctx, strings.Join(parts, "."), "nonce")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
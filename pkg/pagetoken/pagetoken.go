@@ -0,0 +1,147 @@
+// Package pagetoken encodes cursors of keyset pages to opaque tokens signed by HMAC,
+// so clients can't forge or alter a page of other list and expired tokens are rejected.
+package pagetoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+const (
+	version = 1
+	// filterSize is the size of the filter hash kept by the token
+	filterSize = 8
+	// macSize is the size of the truncated signature
+	macSize = 16
+
+	defaultTTL = time.Hour
+)
+
+var (
+	ErrInvalid  = errors.New("invalid page token")
+	ErrExpired  = errors.New("page token expired")
+	ErrDisabled = errors.New("page tokens are disabled")
+)
+
+// Config of the tokens. Services passing tokens to each other must have the same secret.
+type Config struct {
+	// Secret signs the tokens, tokens are disabled without it.
+	Secret string `mapstructure:"secret"`
+	// TTL of the tokens, default is 1h.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// Cursor is the state of the list kept by the token.
+type Cursor struct {
+	// Last is the key of the last item of the page, the next page starts after it.
+	Last string
+	// Order is true for descending lists.
+	Order bool
+	// Filter is the hash of the list filter, see Filter.
+	Filter string
+	// ExpiresAt is the time in UNIX format.
+	ExpiresAt int64
+}
+
+// Codec encodes and decodes the tokens. Nil codec is disabled: it encodes empty tokens and
+// rejects all tokens with ErrDisabled.
+type Codec struct {
+	secret []byte
+	ttl    time.Duration
+	clock  clock.Clock
+}
+
+// New returns the codec, it is nil without the secret.
+func New(cfg Config, clk clock.Clock) *Codec {
+	if cfg.Secret == "" {
+		return nil
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	return &Codec{
+		secret: []byte(cfg.Secret),
+		ttl:    cfg.TTL,
+		clock:  clk,
+	}
+}
+
+// Filter returns the hash of the canonical form of the list filter, e.g. its expression string.
+func Filter(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:filterSize])
+}
+
+// Encode returns the token of the page, which ends with the last key.
+func (c *Codec) Encode(last string, order bool, filter string) string {
+	if c == nil {
+		return ""
+	}
+	hash, err := hex.DecodeString(filter)
+	if err != nil || len(hash) != filterSize {
+		// the filter is not a hash of Filter, it never matches the token of other lists anyway
+		hash = make([]byte, filterSize)
+	}
+	flags := byte(0)
+	if order {
+		flags = 1
+	}
+	payload := make([]byte, 0, 2+binary.MaxVarintLen64+filterSize+len(last)+macSize)
+	payload = append(payload, version, flags)
+	payload = appendUvarint(payload, uint64(c.clock.Now().Add(c.ttl).Unix()))
+	payload = append(payload, hash...)
+	payload = append(payload, last...)
+	return base64.RawURLEncoding.EncodeToString(append(payload, c.sign(payload)...))
+}
+
+// Decode returns the cursor of the token, which must be issued for the list of the order and filter.
+func (c *Codec) Decode(token string, order bool, filter string) (Cursor, error) {
+	if c == nil {
+		return Cursor{}, ErrDisabled
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 2+1+filterSize+macSize {
+		return Cursor{}, ErrInvalid
+	}
+	payload, mac := data[:len(data)-macSize], data[len(data)-macSize:]
+	if !hmac.Equal(mac, c.sign(payload)) || payload[0] != version {
+		return Cursor{}, ErrInvalid
+	}
+	cursor := Cursor{Order: payload[1] == 1}
+	expiresAt, n := binary.Uvarint(payload[2:])
+	if n <= 0 || len(payload) < 2+n+filterSize {
+		return Cursor{}, ErrInvalid
+	}
+	rest := payload[2+n:]
+	cursor.ExpiresAt = int64(expiresAt)
+	cursor.Filter = hex.EncodeToString(rest[:filterSize])
+	cursor.Last = string(rest[filterSize:])
+
+	if c.clock.Now().Unix() >= cursor.ExpiresAt {
+		return Cursor{}, ErrExpired
+	}
+	if cursor.Order != order || cursor.Filter != filter {
+		return Cursor{}, errors.Wrap(ErrInvalid, "token of other list")
+	}
+	return cursor, nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
+
+// appendUvarint is binary.AppendUvarint of go 1.19.
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
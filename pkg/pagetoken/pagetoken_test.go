@@ -0,0 +1,121 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/clock"
+)
+
+func TestCodec(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1660412960, 0))
+	codec := New(Config{Secret: "secret", TTL: time.Minute}, clk)
+	filter := Filter("[attributes.team = core]")
+	token := codec.Encode("ivan", true, filter)
+
+	tampered := func() string {
+		data, err := base64.RawURLEncoding.DecodeString(token)
+		require.NoError(t, err)
+		// the last byte of the name
+		data[len(data)-macSize-1] ^= 1
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	cases := []struct {
+		name      string
+		codec     *Codec
+		token     string
+		order     bool
+		filter    string
+		advance   time.Duration
+		expCursor Cursor
+		expErr    error
+	}{
+		{
+			name:   "success",
+			codec:  codec,
+			token:  token,
+			order:  true,
+			filter: filter,
+			expCursor: Cursor{
+				Last:      "ivan",
+				Order:     true,
+				Filter:    filter,
+				ExpiresAt: 1660413020,
+			},
+		},
+		{
+			name:   "failed, tampered",
+			codec:  codec,
+			token:  tampered(),
+			order:  true,
+			filter: filter,
+			expErr: ErrInvalid,
+		},
+		{
+			name:   "failed, other secret",
+			codec:  New(Config{Secret: "other"}, clk),
+			token:  token,
+			order:  true,
+			filter: filter,
+			expErr: ErrInvalid,
+		},
+		{
+			name:   "failed, garbage",
+			codec:  codec,
+			token:  "not a token",
+			order:  true,
+			filter: filter,
+			expErr: ErrInvalid,
+		},
+		{
+			name:   "failed, other order",
+			codec:  codec,
+			token:  token,
+			filter: filter,
+			expErr: ErrInvalid,
+		},
+		{
+			name:   "failed, other filter",
+			codec:  codec,
+			token:  token,
+			order:  true,
+			filter: Filter("<nil>"),
+			expErr: ErrInvalid,
+		},
+		{
+			name:    "failed, expired",
+			codec:   codec,
+			token:   token,
+			order:   true,
+			filter:  filter,
+			advance: time.Minute,
+			expErr:  ErrExpired,
+		},
+		{
+			name:   "failed, disabled",
+			token:  token,
+			order:  true,
+			filter: filter,
+			expErr: ErrDisabled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clk.Advance(c.advance)
+			defer clk.Advance(-c.advance)
+
+			cursor, err := c.codec.Decode(c.token, c.order, c.filter)
+			assert.ErrorIs(t, err, c.expErr)
+			assert.Equal(t, c.expCursor, cursor)
+		})
+	}
+
+	assert.Nil(t, New(Config{}, clk))
+	assert.Empty(t, (*Codec)(nil).Encode("ivan", false, filter))
+}
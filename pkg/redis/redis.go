@@ -18,6 +18,7 @@ func New(ctx context.Context, cfg Config) (*redis.Client, error) {
 		Password: cfg.Password,
 		DB:       0,
 	})
+	rds.AddHook(timingHook{})
 
 	if err := rds.Ping(ctx).Err(); err != nil {
 		return nil, err
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/timing"
+)
+
+type startKey struct{}
+
+// timingHook adds duration of commands to the cache layer of the request timings.
+type timingHook struct{}
+
+func (timingHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return start(ctx), nil
+}
+
+func (timingHook) AfterProcess(ctx context.Context, _ redis.Cmder) error {
+	track(ctx)
+	return nil
+}
+
+func (timingHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return start(ctx), nil
+}
+
+func (timingHook) AfterProcessPipeline(ctx context.Context, _ []redis.Cmder) error {
+	track(ctx)
+	return nil
+}
+
+func start(ctx context.Context) context.Context {
+	if timing.FromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, startKey{}, time.Now())
+}
+
+func track(ctx context.Context) {
+	if started, ok := ctx.Value(startKey{}).(time.Time); ok {
+		timing.Track(ctx, timing.Cache, started)
+	}
+}
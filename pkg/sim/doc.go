@@ -0,0 +1,7 @@
+// Package sim is a deterministic scheduler of tests reproducing concurrency bugs. Yield marks
+// points, where the worker pool and the local cache may switch goroutines. In builds without
+// the sim tag Yield does nothing. Tests built with the tag run actors by Run one at a time,
+// so actors switch at the points only and the schedule decides the interleaving:
+//
+//	go test -tags sim ./pkg/sim/ ./pkg/workerpool/ ./internal/repo/local/
+package sim
@@ -0,0 +1,156 @@
+//go:build sim
+// +build sim
+
+package sim
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// PointStart is the point, where actors wait for their first turn.
+	PointStart = "start"
+
+	defaultTimeout = 5 * time.Second
+)
+
+// Actor is a goroutine of the simulation. Fn must pass ctx to the calls, so their
+// points switch the actor, and must not block out of the points, e.g. on a pool without
+// a free worker, the simulation fails by the timeout then.
+type Actor struct {
+	Name string
+	Fn   func(ctx context.Context)
+}
+
+// Schedule of the simulation.
+type Schedule struct {
+	// Script names actors resumed by switches in order, actors of the following switches
+	// are picked by Seed. A name of the actor, which is not waiting at a point, fails the run.
+	Script []string
+	Seed   int64
+	// Timeout of the running actor to reach the next point, default is 5s.
+	Timeout time.Duration
+}
+
+// Step is a turn of the actor resumed from the point.
+type Step struct {
+	Actor string
+	Point string
+}
+
+type actorKey struct{}
+
+type actor struct {
+	name   string
+	point  string
+	resume chan struct{}
+	s      *scheduler
+}
+
+type scheduler struct {
+	mu     sync.Mutex
+	parked map[string]*actor
+	// events receive a notice, when the running actor waits at a point or finishes
+	events chan struct{}
+}
+
+// Run runs the actors one at a time until all of them finish and returns the steps
+// of the run. Runs of the same schedule and actors have the same steps.
+func Run(schedule Schedule, actors ...Actor) ([]Step, error) {
+	timeout := schedule.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	s := &scheduler{
+		parked: make(map[string]*actor, len(actors)),
+		events: make(chan struct{}),
+	}
+	for _, a := range actors {
+		a := a
+		state := &actor{name: a.Name, resume: make(chan struct{}), s: s}
+		go func() {
+			ctx := context.WithValue(context.Background(), actorKey{}, state)
+			Yield(ctx, PointStart)
+			a.Fn(ctx)
+			s.events <- struct{}{}
+		}()
+	}
+
+	rnd := rand.New(rand.NewSource(schedule.Seed))
+	var steps []Step
+	live := len(actors)
+	for i := 0; i < live; i++ {
+		if err := s.wait(timeout); err != nil {
+			return steps, errors.Wrap(err, "sim: start")
+		}
+	}
+	for live > 0 {
+		next, err := s.pick(schedule.Script, len(steps), rnd)
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, Step{Actor: next.name, Point: next.point})
+		next.resume <- struct{}{}
+		if err = s.wait(timeout); err != nil {
+			return steps, errors.Wrapf(err, "sim: actor [%s] resumed from [%s]", next.name, next.point)
+		}
+		s.mu.Lock()
+		if _, ok := s.parked[next.name]; !ok {
+			live--
+		}
+		s.mu.Unlock()
+	}
+	return steps, nil
+}
+
+// Yield waits for the turn of the actor of ctx. Calls out of actors pass through.
+func Yield(ctx context.Context, point string) {
+	a, ok := ctx.Value(actorKey{}).(*actor)
+	if !ok {
+		return
+	}
+	a.s.mu.Lock()
+	a.point = point
+	a.s.parked[a.name] = a
+	a.s.mu.Unlock()
+	a.s.events <- struct{}{}
+	<-a.resume
+}
+
+func (s *scheduler) wait(timeout time.Duration) error {
+	select {
+	case <-s.events:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("blocked out of the points")
+	}
+}
+
+// pick removes the actor of the step from the waiting ones.
+func (s *scheduler) pick(script []string, step int, rnd *rand.Rand) (*actor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var name string
+	if step < len(script) {
+		name = script[step]
+		if _, ok := s.parked[name]; !ok {
+			return nil, errors.Errorf("sim: step %d: actor [%s] is not waiting", step, name)
+		}
+	} else {
+		names := make([]string, 0, len(s.parked))
+		for n := range s.parked {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		name = names[rnd.Intn(len(names))]
+	}
+	a := s.parked[name]
+	delete(s.parked, name)
+	return a, nil
+}
@@ -0,0 +1,65 @@
+//go:build sim
+// +build sim
+
+package sim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func counting(name string, points int, trace *[]string) Actor {
+	return Actor{Name: name, Fn: func(ctx context.Context) {
+		for i := 0; i < points; i++ {
+			*trace = append(*trace, name)
+			Yield(ctx, "point")
+		}
+	}}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("same seed, same interleaving", func(t *testing.T) {
+		var first, second []string
+		steps, err := Run(Schedule{Seed: 7}, counting("a", 3, &first), counting("b", 3, &first))
+		require.NoError(t, err)
+		again, err := Run(Schedule{Seed: 7}, counting("a", 3, &second), counting("b", 3, &second))
+		require.NoError(t, err)
+		assert.Equal(t, steps, again)
+		assert.Equal(t, first, second)
+		assert.Len(t, first, 6)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		var trace []string
+		steps, err := Run(Schedule{Script: []string{"b", "a", "b", "a", "a"}},
+			counting("a", 2, &trace), counting("b", 1, &trace))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "a", "a"}, trace)
+		assert.Equal(t, []Step{
+			{Actor: "b", Point: PointStart},
+			{Actor: "a", Point: PointStart},
+			{Actor: "b", Point: "point"},
+			{Actor: "a", Point: "point"},
+			{Actor: "a", Point: "point"},
+		}, steps)
+	})
+
+	t.Run("failed, script of finished actor", func(t *testing.T) {
+		var trace []string
+		_, err := Run(Schedule{Script: []string{"b", "b", "b"}}, counting("a", 1, &trace), counting("b", 1, &trace))
+		assert.Error(t, err)
+	})
+
+	t.Run("failed, actor blocked out of the points", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		_, err := Run(Schedule{Timeout: 10 * time.Millisecond}, Actor{Name: "a", Fn: func(context.Context) {
+			<-block
+		}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,9 @@
+//go:build !sim
+// +build !sim
+
+package sim
+
+import "context"
+
+// Yield is a switch point of the simulation, it does nothing in builds without the sim tag.
+func Yield(context.Context, string) {}
@@ -0,0 +1,69 @@
+// Package timing accumulates time spent by the layers of a single request.
+package timing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Layers measured by the data service.
+const (
+	Cache = "cache"
+	Repo  = "repo"
+	Total = "total"
+)
+
+type timingsKey struct{}
+
+// Timings is a breakdown of the request duration by layer, it is safe for concurrent use.
+type Timings struct {
+	mu     sync.Mutex
+	layers map[string]time.Duration
+}
+
+// WithTimings returns context, which collects timings of the request.
+func WithTimings(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{layers: make(map[string]time.Duration)}
+	return context.WithValue(ctx, timingsKey{}, t), t
+}
+
+// FromContext returns timings of the request, nil is returned if they are not collected.
+func FromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(timingsKey{}).(*Timings)
+	return t
+}
+
+// Track adds time since start to the layer, it is no-op if timings are not collected.
+func Track(ctx context.Context, layer string, start time.Time) {
+	if t := FromContext(ctx); t != nil {
+		t.Add(layer, time.Since(start))
+	}
+}
+
+// Add adds d to the layer.
+func (t *Timings) Add(layer string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.layers[layer] += d
+}
+
+// Get returns time spent by the layer.
+func (t *Timings) Get(layer string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.layers[layer]
+}
+
+// Layers returns names of the measured layers in order.
+func (t *Timings) Layers() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	layers := make([]string, 0, len(t.layers))
+	for layer := range t.layers {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	return layers
+}
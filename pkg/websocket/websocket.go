@@ -0,0 +1,284 @@
+// Package websocket is the server side of the WebSocket protocol, see RFC 6455.
+// Extensions and subprotocols are not negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Opcodes of frames.
+const (
+	OpText   = 0x1
+	OpBinary = 0x2
+	OpClose  = 0x8
+	OpPing   = 0x9
+	OpPong   = 0xa
+
+	opContinuation = 0x0
+)
+
+// Status codes of close frames.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+)
+
+const (
+	// acceptGUID is appended to the key of the client to get the accept key
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxMessage limits messages of clients, they are small control messages
+	maxMessage = 64 << 10
+	// maxControl is the limit of control frame payloads by the protocol
+	maxControl = 125
+)
+
+var (
+	ErrHandshake = errors.New("websocket handshake")
+	ErrProtocol  = errors.New("websocket protocol violation")
+	ErrTooBig    = errors.New("websocket message is too big")
+)
+
+// CloseError is returned by ReadMessage, when the client closes the connection.
+type CloseError struct {
+	Code   uint16
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket closed: " + e.Reason
+}
+
+// Conn is the upgraded connection. Messages are read by one goroutine,
+// writes are serialized, so pings and messages may be written concurrently.
+type Conn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	mu     sync.Mutex
+	onPong func()
+}
+
+// AcceptKey returns Sec-WebSocket-Accept of the client key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade switches the request to the protocol. Requests, which are not WebSocket
+// handshakes, get 400 and ErrHandshake is returned.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet,
+		!headerContains(r.Header, "Connection", "upgrade"),
+		!headerContains(r.Header, "Upgrade", "websocket"),
+		key == "":
+		http.Error(w, "websocket handshake is expected", http.StatusBadRequest)
+		return nil, ErrHandshake
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "websocket version 13 is supported", http.StatusUpgradeRequired)
+		return nil, ErrHandshake
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection can not be upgraded", http.StatusInternalServerError)
+		return nil, errors.Wrap(ErrHandshake, "response writer is not a hijacker")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "hijack")
+	}
+	if _, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"); err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "handshake response")
+	}
+	return &Conn{conn: conn, r: rw.Reader}, nil
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPongHandler sets the handler called by ReadMessage on pongs, e.g. to extend the read deadline.
+func (c *Conn) SetPongHandler(handler func()) {
+	c.onPong = handler
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered and pongs are handled
+// on the way. The close frame of the client is answered and returned as *CloseError.
+func (c *Conn) ReadMessage() (byte, []byte, error) {
+	var (
+		op      byte
+		message []byte
+	)
+	for {
+		fin, frameOp, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch frameOp {
+		case OpPing:
+			if err = c.WriteMessage(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			if c.onPong != nil {
+				c.onPong()
+			}
+			continue
+		case OpClose:
+			closeErr := &CloseError{Code: CloseNormal}
+			if len(payload) >= 2 {
+				closeErr.Code = binary.BigEndian.Uint16(payload)
+				closeErr.Reason = string(payload[2:])
+			}
+			_ = c.WriteClose(closeErr.Code, "")
+			return 0, nil, closeErr
+		case OpText, OpBinary:
+			if op != 0 {
+				return 0, nil, errors.Wrap(ErrProtocol, "data frame within fragmented message")
+			}
+			op = frameOp
+		case opContinuation:
+			if op == 0 {
+				return 0, nil, errors.Wrap(ErrProtocol, "continuation without message")
+			}
+		default:
+			return 0, nil, errors.Wrapf(ErrProtocol, "unknown opcode [%d]", frameOp)
+		}
+
+		if len(message)+len(payload) > maxMessage {
+			return 0, nil, ErrTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return op, message, nil
+		}
+	}
+}
+
+// readFrame reads the frame of the client, they are masked by the protocol.
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op := header[0]&0x80 != 0, header[0]&0x0f
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, errors.Wrap(ErrProtocol, "reserved bits are set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, errors.Wrap(ErrProtocol, "frame of the client is not masked")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= OpClose && (length > maxControl || !fin) {
+		return false, 0, nil, errors.Wrap(ErrProtocol, "invalid control frame")
+	}
+	if length > maxMessage {
+		return false, 0, nil, ErrTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage writes the message as one frame, frames of the server are not masked.
+func (c *Conn) WriteMessage(op byte, payload []byte) error {
+	var header []byte
+	switch length := len(payload); {
+	case length < 126:
+		header = []byte{0x80 | op, byte(length)}
+	case length <= 0xffff:
+		header = make([]byte, 4)
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = make([]byte, 10)
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	header[0] = 0x80 | op
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := (&net.Buffers{header, payload}).WriteTo(c.conn)
+	return err
+}
+
+// WriteClose starts the closing handshake with the status code and the reason.
+func (c *Conn) WriteClose(code uint16, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, code)
+	payload = append(payload, reason...)
+	if len(payload) > maxControl {
+		payload = payload[:maxControl]
+	}
+	return c.WriteMessage(OpClose, payload)
+}
+
+// Close closes the connection without the closing handshake.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// the example of RFC 6455 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// client is the client side of the connection, its frames are masked.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, url string) *client {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\n"+
+		"Connection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	require.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return &client{conn: conn, r: r}
+}
+
+func (c *client) write(t *testing.T, fin bool, op byte, payload []byte) {
+	header := []byte{op, 0x80 | byte(len(payload))}
+	if fin {
+		header[0] |= 0x80
+	}
+	if len(payload) >= 126 {
+		header[1] = 0x80 | 126
+		header = append(header, byte(len(payload)>>8), byte(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	header = append(header, mask...)
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	_, err := c.conn.Write(append(header, masked...))
+	require.NoError(t, err)
+}
+
+func (c *client) read(t *testing.T) (byte, []byte) {
+	var header [2]byte
+	_, err := io.ReadFull(c.r, header[:])
+	require.NoError(t, err)
+	length := int(header[1])
+	if length == 126 {
+		var ext [2]byte
+		_, err = io.ReadFull(c.r, ext[:])
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.r, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0f, payload
+}
+
+func TestConn(t *testing.T) {
+	pongs := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetPongHandler(func() {
+			pongs <- struct{}{}
+		})
+		// messages are echoed until the client closes the connection
+		for {
+			op, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(op, message); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	c := dial(t, srv.URL)
+	defer c.conn.Close()
+
+	c.write(t, true, OpText, []byte("hello"))
+	op, payload := c.read(t)
+	assert.Equal(t, byte(OpText), op)
+	assert.Equal(t, "hello", string(payload))
+
+	// fragments are joined, the ping between them is answered first
+	c.write(t, false, OpText, []byte("hel"))
+	c.write(t, true, OpPing, []byte("ping"))
+	c.write(t, true, opContinuation, []byte("lo again"))
+	op, payload = c.read(t)
+	assert.Equal(t, byte(OpPong), op)
+	assert.Equal(t, "ping", string(payload))
+	op, payload = c.read(t)
+	assert.Equal(t, byte(OpText), op)
+	assert.Equal(t, "hello again", string(payload))
+
+	// the length of 300 bytes takes the extended length field
+	long := strings.Repeat("a", 300)
+	c.write(t, true, OpBinary, []byte(long))
+	op, payload = c.read(t)
+	assert.Equal(t, byte(OpBinary), op)
+	assert.Equal(t, long, string(payload))
+
+	c.write(t, true, OpPong, nil)
+	<-pongs
+
+	c.write(t, true, OpClose, []byte{0x03, 0xe8})
+	op, payload = c.read(t)
+	assert.Equal(t, byte(OpClose), op)
+	assert.Equal(t, uint16(CloseNormal), binary.BigEndian.Uint16(payload))
+}
+
+func TestUpgrade_NotHandshake(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := Upgrade(w, r)
+		assert.ErrorIs(t, err, ErrHandshake)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,196 @@
+package workerpool
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"gitlab.ozon.dev/iTukaev/homework/pkg/sim"
+)
+
+// Points of the simulation, where tests built with the sim tag switch goroutines:
+// before the task is queued and before it is run by the worker.
+const (
+	PointEnqueue = "workerpool.enqueue"
+	PointRun     = "workerpool.run"
+)
+
+var (
+	ErrClosed = errors.New("worker pool is closed")
+	ErrPanic  = errors.New("task panicked")
+)
+
+// Task is a unit of work. Its context is cancelled when the submitter context
+// is done or the task timeout is reached.
+type Task func(ctx context.Context) error
+
+// Config of the pool. Non-positive Workers means a single worker, non-positive
+// QueueSize means the queue of Workers size, zero TaskTimeout disables the timeout.
+type Config struct {
+	Workers     int
+	QueueSize   int
+	TaskTimeout time.Duration
+}
+
+// Stats is a snapshot of the pool counters.
+type Stats struct {
+	Workers   int   `json:"workers"`
+	Queued    int   `json:"queued"`
+	Active    int64 `json:"active"`
+	Completed int64 `json:"completed"`
+	Failed    int64 `json:"failed"`
+	Panics    int64 `json:"panics"`
+}
+
+type job struct {
+	ctx  context.Context
+	task Task
+	// done receives the task result, it is nil for fire-and-forget tasks
+	done chan error
+}
+
+// Pool runs tasks by the fixed number of workers. Tasks are recovered from panics,
+// so a failed task never takes the service down.
+type Pool struct {
+	name   string
+	cfg    Config
+	logger *zap.SugaredLogger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan job
+	wg     sync.WaitGroup
+
+	active    int64
+	completed int64
+	failed    int64
+	panics    int64
+}
+
+func New(name string, cfg Config, logger *zap.SugaredLogger) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = cfg.Workers
+	}
+	p := &Pool{
+		name:   name,
+		cfg:    cfg,
+		logger: logger,
+		queue:  make(chan job, cfg.QueueSize),
+	}
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the task and returns without waiting for its result.
+// It blocks while the queue is full until ctx is done.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	return p.enqueue(ctx, job{ctx: ctx, task: task})
+}
+
+// Do queues the task and waits for its result. Once the task is queued, Do waits
+// until it is finished or skipped, so the task never outlives the call.
+func (p *Pool) Do(ctx context.Context, task Task) error {
+	done := make(chan error, 1)
+	if err := p.enqueue(ctx, job{ctx: ctx, task: task, done: done}); err != nil {
+		return err
+	}
+	return <-done
+}
+
+// Close stops accepting tasks and waits until queued tasks are finished.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *Pool) Name() string {
+	return p.name
+}
+
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Workers:   p.cfg.Workers,
+		Queued:    len(p.queue),
+		Active:    atomic.LoadInt64(&p.active),
+		Completed: atomic.LoadInt64(&p.completed),
+		Failed:    atomic.LoadInt64(&p.failed),
+		Panics:    atomic.LoadInt64(&p.panics),
+	}
+}
+
+func (p *Pool) enqueue(ctx context.Context, j job) error {
+	sim.Yield(ctx, PointEnqueue)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case p.queue <- j:
+		return nil
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for j := range p.queue {
+		err := p.run(j)
+		if j.done != nil {
+			j.done <- err
+		}
+	}
+}
+
+func (p *Pool) run(j job) (err error) {
+	sim.Yield(j.ctx, PointRun)
+	// submitter has gone while the task was queued
+	if err = j.ctx.Err(); err != nil {
+		atomic.AddInt64(&p.failed, 1)
+		return err
+	}
+
+	ctx, cancel := j.ctx, context.CancelFunc(func() {})
+	if p.cfg.TaskTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.TaskTimeout)
+	}
+	defer cancel()
+
+	atomic.AddInt64(&p.active, 1)
+	defer func() {
+		atomic.AddInt64(&p.active, -1)
+		if r := recover(); r != nil {
+			atomic.AddInt64(&p.panics, 1)
+			p.logger.Errorw("worker pool task panicked", "pool", p.name, "panic", r, "stack", string(debug.Stack()))
+			err = errors.Wrapf(ErrPanic, "%v", r)
+		}
+		if err != nil {
+			atomic.AddInt64(&p.failed, 1)
+			return
+		}
+		atomic.AddInt64(&p.completed, 1)
+	}()
+
+	return j.task(ctx)
+}
@@ -0,0 +1,126 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPool_Do(t *testing.T) {
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name string
+		cfg  Config
+		task Task
+		err  error
+	}{
+		{
+			name: "success",
+			task: func(context.Context) error { return nil },
+		},
+		{
+			name: "task error",
+			task: func(context.Context) error { return testErr },
+			err:  testErr,
+		},
+		{
+			name: "panic is recovered",
+			task: func(context.Context) error { panic("boom") },
+			err:  ErrPanic,
+		},
+		{
+			name: "task timeout",
+			cfg:  Config{TaskTimeout: 10 * time.Millisecond},
+			task: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			err: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New("test", tc.cfg, zap.NewNop().Sugar())
+			defer p.Close()
+
+			err := p.Do(context.Background(), tc.task)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Equal(t, int64(1), p.Stats().Failed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), p.Stats().Completed)
+		})
+	}
+}
+
+func TestPool_Concurrency(t *testing.T) {
+	p := New("test", Config{Workers: 3, QueueSize: 100}, zap.NewNop().Sugar())
+
+	var active, max int64
+	for i := 0; i < 50; i++ {
+		require.NoError(t, p.Submit(context.Background(), func(context.Context) error {
+			n := atomic.AddInt64(&active, 1)
+			for {
+				m := atomic.LoadInt64(&max)
+				if n <= m || atomic.CompareAndSwapInt64(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&active, -1)
+			return nil
+		}))
+	}
+	p.Close()
+
+	assert.LessOrEqual(t, max, int64(3))
+	assert.Equal(t, int64(50), p.Stats().Completed)
+	assert.ErrorIs(t, p.Submit(context.Background(), func(context.Context) error { return nil }), ErrClosed)
+}
+
+func TestPool_Cancel(t *testing.T) {
+	p := New("test", Config{Workers: 1, QueueSize: 1}, zap.NewNop().Sugar())
+	defer p.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = p.Do(context.Background(), func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// queued task is skipped, when its context is cancelled before start
+	var called int32
+	require.NoError(t, p.Submit(ctx, func(context.Context) error {
+		atomic.StoreInt32(&called, 1)
+		return nil
+	}))
+	cancel()
+
+	// queue is full, so the submitter gives up on its context
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+	assert.ErrorIs(t, p.Submit(timeout, func(context.Context) error { return nil }), context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	assert.NoError(t, p.Do(context.Background(), func(context.Context) error { return nil }))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&called))
+}
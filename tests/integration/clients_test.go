@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	apiDataPkg "gitlab.ozon.dev/iTukaev/homework/internal/api/data"
+	avatarPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/avatar"
+	userMockPkg "gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/mock"
+	"gitlab.ozon.dev/iTukaev/homework/internal/pkg/core/user/models"
+	pb "gitlab.ozon.dev/iTukaev/homework/pkg/api"
+	grpcPkg "gitlab.ozon.dev/iTukaev/homework/pkg/grpc"
+	loggerPkg "gitlab.ozon.dev/iTukaev/homework/pkg/logger"
+)
+
+const clientsDir = "../../clients"
+
+// TestClients_Smoke runs smoke scripts of the generated clients against the server.
+// Clients are generated by `make clients`, a client is skipped, if it is not generated.
+func TestClients_Smoke(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockUser := userMockPkg.NewMockInterface(ctl)
+	mockUser.EXPECT().List(gomock.Any(), false, uint64(10), uint64(0), gomock.Any(), "").
+		Return([]models.User{{ID: "id-1", Name: "ivan", Status: models.StatusActive}}, nil).AnyTimes()
+	mockUser.EXPECT().List(gomock.Any(), false, uint64(10), uint64(1), gomock.Any(), "").
+		Return(nil, nil).AnyTimes()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	methods := grpcPkg.NewMethods(grpcPkg.MethodsConfig{}, pb.User_ServiceDesc)
+	pb.RegisterUserServer(server, apiDataPkg.New(mockUser, loggerPkg.NewFatal(), avatarPkg.Config{}, methods))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+	addr := listener.Addr().String()
+
+	cases := []struct {
+		name      string
+		generated string
+		command   []string
+	}{
+		{
+			name:      "python",
+			generated: "python/src/api_pb2.py",
+			command:   []string{"python3", "smoke.py", "--addr", addr},
+		},
+		{
+			name:      "typescript",
+			generated: "typescript/dist/smoke.js",
+			command:   []string{"node", "dist/smoke.js", "--addr", addr},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := os.Stat(filepath.Join(clientsDir, c.generated)); err != nil {
+				t.Skipf("%s client is not generated, run `make clients`", c.name)
+			}
+			cmd := exec.Command(c.command[0], c.command[1:]...)
+			cmd.Dir = filepath.Join(clientsDir, c.name)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			t.Log(string(out))
+		})
+	}
+}
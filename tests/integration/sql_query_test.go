@@ -5,11 +5,13 @@ package integration
 
 const (
 	tableCreate = `CREATE TABLE IF NOT EXISTS public.users (
-name          varchar(30) NOT NULL CONSTRAINT name_right CHECK ( name ~ '^[A-Za-z0-9_\.]+$' ) PRIMARY KEY,
+id            uuid NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+name          varchar(30) NOT NULL CONSTRAINT name_right CHECK ( name ~ '^[A-Za-z0-9_\.]+$' ) UNIQUE,
 password      varchar(30) NOT NULL,
 email         varchar(50) NOT NULL UNIQUE CONSTRAINT email_right CHECK(email ~ '^.*@[A-Za-z0-9\-_\.]*$'),
 full_name     varchar(255) NOT NULL,
-created_at    integer
+created_at    integer,
+attributes    jsonb NOT NULL DEFAULT '{}'::jsonb
 );`
 
 	insertUsers = `INSERT INTO public.users (name, password, email, full_name, created_at)